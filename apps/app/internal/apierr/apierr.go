@@ -0,0 +1,151 @@
+// Package apierr gives API errors a stable, machine-readable shape. Every
+// error response is rendered by Handler as
+//
+//	{"code": "POLICY_NOT_FOUND", "message": "policy not found",
+//	 "field_errors": {}, "request_id": "..."}
+//
+// so clients can branch on code rather than on the English message.
+// Errors are still *echo.HTTPError, carrying a *Body as their message, so
+// middleware that inspects the status code keeps working.
+package apierr
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Codes shared across endpoints. Endpoint-specific codes are written at the
+// call site.
+const (
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInvalidBody      = "INVALID_BODY"
+	CodeDatabaseError    = "DATABASE_ERROR"
+	CodeForbidden        = "FORBIDDEN"
+	CodeInternal         = "INTERNAL_ERROR"
+)
+
+// Body is the JSON error envelope.
+type Body struct {
+	Code        string            `json:"code"`
+	Message     string            `json:"message"`
+	FieldErrors map[string]string `json:"field_errors"`
+	RequestID   string            `json:"request_id"`
+
+	// extra holds endpoint-specific keys rendered alongside the envelope,
+	// such as the holder of a conflicting edit lock.
+	extra map[string]any
+}
+
+func (b *Body) String() string { return b.Code + ": " + b.Message }
+
+// MarshalJSON renders the envelope with any extra keys alongside it.
+func (b *Body) MarshalJSON() ([]byte, error) {
+	type envelope Body
+	out, err := json.Marshal((*envelope)(b))
+	if err != nil || len(b.extra) == 0 {
+		return out, err
+	}
+	m := maps.Clone(b.extra)
+	if err := json.Unmarshal(out, &m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// New returns an error with the given status, code, and message.
+func New(status int, code, message string) *echo.HTTPError {
+	return echo.NewHTTPError(status, &Body{Code: code, Message: message, FieldErrors: map[string]string{}})
+}
+
+// Invalid returns a 400 VALIDATION_FAILED error attributing message to each
+// of fields.
+func Invalid(message string, fields ...string) *echo.HTTPError {
+	he := New(http.StatusBadRequest, CodeValidationFailed, message)
+	for _, f := range fields {
+		he.Message.(*Body).FieldErrors[f] = message
+	}
+	return he
+}
+
+// With returns err with key added to its response body.
+func With(err *echo.HTTPError, key string, value any) *echo.HTTPError {
+	b := *bodyOf(err)
+	b.extra = maps.Clone(b.extra)
+	if b.extra == nil {
+		b.extra = map[string]any{}
+	}
+	b.extra[key] = value
+	return echo.NewHTTPError(err.Code, &b)
+}
+
+// Database is the 500 returned when a query fails.
+func Database() *echo.HTTPError {
+	return New(http.StatusInternalServerError, CodeDatabaseError, "database error")
+}
+
+// From returns the envelope for err, as Handler would render it, for
+// endpoints that report errors per item rather than for the whole request.
+func From(err error) *Body {
+	var he *echo.HTTPError
+	if !errors.As(err, &he) {
+		he = New(http.StatusInternalServerError, CodeInternal, "internal error")
+	}
+	return bodyOf(he)
+}
+
+// Handler is the server's echo.HTTPErrorHandler. It renders every error,
+// coded or not, in the envelope, deriving a code from the status for errors
+// raised without one (by Echo itself, for example).
+func Handler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	he := &echo.HTTPError{Code: http.StatusInternalServerError}
+	if !errors.As(err, &he) {
+		log.Printf("unhandled error: %v", err)
+		he = New(http.StatusInternalServerError, CodeInternal, "internal error")
+	}
+	if err := Respond(c, he); err != nil {
+		log.Printf("write error response: %v", err)
+	}
+}
+
+// Respond writes err's envelope as the response, for handlers whose error
+// responses are part of their normal flow.
+func Respond(c echo.Context, err *echo.HTTPError) error {
+	b := *bodyOf(err)
+	b.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(err.Code)
+	}
+	return c.JSON(err.Code, &b)
+}
+
+// bodyOf returns err's envelope, building one from a plain message.
+func bodyOf(err *echo.HTTPError) *Body {
+	switch m := err.Message.(type) {
+	case *Body:
+		return m
+	case string:
+		return &Body{Code: statusCode(err.Code), Message: m, FieldErrors: map[string]string{}}
+	default:
+		return &Body{Code: statusCode(err.Code), Message: strings.ToLower(http.StatusText(err.Code)), FieldErrors: map[string]string{}}
+	}
+}
+
+// statusCode is the code for an error raised without one.
+func statusCode(status int) string {
+	if status == http.StatusInternalServerError {
+		return CodeInternal
+	}
+	if text := http.StatusText(status); text != "" {
+		return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+	}
+	return CodeInternal
+}
@@ -0,0 +1,54 @@
+package apierr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandler(t *testing.T) {
+	e := echo.New()
+	render := func(err error) (int, map[string]any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+		c.Response().Header().Set(echo.HeaderXRequestID, "req-1")
+		Handler(err, c)
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("unmarshal %q: %v", rec.Body.String(), err)
+		}
+		return rec.Code, body
+	}
+
+	code, body := render(New(http.StatusBadRequest, "POLICY_NOT_PUBLISHED", "can only acknowledge published policies"))
+	if code != http.StatusBadRequest || body["code"] != "POLICY_NOT_PUBLISHED" ||
+		body["message"] != "can only acknowledge published policies" || body["request_id"] != "req-1" {
+		t.Errorf("coded error = %d %v", code, body)
+	}
+
+	_, body = render(Invalid("title is required", "title"))
+	if fields, _ := body["field_errors"].(map[string]any); body["code"] != CodeValidationFailed || fields["title"] != "title is required" {
+		t.Errorf("validation error = %v", body)
+	}
+
+	_, body = render(With(New(http.StatusConflict, "POLICY_LOCKED", "Ann is editing this draft"), "lock", map[string]string{"user_name": "Ann"}))
+	if lock, _ := body["lock"].(map[string]any); body["code"] != "POLICY_LOCKED" || lock["user_name"] != "Ann" {
+		t.Errorf("error with extra = %v", body)
+	}
+
+	// Errors raised without a code, by Echo or a dependency, get one from
+	// their status.
+	code, body = render(echo.ErrMethodNotAllowed)
+	if code != http.StatusMethodNotAllowed || body["code"] != "METHOD_NOT_ALLOWED" || body["message"] != "Method Not Allowed" {
+		t.Errorf("plain echo error = %d %v", code, body)
+	}
+	code, body = render(errors.New("boom"))
+	if code != http.StatusInternalServerError || body["code"] != CodeInternal || body["message"] != "internal error" {
+		t.Errorf("unexpected error = %d %v", code, body)
+	}
+}
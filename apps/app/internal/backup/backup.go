@@ -0,0 +1,71 @@
+// Package backup copies the SQLite database into blob storage, on demand or
+// on a schedule, so a lost container disk does not lose the data.
+package backup
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/storage"
+)
+
+// Result describes a completed backup.
+type Result struct {
+	Key       string    `json:"key"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Run snapshots the database to a temporary file and uploads it under
+// backups/policyflow-<timestamp>.db.
+func Run(ctx context.Context, db *database.DB, store storage.Store) (*Result, error) {
+	dir, err := os.MkdirTemp("", "policyflow-backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	ts := time.Now().UTC()
+	path := filepath.Join(dir, "snapshot.db")
+	if err := db.Backup(ctx, path); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	key := "backups/policyflow-" + ts.Format("20060102T150405Z") + ".db"
+	if err := store.Put(ctx, key, f, info.Size(), "application/vnd.sqlite3"); err != nil {
+		return nil, err
+	}
+	return &Result{Key: key, SizeBytes: info.Size(), CreatedAt: ts}, nil
+}
+
+// Schedule runs a backup every interval until ctx is cancelled.
+func Schedule(ctx context.Context, db *database.DB, store storage.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r, err := Run(ctx, db, store)
+			if err != nil {
+				log.Printf("backup error: %v", err)
+				continue
+			}
+			log.Printf("Backup written to %s (%d bytes)", r.Key, r.SizeBytes)
+		}
+	}
+}
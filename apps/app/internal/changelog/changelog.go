@@ -0,0 +1,124 @@
+// Package changelog summarizes the difference between two policy versions.
+package changelog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// section is a markdown heading and the text beneath it.
+type section struct {
+	title string
+	body  string
+}
+
+// Summarize describes how next differs from prev in terms of markdown
+// sections: which headings were added, removed, or had their text changed.
+// prev is empty for a policy's first version.
+func Summarize(prev, next string) string {
+	if strings.TrimSpace(prev) == "" {
+		return "Initial version."
+	}
+	before, after := split(prev), split(next)
+
+	beforeByTitle := make(map[string]string, len(before))
+	for _, s := range before {
+		beforeByTitle[s.title] = s.body
+	}
+	afterTitles := make(map[string]bool, len(after))
+
+	var added, removed, modified []string
+	for _, s := range after {
+		afterTitles[s.title] = true
+		old, ok := beforeByTitle[s.title]
+		switch {
+		case !ok:
+			added = append(added, s.title)
+		case old != s.body:
+			modified = append(modified, s.title)
+		}
+	}
+	for _, s := range before {
+		if !afterTitles[s.title] {
+			removed = append(removed, s.title)
+		}
+	}
+
+	var parts []string
+	for _, p := range []struct {
+		verb   string
+		titles []string
+	}{{"Added", added}, {"Removed", removed}, {"Modified", modified}} {
+		if len(p.titles) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s.", p.verb, strings.Join(quote(p.titles), ", ")))
+		}
+	}
+	if len(parts) == 0 {
+		return "No content changes."
+	}
+	return strings.Join(parts, " ")
+}
+
+// Sections lists the section titles of a markdown document in order, named
+// as in Summarize: "Introduction" for text before the first heading and
+// numbered repeats.
+func Sections(content string) []string {
+	sections := split(content)
+	titles := make([]string, len(sections))
+	for i, s := range sections {
+		titles[i] = s.title
+	}
+	return titles
+}
+
+// split breaks markdown into sections at ATX headings. Text before the first
+// heading belongs to an "Introduction" section. Repeated headings are
+// numbered so each section keeps a distinct key.
+func split(content string) []section {
+	var sections []section
+	seen := map[string]int{}
+	cur := section{title: "Introduction"}
+	var body []string
+	flush := func() {
+		cur.body = strings.TrimSpace(strings.Join(body, "\n"))
+		if cur.title != "Introduction" || cur.body != "" {
+			sections = append(sections, cur)
+		}
+		body = body[:0]
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if title, ok := heading(line); ok {
+			flush()
+			seen[title]++
+			if n := seen[title]; n > 1 {
+				title = fmt.Sprintf("%s (%d)", title, n)
+			}
+			cur = section{title: title}
+			continue
+		}
+		body = append(body, strings.TrimRight(line, " \t"))
+	}
+	flush()
+	return sections
+}
+
+func heading(line string) (string, bool) {
+	trimmed := strings.TrimLeft(line, "#")
+	level := len(line) - len(trimmed)
+	if level == 0 || level > 6 || (trimmed != "" && trimmed[0] != ' ' && trimmed[0] != '\t') {
+		return "", false
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(trimmed), "#"))
+	if title == "" {
+		return "", false
+	}
+	return title, true
+}
+
+func quote(titles []string) []string {
+	out := make([]string, len(titles))
+	for i, t := range titles {
+		out[i] = `"` + t + `"`
+	}
+	return out
+}
@@ -0,0 +1,25 @@
+package changelog
+
+import "testing"
+
+func TestSummarize(t *testing.T) {
+	prev := "Intro text.\n\n# Scope\nAll staff.\n\n## Leave\nTwenty days.\n\n## Travel\nEconomy only.\n"
+	tests := []struct {
+		name, prev, next, want string
+	}{
+		{"first version", "", prev, "Initial version."},
+		{"unchanged", prev, prev + "\n", "No content changes."},
+		{
+			"mixed",
+			prev,
+			"Intro text.\n\n# Scope\nAll staff and contractors.\n\n## Leave\nTwenty days.\n\n## Expenses\nReceipts required.\n",
+			`Added: "Expenses". Removed: "Travel". Modified: "Scope".`,
+		},
+		{"hashtag is not a heading", "# A\nx", "# A\n#notaheading", `Modified: "A".`},
+	}
+	for _, tt := range tests {
+		if got := Summarize(tt.prev, tt.next); got != tt.want {
+			t.Errorf("%s: Summarize() = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportedAck is an acknowledgement given outside PolicyFlow.
+type ImportedAck struct {
+	UserID          string
+	PolicyVersionID string
+	Timestamp       time.Time
+}
+
+// ImportAcknowledgements records acks with the imported source in one
+// transaction, skipping users who already acknowledged the version, and
+// returns how many were added. With dryRun the transaction is rolled back,
+// so the count is what an import would add.
+func (db *DB) ImportAcknowledgements(ctx context.Context, acks []ImportedAck, dryRun bool) (int, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO acknowledgements (id, user_id, policy_version_id, timestamp, signature_hash, source)
+		 VALUES (?,?,?,?,?,?)
+		 ON CONFLICT (user_id, policy_version_id) DO NOTHING`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	added := 0
+	for _, a := range acks {
+		ts := a.Timestamp.UTC()
+		sig := fmt.Sprintf("%x", sha256.Sum256([]byte(a.UserID+a.PolicyVersionID+ts.String()+AckSourceImported)))
+		res, err := stmt.ExecContext(ctx, uuid.New().String(), a.UserID, a.PolicyVersionID, ts.Format(time.RFC3339), sig, AckSourceImported)
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		added += int(n)
+	}
+	if dryRun {
+		return added, nil
+	}
+	return added, tx.Commit()
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Page sizes for acknowledgement listings.
+const (
+	DefaultAckPageSize = 100
+	MaxAckPageSize     = 1000
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// AckPage is one page of acknowledgements, newest first. NextCursor is set
+// when more acknowledgements follow.
+type AckPage struct {
+	Items      []*Acknowledgement `json:"items"`
+	NextCursor *string            `json:"next_cursor"`
+}
+
+// AckDetail is an acknowledgement with the acknowledging user's name,
+// email, and current department.
+type AckDetail struct {
+	Acknowledgement
+	UserName       string  `json:"user_name"`
+	UserEmail      string  `json:"user_email"`
+	DepartmentID   *string `json:"department_id"`
+	DepartmentName *string `json:"department_name"`
+}
+
+// AckDetailPage is one page of AckDetail, newest first.
+type AckDetailPage struct {
+	Items      []*AckDetail `json:"items"`
+	NextCursor *string      `json:"next_cursor"`
+}
+
+// ListAcknowledgementDetailsPage returns a page of a version's
+// acknowledgements, ordered by (timestamp, id) descending and starting after
+// cursor, each joined to its user and department in the same query.
+func (db *DB) ListAcknowledgementDetailsPage(ctx context.Context, policyVersionID, cursor string, limit int) (*AckDetailPage, error) {
+	limit, afterTS, afterID, err := ackKeyset(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT a.id, a.user_id, a.policy_version_id, a.timestamp, a.signature_hash, a.source,
+		        u.name, u.email, u.department_id, d.name
+		 FROM acknowledgements a
+		 JOIN users u ON u.id = a.user_id
+		 LEFT JOIN departments d ON d.id = u.department_id
+		 WHERE a.policy_version_id = ?
+		   AND (? = '' OR a.timestamp < ? OR (a.timestamp = ? AND a.id < ?))
+		 ORDER BY a.timestamp DESC, a.id DESC
+		 LIMIT ?`,
+		policyVersionID, afterTS, afterTS, afterTS, afterID, limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &AckDetailPage{Items: []*AckDetail{}}
+	var lastTS string
+	for rows.Next() {
+		a := &AckDetail{}
+		var ts string
+		var deptID, deptName sql.NullString
+		if err := rows.Scan(&a.ID, &a.UserID, &a.PolicyVersionID, &ts, &a.SignatureHash, &a.Source,
+			&a.UserName, &a.UserEmail, &deptID, &deptName); err != nil {
+			return nil, err
+		}
+		if len(page.Items) == limit {
+			next := encodeAckCursor(lastTS, page.Items[limit-1].ID)
+			page.NextCursor = &next
+			break
+		}
+		a.Timestamp = parseTime(ts)
+		a.DepartmentID = nullString(deptID)
+		a.DepartmentName = nullString(deptName)
+		page.Items = append(page.Items, a)
+		lastTS = ts
+	}
+	return page, rows.Err()
+}
+
+// EachAcknowledgementDetail calls fn for every acknowledgement of a
+// version, newest first, as it is read, for exports too large to hold in
+// memory. An error from fn stops the iteration and is returned.
+func (db *DB) EachAcknowledgementDetail(ctx context.Context, policyVersionID string, fn func(*AckDetail) error) error {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT a.id, a.user_id, a.policy_version_id, a.timestamp, a.signature_hash, a.source,
+		        u.name, u.email, u.department_id, d.name
+		 FROM acknowledgements a
+		 JOIN users u ON u.id = a.user_id
+		 LEFT JOIN departments d ON d.id = u.department_id
+		 WHERE a.policy_version_id = ?
+		 ORDER BY a.timestamp DESC, a.id DESC`,
+		policyVersionID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a := &AckDetail{}
+		var ts string
+		var deptID, deptName sql.NullString
+		if err := rows.Scan(&a.ID, &a.UserID, &a.PolicyVersionID, &ts, &a.SignatureHash, &a.Source,
+			&a.UserName, &a.UserEmail, &deptID, &deptName); err != nil {
+			return err
+		}
+		a.Timestamp = parseTime(ts)
+		a.DepartmentID = nullString(deptID)
+		a.DepartmentName = nullString(deptName)
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ListUserAcknowledgementsPage returns a page of a user's acknowledgements,
+// ordered by (timestamp, id) descending, starting after cursor. Seeking on
+// that keyset keeps deep pages as cheap as the first.
+func (db *DB) ListUserAcknowledgementsPage(ctx context.Context, userID, cursor string, limit int) (*AckPage, error) {
+	limit, afterTS, afterID, err := ackKeyset(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT id, user_id, policy_version_id, timestamp, signature_hash, source FROM acknowledgements
+		 WHERE user_id = ?
+		   AND (? = '' OR timestamp < ? OR (timestamp = ? AND id < ?))
+		 ORDER BY timestamp DESC, id DESC
+		 LIMIT ?`,
+		userID, afterTS, afterTS, afterTS, afterID, limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &AckPage{Items: []*Acknowledgement{}}
+	var lastTS string
+	for rows.Next() {
+		a := &Acknowledgement{}
+		var ts string
+		if err := rows.Scan(&a.ID, &a.UserID, &a.PolicyVersionID, &ts, &a.SignatureHash, &a.Source); err != nil {
+			return nil, err
+		}
+		if len(page.Items) == limit {
+			next := encodeAckCursor(lastTS, page.Items[limit-1].ID)
+			page.NextCursor = &next
+			break
+		}
+		a.Timestamp = parseTime(ts)
+		page.Items = append(page.Items, a)
+		lastTS = ts
+	}
+	return page, rows.Err()
+}
+
+// ackKeyset clamps limit and decodes cursor into the last row's stored
+// timestamp and ID; both are empty for the first page.
+func ackKeyset(cursor string, limit int) (int, string, string, error) {
+	if limit <= 0 || limit > MaxAckPageSize {
+		limit = DefaultAckPageSize
+	}
+	if cursor == "" {
+		return limit, "", "", nil
+	}
+	ts, id, err := decodeAckCursor(cursor)
+	return limit, ts, id, err
+}
+
+// encodeAckCursor makes an opaque cursor from the last row's stored
+// timestamp and ID.
+func encodeAckCursor(ts, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(ts + "|" + id))
+}
+
+func decodeAckCursor(cursor string) (ts, id string, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(b), "|")
+	if !ok || id == "" {
+		return "", "", ErrInvalidCursor
+	}
+	if _, err := time.Parse(time.RFC3339, ts); err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	return ts, id, nil
+}
+
+// CountAcknowledgements returns how many acknowledgements a version has.
+func (db *DB) CountAcknowledgements(ctx context.Context, policyVersionID string) (int, error) {
+	var n int
+	err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM acknowledgements WHERE policy_version_id=?`, policyVersionID).Scan(&n)
+	return n, err
+}
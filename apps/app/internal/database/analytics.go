@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// TimeseriesQuery selects the activity counted by ActivityTimeseries.
+type TimeseriesQuery struct {
+	From, To     time.Time
+	Weekly       bool    // bucket by ISO week (Monday) instead of day
+	ByDepartment bool    // split each bucket by department
+	DepartmentID *string // restrict to one department
+}
+
+// TimeseriesPoint holds the counts for one period (and department, if grouped).
+type TimeseriesPoint struct {
+	Period           string  `json:"period"` // YYYY-MM-DD of the day or week start
+	DepartmentID     *string `json:"department_id,omitempty"`
+	DepartmentName   *string `json:"department_name,omitempty"`
+	Acknowledgements int     `json:"acknowledgements"`
+	Publishes        int     `json:"publishes"`
+}
+
+// ActivityTimeseries counts acknowledgements (by the acknowledging user's
+// department) and version publishes (by the policy's department) per period.
+// Points are returned only for periods with activity, ordered by period.
+func (db *DB) ActivityTimeseries(ctx context.Context, q TimeseriesQuery) ([]*TimeseriesPoint, error) {
+	bucket := func(col string) string {
+		if q.Weekly {
+			return `date(` + col + `, 'weekday 0', '-6 days')`
+		}
+		return `date(` + col + `)`
+	}
+	group := `NULL`
+	if q.ByDepartment {
+		group = `dept`
+	}
+	from, to := q.From.UTC().Format(time.RFC3339), q.To.UTC().Format(time.RFC3339)
+
+	query := `
+SELECT period, ` + group + ` AS dept_id, (SELECT name FROM departments WHERE id = ` + group + `),
+       SUM(acks), SUM(pubs)
+FROM (
+	SELECT ` + bucket("a.timestamp") + ` AS period, u.department_id AS dept, 1 AS acks, 0 AS pubs
+	FROM acknowledgements a JOIN users u ON u.id = a.user_id
+	WHERE a.timestamp >= ? AND a.timestamp < ? AND (? = '' OR u.department_id = ?)
+	UNION ALL
+	SELECT ` + bucket("v.published_at") + `, p.department_id, 0, 1
+	FROM policy_versions v JOIN policies p ON p.id = v.policy_id
+	WHERE v.published_at >= ? AND v.published_at < ? AND (? = '' OR p.department_id = ?)
+)
+GROUP BY period, dept_id
+ORDER BY period, dept_id`
+
+	dept := deref(q.DepartmentID)
+	rows, err := db.conn.QueryContext(ctx, query, from, to, dept, dept, from, to, dept, dept)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []*TimeseriesPoint
+	for rows.Next() {
+		p := &TimeseriesPoint{}
+		var deptID, deptName sql.NullString
+		if err := rows.Scan(&p.Period, &deptID, &deptName, &p.Acknowledgements, &p.Publishes); err != nil {
+			return nil, err
+		}
+		if deptID.Valid {
+			p.DepartmentID = &deptID.String
+		}
+		if deptName.Valid {
+			p.DepartmentName = &deptName.String
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// CountAcknowledgementsSince counts acknowledgements given after since, by
+// users of deptID when it is set.
+func (db *DB) CountAcknowledgementsSince(ctx context.Context, deptID *string, since time.Time) (int, error) {
+	var n int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM acknowledgements a
+		 JOIN users u ON u.id = a.user_id
+		 WHERE a.timestamp >= ? AND (? IS NULL OR u.department_id = ?)`,
+		since.UTC().Format(time.RFC3339), deptID, deptID,
+	).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,297 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Assignment target types.
+const (
+	AssignUser       = "user"
+	AssignRole       = "role"
+	AssignDepartment = "department"
+)
+
+// PolicyAssignment explicitly requires a policy for a user, every user with a
+// role, or every member of a department.
+type PolicyAssignment struct {
+	ID         string    `json:"id"`
+	PolicyID   string    `json:"policy_id"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	TargetName string    `json:"target_name"`
+	CreatedBy  *string   `json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// assignmentMatch is the SQL predicate matching an assignment row `a` against
+// a user. Bind args: userID, role, deptID.
+const assignmentMatch = `((a.target_type = 'user' AND a.target_id = ?)
+	OR (a.target_type = 'role' AND a.target_id = ?)
+	OR (a.target_type = 'department' AND a.target_id = ?))`
+
+// CreatePolicyAssignment adds an assignment. Re-assigning an existing target is
+// a no-op and returns the existing row.
+func (db *DB) CreatePolicyAssignment(ctx context.Context, policyID, targetType, targetID string, createdBy *string) (*PolicyAssignment, error) {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_assignments (id, policy_id, target_type, target_id, created_by, created_at)
+		 VALUES (?,?,?,?,?,?) ON CONFLICT(policy_id, target_type, target_id) DO NOTHING`,
+		uuid.New().String(), policyID, targetType, targetID, createdBy, now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.scanAssignment(db.conn.QueryRowContext(ctx,
+		assignmentSelect+` WHERE a.policy_id = ? AND a.target_type = ? AND a.target_id = ?`,
+		policyID, targetType, targetID,
+	))
+}
+
+func (db *DB) GetPolicyAssignment(ctx context.Context, id string) (*PolicyAssignment, error) {
+	return db.scanAssignment(db.conn.QueryRowContext(ctx, assignmentSelect+` WHERE a.id = ?`, id))
+}
+
+func (db *DB) ListPolicyAssignments(ctx context.Context, policyID string) ([]*PolicyAssignment, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		assignmentSelect+` WHERE a.policy_id = ? ORDER BY a.target_type, target_name`, policyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*PolicyAssignment
+	for rows.Next() {
+		a, err := db.scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (db *DB) DeletePolicyAssignment(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM policy_assignments WHERE id=?`, id)
+	return err
+}
+
+// IsPolicyAssignedTo reports whether any assignment on the policy matches the user.
+func (db *DB) IsPolicyAssignedTo(ctx context.Context, policyID, userID, role string, deptID *string) (bool, error) {
+	var count int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM policy_assignments a WHERE a.policy_id = ? AND `+assignmentMatch,
+		policyID, userID, role, deref(deptID),
+	).Scan(&count)
+	return count > 0, err
+}
+
+// requiredWhere restricts policySelect to the published policies a user must
+// acknowledge. A policy with assignments is required only for matching users;
+// a policy without assignments is required for everyone who can see it.
+// Bind args: userID, role, deptID, deptID.
+const requiredWhere = ` WHERE p.status = 'Published' AND (
+	EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id AND ` + assignmentMatch + `)
+	OR (NOT EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id)
+	    AND (p.visibility_type = 'organization'
+	         OR (p.visibility_type = 'department' AND p.department_id = ?))))`
+
+// requiredForUser is the per-user counterpart of requiredWhere: it matches a
+// policy (alias p) against a user row (alias u) without bind args, for
+// queries that aggregate over users.
+const requiredForUser = `(CASE WHEN EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id)
+	THEN EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id AND (
+		(a.target_type = 'user' AND a.target_id = u.id)
+		OR (a.target_type = 'role' AND a.target_id = u.role)
+		OR (a.target_type = 'department' AND a.target_id = u.department_id)))
+	ELSE (p.visibility_type = 'organization'
+	      OR (p.visibility_type = 'department' AND p.department_id = u.department_id)) END)`
+
+// PolicyAckCoverage counts the active users a policy is required for and how
+// many of them have acknowledged its current version.
+func (db *DB) PolicyAckCoverage(ctx context.Context, policyID string) (required, acknowledged int, err error) {
+	err = db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*), COUNT(ak.id)
+		 FROM policies p
+		 JOIN users u ON u.deactivated_at IS NULL AND `+requiredForUser+`
+		 LEFT JOIN acknowledgements ak ON ak.user_id = u.id AND ak.policy_version_id = p.current_version_id
+		 WHERE p.id = ?`, policyID,
+	).Scan(&required, &acknowledged)
+	return required, acknowledged, err
+}
+
+// AckCoverage is how many of the active users a policy is required for have
+// acknowledged its current version.
+type AckCoverage struct {
+	AckCount          int     `json:"ack_count"`
+	EligibleUserCount int     `json:"eligible_user_count"`
+	CompliancePct     float64 `json:"compliance_pct"` // 0–100, one decimal
+}
+
+func newAckCoverage(eligible, acked int) *AckCoverage {
+	c := &AckCoverage{AckCount: acked, EligibleUserCount: eligible}
+	if eligible > 0 {
+		c.CompliancePct = math.Round(float64(acked)*1000/float64(eligible)) / 10
+	}
+	return c
+}
+
+// AckCoverageByPolicy returns PolicyAckCoverage for every policy in one
+// aggregate query, keyed by policy ID. Policies nobody is required to
+// acknowledge are absent.
+func (db *DB) AckCoverageByPolicy(ctx context.Context) (map[string]*AckCoverage, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT p.id, COUNT(*), COUNT(ak.id)
+		 FROM policies p
+		 JOIN users u ON u.deactivated_at IS NULL AND `+requiredForUser+`
+		 LEFT JOIN acknowledgements ak ON ak.user_id = u.id AND ak.policy_version_id = p.current_version_id
+		 GROUP BY p.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]*AckCoverage{}
+	for rows.Next() {
+		var id string
+		var eligible, acked int
+		if err := rows.Scan(&id, &eligible, &acked); err != nil {
+			return nil, err
+		}
+		out[id] = newAckCoverage(eligible, acked)
+	}
+	return out, rows.Err()
+}
+
+// GetAckCoverage is PolicyAckCoverage as an AckCoverage.
+func (db *DB) GetAckCoverage(ctx context.Context, policyID string) (*AckCoverage, error) {
+	required, acked, err := db.PolicyAckCoverage(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	return newAckCoverage(required, acked), nil
+}
+
+// ListRequiredPoliciesForUser returns the published policies a user must acknowledge.
+func (db *DB) ListRequiredPoliciesForUser(ctx context.Context, userID, role string, deptID *string) ([]*Policy, error) {
+	return db.queryPolicies(ctx,
+		policySelect+requiredWhere+` ORDER BY p.created_at DESC`,
+		userID, role, deref(deptID), deref(deptID),
+	)
+}
+
+// ListPendingPoliciesForUser returns required policies whose current version
+// the user has not acknowledged and is in effect, soonest deadline first.
+// Deadlines are the user's own: see GraceDeadline.
+func (db *DB) ListPendingPoliciesForUser(ctx context.Context, userID, role string, deptID *string) ([]*Policy, error) {
+	ts := now()
+	policies, err := db.queryPolicies(ctx,
+		policySelect+requiredWhere+`
+		   AND p.current_version_id IS NOT NULL
+		   AND NOT EXISTS (SELECT 1 FROM acknowledgements ak
+		                   WHERE ak.user_id = ? AND ak.policy_version_id = p.current_version_id)
+		   AND NOT EXISTS (SELECT 1 FROM policy_versions ev WHERE ev.id = p.current_version_id
+		                   AND (ev.effective_from > ? OR ev.effective_to < ?))
+		 ORDER BY p.ack_deadline IS NULL, p.ack_deadline ASC, p.created_at DESC`,
+		userID, role, deref(deptID), deref(deptID), userID, ts, ts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	joined, grace, err := db.newHireGrace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range policies {
+		p.AckDeadline = GraceDeadline(p.AckDeadline, joined, grace)
+	}
+	return policies, nil
+}
+
+// ListOverduePoliciesForUser is ListPendingPoliciesForUser restricted to
+// policies whose acknowledgement deadline for the user is before now.
+func (db *DB) ListOverduePoliciesForUser(ctx context.Context, userID, role string, deptID *string, now time.Time) ([]*Policy, error) {
+	pending, err := db.ListPendingPoliciesForUser(ctx, userID, role, deptID)
+	if err != nil {
+		return nil, err
+	}
+	var overdue []*Policy
+	for _, p := range pending {
+		if p.AckDeadline != nil && p.AckDeadline.Before(now) {
+			overdue = append(overdue, p)
+		}
+	}
+	return overdue, nil
+}
+
+// GraceDeadline is a policy's acknowledgement deadline for a user who joined
+// at joined: a new hire gets at least graceDays from joining, so they are not
+// overdue on day one for a deadline set before they arrived. Policies
+// without a deadline stay without one.
+func GraceDeadline(deadline *time.Time, joined time.Time, graceDays int) *time.Time {
+	if deadline == nil || graceDays <= 0 {
+		return deadline
+	}
+	if end := joined.AddDate(0, 0, graceDays); end.After(*deadline) {
+		return &end
+	}
+	return deadline
+}
+
+// NewHireGraceDays returns the organization's new-hire grace period.
+func (db *DB) NewHireGraceDays(ctx context.Context) (int, error) {
+	return db.GetIntSetting(ctx, SettingNewHireGraceDays, 0)
+}
+
+// newHireGrace returns when a user's account was created and the grace
+// period that applies from then.
+func (db *DB) newHireGrace(ctx context.Context, userID string) (time.Time, int, error) {
+	grace, err := db.NewHireGraceDays(ctx)
+	if err != nil || grace <= 0 {
+		return time.Time{}, 0, err
+	}
+	var createdAt string
+	err = db.conn.QueryRowContext(ctx, `SELECT created_at FROM users WHERE id = ?`, userID).Scan(&createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, 0, nil
+	}
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return parseTime(createdAt), grace, nil
+}
+
+const assignmentSelect = `SELECT a.id, a.policy_id, a.target_type, a.target_id,
+	COALESCE(CASE a.target_type
+		WHEN 'user' THEN (SELECT u.name FROM users u WHERE u.id = a.target_id)
+		WHEN 'department' THEN (SELECT d.name FROM departments d WHERE d.id = a.target_id)
+		ELSE a.target_id END, '') AS target_name,
+	a.created_by, a.created_at
+	FROM policy_assignments a`
+
+func (db *DB) scanAssignment(row scanner) (*PolicyAssignment, error) {
+	a := &PolicyAssignment{}
+	var createdBy sql.NullString
+	var createdAt string
+	if err := row.Scan(&a.ID, &a.PolicyID, &a.TargetType, &a.TargetID, &a.TargetName, &createdBy, &createdAt); err != nil {
+		return nil, err
+	}
+	a.CreatedAt = parseTime(createdAt)
+	if createdBy.Valid {
+		a.CreatedBy = &createdBy.String
+	}
+	return a, nil
+}
+
+// deref returns the pointed-to string, or "" for nil. Useful for binding
+// optional IDs into predicates that should simply not match when absent.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
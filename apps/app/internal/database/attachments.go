@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PolicyAttachment is a supporting file (form, diagram, signed original)
+// attached to a policy. The bytes live in blob storage under StorageKey.
+type PolicyAttachment struct {
+	ID             string    `json:"id"`
+	PolicyID       string    `json:"policy_id"`
+	Filename       string    `json:"filename"`
+	ContentType    string    `json:"content_type"`
+	SizeBytes      int64     `json:"size_bytes"`
+	SHA256         string    `json:"sha256"`
+	StorageKey     string    `json:"-"`
+	UploadedBy     *string   `json:"uploaded_by"`
+	UploadedByName *string   `json:"uploaded_by_name"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+const attachmentSelect = `
+SELECT a.id, a.policy_id, a.filename, a.content_type, a.size_bytes, a.sha256, a.storage_key,
+       a.uploaded_by, u.name, a.created_at
+FROM policy_attachments a
+LEFT JOIN users u ON u.id = a.uploaded_by`
+
+func (db *DB) scanAttachment(row interface{ Scan(...any) error }) (*PolicyAttachment, error) {
+	a := &PolicyAttachment{}
+	var uploadedBy, uploadedByName sql.NullString
+	var createdAt string
+	if err := row.Scan(&a.ID, &a.PolicyID, &a.Filename, &a.ContentType, &a.SizeBytes, &a.SHA256, &a.StorageKey,
+		&uploadedBy, &uploadedByName, &createdAt); err != nil {
+		return nil, err
+	}
+	a.UploadedBy = nullString(uploadedBy)
+	a.UploadedByName = nullString(uploadedByName)
+	a.CreatedAt = parseTime(createdAt)
+	return a, nil
+}
+
+// CreatePolicyAttachment records an attachment whose bytes were already
+// written to storage under a.StorageKey.
+func (db *DB) CreatePolicyAttachment(ctx context.Context, a *PolicyAttachment) (*PolicyAttachment, error) {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_attachments (id, policy_id, filename, content_type, size_bytes, sha256, storage_key, uploaded_by, created_at)
+		 VALUES (?,?,?,?,?,?,?,?,?)`,
+		a.ID, a.PolicyID, a.Filename, a.ContentType, a.SizeBytes, a.SHA256, a.StorageKey, a.UploadedBy, now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetPolicyAttachment(ctx, a.ID)
+}
+
+func (db *DB) GetPolicyAttachment(ctx context.Context, id string) (*PolicyAttachment, error) {
+	return db.scanAttachment(db.conn.QueryRowContext(ctx, attachmentSelect+` WHERE a.id = ?`, id))
+}
+
+func (db *DB) ListPolicyAttachments(ctx context.Context, policyID string) ([]*PolicyAttachment, error) {
+	rows, err := db.conn.QueryContext(ctx, attachmentSelect+` WHERE a.policy_id = ? ORDER BY a.created_at, a.filename`, policyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*PolicyAttachment
+	for rows.Next() {
+		a, err := db.scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (db *DB) DeletePolicyAttachment(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM policy_attachments WHERE id = ?`, id)
+	return err
+}
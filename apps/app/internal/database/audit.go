@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEntry is one record in the audit trail. ImpersonatorID is set when
+// the action was taken in a SuperAdmin's impersonation session; ActorID is
+// then the impersonated user.
+type AuditEntry struct {
+	ID               string    `json:"id"`
+	ActorID          *string   `json:"actor_id"`
+	ActorName        *string   `json:"actor_name"`
+	ImpersonatorID   *string   `json:"impersonator_id"`
+	ImpersonatorName *string   `json:"impersonator_name"`
+	Action           string    `json:"action"`
+	TargetType       string    `json:"target_type"`
+	TargetID         string    `json:"target_id"`
+	Details          string    `json:"details"`
+	IPAddress        string    `json:"ip_address"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Domain events recorded in the audit log alongside the per-request entries.
+// They make up the organization activity feed.
+const (
+	ActivityPolicyCreated     = "policy.created"
+	ActivityPolicyPublished   = "policy.published"
+	ActivityPolicyArchived    = "policy.archived"
+	ActivityVersionCreated    = "policy.version_created"
+	ActivityUserCreated       = "user.created"
+	ActivityUserRemoved       = "user.removed"
+	ActivityDepartmentCreated = "department.created"
+	ActivityDepartmentUpdated = "department.updated"
+	ActivityDepartmentDeleted = "department.deleted"
+)
+
+// ActivityActions lists every activity feed event type.
+var ActivityActions = []string{
+	ActivityPolicyCreated, ActivityPolicyPublished, ActivityPolicyArchived, ActivityVersionCreated,
+	ActivityUserCreated, ActivityUserRemoved,
+	ActivityDepartmentCreated, ActivityDepartmentUpdated, ActivityDepartmentDeleted,
+}
+
+// ActionAccessDenied records a request refused for lack of authentication or
+// permission, or for a policy hidden from the caller.
+const ActionAccessDenied = "access.denied"
+
+// ActionInviteResent records an admin re-sending a user's welcome email.
+const ActionInviteResent = "user.invite_resent"
+
+// ActionAcknowledgementsImported records an import of acknowledgements
+// given outside PolicyFlow.
+const ActionAcknowledgementsImported = "acknowledgements.imported"
+
+// AuditFilter narrows ListAuditLog. Zero values match everything.
+type AuditFilter struct {
+	ActorID string
+	Action  string
+	Limit   int
+}
+
+func (db *DB) RecordAudit(ctx context.Context, e *AuditEntry) error {
+	e.ID = uuid.New().String()
+	ts := now()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO audit_log (id, actor_id, impersonator_id, action, target_type, target_id, details, ip_address, created_at)
+		 VALUES (?,?,?,?,?,?,?,?,?)`,
+		e.ID, e.ActorID, e.ImpersonatorID, e.Action, e.TargetType, e.TargetID, e.Details, e.IPAddress, ts,
+	)
+	if err != nil {
+		return err
+	}
+	e.CreatedAt = parseTime(ts)
+	return nil
+}
+
+// ListAuditLog returns matching entries, newest first.
+func (db *DB) ListAuditLog(ctx context.Context, f AuditFilter) ([]*AuditEntry, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT a.id, a.actor_id, au.name, a.impersonator_id, iu.name, a.action, a.target_type, a.target_id,
+		        a.details, a.ip_address, a.created_at
+		 FROM audit_log a
+		 LEFT JOIN users au ON au.id = a.actor_id
+		 LEFT JOIN users iu ON iu.id = a.impersonator_id
+		 WHERE (? = '' OR a.actor_id = ? OR a.impersonator_id = ?)
+		   AND (? = '' OR a.action = ?)
+		 ORDER BY a.created_at DESC, a.rowid DESC
+		 LIMIT ?`,
+		f.ActorID, f.ActorID, f.ActorID, f.Action, f.Action, f.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*AuditEntry
+	for rows.Next() {
+		e := &AuditEntry{}
+		var actorID, actorName, impID, impName sql.NullString
+		var createdAt string
+		if err := rows.Scan(&e.ID, &actorID, &actorName, &impID, &impName, &e.Action, &e.TargetType, &e.TargetID,
+			&e.Details, &e.IPAddress, &createdAt); err != nil {
+			return nil, err
+		}
+		e.ActorID = nullString(actorID)
+		e.ActorName = nullString(actorName)
+		e.ImpersonatorID = nullString(impID)
+		e.ImpersonatorName = nullString(impName)
+		e.CreatedAt = parseTime(createdAt)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// DeniedAccess summarizes the refused requests of one user or, for requests
+// without a valid session, one IP address.
+type DeniedAccess struct {
+	ActorID   *string   `json:"actor_id"`
+	ActorName *string   `json:"actor_name"`
+	IPAddress string    `json:"ip_address"` // most recent
+	Attempts  int       `json:"attempts"`
+	Targets   int       `json:"targets"` // distinct entities
+	FirstAt   time.Time `json:"first_at"`
+	LastAt    time.Time `json:"last_at"`
+}
+
+// ListDeniedAccess returns the users and anonymous IP addresses refused at
+// least min times since since, most refused first.
+func (db *DB) ListDeniedAccess(ctx context.Context, since time.Time, min int) ([]*DeniedAccess, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT g.actor_id, au.name, l.ip_address, g.attempts, g.targets, g.first_at, g.last_at
+		 FROM (SELECT actor_id, COUNT(*) AS attempts, COUNT(DISTINCT NULLIF(target_id, '')) AS targets,
+		              MIN(created_at) AS first_at, MAX(created_at) AS last_at, MAX(rowid) AS last_row
+		       FROM audit_log
+		       WHERE action = ? AND created_at >= ?
+		       GROUP BY COALESCE(actor_id, 'ip:' || ip_address)
+		       HAVING COUNT(*) >= ?) g
+		 JOIN audit_log l ON l.rowid = g.last_row
+		 LEFT JOIN users au ON au.id = g.actor_id
+		 ORDER BY g.attempts DESC, g.last_at DESC`,
+		ActionAccessDenied, since.UTC().Format(time.RFC3339), min,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*DeniedAccess
+	for rows.Next() {
+		d := &DeniedAccess{}
+		var actorID, actorName sql.NullString
+		var firstAt, lastAt string
+		if err := rows.Scan(&actorID, &actorName, &d.IPAddress, &d.Attempts, &d.Targets, &firstAt, &lastAt); err != nil {
+			return nil, err
+		}
+		d.ActorID = nullString(actorID)
+		d.ActorName = nullString(actorName)
+		d.FirstAt = parseTime(firstAt)
+		d.LastAt = parseTime(lastAt)
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ActivityItem is one entry in the activity feed. TargetName is the target's
+// current name or title, so renamed and anonymized records show as they are
+// now; it is nil once the target has been deleted.
+type ActivityItem struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	ActorID    *string   `json:"actor_id"`
+	ActorName  *string   `json:"actor_name"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	TargetName *string   `json:"target_name"`
+	Details    string    `json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ActivityFilter narrows ListActivity. Zero values match everything.
+type ActivityFilter struct {
+	Types    []string // subset of ActivityActions; empty = all
+	ActorID  string
+	TargetID string
+	Since    *time.Time
+	Before   string // ID of the last item of the previous page
+	Limit    int
+}
+
+// ListActivity returns activity feed items, newest first.
+func (db *DB) ListActivity(ctx context.Context, f ActivityFilter) ([]*ActivityItem, error) {
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	types := f.Types
+	if len(types) == 0 {
+		types = ActivityActions
+	}
+	since := ""
+	if f.Since != nil {
+		since = f.Since.UTC().Format(time.RFC3339)
+	}
+	args := make([]any, 0, len(types)+8)
+	for _, t := range types {
+		args = append(args, t)
+	}
+	args = append(args, f.ActorID, f.ActorID, f.TargetID, f.TargetID, since, since, f.Before, f.Before, f.Limit)
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT a.id, a.action, a.actor_id, au.name, a.target_type, a.target_id,
+		        COALESCE(tu.name, tp.title, td.name), a.details, a.created_at
+		 FROM audit_log a
+		 LEFT JOIN users au ON au.id = a.actor_id
+		 LEFT JOIN users tu ON a.target_type = 'user' AND tu.id = a.target_id
+		 LEFT JOIN policies tp ON a.target_type = 'policy' AND tp.id = a.target_id
+		 LEFT JOIN departments td ON a.target_type = 'department' AND td.id = a.target_id
+		 WHERE a.action IN (?`+strings.Repeat(",?", len(types)-1)+`)
+		   AND (? = '' OR a.actor_id = ?)
+		   AND (? = '' OR a.target_id = ?)
+		   AND (? = '' OR a.created_at >= ?)
+		   AND (? = '' OR a.rowid < (SELECT rowid FROM audit_log WHERE id = ?))
+		 ORDER BY a.rowid DESC
+		 LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*ActivityItem
+	for rows.Next() {
+		it := &ActivityItem{}
+		var actorID, actorName, targetName sql.NullString
+		var createdAt string
+		if err := rows.Scan(&it.ID, &it.Type, &actorID, &actorName, &it.TargetType, &it.TargetID,
+			&targetName, &it.Details, &createdAt); err != nil {
+			return nil, err
+		}
+		it.ActorID = nullString(actorID)
+		it.ActorName = nullString(actorName)
+		it.TargetName = nullString(targetName)
+		it.CreatedAt = parseTime(createdAt)
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
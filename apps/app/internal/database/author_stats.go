@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// AuthorStats counts the policies one author created by status, for
+// balancing policy work across authors.
+type AuthorStats struct {
+	AuthorID    *string `json:"author_id"` // nil for policies whose creator is unknown
+	AuthorName  string  `json:"author_name"`
+	AuthorEmail string  `json:"author_email"`
+	Policies    int     `json:"policies"`
+	Drafts      int     `json:"drafts"`
+	InReview    int     `json:"in_review"`
+	Published   int     `json:"published"`
+	Archived    int     `json:"archived"`
+	// OverdueReviews counts published policies whose current version went
+	// live longer ago than the review interval.
+	OverdueReviews int `json:"overdue_reviews"`
+	// AvgDaysToPublish is the mean time from creating a policy to its first
+	// published version; nil when none of the author's policies has been
+	// published.
+	AvgDaysToPublish *float64 `json:"avg_days_to_publish"`
+}
+
+// ListAuthorStats groups policies by the user who created them, only
+// policies in deptID when it is set. A published policy is overdue for
+// review when its current version went live before reviewBefore. Authors
+// are ordered by name, with unknown creators last.
+func (db *DB) ListAuthorStats(ctx context.Context, deptID *string, reviewBefore time.Time) ([]*AuthorStats, error) {
+	query := `SELECT p.created_by, u.name, u.email, COUNT(*),
+	       SUM(p.status = 'Draft'), SUM(p.status = 'Review'), SUM(p.status = 'Published'), SUM(p.status = 'Archived'),
+	       SUM(p.status = 'Published' AND COALESCE(cv.published_at, cv.created_at) < ?),
+	       AVG(julianday((SELECT MIN(v.published_at) FROM policy_versions v WHERE v.policy_id = p.id)) - julianday(p.created_at))
+	FROM policies p
+	LEFT JOIN users u ON u.id = p.created_by
+	LEFT JOIN policy_versions cv ON cv.id = p.current_version_id`
+	args := []any{reviewBefore.UTC().Format(time.RFC3339)}
+	if deptID != nil {
+		query += ` WHERE p.department_id = ?`
+		args = append(args, *deptID)
+	}
+	rows, err := db.conn.QueryContext(ctx, query+` GROUP BY p.created_by ORDER BY u.name IS NULL, u.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []*AuthorStats{}
+	for rows.Next() {
+		a := &AuthorStats{}
+		var authorID, name, email sql.NullString
+		var avgDays sql.NullFloat64
+		if err := rows.Scan(&authorID, &name, &email, &a.Policies, &a.Drafts, &a.InReview, &a.Published, &a.Archived,
+			&a.OverdueReviews, &avgDays); err != nil {
+			return nil, err
+		}
+		a.AuthorID = nullString(authorID)
+		a.AuthorName, a.AuthorEmail = name.String, email.String
+		if a.AuthorID == nil || !name.Valid {
+			a.AuthorName = "Unknown"
+		}
+		if avgDays.Valid {
+			a.AvgDaysToPublish = &avgDays.Float64
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
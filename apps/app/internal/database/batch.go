@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"strings"
+)
+
+// GetPoliciesByIDs returns the policies with the given IDs in the order
+// requested, skipping IDs that do not exist.
+func (db *DB) GetPoliciesByIDs(ctx context.Context, ids []string) ([]*Policy, error) {
+	if len(ids) == 0 {
+		return []*Policy{}, nil
+	}
+	policies, err := db.queryPolicies(ctx, policySelect+` WHERE p.id IN (`+placeholders(len(ids))+`)`, anySlice(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Policy, len(policies))
+	for _, p := range policies {
+		byID[p.ID] = p
+	}
+	return inOrder(ids, byID), nil
+}
+
+// GetUsersByIDs returns the users with the given IDs in the order
+// requested, skipping IDs that do not exist.
+func (db *DB) GetUsersByIDs(ctx context.Context, ids []string) ([]*User, error) {
+	if len(ids) == 0 {
+		return []*User{}, nil
+	}
+	users, err := db.queryUsers(ctx, userSelect+` WHERE u.id IN (`+placeholders(len(ids))+`)`, anySlice(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	return inOrder(ids, byID), nil
+}
+
+// GetPolicyVersionsByIDs returns the versions with the given IDs, keyed by
+// ID.
+func (db *DB) GetPolicyVersionsByIDs(ctx context.Context, ids []string) (map[string]*PolicyVersion, error) {
+	out := make(map[string]*PolicyVersion, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := db.conn.QueryContext(ctx, versionSelect+` WHERE v.id IN (`+placeholders(len(ids))+`)`, anySlice(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		v, err := db.scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[v.ID] = v
+	}
+	return out, rows.Err()
+}
+
+func inOrder[T any](ids []string, byID map[string]*T) []*T {
+	out := make([]*T, 0, len(byID))
+	for _, id := range ids {
+		if v, ok := byID[id]; ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func placeholders(n int) string {
+	return "?" + strings.Repeat(",?", n-1)
+}
+
+func anySlice(ids []string) []any {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
@@ -0,0 +1,177 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"policyflow/internal/database"
+)
+
+// openDB opens and migrates a SQLite database at path.
+func openDB(tb testing.TB, path string) *database.DB {
+	tb.Helper()
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		tb.Fatalf("open db: %v", err)
+	}
+	conn.SetMaxOpenConns(1)
+	db := database.New(conn)
+	ctx := context.Background()
+	if err := db.Init(ctx); err != nil {
+		tb.Fatalf("db.Init: %v", err)
+	}
+	if err := db.Migrate(ctx); err != nil {
+		tb.Fatalf("db.Migrate: %v", err)
+	}
+	return db
+}
+
+// TestGenerateLoad verifies the generated organization is complete and
+// that acknowledgements only go to users a policy is required of.
+func TestGenerateLoad(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, ":memory:")
+	sum, err := db.GenerateLoad(ctx, database.LoadSpec{Departments: 3, Users: 60, Policies: 10, OrgWidePct: 20, AckPct: 100, Seed: 7})
+	if err != nil {
+		t.Fatalf("GenerateLoad: %v", err)
+	}
+	stats, err := db.GetStats(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalUsers != 60 || stats.PublishedCount != 10 || stats.TotalAckCount != sum.Acknowledgements {
+		t.Errorf("stats = %+v; want 60 users, 10 published policies, %d acknowledgements", stats, sum.Acknowledgements)
+	}
+	coverage, err := db.AckCoverageByPolicy(ctx)
+	if err != nil {
+		t.Fatalf("AckCoverageByPolicy: %v", err)
+	}
+	for id, c := range coverage {
+		if c.AckCount != c.EligibleUserCount {
+			t.Errorf("policy %s: %d of %d acknowledged; want all at 100%%", id, c.AckCount, c.EligibleUserCount)
+		}
+	}
+}
+
+// The benchmarks run against POLICYFLOW_BENCH_DB when set, a file filled
+// with `policyflowctl seed`, and otherwise against a small generated
+// organization:
+//
+//	policyflowctl seed --db /tmp/bench.db --users 50000 --policies 2000
+//	POLICYFLOW_BENCH_DB=/tmp/bench.db go test ./internal/database -run '^$' -bench .
+var bench struct {
+	once sync.Once
+	db   *database.DB
+	user *database.User
+}
+
+func benchDB(b *testing.B) (*database.DB, *database.User) {
+	b.Helper()
+	bench.once.Do(func() {
+		ctx := context.Background()
+		if path := os.Getenv("POLICYFLOW_BENCH_DB"); path != "" {
+			bench.db = openDB(b, path)
+		} else {
+			bench.db = openDB(b, ":memory:")
+			if _, err := bench.db.GenerateLoad(ctx, database.LoadSpec{
+				Departments: 20, Users: 2000, Policies: 200, OrgWidePct: 5, AckPct: 50, Seed: 1,
+			}); err != nil {
+				b.Fatalf("GenerateLoad: %v", err)
+			}
+		}
+		users, err := bench.db.ListUsers(ctx)
+		if err != nil || len(users) == 0 {
+			b.Fatalf("ListUsers: %d users, %v", len(users), err)
+		}
+		bench.user = users[len(users)/2]
+	})
+	if bench.db == nil {
+		b.Fatal("benchmark database unavailable")
+	}
+	return bench.db, bench.user
+}
+
+func BenchmarkListPoliciesForUser(b *testing.B) {
+	db, u := benchDB(b)
+	for b.Loop() {
+		if _, err := db.ListPoliciesForUser(context.Background(), u.ID, u.Role, u.DepartmentID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListPendingPoliciesForUser(b *testing.B) {
+	db, u := benchDB(b)
+	for b.Loop() {
+		if _, err := db.ListPendingPoliciesForUser(context.Background(), u.ID, u.Role, u.DepartmentID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAckStatusForUser(b *testing.B) {
+	db, u := benchDB(b)
+	for b.Loop() {
+		if _, err := db.AckStatusForUser(context.Background(), u.ID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetStats(b *testing.B) {
+	db, _ := benchDB(b)
+	for b.Loop() {
+		if _, err := db.GetStats(context.Background(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPolicyAckCounts(b *testing.B) {
+	db, _ := benchDB(b)
+	for b.Loop() {
+		if _, err := db.PolicyAckCounts(context.Background(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAckCoverageByPolicy(b *testing.B) {
+	db, _ := benchDB(b)
+	for b.Loop() {
+		if _, err := db.AckCoverageByPolicy(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildAckMatrixDepartment(b *testing.B) {
+	db, u := benchDB(b)
+	now := time.Now().UTC()
+	for b.Loop() {
+		if _, err := db.BuildAckMatrix(context.Background(), u.DepartmentID, now); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListAcknowledgementDetailsPage(b *testing.B) {
+	db, u := benchDB(b)
+	ctx := context.Background()
+	policies, err := db.ListPoliciesForUser(ctx, u.ID, u.Role, u.DepartmentID)
+	if err != nil || len(policies) == 0 {
+		b.Fatalf("ListPoliciesForUser: %d policies, %v", len(policies), err)
+	}
+	versionID := *policies[0].CurrentVersionID
+	for b.Loop() {
+		if _, err := db.ListAcknowledgementDetailsPage(ctx, versionID, "", database.DefaultAckPageSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
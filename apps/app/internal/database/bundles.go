@@ -0,0 +1,277 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PolicyBundle is a named set of policies, such as "New Hire Packet", that
+// is assigned to every user created in DepartmentID with Role. A nil
+// criterion matches any department or role.
+type PolicyBundle struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	DepartmentID   *string         `json:"department_id"`
+	DepartmentName *string         `json:"department_name"`
+	Role           *string         `json:"role"`
+	Policies       []*BundlePolicy `json:"policies"`
+	AssignedCount  int             `json:"assigned_count"`
+	CreatedBy      *string         `json:"created_by"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// BundlePolicy is a policy in a bundle, in bundle order.
+type BundlePolicy struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// BundleAssignment records that a bundle was applied to a user.
+type BundleAssignment struct {
+	BundleID   string    `json:"bundle_id"`
+	UserID     string    `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	UserEmail  string    `json:"user_email"`
+	AssignedBy *string   `json:"assigned_by"` // nil = on account creation
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// ErrBundleNameTaken is returned when another bundle has the name.
+var ErrBundleNameTaken = errors.New("bundle name already in use")
+
+const bundleSelect = `SELECT b.id, b.name, b.description, b.department_id, d.name, b.role,
+	(SELECT COUNT(*) FROM policy_bundle_assignments ba WHERE ba.bundle_id = b.id),
+	b.created_by, b.created_at
+	FROM policy_bundles b LEFT JOIN departments d ON d.id = b.department_id`
+
+func (db *DB) queryBundles(ctx context.Context, query string, args ...any) ([]*PolicyBundle, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var out []*PolicyBundle
+	byID := map[string]*PolicyBundle{}
+	for rows.Next() {
+		b := &PolicyBundle{Policies: []*BundlePolicy{}}
+		var deptID, deptName, role, createdBy sql.NullString
+		var createdAt string
+		if err := rows.Scan(&b.ID, &b.Name, &b.Description, &deptID, &deptName, &role,
+			&b.AssignedCount, &createdBy, &createdAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.DepartmentID, b.DepartmentName, b.Role = nullString(deptID), nullString(deptName), nullString(role)
+		b.CreatedBy = nullString(createdBy)
+		b.CreatedAt = parseTime(createdAt)
+		out = append(out, b)
+		byID[b.ID] = b
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(out) == 0 {
+		return out, err
+	}
+
+	ids := make([]string, len(out))
+	for i, b := range out {
+		ids[i] = b.ID
+	}
+	rows, err = db.conn.QueryContext(ctx,
+		`SELECT i.bundle_id, p.id, p.title, p.status FROM policy_bundle_items i JOIN policies p ON p.id = i.policy_id
+		 WHERE i.bundle_id IN (`+placeholders(len(ids))+`) ORDER BY i.position`, anySlice(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bundleID string
+		p := &BundlePolicy{}
+		if err := rows.Scan(&bundleID, &p.ID, &p.Title, &p.Status); err != nil {
+			return nil, err
+		}
+		byID[bundleID].Policies = append(byID[bundleID].Policies, p)
+	}
+	return out, rows.Err()
+}
+
+// ListBundles returns every bundle by name.
+func (db *DB) ListBundles(ctx context.Context) ([]*PolicyBundle, error) {
+	return db.queryBundles(ctx, bundleSelect+` ORDER BY b.name`)
+}
+
+func (db *DB) GetBundle(ctx context.Context, id string) (*PolicyBundle, error) {
+	bundles, err := db.queryBundles(ctx, bundleSelect+` WHERE b.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(bundles) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return bundles[0], nil
+}
+
+// SaveBundle creates the bundle when b.ID is empty and updates it
+// otherwise, replacing its policies with policyIDs in order.
+func (db *DB) SaveBundle(ctx context.Context, b *PolicyBundle, policyIDs []string) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var res sql.Result
+	if b.ID == "" {
+		b.ID = uuid.New().String()
+		res, err = tx.ExecContext(ctx,
+			`INSERT INTO policy_bundles (id, name, description, department_id, role, created_by, created_at)
+			 VALUES (?,?,?,?,?,?,?) ON CONFLICT (name) DO NOTHING`,
+			b.ID, b.Name, b.Description, b.DepartmentID, b.Role, b.CreatedBy, now())
+	} else {
+		res, err = tx.ExecContext(ctx,
+			`UPDATE OR IGNORE policy_bundles SET name = ?, description = ?, department_id = ?, role = ? WHERE id = ?`,
+			b.Name, b.Description, b.DepartmentID, b.Role, b.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrBundleNameTaken
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM policy_bundle_items WHERE bundle_id = ?`, b.ID); err != nil {
+		return err
+	}
+	for i, id := range policyIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO policy_bundle_items (bundle_id, policy_id, position) VALUES (?,?,?)`, b.ID, id, i,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteBundle removes a bundle. Assignments it made stay in place.
+func (db *DB) DeleteBundle(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM policy_bundles WHERE id = ?`, id)
+	return err
+}
+
+// ListBundleAssignments returns who a bundle has been applied to, newest
+// first.
+func (db *DB) ListBundleAssignments(ctx context.Context, bundleID string) ([]*BundleAssignment, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT ba.bundle_id, ba.user_id, u.name, u.email, ba.assigned_by, ba.assigned_at
+		 FROM policy_bundle_assignments ba JOIN users u ON u.id = ba.user_id
+		 WHERE ba.bundle_id = ? ORDER BY ba.assigned_at DESC, u.name`, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*BundleAssignment
+	for rows.Next() {
+		a := &BundleAssignment{}
+		var assignedBy sql.NullString
+		var assignedAt string
+		if err := rows.Scan(&a.BundleID, &a.UserID, &a.UserName, &a.UserEmail, &assignedBy, &assignedAt); err != nil {
+			return nil, err
+		}
+		a.AssignedBy = nullString(assignedBy)
+		a.AssignedAt = parseTime(assignedAt)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// AssignBundle requires each of the bundle's policies of user and records
+// that the bundle was applied, reporting false if it already had been.
+//
+// Policies are assigned to the user directly, except where they already
+// apply: assigning a policy that has no assignments would otherwise narrow
+// it from everyone who can see it to this one user. A department policy
+// without assignments is first assigned to its own department for the same
+// reason.
+func (db *DB) AssignBundle(ctx context.Context, bundle *PolicyBundle, user *User, assignedBy *string) (bool, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	ts := now()
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO policy_bundle_assignments (bundle_id, user_id, assigned_by, assigned_at)
+		 VALUES (?,?,?,?) ON CONFLICT (bundle_id, user_id) DO NOTHING`,
+		bundle.ID, user.ID, assignedBy, ts)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	assign := func(policyID, targetType, targetID string) error {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO policy_assignments (id, policy_id, target_type, target_id, created_by, created_at)
+			 VALUES (?,?,?,?,?,?) ON CONFLICT(policy_id, target_type, target_id) DO NOTHING`,
+			uuid.New().String(), policyID, targetType, targetID, assignedBy, ts)
+		return err
+	}
+	for _, bp := range bundle.Policies {
+		var visibility string
+		var deptID sql.NullString
+		var assigned, matches bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT p.visibility_type, p.department_id,
+			        EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id),
+			        EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id AND `+assignmentMatch+`)
+			 FROM policies p WHERE p.id = ?`,
+			user.ID, user.Role, deref(user.DepartmentID), bp.ID,
+		).Scan(&visibility, &deptID, &assigned, &matches); err != nil {
+			return false, err
+		}
+		if matches {
+			continue
+		}
+		if !assigned {
+			if visibility != "department" || (deptID.Valid && deptID.String == deref(user.DepartmentID)) {
+				continue // already required of the user
+			}
+			if deptID.Valid {
+				if err := assign(bp.ID, AssignDepartment, deptID.String); err != nil {
+					return false, err
+				}
+			}
+		}
+		if err := assign(bp.ID, AssignUser, user.ID); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// AssignOnboardingBundles applies every bundle matching a newly created
+// user's department and role, returning the bundles applied.
+func (db *DB) AssignOnboardingBundles(ctx context.Context, user *User) ([]*PolicyBundle, error) {
+	bundles, err := db.queryBundles(ctx,
+		bundleSelect+` WHERE (b.department_id IS NULL OR b.department_id = ?) AND (b.role IS NULL OR b.role = ?)
+		 ORDER BY b.name`,
+		deref(user.DepartmentID), user.Role)
+	if err != nil {
+		return nil, err
+	}
+	var applied []*PolicyBundle
+	for _, b := range bundles {
+		ok, err := db.AssignBundle(ctx, b, user, nil)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied = append(applied, b)
+		}
+	}
+	return applied, nil
+}
@@ -0,0 +1,388 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Campaign audiences.
+const (
+	AudienceOrganization = "organization"
+	AudienceDepartment   = "department"
+	AudienceRole         = "role"
+)
+
+// Campaign statuses, from the dates.
+const (
+	CampaignScheduled = "scheduled"
+	CampaignActive    = "active"
+	CampaignEnded     = "ended"
+)
+
+// Campaign asks an audience to acknowledge a set of policies between
+// StartsAt and Deadline, such as the annual policy refresh. It does not
+// change who must acknowledge a policy: a user counts towards a campaign for
+// the policies that are required of them and that they are in the audience
+// for.
+type Campaign struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	StartsAt     time.Time         `json:"starts_at"`
+	Deadline     time.Time         `json:"deadline"`
+	AudienceType string            `json:"audience_type"`
+	AudienceID   *string           `json:"audience_id"` // department ID or role; nil for the organization
+	AudienceName string            `json:"audience_name"`
+	ReminderDays []int             `json:"reminder_days"` // days before the deadline, largest first
+	Status       string            `json:"status"`
+	Policies     []*CampaignPolicy `json:"policies"`
+	CreatedBy    *string           `json:"created_by"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// CampaignPolicy is a policy in a campaign, in campaign order.
+type CampaignPolicy struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// ErrCampaignNameTaken is returned when another campaign has the name.
+var ErrCampaignNameTaken = errors.New("campaign name already in use")
+
+const campaignSelect = `SELECT c.id, c.name, c.description, c.starts_at, c.deadline, c.audience_type, c.audience_id,
+	CASE c.audience_type WHEN 'organization' THEN 'Everyone' WHEN 'department' THEN COALESCE(d.name, '(deleted department)')
+	ELSE c.audience_id END,
+	c.reminder_days, c.created_by, c.created_at
+	FROM campaigns c LEFT JOIN departments d ON c.audience_type = 'department' AND d.id = c.audience_id`
+
+func (db *DB) queryCampaigns(ctx context.Context, query string, args ...any) ([]*Campaign, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Campaign
+	byID := map[string]*Campaign{}
+	now := time.Now()
+	for rows.Next() {
+		c := &Campaign{Policies: []*CampaignPolicy{}, ReminderDays: []int{}}
+		var startsAt, deadline, reminderDays, createdAt string
+		var audienceID, createdBy sql.NullString
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &startsAt, &deadline, &c.AudienceType, &audienceID,
+			&c.AudienceName, &reminderDays, &createdBy, &createdAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c.StartsAt, c.Deadline, c.CreatedAt = parseTime(startsAt), parseTime(deadline), parseTime(createdAt)
+		c.AudienceID, c.CreatedBy = nullString(audienceID), nullString(createdBy)
+		for _, d := range strings.Split(reminderDays, ",") {
+			if n, err := strconv.Atoi(d); err == nil {
+				c.ReminderDays = append(c.ReminderDays, n)
+			}
+		}
+		c.Status = c.StatusAt(now)
+		out = append(out, c)
+		byID[c.ID] = c
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(out) == 0 {
+		return out, err
+	}
+
+	ids := make([]string, len(out))
+	for i, c := range out {
+		ids[i] = c.ID
+	}
+	rows, err = db.conn.QueryContext(ctx,
+		`SELECT cp.campaign_id, p.id, p.title, p.status FROM campaign_policies cp JOIN policies p ON p.id = cp.policy_id
+		 WHERE cp.campaign_id IN (`+placeholders(len(ids))+`) ORDER BY cp.position`, anySlice(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var campaignID string
+		p := &CampaignPolicy{}
+		if err := rows.Scan(&campaignID, &p.ID, &p.Title, &p.Status); err != nil {
+			return nil, err
+		}
+		byID[campaignID].Policies = append(byID[campaignID].Policies, p)
+	}
+	return out, rows.Err()
+}
+
+// StatusAt is the campaign's status at t.
+func (c *Campaign) StatusAt(t time.Time) string {
+	switch {
+	case t.Before(c.StartsAt):
+		return CampaignScheduled
+	case t.Before(c.Deadline):
+		return CampaignActive
+	}
+	return CampaignEnded
+}
+
+// ListCampaigns returns every campaign, the latest deadline first.
+func (db *DB) ListCampaigns(ctx context.Context) ([]*Campaign, error) {
+	return db.queryCampaigns(ctx, campaignSelect+` ORDER BY c.deadline DESC, c.name`)
+}
+
+// ListActiveCampaigns returns the campaigns that have started and whose
+// deadline has not passed at now.
+func (db *DB) ListActiveCampaigns(ctx context.Context, now time.Time) ([]*Campaign, error) {
+	ts := now.UTC().Format(time.RFC3339)
+	return db.queryCampaigns(ctx, campaignSelect+` WHERE c.starts_at <= ? AND c.deadline > ? ORDER BY c.deadline`, ts, ts)
+}
+
+func (db *DB) GetCampaign(ctx context.Context, id string) (*Campaign, error) {
+	campaigns, err := db.queryCampaigns(ctx, campaignSelect+` WHERE c.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(campaigns) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return campaigns[0], nil
+}
+
+// SaveCampaign creates the campaign when c.ID is empty and updates it
+// otherwise, replacing its policies with policyIDs in order.
+func (db *DB) SaveCampaign(ctx context.Context, c *Campaign, policyIDs []string) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	days := slices.Clone(c.ReminderDays)
+	slices.Sort(days)
+	slices.Reverse(days)
+	c.ReminderDays = slices.Compact(days)
+	reminderDays := make([]string, len(c.ReminderDays))
+	for i, d := range c.ReminderDays {
+		reminderDays[i] = strconv.Itoa(d)
+	}
+	startsAt, deadline := c.StartsAt.UTC().Format(time.RFC3339), c.Deadline.UTC().Format(time.RFC3339)
+
+	var res sql.Result
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+		res, err = tx.ExecContext(ctx,
+			`INSERT INTO campaigns (id, name, description, starts_at, deadline, audience_type, audience_id, reminder_days, created_by, created_at)
+			 VALUES (?,?,?,?,?,?,?,?,?,?) ON CONFLICT (name) DO NOTHING`,
+			c.ID, c.Name, c.Description, startsAt, deadline, c.AudienceType, c.AudienceID,
+			strings.Join(reminderDays, ","), c.CreatedBy, now())
+	} else {
+		res, err = tx.ExecContext(ctx,
+			`UPDATE OR IGNORE campaigns SET name = ?, description = ?, starts_at = ?, deadline = ?, audience_type = ?,
+			 audience_id = ?, reminder_days = ? WHERE id = ?`,
+			c.Name, c.Description, startsAt, deadline, c.AudienceType, c.AudienceID, strings.Join(reminderDays, ","), c.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCampaignNameTaken
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM campaign_policies WHERE campaign_id = ?`, c.ID); err != nil {
+		return err
+	}
+	for i, id := range policyIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO campaign_policies (campaign_id, policy_id, position) VALUES (?,?,?)`, c.ID, id, i,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteCampaign removes a campaign and its reminder log. Acknowledgements
+// given during it are kept.
+func (db *DB) DeleteCampaign(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM campaigns WHERE id = ?`, id)
+	return err
+}
+
+// campaignPairs selects one row per active audience user and published
+// campaign policy required of them, with the time they acknowledged its
+// current version or NULL. Bind args: campaignID.
+const campaignPairs = `SELECT u.id, u.name, u.email, u.department_id, COALESCE(d.name, ''),
+	       p.id, p.title, ak.timestamp
+	FROM campaigns c
+	JOIN campaign_policies cp ON cp.campaign_id = c.id
+	JOIN policies p ON p.id = cp.policy_id AND p.status = 'Published'
+	JOIN users u ON u.deactivated_at IS NULL
+	     AND (c.audience_type = 'organization'
+	          OR (c.audience_type = 'department' AND u.department_id = c.audience_id)
+	          OR (c.audience_type = 'role' AND u.role = c.audience_id))
+	     AND ` + requiredForUser + `
+	LEFT JOIN acknowledgements ak ON ak.user_id = u.id AND ak.policy_version_id = p.current_version_id
+	LEFT JOIN departments d ON d.id = u.department_id
+	WHERE c.id = ?`
+
+// CampaignPolicyProgress counts a policy's audience and acknowledgements.
+type CampaignPolicyProgress struct {
+	PolicyID     string `json:"policy_id"`
+	PolicyTitle  string `json:"policy_title"`
+	Required     int    `json:"required"`
+	Acknowledged int    `json:"acknowledged"`
+}
+
+// CampaignDepartmentProgress counts a department's users, those who have
+// finished, and its acknowledgements.
+type CampaignDepartmentProgress struct {
+	DepartmentID   *string `json:"department_id"`
+	Department     string  `json:"department"`
+	Users          int     `json:"users"`
+	CompletedUsers int     `json:"completed_users"`
+	Required       int     `json:"required"`
+	Acknowledged   int     `json:"acknowledged"`
+}
+
+// CampaignProgress is where a campaign stands. Users counts the audience
+// members with at least one campaign policy required of them, and
+// CompletedUsers those who have acknowledged all of theirs. Late counts
+// acknowledgements given after the deadline.
+type CampaignProgress struct {
+	CampaignID     string                        `json:"campaign_id"`
+	Status         string                        `json:"status"`
+	Deadline       time.Time                     `json:"deadline"`
+	Users          int                           `json:"users"`
+	CompletedUsers int                           `json:"completed_users"`
+	Required       int                           `json:"required"`
+	Acknowledged   int                           `json:"acknowledged"`
+	Late           int                           `json:"late"`
+	Percent        float64                       `json:"percent"`
+	ByPolicy       []*CampaignPolicyProgress     `json:"by_policy"`
+	ByDepartment   []*CampaignDepartmentProgress `json:"by_department"`
+	GeneratedAt    time.Time                     `json:"generated_at"`
+}
+
+// GetCampaignProgress counts c's acknowledgements as of now, only among
+// users in deptID when it is set. Policies keep campaign order and
+// departments are by name.
+func (db *DB) GetCampaignProgress(ctx context.Context, c *Campaign, deptID *string) (*CampaignProgress, error) {
+	query, args := campaignPairs, []any{c.ID}
+	if deptID != nil {
+		query += ` AND u.department_id = ?`
+		args = append(args, *deptID)
+	}
+	rows, err := db.conn.QueryContext(ctx, query+` ORDER BY d.name, u.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now().UTC()
+	pr := &CampaignProgress{CampaignID: c.ID, Status: c.StatusAt(now), Deadline: c.Deadline, GeneratedAt: now,
+		ByPolicy: []*CampaignPolicyProgress{}, ByDepartment: []*CampaignDepartmentProgress{}}
+	policies := map[string]*CampaignPolicyProgress{}
+	for _, p := range c.Policies {
+		policies[p.ID] = &CampaignPolicyProgress{PolicyID: p.ID, PolicyTitle: p.Title}
+	}
+	departments := map[string]*CampaignDepartmentProgress{}
+	outstanding := map[string]bool{} // user ID → has an unacknowledged policy
+	userDept := map[string]string{}
+	for rows.Next() {
+		var userID, name, email, department, policyID, title string
+		var userDeptID, ackedAt sql.NullString
+		if err := rows.Scan(&userID, &name, &email, &userDeptID, &department, &policyID, &title, &ackedAt); err != nil {
+			return nil, err
+		}
+		dp := departments[userDeptID.String]
+		if dp == nil {
+			dp = &CampaignDepartmentProgress{DepartmentID: nullString(userDeptID), Department: department}
+			departments[userDeptID.String] = dp
+			pr.ByDepartment = append(pr.ByDepartment, dp)
+		}
+		if _, seen := userDept[userID]; !seen {
+			userDept[userID] = userDeptID.String
+			dp.Users++
+			pr.Users++
+		}
+		pp := policies[policyID]
+		pr.Required++
+		pp.Required++
+		dp.Required++
+		if !ackedAt.Valid {
+			outstanding[userID] = true
+			continue
+		}
+		pr.Acknowledged++
+		pp.Acknowledged++
+		dp.Acknowledged++
+		if parseTime(ackedAt.String).After(c.Deadline) {
+			pr.Late++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for userID, dept := range userDept {
+		if !outstanding[userID] {
+			pr.CompletedUsers++
+			departments[dept].CompletedUsers++
+		}
+	}
+	for _, p := range c.Policies {
+		pr.ByPolicy = append(pr.ByPolicy, policies[p.ID])
+	}
+	if pr.Required > 0 {
+		pr.Percent = float64(pr.Acknowledged*1000/pr.Required) / 10
+	}
+	return pr, nil
+}
+
+// CampaignTodo is an audience member with campaign policies still to
+// acknowledge, in campaign order.
+type CampaignTodo struct {
+	UserID    string            `json:"user_id"`
+	UserName  string            `json:"user_name"`
+	UserEmail string            `json:"user_email"`
+	Policies  []*CampaignPolicy `json:"policies"`
+}
+
+// ListCampaignTodos returns the audience members who have not acknowledged
+// every campaign policy required of them and have not yet been sent
+// reminder stage, by name.
+func (db *DB) ListCampaignTodos(ctx context.Context, c *Campaign, stage string) ([]*CampaignTodo, error) {
+	rows, err := db.conn.QueryContext(ctx, campaignPairs+` AND ak.id IS NULL
+		AND NOT EXISTS (SELECT 1 FROM campaign_reminders r WHERE r.campaign_id = c.id AND r.user_id = u.id AND r.stage = ?)
+		ORDER BY u.name, u.id, cp.position`, c.ID, stage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*CampaignTodo
+	for rows.Next() {
+		var userID, name, email, department, policyID, title string
+		var deptID, ackedAt sql.NullString
+		if err := rows.Scan(&userID, &name, &email, &deptID, &department, &policyID, &title, &ackedAt); err != nil {
+			return nil, err
+		}
+		if len(out) == 0 || out[len(out)-1].UserID != userID {
+			out = append(out, &CampaignTodo{UserID: userID, UserName: name, UserEmail: email})
+		}
+		t := out[len(out)-1]
+		t.Policies = append(t.Policies, &CampaignPolicy{ID: policyID, Title: title, Status: "Published"})
+	}
+	return out, rows.Err()
+}
+
+// RecordCampaignReminder notes that the user was sent reminder stage of a
+// campaign.
+func (db *DB) RecordCampaignReminder(ctx context.Context, campaignID, userID, stage string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO campaign_reminders (campaign_id, user_id, stage, sent_at) VALUES (?,?,?,?)
+		 ON CONFLICT (campaign_id, user_id, stage) DO NOTHING`,
+		campaignID, userID, stage, now())
+	return err
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Change request statuses.
+const (
+	ChangeRequestOpen      = "open"
+	ChangeRequestResolved  = "resolved"
+	ChangeRequestDismissed = "dismissed"
+)
+
+// ChangeRequest is a reviewer's request to change one section of a policy
+// under review. Open requests block publishing.
+type ChangeRequest struct {
+	ID              string     `json:"id"`
+	PolicyID        string     `json:"policy_id"`
+	PolicyVersionID *string    `json:"policy_version_id"` // version the reviewer was reading
+	Section         string     `json:"section"`
+	Comment         string     `json:"comment"`
+	Status          string     `json:"status"`
+	CreatedBy       *string    `json:"created_by"`
+	CreatedByName   *string    `json:"created_by_name"`
+	ResolvedBy      *string    `json:"resolved_by"`
+	ResolvedByName  *string    `json:"resolved_by_name"`
+	ResolutionNote  string     `json:"resolution_note"`
+	ResolvedAt      *time.Time `json:"resolved_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+const changeRequestSelect = `SELECT c.id, c.policy_id, c.policy_version_id, c.section, c.comment, c.status,
+	c.created_by, cu.name, c.resolved_by, ru.name, c.resolution_note, c.resolved_at, c.created_at
+	FROM change_requests c
+	LEFT JOIN users cu ON cu.id = c.created_by
+	LEFT JOIN users ru ON ru.id = c.resolved_by`
+
+func (db *DB) CreateChangeRequest(ctx context.Context, policyID string, versionID *string, section, comment string, createdBy *string) (*ChangeRequest, error) {
+	id := uuid.New().String()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO change_requests (id, policy_id, policy_version_id, section, comment, status, created_by, created_at)
+		 VALUES (?,?,?,?,?,?,?,?)`,
+		id, policyID, versionID, section, comment, ChangeRequestOpen, createdBy, now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetChangeRequest(ctx, id)
+}
+
+func (db *DB) GetChangeRequest(ctx context.Context, id string) (*ChangeRequest, error) {
+	return db.scanChangeRequest(db.conn.QueryRowContext(ctx, changeRequestSelect+` WHERE c.id = ?`, id))
+}
+
+// ListChangeRequests returns a policy's change requests, oldest first,
+// optionally only those with status.
+func (db *DB) ListChangeRequests(ctx context.Context, policyID, status string) ([]*ChangeRequest, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		changeRequestSelect+` WHERE c.policy_id = ? AND (? = '' OR c.status = ?) ORDER BY c.created_at`,
+		policyID, status, status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*ChangeRequest
+	for rows.Next() {
+		cr, err := db.scanChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cr)
+	}
+	return out, rows.Err()
+}
+
+// CountOpenChangeRequests returns how many of a policy's change requests are
+// still open.
+func (db *DB) CountOpenChangeRequests(ctx context.Context, policyID string) (int, error) {
+	var n int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM change_requests WHERE policy_id = ? AND status = 'open'`, policyID,
+	).Scan(&n)
+	return n, err
+}
+
+// CloseChangeRequest marks an open change request resolved or dismissed. It
+// returns sql.ErrNoRows if the request is no longer open.
+func (db *DB) CloseChangeRequest(ctx context.Context, id, status, closedBy, note string) error {
+	res, err := db.conn.ExecContext(ctx,
+		`UPDATE change_requests SET status = ?, resolved_by = ?, resolution_note = ?, resolved_at = ?
+		 WHERE id = ? AND status = 'open'`,
+		status, closedBy, note, now(), id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (db *DB) scanChangeRequest(row scanner) (*ChangeRequest, error) {
+	cr := &ChangeRequest{}
+	var versionID, createdBy, createdByName, resolvedBy, resolvedByName, resolvedAt sql.NullString
+	var createdAt string
+	if err := row.Scan(&cr.ID, &cr.PolicyID, &versionID, &cr.Section, &cr.Comment, &cr.Status,
+		&createdBy, &createdByName, &resolvedBy, &resolvedByName, &cr.ResolutionNote, &resolvedAt, &createdAt); err != nil {
+		return nil, err
+	}
+	cr.PolicyVersionID = nullString(versionID)
+	cr.CreatedBy = nullString(createdBy)
+	cr.CreatedByName = nullString(createdByName)
+	cr.ResolvedBy = nullString(resolvedBy)
+	cr.ResolvedByName = nullString(resolvedByName)
+	if resolvedAt.Valid {
+		t := parseTime(resolvedAt.String)
+		cr.ResolvedAt = &t
+	}
+	cr.CreatedAt = parseTime(createdAt)
+	return cr, nil
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attributes of an employee record a claim rule can match.
+const (
+	ClaimDepartment = "department" // the department name the HR system reports
+	ClaimOU         = "ou"         // organizational unit or division
+	ClaimJobTitle   = "job_title"
+	ClaimGroup      = "group" // matches when any of the employee's groups does
+)
+
+// How a claim rule compares the attribute with its value. All but
+// ClaimRegex ignore case.
+const (
+	ClaimEquals   = "equals"
+	ClaimContains = "contains"
+	ClaimPrefix   = "prefix"
+	ClaimRegex    = "regex"
+)
+
+// ClaimRule assigns a role, a department, or both to users provisioned
+// from the HR system whose attribute matches. Rules are tried in Position
+// order; for each of role and department the first matching rule that sets
+// it wins.
+type ClaimRule struct {
+	ID             string    `json:"id"`
+	Attribute      string    `json:"attribute"`
+	Operator       string    `json:"operator"`
+	Value          string    `json:"value"`
+	Role           *string   `json:"role"`          // nil = leave the role to other rules
+	DepartmentID   *string   `json:"department_id"` // nil = leave the department to other rules
+	DepartmentName *string   `json:"department_name"`
+	Position       int       `json:"position"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+const claimRuleSelect = `SELECT r.id, r.attribute, r.operator, r.value, r.role, r.department_id, d.name, r.position, r.created_at
+	FROM claim_rules r LEFT JOIN departments d ON d.id = r.department_id`
+
+func scanClaimRule(row interface{ Scan(...any) error }) (*ClaimRule, error) {
+	r := &ClaimRule{}
+	var createdAt string
+	if err := row.Scan(&r.ID, &r.Attribute, &r.Operator, &r.Value, &r.Role, &r.DepartmentID, &r.DepartmentName,
+		&r.Position, &createdAt); err != nil {
+		return nil, err
+	}
+	r.CreatedAt = parseTime(createdAt)
+	return r, nil
+}
+
+// ListClaimRules returns the claim rules in the order they are tried.
+func (db *DB) ListClaimRules(ctx context.Context) ([]*ClaimRule, error) {
+	rows, err := db.conn.QueryContext(ctx, claimRuleSelect+` ORDER BY r.position, r.created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*ClaimRule
+	for rows.Next() {
+		r, err := scanClaimRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (db *DB) GetClaimRule(ctx context.Context, id string) (*ClaimRule, error) {
+	return scanClaimRule(db.conn.QueryRowContext(ctx, claimRuleSelect+` WHERE r.id = ?`, id))
+}
+
+// CreateClaimRule adds a rule after the existing ones.
+func (db *DB) CreateClaimRule(ctx context.Context, r *ClaimRule) error {
+	r.ID = uuid.New().String()
+	if _, err := db.conn.ExecContext(ctx,
+		`INSERT INTO claim_rules (id, attribute, operator, value, role, department_id, position, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), -1) + 1 FROM claim_rules), ?)`,
+		r.ID, r.Attribute, r.Operator, r.Value, r.Role, r.DepartmentID, now(),
+	); err != nil {
+		return err
+	}
+	created, err := db.GetClaimRule(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+	*r = *created
+	return nil
+}
+
+// UpdateClaimRule saves every field of r but its creation time.
+func (db *DB) UpdateClaimRule(ctx context.Context, r *ClaimRule) error {
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE claim_rules SET attribute = ?, operator = ?, value = ?, role = ?, department_id = ?, position = ? WHERE id = ?`,
+		r.Attribute, r.Operator, r.Value, r.Role, r.DepartmentID, r.Position, r.ID)
+	return err
+}
+
+func (db *DB) DeleteClaimRule(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM claim_rules WHERE id = ?`, id)
+	return err
+}
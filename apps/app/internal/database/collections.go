@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Collection is an ordered sequence of policies, such as an employee
+// handbook, that staff read through from start to finish.
+type Collection struct {
+	ID          string              `json:"id"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Position    int                 `json:"position"`
+	Policies    []*CollectionPolicy `json:"policies"`
+	CreatedBy   *string             `json:"created_by"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// CollectionPolicy is a policy in a collection, in reading order.
+type CollectionPolicy struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+const collectionSelect = `SELECT id, title, description, position, created_by, created_at, updated_at FROM collections`
+
+func (db *DB) queryCollections(ctx context.Context, query string, args ...any) ([]*Collection, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Collection
+	byID := map[string]*Collection{}
+	for rows.Next() {
+		col := &Collection{Policies: []*CollectionPolicy{}}
+		var createdBy sql.NullString
+		var createdAt, updatedAt string
+		if err := rows.Scan(&col.ID, &col.Title, &col.Description, &col.Position,
+			&createdBy, &createdAt, &updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		col.CreatedBy = nullString(createdBy)
+		col.CreatedAt, col.UpdatedAt = parseTime(createdAt), parseTime(updatedAt)
+		out = append(out, col)
+		byID[col.ID] = col
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(out) == 0 {
+		return out, err
+	}
+
+	ids := make([]string, len(out))
+	for i, col := range out {
+		ids[i] = col.ID
+	}
+	rows, err = db.conn.QueryContext(ctx,
+		`SELECT i.collection_id, p.id, p.title, p.status FROM collection_items i JOIN policies p ON p.id = i.policy_id
+		 WHERE i.collection_id IN (`+placeholders(len(ids))+`) ORDER BY i.position`, anySlice(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var collectionID string
+		p := &CollectionPolicy{}
+		if err := rows.Scan(&collectionID, &p.ID, &p.Title, &p.Status); err != nil {
+			return nil, err
+		}
+		byID[collectionID].Policies = append(byID[collectionID].Policies, p)
+	}
+	return out, rows.Err()
+}
+
+// ListCollections returns every collection in display order.
+func (db *DB) ListCollections(ctx context.Context) ([]*Collection, error) {
+	return db.queryCollections(ctx, collectionSelect+` ORDER BY position, title`)
+}
+
+func (db *DB) GetCollection(ctx context.Context, id string) (*Collection, error) {
+	cols, err := db.queryCollections(ctx, collectionSelect+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return cols[0], nil
+}
+
+// SaveCollection creates the collection when col.ID is empty, placing it
+// after the existing ones, and updates it otherwise. Its policies are
+// replaced with policyIDs in reading order.
+func (db *DB) SaveCollection(ctx context.Context, col *Collection, policyIDs []string) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ts := now()
+	if col.ID == "" {
+		col.ID = uuid.New().String()
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO collections (id, title, description, position, created_by, created_at, updated_at)
+			 VALUES (?,?,?,(SELECT COALESCE(MAX(position), -1) + 1 FROM collections),?,?,?)`,
+			col.ID, col.Title, col.Description, col.CreatedBy, ts, ts)
+	} else {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE collections SET title = ?, description = ?, position = ?, updated_at = ? WHERE id = ?`,
+			col.Title, col.Description, col.Position, ts, col.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM collection_items WHERE collection_id = ?`, col.ID); err != nil {
+		return err
+	}
+	for i, id := range policyIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO collection_items (collection_id, policy_id, position) VALUES (?,?,?)`, col.ID, id, i,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteCollection removes a collection; its policies are unaffected.
+func (db *DB) DeleteCollection(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM collections WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// Acknowledgement matrix cell states.
+const (
+	AckDone        = "acknowledged"
+	AckPending     = "pending"
+	AckOverdue     = "overdue"
+	AckNotRequired = ""
+)
+
+// AckMatrix is every active user against every policy at least one of them
+// must acknowledge. Cells[i][j] is Users[i]'s state for Policies[j].
+type AckMatrix struct {
+	Policies []*Policy      `json:"policies"`
+	Users    []*User        `json:"users"`
+	Cells    [][]MatrixCell `json:"cells"`
+}
+
+// MatrixCell is one user's state for one policy's current version.
+// ExceptionUntil is set while the user has an approved exception to the
+// policy.
+type MatrixCell struct {
+	Status         string     `json:"status"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ExceptionUntil *time.Time `json:"exception_until,omitempty"`
+}
+
+// DepartmentCompliance summarizes a department's row of the matrix.
+type DepartmentCompliance struct {
+	DepartmentID  *string `json:"department_id"`
+	Department    string  `json:"department"`
+	Users         int     `json:"users"`
+	Required      int     `json:"required"`
+	Acknowledged  int     `json:"acknowledged"`
+	Pending       int     `json:"pending"`
+	Overdue       int     `json:"overdue"`
+	Exceptions    int     `json:"active_exceptions"`
+	AckPercentage float64 `json:"ack_percentage"`
+}
+
+// BuildAckMatrix computes the acknowledgement matrix for active users, only
+// those in deptID when it is set. Users are ordered by department then name,
+// policies by title.
+func (db *DB) BuildAckMatrix(ctx context.Context, deptID *string, now time.Time) (*AckMatrix, error) {
+	all, err := db.ListActiveUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var users []*User
+	for _, u := range all {
+		if deptID == nil || (u.DepartmentID != nil && *u.DepartmentID == *deptID) {
+			users = append(users, u)
+		}
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		di, dj := deref(users[i].DepartmentName), deref(users[j].DepartmentName)
+		if di != dj {
+			return di < dj
+		}
+		return users[i].Name < users[j].Name
+	})
+
+	policies := map[string]*Policy{}
+	required := make([]map[string]bool, len(users))
+	acked := make([]map[string]time.Time, len(users))
+	for i, u := range users {
+		ps, err := db.ListRequiredPoliciesForUser(ctx, u.ID, u.Role, u.DepartmentID)
+		if err != nil {
+			return nil, err
+		}
+		required[i] = map[string]bool{}
+		for _, p := range ps {
+			if p.CurrentVersionID == nil {
+				continue
+			}
+			policies[p.ID] = p
+			required[i][p.ID] = true
+		}
+		acks, err := db.ListUserAcknowledgements(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		acked[i] = map[string]time.Time{}
+		for _, a := range acks {
+			acked[i][a.PolicyVersionID] = a.Timestamp
+		}
+	}
+
+	exceptions, err := db.ActiveExceptions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	grace, err := db.NewHireGraceDays(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &AckMatrix{Policies: make([]*Policy, 0, len(policies)), Users: users}
+	for _, p := range policies {
+		m.Policies = append(m.Policies, p)
+	}
+	sort.Slice(m.Policies, func(i, j int) bool { return m.Policies[i].Title < m.Policies[j].Title })
+	if m.Users == nil {
+		m.Users = []*User{}
+	}
+
+	m.Cells = make([][]MatrixCell, len(users))
+	for i, u := range users {
+		m.Cells[i] = make([]MatrixCell, len(m.Policies))
+		for j, p := range m.Policies {
+			if !required[i][p.ID] {
+				continue
+			}
+			if t, ok := acked[i][*p.CurrentVersionID]; ok {
+				m.Cells[i][j] = MatrixCell{Status: AckDone, AcknowledgedAt: &t}
+			} else if d := GraceDeadline(p.AckDeadline, u.CreatedAt, grace); d != nil && now.After(*d) {
+				m.Cells[i][j] = MatrixCell{Status: AckOverdue}
+			} else {
+				m.Cells[i][j] = MatrixCell{Status: AckPending}
+			}
+			if t, ok := exceptions[u.ID][p.ID]; ok {
+				m.Cells[i][j].ExceptionUntil = &t
+			}
+		}
+	}
+	return m, nil
+}
+
+// ByDepartment totals the matrix per department, in the order departments
+// first appear. Users without a department are grouped under "No department".
+func (m *AckMatrix) ByDepartment() []*DepartmentCompliance {
+	var out []*DepartmentCompliance
+	index := map[string]*DepartmentCompliance{}
+	for i, u := range m.Users {
+		key := deref(u.DepartmentID)
+		d, ok := index[key]
+		if !ok {
+			d = &DepartmentCompliance{DepartmentID: u.DepartmentID, Department: UserDepartment(u)}
+			index[key] = d
+			out = append(out, d)
+		}
+		d.Users++
+		for _, cell := range m.Cells[i] {
+			if cell.ExceptionUntil != nil {
+				d.Exceptions++
+			}
+			switch cell.Status {
+			case AckDone:
+				d.Acknowledged++
+			case AckPending:
+				d.Pending++
+			case AckOverdue:
+				d.Overdue++
+			default:
+				continue
+			}
+			d.Required++
+		}
+	}
+	for _, d := range out {
+		if d.Required > 0 {
+			d.AckPercentage = math.Round(float64(d.Acknowledged)*1000/float64(d.Required)) / 10
+		}
+	}
+	return out
+}
+
+// UserDepartment is the department name shown for u in reports.
+func UserDepartment(u *User) string {
+	if u.DepartmentName == nil || *u.DepartmentName == "" {
+		return "No department"
+	}
+	return *u.DepartmentName
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Custom field types.
+const (
+	FieldText   = "text"
+	FieldDate   = "date"   // YYYY-MM-DD
+	FieldSelect = "select" // one of Options
+)
+
+// CustomField is an organization-defined policy attribute such as
+// "Regulatory basis". Key names it in API responses and filters and does
+// not change; Label is what people see.
+type CustomField struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	Label     string    `json:"label"`
+	Type      string    `json:"type"`
+	Options   []string  `json:"options"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ErrFieldKeyTaken is returned when another custom field has the key.
+var ErrFieldKeyTaken = errors.New("custom field key already in use")
+
+const customFieldSelect = `SELECT id, key, label, type, options, position, created_at FROM custom_fields`
+
+func scanCustomField(row interface{ Scan(...any) error }) (*CustomField, error) {
+	f := &CustomField{}
+	var options, createdAt string
+	if err := row.Scan(&f.ID, &f.Key, &f.Label, &f.Type, &options, &f.Position, &createdAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(options), &f.Options); err != nil {
+		return nil, err
+	}
+	f.CreatedAt = parseTime(createdAt)
+	return f, nil
+}
+
+// ListCustomFields returns the custom fields in display order.
+func (db *DB) ListCustomFields(ctx context.Context) ([]*CustomField, error) {
+	rows, err := db.conn.QueryContext(ctx, customFieldSelect+` ORDER BY position, created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*CustomField
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+func (db *DB) GetCustomField(ctx context.Context, id string) (*CustomField, error) {
+	return scanCustomField(db.conn.QueryRowContext(ctx, customFieldSelect+` WHERE id = ?`, id))
+}
+
+// CreateCustomField adds a field at the end of the display order.
+func (db *DB) CreateCustomField(ctx context.Context, f *CustomField) error {
+	options, err := json.Marshal(f.Options)
+	if err != nil {
+		return err
+	}
+	f.ID = uuid.New().String()
+	ts := now()
+	res, err := db.conn.ExecContext(ctx,
+		`INSERT INTO custom_fields (id, key, label, type, options, position, created_at)
+		 VALUES (?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), -1) + 1 FROM custom_fields), ?)
+		 ON CONFLICT (key) DO NOTHING`,
+		f.ID, f.Key, f.Label, f.Type, string(options), ts,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrFieldKeyTaken
+	}
+	created, err := db.GetCustomField(ctx, f.ID)
+	if err != nil {
+		return err
+	}
+	*f = *created
+	return nil
+}
+
+// UpdateCustomField changes a field's label, options, and position.
+func (db *DB) UpdateCustomField(ctx context.Context, f *CustomField) error {
+	options, err := json.Marshal(f.Options)
+	if err != nil {
+		return err
+	}
+	_, err = db.conn.ExecContext(ctx,
+		`UPDATE custom_fields SET label = ?, options = ?, position = ? WHERE id = ?`,
+		f.Label, string(options), f.Position, f.ID)
+	return err
+}
+
+// DeleteCustomField removes a field and every policy's value for it.
+func (db *DB) DeleteCustomField(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM custom_fields WHERE id = ?`, id)
+	return err
+}
+
+// CustomFieldValuesInUse returns the distinct values policies hold for a
+// field.
+func (db *DB) CustomFieldValuesInUse(ctx context.Context, fieldID string) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT DISTINCT value FROM policy_field_values WHERE field_id = ?`, fieldID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// SetPolicyFieldValues sets a policy's values by field ID; an empty value
+// clears the field. Fields not named are left alone.
+func (db *DB) SetPolicyFieldValues(ctx context.Context, policyID string, values map[string]string) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for fieldID, v := range values {
+		if v == "" {
+			_, err = tx.ExecContext(ctx, `DELETE FROM policy_field_values WHERE policy_id = ? AND field_id = ?`, policyID, fieldID)
+		} else {
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO policy_field_values (policy_id, field_id, value) VALUES (?,?,?)
+				 ON CONFLICT (policy_id, field_id) DO UPDATE SET value = excluded.value`,
+				policyID, fieldID, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -1,8 +1,11 @@
 package database
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,21 +14,39 @@ import (
 
 // DB wraps the SQL database and provides all query methods.
 type DB struct {
-	conn *sql.DB
+	conn  *sql.DB
+	read  *DB        // read-only view for heavy queries; nil means use conn
+	users *userCache // see GetUserByIDCached; nil on the read view
 }
 
 func New(conn *sql.DB) *DB {
-	return &DB{conn: conn}
+	return &DB{conn: conn, users: newUserCache()}
+}
+
+// SetReadPool routes Reader to a separate read-only pool. In WAL mode its
+// connections read a consistent snapshot without waiting on the single
+// writer connection, so long reports and exports don't hold up writes.
+func (db *DB) SetReadPool(pool *sql.DB) {
+	db.read = &DB{conn: pool}
+}
+
+// Reader returns the DB to use for heavy read-only queries: the read pool
+// when one is set, otherwise db itself. Never write through it.
+func (db *DB) Reader() *DB {
+	if db.read == nil {
+		return db
+	}
+	return db.read
 }
 
 // Init creates base tables and configures SQLite pragmas.
-func (db *DB) Init() error {
+func (db *DB) Init(ctx context.Context) error {
 	pragmas := `
 PRAGMA journal_mode = WAL;
 PRAGMA foreign_keys = ON;
 PRAGMA busy_timeout = 5000;
 `
-	if _, err := db.conn.Exec(pragmas); err != nil {
+	if _, err := db.conn.ExecContext(ctx, pragmas); err != nil {
 		return fmt.Errorf("pragmas: %w", err)
 	}
 
@@ -70,12 +91,19 @@ CREATE TABLE IF NOT EXISTS acknowledgements (
 	FOREIGN KEY (policy_version_id) REFERENCES policy_versions(id)
 );
 `
-	if _, err := db.conn.Exec(schema); err != nil {
+	if _, err := db.conn.ExecContext(ctx, schema); err != nil {
 		return fmt.Errorf("schema: %w", err)
 	}
 	return nil
 }
 
+// Backup writes a consistent copy of the database to path, which must not
+// exist yet.
+func (db *DB) Backup(ctx context.Context, path string) error {
+	_, err := db.conn.ExecContext(ctx, `VACUUM INTO ?`, path)
+	return err
+}
+
 // parseTime tries multiple formats to robustly parse timestamps stored in SQLite.
 func parseTime(s string) time.Time {
 	for _, format := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05Z"} {
@@ -86,6 +114,14 @@ func parseTime(s string) time.Time {
 	return time.Time{}
 }
 
+// nullString converts a nullable column to a *string.
+func nullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
 // now returns the current UTC time formatted as RFC3339 for consistent SQLite storage.
 func now() string {
 	return time.Now().UTC().Format(time.RFC3339)
@@ -102,35 +138,71 @@ type Department struct {
 }
 
 type User struct {
-	ID             string    `json:"id"`
-	Email          string    `json:"email"`
-	Name           string    `json:"name"`
-	Role           string    `json:"role"`
-	CreatedBy      *string   `json:"created_by,omitempty"`
-	DepartmentID   *string   `json:"department_id"`
-	DepartmentName *string   `json:"department_name"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID             string     `json:"id"`
+	Email          string     `json:"email"`
+	Name           string     `json:"name"`
+	Role           string     `json:"role"`
+	CreatedBy      *string    `json:"created_by,omitempty"`
+	DepartmentID   *string    `json:"department_id"`
+	DepartmentName *string    `json:"department_name"`
+	ManagerID      *string    `json:"manager_id"`
+	ExternalID     *string    `json:"external_id,omitempty"`    // HRIS employee ID
+	DeactivatedAt  *time.Time `json:"deactivated_at,omitempty"` // set for leavers; blocks login
+	LastLoginAt    *time.Time `json:"last_login_at"`
+	AnonymizedAt   *time.Time `json:"anonymized_at,omitempty"` // personal data erased; acknowledgements kept
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
 type Policy struct {
-	ID               string    `json:"id"`
-	Title            string    `json:"title"`
-	CurrentVersionID *string   `json:"current_version_id,omitempty"`
-	Status           string    `json:"status"`
-	Department       string    `json:"department"` // legacy text field
-	DepartmentID     *string   `json:"department_id"`
-	DepartmentName   *string   `json:"department_name"`
-	VisibilityType   string    `json:"visibility_type"`
-	CreatedAt        time.Time `json:"created_at"`
+	ID               string     `json:"id"`
+	Title            string     `json:"title"`
+	CurrentVersionID *string    `json:"current_version_id,omitempty"`
+	Status           string     `json:"status"`
+	Department       string     `json:"department"` // Deprecated: legacy text; use DepartmentID
+	DepartmentID     *string    `json:"department_id"`
+	DepartmentName   *string    `json:"department_name"`
+	VisibilityType   string     `json:"visibility_type"`
+	AckDeadline      *time.Time `json:"ack_deadline"`
+	Public           bool       `json:"public"`  // listed on the public portal when enabled
+	Version          int        `json:"version"` // bumped on every change; used for optimistic locking
+	CreatedBy        *string    `json:"created_by"`
+	CreatedByName    *string    `json:"created_by_name"`
+	UpdatedBy        *string    `json:"updated_by"`
+	UpdatedByName    *string    `json:"updated_by_name"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// CustomFields holds the policy's values for the organization's custom
+	// fields, by field key. Fields without a value are absent.
+	CustomFields map[string]string `json:"custom_fields"`
 }
 
 type PolicyVersion struct {
-	ID            string    `json:"id"`
-	PolicyID      string    `json:"policy_id"`
-	Content       string    `json:"content"`
-	VersionString string    `json:"version_string"`
-	Changelog     string    `json:"changelog"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            string     `json:"id"`
+	PolicyID      string     `json:"policy_id"`
+	Content       string     `json:"content"`
+	VersionString string     `json:"version_string"`
+	Changelog     string     `json:"changelog"`
+	PublishedAt   *time.Time `json:"published_at"`   // first time this version went live
+	EffectiveFrom *time.Time `json:"effective_from"` // nil = effective once current
+	EffectiveTo   *time.Time `json:"effective_to"`   // nil = no expiry
+	CreatedBy     *string    `json:"created_by"`
+	CreatedByName *string    `json:"created_by_name"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	// Source is the uploaded original the version was made from, when it is
+	// a file such as a PDF; Content then holds its extracted text.
+	Source *VersionSource `json:"source,omitempty"`
+}
+
+// VersionSource is a version's original file, stored in blob storage under
+// StorageKey.
+type VersionSource struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	SHA256      string `json:"sha256"`
+	StorageKey  string `json:"-"`
 }
 
 type Acknowledgement struct {
@@ -139,8 +211,15 @@ type Acknowledgement struct {
 	PolicyVersionID string    `json:"policy_version_id"`
 	Timestamp       time.Time `json:"timestamp"`
 	SignatureHash   string    `json:"signature_hash"`
+	Source          string    `json:"source"` // AckSourceApp or AckSourceImported
 }
 
+// Where an acknowledgement was given.
+const (
+	AckSourceApp      = "app"      // in PolicyFlow
+	AckSourceImported = "imported" // on paper or in an earlier system; see ImportAcknowledgements
+)
+
 // ─── scanner helper ────────────────────────────────────────────────────────
 
 type scanner interface {
@@ -149,14 +228,14 @@ type scanner interface {
 
 // ─── Department queries ────────────────────────────────────────────────────
 
-func (db *DB) CreateDepartment(name, description string) (*Department, error) {
+func (db *DB) CreateDepartment(ctx context.Context, name, description string) (*Department, error) {
 	d := &Department{
 		ID:          uuid.New().String(),
 		Name:        name,
 		Description: description,
 	}
 	ts := now()
-	_, err := db.conn.Exec(
+	_, err := db.conn.ExecContext(ctx,
 		`INSERT INTO departments (id, name, description, created_at, updated_at) VALUES (?,?,?,?,?)`,
 		d.ID, d.Name, d.Description, ts, ts,
 	)
@@ -168,20 +247,20 @@ func (db *DB) CreateDepartment(name, description string) (*Department, error) {
 	return d, nil
 }
 
-func (db *DB) GetDepartment(id string) (*Department, error) {
-	return db.scanDepartment(db.conn.QueryRow(
+func (db *DB) GetDepartment(ctx context.Context, id string) (*Department, error) {
+	return db.scanDepartment(db.conn.QueryRowContext(ctx,
 		`SELECT id, name, description, created_at, updated_at FROM departments WHERE id = ?`, id,
 	))
 }
 
-func (db *DB) GetDepartmentByName(name string) (*Department, error) {
-	return db.scanDepartment(db.conn.QueryRow(
+func (db *DB) GetDepartmentByName(ctx context.Context, name string) (*Department, error) {
+	return db.scanDepartment(db.conn.QueryRowContext(ctx,
 		`SELECT id, name, description, created_at, updated_at FROM departments WHERE name = ?`, name,
 	))
 }
 
-func (db *DB) ListDepartments() ([]*Department, error) {
-	rows, err := db.conn.Query(
+func (db *DB) ListDepartments(ctx context.Context) ([]*Department, error) {
+	rows, err := db.conn.QueryContext(ctx,
 		`SELECT id, name, description, created_at, updated_at FROM departments ORDER BY name ASC`,
 	)
 	if err != nil {
@@ -200,26 +279,91 @@ func (db *DB) ListDepartments() ([]*Department, error) {
 	return depts, rows.Err()
 }
 
-func (db *DB) UpdateDepartment(id, name, description string) (*Department, error) {
+func (db *DB) UpdateDepartment(ctx context.Context, id, name, description string) (*Department, error) {
 	ts := now()
-	_, err := db.conn.Exec(
+	_, err := db.conn.ExecContext(ctx,
 		`UPDATE departments SET name=?, description=?, updated_at=? WHERE id=?`,
 		name, description, ts, id,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return db.GetDepartment(id)
+	return db.GetDepartment(ctx, id)
 }
 
-func (db *DB) DeleteDepartment(id string) error {
-	_, err := db.conn.Exec(`DELETE FROM departments WHERE id=?`, id)
+func (db *DB) DeleteDepartment(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM departments WHERE id=?`, id)
 	return err
 }
 
-func (db *DB) DepartmentHasPolicies(id string) (bool, error) {
+// DepartmentReassignment counts what DeleteDepartmentReassigning moved.
+type DepartmentReassignment struct {
+	Policies int64 `json:"policies"`
+	Users    int64 `json:"users"`
+}
+
+// DeleteDepartmentReassigning moves a department's policies, users,
+// department assignments, report schedules, and bundles to another
+// department, then deletes it, all in one transaction. Moved policies are
+// attributed to updatedBy and their version is bumped.
+func (db *DB) DeleteDepartmentReassigning(ctx context.Context, id, to string, updatedBy *string) (*DepartmentReassignment, error) {
+	defer db.users.reset()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	out := &DepartmentReassignment{}
+	res, err := tx.ExecContext(ctx,
+		`UPDATE policies SET department_id=?, version=version+1, updated_by=COALESCE(?, updated_by), updated_at=?
+		 WHERE department_id=?`,
+		to, updatedBy, now(), id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if out.Policies, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+	if res, err = tx.ExecContext(ctx, `UPDATE users SET department_id=? WHERE department_id=?`, to, id); err != nil {
+		return nil, err
+	}
+	if out.Users, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+	// A policy already assigned to both departments keeps the one assignment,
+	// and likewise an admin already granted both; the leftovers cascade.
+	for _, q := range []string{
+		`UPDATE OR IGNORE policy_assignments SET target_id=? WHERE target_type='department' AND target_id=?`,
+		`UPDATE OR IGNORE department_admins SET department_id=? WHERE department_id=?`,
+		`UPDATE report_schedules SET department_id=? WHERE department_id=?`,
+		`UPDATE policy_bundles SET department_id=? WHERE department_id=?`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, to, id); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM policy_assignments WHERE target_type='department' AND target_id=?`, id); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM departments WHERE id=?`, id); err != nil {
+		return nil, err
+	}
+	return out, tx.Commit()
+}
+
+func (db *DB) DepartmentHasUsers(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM users WHERE department_id=?`, id,
+	).Scan(&count)
+	return count > 0, err
+}
+
+func (db *DB) DepartmentHasPolicies(ctx context.Context, id string) (bool, error) {
 	var count int
-	err := db.conn.QueryRow(
+	err := db.conn.QueryRowContext(ctx,
 		`SELECT COUNT(*) FROM policies WHERE department_id=?`, id,
 	).Scan(&count)
 	return count > 0, err
@@ -238,7 +382,7 @@ func (db *DB) scanDepartment(row scanner) (*Department, error) {
 
 // ─── User queries ──────────────────────────────────────────────────────────
 
-func (db *DB) CreateUser(email, name, role string, createdBy *string, departmentID *string) (*User, error) {
+func (db *DB) CreateUser(ctx context.Context, email, name, role string, createdBy *string, departmentID *string) (*User, error) {
 	u := &User{
 		ID:           uuid.New().String(),
 		Email:        email,
@@ -248,7 +392,7 @@ func (db *DB) CreateUser(email, name, role string, createdBy *string, department
 		DepartmentID: departmentID,
 	}
 	ts := now()
-	_, err := db.conn.Exec(
+	_, err := db.conn.ExecContext(ctx,
 		`INSERT INTO users (id, email, name, role, created_by, department_id, created_at) VALUES (?,?,?,?,?,?,?)`,
 		u.ID, u.Email, u.Name, u.Role, u.CreatedBy, u.DepartmentID, ts,
 	)
@@ -259,66 +403,197 @@ func (db *DB) CreateUser(email, name, role string, createdBy *string, department
 	return u, nil
 }
 
-func (db *DB) UpdateUser(id, name, email, role string, departmentID *string) error {
-	_, err := db.conn.Exec(
+// UpdateUser saves a user's profile. A user whose role is no longer
+// DeptAdmin loses any departments granted to them.
+func (db *DB) UpdateUser(ctx context.Context, id, name, email, role string, departmentID *string) error {
+	defer db.users.reset()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx,
 		`UPDATE users SET name=?, email=?, role=?, department_id=? WHERE id=?`,
 		name, email, role, departmentID, id,
+	); err != nil {
+		return err
+	}
+	if role != "DeptAdmin" {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM department_admins WHERE user_id=?`, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteUser removes a user and their activity rows. It fails while the user
+// has acknowledgements; anonymize them instead to keep that evidence. When
+// transferTo is set, what they own moves to that user first (see
+// transferOwnership) and the returned transfer says how much; otherwise it
+// is nil.
+func (db *DB) DeleteUser(ctx context.Context, id, transferTo string) (*OwnershipTransfer, error) {
+	defer db.users.reset()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	var moved *OwnershipTransfer
+	if transferTo != "" {
+		if moved, err = transferOwnership(ctx, tx, id, transferTo); err != nil {
+			return nil, err
+		}
+	}
+	if err := detachUser(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id=?`, id); err != nil {
+		return nil, err
+	}
+	return moved, tx.Commit()
+}
+
+// AnonymizeUser erases a user's personal data for an erasure request while
+// keeping the account row, so their acknowledgements and signature hashes
+// remain valid compliance evidence. Name and email become a pseudonym
+// derived from the ID, the account is deactivated, and activity that is not
+// evidence (read events, reminders, assignments) is removed. transferTo
+// works as for DeleteUser.
+func (db *DB) AnonymizeUser(ctx context.Context, id, transferTo string) (*OwnershipTransfer, error) {
+	defer db.users.reset()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	var moved *OwnershipTransfer
+	if transferTo != "" {
+		if moved, err = transferOwnership(ctx, tx, id, transferTo); err != nil {
+			return nil, err
+		}
+	}
+	if err := detachUser(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	pseudonym := "anon-" + fmt.Sprintf("%x", sha256.Sum256([]byte(id)))[:12]
+	ts := now()
+	res, err := tx.ExecContext(ctx,
+		`UPDATE users SET name=?, email=?, external_id=NULL, manager_id=NULL, last_login_at=NULL,
+		 deactivated_at=COALESCE(deactivated_at, ?), anonymized_at=?
+		 WHERE id=? AND anonymized_at IS NULL`,
+		"Former user "+pseudonym, pseudonym+"@anonymized.invalid", ts, ts, id,
 	)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return moved, tx.Commit()
+}
+
+// detachUser removes the rows that reference a user but are not compliance
+// evidence, and unlinks their direct reports and the accounts and
+// assignments they created.
+func detachUser(ctx context.Context, tx *sql.Tx, id string) error {
+	for _, q := range []string{
+		`UPDATE users SET manager_id=NULL WHERE manager_id=?`,
+		`UPDATE users SET created_by=NULL WHERE created_by=?`,
+		`UPDATE policy_assignments SET created_by=NULL WHERE created_by=?`,
+		`DELETE FROM policy_read_events WHERE user_id=?`,
+		`DELETE FROM reminder_log WHERE user_id=?`,
+		`DELETE FROM reminder_snoozes WHERE user_id=?`,
+		`DELETE FROM login_events WHERE user_id=?`,
+		`DELETE FROM policy_assignments WHERE target_type='user' AND target_id=?`,
+		`UPDATE policy_bundle_assignments SET assigned_by=NULL WHERE assigned_by=?`,
+		`DELETE FROM policy_bundle_assignments WHERE user_id=?`,
+		`DELETE FROM publish_notifications WHERE user_id=?`,
+		`DELETE FROM department_welcomes WHERE user_id=?`,
+		`DELETE FROM campaign_reminders WHERE user_id=?`,
+		`UPDATE campaigns SET created_by=NULL WHERE created_by=?`,
+		`UPDATE policy_faqs SET created_by=NULL WHERE created_by=?`,
+		`UPDATE policy_faqs SET updated_by=NULL WHERE updated_by=?`,
+		`UPDATE policy_training SET updated_by=NULL WHERE updated_by=?`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, id); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (db *DB) DeleteUser(id string) error {
-	_, err := db.conn.Exec(`DELETE FROM users WHERE id=?`, id)
+// CountUserAcknowledgements returns how many acknowledgements a user has.
+func (db *DB) CountUserAcknowledgements(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM acknowledgements WHERE user_id=?`, userID).Scan(&n)
+	return n, err
+}
+
+// RecordLogin stamps the user's last successful sign-in.
+func (db *DB) RecordLogin(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE users SET last_login_at=? WHERE id=?`, now(), id)
 	return err
 }
 
-func (db *DB) CountSuperAdmins() (int, error) {
+func (db *DB) CountSuperAdmins(ctx context.Context) (int, error) {
 	var count int
-	err := db.conn.QueryRow(`SELECT COUNT(*) FROM users WHERE role='SuperAdmin'`).Scan(&count)
+	err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role='SuperAdmin'`).Scan(&count)
 	return count, err
 }
 
-func (db *DB) GetUserByID(id string) (*User, error) {
-	return db.scanUser(db.conn.QueryRow(
-		`SELECT u.id, u.email, u.name, u.role, u.created_by, u.department_id, d.name, u.created_at
-		 FROM users u LEFT JOIN departments d ON u.department_id = d.id WHERE u.id = ?`, id,
-	))
+// userSelect is the column list shared by all user queries; keep it in step
+// with scanUser.
+const userSelect = `SELECT u.id, u.email, u.name, u.role, u.created_by, u.department_id, d.name, u.manager_id,
+	u.external_id, u.deactivated_at, u.last_login_at, u.anonymized_at, u.created_at
+	FROM users u LEFT JOIN departments d ON u.department_id = d.id`
+
+func (db *DB) GetUserByID(ctx context.Context, id string) (*User, error) {
+	return db.scanUser(db.conn.QueryRowContext(ctx, userSelect+` WHERE u.id = ?`, id))
 }
 
-func (db *DB) GetUserByEmail(email string) (*User, error) {
-	return db.scanUser(db.conn.QueryRow(
-		`SELECT u.id, u.email, u.name, u.role, u.created_by, u.department_id, d.name, u.created_at
-		 FROM users u LEFT JOIN departments d ON u.department_id = d.id WHERE u.email = ?`, email,
-	))
+func (db *DB) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	return db.scanUser(db.conn.QueryRowContext(ctx, userSelect+` WHERE u.email = ?`, email))
 }
 
-func (db *DB) ListUsers() ([]*User, error) {
-	rows, err := db.conn.Query(
-		`SELECT u.id, u.email, u.name, u.role, u.created_by, u.department_id, d.name, u.created_at
-		 FROM users u LEFT JOIN departments d ON u.department_id = d.id ORDER BY u.created_at ASC`,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+func (db *DB) ListUsers(ctx context.Context) ([]*User, error) {
+	return db.queryUsers(ctx, userSelect+` ORDER BY u.created_at ASC`)
+}
 
-	var users []*User
-	for rows.Next() {
-		u, err := db.scanUser(rows)
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, u)
-	}
-	return users, rows.Err()
+// ListActiveUsers returns all users that have not been deactivated.
+func (db *DB) ListActiveUsers(ctx context.Context) ([]*User, error) {
+	return db.queryUsers(ctx, userSelect+` WHERE u.deactivated_at IS NULL ORDER BY u.created_at ASC`)
 }
 
-func (db *DB) ListUsersByDepartment(deptID string) ([]*User, error) {
-	rows, err := db.conn.Query(
-		`SELECT u.id, u.email, u.name, u.role, u.created_by, u.department_id, d.name, u.created_at
-		 FROM users u LEFT JOIN departments d ON u.department_id = d.id
-		 WHERE u.department_id = ? ORDER BY u.created_at ASC`, deptID,
-	)
+// ListInactiveUsers returns active users who have not signed in since
+// cutoff, counting accounts that never signed in from their creation so new
+// invitees are not reported straight away. Those who never signed in come
+// first, then the longest idle. deptID limits the list to one department.
+func (db *DB) ListInactiveUsers(ctx context.Context, cutoff time.Time, deptID *string) ([]*User, error) {
+	return db.queryUsers(ctx, userSelect+`
+		WHERE u.deactivated_at IS NULL
+		  AND COALESCE(u.last_login_at, u.created_at) < ?
+		  AND (? IS NULL OR u.department_id = ?)
+		ORDER BY u.last_login_at IS NOT NULL, COALESCE(u.last_login_at, u.created_at) ASC`,
+		cutoff.UTC().Format(time.RFC3339), deptID, deptID)
+}
+
+func (db *DB) ListUsersByDepartment(ctx context.Context, deptID string) ([]*User, error) {
+	return db.queryUsers(ctx, userSelect+` WHERE u.department_id = ? ORDER BY u.created_at ASC`, deptID)
+}
+
+// ListDirectReports returns the users whose manager is managerID.
+func (db *DB) ListDirectReports(ctx context.Context, managerID string) ([]*User, error) {
+	return db.queryUsers(ctx, userSelect+` WHERE u.manager_id = ? AND u.deactivated_at IS NULL ORDER BY u.name ASC`, managerID)
+}
+
+// SetUserManager sets or clears (nil) a user's line manager.
+func (db *DB) SetUserManager(ctx context.Context, id string, managerID *string) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE users SET manager_id=? WHERE id=?`, managerID, id)
+	return err
+}
+
+func (db *DB) queryUsers(ctx context.Context, query string, args ...any) ([]*User, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -337,12 +612,31 @@ func (db *DB) ListUsersByDepartment(deptID string) ([]*User, error) {
 
 func (db *DB) scanUser(row scanner) (*User, error) {
 	u := &User{}
-	var createdBy, deptID, deptName sql.NullString
+	var createdBy, deptID, deptName, managerID, externalID, deactivatedAt, lastLoginAt, anonymizedAt sql.NullString
 	var createdAt string
-	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.Role, &createdBy, &deptID, &deptName, &createdAt)
+	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.Role, &createdBy, &deptID, &deptName, &managerID,
+		&externalID, &deactivatedAt, &lastLoginAt, &anonymizedAt, &createdAt)
 	if err != nil {
 		return nil, err
 	}
+	if lastLoginAt.Valid {
+		t := parseTime(lastLoginAt.String)
+		u.LastLoginAt = &t
+	}
+	if anonymizedAt.Valid {
+		t := parseTime(anonymizedAt.String)
+		u.AnonymizedAt = &t
+	}
+	if externalID.Valid {
+		u.ExternalID = &externalID.String
+	}
+	if deactivatedAt.Valid {
+		t := parseTime(deactivatedAt.String)
+		u.DeactivatedAt = &t
+	}
+	if managerID.Valid {
+		u.ManagerID = &managerID.String
+	}
 	if createdBy.Valid {
 		u.CreatedBy = &createdBy.String
 	}
@@ -358,7 +652,7 @@ func (db *DB) scanUser(row scanner) (*User, error) {
 
 // ─── Policy queries ────────────────────────────────────────────────────────
 
-func (db *DB) CreatePolicy(title, department string, departmentID *string, visibilityType string) (*Policy, error) {
+func (db *DB) CreatePolicy(ctx context.Context, title, department string, departmentID *string, visibilityType string, createdBy *string) (*Policy, error) {
 	p := &Policy{
 		ID:             uuid.New().String(),
 		Title:          title,
@@ -366,50 +660,72 @@ func (db *DB) CreatePolicy(title, department string, departmentID *string, visib
 		DepartmentID:   departmentID,
 		VisibilityType: visibilityType,
 		Status:         "Draft",
+		CreatedBy:      createdBy,
+		UpdatedBy:      createdBy,
 	}
 	ts := now()
-	_, err := db.conn.Exec(
-		`INSERT INTO policies (id, title, department, department_id, visibility_type, status, created_at) VALUES (?,?,?,?,?,?,?)`,
-		p.ID, p.Title, p.Department, p.DepartmentID, p.VisibilityType, p.Status, ts,
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policies (id, title, department, department_id, visibility_type, status, version, created_by, updated_by, created_at, updated_at)
+		 VALUES (?,?,?,?,?,?,1,?,?,?,?)`,
+		p.ID, p.Title, p.Department, p.DepartmentID, p.VisibilityType, p.Status, createdBy, createdBy, ts, ts,
 	)
 	if err != nil {
 		return nil, err
 	}
+	p.Version = 1
 	p.CreatedAt = parseTime(ts)
+	p.UpdatedAt = p.CreatedAt
 	return p, nil
 }
 
-func (db *DB) GetPolicy(id string) (*Policy, error) {
-	return db.scanPolicy(db.conn.QueryRow(
-		`SELECT p.id, p.title, p.current_version_id, p.status, p.department, p.department_id, d.name, p.visibility_type, p.created_at
-		 FROM policies p LEFT JOIN departments d ON p.department_id = d.id WHERE p.id = ?`, id,
-	))
+// policySelect is the column list shared by all policy queries; keep it in
+// step with scanPolicy.
+const policySelect = `SELECT p.id, p.title, p.current_version_id, p.status, p.department,
+	p.department_id, d.name, p.visibility_type, p.ack_deadline, p.is_public, p.version, p.created_by, cu.name, p.updated_by, uu.name,
+	p.created_at, p.updated_at,
+	(SELECT json_group_object(f.key, fv.value) FROM policy_field_values fv JOIN custom_fields f ON f.id = fv.field_id
+	 WHERE fv.policy_id = p.id)
+	FROM policies p
+	LEFT JOIN departments d ON p.department_id = d.id
+	LEFT JOIN users cu ON p.created_by = cu.id
+	LEFT JOIN users uu ON p.updated_by = uu.id`
+
+func (db *DB) GetPolicy(ctx context.Context, id string) (*Policy, error) {
+	return db.scanPolicy(db.conn.QueryRowContext(ctx, policySelect+` WHERE p.id = ?`, id))
 }
 
-// ListPoliciesForUser returns policies visible to the given role/department.
-// SuperAdmin sees all. Others see org-wide + their own department's policies.
-func (db *DB) ListPoliciesForUser(role string, deptID *string) ([]*Policy, error) {
-	var (
-		rows *sql.Rows
-		err  error
-	)
-	base := `SELECT p.id, p.title, p.current_version_id, p.status, p.department,
-	                p.department_id, d.name, p.visibility_type, p.created_at
-	         FROM policies p LEFT JOIN departments d ON p.department_id = d.id`
-
+// ListPoliciesForUser returns policies visible to the given user.
+// SuperAdmin sees all. Others see org-wide + their own department's policies,
+// plus any policy explicitly assigned to them.
+func (db *DB) ListPoliciesForUser(ctx context.Context, userID, role string, deptID *string) ([]*Policy, error) {
 	if role == "SuperAdmin" {
-		rows, err = db.conn.Query(base + ` ORDER BY p.created_at DESC`)
-	} else if deptID != nil {
-		rows, err = db.conn.Query(
-			base+` WHERE p.visibility_type = 'organization'
+		return db.queryPolicies(ctx, policySelect+` ORDER BY p.created_at DESC`)
+	}
+	if deptID != nil {
+		return db.queryPolicies(ctx,
+			policySelect+` WHERE p.visibility_type = 'organization'
 			            OR (p.visibility_type = 'department' AND p.department_id = ?)
+			            OR EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id AND `+assignmentMatch+`)
 			       ORDER BY p.created_at DESC`,
-			*deptID,
+			*deptID, userID, role, *deptID,
 		)
-	} else {
-		// No department — only org-wide policies.
-		rows, err = db.conn.Query(base + ` WHERE p.visibility_type = 'organization' ORDER BY p.created_at DESC`)
 	}
+	// No department — only org-wide and explicitly assigned policies.
+	return db.queryPolicies(ctx,
+		policySelect+` WHERE p.visibility_type = 'organization'
+		            OR EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id AND `+assignmentMatch+`)
+		       ORDER BY p.created_at DESC`,
+		userID, role, "",
+	)
+}
+
+// ListPolicies returns all policies (admin use — no visibility filter).
+func (db *DB) ListPolicies(ctx context.Context) ([]*Policy, error) {
+	return db.queryPolicies(ctx, policySelect+` ORDER BY p.created_at DESC`)
+}
+
+func (db *DB) queryPolicies(ctx context.Context, query string, args ...any) ([]*Policy, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -426,52 +742,114 @@ func (db *DB) ListPoliciesForUser(role string, deptID *string) ([]*Policy, error
 	return policies, rows.Err()
 }
 
-// ListPolicies returns all policies (admin use — no visibility filter).
-func (db *DB) ListPolicies() ([]*Policy, error) {
-	rows, err := db.conn.Query(
-		`SELECT p.id, p.title, p.current_version_id, p.status, p.department,
-		        p.department_id, d.name, p.visibility_type, p.created_at
-		 FROM policies p LEFT JOIN departments d ON p.department_id = d.id ORDER BY p.created_at DESC`,
+// ErrVersionConflict is returned when a policy was changed since the caller
+// last read it.
+var ErrVersionConflict = errors.New("policy was modified concurrently")
+
+func (db *DB) UpdatePolicy(ctx context.Context, id, title, status, department string, departmentID *string, visibilityType string) error {
+	return db.UpdatePolicyIfVersion(ctx, id, 0, nil, title, status, department, departmentID, visibilityType)
+}
+
+// UpdatePolicyIfVersion updates the policy only if its version counter still
+// equals expectedVersion (0 skips the check), returning ErrVersionConflict
+// otherwise. The counter is bumped on success. A nil updatedBy leaves the
+// recorded last updater unchanged.
+func (db *DB) UpdatePolicyIfVersion(ctx context.Context, id string, expectedVersion int, updatedBy *string, title, status, department string, departmentID *string, visibilityType string) error {
+	res, err := db.conn.ExecContext(ctx,
+		`UPDATE policies SET title=?, status=?, department=?, department_id=?, visibility_type=?,
+		        version=version+1, updated_by=COALESCE(?, updated_by), updated_at=?
+		 WHERE id=? AND (?=0 OR version=?)`,
+		title, status, department, departmentID, visibilityType, updatedBy, now(), id, expectedVersion, expectedVersion,
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
-
-	var policies []*Policy
-	for rows.Next() {
-		p, err := db.scanPolicy(rows)
-		if err != nil {
-			return nil, err
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if _, err := db.GetPolicy(ctx, id); err != nil {
+			return err
 		}
-		policies = append(policies, p)
+		return ErrVersionConflict
 	}
-	return policies, rows.Err()
+	return db.markCurrentVersionPublished(ctx, id)
 }
 
-func (db *DB) UpdatePolicy(id, title, status, department string, departmentID *string, visibilityType string) error {
-	_, err := db.conn.Exec(
-		`UPDATE policies SET title=?, status=?, department=?, department_id=?, visibility_type=? WHERE id=?`,
-		title, status, department, departmentID, visibilityType, id,
+// markCurrentVersionPublished stamps published_at on the policy's current
+// version the first time it is live (policy Published). No-op otherwise.
+func (db *DB) markCurrentVersionPublished(ctx context.Context, policyID string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE policy_versions SET published_at=?
+		 WHERE published_at IS NULL AND id = (
+		   SELECT current_version_id FROM policies WHERE id=? AND status='Published')`,
+		now(), policyID,
 	)
 	return err
 }
 
-func (db *DB) SetPolicyCurrentVersion(policyID, versionID string) error {
-	_, err := db.conn.Exec(
-		`UPDATE policies SET current_version_id=? WHERE id=?`, versionID, policyID,
-	)
+// SetPolicyAckDeadline sets or clears (nil) the acknowledgement deadline.
+func (db *DB) SetPolicyAckDeadline(ctx context.Context, id string, deadline *time.Time) error {
+	var v any
+	if deadline != nil {
+		v = deadline.UTC().Format(time.RFC3339)
+	}
+	_, err := db.conn.ExecContext(ctx, `UPDATE policies SET ack_deadline=? WHERE id=?`, v, id)
+	return err
+}
+
+// SetPolicyPublic marks whether the policy is listed on the public portal.
+func (db *DB) SetPolicyPublic(ctx context.Context, id string, public bool) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE policies SET is_public=? WHERE id=?`, public, id)
 	return err
 }
 
+// ListPublicPolicies returns the policies shown on the public portal: marked
+// public, published, organization-wide, and with a current version.
+func (db *DB) ListPublicPolicies(ctx context.Context) ([]*Policy, error) {
+	return db.queryPolicies(ctx, policySelect+`
+		WHERE p.is_public = 1 AND p.status = 'Published' AND p.visibility_type = 'organization'
+		  AND p.current_version_id IS NOT NULL
+		ORDER BY p.title`)
+}
+
+func (db *DB) SetPolicyCurrentVersion(ctx context.Context, policyID, versionID string) error {
+	ts := now()
+	if err := db.snapshotSupersededVersion(ctx, policyID, versionID, ts); err != nil {
+		return err
+	}
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE policies SET current_version_id=?, version=version+1, updated_at=?,
+		        updated_by=COALESCE((SELECT created_by FROM policy_versions WHERE id=?), updated_by)
+		 WHERE id=?`,
+		versionID, ts, versionID, policyID,
+	)
+	if err != nil {
+		return err
+	}
+	return db.markCurrentVersionPublished(ctx, policyID)
+}
+
 func (db *DB) scanPolicy(row scanner) (*Policy, error) {
 	p := &Policy{}
-	var cvID, deptID, deptName sql.NullString
-	var createdAt string
-	err := row.Scan(&p.ID, &p.Title, &cvID, &p.Status, &p.Department, &deptID, &deptName, &p.VisibilityType, &createdAt)
+	var cvID, deptID, deptName, deadline sql.NullString
+	var createdBy, createdByName, updatedBy, updatedByName sql.NullString
+	var createdAt, updatedAt, customFields string
+	err := row.Scan(&p.ID, &p.Title, &cvID, &p.Status, &p.Department, &deptID, &deptName, &p.VisibilityType, &deadline,
+		&p.Public, &p.Version, &createdBy, &createdByName, &updatedBy, &updatedByName, &createdAt, &updatedAt, &customFields)
 	if err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal([]byte(customFields), &p.CustomFields); err != nil {
+		return nil, err
+	}
+	p.CreatedBy = nullString(createdBy)
+	p.CreatedByName = nullString(createdByName)
+	p.UpdatedBy = nullString(updatedBy)
+	p.UpdatedByName = nullString(updatedByName)
+	if deadline.Valid {
+		t := parseTime(deadline.String)
+		p.AckDeadline = &t
+	}
 	if cvID.Valid {
 		p.CurrentVersionID = &cvID.String
 	}
@@ -482,23 +860,25 @@ func (db *DB) scanPolicy(row scanner) (*Policy, error) {
 		p.DepartmentName = &deptName.String
 	}
 	p.CreatedAt = parseTime(createdAt)
+	p.UpdatedAt = parseTime(updatedAt)
 	return p, nil
 }
 
 // ─── Policy version queries ────────────────────────────────────────────────
 
-func (db *DB) CreatePolicyVersion(policyID, content, versionString, changelog string) (*PolicyVersion, error) {
+func (db *DB) CreatePolicyVersion(ctx context.Context, policyID, content, versionString, changelog string, createdBy *string) (*PolicyVersion, error) {
 	v := &PolicyVersion{
 		ID:            uuid.New().String(),
 		PolicyID:      policyID,
 		Content:       content,
 		VersionString: versionString,
 		Changelog:     changelog,
+		CreatedBy:     createdBy,
 	}
 	ts := now()
-	_, err := db.conn.Exec(
-		`INSERT INTO policy_versions (id, policy_id, content, version_string, changelog, created_at) VALUES (?,?,?,?,?,?)`,
-		v.ID, v.PolicyID, v.Content, v.VersionString, v.Changelog, ts,
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_versions (id, policy_id, content, version_string, changelog, created_by, created_at) VALUES (?,?,?,?,?,?,?)`,
+		v.ID, v.PolicyID, v.Content, v.VersionString, v.Changelog, createdBy, ts,
 	)
 	if err != nil {
 		return nil, err
@@ -507,16 +887,20 @@ func (db *DB) CreatePolicyVersion(policyID, content, versionString, changelog st
 	return v, nil
 }
 
-func (db *DB) GetPolicyVersion(id string) (*PolicyVersion, error) {
-	return db.scanVersion(db.conn.QueryRow(
-		`SELECT id, policy_id, content, version_string, changelog, created_at FROM policy_versions WHERE id = ?`, id,
-	))
+// versionSelect is the column list shared by all version queries; keep it in
+// step with scanVersion.
+const versionSelect = `SELECT v.id, v.policy_id, v.content, v.version_string, v.changelog, v.published_at,
+	v.effective_from, v.effective_to, v.created_by, u.name, v.created_at,
+	v.source_filename, v.source_content_type, v.source_size, v.source_sha256, v.source_key
+	FROM policy_versions v LEFT JOIN users u ON v.created_by = u.id`
+
+func (db *DB) GetPolicyVersion(ctx context.Context, id string) (*PolicyVersion, error) {
+	return db.scanVersion(db.conn.QueryRowContext(ctx, versionSelect+` WHERE v.id = ?`, id))
 }
 
-func (db *DB) ListPolicyVersions(policyID string) ([]*PolicyVersion, error) {
-	rows, err := db.conn.Query(
-		`SELECT id, policy_id, content, version_string, changelog, created_at FROM policy_versions WHERE policy_id=? ORDER BY created_at DESC`,
-		policyID,
+func (db *DB) ListPolicyVersions(ctx context.Context, policyID string) ([]*PolicyVersion, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		versionSelect+` WHERE v.policy_id=? ORDER BY v.created_at DESC`, policyID,
 	)
 	if err != nil {
 		return nil, err
@@ -534,20 +918,170 @@ func (db *DB) ListPolicyVersions(policyID string) ([]*PolicyVersion, error) {
 	return versions, rows.Err()
 }
 
+// ErrVersionInUse is returned when deleting a version that is current or has
+// been acknowledged.
+var ErrVersionInUse = errors.New("policy version is current or acknowledged")
+
+// prunableVersion matches versions of a policy (alias v) that are not current
+// and have never been acknowledged.
+const prunableVersion = `v.id NOT IN (SELECT current_version_id FROM policies WHERE current_version_id IS NOT NULL)
+	AND NOT EXISTS (SELECT 1 FROM acknowledgements a WHERE a.policy_version_id = v.id)`
+
+// DeletePolicyVersion removes a prunable version along with its read events
+// and reminder history, returning ErrVersionInUse if it is not prunable.
+func (db *DB) DeletePolicyVersion(ctx context.Context, id string) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var ok bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM policy_versions v WHERE v.id = ? AND `+prunableVersion+`)`, id,
+	).Scan(&ok); err != nil {
+		return err
+	}
+	if !ok {
+		return ErrVersionInUse
+	}
+	for _, q := range []string{
+		`DELETE FROM policy_read_events WHERE policy_version_id = ?`,
+		`DELETE FROM reminder_log WHERE policy_version_id = ?`,
+		`DELETE FROM policy_versions WHERE id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetPolicyVersionEffective sets the window in which a version is in force;
+// nil leaves that end open.
+func (db *DB) SetPolicyVersionEffective(ctx context.Context, versionID string, from, to *time.Time) error {
+	var f, t any
+	if from != nil {
+		f = from.UTC().Format(time.RFC3339)
+	}
+	if to != nil {
+		t = to.UTC().Format(time.RFC3339)
+	}
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE policy_versions SET effective_from = ?, effective_to = ? WHERE id = ?`, f, t, versionID)
+	return err
+}
+
+// InEffect reports whether the version is in force at t. Both ends of the
+// window are inclusive.
+func (v *PolicyVersion) InEffect(t time.Time) bool {
+	return (v.EffectiveFrom == nil || !t.Before(*v.EffectiveFrom)) &&
+		(v.EffectiveTo == nil || !t.After(*v.EffectiveTo))
+}
+
+// SetPolicyVersionSource records the original file a version was made from.
+func (db *DB) SetPolicyVersionSource(ctx context.Context, versionID string, src *VersionSource) error {
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE policy_versions SET source_filename = ?, source_content_type = ?, source_size = ?, source_sha256 = ?, source_key = ?
+		 WHERE id = ?`,
+		src.Filename, src.ContentType, src.SizeBytes, src.SHA256, src.StorageKey, versionID,
+	)
+	return err
+}
+
+// PruneOldVersions deletes the oldest never-published, prunable versions of a
+// policy until at most keep versions remain. Published history and versions
+// backed by an uploaded original are never pruned automatically, the latter
+// so their stored file is never orphaned. It returns the number of versions
+// deleted.
+func (db *DB) PruneOldVersions(ctx context.Context, policyID string, keep int) (int, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT v.id FROM policy_versions v
+		 WHERE v.policy_id = ? AND v.published_at IS NULL AND v.source_key IS NULL AND `+prunableVersion+`
+		 ORDER BY v.created_at ASC`, policyID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var candidates []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		candidates = append(candidates, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var total int
+	if err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM policy_versions WHERE policy_id = ?`, policyID,
+	).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, id := range candidates {
+		if total-deleted <= keep {
+			break
+		}
+		if err := db.DeletePolicyVersion(ctx, id); err != nil {
+			if errors.Is(err, ErrVersionInUse) {
+				continue
+			}
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
 func (db *DB) scanVersion(row scanner) (*PolicyVersion, error) {
 	v := &PolicyVersion{}
+	var publishedAt, effectiveFrom, effectiveTo, createdBy, createdByName sql.NullString
+	var srcName, srcType, srcHash, srcKey sql.NullString
+	var srcSize sql.NullInt64
 	var createdAt string
-	err := row.Scan(&v.ID, &v.PolicyID, &v.Content, &v.VersionString, &v.Changelog, &createdAt)
+	err := row.Scan(&v.ID, &v.PolicyID, &v.Content, &v.VersionString, &v.Changelog, &publishedAt,
+		&effectiveFrom, &effectiveTo, &createdBy, &createdByName, &createdAt,
+		&srcName, &srcType, &srcSize, &srcHash, &srcKey)
 	if err != nil {
 		return nil, err
 	}
+	if srcKey.Valid {
+		v.Source = &VersionSource{
+			Filename:    srcName.String,
+			ContentType: srcType.String,
+			SizeBytes:   srcSize.Int64,
+			SHA256:      srcHash.String,
+			StorageKey:  srcKey.String,
+		}
+	}
+	v.CreatedBy = nullString(createdBy)
+	v.CreatedByName = nullString(createdByName)
+	if publishedAt.Valid {
+		t := parseTime(publishedAt.String)
+		v.PublishedAt = &t
+	}
+	if effectiveFrom.Valid {
+		t := parseTime(effectiveFrom.String)
+		v.EffectiveFrom = &t
+	}
+	if effectiveTo.Valid {
+		t := parseTime(effectiveTo.String)
+		v.EffectiveTo = &t
+	}
 	v.CreatedAt = parseTime(createdAt)
 	return v, nil
 }
 
 // ─── Acknowledgement queries ───────────────────────────────────────────────
 
-func (db *DB) CreateAcknowledgement(userID, policyVersionID string) (*Acknowledgement, error) {
+func (db *DB) CreateAcknowledgement(ctx context.Context, userID, policyVersionID string) (*Acknowledgement, error) {
 	ts := time.Now().UTC()
 	sig := fmt.Sprintf("%x", sha256.Sum256([]byte(userID+policyVersionID+ts.String())))
 	a := &Acknowledgement{
@@ -556,8 +1090,9 @@ func (db *DB) CreateAcknowledgement(userID, policyVersionID string) (*Acknowledg
 		PolicyVersionID: policyVersionID,
 		Timestamp:       ts,
 		SignatureHash:   sig,
+		Source:          AckSourceApp,
 	}
-	_, err := db.conn.Exec(
+	_, err := db.conn.ExecContext(ctx,
 		`INSERT INTO acknowledgements (id, user_id, policy_version_id, timestamp, signature_hash) VALUES (?,?,?,?,?)`,
 		a.ID, a.UserID, a.PolicyVersionID, ts.Format(time.RFC3339), a.SignatureHash,
 	)
@@ -567,18 +1102,18 @@ func (db *DB) CreateAcknowledgement(userID, policyVersionID string) (*Acknowledg
 	return a, nil
 }
 
-func (db *DB) HasAcknowledged(userID, policyVersionID string) (bool, error) {
+func (db *DB) HasAcknowledged(ctx context.Context, userID, policyVersionID string) (bool, error) {
 	var count int
-	err := db.conn.QueryRow(
+	err := db.conn.QueryRowContext(ctx,
 		`SELECT COUNT(*) FROM acknowledgements WHERE user_id=? AND policy_version_id=?`,
 		userID, policyVersionID,
 	).Scan(&count)
 	return count > 0, err
 }
 
-func (db *DB) ListAcknowledgements(policyVersionID string) ([]*Acknowledgement, error) {
-	rows, err := db.conn.Query(
-		`SELECT id, user_id, policy_version_id, timestamp, signature_hash FROM acknowledgements WHERE policy_version_id=? ORDER BY timestamp DESC`,
+func (db *DB) ListAcknowledgements(ctx context.Context, policyVersionID string) ([]*Acknowledgement, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT id, user_id, policy_version_id, timestamp, signature_hash, source FROM acknowledgements WHERE policy_version_id=? ORDER BY timestamp DESC`,
 		policyVersionID,
 	)
 	if err != nil {
@@ -590,7 +1125,7 @@ func (db *DB) ListAcknowledgements(policyVersionID string) ([]*Acknowledgement,
 	for rows.Next() {
 		a := &Acknowledgement{}
 		var ts string
-		if err := rows.Scan(&a.ID, &a.UserID, &a.PolicyVersionID, &ts, &a.SignatureHash); err != nil {
+		if err := rows.Scan(&a.ID, &a.UserID, &a.PolicyVersionID, &ts, &a.SignatureHash, &a.Source); err != nil {
 			return nil, err
 		}
 		a.Timestamp = parseTime(ts)
@@ -599,9 +1134,9 @@ func (db *DB) ListAcknowledgements(policyVersionID string) ([]*Acknowledgement,
 	return acks, rows.Err()
 }
 
-func (db *DB) ListUserAcknowledgements(userID string) ([]*Acknowledgement, error) {
-	rows, err := db.conn.Query(
-		`SELECT id, user_id, policy_version_id, timestamp, signature_hash FROM acknowledgements WHERE user_id=? ORDER BY timestamp DESC`,
+func (db *DB) ListUserAcknowledgements(ctx context.Context, userID string) ([]*Acknowledgement, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT id, user_id, policy_version_id, timestamp, signature_hash, source FROM acknowledgements WHERE user_id=? ORDER BY timestamp DESC`,
 		userID,
 	)
 	if err != nil {
@@ -613,7 +1148,7 @@ func (db *DB) ListUserAcknowledgements(userID string) ([]*Acknowledgement, error
 	for rows.Next() {
 		a := &Acknowledgement{}
 		var ts string
-		if err := rows.Scan(&a.ID, &a.UserID, &a.PolicyVersionID, &ts, &a.SignatureHash); err != nil {
+		if err := rows.Scan(&a.ID, &a.UserID, &a.PolicyVersionID, &ts, &a.SignatureHash, &a.Source); err != nil {
 			return nil, err
 		}
 		a.Timestamp = parseTime(ts)
@@ -625,30 +1160,106 @@ func (db *DB) ListUserAcknowledgements(userID string) ([]*Acknowledgement, error
 // ─── Admin stats ───────────────────────────────────────────────────────────
 
 type Stats struct {
-	TotalUsers     int `json:"total_users"`
-	TotalPolicies  int `json:"total_policies"`
-	PublishedCount int `json:"published_count"`
-	DraftCount     int `json:"draft_count"`
-	ReviewCount    int `json:"review_count"`
-	ArchivedCount  int `json:"archived_count"`
-	TotalAckCount  int `json:"total_acknowledgements"`
+	TotalUsers     int          `json:"total_users"`
+	TotalPolicies  int          `json:"total_policies"`
+	PublishedCount int          `json:"published_count"`
+	DraftCount     int          `json:"draft_count"`
+	ReviewCount    int          `json:"review_count"`
+	ArchivedCount  int          `json:"archived_count"`
+	TotalAckCount  int          `json:"total_acknowledgements"`
+	Period         *StatsPeriod `json:"period,omitempty"`
+}
+
+// StatsRange limits activity counts to [From, To). A zero bound is open.
+type StatsRange struct {
+	From, To time.Time
+}
+
+// args returns the range's bounds as query arguments, open ends widened to
+// cover every stored timestamp.
+func (r *StatsRange) args() (string, string) {
+	from, to := "", "9999"
+	if r != nil && !r.From.IsZero() {
+		from = r.From.UTC().Format(time.RFC3339)
+	}
+	if r != nil && !r.To.IsZero() {
+		to = r.To.UTC().Format(time.RFC3339)
+	}
+	return from, to
 }
 
-func (db *DB) GetStats() (*Stats, error) {
+// StatsPeriod counts the activity within a StatsRange.
+type StatsPeriod struct {
+	Acknowledgements int `json:"acknowledgements"`
+	Publishes        int `json:"publishes"`
+	NewUsers         int `json:"new_users"`
+}
+
+// GetStats returns the current totals and, when r is set, the
+// acknowledgements, version publishes, and accounts created within it.
+func (db *DB) GetStats(ctx context.Context, r *StatsRange) (*Stats, error) {
 	s := &Stats{}
-	db.conn.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&s.TotalUsers)
-	db.conn.QueryRow(`SELECT COUNT(*) FROM policies`).Scan(&s.TotalPolicies)
-	db.conn.QueryRow(`SELECT COUNT(*) FROM policies WHERE status='Published'`).Scan(&s.PublishedCount)
-	db.conn.QueryRow(`SELECT COUNT(*) FROM policies WHERE status='Draft'`).Scan(&s.DraftCount)
-	db.conn.QueryRow(`SELECT COUNT(*) FROM policies WHERE status='Review'`).Scan(&s.ReviewCount)
-	db.conn.QueryRow(`SELECT COUNT(*) FROM policies WHERE status='Archived'`).Scan(&s.ArchivedCount)
-	db.conn.QueryRow(`SELECT COUNT(*) FROM acknowledgements`).Scan(&s.TotalAckCount)
+	db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&s.TotalUsers)
+	db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM policies`).Scan(&s.TotalPolicies)
+	db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM policies WHERE status='Published'`).Scan(&s.PublishedCount)
+	db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM policies WHERE status='Draft'`).Scan(&s.DraftCount)
+	db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM policies WHERE status='Review'`).Scan(&s.ReviewCount)
+	db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM policies WHERE status='Archived'`).Scan(&s.ArchivedCount)
+	db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM acknowledgements`).Scan(&s.TotalAckCount)
+	if r == nil {
+		return s, nil
+	}
+	from, to := r.args()
+	s.Period = &StatsPeriod{}
+	err := db.conn.QueryRowContext(ctx, `SELECT
+		(SELECT COUNT(*) FROM acknowledgements WHERE timestamp >= ? AND timestamp < ?),
+		(SELECT COUNT(*) FROM policy_versions WHERE published_at >= ? AND published_at < ?),
+		(SELECT COUNT(*) FROM users WHERE created_at >= ? AND created_at < ?)`,
+		from, to, from, to, from, to,
+	).Scan(&s.Period.Acknowledgements, &s.Period.Publishes, &s.Period.NewUsers)
+	if err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
+// PolicyAckCount is the number of acknowledgements of a published policy's
+// current version.
+type PolicyAckCount struct {
+	PolicyID string `json:"policy_id"`
+	Title    string `json:"title"`
+	AckCount int    `json:"ack_count"`
+}
+
+// PolicyAckCounts counts acknowledgements of each published policy's
+// current version, only those made within r when it is set. Policies are
+// ordered newest first.
+func (db *DB) PolicyAckCounts(ctx context.Context, r *StatsRange) ([]*PolicyAckCount, error) {
+	from, to := r.args()
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT p.id, p.title, COUNT(a.id) FROM policies p
+		 LEFT JOIN acknowledgements a ON a.policy_version_id = p.current_version_id
+		      AND a.timestamp >= ? AND a.timestamp < ?
+		 WHERE p.status = 'Published' AND p.current_version_id IS NOT NULL
+		 GROUP BY p.id ORDER BY p.created_at DESC`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*PolicyAckCount
+	for rows.Next() {
+		c := &PolicyAckCount{}
+		if err := rows.Scan(&c.PolicyID, &c.Title, &c.AckCount); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
 // AckStatusForUser returns a map of policy_version_id → bool for all acknowledgements by a user.
-func (db *DB) AckStatusForUser(userID string) (map[string]bool, error) {
-	rows, err := db.conn.Query(
+func (db *DB) AckStatusForUser(ctx context.Context, userID string) (map[string]bool, error) {
+	rows, err := db.conn.QueryContext(ctx,
 		`SELECT policy_version_id FROM acknowledgements WHERE user_id=?`, userID,
 	)
 	if err != nil {
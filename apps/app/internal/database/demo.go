@@ -0,0 +1,330 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DemoAccounts are the demo users visitors sign in as, by role.
+var DemoAccounts = map[string]string{
+	"SuperAdmin": "admin@demo.policyflow.invalid",
+	"DeptAdmin":  "manager@demo.policyflow.invalid",
+	"Staff":      "staff@demo.policyflow.invalid",
+}
+
+// ErrNotDemoData is returned by ResetDemoData for a database that holds
+// users but was not filled by it, so real data is never wiped.
+var ErrNotDemoData = errors.New("database holds data that was not generated for the demo")
+
+var demoDepartments = []struct{ name, description string }{
+	{"Human Resources", "People operations, hiring, and employee relations"},
+	{"Engineering", "Product development and technical operations"},
+	{"Finance", "Accounting, payroll, and procurement"},
+	{"Sales", "Account management and new business"},
+	{"Customer Support", "Help desk and customer success"},
+	{"Legal & Compliance", "Contracts, regulatory affairs, and audits"},
+	{"Operations", "Facilities, travel, and office services"},
+}
+
+var demoFirstNames = []string{
+	"Amara", "Ben", "Carlos", "Dana", "Elif", "Farah", "Grace", "Hiro", "Isabel", "Jonas",
+	"Kwame", "Lena", "Mateo", "Nadia", "Oscar", "Priya", "Quinn", "Rosa", "Samir", "Tara",
+	"Umar", "Vera", "Wei", "Ximena", "Yusuf", "Zoe", "Aiden", "Bianca", "Chidi", "Devi",
+}
+
+var demoLastNames = []string{
+	"Okafor", "Schmidt", "Alvarez", "Nguyen", "Kowalski", "Haddad", "Tanaka", "Murphy", "Rossi", "Lindqvist",
+	"Mensah", "Dubois", "Patel", "Costa", "Ivanova", "Kim", "Oyelaran", "Fischer", "Moreau", "Chen",
+}
+
+// demoPolicies are published unless draft; dept indexes demoDepartments and
+// is -1 for organization-wide policies. Policies with revisions have an
+// older version superseded by the current one.
+var demoPolicies = []struct {
+	title     string
+	dept      int
+	revisions int
+	deadline  int // days from now; 0 = none, negative = already passed
+	draft     bool
+}{
+	{"Employee Code of Conduct", -1, 2, 0, false},
+	{"Information Security Policy", -1, 1, 14, false},
+	{"Acceptable Use of IT Systems", -1, 0, 0, false},
+	{"Data Protection and Privacy", -1, 1, -5, false},
+	{"Anti-Bribery and Corruption", -1, 0, 30, false},
+	{"Health and Safety at Work", -1, 0, 0, false},
+	{"Remote Work Policy", -1, 1, 0, false},
+	{"Whistleblowing Procedure", -1, 0, 0, false},
+	{"Recruitment and Onboarding", 0, 0, 0, false},
+	{"Secure Development Lifecycle", 1, 1, 10, false},
+	{"Incident Response Runbook", 1, 0, 0, false},
+	{"Expense Reimbursement", 2, 1, 0, false},
+	{"Procurement and Vendor Approval", 2, 0, -2, false},
+	{"Customer Data Handling", 4, 0, 21, false},
+	{"Sales Discount Approval", 3, 0, 0, false},
+	{"Records Retention Schedule", 5, 0, 0, false},
+	{"Business Travel", 6, 0, 0, false},
+	{"AI Tools Usage Guidelines", -1, 0, 0, true},
+}
+
+// demoContent writes a plausible policy body for a title.
+func demoContent(title, version string) string {
+	return fmt.Sprintf(`# %s
+
+_Version %s_
+
+## 1. Purpose
+
+This policy sets out how %s applies across the organization, so that everyone understands what is expected of them and why.
+
+## 2. Scope
+
+It applies to all employees, contractors, and temporary staff, wherever they work.
+
+## 3. Responsibilities
+
+- **Employees** read this policy, follow it, and raise concerns early.
+- **Managers** make sure their teams understand it and lead by example.
+- **Policy owners** review it at least once a year and publish changes.
+
+## 4. Requirements
+
+1. Follow the procedures described in the related guidance.
+2. Complete any required training before starting the activities it covers.
+3. Report suspected breaches to your manager or through the reporting channels.
+
+## 5. Exceptions
+
+Exceptions must be requested through PolicyFlow and approved by the policy owner before they apply.
+
+## 6. Acknowledgement
+
+By acknowledging this policy you confirm that you have read and understood it and will comply with it.
+`, title, version, strings.ToLower(title))
+}
+
+// ResetDemoData replaces everything in the database with a generated
+// demo organization: departments, about eighty users with the
+// DemoAccounts among them, published and draft policies, some with earlier
+// versions and deadlines, and acknowledgements by most, not all, of the
+// users each policy is required of, dated over the past months. Signing
+// keys and the migration history are kept. It refuses with ErrNotDemoData
+// when the database has users but was never reset for the demo.
+func (db *DB) ResetDemoData(ctx context.Context, seed uint64) (*LoadSummary, error) {
+	defer db.users.reset()
+	marked, err := db.GetSetting(ctx, SettingDemoData, "")
+	if err != nil {
+		return nil, err
+	}
+	if marked == "" {
+		var users int
+		if err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&users); err != nil {
+			return nil, err
+		}
+		if users > 0 {
+			return nil, ErrNotDemoData
+		}
+	}
+
+	r := rand.New(rand.NewPCG(seed, seed))
+	nowT := time.Now().UTC()
+	at := func(t time.Time) string { return t.Format(time.RFC3339) }
+	ago := func(days int) time.Time {
+		return nowT.Add(-time.Duration(r.IntN(days*24)+1) * time.Hour)
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	// Rows reference each other every which way; check once at the end.
+	if _, err := tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+		   AND name NOT IN ('schema_migrations', 'jwt_keys')`)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM "`+t+`"`); err != nil {
+			return nil, err
+		}
+	}
+
+	sum := &LoadSummary{}
+	deptIDs := make([]string, len(demoDepartments))
+	for i, d := range demoDepartments {
+		deptIDs[i] = uuid.New().String()
+		created := at(ago(720))
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO departments (id, name, description, created_at, updated_at) VALUES (?,?,?,?,?)`,
+			deptIDs[i], d.name, d.description, created, created); err != nil {
+			return nil, err
+		}
+		sum.Departments++
+	}
+
+	type demoUser struct {
+		id, dept string
+		created  time.Time
+	}
+	var users []demoUser
+	addUser := func(email, name, role string, dept *string, created time.Time) error {
+		u := demoUser{id: uuid.New().String(), created: created}
+		if dept != nil {
+			u.dept = *dept
+		}
+		var lastLogin any
+		if r.IntN(100) < 85 {
+			lastLogin = at(created.Add(time.Duration(r.Int64N(int64(nowT.Sub(created))))))
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO users (id, email, name, role, department_id, last_login_at, created_at) VALUES (?,?,?,?,?,?,?)`,
+			u.id, email, name, role, dept, lastLogin, at(created)); err != nil {
+			return err
+		}
+		users = append(users, u)
+		sum.Users++
+		return nil
+	}
+	if err := addUser(DemoAccounts["SuperAdmin"], "Alex Morgan", "SuperAdmin", nil, ago(720)); err != nil {
+		return nil, err
+	}
+	adminID := users[0].id
+	if err := addUser(DemoAccounts["DeptAdmin"], "Jordan Reyes", "DeptAdmin", &deptIDs[1], ago(600)); err != nil {
+		return nil, err
+	}
+	if err := addUser(DemoAccounts["Staff"], "Sam Taylor", "Staff", &deptIDs[1], ago(400)); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for len(users) < 80 {
+		first, last := demoFirstNames[r.IntN(len(demoFirstNames))], demoLastNames[r.IntN(len(demoLastNames))]
+		email := strings.ToLower(first + "." + last + "@demo.policyflow.invalid")
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		role := "Staff"
+		if len(users) < 3+len(deptIDs) {
+			role = "DeptAdmin" // one per department
+		}
+		dept := deptIDs[(len(users)-3)%len(deptIDs)]
+		if err := addUser(email, first+" "+last, role, &dept, ago(720)); err != nil {
+			return nil, err
+		}
+	}
+
+	ackStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO acknowledgements (id, user_id, policy_version_id, timestamp, signature_hash) VALUES (?,?,?,?,?)`)
+	if err != nil {
+		return nil, err
+	}
+	defer ackStmt.Close()
+	for _, p := range demoPolicies {
+		policyID := uuid.New().String()
+		var deptID *string
+		visibility := "organization"
+		if p.dept >= 0 {
+			deptID, visibility = &deptIDs[p.dept], "department"
+		}
+		status := "Published"
+		if p.draft {
+			status = "Draft"
+		}
+		var deadline any
+		if p.deadline != 0 {
+			deadline = at(nowT.AddDate(0, 0, p.deadline))
+		}
+		created := ago(540)
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO policies (id, title, department, department_id, visibility_type, status, version, ack_deadline, created_by, updated_by, created_at, updated_at)
+			 VALUES (?,?,'',?,?,?,1,?,?,?,?,?)`,
+			policyID, p.title, deptID, visibility, status, deadline, adminID, adminID, at(created), at(created)); err != nil {
+			return nil, err
+		}
+		sum.Policies++
+
+		// Versions are spread between the policy's creation and now.
+		var versionID string
+		var published time.Time
+		for v := 0; v <= p.revisions; v++ {
+			versionID = uuid.New().String()
+			published = created.Add(time.Duration(int64(nowT.Sub(created)) * int64(v) / int64(p.revisions+1)))
+			versionString := fmt.Sprintf("v1.%d", v)
+			changelog := "Initial release"
+			if v > 0 {
+				changelog = "Annual review: clarified responsibilities and updated contacts"
+			}
+			var publishedAt any
+			if !p.draft {
+				publishedAt = at(published)
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO policy_versions (id, policy_id, content, version_string, changelog, created_by, created_at, published_at) VALUES (?,?,?,?,?,?,?,?)`,
+				versionID, policyID, demoContent(p.title, versionString), versionString, changelog, adminID, at(published), publishedAt); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE policies SET current_version_id = ? WHERE id = ?`, versionID, policyID); err != nil {
+			return nil, err
+		}
+		if p.draft {
+			continue
+		}
+
+		// Most of the users the policy applies to have acknowledged it,
+		// some time after it was published or they joined.
+		for _, u := range users {
+			if deptID != nil && u.dept != *deptID {
+				continue
+			}
+			if r.IntN(100) >= 70 {
+				continue
+			}
+			from := published
+			if u.created.After(from) {
+				from = u.created
+			}
+			ts := from.Add(time.Duration(r.Int64N(int64(nowT.Sub(from)) + 1)))
+			sig := fmt.Sprintf("%x", sha256.Sum256([]byte(u.id+versionID+ts.String())))
+			if _, err := ackStmt.ExecContext(ctx, uuid.New().String(), u.id, versionID, at(ts), sig); err != nil {
+				return nil, err
+			}
+			sum.Acknowledgements++
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO settings (key, value, updated_at) VALUES (?,?,?)`,
+		SettingDemoData, at(nowT), at(nowT)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return sum, nil
+}
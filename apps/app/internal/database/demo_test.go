@@ -0,0 +1,62 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"policyflow/internal/database"
+)
+
+// TestResetDemoData checks the demo can be reset repeatedly, leaves some
+// policies partly acknowledged, and never wipes a database with real users.
+func TestResetDemoData(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, ":memory:")
+	first, err := db.ResetDemoData(ctx, 1)
+	if err != nil {
+		t.Fatalf("first reset: %v", err)
+	}
+	if _, err := db.CreateUser(ctx, "visitor@example.com", "Visitor", "Staff", nil, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	second, err := db.ResetDemoData(ctx, 1)
+	if err != nil {
+		t.Fatalf("second reset: %v", err)
+	}
+	if *second != *first {
+		t.Errorf("second reset = %+v; want %+v", second, first)
+	}
+	if _, err := db.GetUserByEmail(ctx, "visitor@example.com"); err == nil {
+		t.Error("visitor's user survived the reset")
+	}
+	for role, email := range database.DemoAccounts {
+		if u, err := db.GetUserByEmail(ctx, email); err != nil || u.Role != role {
+			t.Errorf("demo %s account: %+v, %v", role, u, err)
+		}
+	}
+	coverage, err := db.AckCoverageByPolicy(ctx)
+	if err != nil {
+		t.Fatalf("AckCoverageByPolicy: %v", err)
+	}
+	partial := 0
+	for _, c := range coverage {
+		if c.AckCount > 0 && c.AckCount < c.EligibleUserCount {
+			partial++
+		}
+	}
+	if partial == 0 {
+		t.Error("no policy is partly acknowledged")
+	}
+
+	prod := openDB(t, ":memory:")
+	if _, err := prod.CreateUser(ctx, "hr@example.com", "HR", "SuperAdmin", nil, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := prod.ResetDemoData(ctx, 1); !errors.Is(err, database.ErrNotDemoData) {
+		t.Errorf("reset of a real database = %v; want ErrNotDemoData", err)
+	}
+	if _, err := prod.GetUserByEmail(ctx, "hr@example.com"); err != nil {
+		t.Errorf("real user lost: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// DepartmentGrant lets a DeptAdmin administer a department other than
+// their own.
+type DepartmentGrant struct {
+	UserID         string    `json:"user_id"`
+	DepartmentID   string    `json:"department_id"`
+	DepartmentName string    `json:"department_name"`
+	GrantedBy      *string   `json:"granted_by"`
+	GrantedAt      time.Time `json:"granted_at"`
+}
+
+// GrantDepartmentAdmin lets userID administer deptID. Granting twice keeps
+// the first grant.
+func (db *DB) GrantDepartmentAdmin(ctx context.Context, userID, deptID string, grantedBy *string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT OR IGNORE INTO department_admins (user_id, department_id, granted_by, granted_at) VALUES (?,?,?,?)`,
+		userID, deptID, grantedBy, now())
+	return err
+}
+
+// RevokeDepartmentAdmin removes a grant, reporting whether there was one.
+func (db *DB) RevokeDepartmentAdmin(ctx context.Context, userID, deptID string) (bool, error) {
+	res, err := db.conn.ExecContext(ctx,
+		`DELETE FROM department_admins WHERE user_id = ? AND department_id = ?`, userID, deptID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ListDepartmentGrants returns the departments granted to userID, by name.
+func (db *DB) ListDepartmentGrants(ctx context.Context, userID string) ([]*DepartmentGrant, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT g.user_id, g.department_id, d.name, g.granted_by, g.granted_at
+		 FROM department_admins g JOIN departments d ON d.id = g.department_id
+		 WHERE g.user_id = ? ORDER BY d.name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*DepartmentGrant
+	for rows.Next() {
+		g := &DepartmentGrant{}
+		var grantedBy sql.NullString
+		var grantedAt string
+		if err := rows.Scan(&g.UserID, &g.DepartmentID, &g.DepartmentName, &grantedBy, &grantedAt); err != nil {
+			return nil, err
+		}
+		g.GrantedBy = nullString(grantedBy)
+		g.GrantedAt = parseTime(grantedAt)
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+// HasDepartmentGrant reports whether userID has been granted deptID.
+func (db *DB) HasDepartmentGrant(ctx context.Context, userID, deptID string) (bool, error) {
+	var n int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM department_admins WHERE user_id = ? AND department_id = ?`, userID, deptID,
+	).Scan(&n)
+	return n > 0, err
+}
@@ -0,0 +1,66 @@
+package database
+
+import "context"
+
+// DepartmentWelcome is the email owed to a user who joined a department,
+// listing the policies awaiting their acknowledgement. Stale is set when
+// the user has since been deactivated or moved on, so it is not needed.
+type DepartmentWelcome struct {
+	UserID         string
+	UserEmail      string
+	UserName       string
+	Role           string
+	DepartmentID   string
+	DepartmentName string
+	Stale          bool
+}
+
+// QueueDepartmentWelcome owes user a welcome to the department they are in
+// now, replacing any unsent one. Users without a department are skipped.
+func (db *DB) QueueDepartmentWelcome(ctx context.Context, user *User) error {
+	if user.DepartmentID == nil {
+		return nil
+	}
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO department_welcomes (user_id, department_id, created_at) VALUES (?, ?, ?)
+		 ON CONFLICT (user_id) DO UPDATE SET department_id = excluded.department_id,
+		        created_at = excluded.created_at, sent_at = NULL`,
+		user.ID, *user.DepartmentID, now())
+	return err
+}
+
+// ListUnsentDepartmentWelcomes returns up to limit queued welcomes, oldest
+// first.
+func (db *DB) ListUnsentDepartmentWelcomes(ctx context.Context, limit int) ([]*DepartmentWelcome, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT w.user_id, u.email, u.name, u.role, w.department_id, d.name,
+		        u.deactivated_at IS NOT NULL OR u.department_id IS NOT w.department_id
+		 FROM department_welcomes w
+		 JOIN users u ON u.id = w.user_id
+		 JOIN departments d ON d.id = w.department_id
+		 WHERE w.sent_at IS NULL ORDER BY w.created_at LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*DepartmentWelcome
+	for rows.Next() {
+		w := &DepartmentWelcome{}
+		if err := rows.Scan(&w.UserID, &w.UserEmail, &w.UserName, &w.Role, &w.DepartmentID, &w.DepartmentName,
+			&w.Stale); err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// MarkDepartmentWelcomeSent records that a user's welcome to a department
+// was sent or is no longer needed. One queued since, for another
+// department, is left unsent.
+func (db *DB) MarkDepartmentWelcomeSent(ctx context.Context, userID, departmentID string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE department_welcomes SET sent_at = ? WHERE user_id = ? AND department_id = ? AND sent_at IS NULL`,
+		now(), userID, departmentID)
+	return err
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// EmailTemplate is the organization's edit of a built-in email template.
+type EmailTemplate struct {
+	Name      string    `json:"name"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	UpdatedBy *string   `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListEmailTemplates returns every edited template.
+func (db *DB) ListEmailTemplates(ctx context.Context) ([]*EmailTemplate, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT name, subject, body, updated_by, updated_at FROM email_templates ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*EmailTemplate
+	for rows.Next() {
+		t := &EmailTemplate{}
+		var updatedBy sql.NullString
+		var updatedAt string
+		if err := rows.Scan(&t.Name, &t.Subject, &t.Body, &updatedBy, &updatedAt); err != nil {
+			return nil, err
+		}
+		t.UpdatedBy = nullString(updatedBy)
+		t.UpdatedAt = parseTime(updatedAt)
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// SetEmailTemplate stores the organization's edit of template name.
+func (db *DB) SetEmailTemplate(ctx context.Context, name, subject, body string, updatedBy *string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO email_templates (name, subject, body, updated_by, updated_at) VALUES (?,?,?,?,?)
+		 ON CONFLICT(name) DO UPDATE SET subject=excluded.subject, body=excluded.body,
+		     updated_by=excluded.updated_by, updated_at=excluded.updated_at`,
+		name, subject, body, updatedBy, now())
+	return err
+}
+
+// DeleteEmailTemplate discards the edit of template name, restoring the
+// default.
+func (db *DB) DeleteEmailTemplate(ctx context.Context, name string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM email_templates WHERE name = ?`, name)
+	return err
+}
+
+// EmailTemplateOverride returns the edited subject and body of template
+// name; ok is false when it has not been edited. It lets the mailer read
+// templates without depending on this package.
+func (db *DB) EmailTemplateOverride(ctx context.Context, name string) (subject, body string, ok bool, err error) {
+	err = db.conn.QueryRowContext(ctx,
+		`SELECT subject, body FROM email_templates WHERE name = ?`, name).Scan(&subject, &body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", false, nil
+	}
+	return subject, body, err == nil, err
+}
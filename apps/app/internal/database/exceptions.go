@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Exception statuses.
+const (
+	ExceptionPending  = "pending"
+	ExceptionApproved = "approved"
+	ExceptionDenied   = "denied"
+)
+
+// PolicyException is a user's documented exemption from a policy. It is
+// requested with a justification and duration and, once approved, is active
+// until ExpiresAt (approval time plus the duration).
+type PolicyException struct {
+	ID            string     `json:"id"`
+	PolicyID      string     `json:"policy_id"`
+	PolicyTitle   string     `json:"policy_title"`
+	UserID        string     `json:"user_id"`
+	UserName      string     `json:"user_name"`
+	UserEmail     string     `json:"user_email"`
+	Justification string     `json:"justification"`
+	DurationDays  int        `json:"duration_days"`
+	Status        string     `json:"status"`
+	DecidedBy     *string    `json:"decided_by"`
+	DecidedByName *string    `json:"decided_by_name"`
+	DecisionNote  string     `json:"decision_note"`
+	DecidedAt     *time.Time `json:"decided_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Active reports whether the exception is approved and not yet expired at t.
+func (e *PolicyException) Active(t time.Time) bool {
+	return e.Status == ExceptionApproved && e.ExpiresAt != nil && t.Before(*e.ExpiresAt)
+}
+
+// ExceptionFilter narrows ListPolicyExceptions. ApproverID and ApproverDeptID
+// together limit results to exceptions on policies the approver owns or that
+// belong to their department.
+type ExceptionFilter struct {
+	PolicyID       string
+	UserID         string
+	Status         string
+	ApproverID     string
+	ApproverDeptID *string
+}
+
+const exceptionSelect = `SELECT e.id, e.policy_id, p.title, e.user_id, u.name, u.email, e.justification,
+	e.duration_days, e.status, e.decided_by, du.name, e.decision_note, e.decided_at, e.expires_at, e.created_at
+	FROM policy_exceptions e
+	JOIN policies p ON p.id = e.policy_id
+	JOIN users u ON u.id = e.user_id
+	LEFT JOIN users du ON du.id = e.decided_by`
+
+func (db *DB) CreatePolicyException(ctx context.Context, policyID, userID, justification string, durationDays int) (*PolicyException, error) {
+	id := uuid.New().String()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_exceptions (id, policy_id, user_id, justification, duration_days, status, created_at)
+		 VALUES (?,?,?,?,?,?,?)`,
+		id, policyID, userID, justification, durationDays, ExceptionPending, now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetPolicyException(ctx, id)
+}
+
+func (db *DB) GetPolicyException(ctx context.Context, id string) (*PolicyException, error) {
+	return db.scanException(db.conn.QueryRowContext(ctx, exceptionSelect+` WHERE e.id = ?`, id))
+}
+
+// ListPolicyExceptions returns matching exceptions, newest first.
+func (db *DB) ListPolicyExceptions(ctx context.Context, f ExceptionFilter) ([]*PolicyException, error) {
+	query := exceptionSelect + ` WHERE 1=1`
+	var args []any
+	if f.PolicyID != "" {
+		query, args = query+` AND e.policy_id = ?`, append(args, f.PolicyID)
+	}
+	if f.UserID != "" {
+		query, args = query+` AND e.user_id = ?`, append(args, f.UserID)
+	}
+	if f.Status != "" {
+		query, args = query+` AND e.status = ?`, append(args, f.Status)
+	}
+	if f.ApproverID != "" {
+		query, args = query+` AND (p.created_by = ? OR p.department_id = ?)`, append(args, f.ApproverID, deref(f.ApproverDeptID))
+	}
+	rows, err := db.conn.QueryContext(ctx, query+` ORDER BY e.created_at DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*PolicyException
+	for rows.Next() {
+		e, err := db.scanException(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// HasOpenException reports whether the user already has a pending or
+// still-active exception for the policy.
+func (db *DB) HasOpenException(ctx context.Context, policyID, userID string, t time.Time) (bool, error) {
+	var n int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM policy_exceptions WHERE policy_id = ? AND user_id = ?
+		   AND (status = 'pending' OR (status = 'approved' AND expires_at > ?))`,
+		policyID, userID, t.UTC().Format(time.RFC3339),
+	).Scan(&n)
+	return n > 0, err
+}
+
+// DecidePolicyException records the decision on a pending exception;
+// expiresAt is set for approvals. It returns sql.ErrNoRows if the exception
+// is no longer pending.
+func (db *DB) DecidePolicyException(ctx context.Context, id, status, decidedBy, note string, decidedAt time.Time, expiresAt *time.Time) error {
+	var expires *string
+	if expiresAt != nil {
+		s := expiresAt.UTC().Format(time.RFC3339)
+		expires = &s
+	}
+	res, err := db.conn.ExecContext(ctx,
+		`UPDATE policy_exceptions SET status = ?, decided_by = ?, decision_note = ?, decided_at = ?, expires_at = ?
+		 WHERE id = ? AND status = 'pending'`,
+		status, decidedBy, note, decidedAt.UTC().Format(time.RFC3339), expires, id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ActiveExceptions maps user ID → policy ID → expiry for every exception
+// active at t.
+func (db *DB) ActiveExceptions(ctx context.Context, t time.Time) (map[string]map[string]time.Time, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT user_id, policy_id, expires_at FROM policy_exceptions
+		 WHERE status = 'approved' AND expires_at > ?`, t.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]map[string]time.Time{}
+	for rows.Next() {
+		var userID, policyID, expires string
+		if err := rows.Scan(&userID, &policyID, &expires); err != nil {
+			return nil, err
+		}
+		if out[userID] == nil {
+			out[userID] = map[string]time.Time{}
+		}
+		out[userID][policyID] = parseTime(expires)
+	}
+	return out, rows.Err()
+}
+
+func (db *DB) scanException(row scanner) (*PolicyException, error) {
+	e := &PolicyException{}
+	var decidedBy, decidedByName, decidedAt, expiresAt sql.NullString
+	var createdAt string
+	if err := row.Scan(&e.ID, &e.PolicyID, &e.PolicyTitle, &e.UserID, &e.UserName, &e.UserEmail, &e.Justification,
+		&e.DurationDays, &e.Status, &decidedBy, &decidedByName, &e.DecisionNote, &decidedAt, &expiresAt, &createdAt); err != nil {
+		return nil, err
+	}
+	e.DecidedBy = nullString(decidedBy)
+	e.DecidedByName = nullString(decidedByName)
+	if decidedAt.Valid {
+		t := parseTime(decidedAt.String)
+		e.DecidedAt = &t
+	}
+	if expiresAt.Valid {
+		t := parseTime(expiresAt.String)
+		e.ExpiresAt = &t
+	}
+	e.CreatedAt = parseTime(createdAt)
+	return e, nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PolicyFAQ is a question and answer clarifying a policy. FAQs belong to
+// the policy rather than a version: they are not part of what users
+// acknowledge, so adding or editing one does not ask anyone to acknowledge
+// the policy again.
+type PolicyFAQ struct {
+	ID        string    `json:"id"`
+	PolicyID  string    `json:"policy_id"`
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"` // Markdown
+	Position  int       `json:"position"`
+	CreatedBy *string   `json:"created_by"`
+	UpdatedBy *string   `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+const faqSelect = `SELECT id, policy_id, question, answer, position, created_by, updated_by, created_at, updated_at
+	FROM policy_faqs`
+
+// ListPolicyFAQs returns a policy's FAQs in display order.
+func (db *DB) ListPolicyFAQs(ctx context.Context, policyID string) ([]*PolicyFAQ, error) {
+	rows, err := db.conn.QueryContext(ctx, faqSelect+` WHERE policy_id = ? ORDER BY position`, policyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*PolicyFAQ
+	for rows.Next() {
+		f, err := scanFAQ(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// GetPolicyFAQ returns one of a policy's FAQs, or sql.ErrNoRows if the
+// policy has no FAQ with that ID.
+func (db *DB) GetPolicyFAQ(ctx context.Context, policyID, id string) (*PolicyFAQ, error) {
+	return scanFAQ(db.conn.QueryRowContext(ctx, faqSelect+` WHERE policy_id = ? AND id = ?`, policyID, id))
+}
+
+// CreatePolicyFAQ adds an FAQ after the policy's others.
+func (db *DB) CreatePolicyFAQ(ctx context.Context, policyID, question, answer string, createdBy *string) (*PolicyFAQ, error) {
+	id, ts := uuid.New().String(), now()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_faqs (id, policy_id, question, answer, position, created_by, updated_by, created_at, updated_at)
+		 VALUES (?,?,?,?, (SELECT COALESCE(MAX(position), -1) + 1 FROM policy_faqs WHERE policy_id = ?), ?,?,?,?)`,
+		id, policyID, question, answer, policyID, createdBy, createdBy, ts, ts)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetPolicyFAQ(ctx, policyID, id)
+}
+
+// UpdatePolicyFAQ saves f's question and answer and moves it to
+// f.Position, clamped to the policy's FAQs, shifting the others.
+func (db *DB) UpdatePolicyFAQ(ctx context.Context, f *PolicyFAQ) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM policy_faqs WHERE policy_id = ? AND id <> ? ORDER BY position`, f.PolicyID, f.ID)
+	if err != nil {
+		return err
+	}
+	var order []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		order = append(order, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	f.Position = min(max(f.Position, 0), len(order))
+	order = append(order[:f.Position], append([]string{f.ID}, order[f.Position:]...)...)
+	for i, id := range order {
+		if _, err := tx.ExecContext(ctx, `UPDATE policy_faqs SET position = ? WHERE id = ?`, i, id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE policy_faqs SET question = ?, answer = ?, updated_by = ?, updated_at = ? WHERE id = ?`,
+		f.Question, f.Answer, f.UpdatedBy, now(), f.ID,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeletePolicyFAQ removes an FAQ; the others keep their order.
+func (db *DB) DeletePolicyFAQ(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM policy_faqs WHERE id = ?`, id)
+	return err
+}
+
+func scanFAQ(row scanner) (*PolicyFAQ, error) {
+	f := &PolicyFAQ{}
+	var createdBy, updatedBy sql.NullString
+	var createdAt, updatedAt string
+	if err := row.Scan(&f.ID, &f.PolicyID, &f.Question, &f.Answer, &f.Position, &createdBy, &updatedBy,
+		&createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	f.CreatedBy, f.UpdatedBy = nullString(createdBy), nullString(updatedBy)
+	f.CreatedAt, f.UpdatedAt = parseTime(createdAt), parseTime(updatedAt)
+	return f, nil
+}
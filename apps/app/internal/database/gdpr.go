@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// subjectSections lists every table that holds data about a user, keyed by
+// the section name used in a data-subject access export. Each query takes
+// the user ID as its only argument (repeated for each placeholder). Add a
+// section here whenever a new table stores personal data.
+var subjectSections = []struct {
+	name  string
+	query string
+}{
+	{"acknowledgements", `
+SELECT a.id, p.id AS policy_id, p.title AS policy_title, v.version_string, a.timestamp, a.signature_hash, a.source
+FROM acknowledgements a
+JOIN policy_versions v ON v.id = a.policy_version_id
+JOIN policies p ON p.id = v.policy_id
+WHERE a.user_id = ? ORDER BY a.timestamp`},
+	{"read_events", `
+SELECT p.title AS policy_title, v.version_string, e.event, e.created_at
+FROM policy_read_events e
+JOIN policy_versions v ON v.id = e.policy_version_id
+JOIN policies p ON p.id = v.policy_id
+WHERE e.user_id = ? ORDER BY e.created_at`},
+	{"reminders_sent", `
+SELECT p.title AS policy_title, v.version_string, r.sent_at
+FROM reminder_log r
+JOIN policy_versions v ON v.id = r.policy_version_id
+JOIN policies p ON p.id = v.policy_id
+WHERE r.user_id = ? ORDER BY r.sent_at`},
+	{"reminder_snoozes", `
+SELECT p.title AS policy_title, v.version_string, s.snoozed_until, s.created_at
+FROM reminder_snoozes s
+JOIN policy_versions v ON v.id = s.policy_version_id
+JOIN policies p ON p.id = v.policy_id
+WHERE s.user_id = ? ORDER BY s.created_at`},
+	{"policy_exceptions", `
+SELECT e.id, p.title AS policy_title, e.justification, e.duration_days, e.status, e.decision_note,
+       e.decided_at, e.expires_at, e.created_at,
+       CASE WHEN e.decided_by = ? THEN 'approver' ELSE 'requester' END AS role
+FROM policy_exceptions e JOIN policies p ON p.id = e.policy_id
+WHERE e.user_id = ? OR e.decided_by = ? ORDER BY e.created_at`},
+	{"change_requests", `
+SELECT c.id, p.title AS policy_title, c.section, c.comment, c.status, c.resolution_note, c.resolved_at, c.created_at,
+       CASE WHEN c.resolved_by = ? AND c.created_by IS NOT ? THEN 'resolver' ELSE 'requester' END AS role
+FROM change_requests c JOIN policies p ON p.id = c.policy_id
+WHERE c.created_by = ? OR c.resolved_by = ? ORDER BY c.created_at`},
+	{"policy_assignments", `
+SELECT a.policy_id, p.title AS policy_title, a.target_type, a.target_id, a.created_at,
+       CASE WHEN a.created_by = ? AND NOT (a.target_type = 'user' AND a.target_id = ?) THEN 'assigner' ELSE 'assignee' END AS role
+FROM policy_assignments a JOIN policies p ON p.id = a.policy_id
+WHERE (a.target_type = 'user' AND a.target_id = ?) OR a.created_by = ? ORDER BY a.created_at`},
+	{"policy_bundles_received", `
+SELECT b.name AS bundle_name, ba.user_id, ba.assigned_at,
+       CASE WHEN ba.assigned_by = ? AND ba.user_id <> ? THEN 'assigner' ELSE 'recipient' END AS role
+FROM policy_bundle_assignments ba JOIN policy_bundles b ON b.id = ba.bundle_id
+WHERE ba.user_id = ? OR ba.assigned_by = ? ORDER BY ba.assigned_at`},
+	{"policy_bundles_created", `
+SELECT b.id, b.name, b.created_at FROM policy_bundles b WHERE b.created_by = ? ORDER BY b.created_at`},
+	{"publish_notifications", `
+SELECT p.title AS policy_title, v.version_string, n.created_at, n.sent_at
+FROM publish_notifications n
+JOIN policy_versions v ON v.id = n.policy_version_id
+JOIN policies p ON p.id = v.policy_id
+WHERE n.user_id = ? ORDER BY n.created_at`},
+	{"department_welcomes", `
+SELECT d.name AS department_name, w.created_at, w.sent_at
+FROM department_welcomes w JOIN departments d ON d.id = w.department_id
+WHERE w.user_id = ?`},
+	{"campaign_emails", `
+SELECT c.name AS campaign_name, r.stage, r.sent_at
+FROM campaign_reminders r JOIN campaigns c ON c.id = r.campaign_id
+WHERE r.user_id = ? ORDER BY r.sent_at`},
+	{"policy_training_links", `
+SELECT p.title AS policy_title, t.course_id, t.updated_at
+FROM policy_training t JOIN policies p ON p.id = t.policy_id
+WHERE t.updated_by = ? ORDER BY t.updated_at`},
+	{"training_completions", `
+SELECT t.course_id, t.completed_at, t.received_at
+FROM training_completions t WHERE t.user_id = ? ORDER BY t.completed_at`},
+	{"department_admin_grants", `
+SELECT g.department_id, d.name AS department_name, g.granted_at,
+       CASE WHEN g.granted_by = ? AND g.user_id <> ? THEN 'granter' ELSE 'grantee' END AS role
+FROM department_admins g JOIN departments d ON d.id = g.department_id
+WHERE g.granted_by = ? OR g.user_id = ? ORDER BY g.granted_at`},
+	{"login_history", `
+SELECT event, ip_address, user_agent, created_at FROM login_events WHERE user_id = ? ORDER BY created_at`},
+	{"policies_authored", `
+SELECT id, title, created_at FROM policies WHERE created_by = ? ORDER BY created_at`},
+	{"versions_authored", `
+SELECT v.id, p.title AS policy_title, v.version_string, v.created_at
+FROM policy_versions v JOIN policies p ON p.id = v.policy_id
+WHERE v.created_by = ? ORDER BY v.created_at`},
+	{"faqs_authored", `
+SELECT f.id, p.title AS policy_title, f.question, f.created_at, f.updated_at,
+       CASE WHEN f.created_by = ? THEN 'author' ELSE 'editor' END AS role
+FROM policy_faqs f JOIN policies p ON p.id = f.policy_id
+WHERE f.created_by = ? OR f.updated_by = ? ORDER BY f.created_at`},
+	{"campaigns_created", `
+SELECT id, name, starts_at, deadline, created_at FROM campaigns WHERE created_by = ? ORDER BY created_at`},
+	{"collections_created", `
+SELECT id, title, created_at FROM collections WHERE created_by = ? ORDER BY created_at`},
+	{"policy_relations_created", `
+SELECT p.title AS policy_title, rp.title AS related_policy_title, r.relation_type, r.created_at
+FROM policy_relations r
+JOIN policies p ON p.id = r.policy_id
+JOIN policies rp ON rp.id = r.related_policy_id
+WHERE r.created_by = ? ORDER BY r.created_at`},
+	{"report_schedules", `
+SELECT s.id, s.name, s.frequency, s.recipients, s.created_at,
+       CASE WHEN s.created_by = ? THEN 'creator' ELSE 'recipient' END AS role
+FROM report_schedules s
+WHERE s.created_by = ?
+   OR EXISTS (SELECT 1 FROM json_each(s.recipients) r JOIN users u ON u.id = ? WHERE lower(r.value) = lower(u.email))
+ORDER BY s.created_at`},
+	{"share_links_created", `
+SELECT s.id, p.title AS policy_title, s.created_at, s.expires_at, s.revoked_at
+FROM policy_shares s JOIN policies p ON p.id = s.policy_id
+WHERE s.created_by = ? ORDER BY s.created_at`},
+	{"attachments_uploaded", `
+SELECT a.id, p.title AS policy_title, a.filename, a.created_at
+FROM policy_attachments a JOIN policies p ON p.id = a.policy_id
+WHERE a.uploaded_by = ? ORDER BY a.created_at`},
+	{"uploads_scanned", `
+SELECT id, kind, filename, infected, signature, created_at
+FROM upload_scans WHERE uploaded_by = ? ORDER BY created_at`},
+	{"uploads_in_progress", `
+SELECT id, filename, size_bytes, received, created_at FROM uploads WHERE user_id = ? ORDER BY created_at`},
+	{"settings_changed", `
+SELECT key, updated_at FROM settings WHERE updated_by = ? ORDER BY updated_at`},
+	{"email_templates_changed", `
+SELECT name, updated_at FROM email_templates WHERE updated_by = ? ORDER BY updated_at`},
+	{"audit_entries", `
+SELECT action, target_type, target_id, ip_address, created_at,
+       CASE WHEN impersonator_id = ? THEN 'impersonator' ELSE 'actor' END AS role
+FROM audit_log WHERE actor_id = ? OR impersonator_id = ? ORDER BY created_at`},
+	{"hris_syncs_triggered", `
+SELECT id, provider, status, started_at FROM hris_sync_runs WHERE triggered_by = ? ORDER BY started_at`},
+}
+
+// SubjectData collects everything stored about a user for a data-subject
+// access request. The profile contains every column of the user's row, so
+// new user fields are included automatically.
+func (db *DB) SubjectData(ctx context.Context, userID string) (map[string]any, error) {
+	profile, err := db.queryMaps(ctx, `SELECT * FROM users WHERE id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(profile) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	out := map[string]any{"profile": profile[0]}
+	for _, s := range subjectSections {
+		args := make([]any, strings.Count(s.query, "?"))
+		for i := range args {
+			args[i] = userID
+		}
+		rows, err := db.queryMaps(ctx, s.query, args...)
+		if err != nil {
+			return nil, err
+		}
+		out[s.name] = rows
+	}
+	return out, nil
+}
+
+// queryMaps runs a query and returns each row as a column→value map.
+func (db *DB) queryMaps(ctx context.Context, query string, args ...any) ([]map[string]any, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	out := []map[string]any{}
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, len(cols))
+		for i, c := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				vals[i] = string(b)
+			}
+			m[c] = vals[i]
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HRISSyncRun records one execution of the HRIS user sync.
+type HRISSyncRun struct {
+	ID          string     `json:"id"`
+	Provider    string     `json:"provider"`
+	TriggeredBy *string    `json:"triggered_by"` // user ID, nil for scheduled runs
+	Status      string     `json:"status"`       // running | success | partial | failed
+	Created     int        `json:"created"`
+	Updated     int        `json:"updated"`
+	Deactivated int        `json:"deactivated"`
+	Skipped     int        `json:"skipped"`
+	Log         string     `json:"log"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DryRun      bool       `json:"dry_run"` // a preview, not stored
+}
+
+func (db *DB) GetUserByExternalID(ctx context.Context, externalID string) (*User, error) {
+	return db.scanUser(db.conn.QueryRowContext(ctx, userSelect+` WHERE u.external_id = ?`, externalID))
+}
+
+func (db *DB) SetUserExternalID(ctx context.Context, id, externalID string) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE users SET external_id=? WHERE id=?`, externalID, id)
+	return err
+}
+
+// DeactivateUser marks a user as a leaver. Their acknowledgements are kept.
+func (db *DB) DeactivateUser(ctx context.Context, id string) error {
+	defer db.users.reset()
+	_, err := db.conn.ExecContext(ctx, `UPDATE users SET deactivated_at=? WHERE id=? AND deactivated_at IS NULL`, now(), id)
+	return err
+}
+
+func (db *DB) ReactivateUser(ctx context.Context, id string) error {
+	defer db.users.reset()
+	_, err := db.conn.ExecContext(ctx, `UPDATE users SET deactivated_at=NULL WHERE id=?`, id)
+	return err
+}
+
+func (db *DB) CreateHRISSyncRun(ctx context.Context, provider string, triggeredBy *string) (*HRISSyncRun, error) {
+	r := &HRISSyncRun{
+		ID:          uuid.New().String(),
+		Provider:    provider,
+		TriggeredBy: triggeredBy,
+		Status:      "running",
+	}
+	ts := now()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO hris_sync_runs (id, provider, triggered_by, status, started_at) VALUES (?,?,?,?,?)`,
+		r.ID, r.Provider, r.TriggeredBy, r.Status, ts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	r.StartedAt = parseTime(ts)
+	return r, nil
+}
+
+// FinishHRISSyncRun stores the final counters, log, and status of a run.
+func (db *DB) FinishHRISSyncRun(ctx context.Context, r *HRISSyncRun) error {
+	ts := now()
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE hris_sync_runs SET status=?, created=?, updated=?, deactivated=?, skipped=?, log=?, finished_at=? WHERE id=?`,
+		r.Status, r.Created, r.Updated, r.Deactivated, r.Skipped, r.Log, ts, r.ID,
+	)
+	if err != nil {
+		return err
+	}
+	t := parseTime(ts)
+	r.FinishedAt = &t
+	return nil
+}
+
+// ListHRISSyncRuns returns the most recent runs, newest first.
+func (db *DB) ListHRISSyncRuns(ctx context.Context, limit int) ([]*HRISSyncRun, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT id, provider, triggered_by, status, created, updated, deactivated, skipped, log, started_at, finished_at
+		 FROM hris_sync_runs ORDER BY started_at DESC LIMIT ?`, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*HRISSyncRun
+	for rows.Next() {
+		r := &HRISSyncRun{}
+		var triggeredBy, finishedAt sql.NullString
+		var startedAt string
+		if err := rows.Scan(&r.ID, &r.Provider, &triggeredBy, &r.Status, &r.Created, &r.Updated,
+			&r.Deactivated, &r.Skipped, &r.Log, &startedAt, &finishedAt); err != nil {
+			return nil, err
+		}
+		if triggeredBy.Valid {
+			r.TriggeredBy = &triggeredBy.String
+		}
+		r.StartedAt = parseTime(startedAt)
+		if finishedAt.Valid {
+			t := parseTime(finishedAt.String)
+			r.FinishedAt = &t
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
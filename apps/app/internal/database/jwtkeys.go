@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// JWTKey is a session-signing key. ExpiresAt is nil for the current key and
+// set to the end of the rotation window for previous ones.
+type JWTKey struct {
+	KID       string     `json:"kid"`
+	Secret    string     `json:"-"` // base64; empty for the JWT_SECRET key
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ListJWTKeys returns every key, expired ones included, newest first.
+func (db *DB) ListJWTKeys(ctx context.Context) ([]*JWTKey, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT kid, secret, created_at, expires_at FROM jwt_keys ORDER BY created_at DESC, rowid DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*JWTKey
+	for rows.Next() {
+		k := &JWTKey{}
+		var createdAt string
+		var expiresAt sql.NullString
+		if err := rows.Scan(&k.KID, &k.Secret, &createdAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		k.CreatedAt = parseTime(createdAt)
+		if expiresAt.Valid {
+			t := parseTime(expiresAt.String)
+			k.ExpiresAt = &t
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RotateJWTKey makes newKey current. Every previously current key, including
+// the environment key identified by envKID, stays valid until retireAt.
+func (db *DB) RotateJWTKey(ctx context.Context, envKID string, newKey *JWTKey, retireAt time.Time) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ts, retire := now(), retireAt.UTC().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO jwt_keys (kid, secret, created_at, expires_at) VALUES (?, '', ?, ?) ON CONFLICT(kid) DO NOTHING`,
+		envKID, ts, retire,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE jwt_keys SET expires_at=? WHERE expires_at IS NULL`, retire); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO jwt_keys (kid, secret, created_at) VALUES (?,?,?)`, newKey.KID, newKey.Secret, ts,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
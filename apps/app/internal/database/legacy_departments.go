@@ -0,0 +1,250 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// How a legacy policies.department value relates to the departments table.
+const (
+	LegacyMatchExact = "exact" // same name, ignoring case, punctuation, and "department"
+	LegacyMatchFuzzy = "fuzzy" // a similar name; only applied when confirmed
+	LegacyMatchNone  = "none"  // nothing similar; a department is created for it
+)
+
+// Outcomes of consolidating a legacy value.
+const (
+	LegacyMapped  = "mapped"
+	LegacyCreated = "created"
+	LegacySkipped = "skipped"
+)
+
+// fuzzyThreshold is the minimum similarity (0–1) for a fuzzy match.
+const fuzzyThreshold = 0.75
+
+// LegacyDepartment is a distinct value of the deprecated policies.department
+// text column on policies that have no department_id, with the department
+// it would be mapped to.
+type LegacyDepartment struct {
+	Value          string  `json:"value"`
+	Policies       int     `json:"policies"`
+	Match          string  `json:"match"`
+	DepartmentID   *string `json:"department_id"`
+	DepartmentName *string `json:"department_name"`
+	Similarity     float64 `json:"similarity"`
+	Action         string  `json:"action,omitempty"` // set once consolidated
+}
+
+// ConsolidateOptions controls ConsolidateLegacyDepartments. Overrides maps a
+// legacy value to the department ID it should use, whatever the matcher
+// found.
+type ConsolidateOptions struct {
+	AcceptFuzzy bool              `json:"accept_fuzzy"`
+	Overrides   map[string]string `json:"overrides"`
+}
+
+// ErrUnknownDepartment is returned when an override names no department.
+var ErrUnknownDepartment = errors.New("override names an unknown department")
+
+// LegacyDepartments reports each legacy department value still in use and
+// the department it matches, without changing anything.
+func (db *DB) LegacyDepartments(ctx context.Context) ([]*LegacyDepartment, error) {
+	depts, err := db.ListDepartments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out, err := legacyValues(ctx, db.conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range out {
+		l.match(depts)
+	}
+	return out, nil
+}
+
+// ConsolidateLegacyDepartments moves every policy that only has a legacy
+// department value onto a real department: the overridden one, an exact
+// match, a fuzzy match when accepted, or otherwise a newly created
+// department named after the value. Unconfirmed fuzzy matches are skipped.
+// The legacy text is cleared on every policy that ends up with a
+// department_id. It runs in one transaction and returns what it did.
+func (db *DB) ConsolidateLegacyDepartments(ctx context.Context, opts ConsolidateOptions) ([]*LegacyDepartment, error) {
+	depts, err := db.ListDepartments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	out, err := legacyValues(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range out {
+		l.match(depts)
+		if id, ok := opts.Overrides[l.Value]; ok {
+			var d *Department
+			for _, cand := range depts {
+				if cand.ID == id {
+					d = cand
+				}
+			}
+			if d == nil {
+				return nil, ErrUnknownDepartment
+			}
+			l.DepartmentID, l.DepartmentName, l.Action = &d.ID, &d.Name, LegacyMapped
+		} else {
+			switch l.Match {
+			case LegacyMatchExact:
+				l.Action = LegacyMapped
+			case LegacyMatchFuzzy:
+				l.Action = LegacySkipped
+				if opts.AcceptFuzzy {
+					l.Action = LegacyMapped
+				}
+			case LegacyMatchNone:
+				d := &Department{ID: uuid.New().String(), Name: strings.TrimSpace(l.Value)}
+				ts := now()
+				if _, err := tx.ExecContext(ctx,
+					`INSERT INTO departments (id, name, description, created_at, updated_at) VALUES (?,?,'',?,?)`,
+					d.ID, d.Name, ts, ts,
+				); err != nil {
+					return nil, err
+				}
+				// Later values that differ only in spacing or case reuse it.
+				depts = append(depts, d)
+				l.DepartmentID, l.DepartmentName, l.Action = &d.ID, &d.Name, LegacyCreated
+			}
+		}
+		if l.Action == LegacySkipped {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE policies SET department_id = ?, department = '' WHERE department_id IS NULL AND department = ?`,
+			*l.DepartmentID, l.Value,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE policies SET department = '' WHERE department_id IS NOT NULL AND department <> ''`,
+	); err != nil {
+		return nil, err
+	}
+	return out, tx.Commit()
+}
+
+// FindDepartmentByLegacyName returns the department whose name exactly
+// matches a legacy department value after normalization, or nil.
+func (db *DB) FindDepartmentByLegacyName(ctx context.Context, value string) (*Department, error) {
+	depts, err := db.ListDepartments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := normalizeDepartment(value)
+	for _, d := range depts {
+		if normalizeDepartment(d.Name) == key {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func legacyValues(ctx context.Context, q querier) ([]*LegacyDepartment, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT department, COUNT(*) FROM policies
+		 WHERE department_id IS NULL AND trim(department) <> ''
+		 GROUP BY department ORDER BY COUNT(*) DESC, department`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []*LegacyDepartment{}
+	for rows.Next() {
+		l := &LegacyDepartment{}
+		if err := rows.Scan(&l.Value, &l.Policies); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// match finds the closest department to l.Value.
+func (l *LegacyDepartment) match(depts []*Department) {
+	key := normalizeDepartment(l.Value)
+	l.Match, l.DepartmentID, l.DepartmentName, l.Similarity = LegacyMatchNone, nil, nil, 0
+	for _, d := range depts {
+		score := similarity(key, normalizeDepartment(d.Name))
+		if score > l.Similarity {
+			l.Similarity, l.DepartmentID, l.DepartmentName = score, &d.ID, &d.Name
+		}
+	}
+	switch {
+	case l.Similarity == 1:
+		l.Match = LegacyMatchExact
+	case l.Similarity >= fuzzyThreshold:
+		l.Match = LegacyMatchFuzzy
+	default:
+		l.DepartmentID, l.DepartmentName, l.Similarity = nil, nil, 0
+	}
+}
+
+// normalizeDepartment lowercases a name, drops punctuation and the words
+// "department" and "dept", and collapses whitespace, so "HR Dept." and
+// "hr" compare equal.
+func normalizeDepartment(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := words[:0]
+	for _, w := range words {
+		if w != "department" && w != "dept" {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// similarity is 1 minus the edit distance relative to the longer string.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
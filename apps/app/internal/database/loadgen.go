@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoadSpec sizes a synthetic organization for load testing.
+type LoadSpec struct {
+	Departments int
+	Users       int
+	Policies    int
+	OrgWidePct  int    // share of policies visible organization-wide (0-100); the rest are per department
+	AckPct      int    // chance (0-100) that a user has acknowledged each policy required of them
+	Seed        uint64 // makes the departments, roles, and dates reproducible
+}
+
+// LoadSummary counts the rows GenerateLoad created.
+type LoadSummary struct {
+	Departments      int
+	Users            int
+	Policies         int
+	Acknowledgements int
+}
+
+// GenerateLoad adds a synthetic organization to db in one transaction:
+// departments, users spread across them (1 in 50 a DeptAdmin), published
+// policies with one version each created over the past year, and
+// acknowledgements by AckPct of the users each policy is required of.
+// Emails carry a per-run tag, so it can be run more than once.
+func (db *DB) GenerateLoad(ctx context.Context, spec LoadSpec) (*LoadSummary, error) {
+	if spec.Departments < 1 || spec.Users < 0 || spec.Policies < 0 {
+		return nil, fmt.Errorf("need at least one department and no negative counts")
+	}
+	r := rand.New(rand.NewPCG(spec.Seed, spec.Seed))
+	tag := uuid.New().String()[:8]
+	nowT := time.Now().UTC()
+	ts := nowT.Format(time.RFC3339)
+	daysAgo := func(max int) string {
+		return nowT.Add(-time.Duration(r.IntN(max*24)) * time.Hour).Format(time.RFC3339)
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Rows generated by this run come after these rowids.
+	var firstUser, firstPolicy int64
+	if err := tx.QueryRowContext(ctx,
+		`SELECT (SELECT COALESCE(MAX(rowid), 0) FROM users), (SELECT COALESCE(MAX(rowid), 0) FROM policies)`,
+	).Scan(&firstUser, &firstPolicy); err != nil {
+		return nil, err
+	}
+
+	deptIDs := make([]string, spec.Departments)
+	for i := range deptIDs {
+		deptIDs[i] = uuid.New().String()
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO departments (id, name, description, created_at, updated_at) VALUES (?,?,?,?,?)`,
+			deptIDs[i], fmt.Sprintf("Load %s Department %d", tag, i+1), "Generated for load testing", ts, ts); err != nil {
+			return nil, err
+		}
+	}
+
+	userStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO users (id, email, name, role, department_id, created_at) VALUES (?,?,?,?,?,?)`)
+	if err != nil {
+		return nil, err
+	}
+	defer userStmt.Close()
+	for i := range spec.Users {
+		role := "Staff"
+		if i%50 == 0 {
+			role = "DeptAdmin"
+		}
+		if _, err := userStmt.ExecContext(ctx, uuid.New().String(), fmt.Sprintf("user%d.%s@load.test", i+1, tag),
+			fmt.Sprintf("Load User %d", i+1), role, deptIDs[r.IntN(len(deptIDs))], daysAgo(730)); err != nil {
+			return nil, err
+		}
+	}
+
+	policyStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO policies (id, title, department, department_id, visibility_type, status, version, current_version_id, created_at, updated_at)
+		 VALUES (?,?,'',?,?,'Published',1,?,?,?)`)
+	if err != nil {
+		return nil, err
+	}
+	defer policyStmt.Close()
+	versionStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO policy_versions (id, policy_id, content, version_string, changelog, created_at, published_at) VALUES (?,?,?,?,'',?,?)`)
+	if err != nil {
+		return nil, err
+	}
+	defer versionStmt.Close()
+	for i := range spec.Policies {
+		policyID, versionID := uuid.New().String(), uuid.New().String()
+		deptID, visibility := &deptIDs[r.IntN(len(deptIDs))], "department"
+		if r.IntN(100) < spec.OrgWidePct {
+			visibility = "organization"
+		}
+		created := daysAgo(365)
+		if _, err := policyStmt.ExecContext(ctx, policyID, fmt.Sprintf("Load %s Policy %d", tag, i+1), deptID, visibility,
+			versionID, created, created); err != nil {
+			return nil, err
+		}
+		if _, err := versionStmt.ExecContext(ctx, versionID, policyID, fmt.Sprintf("# Policy %d\n\nGenerated for load testing.", i+1),
+			"v1.0", created, created); err != nil {
+			return nil, err
+		}
+	}
+
+	// Acknowledgements are chosen in SQL: at this size, doing it row by row
+	// from Go would take far longer than the insert itself. Generated
+	// policies have no assignments, so visibility decides who must
+	// acknowledge them; joining department policies on the department
+	// keeps that to the pairs that matter.
+	const ackSelect = `SELECT lower(hex(randomblob(16))), u.id, v.id,
+		        MIN(strftime('%Y-%m-%dT%H:%M:%SZ', v.created_at, '+' || (abs(random()) % 30) || ' days'), ?),
+		        lower(hex(randomblob(32)))
+		 FROM policies p
+		 JOIN policy_versions v ON v.id = p.current_version_id`
+	const ackWhere = ` WHERE p.rowid > ? AND u.rowid > ? AND abs(random()) % 100 < ?`
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO acknowledgements (id, user_id, policy_version_id, timestamp, signature_hash)
+		 `+ackSelect+` JOIN users u ON u.department_id = p.department_id`+ackWhere+` AND p.visibility_type = 'department'
+		 UNION ALL
+		 `+ackSelect+` CROSS JOIN users u`+ackWhere+` AND p.visibility_type = 'organization'`,
+		ts, firstPolicy, firstUser, spec.AckPct,
+		ts, firstPolicy, firstUser, spec.AckPct)
+	if err != nil {
+		return nil, err
+	}
+	acks, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &LoadSummary{Departments: spec.Departments, Users: spec.Users, Policies: spec.Policies, Acknowledgements: int(acks)}, nil
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PolicyLock is a soft, expiring claim that a user is editing a policy's
+// draft. Holders keep it alive by re-acquiring it before it expires.
+type PolicyLock struct {
+	PolicyID   string    `json:"policy_id"`
+	UserID     string    `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// AcquirePolicyLock takes or renews the lock on a policy for userID until
+// t+ttl. If someone else holds an unexpired lock, it is left alone and
+// returned instead, so callers compare the returned holder with userID.
+func (db *DB) AcquirePolicyLock(ctx context.Context, policyID, userID string, t time.Time, ttl time.Duration) (*PolicyLock, error) {
+	ts := t.UTC().Format(time.RFC3339)
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_locks (policy_id, user_id, acquired_at, expires_at) VALUES (?,?,?,?)
+		 ON CONFLICT(policy_id) DO UPDATE SET
+		   acquired_at = CASE WHEN policy_locks.user_id = excluded.user_id THEN policy_locks.acquired_at ELSE excluded.acquired_at END,
+		   user_id = excluded.user_id,
+		   expires_at = excluded.expires_at
+		 WHERE policy_locks.user_id = excluded.user_id OR policy_locks.expires_at <= ?`,
+		policyID, userID, ts, t.Add(ttl).UTC().Format(time.RFC3339), ts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.getPolicyLock(ctx, policyID)
+}
+
+// GetPolicyLock returns the lock on a policy if it is still held at t, or nil.
+func (db *DB) GetPolicyLock(ctx context.Context, policyID string, t time.Time) (*PolicyLock, error) {
+	l, err := db.getPolicyLock(ctx, policyID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !t.Before(l.ExpiresAt)) {
+		return nil, nil
+	}
+	return l, err
+}
+
+func (db *DB) getPolicyLock(ctx context.Context, policyID string) (*PolicyLock, error) {
+	l := &PolicyLock{}
+	var acquired, expires string
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT l.policy_id, l.user_id, u.name, l.acquired_at, l.expires_at
+		 FROM policy_locks l JOIN users u ON u.id = l.user_id WHERE l.policy_id = ?`, policyID,
+	).Scan(&l.PolicyID, &l.UserID, &l.UserName, &acquired, &expires)
+	if err != nil {
+		return nil, err
+	}
+	l.AcquiredAt = parseTime(acquired)
+	l.ExpiresAt = parseTime(expires)
+	return l, nil
+}
+
+// ReleasePolicyLock drops the lock on a policy. When userID is set, only
+// that user's lock is released.
+func (db *DB) ReleasePolicyLock(ctx context.Context, policyID, userID string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`DELETE FROM policy_locks WHERE policy_id = ? AND (? = '' OR user_id = ?)`, policyID, userID, userID)
+	return err
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Login event types.
+const (
+	LoginEventLinkIssued = "link_issued"
+	LoginEventCodeIssued = "code_issued"
+	LoginEventCodeFailed = "code_failed"
+	LoginEventLogin      = "login"
+)
+
+// MaxLoginCodeAttempts is how many wrong guesses void a sign-in code.
+const MaxLoginCodeAttempts = 5
+
+// LoginEvent records a magic-link or code issuance, a wrong sign-in code, or
+// a successful sign-in.
+type LoginEvent struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Event     string    `json:"event"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeviceKey identifies a browser for new-device detection. IP addresses are
+// left out because they change between networks far more often than devices.
+func DeviceKey(userAgent string) string {
+	sum := sha256.Sum256([]byte(userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+// RecordLoginEvent stores an event. For logins it also reports whether the
+// device is new, i.e. the user has signed in before but never from it.
+func (db *DB) RecordLoginEvent(ctx context.Context, userID, event, ipAddress, userAgent string) (newDevice bool, err error) {
+	key := DeviceKey(userAgent)
+	if event == LoginEventLogin {
+		var prior, known int
+		if err := db.conn.QueryRowContext(ctx,
+			`SELECT COUNT(*), COALESCE(SUM(CASE WHEN device_key = ? THEN 1 ELSE 0 END), 0)
+			 FROM login_events WHERE user_id = ? AND event = ?`,
+			key, userID, LoginEventLogin,
+		).Scan(&prior, &known); err != nil {
+			return false, err
+		}
+		newDevice = prior > 0 && known == 0
+	}
+	_, err = db.conn.ExecContext(ctx,
+		`INSERT INTO login_events (id, user_id, event, ip_address, user_agent, device_key, created_at) VALUES (?,?,?,?,?,?,?)`,
+		uuid.New().String(), userID, event, ipAddress, userAgent, key, time.Now().UTC().Format(time.RFC3339Nano),
+	)
+	return newDevice, err
+}
+
+// CountLoginEventsSince counts a user's events of one kind after since.
+func (db *DB) CountLoginEventsSince(ctx context.Context, userID, event string, since time.Time) (int, error) {
+	var n int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM login_events WHERE user_id = ? AND event = ? AND created_at > ?`,
+		userID, event, since.UTC().Format(time.RFC3339Nano),
+	).Scan(&n)
+	return n, err
+}
+
+// ListLoginEvents returns a user's most recent events, newest first.
+func (db *DB) ListLoginEvents(ctx context.Context, userID string, limit int) ([]*LoginEvent, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT id, user_id, event, ip_address, user_agent, created_at
+		 FROM login_events WHERE user_id = ? ORDER BY created_at DESC LIMIT ?`, userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*LoginEvent
+	for rows.Next() {
+		e := &LoginEvent{}
+		var createdAt string
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Event, &e.IPAddress, &e.UserAgent, &createdAt); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = parseTime(createdAt)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func loginCodeHash(userID, code string) string {
+	sum := sha256.Sum256([]byte(userID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetLoginCode stores code as the user's sign-in code until expiresAt,
+// replacing any earlier one.
+func (db *DB) SetLoginCode(ctx context.Context, userID, code string, expiresAt time.Time) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO login_codes (user_id, code_hash, expires_at, attempts) VALUES (?,?,?,0)
+		 ON CONFLICT(user_id) DO UPDATE SET code_hash=excluded.code_hash, expires_at=excluded.expires_at, attempts=0`,
+		userID, loginCodeHash(userID, code), expiresAt.UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+// UseLoginCode reports whether code is the user's current sign-in code,
+// consuming it if so. A wrong guess counts against the code, which is
+// discarded once it expires or MaxLoginCodeAttempts guesses have failed.
+func (db *DB) UseLoginCode(ctx context.Context, userID, code string) (bool, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var hash, expiresAt string
+	var attempts int
+	err = tx.QueryRowContext(ctx,
+		`SELECT code_hash, expires_at, attempts FROM login_codes WHERE user_id = ?`, userID,
+	).Scan(&hash, &expiresAt, &attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	match := subtle.ConstantTimeCompare([]byte(hash), []byte(loginCodeHash(userID, code))) == 1
+	live := time.Now().Before(parseTime(expiresAt)) && attempts < MaxLoginCodeAttempts
+	if match || !live || attempts+1 >= MaxLoginCodeAttempts {
+		_, err = tx.ExecContext(ctx, `DELETE FROM login_codes WHERE user_id = ?`, userID)
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE login_codes SET attempts = attempts + 1 WHERE user_id = ?`, userID)
+	}
+	if err != nil {
+		return false, err
+	}
+	return match && live, tx.Commit()
+}
@@ -1,19 +1,25 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"time"
 )
 
-// migration describes a single schema migration.
+// migration describes a single schema migration. down undoes sql, dropping
+// whatever data the migration added.
 type migration struct {
 	name string
 	sql  string
+	down string
 }
 
 // migrations is the ordered list of all schema changes.
-// Never remove or reorder — only append.
+// Never remove or reorder — only append. Give each one a down script that
+// undoes it; without one it cannot be reverted.
 var allMigrations = []migration{
 	{
 		name: "001_create_departments",
@@ -24,29 +30,701 @@ var allMigrations = []migration{
 	created_at  TEXT NOT NULL,
 	updated_at  TEXT NOT NULL
 );`,
+		down: `DROP TABLE IF EXISTS departments;`,
 	},
 	{
 		name: "002_users_add_department_id",
 		sql:  `ALTER TABLE users ADD COLUMN department_id TEXT REFERENCES departments(id);`,
+		down: `ALTER TABLE users DROP COLUMN department_id;`,
 	},
 	{
 		name: "003_policies_add_department_id",
 		sql:  `ALTER TABLE policies ADD COLUMN department_id TEXT REFERENCES departments(id);`,
+		down: `ALTER TABLE policies DROP COLUMN department_id;`,
 	},
 	{
 		name: "004_policies_add_visibility_type",
 		sql:  `ALTER TABLE policies ADD COLUMN visibility_type TEXT NOT NULL DEFAULT 'organization';`,
+		down: `ALTER TABLE policies DROP COLUMN visibility_type;`,
 	},
 	{
 		name: "005_roles_rename_admin_to_superadmin",
 		sql:  `UPDATE users SET role = 'SuperAdmin' WHERE role = 'Admin';`,
+		down: `UPDATE users SET role = 'Admin' WHERE role = 'SuperAdmin';`,
+	},
+	{
+		name: "006_create_policy_assignments",
+		sql: `CREATE TABLE IF NOT EXISTS policy_assignments (
+	id          TEXT PRIMARY KEY,
+	policy_id   TEXT NOT NULL REFERENCES policies(id),
+	target_type TEXT NOT NULL,
+	target_id   TEXT NOT NULL,
+	created_by  TEXT REFERENCES users(id),
+	created_at  TEXT NOT NULL,
+	UNIQUE(policy_id, target_type, target_id)
+);`,
+		down: `DROP TABLE IF EXISTS policy_assignments;`,
+	},
+	{
+		name: "007_create_policy_read_events",
+		sql: `CREATE TABLE IF NOT EXISTS policy_read_events (
+	id                TEXT PRIMARY KEY,
+	user_id           TEXT NOT NULL REFERENCES users(id),
+	policy_version_id TEXT NOT NULL REFERENCES policy_versions(id),
+	event             TEXT NOT NULL,
+	created_at        TEXT NOT NULL
+);`,
+		down: `DROP TABLE IF EXISTS policy_read_events;`,
+	},
+	{
+		name: "008_policies_add_ack_deadline",
+		sql:  `ALTER TABLE policies ADD COLUMN ack_deadline TEXT;`,
+		down: `ALTER TABLE policies DROP COLUMN ack_deadline;`,
+	},
+	{
+		name: "009_users_add_manager_id",
+		sql:  `ALTER TABLE users ADD COLUMN manager_id TEXT REFERENCES users(id);`,
+		down: `ALTER TABLE users DROP COLUMN manager_id;`,
+	},
+	{
+		name: "010_users_add_lifecycle",
+		sql: `ALTER TABLE users ADD COLUMN external_id TEXT;
+ALTER TABLE users ADD COLUMN deactivated_at TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_id ON users(external_id) WHERE external_id IS NOT NULL;`,
+		down: `DROP INDEX IF EXISTS idx_users_external_id;
+ALTER TABLE users DROP COLUMN deactivated_at;
+ALTER TABLE users DROP COLUMN external_id;`,
+	},
+	{
+		name: "011_create_hris_sync_runs",
+		sql: `CREATE TABLE IF NOT EXISTS hris_sync_runs (
+	id           TEXT PRIMARY KEY,
+	provider     TEXT NOT NULL,
+	triggered_by TEXT,
+	status       TEXT NOT NULL,
+	created      INTEGER NOT NULL DEFAULT 0,
+	updated      INTEGER NOT NULL DEFAULT 0,
+	deactivated  INTEGER NOT NULL DEFAULT 0,
+	skipped      INTEGER NOT NULL DEFAULT 0,
+	log          TEXT NOT NULL DEFAULT '',
+	started_at   TEXT NOT NULL,
+	finished_at  TEXT
+);`,
+		down: `DROP TABLE IF EXISTS hris_sync_runs;`,
+	},
+	{
+		name: "012_create_reminder_log",
+		sql: `CREATE TABLE IF NOT EXISTS reminder_log (
+	id                TEXT PRIMARY KEY,
+	user_id           TEXT NOT NULL REFERENCES users(id),
+	policy_version_id TEXT NOT NULL REFERENCES policy_versions(id),
+	sent_at           TEXT NOT NULL
+);`,
+		down: `DROP TABLE IF EXISTS reminder_log;`,
+	},
+	{
+		name: "013_policy_versions_add_published_at",
+		sql: `ALTER TABLE policy_versions ADD COLUMN published_at TEXT;
+UPDATE policy_versions SET published_at = created_at
+WHERE id IN (SELECT current_version_id FROM policies WHERE status = 'Published');`,
+		down: `ALTER TABLE policy_versions DROP COLUMN published_at;`,
+	},
+	{
+		name: "014_create_hot_path_indexes",
+		sql: `CREATE INDEX IF NOT EXISTS idx_acknowledgements_user_id ON acknowledgements(user_id);
+CREATE INDEX IF NOT EXISTS idx_acknowledgements_policy_version_id ON acknowledgements(policy_version_id);
+CREATE INDEX IF NOT EXISTS idx_policy_versions_policy_id ON policy_versions(policy_id);
+CREATE INDEX IF NOT EXISTS idx_policies_dept_visibility_status ON policies(department_id, visibility_type, status);
+CREATE INDEX IF NOT EXISTS idx_users_department_id ON users(department_id);`,
+		down: `DROP INDEX IF EXISTS idx_acknowledgements_user_id;
+DROP INDEX IF EXISTS idx_acknowledgements_policy_version_id;
+DROP INDEX IF EXISTS idx_policy_versions_policy_id;
+DROP INDEX IF EXISTS idx_policies_dept_visibility_status;
+DROP INDEX IF EXISTS idx_users_department_id;`,
+	},
+	{
+		name: "015_add_policy_version_counter",
+		sql: `ALTER TABLE policies ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE policies ADD COLUMN updated_at TEXT;
+UPDATE policies SET updated_at = created_at;`,
+		down: `ALTER TABLE policies DROP COLUMN updated_at;
+ALTER TABLE policies DROP COLUMN version;`,
+	},
+	{
+		// No foreign keys: authorship must survive the author's deletion.
+		// Existing rows are left unattributed, since who created them was
+		// never recorded.
+		name: "016_add_policy_attribution",
+		sql: `ALTER TABLE policies ADD COLUMN created_by TEXT;
+ALTER TABLE policies ADD COLUMN updated_by TEXT;
+ALTER TABLE policy_versions ADD COLUMN created_by TEXT;`,
+		down: `ALTER TABLE policy_versions DROP COLUMN created_by;
+ALTER TABLE policies DROP COLUMN updated_by;
+ALTER TABLE policies DROP COLUMN created_by;`,
+	},
+	{
+		name: "017_create_settings",
+		sql: `CREATE TABLE IF NOT EXISTS settings (
+	key        TEXT PRIMARY KEY,
+	value      TEXT NOT NULL,
+	updated_by TEXT,
+	updated_at TEXT NOT NULL
+);`,
+		down: `DROP TABLE IF EXISTS settings;`,
+	},
+	{
+		name: "018_users_add_last_login_at",
+		sql:  `ALTER TABLE users ADD COLUMN last_login_at TEXT;`,
+		down: `ALTER TABLE users DROP COLUMN last_login_at;`,
+	},
+	{
+		name: "019_policies_add_is_public",
+		sql:  `ALTER TABLE policies ADD COLUMN is_public INTEGER NOT NULL DEFAULT 0;`,
+		down: `ALTER TABLE policies DROP COLUMN is_public;`,
+	},
+	{
+		name: "020_create_policy_shares",
+		sql: `CREATE TABLE IF NOT EXISTS policy_shares (
+	id                TEXT PRIMARY KEY,
+	policy_id         TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	policy_version_id TEXT NOT NULL REFERENCES policy_versions(id) ON DELETE CASCADE,
+	created_by        TEXT,
+	expires_at        TEXT NOT NULL,
+	revoked_at        TEXT,
+	created_at        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_policy_shares_policy ON policy_shares(policy_id);
+CREATE TABLE IF NOT EXISTS policy_share_views (
+	id         TEXT PRIMARY KEY,
+	share_id   TEXT NOT NULL REFERENCES policy_shares(id) ON DELETE CASCADE,
+	ip_address TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	viewed_at  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_policy_share_views_share ON policy_share_views(share_id);`,
+		down: `DROP TABLE IF EXISTS policy_share_views;
+DROP TABLE IF EXISTS policy_shares;`,
+	},
+	{
+		name: "021_create_policy_attachments",
+		sql: `CREATE TABLE IF NOT EXISTS policy_attachments (
+	id           TEXT PRIMARY KEY,
+	policy_id    TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	filename     TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size_bytes   INTEGER NOT NULL,
+	sha256       TEXT NOT NULL,
+	storage_key  TEXT NOT NULL,
+	uploaded_by  TEXT,
+	created_at   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_policy_attachments_policy ON policy_attachments(policy_id);`,
+		down: `DROP TABLE IF EXISTS policy_attachments;`,
+	},
+	{
+		name: "022_users_add_anonymized_at",
+		sql:  `ALTER TABLE users ADD COLUMN anonymized_at TEXT;`,
+		down: `ALTER TABLE users DROP COLUMN anonymized_at;`,
+	},
+	{
+		// No foreign keys: the trail must outlive the users it mentions.
+		name: "023_create_audit_log",
+		sql: `CREATE TABLE IF NOT EXISTS audit_log (
+	id              TEXT PRIMARY KEY,
+	actor_id        TEXT,
+	impersonator_id TEXT,
+	action          TEXT NOT NULL,
+	target_type     TEXT NOT NULL DEFAULT '',
+	target_id       TEXT NOT NULL DEFAULT '',
+	details         TEXT NOT NULL DEFAULT '',
+	ip_address      TEXT NOT NULL DEFAULT '',
+	created_at      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id);`,
+		down: `DROP TABLE IF EXISTS audit_log;`,
+	},
+	{
+		name: "024_create_login_events",
+		sql: `CREATE TABLE IF NOT EXISTS login_events (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL REFERENCES users(id),
+	event      TEXT NOT NULL,
+	ip_address TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	device_key TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_login_events_user ON login_events(user_id, created_at);`,
+		down: `DROP TABLE IF EXISTS login_events;`,
+	},
+	{
+		// An empty secret stands for the JWT_SECRET environment key, whose
+		// row only records when it stops being accepted.
+		name: "025_create_jwt_keys",
+		sql: `CREATE TABLE IF NOT EXISTS jwt_keys (
+	kid        TEXT PRIMARY KEY,
+	secret     TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	expires_at TEXT
+);`,
+		down: `DROP TABLE IF EXISTS jwt_keys;`,
+	},
+	{
+		name: "026_create_report_schedules",
+		sql: `CREATE TABLE IF NOT EXISTS report_schedules (
+	id            TEXT PRIMARY KEY,
+	name          TEXT NOT NULL,
+	definition    TEXT NOT NULL,
+	frequency     TEXT NOT NULL,
+	format        TEXT NOT NULL DEFAULT 'csv',
+	recipients    TEXT NOT NULL,
+	department_id TEXT REFERENCES departments(id) ON DELETE CASCADE,
+	created_by    TEXT REFERENCES users(id) ON DELETE SET NULL,
+	created_at    TEXT NOT NULL,
+	next_run_at   TEXT NOT NULL,
+	last_run_at   TEXT,
+	last_error    TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_report_schedules_next ON report_schedules(next_run_at);`,
+		down: `DROP TABLE IF EXISTS report_schedules;`,
+	},
+	{
+		// relates_to links are symmetric and stored once, with the smaller
+		// policy ID first; supersedes reads policy_id supersedes related_policy_id.
+		name: "027_create_policy_relations",
+		sql: `CREATE TABLE IF NOT EXISTS policy_relations (
+	id                TEXT PRIMARY KEY,
+	policy_id         TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	related_policy_id TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	relation_type     TEXT NOT NULL,
+	created_by        TEXT REFERENCES users(id) ON DELETE SET NULL,
+	created_at        TEXT NOT NULL,
+	UNIQUE(policy_id, related_policy_id, relation_type),
+	CHECK(policy_id <> related_policy_id)
+);
+CREATE INDEX IF NOT EXISTS idx_policy_relations_related ON policy_relations(related_policy_id);`,
+		down: `DROP TABLE IF EXISTS policy_relations;`,
+	},
+	{
+		name: "028_create_policy_exceptions",
+		sql: `CREATE TABLE IF NOT EXISTS policy_exceptions (
+	id            TEXT PRIMARY KEY,
+	policy_id     TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	user_id       TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	justification TEXT NOT NULL,
+	duration_days INTEGER NOT NULL,
+	status        TEXT NOT NULL DEFAULT 'pending',
+	decided_by    TEXT REFERENCES users(id) ON DELETE SET NULL,
+	decision_note TEXT NOT NULL DEFAULT '',
+	decided_at    TEXT,
+	expires_at    TEXT,
+	created_at    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_policy_exceptions_policy ON policy_exceptions(policy_id, status);
+CREATE INDEX IF NOT EXISTS idx_policy_exceptions_user ON policy_exceptions(user_id);`,
+		down: `DROP TABLE IF EXISTS policy_exceptions;`,
+	},
+	{
+		name: "029_create_change_requests",
+		sql: `CREATE TABLE IF NOT EXISTS change_requests (
+	id                TEXT PRIMARY KEY,
+	policy_id         TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	policy_version_id TEXT REFERENCES policy_versions(id) ON DELETE SET NULL,
+	section           TEXT NOT NULL,
+	comment           TEXT NOT NULL,
+	status            TEXT NOT NULL DEFAULT 'open',
+	created_by        TEXT REFERENCES users(id) ON DELETE SET NULL,
+	resolved_by       TEXT REFERENCES users(id) ON DELETE SET NULL,
+	resolution_note   TEXT NOT NULL DEFAULT '',
+	resolved_at       TEXT,
+	created_at        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_change_requests_policy ON change_requests(policy_id, status);`,
+		down: `DROP TABLE IF EXISTS change_requests;`,
+	},
+	{
+		name: "030_create_policy_locks",
+		sql: `CREATE TABLE IF NOT EXISTS policy_locks (
+	policy_id   TEXT PRIMARY KEY REFERENCES policies(id) ON DELETE CASCADE,
+	user_id     TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	acquired_at TEXT NOT NULL,
+	expires_at  TEXT NOT NULL
+);`,
+		down: `DROP TABLE IF EXISTS policy_locks;`,
+	},
+	{
+		// A version can be backed by an uploaded original (a PDF) kept in
+		// blob storage; content then holds its extracted text.
+		name: "031_add_policy_version_source",
+		sql: `ALTER TABLE policy_versions ADD COLUMN source_filename TEXT;
+ALTER TABLE policy_versions ADD COLUMN source_content_type TEXT;
+ALTER TABLE policy_versions ADD COLUMN source_size INTEGER;
+ALTER TABLE policy_versions ADD COLUMN source_sha256 TEXT;
+ALTER TABLE policy_versions ADD COLUMN source_key TEXT;`,
+		down: `ALTER TABLE policy_versions DROP COLUMN source_key;
+ALTER TABLE policy_versions DROP COLUMN source_sha256;
+ALTER TABLE policy_versions DROP COLUMN source_size;
+ALTER TABLE policy_versions DROP COLUMN source_content_type;
+ALTER TABLE policy_versions DROP COLUMN source_filename;`,
+	},
+	{
+		// Keyset pagination walks acknowledgements by (timestamp, id).
+		name: "032_index_acknowledgement_pages",
+		sql: `CREATE INDEX IF NOT EXISTS idx_acknowledgements_version_page ON acknowledgements(policy_version_id, timestamp, id);
+CREATE INDEX IF NOT EXISTS idx_acknowledgements_user_page ON acknowledgements(user_id, timestamp, id);`,
+		down: `DROP INDEX IF EXISTS idx_acknowledgements_version_page;
+DROP INDEX IF EXISTS idx_acknowledgements_user_page;`,
+	},
+	{
+		// Organization edits to the built-in email templates; a template
+		// without a row uses the default.
+		name: "033_create_email_templates",
+		sql: `CREATE TABLE IF NOT EXISTS email_templates (
+	name       TEXT PRIMARY KEY,
+	subject    TEXT NOT NULL,
+	body       TEXT NOT NULL,
+	updated_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+	updated_at TEXT NOT NULL
+);`,
+		down: `DROP TABLE IF EXISTS email_templates;`,
+	},
+	{
+		// One outstanding sign-in code per user, stored hashed; requesting
+		// another replaces it.
+		name: "034_create_login_codes",
+		sql: `CREATE TABLE IF NOT EXISTS login_codes (
+	user_id    TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	code_hash  TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	attempts   INTEGER NOT NULL DEFAULT 0
+);`,
+		down: `DROP TABLE IF EXISTS login_codes;`,
+	},
+	{
+		// Departments a DeptAdmin administers besides their own.
+		name: "035_create_department_admins",
+		sql: `CREATE TABLE IF NOT EXISTS department_admins (
+	user_id       TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	department_id TEXT NOT NULL REFERENCES departments(id) ON DELETE CASCADE,
+	granted_by    TEXT REFERENCES users(id) ON DELETE SET NULL,
+	granted_at    TEXT NOT NULL,
+	PRIMARY KEY (user_id, department_id)
+);
+CREATE INDEX IF NOT EXISTS idx_department_admins_department ON department_admins(department_id);`,
+		down: `DROP TABLE IF EXISTS department_admins;`,
+	},
+	{
+		// Virus scan results for uploads; flagged files keep a quarantine key.
+		name: "036_create_upload_scans",
+		sql: `CREATE TABLE IF NOT EXISTS upload_scans (
+	id             TEXT PRIMARY KEY,
+	kind           TEXT NOT NULL,
+	policy_id      TEXT REFERENCES policies(id) ON DELETE SET NULL,
+	filename       TEXT NOT NULL,
+	size_bytes     INTEGER NOT NULL,
+	sha256         TEXT NOT NULL,
+	scanner        TEXT NOT NULL,
+	infected       INTEGER NOT NULL DEFAULT 0,
+	signature      TEXT NOT NULL DEFAULT '',
+	quarantine_key TEXT,
+	uploaded_by    TEXT REFERENCES users(id) ON DELETE SET NULL,
+	created_at     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_upload_scans_infected ON upload_scans(infected, created_at);`,
+		down: `DROP TABLE IF EXISTS upload_scans;`,
+	},
+	{
+		// Chunked uploads in progress; the bytes are in a file on local disk.
+		name: "037_create_uploads",
+		sql: `CREATE TABLE IF NOT EXISTS uploads (
+	id           TEXT PRIMARY KEY,
+	user_id      TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	filename     TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size_bytes   INTEGER NOT NULL,
+	received     INTEGER NOT NULL DEFAULT 0,
+	created_at   TEXT NOT NULL,
+	expires_at   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_uploads_expires ON uploads(expires_at);`,
+		down: `DROP TABLE IF EXISTS uploads;`,
+	},
+	{
+		// Organization-defined policy attributes and their values.
+		name: "038_create_custom_fields",
+		sql: `CREATE TABLE IF NOT EXISTS custom_fields (
+	id         TEXT PRIMARY KEY,
+	key        TEXT NOT NULL UNIQUE,
+	label      TEXT NOT NULL,
+	type       TEXT NOT NULL,
+	options    TEXT NOT NULL DEFAULT '[]',
+	position   INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS policy_field_values (
+	policy_id TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	field_id  TEXT NOT NULL REFERENCES custom_fields(id) ON DELETE CASCADE,
+	value     TEXT NOT NULL,
+	PRIMARY KEY (policy_id, field_id)
+);
+CREATE INDEX IF NOT EXISTS idx_policy_field_values_field ON policy_field_values(field_id, value);`,
+		down: `DROP TABLE IF EXISTS policy_field_values;
+DROP TABLE IF EXISTS custom_fields;`,
+	},
+	{
+		// A version can take effect on a future date and lapse after another;
+		// outside that window it cannot be acknowledged.
+		name: "039_add_version_effective_dates",
+		sql: `ALTER TABLE policy_versions ADD COLUMN effective_from TEXT;
+ALTER TABLE policy_versions ADD COLUMN effective_to TEXT;`,
+		down: `ALTER TABLE policy_versions DROP COLUMN effective_to;
+ALTER TABLE policy_versions DROP COLUMN effective_from;`,
+	},
+	{
+		// Named policy sets assigned to new users by department and role.
+		// assigned_by is NULL when a bundle was applied on account creation.
+		name: "040_create_policy_bundles",
+		sql: `CREATE TABLE IF NOT EXISTS policy_bundles (
+	id            TEXT PRIMARY KEY,
+	name          TEXT NOT NULL UNIQUE,
+	description   TEXT NOT NULL DEFAULT '',
+	department_id TEXT REFERENCES departments(id) ON DELETE CASCADE,
+	role          TEXT,
+	created_by    TEXT REFERENCES users(id) ON DELETE SET NULL,
+	created_at    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS policy_bundle_items (
+	bundle_id TEXT NOT NULL REFERENCES policy_bundles(id) ON DELETE CASCADE,
+	policy_id TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	position  INTEGER NOT NULL,
+	PRIMARY KEY (bundle_id, policy_id)
+);
+CREATE TABLE IF NOT EXISTS policy_bundle_assignments (
+	bundle_id   TEXT NOT NULL REFERENCES policy_bundles(id) ON DELETE CASCADE,
+	user_id     TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	assigned_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+	assigned_at TEXT NOT NULL,
+	PRIMARY KEY (bundle_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_policy_bundle_assignments_user ON policy_bundle_assignments(user_id);`,
+		down: `DROP TABLE IF EXISTS policy_bundle_assignments;
+DROP TABLE IF EXISTS policy_bundle_items;
+DROP TABLE IF EXISTS policy_bundles;`,
+	},
+	{
+		// Collections group policies into an ordered, handbook-like reading
+		// sequence for staff.
+		name: "041_create_collections",
+		sql: `CREATE TABLE IF NOT EXISTS collections (
+	id          TEXT PRIMARY KEY,
+	title       TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	position    INTEGER NOT NULL DEFAULT 0,
+	created_by  TEXT REFERENCES users(id) ON DELETE SET NULL,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS collection_items (
+	collection_id TEXT NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+	policy_id     TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	position      INTEGER NOT NULL,
+	PRIMARY KEY (collection_id, policy_id)
+);`,
+		down: `DROP TABLE IF EXISTS collection_items;
+DROP TABLE IF EXISTS collections;`,
+	},
+	{
+		// A policy can require an external training course; completions
+		// arrive from the LMS by webhook, keyed by the LMS's course ID.
+		name: "042_create_training",
+		sql: `CREATE TABLE IF NOT EXISTS policy_training (
+	policy_id   TEXT PRIMARY KEY REFERENCES policies(id) ON DELETE CASCADE,
+	course_id   TEXT NOT NULL,
+	course_name TEXT NOT NULL DEFAULT '',
+	course_url  TEXT NOT NULL DEFAULT '',
+	updated_by  TEXT REFERENCES users(id) ON DELETE SET NULL,
+	updated_at  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_policy_training_course ON policy_training(course_id);
+CREATE TABLE IF NOT EXISTS training_completions (
+	course_id    TEXT NOT NULL,
+	user_id      TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	completed_at TEXT NOT NULL,
+	received_at  TEXT NOT NULL,
+	PRIMARY KEY (course_id, user_id)
+);`,
+		down: `DROP TABLE IF EXISTS training_completions;
+DROP TABLE IF EXISTS policy_training;`,
+	},
+	{
+		// Compliance of a version at the moment it stopped being current,
+		// since eligibility is otherwise only computed for the current one.
+		name: "043_create_version_ack_snapshots",
+		sql: `CREATE TABLE IF NOT EXISTS version_ack_snapshots (
+	version_id          TEXT PRIMARY KEY REFERENCES policy_versions(id) ON DELETE CASCADE,
+	superseded_at       TEXT NOT NULL,
+	eligible_user_count INTEGER NOT NULL,
+	ack_count           INTEGER NOT NULL
+);`,
+		down: `DROP TABLE IF EXISTS version_ack_snapshots;`,
+	},
+	{
+		// Emails owed to users when a policy version is published, sent in
+		// the background; sent_at is set once delivered or no longer needed.
+		name: "044_create_publish_notifications",
+		sql: `CREATE TABLE IF NOT EXISTS publish_notifications (
+	policy_version_id TEXT NOT NULL REFERENCES policy_versions(id) ON DELETE CASCADE,
+	user_id           TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	created_at        TEXT NOT NULL,
+	sent_at           TEXT,
+	PRIMARY KEY (policy_version_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_publish_notifications_unsent ON publish_notifications(created_at) WHERE sent_at IS NULL;`,
+		down: `DROP TABLE IF EXISTS publish_notifications;`,
+	},
+	{
+		// Users putting off deadline reminders about a policy version; the
+		// reminder job skips the version until snoozed_until.
+		name: "045_create_reminder_snoozes",
+		sql: `CREATE TABLE IF NOT EXISTS reminder_snoozes (
+	user_id           TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	policy_version_id TEXT NOT NULL REFERENCES policy_versions(id) ON DELETE CASCADE,
+	snoozed_until     TEXT NOT NULL,
+	created_at        TEXT NOT NULL,
+	PRIMARY KEY (user_id, policy_version_id)
+);`,
+		down: `DROP TABLE IF EXISTS reminder_snoozes;`,
+	},
+	{
+		// Acknowledgements imported from paper or spreadsheet records are
+		// kept apart from those given in the app.
+		name: "046_add_acknowledgement_source",
+		sql:  `ALTER TABLE acknowledgements ADD COLUMN source TEXT NOT NULL DEFAULT 'app';`,
+		down: `ALTER TABLE acknowledgements DROP COLUMN source;`,
+	},
+	{
+		// Rules that turn HR attributes into roles and departments when
+		// users are provisioned.
+		name: "047_create_claim_rules",
+		sql: `
+CREATE TABLE IF NOT EXISTS claim_rules (
+	id            TEXT PRIMARY KEY,
+	attribute     TEXT NOT NULL,
+	operator      TEXT NOT NULL,
+	value         TEXT NOT NULL,
+	role          TEXT,
+	department_id TEXT REFERENCES departments(id) ON DELETE CASCADE,
+	position      INTEGER NOT NULL,
+	created_at    TEXT NOT NULL
+);`,
+		down: `DROP TABLE IF EXISTS claim_rules;`,
+	},
+	{
+		// The consolidated email owed to a user who joined a department;
+		// moving again before it is sent replaces it.
+		name: "048_create_department_welcomes",
+		sql: `
+CREATE TABLE IF NOT EXISTS department_welcomes (
+	user_id       TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	department_id TEXT NOT NULL REFERENCES departments(id) ON DELETE CASCADE,
+	created_at    TEXT NOT NULL,
+	sent_at       TEXT
+);`,
+		down: `DROP TABLE IF EXISTS department_welcomes;`,
+	},
+	{
+		// Acknowledgement campaigns, such as the annual policy refresh: a set
+		// of policies with an audience, a deadline, and reminder emails, each
+		// stage sent to a user once.
+		name: "049_create_campaigns",
+		sql: `
+CREATE TABLE IF NOT EXISTS campaigns (
+	id            TEXT PRIMARY KEY,
+	name          TEXT NOT NULL UNIQUE,
+	description   TEXT NOT NULL DEFAULT '',
+	starts_at     TEXT NOT NULL,
+	deadline      TEXT NOT NULL,
+	audience_type TEXT NOT NULL,
+	audience_id   TEXT,
+	reminder_days TEXT NOT NULL DEFAULT '',
+	created_by    TEXT REFERENCES users(id) ON DELETE SET NULL,
+	created_at    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS campaign_policies (
+	campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+	policy_id   TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	PRIMARY KEY (campaign_id, policy_id)
+);
+CREATE TABLE IF NOT EXISTS campaign_reminders (
+	campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+	user_id     TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	stage       TEXT NOT NULL,
+	sent_at     TEXT NOT NULL,
+	PRIMARY KEY (campaign_id, user_id, stage)
+);`,
+		down: `DROP TABLE IF EXISTS campaign_reminders;
+DROP TABLE IF EXISTS campaign_policies;
+DROP TABLE IF EXISTS campaigns;`,
+	},
+	{
+		// Questions and answers clarifying a policy, kept outside its
+		// versions so editing them needs no re-acknowledgement.
+		name: "050_create_policy_faqs",
+		sql: `
+CREATE TABLE IF NOT EXISTS policy_faqs (
+	id         TEXT PRIMARY KEY,
+	policy_id  TEXT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+	question   TEXT NOT NULL,
+	answer     TEXT NOT NULL,
+	position   INTEGER NOT NULL,
+	created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+	updated_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_policy_faqs_policy ON policy_faqs(policy_id, position);`,
+		down: `DROP TABLE IF EXISTS policy_faqs;`,
+	},
+	{
+		// Set when a schedule's creator can no longer send it; disabled
+		// schedules are skipped until handed to another owner.
+		name: "051_report_schedules_add_disabled_at",
+		sql:  `ALTER TABLE report_schedules ADD COLUMN disabled_at TEXT;`,
+		down: `ALTER TABLE report_schedules DROP COLUMN disabled_at;`,
 	},
 }
 
+// MigrationStep is one migration to apply, or to undo when Down is set.
+type MigrationStep struct {
+	Name string
+	SQL  string
+	Down bool
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Name       string
+	AppliedAt  *time.Time
+	Reversible bool
+}
+
+// ErrIrreversible is returned when asked to undo a migration that has no
+// down script.
+var ErrIrreversible = errors.New("migration cannot be undone")
+
 // Migrate runs any pending schema migrations. Safe to call on every startup.
-func (db *DB) Migrate() error {
-	// Create the migrations tracking table.
-	_, err := db.conn.Exec(`
+func (db *DB) Migrate(ctx context.Context) error {
+	plan, err := db.PlanUp(ctx, 0)
+	if err != nil {
+		return err
+	}
+	return db.ApplyMigrations(ctx, plan)
+}
+
+func (db *DB) ensureMigrationsTable(ctx context.Context) error {
+	_, err := db.conn.ExecContext(ctx, `
 CREATE TABLE IF NOT EXISTS schema_migrations (
 	name       TEXT PRIMARY KEY,
 	applied_at TEXT NOT NULL
@@ -54,28 +732,201 @@ CREATE TABLE IF NOT EXISTS schema_migrations (
 	if err != nil {
 		return fmt.Errorf("create schema_migrations: %w", err)
 	}
+	return nil
+}
+
+// MigrationStatuses lists every migration in the order they run, with when
+// each was applied.
+func (db *DB) MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	if err := db.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.QueryContext(ctx, `SELECT name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[string]time.Time{}
+	for rows.Next() {
+		var name, at string
+		if err := rows.Scan(&name, &at); err != nil {
+			return nil, err
+		}
+		applied[name] = parseTime(at)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]MigrationStatus, len(allMigrations))
+	for i, m := range allMigrations {
+		out[i] = MigrationStatus{Name: m.name, Reversible: m.down != ""}
+		if at, ok := applied[m.name]; ok {
+			out[i].AppliedAt = &at
+		}
+	}
+	return out, nil
+}
+
+// PlanUp returns the next steps pending migrations in order, or all of them
+// when steps is 0.
+func (db *DB) PlanUp(ctx context.Context, steps int) ([]MigrationStep, error) {
+	statuses, err := db.MigrationStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var plan []MigrationStep
+	for i, st := range statuses {
+		if st.AppliedAt == nil && (steps == 0 || len(plan) < steps) {
+			plan = append(plan, MigrationStep{Name: st.Name, SQL: allMigrations[i].sql})
+		}
+	}
+	return plan, nil
+}
 
+// PlanDown returns the steps to undo the last steps applied migrations,
+// latest first. Migrations applied together are undone in reverse list
+// order. It fails with ErrIrreversible if one of them has no down script.
+func (db *DB) PlanDown(ctx context.Context, steps int) ([]MigrationStep, error) {
+	statuses, err := db.MigrationStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	var applied []MigrationStatus
+	for i, st := range statuses {
+		index[st.Name] = i
+		if st.AppliedAt != nil {
+			applied = append(applied, st)
+		}
+	}
+	sort.SliceStable(applied, func(a, b int) bool {
+		if !applied[a].AppliedAt.Equal(*applied[b].AppliedAt) {
+			return applied[a].AppliedAt.After(*applied[b].AppliedAt)
+		}
+		return index[applied[a].Name] > index[applied[b].Name]
+	})
+	var plan []MigrationStep
+	for _, st := range applied[:min(steps, len(applied))] {
+		m := allMigrations[index[st.Name]]
+		if m.down == "" {
+			return nil, fmt.Errorf("%s: %w", m.name, ErrIrreversible)
+		}
+		plan = append(plan, MigrationStep{Name: m.name, SQL: m.down, Down: true})
+	}
+	return plan, nil
+}
+
+// MigrationSQL returns the up script of the named migration, or "" if
+// there is none by that name.
+func (db *DB) MigrationSQL(name string) string {
 	for _, m := range allMigrations {
-		var existing string
-		err := db.conn.QueryRow(
-			`SELECT name FROM schema_migrations WHERE name = ?`, m.name,
-		).Scan(&existing)
-		if err == nil {
-			// Already applied.
-			continue
+		if m.name == name {
+			return m.sql
 		}
+	}
+	return ""
+}
 
-		log.Printf("Applying migration: %s", m.name)
-		if _, err := db.conn.Exec(m.sql); err != nil {
-			return fmt.Errorf("migration %s: %w", m.name, err)
+// ApplyMigrations runs the steps in order, each in its own transaction
+// with its schema_migrations bookkeeping.
+func (db *DB) ApplyMigrations(ctx context.Context, plan []MigrationStep) error {
+	if err := db.ensureMigrationsTable(ctx); err != nil {
+		return err
+	}
+	for _, step := range plan {
+		verb, done := "Applying", "Applied"
+		if step.Down {
+			verb, done = "Reverting", "Reverted"
 		}
-		if _, err := db.conn.Exec(
-			`INSERT INTO schema_migrations (name, applied_at) VALUES (?, ?)`,
-			m.name, time.Now().UTC().Format(time.RFC3339),
-		); err != nil {
-			return fmt.Errorf("record migration %s: %w", m.name, err)
+		log.Printf("%s migration: %s", verb, step.Name)
+		if err := db.applyStep(ctx, step); err != nil {
+			return fmt.Errorf("migration %s: %w", step.Name, err)
 		}
-		log.Printf("  Applied: %s", m.name)
+		log.Printf("  %s: %s", done, step.Name)
 	}
 	return nil
 }
+
+func (db *DB) applyStep(ctx context.Context, step MigrationStep) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, step.SQL); err != nil {
+		return err
+	}
+	if step.Down {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE name = ?`, step.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (name, applied_at) VALUES (?, ?)`,
+			step.Name, time.Now().UTC().Format(time.RFC3339))
+	}
+	if err != nil {
+		return fmt.Errorf("record: %w", err)
+	}
+	return tx.Commit()
+}
+
+// PendingMigrations returns the names of the migrations not yet applied, in
+// order. While a separate migration job runs they are the ones it has left.
+func (db *DB) PendingMigrations(ctx context.Context) ([]string, error) {
+	applied := map[string]bool{}
+	var tracked int
+	if err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+	).Scan(&tracked); err != nil {
+		return nil, err
+	}
+	if tracked > 0 {
+		rows, err := db.conn.QueryContext(ctx, `SELECT name FROM schema_migrations`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, err
+			}
+			applied[name] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	var pending []string
+	for _, m := range allMigrations {
+		if !applied[m.name] {
+			pending = append(pending, m.name)
+		}
+	}
+	return pending, nil
+}
+
+// CheckWritable takes and releases the database write lock, failing when
+// another writer, such as a migration job, holds it for longer than wait.
+func (db *DB) CheckWritable(ctx context.Context, wait time.Duration) error {
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// The connection's own busy timeout is longer than a probe can wait,
+	// and SQLite does not give it up when ctx ends.
+	var busy int
+	if err := conn.QueryRowContext(ctx, `PRAGMA busy_timeout`).Scan(&busy); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(`PRAGMA busy_timeout = %d`, wait.Milliseconds())); err != nil {
+		return err
+	}
+	// Restore it, and roll back, even if ctx has just ended, so the
+	// connection goes back to the pool as it was.
+	defer conn.ExecContext(context.WithoutCancel(ctx), fmt.Sprintf(`PRAGMA busy_timeout = %d`, busy))
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(context.WithoutCancel(ctx), `ROLLBACK`)
+	return err
+}
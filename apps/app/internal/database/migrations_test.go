@@ -0,0 +1,48 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"policyflow/internal/database"
+)
+
+// TestMigrations_DownAndUpAgain verifies that every migration can be
+// undone in turn with data in the tables, and applied again afterwards.
+func TestMigrations_DownAndUpAgain(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, ":memory:")
+	if _, err := db.GenerateLoad(ctx, database.LoadSpec{Departments: 2, Users: 10, Policies: 3, AckPct: 50, Seed: 1}); err != nil {
+		t.Fatalf("GenerateLoad: %v", err)
+	}
+
+	statuses, err := db.MigrationStatuses(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatuses: %v", err)
+	}
+	plan, err := db.PlanDown(ctx, len(statuses))
+	if err != nil {
+		t.Fatalf("PlanDown: %v", err)
+	}
+	if len(plan) != len(statuses) || plan[0].Name != statuses[len(statuses)-1].Name {
+		t.Fatalf("down plan has %d steps starting at %s; want %d starting at the last migration", len(plan), plan[0].Name, len(statuses))
+	}
+	if err := db.ApplyMigrations(ctx, plan); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if pending, _ := db.PendingMigrations(ctx); len(pending) != len(statuses) {
+		t.Errorf("after undoing all: %d pending; want %d", len(pending), len(statuses))
+	}
+
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("up again: %v", err)
+	}
+	if pending, _ := db.PendingMigrations(ctx); len(pending) != 0 {
+		t.Errorf("after migrating again: pending %v", pending)
+	}
+	redo, _ := db.PlanDown(ctx, 1)
+	up, _ := db.PlanUp(ctx, 0)
+	if len(redo) != 1 || len(up) != 0 {
+		t.Errorf("redo plan %v, up plan %v; want one down step and nothing pending", redo, up)
+	}
+}
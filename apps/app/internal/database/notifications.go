@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// PublishNotification is an email owed to a user about a newly published
+// policy version. Stale is set when the version is no longer current or the
+// user has already acknowledged it, so the email is no longer needed.
+type PublishNotification struct {
+	PolicyVersionID string
+	UserID          string
+	UserEmail       string
+	UserName        string
+	PolicyID        string
+	PolicyTitle     string
+	VersionString   string
+	Changelog       string
+	Stale           bool
+}
+
+// EnqueuePublishNotifications queues an email about a published policy's
+// current version for every active user who can see it and has not yet
+// acknowledged it, returning the users newly queued. Users already queued
+// for the version are skipped.
+func (db *DB) EnqueuePublishNotifications(ctx context.Context, policyID string) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`INSERT INTO publish_notifications (policy_version_id, user_id, created_at)
+		 SELECT p.current_version_id, u.id, ?
+		 FROM policies p
+		 JOIN users u ON u.deactivated_at IS NULL AND (
+		      p.visibility_type = 'organization'
+		   OR (p.visibility_type = 'department' AND p.department_id = u.department_id)
+		   OR EXISTS (SELECT 1 FROM policy_assignments a WHERE a.policy_id = p.id AND (
+		        (a.target_type = 'user' AND a.target_id = u.id)
+		     OR (a.target_type = 'role' AND a.target_id = u.role)
+		     OR (a.target_type = 'department' AND a.target_id = u.department_id))))
+		 WHERE p.id = ? AND p.status = 'Published' AND p.current_version_id IS NOT NULL
+		   AND NOT EXISTS (SELECT 1 FROM acknowledgements ak WHERE ak.user_id = u.id AND ak.policy_version_id = p.current_version_id)
+		 ON CONFLICT (policy_version_id, user_id) DO NOTHING
+		 RETURNING user_id`,
+		now(), policyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+// ListUnsentPublishNotifications returns up to limit queued notifications,
+// oldest first.
+func (db *DB) ListUnsentPublishNotifications(ctx context.Context, limit int) ([]*PublishNotification, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT n.policy_version_id, n.user_id, u.email, u.name, p.id, p.title, v.version_string, v.changelog,
+		        p.current_version_id IS NOT v.id OR p.status <> 'Published' OR u.deactivated_at IS NOT NULL
+		        OR EXISTS (SELECT 1 FROM acknowledgements ak WHERE ak.user_id = u.id AND ak.policy_version_id = v.id)
+		 FROM publish_notifications n
+		 JOIN users u ON u.id = n.user_id
+		 JOIN policy_versions v ON v.id = n.policy_version_id
+		 JOIN policies p ON p.id = v.policy_id
+		 WHERE n.sent_at IS NULL ORDER BY n.created_at LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*PublishNotification
+	for rows.Next() {
+		n := &PublishNotification{}
+		var changelog sql.NullString
+		if err := rows.Scan(&n.PolicyVersionID, &n.UserID, &n.UserEmail, &n.UserName, &n.PolicyID, &n.PolicyTitle,
+			&n.VersionString, &changelog, &n.Stale); err != nil {
+			return nil, err
+		}
+		n.Changelog = changelog.String
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// MarkPublishNotificationSent records that a queued notification was sent
+// or is no longer needed.
+func (db *DB) MarkPublishNotificationSent(ctx context.Context, versionID, userID string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE publish_notifications SET sent_at = ? WHERE policy_version_id = ? AND user_id = ?`,
+		now(), versionID, userID)
+	return err
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// OwnershipTransfer counts what moved from one user to another when the
+// first was removed.
+type OwnershipTransfer struct {
+	Policies        int64 `json:"policies"`
+	Drafts          int64 `json:"drafts"`          // of Policies, those not yet published
+	PendingReviews  int64 `json:"pending_reviews"` // exception requests awaiting the owner
+	ChangeRequests  int64 `json:"change_requests"`
+	ReportSchedules int64 `json:"report_schedules"`
+}
+
+// transferOwnership makes to the owner of the policies from created, and
+// with them the exception requests awaiting the owner's decision, and hands
+// over from's open change requests and report schedules, re-enabling any
+// schedule disabled for want of an owner. Edit locks held by from are
+// released. Version authorship is history and stays as it is.
+func transferOwnership(ctx context.Context, tx *sql.Tx, from, to string) (*OwnershipTransfer, error) {
+	out := &OwnershipTransfer{}
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*),
+		        COUNT(*) FILTER (WHERE status IN ('Draft', 'Review')),
+		        COALESCE(SUM((SELECT COUNT(*) FROM policy_exceptions e WHERE e.policy_id = p.id AND e.status = 'pending')), 0)
+		 FROM policies p WHERE created_by = ?`, from,
+	).Scan(&out.Policies, &out.Drafts, &out.PendingReviews); err != nil {
+		return nil, err
+	}
+	for _, u := range []struct {
+		query string
+		count *int64
+	}{
+		{`UPDATE policies SET created_by=? WHERE created_by=?`, nil},
+		{`UPDATE change_requests SET created_by=? WHERE created_by=? AND status='open'`, &out.ChangeRequests},
+		{`UPDATE report_schedules SET created_by=?, disabled_at=NULL WHERE created_by=?`, &out.ReportSchedules},
+	} {
+		res, err := tx.ExecContext(ctx, u.query, to, from)
+		if err != nil {
+			return nil, err
+		}
+		if u.count != nil {
+			if *u.count, err = res.RowsAffected(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM policy_locks WHERE user_id=?`, from); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
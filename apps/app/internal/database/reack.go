@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// ReackGap is a user who acknowledged an earlier version of a policy they
+// must acknowledge but not its current version.
+type ReackGap struct {
+	UserID              string    `json:"user_id"`
+	UserName            string    `json:"user_name"`
+	UserEmail           string    `json:"user_email"`
+	DepartmentID        *string   `json:"department_id"`
+	Department          string    `json:"department"`
+	PolicyID            string    `json:"policy_id"`
+	PolicyTitle         string    `json:"policy_title"`
+	CurrentVersion      string    `json:"current_version"`
+	AcknowledgedVersion string    `json:"acknowledged_version"` // the latest one they did acknowledge
+	AcknowledgedAt      time.Time `json:"acknowledged_at"`
+}
+
+// ReackPolicySummary counts a policy's re-acknowledgement gaps.
+type ReackPolicySummary struct {
+	PolicyID       string `json:"policy_id"`
+	PolicyTitle    string `json:"policy_title"`
+	CurrentVersion string `json:"current_version"`
+	Users          int    `json:"users"`
+}
+
+// ReackDepartmentSummary counts a department's re-acknowledgement gaps and
+// the users they belong to.
+type ReackDepartmentSummary struct {
+	DepartmentID *string `json:"department_id"`
+	Department   string  `json:"department"`
+	Users        int     `json:"users"`
+	Gaps         int     `json:"gaps"`
+}
+
+// ReackGapReport lists the re-acknowledgement gaps with totals per policy
+// and per department, departments by name.
+type ReackGapReport struct {
+	ByPolicy     []*ReackPolicySummary     `json:"by_policy"`
+	ByDepartment []*ReackDepartmentSummary `json:"by_department"`
+	Gaps         []*ReackGap               `json:"gaps"`
+}
+
+// ListReackGaps finds active users who acknowledged an earlier version of a
+// published policy that is still required of them but have not acknowledged
+// the current version, only those in deptID when it is set. Gaps are ordered
+// by policy, department, and user.
+func (db *DB) ListReackGaps(ctx context.Context, deptID *string) (*ReackGapReport, error) {
+	query := `SELECT u.id, u.name, u.email, u.department_id, d.name, p.id, p.title, cv.version_string,
+	       lv.version_string, la.timestamp
+	FROM policies p
+	JOIN policy_versions cv ON cv.id = p.current_version_id
+	JOIN users u ON u.deactivated_at IS NULL AND ` + requiredForUser + `
+	JOIN acknowledgements la ON la.id = (
+		SELECT a.id FROM acknowledgements a JOIN policy_versions v ON v.id = a.policy_version_id
+		WHERE a.user_id = u.id AND v.policy_id = p.id ORDER BY a.timestamp DESC LIMIT 1)
+	JOIN policy_versions lv ON lv.id = la.policy_version_id
+	LEFT JOIN departments d ON d.id = u.department_id
+	WHERE p.status = 'Published'
+	  AND NOT EXISTS (SELECT 1 FROM acknowledgements a WHERE a.user_id = u.id AND a.policy_version_id = p.current_version_id)`
+	var args []any
+	if deptID != nil {
+		query += ` AND u.department_id = ?`
+		args = append(args, *deptID)
+	}
+	rows, err := db.conn.QueryContext(ctx, query+` ORDER BY p.title, p.id, d.name, u.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := &ReackGapReport{ByPolicy: []*ReackPolicySummary{}, ByDepartment: []*ReackDepartmentSummary{}, Gaps: []*ReackGap{}}
+	policies := map[string]*ReackPolicySummary{}
+	departments := map[string]*ReackDepartmentSummary{}
+	seen := map[string]bool{}
+	for rows.Next() {
+		g := &ReackGap{}
+		var userDeptID, deptName sql.NullString
+		var ackedAt string
+		if err := rows.Scan(&g.UserID, &g.UserName, &g.UserEmail, &userDeptID, &deptName, &g.PolicyID, &g.PolicyTitle,
+			&g.CurrentVersion, &g.AcknowledgedVersion, &ackedAt); err != nil {
+			return nil, err
+		}
+		g.DepartmentID = nullString(userDeptID)
+		g.Department = UserDepartment(&User{DepartmentName: nullString(deptName)})
+		g.AcknowledgedAt = parseTime(ackedAt)
+		r.Gaps = append(r.Gaps, g)
+
+		ps := policies[g.PolicyID]
+		if ps == nil {
+			ps = &ReackPolicySummary{PolicyID: g.PolicyID, PolicyTitle: g.PolicyTitle, CurrentVersion: g.CurrentVersion}
+			policies[g.PolicyID] = ps
+			r.ByPolicy = append(r.ByPolicy, ps)
+		}
+		ps.Users++
+
+		key := deref(g.DepartmentID)
+		ds := departments[key]
+		if ds == nil {
+			ds = &ReackDepartmentSummary{DepartmentID: g.DepartmentID, Department: g.Department}
+			departments[key] = ds
+			r.ByDepartment = append(r.ByDepartment, ds)
+		}
+		ds.Gaps++
+		if !seen[g.UserID] {
+			seen[g.UserID] = true
+			ds.Users++
+		}
+	}
+	sort.SliceStable(r.ByDepartment, func(i, j int) bool { return r.ByDepartment[i].Department < r.ByDepartment[j].Department })
+	return r, rows.Err()
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Read event types reported by the client while a policy is on screen.
+const (
+	ReadEventOpen           = "open"
+	ReadEventScrollComplete = "scroll_complete"
+)
+
+// ReadStatus summarises a user's reading of one policy version.
+type ReadStatus struct {
+	PolicyVersionID   string     `json:"policy_version_id"`
+	OpenCount         int        `json:"open_count"`
+	FirstOpenedAt     *time.Time `json:"first_opened_at"`
+	ScrollCompletedAt *time.Time `json:"scroll_completed_at"`
+}
+
+func (db *DB) RecordReadEvent(ctx context.Context, userID, policyVersionID, event string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_read_events (id, user_id, policy_version_id, event, created_at) VALUES (?,?,?,?,?)`,
+		uuid.New().String(), userID, policyVersionID, event, time.Now().UTC().Format(time.RFC3339Nano),
+	)
+	return err
+}
+
+func (db *DB) GetReadStatus(ctx context.Context, userID, policyVersionID string) (*ReadStatus, error) {
+	s := &ReadStatus{PolicyVersionID: policyVersionID}
+	var firstOpen, scrolled sql.NullString
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT
+		   COALESCE(SUM(CASE WHEN event = 'open' THEN 1 ELSE 0 END), 0),
+		   MIN(CASE WHEN event = 'open' THEN created_at END),
+		   MIN(CASE WHEN event = 'scroll_complete' THEN created_at END)
+		 FROM policy_read_events WHERE user_id = ? AND policy_version_id = ?`,
+		userID, policyVersionID,
+	).Scan(&s.OpenCount, &firstOpen, &scrolled)
+	if err != nil {
+		return nil, err
+	}
+	if firstOpen.Valid {
+		t := parseTime(firstOpen.String)
+		s.FirstOpenedAt = &t
+	}
+	if scrolled.Valid {
+		t := parseTime(scrolled.String)
+		s.ScrollCompletedAt = &t
+	}
+	return s, nil
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// AckReceipt is an acknowledgement with what a receipt or certificate of
+// it shows: who acknowledged which policy version.
+type AckReceipt struct {
+	Acknowledgement
+	UserName      string
+	UserEmail     string
+	PolicyID      string
+	PolicyTitle   string
+	VersionString string
+	ContentHash   string // SHA-256 of the version content, as on the print view
+}
+
+// GetAckReceipt returns acknowledgement id with its user and policy
+// version. It returns sql.ErrNoRows if there is no such acknowledgement.
+func (db *DB) GetAckReceipt(ctx context.Context, id string) (*AckReceipt, error) {
+	r := &AckReceipt{}
+	var ts, content string
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT a.id, a.user_id, a.policy_version_id, a.timestamp, a.signature_hash, a.source,
+		        u.name, u.email, p.id, p.title, v.version_string, v.content
+		 FROM acknowledgements a
+		 JOIN users u ON u.id = a.user_id
+		 JOIN policy_versions v ON v.id = a.policy_version_id
+		 JOIN policies p ON p.id = v.policy_id
+		 WHERE a.id = ?`, id,
+	).Scan(&r.ID, &r.UserID, &r.PolicyVersionID, &ts, &r.SignatureHash, &r.Source,
+		&r.UserName, &r.UserEmail, &r.PolicyID, &r.PolicyTitle, &r.VersionString, &content)
+	if err != nil {
+		return nil, err
+	}
+	r.Timestamp = parseTime(ts)
+	sum := sha256.Sum256([]byte(content))
+	r.ContentHash = hex.EncodeToString(sum[:])
+	return r, nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Relation types, as stored and as seen from each side of the link.
+const (
+	RelationSupersedes   = "supersedes"
+	RelationSupersededBy = "superseded_by" // the reverse of supersedes; never stored
+	RelationRelatesTo    = "relates_to"
+)
+
+// PolicyRelation links two policies. A supersedes link reads "PolicyID
+// supersedes RelatedPolicyID"; relates_to links have no direction.
+type PolicyRelation struct {
+	ID              string    `json:"id"`
+	PolicyID        string    `json:"policy_id"`
+	RelatedPolicyID string    `json:"related_policy_id"`
+	Type            string    `json:"type"`
+	CreatedBy       *string   `json:"created_by,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// RelatedPolicy is the policy at the other end of a relation, with the
+// relation as seen from the policy it was listed for.
+type RelatedPolicy struct {
+	RelationID string `json:"relation_id"`
+	Relation   string `json:"relation"` // supersedes | superseded_by | relates_to
+	*Policy
+}
+
+// CreatePolicyRelation links policyID to relatedID. Creating a link that
+// already exists, in either direction for relates_to, returns the existing row.
+func (db *DB) CreatePolicyRelation(ctx context.Context, policyID, relatedID, relationType string, createdBy *string) (*PolicyRelation, error) {
+	if relationType == RelationRelatesTo && relatedID < policyID {
+		policyID, relatedID = relatedID, policyID
+	}
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_relations (id, policy_id, related_policy_id, relation_type, created_by, created_at)
+		 VALUES (?,?,?,?,?,?) ON CONFLICT(policy_id, related_policy_id, relation_type) DO NOTHING`,
+		uuid.New().String(), policyID, relatedID, relationType, createdBy, now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.scanRelation(db.conn.QueryRowContext(ctx,
+		relationSelect+` WHERE policy_id = ? AND related_policy_id = ? AND relation_type = ?`,
+		policyID, relatedID, relationType,
+	))
+}
+
+const relationSelect = `SELECT id, policy_id, related_policy_id, relation_type, created_by, created_at FROM policy_relations`
+
+func (db *DB) GetPolicyRelation(ctx context.Context, id string) (*PolicyRelation, error) {
+	return db.scanRelation(db.conn.QueryRowContext(ctx, relationSelect+` WHERE id = ?`, id))
+}
+
+// ListRelatedPolicies returns the policies linked to policyID in either
+// direction, ordered by relation and then title.
+func (db *DB) ListRelatedPolicies(ctx context.Context, policyID string) ([]*RelatedPolicy, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		relationSelect+` WHERE policy_id = ? OR related_policy_id = ?`, policyID, policyID)
+	if err != nil {
+		return nil, err
+	}
+	var relations []*PolicyRelation
+	for rows.Next() {
+		r, err := db.scanRelation(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		relations = append(relations, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]*RelatedPolicy, 0, len(relations))
+	for _, r := range relations {
+		other, relation := r.RelatedPolicyID, r.Type
+		if other == policyID {
+			other = r.PolicyID
+			if r.Type == RelationSupersedes {
+				relation = RelationSupersededBy
+			}
+		}
+		p, err := db.GetPolicy(ctx, other)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &RelatedPolicy{RelationID: r.ID, Relation: relation, Policy: p})
+	}
+	sortRelated(out)
+	return out, nil
+}
+
+func sortRelated(rs []*RelatedPolicy) {
+	order := map[string]int{RelationSupersedes: 0, RelationSupersededBy: 1, RelationRelatesTo: 2}
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Relation != rs[j].Relation {
+			return order[rs[i].Relation] < order[rs[j].Relation]
+		}
+		return rs[i].Title < rs[j].Title
+	})
+}
+
+func (db *DB) DeletePolicyRelation(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM policy_relations WHERE id = ?`, id)
+	return err
+}
+
+func (db *DB) scanRelation(row scanner) (*PolicyRelation, error) {
+	r := &PolicyRelation{}
+	var createdBy sql.NullString
+	var createdAt string
+	if err := row.Scan(&r.ID, &r.PolicyID, &r.RelatedPolicyID, &r.Type, &createdBy, &createdAt); err != nil {
+		return nil, err
+	}
+	r.CreatedBy = nullString(createdBy)
+	r.CreatedAt = parseTime(createdAt)
+	return r, nil
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderSentSince reports whether the user was reminded about the version at or after since.
+func (db *DB) ReminderSentSince(ctx context.Context, userID, policyVersionID string, since time.Time) (bool, error) {
+	var count int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM reminder_log WHERE user_id=? AND policy_version_id=? AND sent_at >= ?`,
+		userID, policyVersionID, since.UTC().Format(time.RFC3339),
+	).Scan(&count)
+	return count > 0, err
+}
+
+func (db *DB) RecordReminder(ctx context.Context, userID, policyVersionID string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO reminder_log (id, user_id, policy_version_id, sent_at) VALUES (?,?,?,?)`,
+		uuid.New().String(), userID, policyVersionID, now(),
+	)
+	return err
+}
+
+// SnoozeReminders stops deadline reminders to the user about the version
+// until the given time, replacing any earlier snooze.
+func (db *DB) SnoozeReminders(ctx context.Context, userID, policyVersionID string, until time.Time) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO reminder_snoozes (user_id, policy_version_id, snoozed_until, created_at) VALUES (?,?,?,?)
+		 ON CONFLICT (user_id, policy_version_id) DO UPDATE SET snoozed_until = excluded.snoozed_until, created_at = excluded.created_at`,
+		userID, policyVersionID, until.UTC().Format(time.RFC3339), now(),
+	)
+	return err
+}
+
+// UnsnoozeReminders cancels the user's snooze on the version, if any.
+func (db *DB) UnsnoozeReminders(ctx context.Context, userID, policyVersionID string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`DELETE FROM reminder_snoozes WHERE user_id=? AND policy_version_id=?`, userID, policyVersionID)
+	return err
+}
+
+// ReminderSnoozes returns when each of the user's snoozes still in force at
+// now ends, keyed by policy version.
+func (db *DB) ReminderSnoozes(ctx context.Context, userID string, now time.Time) (map[string]time.Time, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT policy_version_id, snoozed_until FROM reminder_snoozes WHERE user_id=? AND snoozed_until > ?`,
+		userID, now.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]time.Time{}
+	for rows.Next() {
+		var versionID, until string
+		if err := rows.Scan(&versionID, &until); err != nil {
+			return nil, err
+		}
+		out[versionID] = parseTime(until)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportSchedule emails a saved report definition to a list of recipients
+// every week or month. DepartmentID scopes the report like a DeptAdmin's
+// ad-hoc reports; it is nil for organization-wide schedules.
+type ReportSchedule struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Definition   ReportDefinition `json:"definition"`
+	Frequency    string           `json:"frequency"` // weekly | monthly
+	Format       string           `json:"format"`    // csv | pdf
+	Recipients   []string         `json:"recipients"`
+	DepartmentID *string          `json:"department_id"`
+	CreatedBy    *string          `json:"created_by"`
+	CreatedAt    time.Time        `json:"created_at"`
+	NextRunAt    time.Time        `json:"next_run_at"`
+	LastRunAt    *time.Time       `json:"last_run_at"`
+	LastError    string           `json:"last_error"`
+	DisabledAt   *time.Time       `json:"disabled_at"`
+}
+
+const reportScheduleSelect = `SELECT id, name, definition, frequency, format, recipients, department_id, created_by,
+	created_at, next_run_at, last_run_at, last_error, disabled_at FROM report_schedules`
+
+func scanReportSchedule(row interface{ Scan(...any) error }) (*ReportSchedule, error) {
+	s := &ReportSchedule{}
+	var def, recipients, createdAt, nextRun string
+	var deptID, createdBy, lastRun, disabledAt sql.NullString
+	if err := row.Scan(&s.ID, &s.Name, &def, &s.Frequency, &s.Format, &recipients, &deptID, &createdBy,
+		&createdAt, &nextRun, &lastRun, &s.LastError, &disabledAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(def), &s.Definition); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(recipients), &s.Recipients); err != nil {
+		return nil, err
+	}
+	s.DepartmentID = nullString(deptID)
+	s.CreatedBy = nullString(createdBy)
+	s.CreatedAt = parseTime(createdAt)
+	s.NextRunAt = parseTime(nextRun)
+	if lastRun.Valid {
+		t := parseTime(lastRun.String)
+		s.LastRunAt = &t
+	}
+	if disabledAt.Valid {
+		t := parseTime(disabledAt.String)
+		s.DisabledAt = &t
+	}
+	return s, nil
+}
+
+func (db *DB) CreateReportSchedule(ctx context.Context, s *ReportSchedule) error {
+	def, err := json.Marshal(s.Definition)
+	if err != nil {
+		return err
+	}
+	recipients, err := json.Marshal(s.Recipients)
+	if err != nil {
+		return err
+	}
+	s.ID = uuid.New().String()
+	ts := now()
+	s.CreatedAt = parseTime(ts)
+	_, err = db.conn.ExecContext(ctx,
+		`INSERT INTO report_schedules (id, name, definition, frequency, format, recipients, department_id, created_by, created_at, next_run_at)
+		 VALUES (?,?,?,?,?,?,?,?,?,?)`,
+		s.ID, s.Name, string(def), s.Frequency, s.Format, string(recipients), s.DepartmentID, s.CreatedBy, ts,
+		s.NextRunAt.UTC().Format(time.RFC3339),
+	)
+	return err
+}
+
+func (db *DB) GetReportSchedule(ctx context.Context, id string) (*ReportSchedule, error) {
+	return scanReportSchedule(db.conn.QueryRowContext(ctx, reportScheduleSelect+` WHERE id = ?`, id))
+}
+
+// ListReportSchedules returns schedules, all of them when deptID is nil or
+// only that department's otherwise.
+func (db *DB) ListReportSchedules(ctx context.Context, deptID *string) ([]*ReportSchedule, error) {
+	return db.queryReportSchedules(ctx,
+		reportScheduleSelect+` WHERE (? IS NULL OR department_id = ?) ORDER BY name`, deptID, deptID)
+}
+
+// ListDueReportSchedules returns enabled schedules whose next run is at or
+// before t.
+func (db *DB) ListDueReportSchedules(ctx context.Context, t time.Time) ([]*ReportSchedule, error) {
+	return db.queryReportSchedules(ctx,
+		reportScheduleSelect+` WHERE disabled_at IS NULL AND next_run_at <= ? ORDER BY next_run_at`, t.UTC().Format(time.RFC3339))
+}
+
+func (db *DB) queryReportSchedules(ctx context.Context, query string, args ...any) ([]*ReportSchedule, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*ReportSchedule
+	for rows.Next() {
+		s, err := scanReportSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// MarkReportScheduleRun records a delivery attempt and when to run next.
+func (db *DB) MarkReportScheduleRun(ctx context.Context, id string, ranAt, nextRun time.Time, lastError string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE report_schedules SET last_run_at=?, next_run_at=?, last_error=? WHERE id=?`,
+		ranAt.UTC().Format(time.RFC3339), nextRun.UTC().Format(time.RFC3339), lastError, id,
+	)
+	return err
+}
+
+// DisableReportSchedule stops a schedule from running, with reason as its
+// last error.
+func (db *DB) DisableReportSchedule(ctx context.Context, id, reason string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`UPDATE report_schedules SET disabled_at=?, last_error=? WHERE id=?`, now(), reason, id)
+	return err
+}
+
+func (db *DB) DeleteReportSchedule(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM report_schedules WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,298 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReportDefinition is a declarative, constrained report: which entity to
+// list, how to filter it, and optionally how to group it into counts. Only
+// the fields listed in reportEntities can be referenced, so a definition
+// can never reach arbitrary SQL.
+type ReportDefinition struct {
+	Entity    string         `json:"entity"`
+	Columns   []string       `json:"columns,omitempty"` // default: every field
+	Filters   []ReportFilter `json:"filters,omitempty"`
+	GroupBy   []string       `json:"group_by,omitempty"`
+	DateRange *ReportRange   `json:"date_range,omitempty"`
+	Limit     int            `json:"limit,omitempty"`
+}
+
+// ReportFilter compares a field with a value. Op is one of eq, neq,
+// contains, gte, lte, or in (Values).
+type ReportFilter struct {
+	Field  string   `json:"field"`
+	Op     string   `json:"op"`
+	Value  string   `json:"value,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// ReportRange restricts the entity's date field to [From, To], both
+// YYYY-MM-DD and inclusive; either may be empty.
+type ReportRange struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ReportResult is a rendered report: a header row and string cells.
+type ReportResult struct {
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// ReportError is a problem with a definition, as opposed to a query failure.
+type ReportError struct{ msg string }
+
+func (e *ReportError) Error() string { return e.msg }
+
+func reportErrorf(format string, args ...any) error {
+	return &ReportError{msg: fmt.Sprintf(format, args...)}
+}
+
+// MaxReportRows caps every report.
+const MaxReportRows = 10000
+
+type reportEntity struct {
+	from      string
+	fields    map[string]string // name → SQL expression
+	order     []string          // default column order
+	dateField string            // field the date range applies to
+	deptExpr  string            // department ID, for department scoping
+}
+
+var reportEntities = map[string]reportEntity{
+	"acknowledgements": {
+		from: `acknowledgements a
+			JOIN users u ON u.id = a.user_id
+			JOIN policy_versions v ON v.id = a.policy_version_id
+			JOIN policies p ON p.id = v.policy_id
+			LEFT JOIN departments d ON d.id = u.department_id`,
+		fields: map[string]string{
+			"user_email":      "u.email",
+			"user_name":       "u.name",
+			"role":            "u.role",
+			"department":      "COALESCE(d.name, '')",
+			"policy":          "p.title",
+			"policy_id":       "p.id",
+			"version":         "v.version_string",
+			"acknowledged_at": "a.timestamp",
+		},
+		order:     []string{"acknowledged_at", "user_email", "user_name", "role", "department", "policy", "policy_id", "version"},
+		dateField: "acknowledged_at",
+		deptExpr:  "u.department_id",
+	},
+	"users": {
+		from: `users u LEFT JOIN departments d ON d.id = u.department_id`,
+		fields: map[string]string{
+			"email":         "u.email",
+			"name":          "u.name",
+			"role":          "u.role",
+			"department":    "COALESCE(d.name, '')",
+			"status":        "CASE WHEN u.deactivated_at IS NULL THEN 'active' ELSE 'deactivated' END",
+			"created_at":    "u.created_at",
+			"last_login_at": "COALESCE(u.last_login_at, '')",
+		},
+		order:     []string{"email", "name", "role", "department", "status", "created_at", "last_login_at"},
+		dateField: "created_at",
+		deptExpr:  "u.department_id",
+	},
+	"policies": {
+		from: `policies p
+			LEFT JOIN departments d ON d.id = p.department_id
+			LEFT JOIN policy_versions v ON v.id = p.current_version_id`,
+		fields: map[string]string{
+			"title":           "p.title",
+			"status":          "p.status",
+			"department":      "COALESCE(d.name, '')",
+			"visibility_type": "p.visibility_type",
+			"current_version": "COALESCE(v.version_string, '')",
+			"effective_from":  "COALESCE(v.effective_from, '')",
+			"effective_to":    "COALESCE(v.effective_to, '')",
+			"created_at":      "p.created_at",
+			"updated_at":      "COALESCE(p.updated_at, p.created_at)",
+		},
+		order:     []string{"title", "status", "department", "visibility_type", "current_version", "effective_from", "effective_to", "created_at", "updated_at"},
+		dateField: "created_at",
+		deptExpr:  "p.department_id",
+	},
+}
+
+// ReportFields lists the fields each entity exposes, for clients building
+// definitions.
+func ReportFields() map[string][]string {
+	out := map[string][]string{}
+	for name, e := range reportEntities {
+		out[name] = append([]string(nil), e.order...)
+	}
+	return out
+}
+
+// RunReport validates def and executes it. When deptID is set, only rows in
+// that department are included, whatever the filters say.
+func (db *DB) RunReport(ctx context.Context, def ReportDefinition, deptID *string) (*ReportResult, error) {
+	res := &ReportResult{Rows: [][]string{}}
+	err := db.StreamReport(ctx, def, deptID,
+		func(columns []string) error { res.Columns = columns; return nil },
+		func(row []string) error { res.Rows = append(res.Rows, row); return nil })
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// StreamReport is RunReport without holding the result: it calls start
+// with the columns once the query is running, then row for each row as it
+// is read. An error from either stops the report and is returned.
+func (db *DB) StreamReport(ctx context.Context, def ReportDefinition, deptID *string, start func(columns []string) error, row func([]string) error) error {
+	query, args, columns, err := buildReport(def, deptID)
+	if err != nil {
+		return err
+	}
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if err := start(columns); err != nil {
+		return err
+	}
+
+	cells := make([]sql.NullString, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range cells {
+		ptrs[i] = &cells[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		out := make([]string, len(columns))
+		for i, c := range cells {
+			out[i] = c.String
+		}
+		if err := row(out); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ValidateReport checks def without running it.
+func ValidateReport(def ReportDefinition) error {
+	_, _, _, err := buildReport(def, nil)
+	return err
+}
+
+func buildReport(def ReportDefinition, deptID *string) (string, []any, []string, error) {
+	ent, ok := reportEntities[def.Entity]
+	if !ok {
+		names := make([]string, 0, len(reportEntities))
+		for n := range reportEntities {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", nil, nil, reportErrorf("entity must be one of %s", strings.Join(names, ", "))
+	}
+	field := func(name string) (string, error) {
+		if expr, ok := ent.fields[name]; ok {
+			return expr, nil
+		}
+		return "", reportErrorf("unknown field %q for %s", name, def.Entity)
+	}
+
+	var where []string
+	var args []any
+	for _, f := range def.Filters {
+		expr, err := field(f.Field)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		switch f.Op {
+		case "eq", "":
+			where, args = append(where, expr+" = ?"), append(args, f.Value)
+		case "neq":
+			where, args = append(where, expr+" <> ?"), append(args, f.Value)
+		case "contains":
+			where, args = append(where, "instr(lower("+expr+"), lower(?)) > 0"), append(args, f.Value)
+		case "gte":
+			where, args = append(where, expr+" >= ?"), append(args, f.Value)
+		case "lte":
+			where, args = append(where, expr+" <= ?"), append(args, f.Value)
+		case "in":
+			if len(f.Values) == 0 {
+				return "", nil, nil, reportErrorf("filter on %s: in needs values", f.Field)
+			}
+			where = append(where, expr+" IN (?"+strings.Repeat(",?", len(f.Values)-1)+")")
+			for _, v := range f.Values {
+				args = append(args, v)
+			}
+		default:
+			return "", nil, nil, reportErrorf("filter on %s: op must be eq, neq, contains, gte, lte, or in", f.Field)
+		}
+	}
+	if r := def.DateRange; r != nil {
+		expr := ent.fields[ent.dateField]
+		if r.From != "" {
+			from, err := time.Parse("2006-01-02", r.From)
+			if err != nil {
+				return "", nil, nil, reportErrorf("date_range.from must be YYYY-MM-DD")
+			}
+			where, args = append(where, expr+" >= ?"), append(args, from.Format(time.RFC3339))
+		}
+		if r.To != "" {
+			to, err := time.Parse("2006-01-02", r.To)
+			if err != nil {
+				return "", nil, nil, reportErrorf("date_range.to must be YYYY-MM-DD")
+			}
+			where, args = append(where, expr+" < ?"), append(args, to.AddDate(0, 0, 1).Format(time.RFC3339))
+		}
+	}
+	if deptID != nil {
+		where, args = append(where, ent.deptExpr+" = ?"), append(args, *deptID)
+	}
+
+	limit := def.Limit
+	if limit <= 0 || limit > MaxReportRows {
+		limit = MaxReportRows
+	}
+
+	var selects, columns []string
+	var tail string
+	if len(def.GroupBy) > 0 {
+		var groups []string
+		for _, g := range def.GroupBy {
+			expr, err := field(g)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			selects, columns, groups = append(selects, expr), append(columns, g), append(groups, expr)
+		}
+		selects, columns = append(selects, "CAST(COUNT(*) AS TEXT)"), append(columns, "count")
+		tail = " GROUP BY " + strings.Join(groups, ", ") + " ORDER BY COUNT(*) DESC"
+	} else {
+		columns = def.Columns
+		if len(columns) == 0 {
+			columns = ent.order
+		}
+		for _, col := range columns {
+			expr, err := field(col)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			selects = append(selects, expr)
+		}
+		tail = " ORDER BY " + ent.fields[ent.dateField] + " DESC"
+	}
+
+	query := "SELECT " + strings.Join(selects, ", ") + " FROM " + ent.from
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += tail + " LIMIT ?"
+	args = append(args, limit)
+	return query, args, columns, nil
+}
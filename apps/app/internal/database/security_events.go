@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Security event categories.
+const (
+	SecurityAuthentication = "authentication" // sign-in links, codes, and sign-ins
+	SecurityAccessDenied   = "access_denied"  // refused requests
+	SecurityChange         = "change"         // state-changing requests and domain events
+)
+
+// SecurityEvent is one entry of the security event stream: an audit log
+// entry or a sign-in event. Cursor is the position just after it.
+type SecurityEvent struct {
+	Cursor         string    `json:"cursor"`
+	Category       string    `json:"category"`
+	Type           string    `json:"type"` // the audit action, or auth.<login event>
+	ActorID        *string   `json:"actor_id"`
+	ActorEmail     *string   `json:"actor_email"`
+	ImpersonatorID *string   `json:"impersonator_id,omitempty"`
+	TargetType     string    `json:"target_type,omitempty"`
+	TargetID       string    `json:"target_id,omitempty"`
+	Details        string    `json:"details,omitempty"`
+	IPAddress      string    `json:"ip_address"`
+	UserAgent      string    `json:"user_agent,omitempty"`
+	At             time.Time `json:"at"`
+}
+
+// SecurityCursor is a position in the security event stream: the last
+// audit log and login event rows already read, by row ID. It is stored by
+// the client, so it stays valid across restarts.
+type SecurityCursor struct {
+	Audit, Login int64
+}
+
+func (c SecurityCursor) String() string {
+	return fmt.Sprintf("%d.%d", c.Audit, c.Login)
+}
+
+// ParseSecurityCursor reads a cursor written by SecurityCursor.String.
+func ParseSecurityCursor(s string) (SecurityCursor, error) {
+	a, l, ok := strings.Cut(s, ".")
+	audit, err1 := strconv.ParseInt(a, 10, 64)
+	login, err2 := strconv.ParseInt(l, 10, 64)
+	if !ok || err1 != nil || err2 != nil || audit < 0 || login < 0 {
+		return SecurityCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	return SecurityCursor{Audit: audit, Login: login}, nil
+}
+
+// ListSecurityEvents returns up to limit events after the cursor in time
+// order, and the cursor to pass next time, which is after unchanged when
+// there are none.
+func (db *DB) ListSecurityEvents(ctx context.Context, after SecurityCursor, limit int) ([]*SecurityEvent, SecurityCursor, error) {
+	type row struct {
+		rowid int64
+		event *SecurityEvent
+	}
+	audit, err := db.conn.QueryContext(ctx,
+		`SELECT a.rowid, a.action, a.actor_id, u.email, a.impersonator_id, a.target_type, a.target_id,
+		        a.details, a.ip_address, a.created_at
+		 FROM audit_log a
+		 LEFT JOIN users u ON u.id = a.actor_id
+		 WHERE a.rowid > ?
+		 ORDER BY a.rowid
+		 LIMIT ?`, after.Audit, limit)
+	if err != nil {
+		return nil, after, err
+	}
+	var audits []row
+	for audit.Next() {
+		e := &SecurityEvent{Category: SecurityChange}
+		var rowid int64
+		var actorID, email, impID sql.NullString
+		var createdAt string
+		if err := audit.Scan(&rowid, &e.Type, &actorID, &email, &impID, &e.TargetType, &e.TargetID,
+			&e.Details, &e.IPAddress, &createdAt); err != nil {
+			audit.Close()
+			return nil, after, err
+		}
+		if e.Type == ActionAccessDenied {
+			e.Category = SecurityAccessDenied
+		}
+		e.ActorID, e.ActorEmail, e.ImpersonatorID = nullString(actorID), nullString(email), nullString(impID)
+		e.At = parseTime(createdAt)
+		audits = append(audits, row{rowid, e})
+	}
+	audit.Close()
+	if err := audit.Err(); err != nil {
+		return nil, after, err
+	}
+
+	logins, err := db.conn.QueryContext(ctx,
+		`SELECT l.rowid, l.event, l.user_id, u.email, l.ip_address, l.user_agent, l.created_at
+		 FROM login_events l
+		 LEFT JOIN users u ON u.id = l.user_id
+		 WHERE l.rowid > ?
+		 ORDER BY l.rowid
+		 LIMIT ?`, after.Login, limit)
+	if err != nil {
+		return nil, after, err
+	}
+	var signIns []row
+	for logins.Next() {
+		e := &SecurityEvent{Category: SecurityAuthentication}
+		var rowid int64
+		var userID string
+		var email sql.NullString
+		var createdAt string
+		if err := logins.Scan(&rowid, &e.Type, &userID, &email, &e.IPAddress, &e.UserAgent, &createdAt); err != nil {
+			logins.Close()
+			return nil, after, err
+		}
+		e.Type = "auth." + e.Type
+		e.ActorID, e.ActorEmail = &userID, nullString(email)
+		e.At = parseTime(createdAt)
+		signIns = append(signIns, row{rowid, e})
+	}
+	logins.Close()
+	if err := logins.Err(); err != nil {
+		return nil, after, err
+	}
+
+	// Merge by time, to the second since audit times have no fraction.
+	// Each table is read in insertion order, so taking a prefix of each
+	// keeps the cursor a pair of row IDs.
+	var out []*SecurityEvent
+	next := after
+	for len(out) < limit && (len(audits) > 0 || len(signIns) > 0) {
+		if len(signIns) == 0 || (len(audits) > 0 && !signIns[0].event.At.Truncate(time.Second).Before(audits[0].event.At)) {
+			next.Audit = audits[0].rowid
+			out = append(out, audits[0].event)
+			audits = audits[1:]
+		} else {
+			next.Login = signIns[0].rowid
+			out = append(out, signIns[0].event)
+			signIns = signIns[1:]
+		}
+		out[len(out)-1].Cursor = next.String()
+	}
+	return out, next, nil
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+)
+
+// Organization-wide setting keys.
+const (
+	// SettingVersionRetention is the maximum number of versions kept per
+	// policy; 0 keeps everything.
+	SettingVersionRetention = "version_retention"
+	// SettingPublicPortal ("true"/"false") serves policies marked public on
+	// the unauthenticated /public/policies pages.
+	SettingPublicPortal = "public_portal"
+	// SettingLogoContentType is the MIME type of the uploaded logo; empty
+	// when none has been uploaded.
+	SettingLogoContentType = "logo_content_type"
+	// SettingMagicLinkTTL is how many minutes a sign-in link stays valid.
+	SettingMagicLinkTTL = "magic_link_ttl_minutes"
+	// SettingSessionTTL is the maximum session length in hours, counted
+	// from sign-in no matter how active the user is.
+	SettingSessionTTL = "session_ttl_hours"
+	// SettingIdleTimeout signs users out after this many minutes without a
+	// request; 0 disables it.
+	SettingIdleTimeout = "idle_timeout_minutes"
+	// SettingMaintenanceMode ("true"/"false") refuses API writes with 503.
+	SettingMaintenanceMode = "maintenance_mode"
+	// SettingMaintenanceMessage is shown in the banner during maintenance.
+	SettingMaintenanceMessage = "maintenance_message"
+	// SettingNewHireGraceDays gives users this many days from account
+	// creation to acknowledge policies whose deadline falls sooner; 0
+	// disables it.
+	SettingNewHireGraceDays = "new_hire_grace_days"
+	// SettingPublishNotifications ("true"/"false", default true) emails
+	// the users who can see a policy when a version of it is published.
+	SettingPublishNotifications = "publish_notifications"
+	// SettingAckReceipts ("true"/"false", default false) emails users a
+	// receipt with a certificate link each time they acknowledge a policy.
+	SettingAckReceipts = "ack_receipts"
+	// SettingDemoData is when ResetDemoData last filled the database; set,
+	// it marks the data as generated, so DEMO_MODE may wipe it.
+	SettingDemoData = "demo_data"
+)
+
+// GetSetting returns the stored value for key, or fallback if unset.
+func (db *DB) GetSetting(ctx context.Context, key, fallback string) (string, error) {
+	var v string
+	err := db.conn.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = ?`, key).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fallback, nil
+	}
+	return v, err
+}
+
+// GetIntSetting is GetSetting for integer values. Unparseable values are
+// treated as unset.
+func (db *DB) GetIntSetting(ctx context.Context, key string, fallback int) (int, error) {
+	v, err := db.GetSetting(ctx, key, "")
+	if err != nil || v == "" {
+		return fallback, err
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback, nil
+	}
+	return n, nil
+}
+
+func (db *DB) SetSetting(ctx context.Context, key, value string, updatedBy *string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO settings (key, value, updated_by, updated_at) VALUES (?,?,?,?)
+		 ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_by=excluded.updated_by, updated_at=excluded.updated_at`,
+		key, value, updatedBy, now(),
+	)
+	return err
+}
+
+// GetBoolSetting is GetSetting for "true"/"false" values.
+func (db *DB) GetBoolSetting(ctx context.Context, key string, fallback bool) (bool, error) {
+	v, err := db.GetSetting(ctx, key, "")
+	if err != nil || v == "" {
+		return fallback, err
+	}
+	return v == "true", nil
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PolicyShare is a revocable, time-limited grant letting someone without an
+// account read one policy version. The link itself is signed by the handler;
+// this row carries the expiry and revocation state it is checked against.
+type PolicyShare struct {
+	ID              string     `json:"id"`
+	PolicyID        string     `json:"policy_id"`
+	PolicyVersionID string     `json:"policy_version_id"`
+	VersionString   string     `json:"version_string"`
+	CreatedBy       *string    `json:"created_by"`
+	CreatedByName   *string    `json:"created_by_name"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ViewCount       int        `json:"view_count"`
+	LastViewedAt    *time.Time `json:"last_viewed_at"`
+}
+
+// Active reports whether the share can still be used at t.
+func (s *PolicyShare) Active(t time.Time) bool {
+	return s.RevokedAt == nil && t.Before(s.ExpiresAt)
+}
+
+const shareSelect = `
+SELECT s.id, s.policy_id, s.policy_version_id, v.version_string, s.created_by, u.name,
+       s.expires_at, s.revoked_at, s.created_at,
+       (SELECT COUNT(*) FROM policy_share_views sv WHERE sv.share_id = s.id),
+       (SELECT MAX(sv.viewed_at) FROM policy_share_views sv WHERE sv.share_id = s.id)
+FROM policy_shares s
+JOIN policy_versions v ON v.id = s.policy_version_id
+LEFT JOIN users u ON u.id = s.created_by`
+
+func (db *DB) scanShare(row interface{ Scan(...any) error }) (*PolicyShare, error) {
+	s := &PolicyShare{}
+	var createdBy, createdByName, revokedAt, lastViewed sql.NullString
+	var expiresAt, createdAt string
+	if err := row.Scan(&s.ID, &s.PolicyID, &s.PolicyVersionID, &s.VersionString, &createdBy, &createdByName,
+		&expiresAt, &revokedAt, &createdAt, &s.ViewCount, &lastViewed); err != nil {
+		return nil, err
+	}
+	s.CreatedBy = nullString(createdBy)
+	s.CreatedByName = nullString(createdByName)
+	s.ExpiresAt = parseTime(expiresAt)
+	s.CreatedAt = parseTime(createdAt)
+	if revokedAt.Valid {
+		t := parseTime(revokedAt.String)
+		s.RevokedAt = &t
+	}
+	if lastViewed.Valid {
+		t := parseTime(lastViewed.String)
+		s.LastViewedAt = &t
+	}
+	return s, nil
+}
+
+func (db *DB) CreatePolicyShare(ctx context.Context, policyID, versionID string, expiresAt time.Time, createdBy *string) (*PolicyShare, error) {
+	id := uuid.New().String()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_shares (id, policy_id, policy_version_id, created_by, expires_at, created_at) VALUES (?,?,?,?,?,?)`,
+		id, policyID, versionID, createdBy, expiresAt.UTC().Format(time.RFC3339), now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetPolicyShare(ctx, id)
+}
+
+func (db *DB) GetPolicyShare(ctx context.Context, id string) (*PolicyShare, error) {
+	return db.scanShare(db.conn.QueryRowContext(ctx, shareSelect+` WHERE s.id = ?`, id))
+}
+
+// ListPolicyShares returns every share of a policy, newest first.
+func (db *DB) ListPolicyShares(ctx context.Context, policyID string) ([]*PolicyShare, error) {
+	rows, err := db.conn.QueryContext(ctx, shareSelect+` WHERE s.policy_id = ? ORDER BY s.created_at DESC`, policyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []*PolicyShare
+	for rows.Next() {
+		s, err := db.scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, s)
+	}
+	return shares, rows.Err()
+}
+
+// RevokePolicyShare disables a share immediately. Revoking twice is a no-op.
+func (db *DB) RevokePolicyShare(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE policy_shares SET revoked_at=? WHERE id=? AND revoked_at IS NULL`, now(), id)
+	return err
+}
+
+func (db *DB) RecordShareView(ctx context.Context, shareID, ipAddress, userAgent string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_share_views (id, share_id, ip_address, user_agent, viewed_at) VALUES (?,?,?,?,?)`,
+		uuid.New().String(), shareID, ipAddress, userAgent, now(),
+	)
+	return err
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PolicyTraining links a policy to the LMS course staff must complete
+// alongside acknowledging it.
+type PolicyTraining struct {
+	PolicyID   string    `json:"policy_id"`
+	CourseID   string    `json:"course_id"`
+	CourseName string    `json:"course_name"`
+	CourseURL  string    `json:"course_url"`
+	UpdatedBy  *string   `json:"updated_by"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TrainingGap is a user who acknowledged a policy's current version but has
+// not completed its course.
+type TrainingGap struct {
+	UserID         string    `json:"user_id"`
+	UserName       string    `json:"user_name"`
+	UserEmail      string    `json:"user_email"`
+	DepartmentName *string   `json:"department_name"`
+	PolicyID       string    `json:"policy_id"`
+	PolicyTitle    string    `json:"policy_title"`
+	CourseID       string    `json:"course_id"`
+	CourseName     string    `json:"course_name"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// GetPolicyTraining returns the course linked to a policy, or sql.ErrNoRows.
+func (db *DB) GetPolicyTraining(ctx context.Context, policyID string) (*PolicyTraining, error) {
+	t := &PolicyTraining{}
+	var updatedBy sql.NullString
+	var updatedAt string
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT policy_id, course_id, course_name, course_url, updated_by, updated_at
+		 FROM policy_training WHERE policy_id = ?`, policyID,
+	).Scan(&t.PolicyID, &t.CourseID, &t.CourseName, &t.CourseURL, &updatedBy, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.UpdatedBy = nullString(updatedBy)
+	t.UpdatedAt = parseTime(updatedAt)
+	return t, nil
+}
+
+// SetPolicyTraining links a policy to a course, replacing any earlier link.
+func (db *DB) SetPolicyTraining(ctx context.Context, t *PolicyTraining) error {
+	ts := now()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO policy_training (policy_id, course_id, course_name, course_url, updated_by, updated_at)
+		 VALUES (?,?,?,?,?,?)
+		 ON CONFLICT (policy_id) DO UPDATE SET course_id = excluded.course_id, course_name = excluded.course_name,
+		   course_url = excluded.course_url, updated_by = excluded.updated_by, updated_at = excluded.updated_at`,
+		t.PolicyID, t.CourseID, t.CourseName, t.CourseURL, t.UpdatedBy, ts)
+	if err == nil {
+		t.UpdatedAt = parseTime(ts)
+	}
+	return err
+}
+
+// DeletePolicyTraining unlinks a policy's course, reporting whether it had
+// one. Completions already received are kept.
+func (db *DB) DeletePolicyTraining(ctx context.Context, policyID string) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, `DELETE FROM policy_training WHERE policy_id = ?`, policyID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RecordTrainingCompletion stores that a user completed a course. Repeat
+// deliveries keep the latest completion time.
+func (db *DB) RecordTrainingCompletion(ctx context.Context, courseID, userID string, completedAt time.Time) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO training_completions (course_id, user_id, completed_at, received_at) VALUES (?,?,?,?)
+		 ON CONFLICT (course_id, user_id) DO UPDATE SET completed_at = MAX(completed_at, excluded.completed_at),
+		   received_at = excluded.received_at`,
+		courseID, userID, completedAt.UTC().Format(time.RFC3339), now())
+	return err
+}
+
+// TrainingCompletedAt returns when a user completed a course, or nil.
+func (db *DB) TrainingCompletedAt(ctx context.Context, courseID, userID string) (*time.Time, error) {
+	var completedAt string
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT completed_at FROM training_completions WHERE course_id = ? AND user_id = ?`, courseID, userID,
+	).Scan(&completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t := parseTime(completedAt)
+	return &t, nil
+}
+
+// CountCoursePolicies returns how many policies are linked to a course.
+func (db *DB) CountCoursePolicies(ctx context.Context, courseID string) (int, error) {
+	var n int
+	err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM policy_training WHERE course_id = ?`, courseID).Scan(&n)
+	return n, err
+}
+
+// ListTrainingGaps returns active users who acknowledged the current version
+// of a policy with a linked course but have no completion for the course,
+// only those in deptID when it is set. Ordered by policy then user.
+func (db *DB) ListTrainingGaps(ctx context.Context, deptID *string) ([]*TrainingGap, error) {
+	query := `SELECT u.id, u.name, u.email, d.name, p.id, p.title, t.course_id, t.course_name, a.timestamp
+		FROM policy_training t
+		JOIN policies p ON p.id = t.policy_id
+		JOIN acknowledgements a ON a.policy_version_id = p.current_version_id
+		JOIN users u ON u.id = a.user_id
+		LEFT JOIN departments d ON d.id = u.department_id
+		WHERE u.deactivated_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM training_completions c WHERE c.course_id = t.course_id AND c.user_id = u.id)`
+	var args []any
+	if deptID != nil {
+		query += ` AND u.department_id = ?`
+		args = append(args, *deptID)
+	}
+	rows, err := db.conn.QueryContext(ctx, query+` ORDER BY p.title, u.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*TrainingGap
+	for rows.Next() {
+		g := &TrainingGap{}
+		var deptName sql.NullString
+		var ackedAt string
+		if err := rows.Scan(&g.UserID, &g.UserName, &g.UserEmail, &deptName, &g.PolicyID, &g.PolicyTitle,
+			&g.CourseID, &g.CourseName, &ackedAt); err != nil {
+			return nil, err
+		}
+		g.DepartmentName = nullString(deptName)
+		g.AcknowledgedAt = parseTime(ackedAt)
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
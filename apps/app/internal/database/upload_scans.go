@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload kinds recorded in upload_scans.
+const (
+	UploadAttachment = "attachment"
+	UploadDOCXImport = "docx_import"
+	UploadPDFVersion = "pdf_version"
+)
+
+// UploadScan is the virus scan of one uploaded file. A flagged file is not
+// stored with the policy; QuarantineKey is where it was kept instead, until
+// an admin deletes it.
+type UploadScan struct {
+	ID             string    `json:"id"`
+	Kind           string    `json:"kind"`
+	PolicyID       *string   `json:"policy_id"`
+	Filename       string    `json:"filename"`
+	SizeBytes      int64     `json:"size_bytes"`
+	SHA256         string    `json:"sha256"`
+	Scanner        string    `json:"scanner"`
+	Infected       bool      `json:"infected"`
+	Signature      string    `json:"signature"`
+	QuarantineKey  *string   `json:"-"`
+	Quarantined    bool      `json:"quarantined"`
+	UploadedBy     *string   `json:"uploaded_by"`
+	UploadedByName *string   `json:"uploaded_by_name"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+const uploadScanSelect = `
+SELECT s.id, s.kind, s.policy_id, s.filename, s.size_bytes, s.sha256, s.scanner, s.infected, s.signature,
+       s.quarantine_key, s.uploaded_by, u.name, s.created_at
+FROM upload_scans s
+LEFT JOIN users u ON u.id = s.uploaded_by`
+
+func scanUploadScan(row interface{ Scan(...any) error }) (*UploadScan, error) {
+	s := &UploadScan{}
+	var policyID, key, uploadedBy, uploadedByName sql.NullString
+	var createdAt string
+	if err := row.Scan(&s.ID, &s.Kind, &policyID, &s.Filename, &s.SizeBytes, &s.SHA256, &s.Scanner, &s.Infected,
+		&s.Signature, &key, &uploadedBy, &uploadedByName, &createdAt); err != nil {
+		return nil, err
+	}
+	s.PolicyID = nullString(policyID)
+	s.QuarantineKey = nullString(key)
+	s.Quarantined = s.QuarantineKey != nil
+	s.UploadedBy = nullString(uploadedBy)
+	s.UploadedByName = nullString(uploadedByName)
+	s.CreatedAt = parseTime(createdAt)
+	return s, nil
+}
+
+// RecordUploadScan stores a scan result, filling in its ID and time. The ID
+// may be set in advance to name the quarantined file.
+func (db *DB) RecordUploadScan(ctx context.Context, s *UploadScan) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	ts := now()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO upload_scans (id, kind, policy_id, filename, size_bytes, sha256, scanner, infected, signature,
+		                           quarantine_key, uploaded_by, created_at)
+		 VALUES (?,?,?,?,?,?,?,?,?,?,?,?)`,
+		s.ID, s.Kind, s.PolicyID, s.Filename, s.SizeBytes, s.SHA256, s.Scanner, s.Infected, s.Signature,
+		s.QuarantineKey, s.UploadedBy, ts,
+	)
+	if err != nil {
+		return err
+	}
+	s.Quarantined = s.QuarantineKey != nil
+	s.CreatedAt = parseTime(ts)
+	return nil
+}
+
+// ListUploadScans returns recent scans, newest first; infectedOnly limits
+// them to flagged files.
+func (db *DB) ListUploadScans(ctx context.Context, infectedOnly bool, limit int) ([]*UploadScan, error) {
+	rows, err := db.conn.QueryContext(ctx, uploadScanSelect+`
+		WHERE (? = 0 OR s.infected = 1)
+		ORDER BY s.created_at DESC, s.rowid DESC
+		LIMIT ?`, infectedOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*UploadScan
+	for rows.Next() {
+		s, err := scanUploadScan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (db *DB) GetUploadScan(ctx context.Context, id string) (*UploadScan, error) {
+	return scanUploadScan(db.conn.QueryRowContext(ctx, uploadScanSelect+` WHERE s.id = ?`, id))
+}
+
+// ClearQuarantine records that a scan's quarantined file has been deleted.
+func (db *DB) ClearQuarantine(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE upload_scans SET quarantine_key = NULL WHERE id = ?`, id)
+	return err
+}
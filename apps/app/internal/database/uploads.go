@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload is a file being sent in chunks. Received counts the bytes stored
+// so far; the upload is complete when it reaches SizeBytes.
+type Upload struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	Received    int64     `json:"received"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Complete reports whether every byte has been received.
+func (u *Upload) Complete() bool { return u.Received == u.SizeBytes }
+
+// CreateUpload starts a chunked upload that expires at expiresAt.
+func (db *DB) CreateUpload(ctx context.Context, u *Upload, expiresAt time.Time) error {
+	u.ID = uuid.New().String()
+	ts := now()
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT INTO uploads (id, user_id, filename, content_type, size_bytes, received, created_at, expires_at)
+		 VALUES (?,?,?,?,?,0,?,?)`,
+		u.ID, u.UserID, u.Filename, u.ContentType, u.SizeBytes, ts, expiresAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+	u.CreatedAt = parseTime(ts)
+	u.ExpiresAt = expiresAt.UTC().Truncate(time.Second)
+	return nil
+}
+
+// GetUpload returns an upload that has not expired.
+func (db *DB) GetUpload(ctx context.Context, id string) (*Upload, error) {
+	u := &Upload{}
+	var createdAt, expiresAt string
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT id, user_id, filename, content_type, size_bytes, received, created_at, expires_at
+		 FROM uploads WHERE id = ? AND expires_at > ?`, id, now(),
+	).Scan(&u.ID, &u.UserID, &u.Filename, &u.ContentType, &u.SizeBytes, &u.Received, &createdAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	u.CreatedAt = parseTime(createdAt)
+	u.ExpiresAt = parseTime(expiresAt)
+	return u, nil
+}
+
+// AdvanceUpload moves an upload's received count from `from` to `to`. It
+// returns sql.ErrNoRows if another request moved it first.
+func (db *DB) AdvanceUpload(ctx context.Context, id string, from, to int64) error {
+	res, err := db.conn.ExecContext(ctx, `UPDATE uploads SET received = ? WHERE id = ? AND received = ?`, to, id, from)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (db *DB) DeleteUpload(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM uploads WHERE id = ?`, id)
+	return err
+}
+
+// DeleteExpiredUploads removes expired uploads and returns their IDs, so
+// their files can be deleted.
+func (db *DB) DeleteExpiredUploads(ctx context.Context) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `DELETE FROM uploads WHERE expires_at <= ? RETURNING id`, now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
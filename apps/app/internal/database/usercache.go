@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// userCacheTTL bounds how stale a cached user can be. Writes through this
+// DB drop the entry at once; the TTL covers writes from other processes,
+// such as policyflowctl against the same file.
+const userCacheTTL = 30 * time.Second
+
+// userCache holds recently fetched users for request authentication, which
+// would otherwise read the users table on every API call.
+type userCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedUser
+	gen     uint64 // bumped by reset, so a fetch racing a write is not stored
+}
+
+type cachedUser struct {
+	user    User
+	expires time.Time
+}
+
+func newUserCache() *userCache {
+	return &userCache{entries: map[string]cachedUser{}}
+}
+
+func (uc *userCache) get(id string) (*User, uint64, bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	e, ok := uc.entries[id]
+	if !ok || time.Now().After(e.expires) {
+		return nil, uc.gen, false
+	}
+	u := e.user
+	return &u, uc.gen, true
+}
+
+func (uc *userCache) put(u *User, gen uint64) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if gen != uc.gen {
+		return
+	}
+	uc.entries[u.ID] = cachedUser{user: *u, expires: time.Now().Add(userCacheTTL)}
+}
+
+// reset empties the cache. Writes that change users call it after they
+// commit; those are rare enough that dropping every entry costs little.
+func (uc *userCache) reset() {
+	if uc == nil {
+		return
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.gen++
+	clear(uc.entries)
+}
+
+// GetUserByIDCached is GetUserByID served from a short-lived cache, for
+// checks that run on every request. Callers get their own copy.
+func (db *DB) GetUserByIDCached(ctx context.Context, id string) (*User, error) {
+	if db.users == nil {
+		return db.GetUserByID(ctx, id)
+	}
+	u, gen, ok := db.users.get(id)
+	if ok {
+		return u, nil
+	}
+	u, err := db.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	db.users.put(u, gen)
+	return u, nil
+}
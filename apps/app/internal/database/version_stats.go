@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// VersionAckStats is a version's acknowledgement history. EligibleUserCount
+// and CompliancePct are live for the current version and frozen when a
+// version is superseded; they are nil for versions superseded before
+// snapshots were kept.
+type VersionAckStats struct {
+	VersionID         string           `json:"version_id"`
+	VersionString     string           `json:"version_string"`
+	Current           bool             `json:"current"`
+	PublishedAt       *time.Time       `json:"published_at"`
+	SupersededAt      *time.Time       `json:"superseded_at"`
+	AckCount          int              `json:"ack_count"`
+	EligibleUserCount *int             `json:"eligible_user_count"`
+	CompliancePct     *float64         `json:"compliance_pct"`
+	ByMonth           []*MonthAckCount `json:"by_month"`
+}
+
+// MonthAckCount counts the acknowledgements of a version made in a month.
+type MonthAckCount struct {
+	Month            string `json:"month"` // YYYY-MM
+	Acknowledgements int    `json:"acknowledgements"`
+}
+
+// snapshotSupersededVersion records the coverage of a policy's published
+// current version as it is replaced by nextVersionID.
+func (db *DB) snapshotSupersededVersion(ctx context.Context, policyID, nextVersionID, ts string) error {
+	_, err := db.conn.ExecContext(ctx,
+		`INSERT OR REPLACE INTO version_ack_snapshots (version_id, superseded_at, eligible_user_count, ack_count)
+		 SELECT p.current_version_id, ?, COUNT(u.id), COUNT(ak.id)
+		 FROM policies p
+		 JOIN policy_versions v ON v.id = p.current_version_id AND v.published_at IS NOT NULL
+		 LEFT JOIN users u ON u.deactivated_at IS NULL AND `+requiredForUser+`
+		 LEFT JOIN acknowledgements ak ON ak.user_id = u.id AND ak.policy_version_id = p.current_version_id
+		 WHERE p.id = ? AND p.current_version_id <> ?
+		 GROUP BY p.id`,
+		ts, policyID, nextVersionID)
+	return err
+}
+
+// ListVersionAckStats returns the acknowledgement history of every version
+// of a policy, newest first.
+func (db *DB) ListVersionAckStats(ctx context.Context, policyID string) ([]*VersionAckStats, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT v.id, v.version_string, v.id = p.current_version_id, v.published_at, s.superseded_at,
+		        (SELECT COUNT(*) FROM acknowledgements a WHERE a.policy_version_id = v.id),
+		        s.eligible_user_count, s.ack_count
+		 FROM policy_versions v
+		 JOIN policies p ON p.id = v.policy_id
+		 LEFT JOIN version_ack_snapshots s ON s.version_id = v.id
+		 WHERE v.policy_id = ? ORDER BY v.created_at DESC, v.rowid DESC`, policyID)
+	if err != nil {
+		return nil, err
+	}
+	var out []*VersionAckStats
+	byID := map[string]*VersionAckStats{}
+	for rows.Next() {
+		st := &VersionAckStats{ByMonth: []*MonthAckCount{}}
+		var current sql.NullBool
+		var publishedAt, supersededAt sql.NullString
+		var eligible, ackedThen sql.NullInt64
+		if err := rows.Scan(&st.VersionID, &st.VersionString, &current, &publishedAt, &supersededAt,
+			&st.AckCount, &eligible, &ackedThen); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		st.Current = current.Bool
+		if publishedAt.Valid {
+			t := parseTime(publishedAt.String)
+			st.PublishedAt = &t
+		}
+		if supersededAt.Valid && !st.Current {
+			t := parseTime(supersededAt.String)
+			st.SupersededAt = &t
+			e := int(eligible.Int64)
+			pct := newAckCoverage(e, int(ackedThen.Int64)).CompliancePct
+			st.EligibleUserCount, st.CompliancePct = &e, &pct
+		}
+		out = append(out, st)
+		byID[st.VersionID] = st
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(out) == 0 {
+		return out, err
+	}
+
+	for _, st := range out {
+		if !st.Current {
+			continue
+		}
+		required, acked, err := db.PolicyAckCoverage(ctx, policyID)
+		if err != nil {
+			return nil, err
+		}
+		pct := newAckCoverage(required, acked).CompliancePct
+		st.EligibleUserCount, st.CompliancePct = &required, &pct
+	}
+
+	rows, err = db.conn.QueryContext(ctx,
+		`SELECT a.policy_version_id, substr(a.timestamp, 1, 7) AS month, COUNT(*)
+		 FROM acknowledgements a JOIN policy_versions v ON v.id = a.policy_version_id
+		 WHERE v.policy_id = ? GROUP BY a.policy_version_id, month ORDER BY month`, policyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var versionID string
+		m := &MonthAckCount{}
+		if err := rows.Scan(&versionID, &m.Month, &m.Acknowledgements); err != nil {
+			return nil, err
+		}
+		byID[versionID].ByMonth = append(byID[versionID].ByMonth, m)
+	}
+	return out, rows.Err()
+}
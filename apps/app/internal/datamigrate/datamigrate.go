@@ -0,0 +1,485 @@
+// Package datamigrate copies a PolicyFlow SQLite database into another
+// database, preserving every ID and verifying the copy table by table.
+package datamigrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the target database.
+type Dialect struct {
+	Name string
+	// Placeholder returns the bind parameter for the nth (1-based) argument.
+	Placeholder func(n int) string
+	// ColumnType maps a declared SQLite column type to the target's.
+	ColumnType func(sqliteType string) string
+	// ForeignKeys adds foreign keys with ALTER TABLE after the data is
+	// loaded, which validates every reference in the target.
+	ForeignKeys bool
+	// MaxParams caps the bind parameters in one INSERT.
+	MaxParams int
+}
+
+// Postgres is the dialect for PostgreSQL targets.
+var Postgres = Dialect{
+	Name:        "postgres",
+	Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+	ColumnType: func(t string) string {
+		switch strings.ToUpper(t) {
+		case "INTEGER", "INT", "BIGINT":
+			return "BIGINT"
+		case "REAL", "DOUBLE", "FLOAT":
+			return "DOUBLE PRECISION"
+		case "BLOB":
+			return "BYTEA"
+		default:
+			return "TEXT"
+		}
+	},
+	ForeignKeys: true,
+	MaxParams:   65535,
+}
+
+// TableReport is the verified state of one copied table.
+type TableReport struct {
+	Name     string `json:"name"`
+	Rows     int64  `json:"rows"`
+	Checksum string `json:"checksum"`
+}
+
+type column struct {
+	name, typ string
+	notNull   bool
+	dflt      sql.NullString
+	pk        int
+}
+
+type foreignKey struct {
+	table            string
+	from, to         []string
+	onDelete, update string
+}
+
+type table struct {
+	name    string
+	columns []column
+	uniques [][]string
+	fks     []foreignKey
+}
+
+// Copy copies every table of src, an SQLite database at the current schema,
+// into dst, which must not contain any of them yet. The source is checked
+// with integrity_check and foreign_key_check first. The target schema,
+// data, indexes, and foreign keys are written in one transaction, and it is
+// only committed once each table's row count and content checksum match
+// the source.
+func Copy(ctx context.Context, src, dst *sql.DB, d Dialect) ([]TableReport, error) {
+	if err := checkSource(ctx, src); err != nil {
+		return nil, err
+	}
+	tables, err := readSchema(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := readIndexes(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := dst.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, t := range tables {
+		if _, err := tx.ExecContext(ctx, createTable(t, d)); err != nil {
+			return nil, fmt.Errorf("create %s: %w", t.name, err)
+		}
+	}
+	var reports []TableReport
+	for _, t := range tables {
+		n, err := copyRows(ctx, src, tx, t, d)
+		if err != nil {
+			return nil, fmt.Errorf("copy %s: %w", t.name, err)
+		}
+		log.Printf("  Copied %s (%d rows)", t.name, n)
+	}
+	for _, stmt := range indexes {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("create index: %w", err)
+		}
+	}
+	if d.ForeignKeys {
+		for _, t := range tables {
+			for i, fk := range t.fks {
+				stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE %s ON UPDATE %s",
+					quote(t.name), quote(fmt.Sprintf("%s_fk%d", t.name, i)), quoteList(fk.from), quote(fk.table), quoteList(fk.to),
+					fkAction(fk.onDelete), fkAction(fk.update))
+				if _, err := tx.ExecContext(ctx, stmt); err != nil {
+					return nil, fmt.Errorf("foreign key %s(%s) → %s: %w", t.name, strings.Join(fk.from, ", "), fk.table, err)
+				}
+			}
+		}
+	}
+
+	for _, t := range tables {
+		want, err := checksum(ctx, src, t)
+		if err != nil {
+			return nil, fmt.Errorf("checksum source %s: %w", t.name, err)
+		}
+		got, err := checksum(ctx, tx, t)
+		if err != nil {
+			return nil, fmt.Errorf("checksum target %s: %w", t.name, err)
+		}
+		if got != want {
+			return nil, fmt.Errorf("verify %s: target has %d rows (checksum %s), source has %d (%s)",
+				t.name, got.Rows, got.Checksum[:12], want.Rows, want.Checksum[:12])
+		}
+		reports = append(reports, want)
+	}
+	return reports, tx.Commit()
+}
+
+func checkSource(ctx context.Context, src *sql.DB) error {
+	var result string
+	if err := src.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("integrity check: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("source failed integrity check: %s", result)
+	}
+	rows, err := src.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return fmt.Errorf("foreign key check: %w", err)
+	}
+	defer rows.Close()
+	var broken []string
+	for rows.Next() {
+		var tbl, parent string
+		var rowid sql.NullInt64
+		var fkid int
+		if err := rows.Scan(&tbl, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		broken = append(broken, fmt.Sprintf("%s row %d → %s", tbl, rowid.Int64, parent))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(broken) > 0 {
+		if len(broken) > 10 {
+			broken = append(broken[:10], fmt.Sprintf("and %d more", len(broken)-10))
+		}
+		return fmt.Errorf("source has dangling foreign keys: %s", strings.Join(broken, "; "))
+	}
+	return nil
+}
+
+func readSchema(ctx context.Context, src *sql.DB) ([]*table, error) {
+	names, err := queryStrings(ctx, src, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*table
+	for _, name := range names {
+		t := &table{name: name}
+
+		rows, err := src.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, name)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var c column
+			if err := rows.Scan(&c.name, &c.typ, &c.notNull, &c.dflt, &c.pk); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			t.columns = append(t.columns, c)
+		}
+		rows.Close()
+
+		// Inline UNIQUE constraints exist only as automatic indexes.
+		uniques, err := queryStrings(ctx, src, `SELECT name FROM pragma_index_list(?) WHERE origin = 'u' ORDER BY name`, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range uniques {
+			cols, err := queryStrings(ctx, src, `SELECT name FROM pragma_index_info(?) ORDER BY seqno`, idx)
+			if err != nil {
+				return nil, err
+			}
+			t.uniques = append(t.uniques, cols)
+		}
+
+		rows, err = src.QueryContext(ctx, `SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`, name)
+		if err != nil {
+			return nil, err
+		}
+		byID := map[int]*foreignKey{}
+		var order []int
+		for rows.Next() {
+			var id int
+			var parent, from, onUpdate, onDelete string
+			var to sql.NullString
+			if err := rows.Scan(&id, &parent, &from, &to, &onUpdate, &onDelete); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			fk, ok := byID[id]
+			if !ok {
+				fk = &foreignKey{table: parent, update: onUpdate, onDelete: onDelete}
+				byID[id] = fk
+				order = append(order, id)
+			}
+			fk.from = append(fk.from, from)
+			fk.to = append(fk.to, to.String)
+		}
+		rows.Close()
+		for _, id := range order {
+			t.fks = append(t.fks, *byID[id])
+		}
+		tables = append(tables, t)
+	}
+	// References without a target column point at the parent's primary key.
+	pks := map[string][]string{}
+	for _, t := range tables {
+		pks[t.name] = primaryKey(t)
+	}
+	for _, t := range tables {
+		for i := range t.fks {
+			if t.fks[i].to[0] == "" {
+				t.fks[i].to = pks[t.fks[i].table]
+			}
+		}
+	}
+	return tables, nil
+}
+
+// readIndexes returns the CREATE INDEX statements of explicitly created
+// indexes, which are plain SQL that both databases accept.
+func readIndexes(ctx context.Context, src *sql.DB) ([]string, error) {
+	return queryStrings(ctx, src, `SELECT sql FROM sqlite_master WHERE type = 'index' AND sql IS NOT NULL ORDER BY name`)
+}
+
+func primaryKey(t *table) []string {
+	var pk []column
+	for _, c := range t.columns {
+		if c.pk > 0 {
+			pk = append(pk, c)
+		}
+	}
+	sort.Slice(pk, func(i, j int) bool { return pk[i].pk < pk[j].pk })
+	names := make([]string, len(pk))
+	for i, c := range pk {
+		names[i] = c.name
+	}
+	return names
+}
+
+func createTable(t *table, d Dialect) string {
+	var defs []string
+	for _, c := range t.columns {
+		def := quote(c.name) + " " + d.ColumnType(c.typ)
+		if c.notNull {
+			def += " NOT NULL"
+		}
+		if c.dflt.Valid {
+			def += " DEFAULT " + c.dflt.String
+		}
+		defs = append(defs, def)
+	}
+	if pk := primaryKey(t); len(pk) > 0 {
+		defs = append(defs, "PRIMARY KEY ("+quoteList(pk)+")")
+	}
+	for _, u := range t.uniques {
+		defs = append(defs, "UNIQUE ("+quoteList(u)+")")
+	}
+	return "CREATE TABLE " + quote(t.name) + " (\n\t" + strings.Join(defs, ",\n\t") + "\n)"
+}
+
+func copyRows(ctx context.Context, src *sql.DB, tx *sql.Tx, t *table, d Dialect) (int64, error) {
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+	rows, err := src.QueryContext(ctx, "SELECT "+quoteList(names)+" FROM "+quote(t.name))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	perBatch := max(1, min(500, d.MaxParams/len(names)))
+	var batch []any
+	var copied int64
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n := len(batch) / len(names)
+		var tuples []string
+		for r := 0; r < n; r++ {
+			ph := make([]string, len(names))
+			for i := range names {
+				ph[i] = d.Placeholder(r*len(names) + i + 1)
+			}
+			tuples = append(tuples, "("+strings.Join(ph, ", ")+")")
+		}
+		_, err := tx.ExecContext(ctx, "INSERT INTO "+quote(t.name)+" ("+quoteList(names)+") VALUES "+strings.Join(tuples, ", "), batch...)
+		copied += int64(n)
+		batch = batch[:0]
+		return err
+	}
+	for rows.Next() {
+		vals := make([]any, len(names))
+		ptrs := make([]any, len(names))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, err
+		}
+		for i, c := range t.columns {
+			vals[i] = convert(vals[i], d.ColumnType(c.typ))
+		}
+		batch = append(batch, vals...)
+		if len(batch)/len(names) >= perBatch {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return copied, flush()
+}
+
+// convert adapts a value read from SQLite, where any column can hold any
+// type, to the target column type.
+func convert(v any, targetType string) any {
+	if targetType != "TEXT" {
+		return v
+	}
+	switch x := v.(type) {
+	case int64, float64:
+		return canonical(x)
+	case []byte:
+		return string(x)
+	}
+	return v
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// checksum hashes every row of t independently of row order, since the two
+// databases may sort text differently.
+func checksum(ctx context.Context, q querier, t *table) (TableReport, error) {
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+	rows, err := q.QueryContext(ctx, "SELECT "+quoteList(names)+" FROM "+quote(t.name))
+	if err != nil {
+		return TableReport{}, err
+	}
+	defer rows.Close()
+
+	var sums [][32]byte
+	for rows.Next() {
+		vals := make([]any, len(names))
+		ptrs := make([]any, len(names))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return TableReport{}, err
+		}
+		h := sha256.New()
+		for _, v := range vals {
+			s := canonical(v)
+			fmt.Fprintf(h, "%d:%s", len(s), s)
+		}
+		var sum [32]byte
+		copy(sum[:], h.Sum(nil))
+		sums = append(sums, sum)
+	}
+	if err := rows.Err(); err != nil {
+		return TableReport{}, err
+	}
+	sort.Slice(sums, func(i, j int) bool { return string(sums[i][:]) < string(sums[j][:]) })
+	h := sha256.New()
+	for _, s := range sums {
+		h.Write(s[:])
+	}
+	return TableReport{Name: t.name, Rows: int64(len(sums)), Checksum: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// canonical renders a scanned value the same way whichever driver read it.
+func canonical(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "\x00"
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case bool:
+		if x {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return string(x)
+	case string:
+		return x
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func queryStrings(ctx context.Context, q querier, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func fkAction(a string) string {
+	switch a = strings.ToUpper(a); a {
+	case "CASCADE", "SET NULL", "SET DEFAULT", "RESTRICT":
+		return a
+	default:
+		return "NO ACTION"
+	}
+}
+
+func quote(name string) string { return `"` + strings.ReplaceAll(name, `"`, `""`) + `"` }
+
+func quoteList(names []string) string {
+	q := make([]string, len(names))
+	for i, n := range names {
+		q[i] = quote(n)
+	}
+	return strings.Join(q, ", ")
+}
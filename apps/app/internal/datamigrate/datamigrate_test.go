@@ -0,0 +1,79 @@
+package datamigrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"policyflow/internal/database"
+	"policyflow/internal/seed"
+)
+
+// sqliteTarget lets the copy be exercised without a Postgres server.
+var sqliteTarget = Dialect{
+	Name:        "sqlite",
+	Placeholder: func(int) string { return "?" },
+	ColumnType:  func(t string) string { return strings.ToUpper(t) },
+	MaxParams:   999,
+}
+
+func openSQLite(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite", filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	src := openSQLite(t, "src.db")
+	db := database.New(src)
+	if err := db.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := seed.Run(ctx, db, "admin@example.com", "Admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := openSQLite(t, "dst.db")
+	tables, err := Copy(ctx, src, dst, sqliteTarget)
+	if err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	rows := map[string]int64{}
+	for _, tr := range tables {
+		rows[tr.Name] = tr.Rows
+	}
+	if rows["users"] != 2 || rows["policies"] != 2 || rows["schema_migrations"] == 0 {
+		t.Errorf("copied rows = %v", rows)
+	}
+	var srcID, dstID string
+	src.QueryRow(`SELECT id FROM users WHERE email = 'admin@example.com'`).Scan(&srcID)
+	dst.QueryRow(`SELECT id FROM users WHERE email = 'admin@example.com'`).Scan(&dstID)
+	if srcID == "" || srcID != dstID {
+		t.Errorf("user ID %q became %q", srcID, dstID)
+	}
+
+	// A second run must not touch a target that already has the tables.
+	if _, err := Copy(ctx, src, dst, sqliteTarget); err == nil {
+		t.Error("Copy into a non-empty target succeeded")
+	}
+
+	// Dangling references in the source stop the copy before it starts.
+	src.Exec(`PRAGMA foreign_keys = OFF`)
+	src.Exec(`INSERT INTO acknowledgements (id, user_id, policy_version_id, timestamp, signature_hash) VALUES ('a', 'ghost', 'ghost', '2026-01-01T00:00:00Z', 'x')`)
+	if _, err := Copy(ctx, src, openSQLite(t, "dst2.db"), sqliteTarget); err == nil || !strings.Contains(err.Error(), "dangling") {
+		t.Errorf("Copy with dangling keys err = %v", err)
+	}
+}
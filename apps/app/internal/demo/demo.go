@@ -0,0 +1,52 @@
+// Package demo keeps a public demo instance filled with generated data,
+// reset every night so visitors' changes do not pile up.
+package demo
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"policyflow/internal/database"
+)
+
+// resetHour is when, in UTC, the nightly reset runs.
+const resetHour = 3
+
+// Reset replaces the database contents with a fresh demo organization.
+// It fails with database.ErrNotDemoData on a database holding real data.
+func Reset(ctx context.Context, db *database.DB) error {
+	sum, err := db.ResetDemoData(ctx, 1)
+	if err != nil {
+		return err
+	}
+	log.Printf("demo: reset to %d departments, %d users, %d policies, %d acknowledgements",
+		sum.Departments, sum.Users, sum.Policies, sum.Acknowledgements)
+	return nil
+}
+
+// Schedule resets the demo every night until ctx is cancelled.
+func Schedule(ctx context.Context, db *database.DB) {
+	for {
+		timer := time.NewTimer(time.Until(nextReset(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := Reset(ctx, db); err != nil {
+				log.Printf("demo: %v", err)
+			}
+		}
+	}
+}
+
+// nextReset returns the first reset time after now.
+func nextReset(now time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), resetHour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,488 @@
+// Package docx converts Word documents to markdown.
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid is returned for files that are not Word (.docx) documents.
+var ErrInvalid = errors.New("not a valid .docx file")
+
+// maxPartBytes caps how much of any one part of the package is read, so a
+// small, highly compressed upload cannot exhaust memory.
+const maxPartBytes = 64 << 20
+
+// Document is a converted Word document.
+type Document struct {
+	Title    string   // text of the first Title paragraph, if any
+	Markdown string   // headings, paragraphs, lists, and tables
+	Warnings []string // content that could not be converted
+}
+
+// Convert reads a .docx package and renders its body as markdown. Headings
+// come from the built-in "Heading 1"–"Heading 6" styles, lists from Word
+// numbering, and tables become pipe tables with the first row as header.
+// Bold, italic, and hyperlinks are kept; images and other embedded objects
+// are dropped with a warning.
+func Convert(r io.ReaderAt, size int64) (*Document, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	parts := map[string]*zip.File{}
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+	body, ok := parts["word/document.xml"]
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	c := &converter{headings: map[string]int{}, titles: map[string]bool{}, ordered: map[string]bool{}, links: map[string]string{}}
+	if f, ok := parts["word/styles.xml"]; ok {
+		if err := c.loadStyles(f); err != nil {
+			return nil, err
+		}
+	}
+	if f, ok := parts["word/numbering.xml"]; ok {
+		if err := c.loadNumbering(f); err != nil {
+			return nil, err
+		}
+	}
+	if f, ok := parts["word/_rels/document.xml.rels"]; ok {
+		if err := c.loadLinks(f); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.convert(body); err != nil {
+		return nil, err
+	}
+	if c.images > 0 {
+		c.doc.Warnings = append(c.doc.Warnings, fmt.Sprintf("%d image(s) or embedded object(s) were not imported", c.images))
+	}
+	c.doc.Markdown = strings.TrimSpace(c.out.String()) + "\n"
+	return &c.doc, nil
+}
+
+type converter struct {
+	headings map[string]int    // style ID → heading level
+	titles   map[string]bool   // style IDs of the Title style
+	ordered  map[string]bool   // "numID/ilvl" → numbered rather than bulleted
+	links    map[string]string // relationship ID → external URL
+
+	doc      Document
+	out      strings.Builder
+	lastList bool // previous block was a list item
+	images   int
+}
+
+func decodePart(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return ErrInvalid
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartBytes)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalid, f.Name, err)
+	}
+	return nil
+}
+
+type valAttr struct {
+	Val string `xml:"val,attr"`
+}
+
+var headingName = regexp.MustCompile(`^heading ([1-9])$`)
+
+func (c *converter) loadStyles(f *zip.File) error {
+	var styles struct {
+		Styles []struct {
+			ID   string  `xml:"styleId,attr"`
+			Name valAttr `xml:"name"`
+		} `xml:"style"`
+	}
+	if err := decodePart(f, &styles); err != nil {
+		return err
+	}
+	for _, s := range styles.Styles {
+		name := strings.ToLower(s.Name.Val)
+		if m := headingName.FindStringSubmatch(name); m != nil {
+			level, _ := strconv.Atoi(m[1])
+			c.headings[s.ID] = min(level, 6)
+		} else if name == "title" {
+			c.titles[s.ID] = true
+		}
+	}
+	return nil
+}
+
+func (c *converter) loadNumbering(f *zip.File) error {
+	var numbering struct {
+		Abstract []struct {
+			ID     string `xml:"abstractNumId,attr"`
+			Levels []struct {
+				Level  string  `xml:"ilvl,attr"`
+				Format valAttr `xml:"numFmt"`
+			} `xml:"lvl"`
+		} `xml:"abstractNum"`
+		Nums []struct {
+			ID       string  `xml:"numId,attr"`
+			Abstract valAttr `xml:"abstractNumId"`
+		} `xml:"num"`
+	}
+	if err := decodePart(f, &numbering); err != nil {
+		return err
+	}
+	formats := map[string]map[string]string{}
+	for _, a := range numbering.Abstract {
+		formats[a.ID] = map[string]string{}
+		for _, l := range a.Levels {
+			formats[a.ID][l.Level] = l.Format.Val
+		}
+	}
+	for _, n := range numbering.Nums {
+		for level, format := range formats[n.Abstract.Val] {
+			c.ordered[n.ID+"/"+level] = format != "bullet" && format != "none" && format != ""
+		}
+	}
+	return nil
+}
+
+func (c *converter) loadLinks(f *zip.File) error {
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+			Mode   string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(f, &rels); err != nil {
+		return err
+	}
+	for _, r := range rels.Rels {
+		if r.Mode == "External" {
+			c.links[r.ID] = r.Target
+		}
+	}
+	return nil
+}
+
+// convert walks the document body, rendering paragraphs and tables in order.
+// Paragraphs nested in content controls or text boxes are found wherever
+// they appear.
+func (c *converter) convert(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return ErrInvalid
+	}
+	defer rc.Close()
+	d := xml.NewDecoder(io.LimitReader(rc, maxPartBytes))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "p":
+			p, err := c.paragraph(d, start)
+			if err != nil {
+				return err
+			}
+			c.writeParagraph(p)
+		case "tbl":
+			rows, err := c.table(d, start)
+			if err != nil {
+				return err
+			}
+			c.writeTable(rows)
+		}
+	}
+}
+
+// para is a parsed paragraph: its style, list membership, and inline markdown.
+type para struct {
+	style string
+	numID string
+	level int
+	text  string
+}
+
+// span is a run of text with uniform formatting.
+type span struct {
+	text         string
+	bold, italic bool
+	link         string
+}
+
+func (c *converter) paragraph(d *xml.Decoder, start xml.StartElement) (*para, error) {
+	p := &para{}
+	var spans []span
+	var cur span
+	var link string
+	inRun, inRunProps := false, false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "pStyle":
+				p.style = attr(t, "val")
+			case "numId":
+				p.numID = attr(t, "val")
+			case "ilvl":
+				p.level, _ = strconv.Atoi(attr(t, "val"))
+			case "hyperlink":
+				link = c.links[attr(t, "id")]
+			case "r":
+				inRun, cur = true, span{link: link}
+			case "rPr":
+				inRunProps = inRun
+			case "b":
+				if inRunProps {
+					cur.bold = on(t)
+				}
+			case "i":
+				if inRunProps {
+					cur.italic = on(t)
+				}
+			case "t":
+				var s string
+				if err := d.DecodeElement(&s, &t); err != nil {
+					return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+				}
+				cur.text += s
+			case "tab", "br", "cr":
+				cur.text += " "
+			case "drawing", "pict", "object":
+				c.images++
+				if err := d.Skip(); err != nil {
+					return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+				}
+			case "delText", "instrText", "footnoteReference":
+				// Tracked deletions and field codes are not document text.
+				if err := d.Skip(); err != nil {
+					return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "rPr":
+				inRunProps = false
+			case "r":
+				if inRun && cur.text != "" {
+					spans = append(spans, cur)
+				}
+				inRun = false
+			case "hyperlink":
+				link = ""
+			case start.Name.Local:
+				p.text = renderSpans(spans)
+				return p, nil
+			}
+		}
+	}
+}
+
+func attr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// on reads a toggle property such as <w:b/> or <w:b w:val="0"/>.
+func on(e xml.StartElement) bool {
+	v := attr(e, "val")
+	return v != "0" && v != "false" && v != "none"
+}
+
+// renderSpans merges adjacent spans with the same formatting and renders
+// them as inline markdown. Emphasis markers go inside surrounding spaces,
+// where markdown requires them.
+func renderSpans(spans []span) string {
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && merged[n-1].bold == s.bold && merged[n-1].italic == s.italic && merged[n-1].link == s.link {
+			merged[n-1].text += s.text
+			continue
+		}
+		merged = append(merged, s)
+	}
+	var b strings.Builder
+	for _, s := range merged {
+		text := escape(s.text)
+		core := strings.TrimSpace(text)
+		if core == "" {
+			b.WriteString(text)
+			continue
+		}
+		lead := text[:strings.Index(text, core)]
+		trail := text[len(lead)+len(core):]
+		if s.italic {
+			core = "_" + core + "_"
+		}
+		if s.bold {
+			core = "**" + core + "**"
+		}
+		if s.link != "" {
+			core = "[" + core + "](" + s.link + ")"
+		}
+		b.WriteString(lead + core + trail)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+var mdSpecial = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`)
+
+func escape(s string) string { return mdSpecial.Replace(s) }
+
+// blockStart matches text that markdown would read as a heading, quote, or
+// list item when it starts a line.
+var blockStart = regexp.MustCompile(`^(#|>|[-+] |\d+[.)] )`)
+
+func (c *converter) writeParagraph(p *para) {
+	if p.text == "" {
+		return
+	}
+	if c.titles[p.style] {
+		if c.doc.Title == "" {
+			c.doc.Title = unescape(p.text)
+			return
+		}
+		c.block("# "+p.text, false)
+		return
+	}
+	if level, ok := c.headings[p.style]; ok {
+		c.block(strings.Repeat("#", level)+" "+p.text, false)
+		return
+	}
+	if p.numID != "" && p.numID != "0" {
+		marker := "- "
+		if c.ordered[p.numID+"/"+strconv.Itoa(p.level)] {
+			marker = "1. "
+		}
+		c.block(strings.Repeat("   ", p.level)+marker+p.text, true)
+		return
+	}
+	text := p.text
+	if blockStart.MatchString(text) {
+		text = `\` + text
+	}
+	c.block(text, false)
+}
+
+// block appends a markdown block, separating blocks with a blank line but
+// keeping consecutive list items together.
+func (c *converter) block(s string, listItem bool) {
+	if c.out.Len() > 0 {
+		if listItem && c.lastList {
+			c.out.WriteString("\n")
+		} else {
+			c.out.WriteString("\n\n")
+		}
+	}
+	c.out.WriteString(s)
+	c.lastList = listItem
+}
+
+// table collects a table's cell text, row by row. Paragraphs within a cell,
+// including those of nested tables, are joined with spaces.
+func (c *converter) table(d *xml.Decoder, start xml.StartElement) ([][]string, error) {
+	var rows [][]string
+	depth := 0 // nesting of tables inside this one
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tbl":
+				depth++
+			case "tr":
+				if depth == 0 {
+					rows = append(rows, nil)
+				}
+			case "tc":
+				if depth == 0 && len(rows) > 0 {
+					rows[len(rows)-1] = append(rows[len(rows)-1], "")
+				}
+			case "p":
+				p, err := c.paragraph(d, t)
+				if err != nil {
+					return nil, err
+				}
+				if n := len(rows); n > 0 && len(rows[n-1]) > 0 && p.text != "" {
+					cell := &rows[n-1][len(rows[n-1])-1]
+					*cell = strings.TrimSpace(*cell + " " + p.text)
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "tbl" {
+				if depth == 0 {
+					return rows, nil
+				}
+				depth--
+			}
+		}
+	}
+}
+
+func (c *converter) writeTable(rows [][]string) {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r))
+	}
+	if width == 0 {
+		return
+	}
+	var b strings.Builder
+	line := func(cells []string) {
+		b.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(cells) {
+				cell = strings.ReplaceAll(cells[i], "|", `\|`)
+			}
+			b.WriteString(" " + cell + " |")
+		}
+	}
+	line(rows[0])
+	b.WriteString("\n|" + strings.Repeat(" --- |", width))
+	for _, r := range rows[1:] {
+		b.WriteString("\n")
+		line(r)
+	}
+	c.block(b.String(), false)
+}
+
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
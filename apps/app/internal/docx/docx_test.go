@@ -0,0 +1,76 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func build(t *testing.T, parts map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+const ns = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+func TestConvert(t *testing.T) {
+	r := build(t, map[string]string{
+		"word/document.xml": `<w:document ` + ns + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>Travel Policy</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Scope</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Applies to </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>all</w:t></w:r><w:r><w:t xml:space="preserve"> staff, see </w:t></w:r><w:hyperlink r:id="rId9"><w:r><w:t>the portal</w:t></w:r></w:hyperlink><w:r><w:t>.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Book early</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Economy class</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>Submit receipts</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Grade</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Limit</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>A|B</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>$100</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:p><w:r><w:drawing/></w:r></w:p>
+</w:body></w:document>`,
+		"word/styles.xml": `<w:styles ` + ns + `>
+<w:style w:styleId="Title"><w:name w:val="Title"/></w:style>
+<w:style w:styleId="Heading1"><w:name w:val="heading 1"/></w:style></w:styles>`,
+		"word/numbering.xml": `<w:numbering ` + ns + `>
+<w:abstractNum w:abstractNumId="10"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:abstractNum w:abstractNumId="20"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="10"/></w:num>
+<w:num w:numId="2"><w:abstractNumId w:val="20"/></w:num></w:numbering>`,
+		"word/_rels/document.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId9" Type="hyperlink" Target="https://intranet.example.com" TargetMode="External"/></Relationships>`,
+	})
+
+	doc, err := Convert(r, r.Size())
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	want := "# Scope\n\n" +
+		"Applies to **all** staff, see [the portal](https://intranet.example.com).\n\n" +
+		"- Book early\n" +
+		"   - Economy class\n" +
+		"1. Submit receipts\n\n" +
+		"| Grade | Limit |\n| --- | --- |\n| A\\|B | $100 |\n"
+	if doc.Markdown != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", doc.Markdown, want)
+	}
+	if doc.Title != "Travel Policy" {
+		t.Errorf("title = %q", doc.Title)
+	}
+	if len(doc.Warnings) != 1 {
+		t.Errorf("warnings = %v; want one for the image", doc.Warnings)
+	}
+
+	if _, err := Convert(bytes.NewReader([]byte("not a zip")), 9); err != ErrInvalid {
+		t.Errorf("Convert(garbage) err = %v; want ErrInvalid", err)
+	}
+}
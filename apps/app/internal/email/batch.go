@@ -0,0 +1,103 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+)
+
+// maxPerConn is how many messages a Batch sends over one connection before
+// reconnecting, below the per-session limit common SMTP servers enforce.
+const maxPerConn = 100
+
+// Batch sends many messages over one SMTP connection, for runs such as
+// deadline reminders that mail hundreds of users at once. Its embedded
+// Mailer has the usual Send methods; each still returns its own error, and
+// a failed message does not stop the ones after it. The connection is
+// opened on the first send and must be released with Close.
+type Batch struct {
+	*Mailer
+
+	client *smtp.Client
+	onConn int // messages sent over client
+	sent   int
+	errs   []error
+}
+
+// SendError is a message a Batch could not deliver.
+type SendError struct {
+	To  string
+	Err error
+}
+
+func (e *SendError) Error() string { return fmt.Sprintf("%s: %v", e.To, e.Err) }
+func (e *SendError) Unwrap() error { return e.Err }
+
+// Batch starts a batch of messages sharing one connection.
+func (m *Mailer) Batch() *Batch {
+	mm := *m
+	b := &Batch{Mailer: &mm}
+	mm.batch = b
+	return b
+}
+
+// send delivers msg over the batch's connection, dialing a new one when
+// there is none. After a failure the connection is reset for the next
+// message, or dropped if the server is no longer answering.
+func (b *Batch) send(to, msg string) error {
+	err := b.trySend(to, msg)
+	if err != nil {
+		err = &SendError{To: to, Err: err}
+		b.errs = append(b.errs, err)
+		return err
+	}
+	b.sent++
+	return nil
+}
+
+func (b *Batch) trySend(to, msg string) error {
+	if b.client != nil && b.onConn >= maxPerConn {
+		b.hangUp()
+	}
+	if b.client == nil {
+		client, err := b.dial()
+		if err != nil {
+			return err
+		}
+		b.client, b.onConn = client, 0
+	}
+	b.onConn++
+	if err := b.deliver(b.client, to, msg); err != nil {
+		if b.client.Reset() != nil {
+			b.client.Close()
+			b.client = nil
+		}
+		return err
+	}
+	return nil
+}
+
+// hangUp ends the current connection.
+func (b *Batch) hangUp() {
+	if err := b.client.Quit(); err != nil {
+		b.client.Close()
+	}
+	b.client = nil
+}
+
+// Sent is the number of messages delivered so far.
+func (b *Batch) Sent() int { return b.sent }
+
+// Err joins the SendErrors of every failed message so far, or is nil.
+func (b *Batch) Err() error { return errors.Join(b.errs...) }
+
+// Close ends the batch's connection, if one is open.
+func (b *Batch) Close() {
+	if b.client != nil {
+		b.hangUp()
+	}
+	if len(b.errs) > 0 {
+		log.Printf("SMTP: batch sent %d, failed %d", b.sent, len(b.errs))
+	}
+}
@@ -0,0 +1,111 @@
+package email
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeSMTP is a minimal SMTP server that rejects recipients containing
+// "reject" and records how many connections it accepted and messages it
+// received.
+type fakeSMTP struct {
+	conns    atomic.Int32
+	mu       sync.Mutex
+	received []string
+}
+
+func startFakeSMTP(t *testing.T) (*fakeSMTP, int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	s := &fakeSMTP{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.conns.Add(1)
+			go s.serve(conn)
+		}
+	}()
+	return s, ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	reply := func(line string) { w.WriteString(line + "\r\n"); w.Flush() }
+	reply("220 fake ESMTP")
+	var rcpt string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "RCPT"):
+			if strings.Contains(cmd, "REJECT") {
+				reply("550 no such user")
+				continue
+			}
+			rcpt = strings.TrimSpace(line)
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+			}
+			s.mu.Lock()
+			s.received = append(s.received, rcpt)
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default: // MAIL, RSET, NOOP
+			reply("250 ok")
+		}
+	}
+}
+
+func TestBatch_ReusesConnectionAndCollectsErrors(t *testing.T) {
+	srv, port := startFakeSMTP(t)
+	m := &Mailer{host: "127.0.0.1", port: port, from: "policyflow@example.com"}
+
+	b := m.Batch()
+	for _, to := range []string{"a@example.com", "reject@example.com", "b@example.com"} {
+		b.SendScheduledReport(to, "Weekly", 1, Attachment{Filename: "r.csv", ContentType: "text/csv", Data: []byte("x")})
+	}
+	b.Close()
+
+	if n := srv.conns.Load(); n != 1 {
+		t.Errorf("connections = %d; want 1", n)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.received) != 2 || b.Sent() != 2 {
+		t.Errorf("received %v, sent %d; want both deliverable messages", srv.received, b.Sent())
+	}
+	var se *SendError
+	if err := b.Err(); !errors.As(err, &se) || se.To != "reject@example.com" {
+		t.Errorf("Err() = %v; want the rejected recipient", err)
+	}
+}
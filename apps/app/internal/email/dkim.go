@@ -0,0 +1,181 @@
+package email
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dkimHeaders are the headers signed when present, in signing order.
+var dkimHeaders = []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type"}
+
+// dkimSigner adds DKIM-Signature headers (RFC 6376, relaxed/relaxed) so
+// that receivers can check messages really come from the sending domain.
+type dkimSigner struct {
+	domain   string
+	selector string
+	key      crypto.Signer
+	algo     string // "rsa-sha256" or "ed25519-sha256"
+}
+
+// SetDKIM signs outgoing mail for domain, or the From address's domain if
+// empty, with the first RSA or Ed25519 key in pemData (PKCS#8 "PRIVATE KEY"
+// or PKCS#1 "RSA PRIVATE KEY"). The public key must be published in DNS at
+// <selector>._domainkey.<domain>.
+func (m *Mailer) SetDKIM(domain, selector string, pemData []byte) error {
+	if domain == "" {
+		_, domain, _ = strings.Cut(m.from, "@")
+	}
+	if domain == "" || selector == "" {
+		return errors.New("email: DKIM needs both a domain and a selector")
+	}
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			return errors.New("email: no private key found in DKIM PEM data")
+		}
+		var priv any
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("email: parse DKIM key: %w", err)
+		}
+		s := &dkimSigner{domain: domain, selector: selector}
+		switch k := priv.(type) {
+		case *rsa.PrivateKey:
+			s.key, s.algo = k, "rsa-sha256"
+		case ed25519.PrivateKey:
+			s.key, s.algo = k, "ed25519-sha256"
+		default:
+			return errors.New("email: DKIM keys must be RSA or Ed25519")
+		}
+		m.dkim = s
+		return nil
+	}
+}
+
+// sign returns msg, with its line endings normalized to CRLF, under a
+// DKIM-Signature header.
+func (s *dkimSigner) sign(msg string, now time.Time) (string, error) {
+	msg = strings.ReplaceAll(strings.ReplaceAll(msg, "\r\n", "\n"), "\n", "\r\n")
+	header, body, _ := strings.Cut(msg, "\r\n\r\n")
+	header += "\r\n"
+
+	bodyHash := sha256.Sum256([]byte(relaxedBody(body)))
+	fields := headerFields(header)
+	var names []string
+	var signed strings.Builder
+	for _, name := range dkimHeaders {
+		if f, ok := fields[strings.ToLower(name)]; ok {
+			names = append(names, name)
+			signed.WriteString(relaxedHeader(f))
+		}
+	}
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		s.algo, s.domain, s.selector, now.Unix(), strings.Join(names, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+	signed.WriteString(strings.TrimSuffix(relaxedHeader("DKIM-Signature: "+value+"\r\n"), "\r\n"))
+
+	digest := sha256.Sum256([]byte(signed.String()))
+	opts := crypto.Hash(0)
+	if s.algo == "rsa-sha256" {
+		opts = crypto.SHA256
+	}
+	sig, err := s.key.Sign(rand.Reader, digest[:], opts)
+	if err != nil {
+		return "", fmt.Errorf("dkim sign: %w", err)
+	}
+	return "DKIM-Signature: " + value + foldBase64(base64.StdEncoding.EncodeToString(sig)) + "\r\n" + msg, nil
+}
+
+// headerFields maps each lowercased header name to its first field,
+// continuation lines included.
+func headerFields(header string) map[string]string {
+	out := map[string]string{}
+	lines := strings.SplitAfter(header, "\r\n")
+	for i := 0; i < len(lines); i++ {
+		field := lines[i]
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "\t")) {
+			i++
+			field += lines[i]
+		}
+		name, _, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, seen := out[name]; !seen {
+			out[name] = field
+		}
+	}
+	return out
+}
+
+// relaxedHeader canonicalizes one header field under the "relaxed"
+// algorithm: lowercase name, unfolded value with runs of whitespace
+// collapsed, and no whitespace around the colon or at the end.
+func relaxedHeader(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+	value = strings.ReplaceAll(value, "\r\n", "")
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + strings.Join(strings.Fields(value), " ") + "\r\n"
+}
+
+// relaxedBody canonicalizes a body under the "relaxed" algorithm: runs of
+// whitespace collapsed, trailing whitespace and trailing empty lines
+// removed, and a non-empty body ending in CRLF.
+func relaxedBody(body string) string {
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		var b strings.Builder
+		space := false
+		for _, r := range line {
+			if r == ' ' || r == '\t' {
+				space = true
+				continue
+			}
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(r)
+		}
+		lines[i] = b.String()
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// foldBase64 splits a long signature over continuation lines, as header
+// lines should stay under 78 characters.
+func foldBase64(s string) string {
+	var b strings.Builder
+	for len(s) > 64 {
+		b.WriteString(s[:64] + "\r\n\t")
+		s = s[64:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package email
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRelaxedCanonicalization(t *testing.T) {
+	// The example from RFC 6376 section 3.4.6.
+	fields := headerFields("A: X\r\nB : Y\t\r\n\tZ  \r\n")
+	if got := relaxedHeader(fields["a"]) + relaxedHeader(fields["b"]); got != "a:X\r\nb:Y Z\r\n" {
+		t.Errorf("headers = %q", got)
+	}
+	if got := relaxedBody(" C \r\nD \t E\r\n\r\n\r\n"); got != " C\r\nD E\r\n" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+// TestDKIM_SignsVerifiably checks a signed message against the signer's
+// public key the way a receiver would.
+func TestDKIM_SignsVerifiably(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edDER, _ := x509.MarshalPKCS8PrivateKey(edKey)
+
+	for name, keyPEM := range map[string][]byte{
+		"rsa":     pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+		"ed25519": pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := &Mailer{from: "noreply@example.com"}
+			if err := m.SetDKIM("", "pf2026", keyPEM); err != nil {
+				t.Fatal(err)
+			}
+			msg, err := m.buildMessage("ann@example.org", "Reminder", "Hi Ann,\n\nPlease  read this.\n", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signed, err := m.dkim.sign(msg, time.Unix(1700000000, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header, body, _ := strings.Cut(signed, "\r\n\r\n")
+			fields := headerFields(header + "\r\n")
+			sigField := fields["dkim-signature"]
+			tags := map[string]string{}
+			for _, tag := range strings.Split(strings.ReplaceAll(strings.SplitN(sigField, ":", 2)[1], "\r\n\t", ""), ";") {
+				k, v, _ := strings.Cut(strings.TrimSpace(tag), "=")
+				tags[k] = v
+			}
+			if tags["d"] != "example.com" || tags["s"] != "pf2026" || tags["h"] != "From:To:Subject:MIME-Version:Content-Type" {
+				t.Fatalf("tags = %v", tags)
+			}
+			bh := sha256.Sum256([]byte(relaxedBody(body)))
+			if tags["bh"] != base64.StdEncoding.EncodeToString(bh[:]) {
+				t.Errorf("body hash mismatch")
+			}
+
+			var data strings.Builder
+			for _, h := range strings.Split(tags["h"], ":") {
+				data.WriteString(relaxedHeader(fields[strings.ToLower(h)]))
+			}
+			unsigned := regexp.MustCompile(`b=[^;]*$`).ReplaceAllString(relaxedHeader(sigField), "b=")
+			data.WriteString(strings.TrimSuffix(unsigned, "\r\n"))
+			digest := sha256.Sum256([]byte(data.String()))
+			sig, _ := base64.StdEncoding.DecodeString(tags["b"])
+
+			switch name {
+			case "rsa":
+				err = rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], sig)
+			case "ed25519":
+				if !ed25519.Verify(edKey.Public().(ed25519.PublicKey), digest[:], sig) {
+					err = rsa.ErrVerification
+				}
+			}
+			if err != nil {
+				t.Errorf("signature does not verify: %v", err)
+			}
+		})
+	}
+}
@@ -1,13 +1,19 @@
 package email
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Mailer sends emails via SMTP or logs them if SMTP is not configured.
@@ -19,6 +25,11 @@ type Mailer struct {
 	from     string
 	devMode  bool
 	useTLS   bool // true = implicit TLS (port 465); false = STARTTLS (port 587)
+
+	dkim      *dkimSigner // nil unless SetDKIM was called
+	overrides Overrides   // nil unless SetOverrides was called
+	batch     *Batch      // set on a Batch's Mailer: send over its connection
+	outbox    *Outbox     // nil unless Capture was called
 }
 
 func New() *Mailer {
@@ -46,100 +57,298 @@ func New() *Mailer {
 	}
 }
 
-func (m *Mailer) SendMagicLink(toEmail, toName, magicURL string) error {
-	subject := "PolicyFlow — Your login link"
-	body := fmt.Sprintf(`Hi %s,
+func (m *Mailer) SendMagicLink(toEmail, toName, magicURL string, validFor time.Duration) error {
+	subject, body := m.render(TemplateMagicLink, map[string]any{
+		"Name": toName, "URL": magicURL, "ValidFor": humanDuration(validFor),
+	})
+	return m.send(toEmail, subject, body)
+}
+
+// SendLoginCode emails a one-time sign-in code, for users whose mail
+// gateway rewrites or pre-opens links.
+func (m *Mailer) SendLoginCode(toEmail, toName, code string, validFor time.Duration) error {
+	subject, body := m.render(TemplateLoginCode, map[string]any{
+		"Name": toName, "Code": code, "ValidFor": humanDuration(validFor),
+	})
+	return m.send(toEmail, subject, body)
+}
 
-Click the link below to log in to PolicyFlow. This link is valid for 24 hours.
+func (m *Mailer) SendNewUserWelcome(toEmail, toName, magicURL string, validFor time.Duration) error {
+	subject, body := m.render(TemplateWelcome, map[string]any{
+		"Name": toName, "URL": magicURL, "ValidFor": humanDuration(validFor),
+	})
+	return m.send(toEmail, subject, body)
+}
 
-%s
+// SendNewDeviceLogin warns a user that their account was signed in to from a
+// browser it has not seen before.
+func (m *Mailer) SendNewDeviceLogin(toEmail, toName string, at time.Time, ipAddress, userAgent string) error {
+	subject, body := m.render(TemplateNewDeviceLogin, map[string]any{
+		"Name": toName, "Time": at.UTC().Format("Mon 2 Jan 2006 15:04 MST"), "IP": ipAddress, "Browser": userAgent,
+	})
+	return m.send(toEmail, subject, body)
+}
 
-If you did not request this, you can safely ignore this email.
+// ReminderItem is one outstanding policy listed in a deadline reminder.
+type ReminderItem struct {
+	Title    string
+	Deadline time.Time
+	Overdue  bool
+	URL      string
+}
 
-— The PolicyFlow Team
-`, toName, magicURL)
+// SendDeadlineReminder lists outstanding policies with their deadlines and
+// attaches an iCalendar file so recipients can add the deadlines to their calendar.
+func (m *Mailer) SendDeadlineReminder(toEmail, toName string, items []ReminderItem, calendar []byte) error {
+	var list strings.Builder
+	for _, it := range items {
+		status := "due " + it.Deadline.Format("Mon 2 Jan 2006")
+		if it.Overdue {
+			status = "OVERDUE since " + it.Deadline.Format("Mon 2 Jan 2006")
+		}
+		fmt.Fprintf(&list, "• %s — %s\n  %s\n", it.Title, status, it.URL)
+	}
+	subject, body := m.render(TemplateDeadlineReminder, map[string]any{"Name": toName, "Policies": list.String()})
+	return m.sendWithAttachments(toEmail, subject, body, []Attachment{{
+		Filename:    "policy-deadlines.ics",
+		ContentType: "text/calendar; charset=utf-8; method=PUBLISH",
+		Data:        calendar,
+	}})
+}
 
+// SendDepartmentWelcome lists, in one email, the policies awaiting the
+// acknowledgement of a user who has just joined a department.
+func (m *Mailer) SendDepartmentWelcome(toEmail, toName, department string, items []ReminderItem) error {
+	var list strings.Builder
+	for _, it := range items {
+		fmt.Fprintf(&list, "• %s", it.Title)
+		switch {
+		case it.Deadline.IsZero():
+		case it.Overdue:
+			fmt.Fprintf(&list, " — OVERDUE since %s", it.Deadline.Format("Mon 2 Jan 2006"))
+		default:
+			fmt.Fprintf(&list, " — due %s", it.Deadline.Format("Mon 2 Jan 2006"))
+		}
+		fmt.Fprintf(&list, "\n  %s\n", it.URL)
+	}
+	subject, body := m.render(TemplateDepartmentWelcome, map[string]any{
+		"Name": toName, "Department": department, "Policies": list.String(),
+	})
 	return m.send(toEmail, subject, body)
 }
 
-func (m *Mailer) SendNewUserWelcome(toEmail, toName, magicURL string) error {
-	subject := "Welcome to PolicyFlow"
-	body := fmt.Sprintf(`Hi %s,
-
-An account has been created for you on PolicyFlow, your company's policy management system.
+// SendCampaignReminder lists the policies of an acknowledgement campaign a
+// user has still to acknowledge.
+func (m *Mailer) SendCampaignReminder(toEmail, toName, campaign, description string, deadline time.Time, items []ReminderItem) error {
+	var list strings.Builder
+	for _, it := range items {
+		fmt.Fprintf(&list, "• %s\n  %s\n", it.Title, it.URL)
+	}
+	subject, body := m.render(TemplateCampaignReminder, map[string]any{
+		"Name": toName, "Campaign": campaign, "Description": description,
+		"Deadline": deadline.Format("Mon 2 Jan 2006"), "Policies": list.String(),
+	})
+	return m.send(toEmail, subject, body)
+}
 
-Click the link below to log in for the first time. This link is valid for 24 hours.
+// SendExceptionRequest asks a policy owner to approve or deny an exception.
+func (m *Mailer) SendExceptionRequest(toEmail, toName, requester, policyTitle, justification string, days int, reviewURL string) error {
+	subject, body := m.render(TemplateExceptionRequest, map[string]any{
+		"Name": toName, "Requester": requester, "Policy": policyTitle, "Days": days,
+		"Justification": justification, "URL": reviewURL,
+	})
+	return m.send(toEmail, subject, body)
+}
 
-%s
+// SendExceptionDecision tells the requester whether their exception was
+// approved and, if so, until when.
+func (m *Mailer) SendExceptionDecision(toEmail, toName, policyTitle string, approved bool, note string, expiresAt *time.Time) error {
+	decision, outcome := "denied", "denied"
+	if approved {
+		decision, outcome = "approved", "approved until "+expiresAt.Format("Mon 2 Jan 2006")
+	}
+	subject, body := m.render(TemplateExceptionDecision, map[string]any{
+		"Name": toName, "Policy": policyTitle, "Decision": decision, "Outcome": outcome, "Note": note,
+	})
+	return m.send(toEmail, subject, body)
+}
 
-After logging in, you can view and acknowledge company policies.
+// SendScheduledReport delivers a scheduled report as an attachment.
+func (m *Mailer) SendScheduledReport(toEmail, title string, rows int, report Attachment) error {
+	subject, body := m.render(TemplateScheduledReport, map[string]any{"Title": title, "Rows": rows})
+	return m.sendWithAttachments(toEmail, subject, body, []Attachment{report})
+}
 
-— The PolicyFlow Team
-`, toName, magicURL)
+// SendPolicyPublished tells a user a new version of a policy awaits their
+// acknowledgement, with its changelog and a link to it.
+func (m *Mailer) SendPolicyPublished(toEmail, toName, policyTitle, version, changelog, policyURL string) error {
+	subject, body := m.render(TemplatePolicyPublished, map[string]any{
+		"Name": toName, "Policy": policyTitle, "Version": version, "Changelog": changelog, "URL": policyURL,
+	})
+	return m.send(toEmail, subject, body)
+}
 
+// SendAckReceipt confirms to a user that they acknowledged a policy
+// version, with the signature hash and a link to their certificate.
+func (m *Mailer) SendAckReceipt(toEmail, toName, policyTitle, version string, at time.Time, signature, certificateURL string) error {
+	subject, body := m.render(TemplateAckReceipt, map[string]any{
+		"Name": toName, "Policy": policyTitle, "Version": version, "Time": at.UTC().Format("Mon 2 Jan 2006 15:04 MST"),
+		"Signature": signature, "URL": certificateURL,
+	})
 	return m.send(toEmail, subject, body)
 }
 
+// Attachment is a file attached to an outgoing email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
 func (m *Mailer) send(to, subject, body string) error {
+	return m.sendWithAttachments(to, subject, body, nil)
+}
+
+func (m *Mailer) sendWithAttachments(to, subject, body string, attachments []Attachment) error {
+	names := make([]string, len(attachments))
+	for i, a := range attachments {
+		names[i] = a.Filename
+	}
+	if m.outbox != nil {
+		m.outbox.add(Message{To: to, Subject: subject, Body: body, Attachments: names})
+		if m.batch != nil {
+			m.batch.sent++
+		}
+		return nil
+	}
 	if m.devMode || m.host == "" {
-		log.Printf("📧 EMAIL (dev mode — not sent)\nTo: %s\nSubject: %s\nBody:\n%s", to, subject, body)
+		log.Printf("📧 EMAIL (dev mode — not sent)\nTo: %s\nSubject: %s\nAttachments: %v\nBody:\n%s", to, subject, names, body)
 		return nil
 	}
 
-	addr := fmt.Sprintf("%s:%d", m.host, m.port)
-	msg := strings.Join([]string{
+	msg, err := m.buildMessage(to, subject, body, attachments)
+	if err != nil {
+		return err
+	}
+	if m.dkim != nil {
+		if msg, err = m.dkim.sign(msg, time.Now()); err != nil {
+			return err
+		}
+	}
+	if m.batch != nil {
+		return m.batch.send(to, msg)
+	}
+
+	client, err := m.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Quit()
+	return m.deliver(client, to, msg)
+}
+
+// buildMessage renders the RFC 5322 message: plain text when there are no
+// attachments, multipart/mixed otherwise.
+func (m *Mailer) buildMessage(to, subject, body string, attachments []Attachment) (string, error) {
+	headers := []string{
 		fmt.Sprintf("From: PolicyFlow <%s>", m.from),
 		fmt.Sprintf("To: %s", to),
 		fmt.Sprintf("Subject: %s", subject),
 		"MIME-Version: 1.0",
-		"Content-Type: text/plain; charset=utf-8",
-		"",
-		body,
-	}, "\r\n")
-
-	var auth smtp.Auth
-	if m.username != "" && m.password != "" {
-		auth = smtp.PlainAuth("", m.username, m.password, m.host)
 	}
-
-	if m.useTLS {
-		return m.sendImplicitTLS(addr, auth, to, msg)
+	if len(attachments) == 0 {
+		return strings.Join(append(headers, "Content-Type: text/plain; charset=utf-8", "", body), "\r\n"), nil
 	}
-	return m.sendSTARTTLS(addr, auth, to, msg)
-}
 
-// sendSTARTTLS uses the standard smtp.SendMail which negotiates STARTTLS (port 587).
-func (m *Mailer) sendSTARTTLS(addr string, auth smtp.Auth, to, msg string) error {
-	log.Printf("SMTP: connecting to %s (STARTTLS)…", addr)
-	if err := smtp.SendMail(addr, auth, m.from, []string{to}, []byte(msg)); err != nil {
-		return fmt.Errorf("smtp send (STARTTLS): %w", err)
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return "", err
 	}
-	log.Printf("SMTP: sent to %s", to)
-	return nil
+	part.Write([]byte(body))
+	for _, a := range attachments {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf(`attachment; filename="%s"`, a.Filename)},
+		})
+		if err != nil {
+			return "", err
+		}
+		enc := base64.StdEncoding.EncodeToString(a.Data)
+		for len(enc) > 76 {
+			part.Write([]byte(enc[:76] + "\r\n"))
+			enc = enc[76:]
+		}
+		part.Write([]byte(enc + "\r\n"))
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	headers = append(headers, fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s", mw.Boundary()), "")
+	return strings.Join(headers, "\r\n") + "\r\n" + buf.String(), nil
 }
 
-// sendImplicitTLS connects with immediate TLS (port 465).
-func (m *Mailer) sendImplicitTLS(addr string, auth smtp.Auth, to, msg string) error {
-	log.Printf("SMTP: connecting to %s (implicit TLS)…", addr)
-	tlsConfig := &tls.Config{ServerName: m.host}
-	conn, err := tls.Dial("tcp", addr, tlsConfig)
+// ErrNotConfigured is returned by Check when emails are logged rather than
+// sent: SMTP_HOST is unset or DEV_EMAIL_MODE is on.
+var ErrNotConfigured = errors.New("SMTP is not configured; emails are logged, not sent")
+
+// Check connects and authenticates to the SMTP server without sending
+// anything, for the self-check.
+func (m *Mailer) Check() error {
+	if m.outbox != nil || m.devMode || m.host == "" {
+		return ErrNotConfigured
+	}
+	client, err := m.dial()
 	if err != nil {
-		return fmt.Errorf("smtp tls dial: %w", err)
+		return err
 	}
+	return client.Quit()
+}
 
-	client, err := smtp.NewClient(conn, m.host)
-	if err != nil {
-		return fmt.Errorf("smtp client: %w", err)
+// dial connects and authenticates to the SMTP server, with implicit TLS
+// (port 465) when useTLS is set and STARTTLS (port 587) when the server
+// offers it otherwise.
+func (m *Mailer) dial() (*smtp.Client, error) {
+	addr := fmt.Sprintf("%s:%d", m.host, m.port)
+	var client *smtp.Client
+	if m.useTLS {
+		log.Printf("SMTP: connecting to %s (implicit TLS)…", addr)
+		conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: m.host})
+		if err != nil {
+			return nil, fmt.Errorf("smtp tls dial: %w", err)
+		}
+		if client, err = smtp.NewClient(conn, m.host); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("smtp client: %w", err)
+		}
+	} else {
+		log.Printf("SMTP: connecting to %s (STARTTLS)…", addr)
+		var err error
+		if client, err = smtp.Dial(addr); err != nil {
+			return nil, fmt.Errorf("smtp dial: %w", err)
+		}
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("smtp STARTTLS: %w", err)
+			}
+		}
 	}
-	defer client.Quit()
 
-	if auth != nil {
+	if m.username != "" && m.password != "" {
 		log.Printf("SMTP: authenticating as %s…", m.username)
-		if err := client.Auth(auth); err != nil {
-			return fmt.Errorf("smtp auth: %w", err)
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp auth: %w", err)
 		}
 	}
+	return client, nil
+}
 
+// deliver sends one message over an open connection.
+func (m *Mailer) deliver(client *smtp.Client, to, msg string) error {
 	if err := client.Mail(m.from); err != nil {
 		return fmt.Errorf("smtp MAIL FROM: %w", err)
 	}
@@ -159,3 +368,15 @@ func (m *Mailer) sendImplicitTLS(addr string, auth smtp.Auth, to, msg string) er
 	log.Printf("SMTP: sent to %s", to)
 	return nil
 }
+
+// humanDuration renders link lifetimes such as "24 hours" or "15 minutes".
+func humanDuration(d time.Duration) string {
+	n, unit := int(d/time.Minute), "minute"
+	if d >= time.Hour && d%time.Hour == 0 {
+		n, unit = int(d/time.Hour), "hour"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
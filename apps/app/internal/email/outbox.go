@@ -0,0 +1,49 @@
+package email
+
+import "sync"
+
+// Message is an email as the Mailer would have sent it.
+type Message struct {
+	To          string
+	Subject     string
+	Body        string
+	Attachments []string // file names
+}
+
+// Outbox records the messages of a Mailer in capture mode.
+type Outbox struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+// Capture switches m to recording messages in the returned Outbox instead
+// of sending or logging them, for tests that need to read what was sent.
+func (m *Mailer) Capture() *Outbox {
+	m.outbox = &Outbox{}
+	return m.outbox
+}
+
+func (o *Outbox) add(msg Message) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.msgs = append(o.msgs, msg)
+}
+
+// Messages returns the messages recorded so far, oldest first.
+func (o *Outbox) Messages() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Message(nil), o.msgs...)
+}
+
+// Last returns the latest message to the address, if any.
+func (o *Outbox) Last(to string) (Message, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.msgs) - 1; i >= 0; i-- {
+		if o.msgs[i].To == to {
+			return o.msgs[i], true
+		}
+	}
+	return Message{}, false
+}
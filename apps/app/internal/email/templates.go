@@ -0,0 +1,309 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Template names.
+const (
+	TemplateMagicLink         = "magic_link"
+	TemplateLoginCode         = "login_code"
+	TemplateWelcome           = "welcome"
+	TemplateNewDeviceLogin    = "new_device_login"
+	TemplateDeadlineReminder  = "deadline_reminder"
+	TemplateExceptionRequest  = "exception_request"
+	TemplateExceptionDecision = "exception_decision"
+	TemplateScheduledReport   = "scheduled_report"
+	TemplatePolicyPublished   = "policy_published"
+	TemplateAckReceipt        = "ack_receipt"
+	TemplateDepartmentWelcome = "department_welcome"
+	TemplateCampaignReminder  = "campaign_reminder"
+)
+
+// Template is an email's subject and plain-text body, written as Go
+// templates over the email's variables: "Hi {{.Name}},".
+type Template struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Overrides supplies the organization's edited templates, which replace the
+// built-in defaults.
+type Overrides interface {
+	EmailTemplateOverride(ctx context.Context, name string) (subject, body string, ok bool, err error)
+}
+
+// defaultTemplate is a built-in template with example values for each of
+// its variables, used for previews and to validate edits.
+type defaultTemplate struct {
+	Template
+	sample map[string]any
+}
+
+var defaultTemplates = map[string]defaultTemplate{
+	TemplateMagicLink: {
+		Template{TemplateMagicLink, "PolicyFlow — Your login link", `Hi {{.Name}},
+
+Click the link below to log in to PolicyFlow. This link is valid for {{.ValidFor}}.
+
+{{.URL}}
+
+If you did not request this, you can safely ignore this email.
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "URL": "https://policyflow.example.com/api/magic-login?token=…", "ValidFor": "15 minutes"},
+	},
+	TemplateLoginCode: {
+		Template{TemplateLoginCode, "PolicyFlow — Your login code", `Hi {{.Name}},
+
+Your PolicyFlow login code is:
+
+    {{.Code}}
+
+Enter it on the sign-in page. It is valid for {{.ValidFor}} and works once.
+
+If you did not request this, you can safely ignore this email.
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "Code": "042917", "ValidFor": "10 minutes"},
+	},
+	TemplateWelcome: {
+		Template{TemplateWelcome, "Welcome to PolicyFlow", `Hi {{.Name}},
+
+An account has been created for you on PolicyFlow, your company's policy management system.
+
+Click the link below to log in for the first time. This link is valid for {{.ValidFor}}.
+
+{{.URL}}
+
+After logging in, you can view and acknowledge company policies.
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "URL": "https://policyflow.example.com/api/magic-login?token=…", "ValidFor": "24 hours"},
+	},
+	TemplateNewDeviceLogin: {
+		Template{TemplateNewDeviceLogin, "PolicyFlow — New sign-in to your account", `Hi {{.Name}},
+
+Your PolicyFlow account was just signed in to from a new device:
+
+  Time:    {{.Time}}
+  IP:      {{.IP}}
+  Browser: {{.Browser}}
+
+If this was you, there's nothing to do. If not, contact your PolicyFlow administrator.
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "Time": "Mon 2 Jan 2006 15:04 UTC", "IP": "203.0.113.7", "Browser": "Mozilla/5.0 (Macintosh) Firefox/130.0"},
+	},
+	TemplateDeadlineReminder: {
+		Template{TemplateDeadlineReminder, "PolicyFlow — Policies awaiting your acknowledgement", `Hi {{.Name}},
+
+The following policies are awaiting your acknowledgement:
+
+{{.Policies}}
+The attached calendar file adds these deadlines to your calendar.
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "Policies": "• Code of Conduct — due Fri 6 Jan 2006\n  https://policyflow.example.com/policies?id=…\n"},
+	},
+	TemplateExceptionRequest: {
+		Template{TemplateExceptionRequest, "PolicyFlow — Exception requested for {{.Policy}}", `Hi {{.Name}},
+
+{{.Requester}} has requested a {{.Days}}-day exception to "{{.Policy}}":
+
+{{.Justification}}
+
+Review the request here:
+
+{{.URL}}
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Sam Owner", "Requester": "Jane Doe", "Policy": "Remote Work", "Days": 30,
+			"Justification": "I am travelling for a client project.", "URL": "https://policyflow.example.com/admin/exceptions?id=…"},
+	},
+	TemplateExceptionDecision: {
+		Template{TemplateExceptionDecision, "PolicyFlow — Exception {{.Decision}} for {{.Policy}}", `Hi {{.Name}},
+
+Your exception request for "{{.Policy}}" has been {{.Outcome}}.
+{{if .Note}}
+Note from the reviewer:
+
+{{.Note}}
+{{end}}
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "Policy": "Remote Work", "Decision": "approved",
+			"Outcome": "approved until Fri 3 Feb 2006", "Note": "Please check in when you are back."},
+	},
+	TemplateScheduledReport: {
+		Template{TemplateScheduledReport, "PolicyFlow — {{.Title}}", `Hello,
+
+Your scheduled PolicyFlow report "{{.Title}}" is attached ({{.Rows}} rows).
+
+You receive this because an administrator added you to the report's recipients.
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Title": "Weekly compliance — 2 Jan 2006", "Rows": 42},
+	},
+	TemplatePolicyPublished: {
+		Template{TemplatePolicyPublished, "PolicyFlow — {{.Policy}} {{.Version}} published", `Hi {{.Name}},
+
+Version {{.Version}} of "{{.Policy}}" has been published and is awaiting your acknowledgement.
+{{if .Changelog}}
+What changed:
+
+{{.Changelog}}
+{{end}}
+Read and acknowledge it here:
+
+{{.URL}}
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "Policy": "Remote Work", "Version": "v2.0",
+			"Changelog": "Added a section on working from abroad.", "URL": "https://policyflow.example.com/policies?id=…"},
+	},
+	TemplateAckReceipt: {
+		Template{TemplateAckReceipt, "PolicyFlow — You acknowledged {{.Policy}} {{.Version}}", `Hi {{.Name}},
+
+This confirms that you acknowledged "{{.Policy}}" version {{.Version}} on {{.Time}}.
+
+  Signature: {{.Signature}}
+
+Keep this email for your records. Your certificate of acknowledgement is available here:
+
+{{.URL}}
+
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "Policy": "Remote Work", "Version": "v2.0", "Time": "Mon 2 Jan 2006 15:04 UTC",
+			"Signature": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", "URL": "https://policyflow.example.com/certificates/…"},
+	},
+	TemplateDepartmentWelcome: {
+		Template{TemplateDepartmentWelcome, "PolicyFlow — Welcome to {{.Department}}: policies awaiting your acknowledgement", `Hi {{.Name}},
+
+You have joined {{.Department}}. The following policies apply to you and are awaiting your acknowledgement:
+
+{{.Policies}}
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "Department": "Engineering",
+			"Policies": "• On-call — due Fri 6 Jan 2006\n  https://policyflow.example.com/policies?id=…\n• Code Review\n  https://policyflow.example.com/policies?id=…\n"},
+	},
+	TemplateCampaignReminder: {
+		Template{TemplateCampaignReminder, "PolicyFlow — {{.Campaign}}: please acknowledge by {{.Deadline}}", `Hi {{.Name}},
+
+{{.Campaign}} runs until {{.Deadline}}.{{if .Description}}
+
+{{.Description}}{{end}}
+
+The following policies are still awaiting your acknowledgement:
+
+{{.Policies}}
+— The PolicyFlow Team
+`},
+		map[string]any{"Name": "Jane Doe", "Campaign": "2006 Annual Policy Refresh", "Deadline": "Tue 31 Jan 2006",
+			"Description": "Each year everyone re-reads our core policies.",
+			"Policies":    "• Code of Conduct\n  https://policyflow.example.com/policies?id=…\n• Information Security\n  https://policyflow.example.com/policies?id=…\n"},
+	},
+}
+
+// Defaults returns the built-in templates, sorted by name.
+func Defaults() []Template {
+	out := make([]Template, 0, len(defaultTemplates))
+	for _, name := range slices.Sorted(maps.Keys(defaultTemplates)) {
+		out = append(out, defaultTemplates[name].Template)
+	}
+	return out
+}
+
+// Default returns the built-in template called name.
+func Default(name string) (Template, bool) {
+	d, ok := defaultTemplates[name]
+	return d.Template, ok
+}
+
+// Variables lists the placeholders template name can use.
+func Variables(name string) []string {
+	return slices.Sorted(maps.Keys(defaultTemplates[name].sample))
+}
+
+// Preview renders t with its example values. An error means the template
+// does not parse or uses a variable it does not have.
+func Preview(t Template) (subject, body string, err error) {
+	d, ok := defaultTemplates[t.Name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown template %q", t.Name)
+	}
+	return t.render(d.sample)
+}
+
+// render executes the subject and body with data. Line breaks in the
+// subject are flattened so a value cannot add headers.
+func (t Template) render(data map[string]any) (subject, body string, err error) {
+	exec := func(part, src string) (string, error) {
+		tmpl, err := template.New(t.Name).Option("missingkey=error").Parse(src)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", part, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("%s: %w", part, err)
+		}
+		return buf.String(), nil
+	}
+	if subject, err = exec("subject", t.Subject); err != nil {
+		return "", "", err
+	}
+	if body, err = exec("body", t.Body); err != nil {
+		return "", "", err
+	}
+	return strings.Join(strings.Fields(subject), " "), body, nil
+}
+
+// SetOverrides makes the mailer use the organization's edited templates.
+func (m *Mailer) SetOverrides(o Overrides) {
+	m.overrides = o
+}
+
+// render fills in template name, using the organization's edit when there
+// is one. A broken edit is logged and the default used, so that sign-in
+// links keep going out.
+func (m *Mailer) render(name string, data map[string]any) (subject, body string) {
+	d := defaultTemplates[name]
+	if m.overrides != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s, b, ok, err := m.overrides.EmailTemplateOverride(ctx, name)
+		switch {
+		case err != nil:
+			log.Printf("email template %s: %v; using the default", name, err)
+		case ok:
+			if subject, body, err = (Template{name, s, b}).render(data); err == nil {
+				return subject, body
+			}
+			log.Printf("email template %s: %v; using the default", name, err)
+		}
+	}
+	subject, body, err := d.render(data)
+	if err != nil {
+		panic(fmt.Sprintf("email: default template %s: %v", name, err))
+	}
+	return subject, body
+}
@@ -0,0 +1,13 @@
+package email
+
+import "testing"
+
+// TestDefaults_Render guards the built-in templates: Mailer.render panics
+// if one cannot be rendered with the variables its Send method passes.
+func TestDefaults_Render(t *testing.T) {
+	for _, tmpl := range Defaults() {
+		if _, _, err := Preview(tmpl); err != nil {
+			t.Errorf("%s: %v", tmpl.Name, err)
+		}
+	}
+}
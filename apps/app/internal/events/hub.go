@@ -0,0 +1,130 @@
+// Package events fans out domain events (publishes, acknowledgements,
+// notifications) to connected clients in real time.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	PolicyPublished    = "policy.published"
+	PolicyAcknowledged = "policy.acknowledged"
+	Notification       = "notification"
+)
+
+// Event is one message delivered to subscribers.
+type Event struct {
+	ID       uint64    `json:"id"`
+	Type     string    `json:"type"`
+	Data     any       `json:"data"`
+	At       time.Time `json:"at"`
+	Audience Audience  `json:"-"`
+}
+
+// Audience restricts which subscribers receive an event. The zero value
+// means everyone.
+type Audience struct {
+	// UserIDs, when set, delivers only to these users.
+	UserIDs []string
+	// DepartmentID limits delivery to that department; SuperAdmins always
+	// receive the event.
+	DepartmentID *string
+	// AdminsOnly excludes Staff.
+	AdminsOnly bool
+}
+
+// Subscriber is one connected client.
+type Subscriber struct {
+	UserID string
+	Role   string
+	DeptID *string
+	C      chan Event
+}
+
+func (s *Subscriber) wants(a Audience) bool {
+	if len(a.UserIDs) > 0 {
+		for _, id := range a.UserIDs {
+			if id == s.UserID {
+				return true
+			}
+		}
+		return false
+	}
+	if a.AdminsOnly && s.Role != "SuperAdmin" && s.Role != "DeptAdmin" {
+		return false
+	}
+	if a.DepartmentID != nil && s.Role != "SuperAdmin" {
+		return s.DeptID != nil && *s.DeptID == *a.DepartmentID
+	}
+	return true
+}
+
+// Hub delivers published events to matching subscribers. A nil *Hub
+// discards events, so handlers work without one.
+type Hub struct {
+	mu   sync.Mutex
+	seq  uint64
+	subs map[*Subscriber]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[*Subscriber]struct{}{}}
+}
+
+// Subscribe registers a client. Callers must Unsubscribe when it goes away.
+func (h *Hub) Subscribe(userID, role string, deptID *string) *Subscriber {
+	s := &Subscriber{UserID: userID, Role: role, DeptID: deptID, C: make(chan Event, 32)}
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+}
+
+// ConnectedUsers counts the distinct users with a client connected, in
+// deptID when it is set.
+func (h *Hub) ConnectedUsers(deptID *string) int {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	users := map[string]bool{}
+	for s := range h.subs {
+		if deptID == nil || (s.DeptID != nil && *s.DeptID == *deptID) {
+			users[s.UserID] = true
+		}
+	}
+	return len(users)
+}
+
+// Publish sends e to every subscriber in its audience. It never blocks: a
+// client too slow to drain its buffer misses events and should refetch.
+func (h *Hub) Publish(e Event) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	e.ID = h.seq
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	for s := range h.subs {
+		if !s.wants(e.Audience) {
+			continue
+		}
+		select {
+		case s.C <- e:
+		default:
+		}
+	}
+}
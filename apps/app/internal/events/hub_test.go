@@ -0,0 +1,56 @@
+package events
+
+import "testing"
+
+func received(s *Subscriber) []string {
+	var got []string
+	for {
+		select {
+		case e := <-s.C:
+			got = append(got, e.Type)
+		default:
+			return got
+		}
+	}
+}
+
+// TestHub_Audience verifies department, admin-only, and per-user events
+// reach exactly the subscribers they are meant for.
+func TestHub_Audience(t *testing.T) {
+	eng, hr := "eng", "hr"
+	h := NewHub()
+	super := h.Subscribe("s", "SuperAdmin", nil)
+	engAdmin := h.Subscribe("a", "DeptAdmin", &eng)
+	engStaff := h.Subscribe("b", "Staff", &eng)
+	hrStaff := h.Subscribe("c", "Staff", &hr)
+
+	h.Publish(Event{Type: "org"})
+	h.Publish(Event{Type: "eng", Audience: Audience{DepartmentID: &eng}})
+	h.Publish(Event{Type: "eng-admins", Audience: Audience{AdminsOnly: true, DepartmentID: &eng}})
+	h.Publish(Event{Type: "for-c", Audience: Audience{UserIDs: []string{"c"}}})
+
+	want := map[*Subscriber]string{
+		super:    "org,eng,eng-admins",
+		engAdmin: "org,eng,eng-admins",
+		engStaff: "org,eng",
+		hrStaff:  "org,for-c",
+	}
+	for s, w := range want {
+		got := ""
+		for i, typ := range received(s) {
+			if i > 0 {
+				got += ","
+			}
+			got += typ
+		}
+		if got != w {
+			t.Errorf("%s received %q; want %q", s.UserID, got, w)
+		}
+	}
+
+	h.Unsubscribe(hrStaff)
+	h.Publish(Event{Type: "after"})
+	if got := received(hrStaff); len(got) != 0 {
+		t.Errorf("unsubscribed client received %v", got)
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// maxAckImportErrors caps the row errors reported for a rejected file.
+const maxAckImportErrors = 100
+
+// ackImportColumns are the columns an acknowledgement import needs, in
+// any order.
+var ackImportColumns = []string{"email", "policy", "version", "date"}
+
+// ackImportError is a row that could not be imported. Line counts the
+// header as line 1, as spreadsheets do.
+type ackImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ackImportResult counts the rows of an import. Skipped rows were already
+// acknowledged, in PolicyFlow or earlier in the file.
+type ackImportResult struct {
+	Rows     int  `json:"rows"`
+	Imported int  `json:"imported"`
+	Skipped  int  `json:"skipped"`
+	DryRun   bool `json:"dry_run"`
+}
+
+// ImportAcknowledgements records acknowledgements given on paper or kept
+// in a spreadsheet before PolicyFlow. The CSV has a header row with email,
+// policy (ID or exact title), version (its version string), and date
+// (YYYY-MM-DD or RFC3339). Every row is checked first: if any is invalid,
+// nothing is imported and the errors are returned. Imported
+// acknowledgements have source "imported". ?dry_run=true only validates.
+// POST /api/admin/acknowledgements/import  (SuperAdmin only; multipart: file or upload_id)
+func (h *Policy) ImportAcknowledgements(c echo.Context) error {
+	ctx := c.Request().Context()
+	dryRun := c.QueryParam("dry_run") == "true"
+	f, err := h.incomingFile(c, maxImportBytes)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return apierr.New(http.StatusBadRequest, "INVALID_CSV", "file has no header row")
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range ackImportColumns {
+		if _, ok := col[name]; !ok {
+			return apierr.New(http.StatusBadRequest, "INVALID_CSV", fmt.Sprintf("missing column %q; need %s", name, strings.Join(ackImportColumns, ", ")))
+		}
+	}
+	field := func(rec []string, name string) string {
+		if i := col[name]; i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	resolve, err := h.ackImportResolver(c)
+	if err != nil {
+		return err
+	}
+	var (
+		acks    []database.ImportedAck
+		rowErrs []ackImportError
+		seen    = map[[2]string]bool{}
+		result  = ackImportResult{DryRun: dryRun}
+	)
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, ackImportError{line, "not valid CSV"})
+			break
+		}
+		result.Rows++
+		ack, msg := resolve(field(rec, "email"), field(rec, "policy"), field(rec, "version"), field(rec, "date"))
+		if msg != "" {
+			rowErrs = append(rowErrs, ackImportError{line, msg})
+			continue
+		}
+		if key := [2]string{ack.UserID, ack.PolicyVersionID}; !seen[key] {
+			seen[key] = true
+			acks = append(acks, ack)
+		}
+	}
+	if len(rowErrs) > 0 {
+		err := apierr.New(http.StatusBadRequest, "IMPORT_INVALID", fmt.Sprintf("%d row(s) have errors; nothing was imported", len(rowErrs)))
+		return apierr.With(err, "errors", rowErrs[:min(len(rowErrs), maxAckImportErrors)])
+	}
+	if result.Rows == 0 {
+		return apierr.New(http.StatusBadRequest, "INVALID_CSV", "file has no rows")
+	}
+
+	if result.Imported, err = h.db.ImportAcknowledgements(ctx, acks, dryRun); err != nil {
+		return apierr.Database()
+	}
+	result.Skipped = result.Rows - result.Imported
+	if !dryRun {
+		mw.LogAudit(c, h.db, database.ActionAcknowledgementsImported, "acknowledgements", "",
+			fmt.Sprintf("%d imported, %d skipped from %s", result.Imported, result.Skipped, f.Filename))
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ackImportResolver returns a function that turns a CSV row into an
+// acknowledgement, or explains why it cannot. Users, policies, and
+// versions are looked up once each.
+func (h *Policy) ackImportResolver(c echo.Context) (func(email, policy, version, date string) (database.ImportedAck, string), error) {
+	ctx := c.Request().Context()
+	policies, err := h.db.ListPolicies(ctx)
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	byID := map[string]*database.Policy{}
+	byTitle := map[string][]*database.Policy{}
+	for _, p := range policies {
+		byID[p.ID] = p
+		t := strings.ToLower(p.Title)
+		byTitle[t] = append(byTitle[t], p)
+	}
+	users := map[string]*database.User{}
+	versions := map[string][]*database.PolicyVersion{}
+	now := time.Now()
+
+	return func(email, policy, version, date string) (database.ImportedAck, string) {
+		var ack database.ImportedAck
+		if email == "" || policy == "" || version == "" || date == "" {
+			return ack, "email, policy, version, and date are all required"
+		}
+		u, ok := users[email]
+		if !ok {
+			u, err = h.db.GetUserByEmail(ctx, email)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return ack, "could not look up the user"
+			}
+			users[email] = u
+		}
+		if u == nil {
+			return ack, fmt.Sprintf("no user with email %s", email)
+		}
+
+		p := byID[policy]
+		if p == nil {
+			switch matches := byTitle[strings.ToLower(policy)]; len(matches) {
+			case 0:
+				return ack, fmt.Sprintf("no policy %q", policy)
+			case 1:
+				p = matches[0]
+			default:
+				return ack, fmt.Sprintf("%d policies are titled %q; use the policy ID", len(matches), policy)
+			}
+		}
+		vs, ok := versions[p.ID]
+		if !ok {
+			if vs, err = h.db.ListPolicyVersions(ctx, p.ID); err != nil {
+				return ack, "could not look up the policy's versions"
+			}
+			versions[p.ID] = vs
+		}
+		for _, v := range vs {
+			if v.VersionString == version {
+				ack.PolicyVersionID = v.ID
+			}
+		}
+		if ack.PolicyVersionID == "" {
+			return ack, fmt.Sprintf("%q has no version %s", p.Title, version)
+		}
+
+		ts, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			if ts, err = time.Parse(time.RFC3339, date); err != nil {
+				return ack, "date must be YYYY-MM-DD or RFC3339"
+			}
+		}
+		if ts.After(now) {
+			return ack, "date is in the future"
+		}
+		ack.UserID, ack.Timestamp = u.ID, ts
+		return ack, ""
+	}, nil
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestImportAcknowledgements_AllOrNothing checks a file with a bad row
+// imports nothing, and a valid one imports with the imported source while
+// skipping acknowledgements that already exist.
+func TestImportAcknowledgements_AllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ana, _ := db.CreateUser(ctx, "ana@example.com", "Ana", mw.RoleStaff, nil, nil)
+	ben, _ := db.CreateUser(ctx, "ben@example.com", "Ben", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	v1, _ := db.ListPolicyVersions(ctx, p.ID)
+	if _, err := db.CreateAcknowledgement(ctx, ben.ID, v1[0].ID); err != nil {
+		t.Fatalf("CreateAcknowledgement: %v", err)
+	}
+	e := echo.New()
+	h := NewPolicy(db)
+	upload := func(csv, query string) (*httptest.ResponseRecorder, error) {
+		var buf bytes.Buffer
+		mp := multipart.NewWriter(&buf)
+		fw, _ := mp.CreateFormFile("file", "paper-acks.csv")
+		fw.Write([]byte(csv))
+		mp.Close()
+		req := httptest.NewRequest(http.MethodPost, "/?"+query, &buf)
+		req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(mw.CtxUserRole, mw.RoleSuperAdmin)
+		c.Set(mw.CtxUserID, testutil.UserID)
+		return rec, h.ImportAcknowledgements(c)
+	}
+
+	_, err := upload("Email,Policy,Version,Date\n"+
+		"ana@example.com,Code of Conduct,v1.0.0,2023-04-01\n"+
+		"nobody@example.com,Code of Conduct,v1.0.0,2023-04-01\n"+
+		"ana@example.com,Code of Conduct,v9,2023-04-01\n", "")
+	var he *echo.HTTPError
+	if !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Fatalf("import with bad rows = %v; want 400", err)
+	}
+	if acks, _ := db.ListUserAcknowledgements(ctx, ana.ID); len(acks) != 0 {
+		t.Fatalf("a rejected file imported %d acknowledgements", len(acks))
+	}
+
+	valid := "email,policy,version,date\n" +
+		"ana@example.com,code of conduct,v1.0.0,2023-04-01\n" +
+		"ana@example.com," + p.ID + ",v1.0.0,2023-04-02\n" +
+		"ben@example.com,Code of Conduct,v1.0.0,2023-04-01\n"
+	for _, dryRun := range []bool{true, false} {
+		query := ""
+		if dryRun {
+			query = "dry_run=true"
+		}
+		rec, err := upload(valid, query)
+		if err != nil {
+			t.Fatalf("import (dry run %t): %v", dryRun, err)
+		}
+		var res ackImportResult
+		json.Unmarshal(rec.Body.Bytes(), &res)
+		if res.Rows != 3 || res.Imported != 1 || res.Skipped != 2 || res.DryRun != dryRun {
+			t.Errorf("import (dry run %t) = %+v; want 3 rows, 1 imported, 2 skipped", dryRun, res)
+		}
+	}
+	acks, _ := db.ListAcknowledgements(ctx, v1[0].ID)
+	sources := map[string]string{}
+	for _, a := range acks {
+		sources[a.UserID] = a.Source
+	}
+	if sources[ana.ID] != database.AckSourceImported || sources[ben.ID] != database.AckSourceApp {
+		t.Errorf("sources = %v; want ana imported, ben app", sources)
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// Acknowledgements lists who acknowledged a version of the policy, with
+// each user's name, email, and department, newest first, one page at a
+// time. version_id defaults to the current version. Pass the returned
+// next_cursor as ?cursor= to fetch the following page. ?format=csv instead
+// streams every acknowledgement of the version as one CSV download.
+// GET /api/policies/:id/acknowledgements?version_id=&cursor=&limit=&format=json|csv
+func (h *Policy) Acknowledgements(c echo.Context) error {
+	cursor, limit, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return apierr.Invalid("format must be json or csv", "format")
+	}
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+
+	versionID := c.QueryParam("version_id")
+	if versionID == "" {
+		if policy.CurrentVersionID == nil {
+			if format == "csv" {
+				return writeCSV(c, "acknowledgements.csv", [][]string{ackExportHeader})
+			}
+			return c.JSON(http.StatusOK, &database.AckDetailPage{Items: []*database.AckDetail{}})
+		}
+		versionID = *policy.CurrentVersionID
+	} else {
+		v, err := h.db.GetPolicyVersion(ctx, versionID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && v.PolicyID != policy.ID) {
+			return apierr.New(http.StatusNotFound, "VERSION_NOT_FOUND", "version not found")
+		}
+		if err != nil {
+			return apierr.Database()
+		}
+	}
+
+	if format == "csv" {
+		return h.exportAcknowledgements(c, policy, versionID)
+	}
+	page, err := h.db.Reader().ListAcknowledgementDetailsPage(ctx, versionID, cursor, limit)
+	return ackPageResponse(c, page, err)
+}
+
+var ackExportHeader = []string{"policy", "version", "user", "email", "department", "acknowledged_at", "signature_hash", "source"}
+
+// exportAcknowledgements streams a version's acknowledgements as CSV, row
+// by row as they are read.
+func (h *Policy) exportAcknowledgements(c echo.Context, policy *database.Policy, versionID string) error {
+	v, err := h.db.GetPolicyVersion(c.Request().Context(), versionID)
+	if err != nil {
+		return apierr.Database()
+	}
+	out, err := streamCSV(c, "acknowledgements.csv", ackExportHeader)
+	if err != nil {
+		return err
+	}
+	err = h.db.Reader().EachAcknowledgementDetail(c.Request().Context(), versionID, func(a *database.AckDetail) error {
+		return out.Write([]string{policy.Title, v.VersionString, a.UserName, a.UserEmail, deref(a.DepartmentName),
+			a.Timestamp.UTC().Format(time.RFC3339), a.SignatureHash, a.Source})
+	})
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// Acknowledgements lists a user's acknowledgements, newest first, one page
+// at a time. DeptAdmin may only look at users in their own department.
+// GET /api/admin/users/:id/acknowledgements?cursor=&limit=
+func (h *User) Acknowledgements(c echo.Context) error {
+	cursor, limit, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	u, err := h.db.GetUserByID(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+		}
+		return apierr.Database()
+	}
+	if c.Get(mw.CtxUserRole) == mw.RoleDeptAdmin {
+		deptID, _ := c.Get(mw.CtxDeptID).(*string)
+		if !sameDept(deptID, u.DepartmentID) {
+			return apierr.New(http.StatusForbidden, "OUTSIDE_DEPARTMENT", "cannot view users outside your department")
+		}
+	}
+
+	page, err := h.db.Reader().ListUserAcknowledgementsPage(ctx, u.ID, cursor, limit)
+	return ackPageResponse(c, page, err)
+}
+
+// pageParams reads ?cursor= and ?limit= for acknowledgement listings.
+func pageParams(c echo.Context) (string, int, error) {
+	limit := database.DefaultAckPageSize
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > database.MaxAckPageSize {
+			return "", 0, apierr.Invalid("limit must be between 1 and "+strconv.Itoa(database.MaxAckPageSize), "limit")
+		}
+		limit = n
+	}
+	return c.QueryParam("cursor"), limit, nil
+}
+
+func ackPageResponse(c echo.Context, page any, err error) error {
+	if errors.Is(err, database.ErrInvalidCursor) {
+		return apierr.Invalid("invalid cursor", "cursor")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// VersionStats reports each version's acknowledgements by month and its
+// compliance: live for the current version, as it stood when superseded for
+// older ones, so that audits can show historical compliance.
+// GET /api/policies/:id/versions/stats
+func (h *Policy) VersionStats(c echo.Context) error {
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	stats, err := h.db.Reader().ListVersionAckStats(c.Request().Context(), policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if stats == nil {
+		stats = []*database.VersionAckStats{}
+	}
+	return c.JSON(http.StatusOK, stats)
+}
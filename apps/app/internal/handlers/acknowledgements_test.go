@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestAcknowledgements_Pages verifies that paging through a version's
+// acknowledgements returns each one exactly once with its user's details,
+// even when they share a timestamp.
+func TestAcknowledgements_Pages(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	p, _ := db.CreatePolicy(ctx, "Handbook", "", testutil.Ptr(eng.ID), "department", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	for i := range 5 {
+		u, _ := db.CreateUser(ctx, fmt.Sprintf("u%d@example.com", i), "U", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+		if _, err := db.CreateAcknowledgement(ctx, u.ID, *p.CurrentVersionID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := echo.New()
+	h := NewPolicy(db)
+	seen := map[string]bool{}
+	cursor, pages := "", 0
+	for {
+		c, rec := testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleDeptAdmin, testutil.Ptr(eng.ID))
+		c.Request().URL.RawQuery = "limit=2&cursor=" + cursor
+		if err := h.Acknowledgements(c); err != nil {
+			t.Fatalf("Acknowledgements: %v", err)
+		}
+		var page database.AckDetailPage
+		json.Unmarshal(rec.Body.Bytes(), &page)
+		pages++
+		for _, a := range page.Items {
+			if seen[a.ID] {
+				t.Errorf("acknowledgement %s returned twice", a.ID)
+			}
+			if a.UserEmail == "" || a.DepartmentName == nil || *a.DepartmentName != "Engineering" {
+				t.Errorf("acknowledgement %s lacks user details: %+v", a.ID, a)
+			}
+			seen[a.ID] = true
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		cursor = *page.NextCursor
+	}
+	if len(seen) != 5 || pages != 3 {
+		t.Errorf("saw %d acknowledgements in %d pages; want 5 in 3", len(seen), pages)
+	}
+
+	c, _ := testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleSuperAdmin, nil)
+	c.Request().URL.RawQuery = "cursor=garbage"
+	var he *echo.HTTPError
+	if err := h.Acknowledgements(c); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("bad cursor: err = %v; want 400", err)
+	}
+
+	other, _ := db.CreateDepartment(ctx, "Sales", "")
+	c, _ = testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleDeptAdmin, testutil.Ptr(other.ID))
+	if err := h.Acknowledgements(c); !errors.As(err, &he) || he.Code != http.StatusForbidden {
+		t.Errorf("other department: err = %v; want 403", err)
+	}
+}
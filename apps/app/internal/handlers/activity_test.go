@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+type activityPage struct {
+	Items      []database.ActivityItem `json:"items"`
+	NextBefore *string                 `json:"next_before"`
+}
+
+// TestActivity_FeedFromDomainEvents verifies that admin actions appear in
+// the activity feed with current target names, and that the feed can be
+// filtered by type and paged.
+func TestActivity_FeedFromDomainEvents(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	e := echo.New()
+	as := func(c echo.Context) echo.Context {
+		c.Set(mw.CtxUserID, admin.ID)
+		return c
+	}
+
+	depts := NewDepartments(db)
+	c, rec := testutil.NewContext(e, http.MethodPost, `{"name":"Eng"}`, "", mw.RoleSuperAdmin, nil)
+	if err := depts.Create(as(c)); err != nil {
+		t.Fatalf("create dept: %v", err)
+	}
+	var dept database.Department
+	json.Unmarshal(rec.Body.Bytes(), &dept)
+	c, _ = testutil.NewContext(e, http.MethodPut, `{"name":"Engineering"}`, dept.ID, mw.RoleSuperAdmin, nil)
+	if err := depts.Update(as(c)); err != nil {
+		t.Fatalf("update dept: %v", err)
+	}
+
+	policies := NewPolicy(db)
+	c, rec = testutil.NewContext(e, http.MethodPost, `{"title":"Travel"}`, "", mw.RoleSuperAdmin, nil)
+	if err := policies.Create(as(c)); err != nil {
+		t.Fatalf("create policy: %v", err)
+	}
+	var p database.Policy
+	json.Unmarshal(rec.Body.Bytes(), &p)
+	c, _ = testutil.NewContext(e, http.MethodPost, `{"content":"# T","version_string":"v1"}`, p.ID, mw.RoleSuperAdmin, nil)
+	if err := policies.CreateVersion(as(c)); err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	c, _ = testutil.NewContext(e, http.MethodPut, `{"status":"Published","expected_version":2}`, p.ID, mw.RoleSuperAdmin, nil)
+	if err := policies.Update(as(c)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	feed := func(query string) activityPage {
+		t.Helper()
+		c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+		c.Request().URL.RawQuery = query
+		if err := NewAudit(db).Activity(c); err != nil {
+			t.Fatalf("Activity(%s): %v", query, err)
+		}
+		var page activityPage
+		json.Unmarshal(rec.Body.Bytes(), &page)
+		return page
+	}
+
+	all := feed("")
+	want := []string{database.ActivityPolicyPublished, database.ActivityVersionCreated, database.ActivityPolicyCreated,
+		database.ActivityDepartmentUpdated, database.ActivityDepartmentCreated}
+	if len(all.Items) != len(want) {
+		t.Fatalf("feed has %d items; want %d: %+v", len(all.Items), len(want), all.Items)
+	}
+	for i, typ := range want {
+		if all.Items[i].Type != typ {
+			t.Errorf("item %d type = %s; want %s", i, all.Items[i].Type, typ)
+		}
+	}
+	if n := all.Items[4].TargetName; n == nil || *n != "Engineering" {
+		t.Errorf("department target_name = %v; want current name Engineering", n)
+	}
+
+	first := feed("limit=2")
+	if len(first.Items) != 2 || first.NextBefore == nil {
+		t.Fatalf("first page = %+v", first)
+	}
+	second := feed("limit=2&before=" + *first.NextBefore)
+	if len(second.Items) != 2 || second.Items[0].Type != database.ActivityPolicyCreated {
+		t.Errorf("second page = %+v; want to start at policy.created", second.Items)
+	}
+
+	if got := feed("type=department.created,department.updated"); len(got.Items) != 2 {
+		t.Errorf("department filter returned %d items; want 2", len(got.Items))
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// maxTimeseriesRange bounds a single timeseries request.
+const maxTimeseriesRange = 2 * 366 * 24 * time.Hour
+
+// Timeseries returns acknowledgement and publish counts per day or week over a
+// date range, optionally split by department. Periods without activity are
+// zero-filled so the result can be charted directly. DeptAdmin sees only
+// their own department.
+// GET /api/admin/stats/timeseries?from=YYYY-MM-DD&to=YYYY-MM-DD&interval=day|week&group_by=department
+func (h *Policy) Timeseries(c echo.Context) error {
+	ctx := c.Request().Context()
+	interval := c.QueryParam("interval")
+	if interval == "" {
+		interval = "day"
+	}
+	if interval != "day" && interval != "week" {
+		return apierr.Invalid("interval must be day or week", "interval")
+	}
+	groupBy := c.QueryParam("group_by")
+	if groupBy != "" && groupBy != "department" {
+		return apierr.Invalid("group_by must be department", "group_by")
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to := today.AddDate(0, 0, -29), today
+	var err error
+	if v := c.QueryParam("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			return apierr.Invalid("from must be YYYY-MM-DD", "from")
+		}
+	}
+	if v := c.QueryParam("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return apierr.Invalid("to must be YYYY-MM-DD", "to")
+		}
+	}
+	if to.Before(from) {
+		return apierr.Invalid("to must not be before from", "to")
+	}
+	if to.Sub(from) > maxTimeseriesRange {
+		return apierr.New(http.StatusBadRequest, "DATE_RANGE_TOO_LARGE", "date range too large")
+	}
+
+	q := database.TimeseriesQuery{
+		From:         from,
+		To:           to.AddDate(0, 0, 1), // inclusive of the whole "to" day
+		Weekly:       interval == "week",
+		ByDepartment: groupBy == "department",
+	}
+	if c.Get(mw.CtxUserRole) == mw.RoleDeptAdmin {
+		deptID, _ := c.Get(mw.CtxDeptID).(*string)
+		if deptID == nil {
+			return apierr.New(http.StatusForbidden, "DEPARTMENT_REQUIRED", "department admin must belong to a department")
+		}
+		q.DepartmentID = deptID
+	}
+
+	points, err := h.db.Reader().ActivityTimeseries(ctx, q)
+	if err != nil {
+		return apierr.Database()
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"interval": interval,
+		"group_by": groupBy,
+		"series":   zeroFill(points, periods(from, to, q.Weekly)),
+	})
+}
+
+// periods lists the bucket keys covering [from, to].
+func periods(from, to time.Time, weekly bool) []string {
+	step := 1
+	if weekly {
+		// Align to Monday, matching SQLite's date(x, 'weekday 0', '-6 days').
+		from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+		step = 7
+	}
+	var out []string
+	for d := from; !d.After(to); d = d.AddDate(0, 0, step) {
+		out = append(out, d.Format("2006-01-02"))
+	}
+	return out
+}
+
+// zeroFill adds empty points so every group has an entry for every period.
+func zeroFill(points []*database.TimeseriesPoint, keys []string) []*database.TimeseriesPoint {
+	type group struct{ id, name *string }
+	groups := []group{}
+	seenGroup := map[string]bool{}
+	have := map[string]*database.TimeseriesPoint{}
+	for _, p := range points {
+		g := deref(p.DepartmentID)
+		if !seenGroup[g] {
+			seenGroup[g] = true
+			groups = append(groups, group{p.DepartmentID, p.DepartmentName})
+		}
+		have[p.Period+"|"+g] = p
+	}
+	if len(groups) == 0 {
+		groups = append(groups, group{})
+	}
+
+	out := make([]*database.TimeseriesPoint, 0, len(keys)*len(groups))
+	for _, k := range keys {
+		for _, g := range groups {
+			if p, ok := have[k+"|"+deref(g.id)]; ok {
+				out = append(out, p)
+				continue
+			}
+			out = append(out, &database.TimeseriesPoint{Period: k, DepartmentID: g.id, DepartmentName: g.name})
+		}
+	}
+	return out
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
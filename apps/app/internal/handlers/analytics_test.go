@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestAdminStats_DateRange verifies from/to scope the activity counts and
+// per-policy acknowledgements while the totals stay whole.
+func TestAdminStats_DateRange(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, ada.ID, *p.CurrentVersionID)
+
+	e := echo.New()
+	type response struct {
+		Stats     database.Stats             `json:"stats"`
+		AckCounts []*database.PolicyAckCount `json:"ack_counts"`
+	}
+	stats := func(query string) response {
+		t.Helper()
+		c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+		c.Request().URL.RawQuery = query
+		if err := NewPolicy(db).AdminStats(c); err != nil {
+			t.Fatalf("AdminStats(%s): %v", query, err)
+		}
+		var r response
+		json.Unmarshal(rec.Body.Bytes(), &r)
+		return r
+	}
+
+	if r := stats(""); r.Stats.Period != nil || len(r.AckCounts) != 1 || r.AckCounts[0].AckCount != 1 {
+		t.Errorf("unscoped = %+v %+v; want no period, one acknowledgement", r.Stats, r.AckCounts)
+	}
+	today := time.Now().UTC().Format("2006-01-02")
+	r := stats("from=" + today + "&to=" + today)
+	if r.Stats.Period == nil || *r.Stats.Period != (database.StatsPeriod{Acknowledgements: 1, Publishes: 1, NewUsers: 1}) {
+		t.Errorf("today period = %+v; want one of each", r.Stats.Period)
+	}
+	r = stats("from=2020-01-01&to=2020-03-31")
+	if *r.Stats.Period != (database.StatsPeriod{}) || r.AckCounts[0].AckCount != 0 || r.Stats.TotalAckCount != 1 {
+		t.Errorf("Q1 2020 = %+v %+v; want zero activity, totals unchanged", r.Stats, r.AckCounts[0])
+	}
+}
+
+// TestZeroFill verifies that every group gets a point for every period, in
+// period order, keeping the counts of the points that exist.
+func TestZeroFill(t *testing.T) {
+	hr, ops := testutil.Ptr("hr"), testutil.Ptr("ops")
+	points := []*database.TimeseriesPoint{
+		{Period: "2026-03-02", DepartmentID: hr, DepartmentName: testutil.Ptr("HR"), Acknowledgements: 4},
+		{Period: "2026-03-03", DepartmentID: ops, DepartmentName: testutil.Ptr("Ops"), Publishes: 1},
+	}
+	keys := []string{"2026-03-01", "2026-03-02", "2026-03-03"}
+
+	got := zeroFill(points, keys)
+	if len(got) != len(keys)*2 {
+		t.Fatalf("got %d points; want %d", len(got), len(keys)*2)
+	}
+	for i, p := range got {
+		want := keys[i/2]
+		if p.Period != want || p.DepartmentName == nil {
+			t.Errorf("point %d = %s %v; want period %s with a department name", i, p.Period, p.DepartmentName, want)
+		}
+	}
+	if got[2].Acknowledgements != 4 || deref(got[2].DepartmentID) != "hr" || got[5].Publishes != 1 || got[0].Acknowledgements != 0 {
+		t.Errorf("counts not kept: %+v %+v %+v", got[0], got[2], got[5])
+	}
+
+	if got := zeroFill(nil, keys); len(got) != len(keys) || got[0].DepartmentID != nil {
+		t.Errorf("no activity: %d points; want one ungrouped point per period", len(got))
+	}
+}
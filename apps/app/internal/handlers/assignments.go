@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/events"
+	mw "policyflow/internal/middleware"
+)
+
+// Assignments returns the explicit assignment list for a policy.
+// GET /api/policies/:id/assignments
+func (h *Policy) Assignments(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	assignments, err := h.db.ListPolicyAssignments(ctx, policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if assignments == nil {
+		assignments = []*database.PolicyAssignment{}
+	}
+	return c.JSON(http.StatusOK, assignments)
+}
+
+// CreateAssignments assigns a policy to users, roles, or departments.
+// Users may be given by ID or, for uploaded lists, by email.
+// POST /api/policies/:id/assignments
+func (h *Policy) CreateAssignments(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+
+	var body struct {
+		TargetType string   `json:"target_type"`
+		TargetIDs  []string `json:"target_ids"`
+		Emails     []string `json:"emails"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if len(body.Emails) > 0 && body.TargetType == "" {
+		body.TargetType = database.AssignUser
+	}
+	validTypes := map[string]bool{database.AssignUser: true, database.AssignRole: true, database.AssignDepartment: true}
+	if !validTypes[body.TargetType] {
+		return apierr.Invalid("target_type must be user, role, or department", "target_type")
+	}
+	if len(body.Emails) > 0 && body.TargetType != database.AssignUser {
+		return apierr.Invalid("emails can only be used with target_type user", "emails")
+	}
+	if len(body.TargetIDs) == 0 && len(body.Emails) == 0 {
+		return apierr.Invalid("target_ids or emails required", "target_ids", "emails")
+	}
+
+	role := c.Get(mw.CtxUserRole).(string)
+	callerDeptID, _ := c.Get(mw.CtxDeptID).(*string)
+
+	// Resolve every target first so a bad entry doesn't leave a partial list.
+	targets := make([]string, 0, len(body.TargetIDs)+len(body.Emails))
+	unknownEmails := []string{}
+	for _, em := range body.Emails {
+		u, err := h.db.GetUserByEmail(ctx, em)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				unknownEmails = append(unknownEmails, em)
+				continue
+			}
+			return apierr.Database()
+		}
+		if role == mw.RoleDeptAdmin && !sameDept(u.DepartmentID, callerDeptID) {
+			return apierr.New(http.StatusForbidden, "OUTSIDE_DEPARTMENT", "cannot assign users outside your department")
+		}
+		targets = append(targets, u.ID)
+	}
+	for _, id := range body.TargetIDs {
+		switch body.TargetType {
+		case database.AssignUser:
+			u, err := h.db.GetUserByID(ctx, id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return apierr.Invalid("unknown user: "+id, "target_ids")
+				}
+				return apierr.Database()
+			}
+			if role == mw.RoleDeptAdmin && !sameDept(u.DepartmentID, callerDeptID) {
+				return apierr.New(http.StatusForbidden, "OUTSIDE_DEPARTMENT", "cannot assign users outside your department")
+			}
+		case database.AssignRole:
+			if id != mw.RoleSuperAdmin && id != mw.RoleDeptAdmin && id != mw.RoleStaff {
+				return apierr.Invalid("unknown role: "+id, "target_ids")
+			}
+			// A role spans the whole organization.
+			if role == mw.RoleDeptAdmin {
+				return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "department admins cannot assign by role")
+			}
+		case database.AssignDepartment:
+			if _, err := h.db.GetDepartment(ctx, id); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return apierr.Invalid("unknown department: "+id, "target_ids")
+				}
+				return apierr.Database()
+			}
+			if role == mw.RoleDeptAdmin && !sameDept(&id, callerDeptID) {
+				return apierr.New(http.StatusForbidden, "OUTSIDE_DEPARTMENT", "cannot assign other departments")
+			}
+		}
+		targets = append(targets, id)
+	}
+
+	creatorID := c.Get(mw.CtxUserID).(string)
+	created := make([]*database.PolicyAssignment, 0, len(targets))
+	for _, id := range targets {
+		a, err := h.db.CreatePolicyAssignment(ctx, policy.ID, body.TargetType, id, &creatorID)
+		if err != nil {
+			return apierr.Database()
+		}
+		created = append(created, a)
+	}
+	if body.TargetType == database.AssignUser && len(targets) > 0 {
+		h.events.Publish(events.Event{
+			Type: events.Notification,
+			Data: map[string]any{
+				"kind":      "assignment",
+				"policy_id": policy.ID,
+				"title":     policy.Title,
+				"message":   fmt.Sprintf("You have been asked to acknowledge %q.", policy.Title),
+			},
+			Audience: events.Audience{UserIDs: targets},
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]any{
+		"assignments":    created,
+		"unknown_emails": unknownEmails,
+	})
+}
+
+// DeleteAssignment removes a single assignment from a policy.
+// DELETE /api/policies/:id/assignments/:assignmentId
+func (h *Policy) DeleteAssignment(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	a, err := h.db.GetPolicyAssignment(ctx, c.Param("assignmentId"))
+	if err != nil || a.PolicyID != policy.ID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "ASSIGNMENT_NOT_FOUND", "assignment not found")
+		}
+		return apierr.Database()
+	}
+	if err := h.db.DeletePolicyAssignment(ctx, a.ID); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Required returns the published policies the current user must acknowledge,
+// computed from assignments and visibility.
+// GET /api/me/required
+func (h *Policy) Required(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get(mw.CtxUserID).(string)
+	role := c.Get(mw.CtxUserRole).(string)
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+
+	policies, err := h.db.ListRequiredPoliciesForUser(ctx, userID, role, deptID)
+	if err != nil {
+		return apierr.Database()
+	}
+	ackMap, _ := h.db.AckStatusForUser(ctx, userID)
+
+	type requiredPolicy struct {
+		*database.Policy
+		Acknowledged bool `json:"acknowledged"`
+	}
+	result := make([]requiredPolicy, len(policies))
+	for i, p := range policies {
+		acked := false
+		if p.CurrentVersionID != nil {
+			acked = ackMap[*p.CurrentVersionID]
+		}
+		result[i] = requiredPolicy{Policy: p, Acknowledged: acked}
+	}
+	return c.JSON(http.StatusOK, result)
+}
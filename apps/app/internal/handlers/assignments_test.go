@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+func requiredTitles(t *testing.T, h *Policy, e *echo.Echo, userID, role string, deptID *string) map[string]bool {
+	t.Helper()
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", role, deptID)
+	c.Set(mw.CtxUserID, userID)
+	if err := h.Required(c); err != nil {
+		t.Fatalf("Required: %v", err)
+	}
+	var got []database.Policy
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	titles := map[string]bool{}
+	for _, p := range got {
+		titles[p.Title] = true
+	}
+	return titles
+}
+
+// TestRequired_AssignmentsNarrowAndExtend verifies that an assigned policy is
+// required only for matching users, even across department visibility, while
+// unassigned policies remain required for everyone who can see them.
+func TestRequired_AssignmentsNarrowAndExtend(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	alice, _ := db.CreateUser(ctx, "alice@example.com", "Alice", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	bob, _ := db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleDeptAdmin, nil, testutil.Ptr(eng.ID))
+
+	general, _ := db.CreatePolicy(ctx, "General", "", nil, "organization", nil)
+	managers, _ := db.CreatePolicy(ctx, "Managers", "", nil, "organization", nil)
+	hrOnly, _ := db.CreatePolicy(ctx, "HR Handbook", "", testutil.Ptr(hr.ID), "department", nil)
+	for _, p := range []*database.Policy{general, managers, hrOnly} {
+		testutil.Publish(t, db, p)
+	}
+	if _, err := db.CreatePolicyAssignment(ctx, managers.ID, database.AssignRole, mw.RoleDeptAdmin, nil); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+	if _, err := db.CreatePolicyAssignment(ctx, hrOnly.ID, database.AssignUser, alice.ID, nil); err != nil {
+		t.Fatalf("assign user: %v", err)
+	}
+
+	e := echo.New()
+	h := NewPolicy(db)
+
+	got := requiredTitles(t, h, e, alice.ID, mw.RoleStaff, testutil.Ptr(eng.ID))
+	if !got["General"] || got["Managers"] || !got["HR Handbook"] {
+		t.Errorf("alice required = %v; want General and HR Handbook", got)
+	}
+	got = requiredTitles(t, h, e, bob.ID, mw.RoleDeptAdmin, testutil.Ptr(eng.ID))
+	if !got["General"] || !got["Managers"] || got["HR Handbook"] {
+		t.Errorf("bob required = %v; want General and Managers", got)
+	}
+}
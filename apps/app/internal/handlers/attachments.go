@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/storage"
+)
+
+// DefaultMaxUploadBytes caps a single attachment or PDF version unless
+// MAX_UPLOAD_SIZE says otherwise.
+const DefaultMaxUploadBytes = 100 << 20
+
+// Attachments stores supporting files for policies in blob storage.
+type Attachments struct {
+	policy *Policy
+	store  storage.Store
+}
+
+func NewAttachments(policy *Policy, store storage.Store) *Attachments {
+	return &Attachments{policy: policy, store: store}
+}
+
+// List returns the attachments of a policy visible to the caller.
+// GET /api/policies/:id/attachments
+func (h *Attachments) List(c echo.Context) error {
+	policy, err := h.policy.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	list, err := h.policy.db.ListPolicyAttachments(c.Request().Context(), policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if list == nil {
+		list = []*database.PolicyAttachment{}
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// Upload stores a policy attachment, sent as the multipart "file" field or
+// as the upload_id of a finished chunked upload.
+// POST /api/policies/:id/attachments
+func (h *Attachments) Upload(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.policy.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	f, err := h.policy.incomingFile(c, h.policy.maxUpload)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := h.policy.scanUpload(c, database.UploadAttachment, &policy.ID, f.Filename, f); err != nil {
+		return err
+	}
+
+	contentType := f.ContentType
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		contentType = "application/octet-stream"
+	}
+	a := &database.PolicyAttachment{
+		ID:          uuid.New().String(),
+		PolicyID:    policy.ID,
+		Filename:    f.Filename,
+		ContentType: contentType,
+		SizeBytes:   f.Size,
+	}
+	a.StorageKey = "attachments/" + policy.ID + "/" + a.ID
+	uploader := c.Get(mw.CtxUserID).(string)
+	a.UploadedBy = &uploader
+
+	hash := sha256.New()
+	if err := h.store.Put(ctx, a.StorageKey, io.TeeReader(f, hash), f.Size, contentType); err != nil {
+		log.Printf("attachment upload: %v", err)
+		return apierr.New(http.StatusBadGateway, "STORAGE_ERROR", "storage error")
+	}
+	a.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	created, err := h.policy.db.CreatePolicyAttachment(ctx, a)
+	if err != nil {
+		_ = h.store.Delete(ctx, a.StorageKey)
+		return apierr.Database()
+	}
+	f.Done()
+	return c.JSON(http.StatusCreated, created)
+}
+
+// Download streams an attachment. Files are always served as downloads so
+// uploaded HTML cannot run in the app's origin.
+// GET /api/policies/:id/attachments/:attachmentId
+func (h *Attachments) Download(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.policy.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	a, err := h.attachment(c, policy.ID)
+	if err != nil {
+		return err
+	}
+	rc, err := h.store.Get(ctx, a.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return apierr.New(http.StatusNotFound, "ATTACHMENT_NOT_FOUND", "attachment not found")
+		}
+		log.Printf("attachment download: %v", err)
+		return apierr.New(http.StatusBadGateway, "STORAGE_ERROR", "storage error")
+	}
+	defer rc.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	c.Response().Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	return c.Stream(http.StatusOK, a.ContentType, rc)
+}
+
+// Delete removes an attachment and its stored bytes.
+// DELETE /api/policies/:id/attachments/:attachmentId
+func (h *Attachments) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.policy.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	a, err := h.attachment(c, policy.ID)
+	if err != nil {
+		return err
+	}
+	if err := h.policy.db.DeletePolicyAttachment(ctx, a.ID); err != nil {
+		return apierr.Database()
+	}
+	if err := h.store.Delete(ctx, a.StorageKey); err != nil {
+		log.Printf("attachment delete %s: %v", a.StorageKey, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Attachments) attachment(c echo.Context, policyID string) (*database.PolicyAttachment, error) {
+	a, err := h.policy.db.GetPolicyAttachment(c.Request().Context(), c.Param("attachmentId"))
+	if err != nil || a.PolicyID != policyID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return nil, apierr.New(http.StatusNotFound, "ATTACHMENT_NOT_FOUND", "attachment not found")
+		}
+		return nil, apierr.Database()
+	}
+	return a, nil
+}
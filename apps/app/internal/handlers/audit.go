@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+)
+
+// Audit exposes the audit trail to SuperAdmins.
+type Audit struct {
+	db *database.DB
+}
+
+func NewAudit(db *database.DB) *Audit {
+	return &Audit{db: db}
+}
+
+// List returns recent audit entries, optionally filtered by actor (matching
+// either the acting or impersonating user) and action.
+// GET /api/admin/audit?actor_id=&action=&limit=  (SuperAdmin only)
+func (h *Audit) List(c echo.Context) error {
+	f := database.AuditFilter{
+		ActorID: c.QueryParam("actor_id"),
+		Action:  c.QueryParam("action"),
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			return apierr.Invalid("limit must be between 1 and 1000", "limit")
+		}
+		f.Limit = n
+	}
+	entries, err := h.db.ListAuditLog(c.Request().Context(), f)
+	if err != nil {
+		return apierr.Database()
+	}
+	if entries == nil {
+		entries = []*database.AuditEntry{}
+	}
+	return c.JSON(http.StatusOK, entries)
+}
+
+// Activity returns the organization activity feed: policy, user, and
+// department events from the audit log, newest first. Pass the returned
+// next_before as ?before= to fetch the following page.
+// GET /api/admin/activity?type=&actor_id=&target_id=&since=&before=&limit=  (SuperAdmin only)
+func (h *Audit) Activity(c echo.Context) error {
+	f := database.ActivityFilter{
+		ActorID:  c.QueryParam("actor_id"),
+		TargetID: c.QueryParam("target_id"),
+		Before:   c.QueryParam("before"),
+		Limit:    50,
+	}
+	if v := c.QueryParam("type"); v != "" {
+		valid := map[string]bool{}
+		for _, a := range database.ActivityActions {
+			valid[a] = true
+		}
+		for _, t := range strings.Split(v, ",") {
+			if !valid[t] {
+				return apierr.Invalid("unknown activity type: "+t, "type")
+			}
+			f.Types = append(f.Types, t)
+		}
+	}
+	if v := c.QueryParam("since"); v != "" {
+		t, err := time.Parse("2006-01-02", v) // start of that day
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, v); err != nil {
+				return apierr.Invalid("since must be YYYY-MM-DD or RFC3339", "since")
+			}
+		}
+		f.Since = &t
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			return apierr.Invalid("limit must be between 1 and 200", "limit")
+		}
+		f.Limit = n
+	}
+
+	items, err := h.db.ListActivity(c.Request().Context(), f)
+	if err != nil {
+		return apierr.Database()
+	}
+	if items == nil {
+		items = []*database.ActivityItem{}
+	}
+	var next *string
+	if len(items) == f.Limit {
+		next = &items[len(items)-1].ID
+	}
+	return c.JSON(http.StatusOK, map[string]any{"items": items, "next_before": next})
+}
+
+// Denied reports the users, and IP addresses without a valid session, whose
+// requests were refused at least ?min= times in the last ?days= days: 401s,
+// 403s, and lookups of policies hidden from them. Repeated refusals suggest
+// probing, such as guessing the IDs of other departments' policies; the
+// individual requests are in the audit log under action=access.denied.
+// GET /api/admin/audit/denied?days=&min=  (SuperAdmin only)
+func (h *Audit) Denied(c echo.Context) error {
+	days, min := 7, 5
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			return apierr.Invalid("days must be between 1 and 365", "days")
+		}
+		days = n
+	}
+	if v := c.QueryParam("min"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 10000 {
+			return apierr.Invalid("min must be between 1 and 10000", "min")
+		}
+		min = n
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	out, err := h.db.Reader().ListDeniedAccess(c.Request().Context(), since, min)
+	if err != nil {
+		return apierr.Database()
+	}
+	if out == nil {
+		out = []*database.DeniedAccess{}
+	}
+	return c.JSON(http.StatusOK, out)
+}
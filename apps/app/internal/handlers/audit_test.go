@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestAudit_DeniedAccessReport verifies that refused requests, including
+// lookups of policies hidden from the caller and requests without a
+// session, are audited, and that repeat offenders show in the report.
+func TestAudit_DeniedAccessReport(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	staff, _ := db.CreateUser(ctx, "dev@example.com", "Dev", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	secret, _ := db.CreatePolicy(ctx, "Salary Bands", "", testutil.Ptr(hr.ID), "department", nil)
+
+	e := echo.New()
+	e.HTTPErrorHandler = apierr.Handler
+	keys := testutil.NewKeyring(t, db)
+	auth := mw.NewAuth(keys, db)
+	e.GET("/api/policies/:id", NewPolicy(db).Get, auth.Audit, auth.Require)
+	e.GET("/api/admin/stats", NewPolicy(db).AdminStats, auth.Audit, auth.Require, auth.RequireSuperAdmin)
+
+	token, _ := keys.Sign(jwt.MapClaims{
+		"sub": staff.ID, "role": staff.Role, "type": "session",
+		"iat": time.Now().Unix(), "auth_time": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for range 5 {
+		if code := get("/api/policies/"+secret.ID, token); code != http.StatusNotFound {
+			t.Fatalf("hidden policy: status %d; want 404", code)
+		}
+	}
+	if code := get("/api/admin/stats", token); code != http.StatusForbidden {
+		t.Fatalf("admin stats: status %d; want 403", code)
+	}
+	if code := get("/api/policies/"+secret.ID, ""); code != http.StatusUnauthorized {
+		t.Fatalf("no token: status %d; want 401", code)
+	}
+
+	entries, _ := db.ListAuditLog(ctx, database.AuditFilter{Action: database.ActionAccessDenied})
+	if len(entries) != 7 {
+		t.Fatalf("access.denied entries = %d; want 7", len(entries))
+	}
+	if e := entries[len(entries)-1]; e.TargetType != "policy" || e.TargetID != secret.ID || e.ActorID == nil || *e.ActorID != staff.ID {
+		t.Errorf("first entry = %+v", e)
+	}
+
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	c.QueryParams().Set("min", "2")
+	if err := NewAudit(db).Denied(c); err != nil {
+		t.Fatalf("Denied: %v", err)
+	}
+	var report []database.DeniedAccess
+	json.Unmarshal(rec.Body.Bytes(), &report)
+	if len(report) != 1 || report[0].ActorID == nil || *report[0].ActorID != staff.ID ||
+		report[0].Attempts != 6 || report[0].Targets != 1 {
+		t.Errorf("report = %+v; want Dev with 6 attempts on 1 target", report)
+	}
+}
@@ -1,142 +1,356 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
+	"math/big"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 
+	"policyflow/internal/apierr"
 	"policyflow/internal/database"
 	"policyflow/internal/email"
 	mw "policyflow/internal/middleware"
+	"policyflow/internal/tokens"
 )
 
-// Auth handles magic-link authentication.
+// loginCodeLifetime caps how long an emailed sign-in code is valid; codes
+// are short enough to guess, so they live shorter than links.
+const loginCodeLifetime = 10 * time.Minute
+
+// Limits on sign-in codes per user in loginCodeWindow. Failed guesses are
+// counted across codes, so requesting a new code does not reset them.
+const (
+	loginCodeWindow    = time.Hour
+	loginCodeSendLimit = 5
+	loginCodeFailLimit = 10
+)
+
+// Auth handles magic-link and login-code authentication.
 type Auth struct {
-	db        *database.DB
-	mailer    *email.Mailer
-	jwtSecret []byte
-	baseURL   string
+	db      *database.DB
+	mailer  *email.Mailer
+	keys    *tokens.Keyring
+	baseURL string
 }
 
-func NewAuth(db *database.DB, mailer *email.Mailer, jwtSecret string) *Auth {
-	base := os.Getenv("BASE_URL")
-	if base == "" {
-		base = "http://localhost:8080"
-	}
+func NewAuth(db *database.DB, mailer *email.Mailer, keys *tokens.Keyring) *Auth {
 	return &Auth{
-		db:        db,
-		mailer:    mailer,
-		jwtSecret: []byte(jwtSecret),
-		baseURL:   base,
+		db:      db,
+		mailer:  mailer,
+		keys:    keys,
+		baseURL: baseURLFromEnv(),
 	}
 }
 
-// RequestMagicLink sends a login link to the given email address.
-// POST /api/magic-link
+// baseURLFromEnv returns the public server URL used in emails and links.
+func baseURLFromEnv() string {
+	if base := os.Getenv("BASE_URL"); base != "" {
+		return base
+	}
+	return "http://localhost:8080"
+}
+
+// RequestMagicLink sends a login link to the given email address. An
+// optional redirect, such as the policy a reminder pointed to, is carried
+// through the link to the frontend after sign-in.
+// POST /api/magic-link  {"email": "...", "redirect": "/policies?id=..."}
 func (h *Auth) RequestMagicLink(c echo.Context) error {
+	ctx := c.Request().Context()
 	var body struct {
-		Email string `json:"email"`
+		Email    string `json:"email"`
+		Redirect string `json:"redirect"`
 	}
 	if err := c.Bind(&body); err != nil || body.Email == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "email required")
+		return apierr.Invalid("email required", "email")
 	}
 
-	user, err := h.db.GetUserByEmail(body.Email)
+	user, err := h.db.GetUserByEmail(ctx, body.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			// Don't reveal whether the email exists
 			return c.JSON(http.StatusOK, map[string]string{"message": "if that email is registered, a link has been sent"})
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
+	}
+	if user.DeactivatedAt != nil {
+		return c.JSON(http.StatusOK, map[string]string{"message": "if that email is registered, a link has been sent"})
 	}
 
-	magicToken, err := h.buildMagicToken(user.Email)
+	lifetimes, err := tokens.LoadLifetimes(ctx, h.db)
+	if err != nil {
+		return apierr.Database()
+	}
+	magicToken, err := h.buildMagicToken(user.Email, lifetimes)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "token error")
+		return apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "token error")
 	}
 
 	magicURL := fmt.Sprintf("%s/api/magic-login?token=%s", h.baseURL, magicToken)
-	if err := h.mailer.SendMagicLink(user.Email, user.Name, magicURL); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "email error")
+	if to := safeRedirect(body.Redirect); to != "" {
+		magicURL += "&redirect=" + url.QueryEscape(to)
+	}
+	if err := h.mailer.SendMagicLink(user.Email, user.Name, magicURL, lifetimes.MagicLink); err != nil {
+		return apierr.New(http.StatusInternalServerError, "EMAIL_ERROR", "email error")
+	}
+	if _, err := h.db.RecordLoginEvent(ctx, user.ID, database.LoginEventLinkIssued, c.RealIP(), c.Request().UserAgent()); err != nil {
+		log.Printf("record login event: %v", err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "if that email is registered, a link has been sent"})
 }
 
 // MagicLogin validates a magic-link token and returns a session JWT.
-// GET /api/magic-login?token=JWT
+// GET /api/magic-login?token=JWT&redirect=/policies?id=...
 func (h *Auth) MagicLogin(c echo.Context) error {
+	ctx := c.Request().Context()
 	tokenStr := c.QueryParam("token")
 	if tokenStr == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "token required")
+		return apierr.Invalid("token required", "token")
 	}
 
 	email, err := h.parseMagicToken(tokenStr)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired link")
+		return apierr.New(http.StatusUnauthorized, "LINK_EXPIRED", "invalid or expired link")
 	}
 
-	user, err := h.db.GetUserByEmail(email)
+	user, err := h.db.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusUnauthorized, "user not found")
+			return apierr.New(http.StatusUnauthorized, "INVALID_TOKEN", "user not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
+	}
+	if user.DeactivatedAt != nil {
+		return apierr.New(http.StatusUnauthorized, "ACCOUNT_DEACTIVATED", "account deactivated")
 	}
 
-	sessionToken, err := h.buildSessionToken(user)
+	sessionToken, err := h.startSession(c, user)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "session error")
+		return err
 	}
 
 	// Redirect to the frontend with the session token embedded as a query param.
-	// The frontend stores it and redirects to /policies.
+	// The frontend stores it and redirects to the requested page, or /policies.
 	redirectURL := fmt.Sprintf("%s/auth-callback?token=%s", h.baseURL, sessionToken)
+	if to := safeRedirect(c.QueryParam("redirect")); to != "" {
+		redirectURL += "&redirect=" + url.QueryEscape(to)
+	}
 	return c.Redirect(http.StatusTemporaryRedirect, redirectURL)
 }
 
-// Me returns the currently authenticated user.
+// redirectPaths are the frontend pages a sign-in may land on.
+var redirectPaths = []string{"/policies", "/admin"}
+
+// safeRedirect returns raw if it is a same-site path under one of
+// redirectPaths, and "" otherwise, so a crafted link cannot send a freshly
+// signed-in user to another site.
+func safeRedirect(raw string) string {
+	if raw == "" || strings.ContainsAny(raw, "\\\x00\r\n") {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil || !strings.HasPrefix(raw, "/") || strings.HasPrefix(raw, "//") {
+		return ""
+	}
+	clean := path.Clean(u.Path)
+	for _, p := range redirectPaths {
+		if clean == p || strings.HasPrefix(clean, p+"/") {
+			u.Path = clean
+			return u.RequestURI()
+		}
+	}
+	return ""
+}
+
+// RequestLoginCode emails a six-digit sign-in code instead of a link, for
+// users whose mail gateway rewrites or expires link URLs. Like
+// RequestMagicLink, it does not reveal whether the email is registered, so
+// a request over loginCodeSendLimit gets the same answer but no code.
+// POST /api/login-code
+func (h *Auth) RequestLoginCode(c echo.Context) error {
+	ctx := c.Request().Context()
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.Bind(&body); err != nil || body.Email == "" {
+		return apierr.Invalid("email required", "email")
+	}
+	sent := map[string]string{"message": "if that email is registered, a code has been sent"}
+
+	user, err := h.db.GetUserByEmail(ctx, body.Email)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user.DeactivatedAt != nil) {
+		return c.JSON(http.StatusOK, sent)
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	issued, err := h.db.CountLoginEventsSince(ctx, user.ID, database.LoginEventCodeIssued, time.Now().Add(-loginCodeWindow))
+	if err != nil {
+		return apierr.Database()
+	}
+	if issued >= loginCodeSendLimit {
+		return c.JSON(http.StatusOK, sent)
+	}
+
+	lifetimes, err := tokens.LoadLifetimes(ctx, h.db)
+	if err != nil {
+		return apierr.Database()
+	}
+	validFor := min(lifetimes.MagicLink, loginCodeLifetime)
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "code error")
+	}
+	code := fmt.Sprintf("%06d", n)
+	if err := h.db.SetLoginCode(ctx, user.ID, code, time.Now().Add(validFor)); err != nil {
+		return apierr.Database()
+	}
+	if err := h.mailer.SendLoginCode(user.Email, user.Name, code, validFor); err != nil {
+		return apierr.New(http.StatusInternalServerError, "EMAIL_ERROR", "email error")
+	}
+	if _, err := h.db.RecordLoginEvent(ctx, user.ID, database.LoginEventCodeIssued, c.RealIP(), c.Request().UserAgent()); err != nil {
+		log.Printf("record login event: %v", err)
+	}
+	return c.JSON(http.StatusOK, sent)
+}
+
+// VerifyLoginCode exchanges an emailed code for a session token. A code
+// works once, and five wrong guesses void it. After loginCodeFailLimit
+// wrong guesses in loginCodeWindow the user's codes are refused outright.
+// POST /api/login-code/verify  {"email": "...", "code": "123456"}
+func (h *Auth) VerifyLoginCode(c echo.Context) error {
+	ctx := c.Request().Context()
+	var body struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	body.Code = strings.Join(strings.Fields(body.Code), "")
+	if body.Email == "" || body.Code == "" {
+		return apierr.Invalid("email and code are required", "email", "code")
+	}
+	invalid := apierr.New(http.StatusUnauthorized, "INVALID_CODE", "invalid or expired code")
+
+	user, err := h.db.GetUserByEmail(ctx, body.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return invalid
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	failed, err := h.db.CountLoginEventsSince(ctx, user.ID, database.LoginEventCodeFailed, time.Now().Add(-loginCodeWindow))
+	if err != nil {
+		return apierr.Database()
+	}
+	if failed >= loginCodeFailLimit {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(loginCodeWindow.Seconds())))
+		return apierr.New(http.StatusTooManyRequests, "TOO_MANY_ATTEMPTS", "too many wrong codes; try again later")
+	}
+	ok, err := h.db.UseLoginCode(ctx, user.ID, body.Code)
+	if err != nil {
+		return apierr.Database()
+	}
+	if !ok {
+		if _, err := h.db.RecordLoginEvent(ctx, user.ID, database.LoginEventCodeFailed, c.RealIP(), c.Request().UserAgent()); err != nil {
+			return apierr.Database()
+		}
+		return invalid
+	}
+	if user.DeactivatedAt != nil {
+		return apierr.New(http.StatusUnauthorized, "ACCOUNT_DEACTIVATED", "account deactivated")
+	}
+
+	sessionToken, err := h.startSession(c, user)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"token": sessionToken})
+}
+
+// startSession issues a session token for user and records the sign-in,
+// warning the user by email when it comes from a new device.
+func (h *Auth) startSession(c echo.Context, user *database.User) (string, error) {
+	ctx := c.Request().Context()
+	lifetimes, err := tokens.LoadLifetimes(ctx, h.db)
+	if err != nil {
+		return "", apierr.Database()
+	}
+	sessionToken, err := h.buildSessionToken(user, lifetimes)
+	if err != nil {
+		return "", apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "session error")
+	}
+	if err := h.db.RecordLogin(ctx, user.ID); err != nil {
+		return "", apierr.Database()
+	}
+	ip, ua := c.RealIP(), c.Request().UserAgent()
+	newDevice, err := h.db.RecordLoginEvent(ctx, user.ID, database.LoginEventLogin, ip, ua)
+	if err != nil {
+		return "", apierr.Database()
+	}
+	if newDevice {
+		go func() {
+			if err := h.mailer.SendNewDeviceLogin(user.Email, user.Name, time.Now(), ip, ua); err != nil {
+				log.Printf("new device email to %s: %v", user.Email, err)
+			}
+		}()
+	}
+	return sessionToken, nil
+}
+
+// Me returns the currently authenticated user, with impersonated_by set in
+// impersonation sessions.
 // GET /api/me
 func (h *Auth) Me(c echo.Context) error {
+	ctx := c.Request().Context()
 	userID := c.Get(mw.CtxUserID).(string)
-	user, err := h.db.GetUserByID(userID)
+	user, err := h.db.GetUserByID(ctx, userID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
 	}
-	return c.JSON(http.StatusOK, user)
+	impersonatorID, _ := c.Get(mw.CtxImpersonatorID).(string)
+	if impersonatorID == "" {
+		return c.JSON(http.StatusOK, user)
+	}
+	// Let the frontend show an unmistakable impersonation banner.
+	return c.JSON(http.StatusOK, struct {
+		*database.User
+		ImpersonatedBy string `json:"impersonated_by"`
+	}{user, impersonatorID})
 }
 
 // ─── Token helpers ─────────────────────────────────────────────────────────
 
-func (h *Auth) buildMagicToken(email string) (string, error) {
+func (h *Auth) buildMagicToken(email string, lifetimes tokens.Lifetimes) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":  email,
 		"type": "magic",
-		"exp":  time.Now().Add(24 * time.Hour).Unix(),
+		"exp":  time.Now().Add(lifetimes.MagicLink).Unix(),
 		"iat":  time.Now().Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(h.jwtSecret)
+	return h.keys.Sign(claims)
 }
 
 func (h *Auth) parseMagicToken(tokenStr string) (string, error) {
-	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return h.jwtSecret, nil
-	})
-	if err != nil || !token.Valid {
+	claims := jwt.MapClaims{}
+	if err := h.keys.Parse(tokenStr, claims); err != nil {
 		return "", fmt.Errorf("invalid token")
 	}
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || claims["type"] != "magic" {
+	if claims["type"] != "magic" {
 		return "", fmt.Errorf("wrong token type")
 	}
 	email, ok := claims["sub"].(string)
@@ -146,22 +360,31 @@ func (h *Auth) parseMagicToken(tokenStr string) (string, error) {
 	return email, nil
 }
 
-func (h *Auth) buildSessionToken(user *database.User) (string, error) {
+// buildSessionToken issues a session starting now. The middleware reissues
+// it on activity when an idle timeout is set (see Auth.Require).
+func (h *Auth) buildSessionToken(user *database.User, lifetimes tokens.Lifetimes) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"sub":   user.ID,
-		"email": user.Email,
-		"role":  user.Role,
-		"type":  "session",
-		"exp":   time.Now().Add(7 * 24 * time.Hour).Unix(),
-		"iat":   time.Now().Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(h.jwtSecret)
+		"sub":       user.ID,
+		"email":     user.Email,
+		"role":      user.Role,
+		"type":      "session",
+		"exp":       lifetimes.SessionExpiry(now, now).Unix(),
+		"iat":       now.Unix(),
+		"auth_time": now.Unix(),
+	}
+	return h.keys.Sign(claims)
 }
 
 // BuildMagicTokenForUser is exposed for use by the user creation handler.
-func (h *Auth) BuildMagicTokenForUser(email string) (string, error) {
-	return h.buildMagicToken(email)
+// It also returns how long the link is valid for.
+func (h *Auth) BuildMagicTokenForUser(ctx context.Context, email string) (string, time.Duration, error) {
+	lifetimes, err := tokens.LoadLifetimes(ctx, h.db)
+	if err != nil {
+		return "", 0, err
+	}
+	token, err := h.buildMagicToken(email, lifetimes)
+	return token, lifetimes.MagicLink, err
 }
 
 func (h *Auth) BaseURL() string {
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+)
+
+// defaultReviewMonths is how long a published version may stand before the
+// author report counts its policy as overdue for review.
+const defaultReviewMonths = 12
+
+// AuthorStats totals each author's policies by status, with how many are
+// overdue for review and the average days from creating a policy to first
+// publishing it. A policy is overdue for review when its current version
+// has been live longer than review_months (1-60, default 12). DeptAdmin
+// only sees their department's policies.
+// GET /api/admin/reports/authors?format=json|csv&review_months=12
+func (h *Reports) AuthorStats(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return apierr.Invalid("format must be json or csv", "format")
+	}
+	months := defaultReviewMonths
+	if v := c.QueryParam("review_months"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 60 {
+			return apierr.Invalid("review_months must be between 1 and 60", "review_months")
+		}
+		months = n
+	}
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+	authors, err := h.db.Reader().ListAuthorStats(c.Request().Context(), deptID, time.Now().AddDate(0, -months, 0))
+	if err != nil {
+		return apierr.Database()
+	}
+	if format == "csv" {
+		rows := [][]string{{"author", "email", "policies", "drafts", "in_review", "published", "archived", "overdue_reviews", "avg_days_to_publish"}}
+		for _, a := range authors {
+			avg := ""
+			if a.AvgDaysToPublish != nil {
+				avg = strconv.FormatFloat(*a.AvgDaysToPublish, 'f', 1, 64)
+			}
+			rows = append(rows, []string{a.AuthorName, a.AuthorEmail, strconv.Itoa(a.Policies), strconv.Itoa(a.Drafts),
+				strconv.Itoa(a.InReview), strconv.Itoa(a.Published), strconv.Itoa(a.Archived), strconv.Itoa(a.OverdueReviews), avg})
+		}
+		return writeCSV(c, "policy-authors.csv", rows)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"review_months": months, "authors": authors})
+}
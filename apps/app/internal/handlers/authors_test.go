@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestAuthorStats_GroupsByCreator verifies policies are counted by status
+// under the user who created them, that a DeptAdmin only sees their
+// department, and that published policies past the review date count as
+// overdue.
+func TestAuthorStats_GroupsByCreator(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", mw.RoleDeptAdmin, nil, &eng.ID)
+	bob, _ := db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleSuperAdmin, nil, nil)
+	published, _ := db.CreatePolicy(ctx, "On-call", "", &eng.ID, "department", &ada.ID)
+	testutil.Publish(t, db, published)
+	db.CreatePolicy(ctx, "Code Review", "", &eng.ID, "department", &ada.ID)
+	db.CreatePolicy(ctx, "Travel", "", nil, "organization", &bob.ID)
+
+	report := func(role string, deptID *string) []*database.AuthorStats {
+		t.Helper()
+		c, rec := testutil.NewContext(echo.New(), http.MethodGet, "", "", role, deptID)
+		if err := NewReports(db, nil).AuthorStats(c); err != nil {
+			t.Fatalf("AuthorStats: %v", err)
+		}
+		var r struct {
+			Authors []*database.AuthorStats `json:"authors"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &r)
+		return r.Authors
+	}
+	authors := report(mw.RoleSuperAdmin, nil)
+	if len(authors) != 2 || authors[0].AuthorName != "Ada" || authors[1].AuthorName != "Bob" {
+		t.Fatalf("authors = %+v; want Ada then Bob", authors)
+	}
+	a := authors[0]
+	if a.Policies != 2 || a.Drafts != 1 || a.Published != 1 || a.OverdueReviews != 0 || a.AvgDaysToPublish == nil {
+		t.Errorf("Ada = %+v; want 2 policies, 1 draft, 1 published, none overdue, a time to publish", a)
+	}
+	if b := authors[1]; b.Drafts != 1 || b.AvgDaysToPublish != nil {
+		t.Errorf("Bob = %+v; want 1 draft and no time to publish", b)
+	}
+	if authors := report(mw.RoleDeptAdmin, &eng.ID); len(authors) != 1 || authors[0].AuthorName != "Ada" {
+		t.Errorf("department report = %+v; want only Ada", authors)
+	}
+
+	authors, err := db.ListAuthorStats(ctx, nil, time.Now().Add(time.Hour))
+	if err != nil || authors[0].OverdueReviews != 1 {
+		t.Errorf("with every version past review: Ada = %+v, %v; want 1 overdue", authors[0], err)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/backup"
+	"policyflow/internal/database"
+	"policyflow/internal/storage"
+)
+
+// Backups triggers on-demand database backups to blob storage.
+type Backups struct {
+	db    *database.DB
+	store storage.Store
+}
+
+func NewBackups(db *database.DB, store storage.Store) *Backups {
+	return &Backups{db: db, store: store}
+}
+
+// Create snapshots the database into storage and returns the object key.
+// POST /api/admin/backups  (SuperAdmin only)
+func (h *Backups) Create(c echo.Context) error {
+	r, err := backup.Run(c.Request().Context(), h.db, h.store)
+	if err != nil {
+		log.Printf("backup: %v", err)
+		return apierr.New(http.StatusInternalServerError, "BACKUP_FAILED", "backup failed")
+	}
+	return c.JSON(http.StatusCreated, r)
+}
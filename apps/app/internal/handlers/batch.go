@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// Batch lookup limits: ?ids= is kept short enough for a URL; the POST
+// variants take longer lists.
+const (
+	maxQueryIDs = 100
+	maxBodyIDs  = 1000
+)
+
+// queryIDs parses a comma-separated ?ids= list, or returns nil if absent.
+func queryIDs(c echo.Context, max int) ([]string, error) {
+	raw := c.QueryParam("ids")
+	if raw == "" {
+		return nil, nil
+	}
+	ids := uniqueIDs(strings.Split(raw, ","))
+	if len(ids) > max {
+		return nil, apierr.Invalid(
+			"ids accepts at most "+strconv.Itoa(max)+" values; use the POST batch endpoint for longer lists", "ids")
+	}
+	return ids, nil
+}
+
+// bodyIDs reads {"ids": [...]} from a batch request body.
+func bodyIDs(c echo.Context) ([]string, error) {
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return nil, apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid request body")
+	}
+	return checkBodyIDs(req.IDs)
+}
+
+// checkBodyIDs dedupes the IDs from a request body and enforces the batch
+// limit.
+func checkBodyIDs(raw []string) ([]string, error) {
+	ids := uniqueIDs(raw)
+	if len(ids) == 0 {
+		return nil, apierr.Invalid("ids is required", "ids")
+	}
+	if len(ids) > maxBodyIDs {
+		return nil, apierr.Invalid("ids accepts at most "+strconv.Itoa(maxBodyIDs)+" values", "ids")
+	}
+	return ids, nil
+}
+
+// uniqueIDs trims the IDs and drops blanks and repeats, keeping order.
+func uniqueIDs(raw []string) []string {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, id := range raw {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// visiblePolicies loads the policies with the given IDs that the caller
+// may see.
+func (h *Policy) visiblePolicies(c echo.Context, ids []string) ([]*database.Policy, error) {
+	policies, err := h.db.GetPoliciesByIDs(c.Request().Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+	visible := policies[:0]
+	for _, p := range policies {
+		ok, err := h.canView(c, p)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, p)
+		}
+	}
+	return visible, nil
+}
+
+// Batch returns the users with the given IDs, skipping unknown IDs and, for
+// DeptAdmin, users outside their department.
+// POST /api/users/batch  {"ids": [...]}
+func (h *User) Batch(c echo.Context) error {
+	ids, err := bodyIDs(c)
+	if err != nil {
+		return err
+	}
+	return h.byIDs(c, ids)
+}
+
+func (h *User) byIDs(c echo.Context, ids []string) error {
+	users, err := h.db.GetUsersByIDs(c.Request().Context(), ids)
+	if err != nil {
+		return apierr.Database()
+	}
+	if deptID, _ := c.Get(mw.CtxDeptID).(*string); c.Get(mw.CtxUserRole) != mw.RoleSuperAdmin && deptID != nil {
+		scoped := users[:0]
+		for _, u := range users {
+			if sameDept(deptID, u.DepartmentID) {
+				scoped = append(scoped, u)
+			}
+		}
+		users = scoped
+	}
+	return c.JSON(http.StatusOK, users)
+}
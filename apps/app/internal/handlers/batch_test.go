@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestBatch_PoliciesAndUsers verifies batch lookups keep the requested
+// order and leave out unknown IDs and anything outside the caller's scope.
+func TestBatch_PoliciesAndUsers(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	org, _ := db.CreatePolicy(ctx, "Org", "", nil, "organization", nil)
+	engP, _ := db.CreatePolicy(ctx, "Eng", "", testutil.Ptr(eng.ID), "department", nil)
+	hrP, _ := db.CreatePolicy(ctx, "HR", "", testutil.Ptr(hr.ID), "department", nil)
+	alice, _ := db.CreateUser(ctx, "alice@example.com", "Alice", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	bob, _ := db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleStaff, nil, testutil.Ptr(hr.ID))
+
+	e := echo.New()
+	ph := NewPolicy(db)
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleStaff, testutil.Ptr(eng.ID))
+	c.Request().URL.RawQuery = "ids=" + strings.Join([]string{engP.ID, "missing", hrP.ID, org.ID, engP.ID}, ",")
+	if err := ph.List(c); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var policies []database.Policy
+	json.Unmarshal(rec.Body.Bytes(), &policies)
+	if len(policies) != 2 || policies[0].ID != engP.ID || policies[1].ID != org.ID {
+		t.Errorf("GET ?ids= = %+v; want [Eng Org]", policies)
+	}
+
+	c, _ = testutil.NewContext(e, http.MethodGet, "", "", mw.RoleStaff, nil)
+	c.Request().URL.RawQuery = "ids=" + org.ID + "&acknowledged=false"
+	var he *echo.HTTPError
+	if err := ph.List(c); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("ids with acknowledged: err = %v; want 400", err)
+	}
+
+	c, rec = testutil.NewContext(e, http.MethodPost, `{"ids":["`+hrP.ID+`","`+org.ID+`"]}`, "", mw.RoleSuperAdmin, nil)
+	if err := ph.Batch(c); err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	json.Unmarshal(rec.Body.Bytes(), &policies)
+	if len(policies) != 2 || policies[0].ID != hrP.ID {
+		t.Errorf("POST batch = %+v; want [HR Org]", policies)
+	}
+
+	uh := NewUser(db, email.New(), testutil.NewKeyring(t, db))
+	c, rec = testutil.NewContext(e, http.MethodPost, `{"ids":["`+bob.ID+`","`+alice.ID+`"]}`, "", mw.RoleDeptAdmin, testutil.Ptr(eng.ID))
+	if err := uh.Batch(c); err != nil {
+		t.Fatalf("users Batch: %v", err)
+	}
+	var users []database.User
+	json.Unmarshal(rec.Body.Bytes(), &users)
+	if len(users) != 1 || users[0].ID != alice.ID {
+		t.Errorf("DeptAdmin users batch = %+v; want only Alice", users)
+	}
+}
+
+// TestReadOnlyPosts_AllowedWhileImpersonating verifies that POSTs which only
+// read, the batch lookups and the report builder, work in a read-only
+// impersonation session while other POSTs are refused.
+func TestReadOnlyPosts_AllowedWhileImpersonating(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	root, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	dept, _ := db.CreateDepartment(ctx, "Ops", "")
+	head, _ := db.CreateUser(ctx, "head@example.com", "Head", mw.RoleDeptAdmin, nil, testutil.Ptr(dept.ID))
+	keys := testutil.NewKeyring(t, db)
+	token, _ := keys.Sign(jwt.MapClaims{
+		"sub": head.ID, "role": head.Role, "type": "session", "imp": root.ID,
+		"iat": time.Now().Unix(), "auth_time": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	e := echo.New()
+	post := func(path string) error {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetPath(path)
+		return mw.NewAuth(keys, db).Require(func(c echo.Context) error { return nil })(c)
+	}
+	for _, path := range []string{"/api/policies/batch", "/api/users/batch", "/api/admin/reports"} {
+		if err := post(path); err != nil {
+			t.Errorf("POST %s while impersonating: %v", path, err)
+		}
+	}
+	var he *echo.HTTPError
+	if err := post("/api/policies"); !errors.As(err, &he) || he.Code != http.StatusForbidden {
+		t.Errorf("POST /api/policies while impersonating: err = %v; want 403", err)
+	}
+}
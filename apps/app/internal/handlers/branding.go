@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/storage"
+)
+
+const (
+	logoKey      = "branding/logo"
+	maxLogoBytes = 1 << 20
+)
+
+var logoTypes = map[string]bool{"image/png": true, "image/jpeg": true, "image/webp": true, "image/gif": true}
+
+// Branding serves the organization logo shown in the app and on rendered
+// pages.
+type Branding struct {
+	db    *database.DB
+	store storage.Store
+}
+
+func NewBranding(db *database.DB, store storage.Store) *Branding {
+	return &Branding{db: db, store: store}
+}
+
+// Logo returns the uploaded logo, or 404 when none is set.
+// GET /api/branding/logo
+func (h *Branding) Logo(c echo.Context) error {
+	ctx := c.Request().Context()
+	contentType, err := h.db.GetSetting(ctx, database.SettingLogoContentType, "")
+	if err != nil {
+		return apierr.Database()
+	}
+	if contentType == "" {
+		return apierr.New(http.StatusNotFound, "LOGO_NOT_FOUND", "no logo uploaded")
+	}
+	rc, err := h.store.Get(ctx, logoKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return apierr.New(http.StatusNotFound, "LOGO_NOT_FOUND", "no logo uploaded")
+		}
+		log.Printf("logo: %v", err)
+		return apierr.New(http.StatusBadGateway, "STORAGE_ERROR", "storage error")
+	}
+	defer rc.Close()
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	c.Response().Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	return c.Stream(http.StatusOK, contentType, rc)
+}
+
+// UploadLogo replaces the logo with a multipart "file" image (PNG, JPEG,
+// WebP or GIF, up to 1 MB).
+// PUT /api/admin/branding/logo  (SuperAdmin only)
+func (h *Branding) UploadLogo(c echo.Context) error {
+	ctx := c.Request().Context()
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return apierr.Invalid("file is required", "file")
+	}
+	if fh.Size > maxLogoBytes {
+		return apierr.New(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "logo must be 1 MB or smaller")
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return apierr.New(http.StatusBadRequest, "INVALID_UPLOAD", "invalid upload")
+	}
+	defer f.Close()
+
+	// Trust the bytes, not the client's Content-Type.
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	contentType := http.DetectContentType(head[:n])
+	if !logoTypes[contentType] {
+		return apierr.New(http.StatusBadRequest, "UNSUPPORTED_FILE_TYPE", "logo must be a PNG, JPEG, WebP or GIF image")
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return apierr.New(http.StatusBadRequest, "INVALID_UPLOAD", "invalid upload")
+	}
+
+	if err := h.store.Put(ctx, logoKey, f, fh.Size, contentType); err != nil {
+		log.Printf("logo upload: %v", err)
+		return apierr.New(http.StatusBadGateway, "STORAGE_ERROR", "storage error")
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	if err := h.db.SetSetting(ctx, database.SettingLogoContentType, contentType, &userID); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// bulkStatusResult is one policy's outcome in a bulk status change.
+type bulkStatusResult struct {
+	ID     string           `json:"id"`
+	OK     bool             `json:"ok"`
+	Policy *database.Policy `json:"policy,omitempty"`
+	Error  *apierr.Body     `json:"error,omitempty"`
+}
+
+// BulkStatus moves many policies to one status, such as archiving a batch
+// of obsolete ones. Each policy is checked and updated on its own under the
+// same rules as Update, so one failure does not stop the rest; the response
+// reports every policy's outcome in request order. With ?dry_run=true every
+// policy is checked the same way but none is changed; results show each
+// policy as it would be.
+// POST /api/admin/policies/bulk-status?dry_run=  {"ids": [...], "status": "Archived"}
+func (h *Policy) BulkStatus(c echo.Context) error {
+	dryRun := c.QueryParam("dry_run") == "true"
+	var req struct {
+		IDs    []string `json:"ids"`
+		Status string   `json:"status"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid request body")
+	}
+	ids, err := checkBodyIDs(req.IDs)
+	if err != nil {
+		return err
+	}
+	if !policyStatuses[req.Status] {
+		return apierr.Invalid("status must be Draft, Review, Published, or Archived", "status")
+	}
+
+	policies, err := h.db.GetPoliciesByIDs(c.Request().Context(), ids)
+	if err != nil {
+		return apierr.Database()
+	}
+	byID := make(map[string]*database.Policy, len(policies))
+	for _, p := range policies {
+		byID[p.ID] = p
+	}
+
+	results := make([]bulkStatusResult, len(ids))
+	updated, failed := 0, 0
+	for i, id := range ids {
+		res := bulkStatusResult{ID: id}
+		p, err := h.setStatus(c, byID[id], req.Status, dryRun)
+		if err != nil {
+			res.Error = apierr.From(err)
+			failed++
+		} else {
+			res.OK, res.Policy = true, p
+			updated++
+		}
+		results[i] = res
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"updated": updated,
+		"failed":  failed,
+		"results": results,
+		"dry_run": dryRun,
+	})
+}
+
+// setStatus moves policy to status for BulkStatus. The policy's version at
+// load time stands in for the client's expected version, so a concurrent
+// edit fails this policy rather than being overwritten. With dryRun it
+// stops after the checks and returns the policy as it would be.
+func (h *Policy) setStatus(c echo.Context, policy *database.Policy, status string, dryRun bool) (*database.Policy, error) {
+	if policy == nil {
+		return nil, apierr.New(http.StatusNotFound, "POLICY_NOT_FOUND", "policy not found")
+	}
+	if err := canManage(c, policy); err != nil {
+		return nil, err
+	}
+	if policy.Status == status {
+		return policy, nil
+	}
+	if err := h.checkStatusChange(c, policy, status); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		would := *policy
+		would.Status = status
+		return &would, nil
+	}
+
+	ctx := c.Request().Context()
+	userID := c.Get(mw.CtxUserID).(string)
+	err := h.db.UpdatePolicyIfVersion(ctx, policy.ID, policy.Version, &userID, policy.Title, status, policy.Department, policy.DepartmentID, policy.VisibilityType)
+	if errors.Is(err, database.ErrVersionConflict) {
+		return nil, apierr.New(http.StatusConflict, "POLICY_MODIFIED", "policy was modified by someone else; reload and try again")
+	}
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	updated, err := h.db.GetPolicy(ctx, policy.ID)
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	h.statusChanged(c, policy, updated)
+	return updated, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestBulkStatus_PerItemResults verifies that a bulk archive updates the
+// policies the caller may edit and reports the others individually.
+func TestBulkStatus_PerItemResults(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	admin, _ := db.CreateUser(ctx, "admin@example.com", "Admin", mw.RoleDeptAdmin, nil, testutil.Ptr(eng.ID))
+	a, _ := db.CreatePolicy(ctx, "A", "", testutil.Ptr(eng.ID), "department", nil)
+	b, _ := db.CreatePolicy(ctx, "B", "", testutil.Ptr(eng.ID), "department", nil)
+	other, _ := db.CreatePolicy(ctx, "Other", "", testutil.Ptr(hr.ID), "department", nil)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	body := fmt.Sprintf(`{"ids":[%q,%q,"missing",%q],"status":"Archived"}`, a.ID, other.ID, b.ID)
+	c, rec := testutil.NewContext(e, http.MethodPost, body, "", mw.RoleDeptAdmin, testutil.Ptr(eng.ID))
+	c.Set(mw.CtxUserID, admin.ID)
+	if err := h.BulkStatus(c); err != nil {
+		t.Fatalf("BulkStatus: %v", err)
+	}
+	var resp struct {
+		Updated int `json:"updated"`
+		Failed  int `json:"failed"`
+		Results []struct {
+			ID    string `json:"id"`
+			OK    bool   `json:"ok"`
+			Error *struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		} `json:"results"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Updated != 2 || resp.Failed != 2 || len(resp.Results) != 4 {
+		t.Fatalf("response = %+v; want 2 updated, 2 failed", resp)
+	}
+	for i, want := range []string{"", "OUTSIDE_DEPARTMENT", "POLICY_NOT_FOUND", ""} {
+		r := resp.Results[i]
+		if want == "" && !r.OK || want != "" && (r.OK || r.Error == nil || r.Error.Code != want) {
+			t.Errorf("result %d = %+v; want %q", i, r, want)
+		}
+	}
+	for _, p := range []string{a.ID, b.ID} {
+		if got, _ := db.GetPolicy(ctx, p); got.Status != "Archived" {
+			t.Errorf("policy %s status = %s; want Archived", got.Title, got.Status)
+		}
+	}
+	if got, _ := db.GetPolicy(ctx, other.ID); got.Status == "Archived" {
+		t.Error("policy outside the department was archived")
+	}
+}
+
+// TestBulkStatus_DryRun verifies that a dry run reports the same outcomes
+// as a real change but leaves every policy as it was.
+func TestBulkStatus_DryRun(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	a, _ := db.CreatePolicy(ctx, "A", "", testutil.Ptr(eng.ID), "department", nil)
+	other, _ := db.CreatePolicy(ctx, "Other", "", testutil.Ptr(hr.ID), "department", nil)
+
+	body := fmt.Sprintf(`{"ids":[%q,%q],"status":"Archived"}`, a.ID, other.ID)
+	c, rec := testutil.NewContext(echo.New(), http.MethodPost, body, "", mw.RoleDeptAdmin, testutil.Ptr(eng.ID))
+	c.QueryParams().Set("dry_run", "true")
+	if err := NewPolicy(db).BulkStatus(c); err != nil {
+		t.Fatalf("BulkStatus: %v", err)
+	}
+	var resp struct {
+		Updated int  `json:"updated"`
+		Failed  int  `json:"failed"`
+		DryRun  bool `json:"dry_run"`
+		Results []struct {
+			Policy *struct {
+				Status string `json:"status"`
+			} `json:"policy"`
+		} `json:"results"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if !resp.DryRun || resp.Updated != 1 || resp.Failed != 1 || resp.Results[0].Policy.Status != "Archived" {
+		t.Fatalf("response = %+v; want A to be archived and Other refused", resp)
+	}
+	if got, _ := db.GetPolicy(ctx, a.ID); got.Status != "Draft" {
+		t.Errorf("A status = %s after a dry run; want Draft", got.Status)
+	}
+}
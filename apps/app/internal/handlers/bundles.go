@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// maxBundlePolicies bounds the policies in one bundle.
+const maxBundlePolicies = 200
+
+// Bundles manages onboarding packets: named sets of policies assigned to
+// new users by department and role.
+type Bundles struct {
+	db *database.DB
+}
+
+func NewBundles(db *database.DB) *Bundles {
+	return &Bundles{db: db}
+}
+
+// List returns every bundle with its policies and how many users it has
+// been applied to.
+// GET /api/admin/bundles  (SuperAdmin only)
+func (h *Bundles) List(c echo.Context) error {
+	bundles, err := h.db.ListBundles(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	if bundles == nil {
+		bundles = []*database.PolicyBundle{}
+	}
+	return c.JSON(http.StatusOK, bundles)
+}
+
+type bundleRequest struct {
+	Name         *string   `json:"name"`
+	Description  *string   `json:"description"`
+	DepartmentID *string   `json:"department_id"` // "" = any department
+	Role         *string   `json:"role"`          // "" = any role
+	PolicyIDs    *[]string `json:"policy_ids"`
+}
+
+// Create adds a bundle. It is applied to users created from then on; use
+// Assign for existing users.
+// POST /api/admin/bundles  (SuperAdmin only)
+func (h *Bundles) Create(c echo.Context) error {
+	b := &database.PolicyBundle{}
+	userID := c.Get(mw.CtxUserID).(string)
+	b.CreatedBy = &userID
+	return h.save(c, b, nil, http.StatusCreated)
+}
+
+// Update changes a bundle; omitted fields keep their values.
+// PUT /api/admin/bundles/:id  (SuperAdmin only)
+func (h *Bundles) Update(c echo.Context) error {
+	b, err := h.load(c)
+	if err != nil {
+		return err
+	}
+	policyIDs := make([]string, len(b.Policies))
+	for i, p := range b.Policies {
+		policyIDs[i] = p.ID
+	}
+	return h.save(c, b, policyIDs, http.StatusOK)
+}
+
+func (h *Bundles) save(c echo.Context, b *database.PolicyBundle, policyIDs []string, status int) error {
+	ctx := c.Request().Context()
+	var req bundleRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if req.Name != nil {
+		b.Name = strings.TrimSpace(*req.Name)
+	}
+	if b.Name == "" || len(b.Name) > 200 {
+		return apierr.Invalid("name is required and must be under 200 characters", "name")
+	}
+	if req.Description != nil {
+		b.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.DepartmentID != nil {
+		b.DepartmentID = nil
+		if *req.DepartmentID != "" {
+			if _, err := h.db.GetDepartment(ctx, *req.DepartmentID); errors.Is(err, sql.ErrNoRows) {
+				return apierr.Invalid("unknown department", "department_id")
+			} else if err != nil {
+				return apierr.Database()
+			}
+			b.DepartmentID = req.DepartmentID
+		}
+	}
+	if req.Role != nil {
+		b.Role = nil
+		switch *req.Role {
+		case "":
+		case mw.RoleSuperAdmin, mw.RoleDeptAdmin, mw.RoleStaff:
+			b.Role = req.Role
+		default:
+			return apierr.Invalid("role must be SuperAdmin, DeptAdmin, or Staff", "role")
+		}
+	}
+	if req.PolicyIDs != nil {
+		policyIDs = nil
+		for _, id := range *req.PolicyIDs {
+			if !slices.Contains(policyIDs, id) {
+				policyIDs = append(policyIDs, id)
+			}
+		}
+	}
+	if len(policyIDs) == 0 || len(policyIDs) > maxBundlePolicies {
+		return apierr.Invalid("a bundle needs 1-200 policies", "policy_ids")
+	}
+	for _, id := range policyIDs {
+		if _, err := h.db.GetPolicy(ctx, id); errors.Is(err, sql.ErrNoRows) {
+			return apierr.Invalid("unknown policy: "+id, "policy_ids")
+		} else if err != nil {
+			return apierr.Database()
+		}
+	}
+
+	err := h.db.SaveBundle(ctx, b, policyIDs)
+	if errors.Is(err, database.ErrBundleNameTaken) {
+		return apierr.New(http.StatusConflict, "BUNDLE_NAME_TAKEN", "a bundle with that name already exists")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	saved, err := h.db.GetBundle(ctx, b.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(status, saved)
+}
+
+// Delete removes a bundle. Policies it assigned stay assigned.
+// DELETE /api/admin/bundles/:id  (SuperAdmin only)
+func (h *Bundles) Delete(c echo.Context) error {
+	if _, err := h.load(c); err != nil {
+		return err
+	}
+	if err := h.db.DeleteBundle(c.Request().Context(), c.Param("id")); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Assignments lists the users a bundle has been applied to, newest first.
+// GET /api/admin/bundles/:id/assignments  (SuperAdmin only)
+func (h *Bundles) Assignments(c echo.Context) error {
+	if _, err := h.load(c); err != nil {
+		return err
+	}
+	list, err := h.db.ListBundleAssignments(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return apierr.Database()
+	}
+	if list == nil {
+		list = []*database.BundleAssignment{}
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// Assign applies a bundle to existing users, whatever their department and
+// role. Users it was already applied to are reported as skipped.
+// POST /api/admin/bundles/:id/assignments  {"user_ids": [...]}  (SuperAdmin only)
+func (h *Bundles) Assign(c echo.Context) error {
+	ctx := c.Request().Context()
+	b, err := h.load(c)
+	if err != nil {
+		return err
+	}
+	var req struct {
+		UserIDs []string `json:"user_ids"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if len(req.UserIDs) == 0 || len(req.UserIDs) > maxBodyIDs {
+		return apierr.Invalid("user_ids must list 1-1000 users", "user_ids")
+	}
+	users := make([]*database.User, 0, len(req.UserIDs))
+	for _, id := range req.UserIDs {
+		u, err := h.db.GetUserByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.Invalid("unknown user: "+id, "user_ids")
+		}
+		if err != nil {
+			return apierr.Database()
+		}
+		users = append(users, u)
+	}
+	assignedBy := c.Get(mw.CtxUserID).(string)
+	assigned, skipped := []string{}, []string{}
+	for _, u := range users {
+		ok, err := h.db.AssignBundle(ctx, b, u, &assignedBy)
+		if err != nil {
+			return apierr.Database()
+		}
+		if ok {
+			assigned = append(assigned, u.ID)
+		} else {
+			skipped = append(skipped, u.ID)
+		}
+	}
+	return c.JSON(http.StatusOK, map[string][]string{"assigned": assigned, "skipped": skipped})
+}
+
+// load loads the :id bundle, mapping a missing one to 404.
+func (h *Bundles) load(c echo.Context) (*database.PolicyBundle, error) {
+	b, err := h.db.GetBundle(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apierr.New(http.StatusNotFound, "BUNDLE_NOT_FOUND", "bundle not found")
+	}
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	return b, nil
+}
+
+// onboardUser applies the bundles matching a user who is new or has just
+// moved department, and queues the email listing the policies awaiting them
+// there. The change is already saved, so failures are logged rather than
+// returned.
+func onboardUser(c echo.Context, db *database.DB, user *database.User) {
+	ctx := c.Request().Context()
+	if _, err := db.AssignOnboardingBundles(ctx, user); err != nil {
+		log.Printf("onboarding bundles for %s: %v", user.ID, err)
+	}
+	if err := db.QueueDepartmentWelcome(ctx, user); err != nil {
+		log.Printf("department welcome for %s: %v", user.ID, err)
+	}
+}
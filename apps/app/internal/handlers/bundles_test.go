@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestBundles_AssignedOnUserCreation verifies a new user gets the policies
+// of the bundles matching their department and role, that a department
+// policy keeps applying to its own department, and that the assignment is
+// tracked.
+func TestBundles_AssignedOnUserCreation(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	root, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	engineer, _ := db.CreateUser(ctx, "eng@example.com", "Engineer", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	conduct, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	onCall, _ := db.CreatePolicy(ctx, "On-call", "", testutil.Ptr(eng.ID), "department", nil)
+	for _, p := range []*database.Policy{conduct, onCall} {
+		testutil.Publish(t, db, p)
+	}
+
+	e := echo.New()
+	bundles := NewBundles(db)
+	create := func(body string) *database.PolicyBundle {
+		c, rec := testutil.NewContext(e, http.MethodPost, body, "", mw.RoleSuperAdmin, nil)
+		c.Set(mw.CtxUserID, root.ID)
+		if err := bundles.Create(c); err != nil {
+			t.Fatalf("Create bundle: %v", err)
+		}
+		var b database.PolicyBundle
+		json.Unmarshal(rec.Body.Bytes(), &b)
+		return &b
+	}
+	packet := create(`{"name":"New Hire Packet","policy_ids":["` + conduct.ID + `","` + onCall.ID + `"]}`)
+	create(`{"name":"Manager Essentials","role":"DeptAdmin","policy_ids":["` + conduct.ID + `"]}`)
+
+	c, rec := testutil.NewContext(e, http.MethodPost, `{"email":"new@example.com","name":"New Hire","department_id":"`+ops.ID+`"}`, "", mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, root.ID)
+	if err := NewUser(db, email.New(), testutil.NewKeyring(t, db)).Create(c); err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+	var hire database.User
+	json.Unmarshal(rec.Body.Bytes(), &hire)
+
+	titles := func(u *database.User) []string {
+		ps, _ := db.ListRequiredPoliciesForUser(ctx, u.ID, u.Role, u.DepartmentID)
+		out := []string{}
+		for _, p := range ps {
+			out = append(out, p.Title)
+		}
+		return out
+	}
+	if got := titles(&hire); len(got) != 2 {
+		t.Errorf("new hire required = %v; want Code of Conduct and On-call", got)
+	}
+	if got := titles(engineer); len(got) != 2 {
+		t.Errorf("engineer required = %v; On-call must still apply to Engineering", got)
+	}
+
+	c, rec = testutil.NewContext(e, http.MethodGet, "", packet.ID, mw.RoleSuperAdmin, nil)
+	if err := bundles.Assignments(c); err != nil {
+		t.Fatalf("Assignments: %v", err)
+	}
+	var assigned []database.BundleAssignment
+	json.Unmarshal(rec.Body.Bytes(), &assigned)
+	if len(assigned) != 1 || assigned[0].UserID != hire.ID || assigned[0].AssignedBy != nil {
+		t.Errorf("packet assignments = %+v; want the new hire, automatically", assigned)
+	}
+	c, rec = testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	bundles.List(c)
+	var list []database.PolicyBundle
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list) != 2 || list[0].Name != "Manager Essentials" || list[0].AssignedCount != 0 || list[1].AssignedCount != 1 {
+		t.Errorf("bundles = %+v", list)
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// maxCampaignReminders bounds a campaign's reminder days.
+const maxCampaignReminders = 10
+
+// Campaigns manages acknowledgement campaigns: a set of policies an
+// audience is asked to acknowledge by a deadline, with reminder emails,
+// tracked as one unit.
+type Campaigns struct {
+	db *database.DB
+}
+
+func NewCampaigns(db *database.DB) *Campaigns {
+	return &Campaigns{db: db}
+}
+
+// List returns every campaign, the latest deadline first.
+// GET /api/admin/campaigns  (DeptAdmin+)
+func (h *Campaigns) List(c echo.Context) error {
+	campaigns, err := h.db.ListCampaigns(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	if campaigns == nil {
+		campaigns = []*database.Campaign{}
+	}
+	return c.JSON(http.StatusOK, campaigns)
+}
+
+// Get returns one campaign with its policies.
+// GET /api/admin/campaigns/:id  (DeptAdmin+)
+func (h *Campaigns) Get(c echo.Context) error {
+	campaign, err := h.load(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, campaign)
+}
+
+type campaignRequest struct {
+	Name         *string   `json:"name"`
+	Description  *string   `json:"description"`
+	StartsAt     *string   `json:"starts_at"` // YYYY-MM-DD (midnight UTC) or RFC3339; default now
+	Deadline     *string   `json:"deadline"`  // YYYY-MM-DD (end of day UTC) or RFC3339
+	AudienceType *string   `json:"audience_type"`
+	AudienceID   *string   `json:"audience_id"` // department ID or role
+	ReminderDays *[]int    `json:"reminder_days"`
+	PolicyIDs    *[]string `json:"policy_ids"`
+}
+
+// Create adds a campaign. Its first emails go out when it starts.
+// POST /api/admin/campaigns  (SuperAdmin only)
+func (h *Campaigns) Create(c echo.Context) error {
+	campaign := &database.Campaign{StartsAt: time.Now().UTC().Truncate(time.Second), AudienceType: database.AudienceOrganization}
+	userID := c.Get(mw.CtxUserID).(string)
+	campaign.CreatedBy = &userID
+	return h.save(c, campaign, nil, http.StatusCreated)
+}
+
+// Update changes a campaign; omitted fields keep their values. Reminder
+// stages already sent are not sent again.
+// PUT /api/admin/campaigns/:id  (SuperAdmin only)
+func (h *Campaigns) Update(c echo.Context) error {
+	campaign, err := h.load(c)
+	if err != nil {
+		return err
+	}
+	policyIDs := make([]string, len(campaign.Policies))
+	for i, p := range campaign.Policies {
+		policyIDs[i] = p.ID
+	}
+	return h.save(c, campaign, policyIDs, http.StatusOK)
+}
+
+func (h *Campaigns) save(c echo.Context, campaign *database.Campaign, policyIDs []string, status int) error {
+	ctx := c.Request().Context()
+	var req campaignRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if req.Name != nil {
+		campaign.Name = strings.TrimSpace(*req.Name)
+	}
+	if campaign.Name == "" || len(campaign.Name) > 200 {
+		return apierr.Invalid("name is required and must be under 200 characters", "name")
+	}
+	if req.Description != nil {
+		campaign.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.StartsAt != nil {
+		t, err := time.Parse("2006-01-02", *req.StartsAt)
+		if err != nil {
+			t, err = time.Parse(time.RFC3339, *req.StartsAt)
+		}
+		if err != nil {
+			return apierr.Invalid("starts_at must be YYYY-MM-DD or RFC3339", "starts_at")
+		}
+		campaign.StartsAt = t.UTC()
+	}
+	if req.Deadline != nil {
+		deadline, err := parseDeadline(*req.Deadline)
+		if err != nil || deadline == nil {
+			return apierr.Invalid("deadline must be YYYY-MM-DD or RFC3339", "deadline")
+		}
+		campaign.Deadline = *deadline
+	}
+	if campaign.Deadline.IsZero() {
+		return apierr.Invalid("deadline is required", "deadline")
+	}
+	if !campaign.Deadline.After(campaign.StartsAt) {
+		return apierr.Invalid("deadline must be after starts_at", "deadline")
+	}
+
+	if req.AudienceType != nil {
+		campaign.AudienceType, campaign.AudienceID = *req.AudienceType, req.AudienceID
+	}
+	switch campaign.AudienceType {
+	case database.AudienceOrganization:
+		campaign.AudienceID = nil
+	case database.AudienceDepartment:
+		if campaign.AudienceID == nil {
+			return apierr.Invalid("audience_id must name the department", "audience_id")
+		}
+		if _, err := h.db.GetDepartment(ctx, *campaign.AudienceID); errors.Is(err, sql.ErrNoRows) {
+			return apierr.Invalid("unknown department", "audience_id")
+		} else if err != nil {
+			return apierr.Database()
+		}
+	case database.AudienceRole:
+		if campaign.AudienceID == nil || !slices.Contains([]string{mw.RoleSuperAdmin, mw.RoleDeptAdmin, mw.RoleStaff}, *campaign.AudienceID) {
+			return apierr.Invalid("audience_id must be SuperAdmin, DeptAdmin, or Staff", "audience_id")
+		}
+	default:
+		return apierr.Invalid("audience_type must be organization, department, or role", "audience_type")
+	}
+
+	if req.ReminderDays != nil {
+		campaign.ReminderDays = *req.ReminderDays
+	}
+	if len(campaign.ReminderDays) > maxCampaignReminders {
+		return apierr.Invalid("at most 10 reminder days", "reminder_days")
+	}
+	for _, d := range campaign.ReminderDays {
+		if d < 1 || d > 365 {
+			return apierr.Invalid("reminder days must be 1-365 days before the deadline", "reminder_days")
+		}
+	}
+
+	if req.PolicyIDs != nil {
+		policyIDs = nil
+		for _, id := range *req.PolicyIDs {
+			if !slices.Contains(policyIDs, id) {
+				policyIDs = append(policyIDs, id)
+			}
+		}
+	}
+	if len(policyIDs) == 0 || len(policyIDs) > maxBundlePolicies {
+		return apierr.Invalid("a campaign needs 1-200 policies", "policy_ids")
+	}
+	for _, id := range policyIDs {
+		if _, err := h.db.GetPolicy(ctx, id); errors.Is(err, sql.ErrNoRows) {
+			return apierr.Invalid("unknown policy: "+id, "policy_ids")
+		} else if err != nil {
+			return apierr.Database()
+		}
+	}
+
+	err := h.db.SaveCampaign(ctx, campaign, policyIDs)
+	if errors.Is(err, database.ErrCampaignNameTaken) {
+		return apierr.New(http.StatusConflict, "CAMPAIGN_NAME_TAKEN", "a campaign with that name already exists")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	saved, err := h.db.GetCampaign(ctx, campaign.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(status, saved)
+}
+
+// Delete removes a campaign. Acknowledgements given during it are kept.
+// DELETE /api/admin/campaigns/:id  (SuperAdmin only)
+func (h *Campaigns) Delete(c echo.Context) error {
+	if _, err := h.load(c); err != nil {
+		return err
+	}
+	if err := h.db.DeleteCampaign(c.Request().Context(), c.Param("id")); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Progress counts the campaign's acknowledgements so far, overall, per
+// policy, and per department. It is computed on each request, so a
+// dashboard can poll it. A DeptAdmin sees their own department only.
+// GET /api/admin/campaigns/:id/progress  (DeptAdmin+)
+func (h *Campaigns) Progress(c echo.Context) error {
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+	campaign, err := h.load(c)
+	if err != nil {
+		return err
+	}
+	progress, err := h.db.Reader().GetCampaignProgress(c.Request().Context(), campaign, deptID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, progress)
+}
+
+// load loads the :id campaign, mapping a missing one to 404.
+func (h *Campaigns) load(c echo.Context) (*database.Campaign, error) {
+	campaign, err := h.db.GetCampaign(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apierr.New(http.StatusNotFound, "CAMPAIGN_NOT_FOUND", "campaign not found")
+	}
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	return campaign, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestCampaigns_Progress verifies that a campaign counts only its audience
+// and the policies required of each member, and that a DeptAdmin's view is
+// limited to their department.
+func TestCampaigns_Progress(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	root, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	dev1, _ := db.CreateUser(ctx, "dev1@example.com", "Dev One", mw.RoleStaff, nil, &eng.ID)
+	db.CreateUser(ctx, "dev2@example.com", "Dev Two", mw.RoleStaff, nil, &eng.ID)
+	clerk, _ := db.CreateUser(ctx, "clerk@example.com", "Clerk", mw.RoleStaff, nil, &ops.ID)
+	conduct, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	onCall, _ := db.CreatePolicy(ctx, "On-call", "", &eng.ID, "department", nil)
+	for _, p := range []*database.Policy{conduct, onCall} {
+		testutil.Publish(t, db, p)
+	}
+	conduct, _ = db.GetPolicy(ctx, conduct.ID)
+	onCall, _ = db.GetPolicy(ctx, onCall.ID)
+	db.CreateAcknowledgement(ctx, dev1.ID, *conduct.CurrentVersionID)
+	db.CreateAcknowledgement(ctx, dev1.ID, *onCall.CurrentVersionID)
+	db.CreateAcknowledgement(ctx, clerk.ID, *conduct.CurrentVersionID)
+
+	e := echo.New()
+	h := NewCampaigns(db)
+	body := `{"name":"2026 Refresh","deadline":"2099-12-31","reminder_days":[1,14,7,14],"audience_type":"role","audience_id":"Staff",
+		"policy_ids":["` + conduct.ID + `","` + onCall.ID + `"]}`
+	c, rec := testutil.NewContext(e, http.MethodPost, body, "", mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, root.ID)
+	if err := h.Create(c); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	var campaign database.Campaign
+	json.Unmarshal(rec.Body.Bytes(), &campaign)
+	if campaign.Status != database.CampaignActive || len(campaign.ReminderDays) != 3 || campaign.ReminderDays[0] != 14 {
+		t.Errorf("campaign = %+v; want active with reminder days 14, 7, 1", campaign)
+	}
+
+	progress := func(role string, deptID *string) database.CampaignProgress {
+		t.Helper()
+		c, rec := testutil.NewContext(e, http.MethodGet, "", campaign.ID, role, deptID)
+		if err := h.Progress(c); err != nil {
+			t.Fatalf("Progress: %v", err)
+		}
+		var p database.CampaignProgress
+		json.Unmarshal(rec.Body.Bytes(), &p)
+		return p
+	}
+	// Root is not Staff; the clerk owes only the Code of Conduct.
+	p := progress(mw.RoleSuperAdmin, nil)
+	if p.Users != 3 || p.CompletedUsers != 2 || p.Required != 5 || p.Acknowledged != 3 || p.Percent != 60 {
+		t.Errorf("progress = %+v; want 3 users, 2 done, 3 of 5 acknowledged", p)
+	}
+	if len(p.ByPolicy) != 2 || p.ByPolicy[1].PolicyTitle != "On-call" || p.ByPolicy[1].Required != 2 || p.ByPolicy[1].Acknowledged != 1 {
+		t.Errorf("by policy = %+v", p.ByPolicy)
+	}
+	if len(p.ByDepartment) != 2 || p.ByDepartment[0].Department != "Engineering" || p.ByDepartment[0].CompletedUsers != 1 {
+		t.Errorf("by department = %+v", p.ByDepartment)
+	}
+	if p := progress(mw.RoleDeptAdmin, &ops.ID); p.Users != 1 || p.CompletedUsers != 1 || len(p.ByDepartment) != 1 {
+		t.Errorf("operations admin sees %+v; want the clerk only", p)
+	}
+
+	c, _ = testutil.NewContext(e, http.MethodPut, `{"deadline":"2000-01-01"}`, campaign.ID, mw.RoleSuperAdmin, nil)
+	if err := h.Update(c); err == nil {
+		t.Error("Update with a deadline before the start succeeded")
+	}
+}
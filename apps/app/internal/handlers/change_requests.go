@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/changelog"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// ChangeRequests lists a policy's change requests, optionally filtered with
+// ?status=open|resolved|dismissed.
+// GET /api/policies/:id/change-requests
+func (h *Policy) ChangeRequests(c echo.Context) error {
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	status := c.QueryParam("status")
+	switch status {
+	case "", database.ChangeRequestOpen, database.ChangeRequestResolved, database.ChangeRequestDismissed:
+	default:
+		return apierr.Invalid("status must be open, resolved, or dismissed", "status")
+	}
+	out, err := h.db.ListChangeRequests(c.Request().Context(), policy.ID, status)
+	if err != nil {
+		return apierr.Database()
+	}
+	if out == nil {
+		out = []*database.ChangeRequest{}
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+// CreateChangeRequest files a reviewer's change request against one section
+// of the current version. Only policies in Review accept change requests.
+// POST /api/policies/:id/change-requests
+func (h *Policy) CreateChangeRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	if policy.Status != "Review" {
+		return apierr.New(http.StatusConflict, "POLICY_NOT_IN_REVIEW", "change requests can only be filed while a policy is in review")
+	}
+	var body struct {
+		Section string `json:"section"`
+		Comment string `json:"comment"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	body.Section, body.Comment = strings.TrimSpace(body.Section), strings.TrimSpace(body.Comment)
+	if body.Section == "" || body.Comment == "" {
+		return apierr.Invalid("section and comment are required", "section", "comment")
+	}
+	if policy.CurrentVersionID != nil {
+		v, err := h.db.GetPolicyVersion(ctx, *policy.CurrentVersionID)
+		if err != nil {
+			return apierr.Database()
+		}
+		if !slices.Contains(changelog.Sections(v.Content), body.Section) {
+			return apierr.New(http.StatusBadRequest, "SECTION_NOT_FOUND", "section not found in the current version")
+		}
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	cr, err := h.db.CreateChangeRequest(ctx, policy.ID, policy.CurrentVersionID, body.Section, body.Comment, &userID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusCreated, cr)
+}
+
+// CloseChangeRequest resolves or dismisses an open change request. Its
+// author and anyone who manages the policy may mark it resolved; only the
+// policy owner (or SuperAdmin) may dismiss it without a change.
+// PUT /api/policies/:id/change-requests/:requestId
+func (h *Policy) CloseChangeRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	cr, err := h.db.GetChangeRequest(ctx, c.Param("requestId"))
+	if err != nil || cr.PolicyID != policy.ID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "CHANGE_REQUEST_NOT_FOUND", "change request not found")
+		}
+		return apierr.Database()
+	}
+	var body struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	role := c.Get(mw.CtxUserRole).(string)
+	isOwner := role == mw.RoleSuperAdmin || (policy.CreatedBy != nil && *policy.CreatedBy == userID)
+	switch body.Status {
+	case database.ChangeRequestResolved:
+		isAuthor := cr.CreatedBy != nil && *cr.CreatedBy == userID
+		deptID, _ := c.Get(mw.CtxDeptID).(*string)
+		manages := role == mw.RoleDeptAdmin && sameDept(deptID, policy.DepartmentID)
+		if !isOwner && !isAuthor && !manages {
+			return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "only the author or a policy manager can resolve a change request")
+		}
+	case database.ChangeRequestDismissed:
+		if !isOwner {
+			return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "only the policy owner can dismiss a change request")
+		}
+	default:
+		return apierr.Invalid("status must be resolved or dismissed", "status")
+	}
+
+	err = h.db.CloseChangeRequest(ctx, cr.ID, body.Status, userID, strings.TrimSpace(body.Note))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusConflict, "CHANGE_REQUEST_CLOSED", "change request is already closed")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	cr, err = h.db.GetChangeRequest(ctx, cr.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, cr)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestChangeRequests_BlockPublishing verifies that an open change request
+// blocks publishing until it is resolved or dismissed, and that only the
+// policy owner can dismiss one.
+func TestChangeRequests_BlockPublishing(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	owner, _ := db.CreateUser(ctx, "owner@example.com", "Owner", mw.RoleSuperAdmin, nil, nil)
+	reviewer, _ := db.CreateUser(ctx, "reviewer@example.com", "Reviewer", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", &owner.ID)
+	v, _ := db.CreatePolicyVersion(ctx, p.ID, "Intro\n\n## Gifts\n\nNo gifts.\n\n## Conflicts\n\nDeclare them.", "v1.0.0", "", nil)
+	db.SetPolicyCurrentVersion(ctx, p.ID, v.ID)
+	db.UpdatePolicy(ctx, p.ID, p.Title, "Review", "", nil, "organization")
+
+	e := echo.New()
+	h := NewPolicy(db)
+	as := func(u *database.User, body string) (echo.Context, func() []byte) {
+		c, rec := testutil.NewContext(e, http.MethodPost, body, p.ID, u.Role, u.DepartmentID)
+		c.Set(mw.CtxUserID, u.ID)
+		return c, func() []byte { return rec.Body.Bytes() }
+	}
+	var he *echo.HTTPError
+
+	c, _ := as(reviewer, `{"section":"Travel","comment":"?"}`)
+	if err := h.CreateChangeRequest(c); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("unknown section: got %v; want 400", err)
+	}
+	var crs [2]database.ChangeRequest
+	for i, section := range []string{"Gifts", "Conflicts"} {
+		c, body := as(reviewer, `{"section":"`+section+`","comment":"Please clarify"}`)
+		if err := h.CreateChangeRequest(c); err != nil {
+			t.Fatalf("CreateChangeRequest: %v", err)
+		}
+		json.Unmarshal(body(), &crs[i])
+	}
+
+	publishPolicy := func() error {
+		cur, _ := db.GetPolicy(ctx, p.ID)
+		c, _ := as(owner, `{"status":"Published","expected_version":`+strconv.Itoa(cur.Version)+`}`)
+		return h.Update(c)
+	}
+	if err := publishPolicy(); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Fatalf("publish with open change requests: got %v; want 409", err)
+	}
+
+	closeCR := func(u *database.User, id, status string) error {
+		c, _ := as(u, `{"status":"`+status+`"}`)
+		c.SetParamNames("id", "requestId")
+		c.SetParamValues(p.ID, id)
+		return h.CloseChangeRequest(c)
+	}
+	if err := closeCR(reviewer, crs[0].ID, database.ChangeRequestResolved); err != nil {
+		t.Fatalf("resolve by author: %v", err)
+	}
+	if err := closeCR(reviewer, crs[1].ID, database.ChangeRequestDismissed); !errors.As(err, &he) || he.Code != http.StatusForbidden {
+		t.Errorf("dismiss by reviewer: got %v; want 403", err)
+	}
+	if err := closeCR(owner, crs[1].ID, database.ChangeRequestDismissed); err != nil {
+		t.Fatalf("dismiss by owner: %v", err)
+	}
+	if err := publishPolicy(); err != nil {
+		t.Errorf("publish after closing change requests: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/hris"
+	mw "policyflow/internal/middleware"
+)
+
+const maxClaimValueLen = 200
+
+// ClaimRules lets a SuperAdmin map HR attributes (department, OU, job
+// title, groups) to roles and departments, which HRIS syncs and the
+// provisioning webhook then apply.
+type ClaimRules struct {
+	db *database.DB
+}
+
+func NewClaimRules(db *database.DB) *ClaimRules {
+	return &ClaimRules{db: db}
+}
+
+// List returns the claim rules in the order they are tried.
+// GET /api/admin/claim-rules  (SuperAdmin only)
+func (h *ClaimRules) List(c echo.Context) error {
+	rules, err := h.db.ListClaimRules(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	if rules == nil {
+		rules = []*database.ClaimRule{}
+	}
+	return c.JSON(http.StatusOK, rules)
+}
+
+type claimRuleRequest struct {
+	Attribute    string  `json:"attribute"`
+	Operator     string  `json:"operator"`
+	Value        string  `json:"value"`
+	Role         *string `json:"role"`
+	DepartmentID *string `json:"department_id"`
+	Position     *int    `json:"position"`
+}
+
+// Create adds a claim rule after the existing ones.
+// POST /api/admin/claim-rules  (SuperAdmin only)
+func (h *ClaimRules) Create(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req claimRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	r := &database.ClaimRule{}
+	if err := h.fill(ctx, r, req); err != nil {
+		return err
+	}
+	if err := h.db.CreateClaimRule(ctx, r); err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusCreated, r)
+}
+
+// Update replaces a claim rule; position is kept when omitted.
+// PUT /api/admin/claim-rules/:id  (SuperAdmin only)
+func (h *ClaimRules) Update(c echo.Context) error {
+	ctx := c.Request().Context()
+	r, err := h.db.GetClaimRule(ctx, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "CLAIM_RULE_NOT_FOUND", "claim rule not found")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	var req claimRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if err := h.fill(ctx, r, req); err != nil {
+		return err
+	}
+	if req.Position != nil {
+		r.Position = *req.Position
+	}
+	if err := h.db.UpdateClaimRule(ctx, r); err != nil {
+		return apierr.Database()
+	}
+	if r, err = h.db.GetClaimRule(ctx, r.ID); err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, r)
+}
+
+// Delete removes a claim rule. Users it assigned keep their role and
+// department.
+// DELETE /api/admin/claim-rules/:id  (SuperAdmin only)
+func (h *ClaimRules) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	if _, err := h.db.GetClaimRule(ctx, c.Param("id")); errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "CLAIM_RULE_NOT_FOUND", "claim rule not found")
+	} else if err != nil {
+		return apierr.Database()
+	}
+	if err := h.db.DeleteClaimRule(ctx, c.Param("id")); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Evaluate shows what the current rules would assign to an employee with
+// the given attributes, without changing anyone.
+// POST /api/admin/claim-rules/evaluate  (SuperAdmin only)
+func (h *ClaimRules) Evaluate(c echo.Context) error {
+	var body struct {
+		Department string   `json:"department"`
+		OU         string   `json:"ou"`
+		JobTitle   string   `json:"job_title"`
+		Groups     []string `json:"groups"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	rules, err := hris.LoadRules(c.Request().Context(), h.db)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, rules.Resolve(hris.Employee{
+		Department: body.Department, OU: body.OU, JobTitle: body.JobTitle, Groups: body.Groups,
+	}))
+}
+
+// fill validates req and copies it onto r.
+func (h *ClaimRules) fill(ctx context.Context, r *database.ClaimRule, req claimRuleRequest) error {
+	switch req.Attribute {
+	case database.ClaimDepartment, database.ClaimOU, database.ClaimJobTitle, database.ClaimGroup:
+	default:
+		return apierr.Invalid("attribute must be department, ou, job_title, or group", "attribute")
+	}
+	req.Value = strings.TrimSpace(req.Value)
+	if req.Value == "" || len(req.Value) > maxClaimValueLen {
+		return apierr.Invalid("value must be 1-200 characters", "value")
+	}
+	switch req.Operator {
+	case database.ClaimEquals, database.ClaimContains, database.ClaimPrefix:
+	case database.ClaimRegex:
+		if _, err := regexp.Compile(req.Value); err != nil {
+			return apierr.Invalid("value must be a valid regular expression", "value")
+		}
+	default:
+		return apierr.Invalid("operator must be equals, contains, prefix, or regex", "operator")
+	}
+	if req.Role == nil && req.DepartmentID == nil {
+		return apierr.Invalid("a rule must set a role, a department, or both", "role", "department_id")
+	}
+	// Rules cannot grant SuperAdmin: that stays a decision made by hand.
+	if req.Role != nil && *req.Role != mw.RoleStaff && *req.Role != mw.RoleDeptAdmin {
+		return apierr.Invalid("role must be Staff or DeptAdmin", "role")
+	}
+	if req.DepartmentID != nil {
+		if _, err := h.db.GetDepartment(ctx, *req.DepartmentID); errors.Is(err, sql.ErrNoRows) {
+			return apierr.Invalid("unknown department", "department_id")
+		} else if err != nil {
+			return apierr.Database()
+		}
+	}
+	r.Attribute, r.Operator, r.Value = req.Attribute, req.Operator, req.Value
+	r.Role, r.DepartmentID = req.Role, req.DepartmentID
+	return nil
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// maxCollectionPolicies bounds the policies in one collection.
+const maxCollectionPolicies = 200
+
+// Collections manages handbook-like reading sequences of policies and
+// reports each reader's progress through them.
+type Collections struct {
+	db *database.DB
+}
+
+func NewCollections(db *database.DB) *Collections {
+	return &Collections{db: db}
+}
+
+// collectionProgress counts the caller's acknowledgements of a collection's
+// policies.
+type collectionProgress struct {
+	Total         int     `json:"total"`
+	Acknowledged  int     `json:"acknowledged"`
+	AckPercentage float64 `json:"ack_percentage"`
+}
+
+// collectionItem is a policy in a collection as the caller reads it.
+type collectionItem struct {
+	ID               string  `json:"id"`
+	Title            string  `json:"title"`
+	CurrentVersionID *string `json:"current_version_id"`
+	Acknowledged     bool    `json:"acknowledged"`
+}
+
+// collectionView is a collection as the caller sees it: only the policies
+// they can read, with their progress and the next policy to read.
+type collectionView struct {
+	ID           string             `json:"id"`
+	Title        string             `json:"title"`
+	Description  string             `json:"description"`
+	Progress     collectionProgress `json:"progress"`
+	NextPolicyID *string            `json:"next_policy_id"`
+	Items        []collectionItem   `json:"items,omitempty"`
+}
+
+// List returns the collections with at least one policy the caller can
+// read, each with the caller's progress.
+// GET /api/collections
+func (h *Collections) List(c echo.Context) error {
+	cols, err := h.db.ListCollections(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	views, err := h.views(c, cols)
+	if err != nil {
+		return err
+	}
+	out := make([]collectionView, 0, len(views))
+	for _, v := range views {
+		if v.Progress.Total > 0 {
+			v.Items = nil
+			out = append(out, v)
+		}
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+// Get returns a collection's policies in reading order with the caller's
+// acknowledgement of each.
+// GET /api/collections/:id
+func (h *Collections) Get(c echo.Context) error {
+	col, err := h.load(c)
+	if err != nil {
+		return err
+	}
+	views, err := h.views(c, []*database.Collection{col})
+	if err != nil {
+		return err
+	}
+	if len(views[0].Items) == 0 {
+		return apierr.New(http.StatusNotFound, "COLLECTION_NOT_FOUND", "collection not found")
+	}
+	return c.JSON(http.StatusOK, views[0])
+}
+
+// views resolves collections for the caller. A policy counts once it is
+// published with a current version the caller can see.
+func (h *Collections) views(c echo.Context, cols []*database.Collection) ([]collectionView, error) {
+	ctx := c.Request().Context()
+	userID := c.Get(mw.CtxUserID).(string)
+	role := c.Get(mw.CtxUserRole).(string)
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+
+	policies, err := h.db.ListPoliciesForUser(ctx, userID, role, deptID)
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	readable := make(map[string]*database.Policy, len(policies))
+	for _, p := range policies {
+		if p.Status == "Published" && p.CurrentVersionID != nil {
+			readable[p.ID] = p
+		}
+	}
+	ackMap, err := h.db.AckStatusForUser(ctx, userID)
+	if err != nil {
+		return nil, apierr.Database()
+	}
+
+	out := make([]collectionView, len(cols))
+	for i, col := range cols {
+		v := collectionView{ID: col.ID, Title: col.Title, Description: col.Description, Items: []collectionItem{}}
+		for _, cp := range col.Policies {
+			p, ok := readable[cp.ID]
+			if !ok {
+				continue
+			}
+			item := collectionItem{ID: p.ID, Title: p.Title, CurrentVersionID: p.CurrentVersionID,
+				Acknowledged: ackMap[*p.CurrentVersionID]}
+			v.Progress.Total++
+			if item.Acknowledged {
+				v.Progress.Acknowledged++
+			} else if v.NextPolicyID == nil {
+				v.NextPolicyID = &item.ID
+			}
+			v.Items = append(v.Items, item)
+		}
+		if v.Progress.Total > 0 {
+			v.Progress.AckPercentage = math.Round(float64(v.Progress.Acknowledged)*1000/float64(v.Progress.Total)) / 10
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// Manage returns every collection with all of its policies, whatever their
+// status, for editing.
+// GET /api/admin/collections  (SuperAdmin only)
+func (h *Collections) Manage(c echo.Context) error {
+	cols, err := h.db.ListCollections(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	if cols == nil {
+		cols = []*database.Collection{}
+	}
+	return c.JSON(http.StatusOK, cols)
+}
+
+type collectionRequest struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Position    *int      `json:"position"`
+	PolicyIDs   *[]string `json:"policy_ids"`
+}
+
+// Create adds a collection after the existing ones.
+// POST /api/admin/collections  (SuperAdmin only)
+func (h *Collections) Create(c echo.Context) error {
+	col := &database.Collection{}
+	userID := c.Get(mw.CtxUserID).(string)
+	col.CreatedBy = &userID
+	return h.save(c, col, nil, http.StatusCreated)
+}
+
+// Update changes a collection; omitted fields keep their values.
+// PUT /api/admin/collections/:id  (SuperAdmin only)
+func (h *Collections) Update(c echo.Context) error {
+	col, err := h.load(c)
+	if err != nil {
+		return err
+	}
+	policyIDs := make([]string, len(col.Policies))
+	for i, p := range col.Policies {
+		policyIDs[i] = p.ID
+	}
+	return h.save(c, col, policyIDs, http.StatusOK)
+}
+
+func (h *Collections) save(c echo.Context, col *database.Collection, policyIDs []string, status int) error {
+	ctx := c.Request().Context()
+	var req collectionRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if req.Title != nil {
+		col.Title = strings.TrimSpace(*req.Title)
+	}
+	if col.Title == "" || len(col.Title) > 200 {
+		return apierr.Invalid("title is required and must be under 200 characters", "title")
+	}
+	if req.Description != nil {
+		col.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Position != nil {
+		if *req.Position < 0 {
+			return apierr.Invalid("position must not be negative", "position")
+		}
+		col.Position = *req.Position
+	}
+	if req.PolicyIDs != nil {
+		policyIDs = nil
+		for _, id := range *req.PolicyIDs {
+			if !slices.Contains(policyIDs, id) {
+				policyIDs = append(policyIDs, id)
+			}
+		}
+	}
+	if len(policyIDs) == 0 || len(policyIDs) > maxCollectionPolicies {
+		return apierr.Invalid("a collection needs 1-200 policies", "policy_ids")
+	}
+	for _, id := range policyIDs {
+		if _, err := h.db.GetPolicy(ctx, id); errors.Is(err, sql.ErrNoRows) {
+			return apierr.Invalid("unknown policy: "+id, "policy_ids")
+		} else if err != nil {
+			return apierr.Database()
+		}
+	}
+
+	if err := h.db.SaveCollection(ctx, col, policyIDs); err != nil {
+		return apierr.Database()
+	}
+	saved, err := h.db.GetCollection(ctx, col.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(status, saved)
+}
+
+// Delete removes a collection. Its policies and their acknowledgements are
+// unaffected.
+// DELETE /api/admin/collections/:id  (SuperAdmin only)
+func (h *Collections) Delete(c echo.Context) error {
+	if _, err := h.load(c); err != nil {
+		return err
+	}
+	if err := h.db.DeleteCollection(c.Request().Context(), c.Param("id")); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// load loads the :id collection, mapping a missing one to 404.
+func (h *Collections) load(c echo.Context) (*database.Collection, error) {
+	col, err := h.db.GetCollection(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apierr.New(http.StatusNotFound, "COLLECTION_NOT_FOUND", "collection not found")
+	}
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	return col, nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestCollections_ProgressOverReadablePolicies verifies a collection keeps
+// its reading order, counts only the published policies the caller can
+// see, and points at the first one they have not acknowledged.
+func TestCollections_ProgressOverReadablePolicies(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	root, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	engineer, _ := db.CreateUser(ctx, "eng@example.com", "Engineer", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	operator, _ := db.CreateUser(ctx, "ops@example.com", "Operator", mw.RoleStaff, nil, testutil.Ptr(ops.ID))
+	conduct, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	onCall, _ := db.CreatePolicy(ctx, "On-call", "", testutil.Ptr(eng.ID), "department", nil)
+	draft, _ := db.CreatePolicy(ctx, "Travel", "", nil, "organization", nil)
+	for _, p := range []*database.Policy{conduct, onCall} {
+		testutil.Publish(t, db, p)
+	}
+
+	e := echo.New()
+	h := NewCollections(db)
+	c, rec := testutil.NewContext(e, http.MethodPost,
+		`{"title":"Employee Handbook","policy_ids":["`+onCall.ID+`","`+conduct.ID+`","`+draft.ID+`"]}`, "", mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, root.ID)
+	if err := h.Create(c); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	var col database.Collection
+	json.Unmarshal(rec.Body.Bytes(), &col)
+	if len(col.Policies) != 3 || col.Policies[0].ID != onCall.ID {
+		t.Fatalf("created = %+v; want three policies, On-call first", col)
+	}
+
+	cur, _ := db.GetPolicy(ctx, conduct.ID)
+	db.CreateAcknowledgement(ctx, engineer.ID, *cur.CurrentVersionID)
+
+	get := func(u *database.User) collectionView {
+		c, rec := testutil.NewContext(e, http.MethodGet, "", col.ID, u.Role, u.DepartmentID)
+		c.Set(mw.CtxUserID, u.ID)
+		if err := h.Get(c); err != nil {
+			t.Fatalf("Get as %s: %v", u.Name, err)
+		}
+		var v collectionView
+		json.Unmarshal(rec.Body.Bytes(), &v)
+		return v
+	}
+	v := get(engineer)
+	if v.Progress != (collectionProgress{Total: 2, Acknowledged: 1, AckPercentage: 50}) {
+		t.Errorf("engineer progress = %+v; want 1 of 2", v.Progress)
+	}
+	if v.NextPolicyID == nil || *v.NextPolicyID != onCall.ID || len(v.Items) != 2 || !v.Items[1].Acknowledged {
+		t.Errorf("engineer view = %+v; want On-call next, Code of Conduct acknowledged", v)
+	}
+
+	v = get(operator)
+	if v.Progress.Total != 1 || v.Items[0].ID != conduct.ID {
+		t.Errorf("operator view = %+v; want only Code of Conduct", v)
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/reports"
+)
+
+// complianceFormat reads ?format=, which may be json (default), csv, or xlsx.
+func complianceFormat(c echo.Context) (string, error) {
+	switch format := c.QueryParam("format"); format {
+	case "", "json":
+		return "json", nil
+	case "csv", "xlsx":
+		return format, nil
+	}
+	return "", apierr.Invalid("format must be json, csv, or xlsx", "format")
+}
+
+// AckMatrix returns every active user against every policy they must
+// acknowledge, with the acknowledgement date or pending/overdue and any
+// active exception. The xlsx format has one sheet per department. DeptAdmin
+// only sees their department.
+// GET /api/admin/reports/ack-matrix?format=json|csv|xlsx
+func (h *Reports) AckMatrix(c echo.Context) error {
+	format, err := complianceFormat(c)
+	if err != nil {
+		return err
+	}
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+	m, err := h.db.Reader().BuildAckMatrix(c.Request().Context(), deptID, time.Now().UTC())
+	if err != nil {
+		return apierr.Database()
+	}
+
+	switch format {
+	case "xlsx":
+		data, err := reports.MatrixXLSX(m)
+		if err != nil {
+			return apierr.New(http.StatusInternalServerError, "RENDER_FAILED", "failed to render spreadsheet")
+		}
+		return writeXLSX(c, "acknowledgement-matrix.xlsx", data)
+	case "csv":
+		header := []string{"name", "email", "department"}
+		for _, p := range m.Policies {
+			header = append(header, p.Title)
+		}
+		out, err := streamCSV(c, "acknowledgement-matrix.csv", header)
+		if err != nil {
+			return err
+		}
+		for i, u := range m.Users {
+			row := []string{u.Name, u.Email, database.UserDepartment(u)}
+			for _, cell := range m.Cells[i] {
+				row = append(row, reports.MatrixCellText(cell))
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+		return out.Close()
+	}
+	return c.JSON(http.StatusOK, m)
+}
+
+// DepartmentCompliance totals required, acknowledged, pending, and overdue
+// acknowledgements per department. The xlsx format adds a sheet per
+// department listing each user's outstanding policies.
+// GET /api/admin/reports/department-compliance?format=json|csv|xlsx
+func (h *Reports) DepartmentCompliance(c echo.Context) error {
+	format, err := complianceFormat(c)
+	if err != nil {
+		return err
+	}
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+	m, err := h.db.Reader().BuildAckMatrix(c.Request().Context(), deptID, time.Now().UTC())
+	if err != nil {
+		return apierr.Database()
+	}
+
+	switch format {
+	case "xlsx":
+		data, err := reports.DepartmentComplianceXLSX(m)
+		if err != nil {
+			return apierr.New(http.StatusInternalServerError, "RENDER_FAILED", "failed to render spreadsheet")
+		}
+		return writeXLSX(c, "department-compliance.xlsx", data)
+	case "csv":
+		rows := [][]string{{"department", "users", "required", "acknowledged", "pending", "overdue", "active_exceptions", "ack_percentage"}}
+		for _, d := range m.ByDepartment() {
+			rows = append(rows, []string{
+				d.Department, strconv.Itoa(d.Users), strconv.Itoa(d.Required), strconv.Itoa(d.Acknowledged),
+				strconv.Itoa(d.Pending), strconv.Itoa(d.Overdue), strconv.Itoa(d.Exceptions), strconv.FormatFloat(d.AckPercentage, 'f', 1, 64),
+			})
+		}
+		return writeCSV(c, "department-compliance.csv", rows)
+	}
+	departments := m.ByDepartment()
+	if departments == nil {
+		departments = []*database.DepartmentCompliance{}
+	}
+	return c.JSON(http.StatusOK, departments)
+}
+
+// ReackGaps lists users who acknowledged an earlier version of a policy but
+// not the one now current, with totals per policy and per department: the
+// people to remind after a republish. CSV has one row per gap.
+// GET /api/admin/reports/reack-gaps?format=json|csv
+func (h *Reports) ReackGaps(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return apierr.Invalid("format must be json or csv", "format")
+	}
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+	r, err := h.db.Reader().ListReackGaps(c.Request().Context(), deptID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if format == "csv" {
+		out, err := streamCSV(c, "reacknowledgement-gaps.csv", []string{"policy", "current_version", "user", "email", "department", "acknowledged_version", "acknowledged_at"})
+		if err != nil {
+			return err
+		}
+		for _, g := range r.Gaps {
+			if err := out.Write([]string{g.PolicyTitle, g.CurrentVersion, g.UserName, g.UserEmail, g.Department,
+				g.AcknowledgedVersion, g.AcknowledgedAt.Format(time.RFC3339)}); err != nil {
+				return err
+			}
+		}
+		return out.Close()
+	}
+	return c.JSON(http.StatusOK, r)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/xuri/excelize/v2"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestAckMatrix_XLSXPerDepartment verifies that the matrix workbook has a
+// sheet per department with the acknowledgement date, pending, or overdue
+// for each required policy.
+func TestAckMatrix_XLSXPerDepartment(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	alice, _ := db.CreateUser(ctx, "alice@example.com", "Alice", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleStaff, nil, testutil.Ptr(hr.ID))
+
+	conduct, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	onCall, _ := db.CreatePolicy(ctx, "On-call", "", testutil.Ptr(eng.ID), "department", nil)
+	for _, p := range []string{conduct.ID, onCall.ID} {
+		pol, _ := db.GetPolicy(ctx, p)
+		testutil.Publish(t, db, pol)
+	}
+	conductP, _ := db.GetPolicy(ctx, conduct.ID)
+	if _, err := db.CreateAcknowledgement(ctx, alice.ID, *conductP.CurrentVersionID); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	yesterday := time.Now().Add(-24 * time.Hour)
+	db.SetPolicyAckDeadline(ctx, onCall.ID, &yesterday)
+
+	e := echo.New()
+	h := NewReports(db, nil)
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	c.Request().URL.RawQuery = "format=xlsx"
+	if err := h.AckMatrix(c); err != nil {
+		t.Fatalf("AckMatrix: %v", err)
+	}
+
+	f, err := excelize.OpenReader(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+	if got := f.GetSheetList(); len(got) != 2 || got[0] != "Engineering" || got[1] != "HR" {
+		t.Fatalf("sheets = %v; want [Engineering HR]", got)
+	}
+	eng1, _ := f.GetRows("Engineering")
+	today := time.Now().UTC().Format(time.DateOnly)
+	if len(eng1) != 2 || eng1[0][3] != "Code of Conduct" || eng1[0][4] != "On-call" ||
+		eng1[1][0] != "Alice" || eng1[1][3] != today || eng1[1][4] != "Overdue" {
+		t.Errorf("Engineering sheet = %v", eng1)
+	}
+	hrRows, _ := f.GetRows("HR")
+	if len(hrRows) != 2 || len(hrRows[0]) != 4 || hrRows[1][3] != "Pending" {
+		t.Errorf("HR sheet = %v; want only Code of Conduct, pending", hrRows)
+	}
+}
+
+// TestNewHireGrace_DeadlineFromAccountCreation verifies that with a grace
+// period a user who just joined is pending rather than overdue on a policy
+// whose deadline has passed, in their to-do list and in the matrix.
+func TestNewHireGrace_DeadlineFromAccountCreation(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	hire, _ := db.CreateUser(ctx, "new@example.com", "New Hire", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	db.SetPolicyAckDeadline(ctx, p.ID, &lastWeek)
+
+	overdue := func() (list int, matrix string) {
+		ps, err := db.ListOverduePoliciesForUser(ctx, hire.ID, hire.Role, nil, time.Now())
+		if err != nil {
+			t.Fatalf("ListOverduePoliciesForUser: %v", err)
+		}
+		m, err := db.BuildAckMatrix(ctx, nil, time.Now())
+		if err != nil {
+			t.Fatalf("BuildAckMatrix: %v", err)
+		}
+		return len(ps), m.Cells[0][0].Status
+	}
+	if n, status := overdue(); n != 1 || status != database.AckOverdue {
+		t.Errorf("without grace: overdue %d, matrix %q; want 1, overdue", n, status)
+	}
+
+	e := echo.New()
+	c, _ := testutil.NewContext(e, http.MethodPut, `{"new_hire_grace_days":30}`, "", mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, hire.ID)
+	if err := NewSettings(db).Update(c); err != nil {
+		t.Fatalf("Update settings: %v", err)
+	}
+	if n, status := overdue(); n != 0 || status != database.AckPending {
+		t.Errorf("with grace: overdue %d, matrix %q; want 0, pending", n, status)
+	}
+	pending, _ := db.ListPendingPoliciesForUser(ctx, hire.ID, hire.Role, nil)
+	want := hire.CreatedAt.AddDate(0, 0, 30)
+	if len(pending) != 1 || pending[0].AckDeadline == nil || !pending[0].AckDeadline.Equal(want) {
+		t.Errorf("pending = %+v; want one due %v", pending, want)
+	}
+}
+
+// TestReackGaps_AcknowledgedOnlyEarlierVersion verifies the report lists
+// users whose latest acknowledgement is of a superseded version, and not
+// those who never acknowledged or already acknowledged the current one.
+func TestReackGaps_AcknowledgedOnlyEarlierVersion(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	alice, _ := db.CreateUser(ctx, "alice@example.com", "Alice", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	bob, _ := db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleStaff, nil, testutil.Ptr(hr.ID))
+	db.CreateUser(ctx, "carol@example.com", "Carol", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	p, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, alice.ID, *p.CurrentVersionID)
+	db.CreateAcknowledgement(ctx, bob.ID, *p.CurrentVersionID)
+	v2, _ := db.CreatePolicyVersion(ctx, p.ID, "# Body v2", "v2.0.0", "rewrite", nil)
+	db.SetPolicyCurrentVersion(ctx, p.ID, v2.ID)
+	db.CreateAcknowledgement(ctx, bob.ID, v2.ID)
+
+	report := func(role string, deptID *string) database.ReackGapReport {
+		t.Helper()
+		c, rec := testutil.NewContext(echo.New(), http.MethodGet, "", "", role, deptID)
+		if err := NewReports(db, nil).ReackGaps(c); err != nil {
+			t.Fatalf("ReackGaps: %v", err)
+		}
+		var r database.ReackGapReport
+		json.Unmarshal(rec.Body.Bytes(), &r)
+		return r
+	}
+	r := report(mw.RoleSuperAdmin, nil)
+	if len(r.Gaps) != 1 || r.Gaps[0].UserID != alice.ID || r.Gaps[0].AcknowledgedVersion != "v1.0.0" || r.Gaps[0].CurrentVersion != "v2.0.0" {
+		t.Fatalf("gaps = %+v; want Alice on v1.0.0", r.Gaps)
+	}
+	if len(r.ByPolicy) != 1 || r.ByPolicy[0].Users != 1 || len(r.ByDepartment) != 1 || r.ByDepartment[0].Department != "Engineering" {
+		t.Errorf("summaries = %+v %+v", r.ByPolicy, r.ByDepartment)
+	}
+	if r := report(mw.RoleDeptAdmin, testutil.Ptr(hr.ID)); len(r.Gaps) != 0 {
+		t.Errorf("HR gaps = %+v; want none", r.Gaps)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+)
+
+// Config serves the settings the frontend needs at runtime, so the static
+// build does not have them compiled in.
+type Config struct {
+	db     *database.DB
+	policy *Policy
+
+	orgName           string // ORG_NAME
+	supportEmail      string // SUPPORT_EMAIL; empty = none
+	deadlineReminders bool   // DEADLINE_REMINDERS
+	trainingWebhook   bool   // LMS_WEBHOOK_TOKEN is set
+	demoMode          bool   // DEMO_MODE
+}
+
+func NewConfig(db *database.DB, policy *Policy) *Config {
+	orgName := os.Getenv("ORG_NAME")
+	if orgName == "" {
+		orgName = "PolicyFlow"
+	}
+	return &Config{
+		db:                db,
+		policy:            policy,
+		orgName:           orgName,
+		supportEmail:      os.Getenv("SUPPORT_EMAIL"),
+		deadlineReminders: os.Getenv("DEADLINE_REMINDERS") == "true",
+		trainingWebhook:   os.Getenv("LMS_WEBHOOK_TOKEN") != "",
+		demoMode:          os.Getenv("DEMO_MODE") == "true",
+	}
+}
+
+// runtimeConfig holds nothing secret: it is served before sign-in.
+type runtimeConfig struct {
+	OrgName      string `json:"org_name"`
+	SupportEmail string `json:"support_email"`
+	HasLogo      bool   `json:"has_logo"`
+	// SSOEnabled is always false: users sign in with email links and
+	// codes. It is here so clients need not change when SSO is added.
+	SSOEnabled      bool           `json:"sso_enabled"`
+	MaxUploadBytes  int64          `json:"max_upload_bytes"`
+	Acknowledgement ackRequirement `json:"acknowledgement"`
+	Features        configFeatures `json:"features"`
+}
+
+type ackRequirement struct {
+	MinReadSeconds int  `json:"min_read_seconds"`
+	RequireScroll  bool `json:"require_scroll"`
+}
+
+type configFeatures struct {
+	PublicPortal         bool `json:"public_portal"`
+	DeadlineReminders    bool `json:"deadline_reminders"`
+	PublishNotifications bool `json:"publish_notifications"`
+	TrainingWebhook      bool `json:"training_webhook"`
+	DemoMode             bool `json:"demo_mode"` // POST /api/demo/login is available
+}
+
+// Get returns the runtime configuration.
+// GET /api/config  (public)
+func (h *Config) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+	logo, err := h.db.GetSetting(ctx, database.SettingLogoContentType, "")
+	if err != nil {
+		return apierr.Database()
+	}
+	portal, err := h.db.GetBoolSetting(ctx, database.SettingPublicPortal, false)
+	if err != nil {
+		return apierr.Database()
+	}
+	notify, err := h.db.GetBoolSetting(ctx, database.SettingPublishNotifications, true)
+	if err != nil {
+		return apierr.Database()
+	}
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	return c.JSON(http.StatusOK, runtimeConfig{
+		OrgName:        h.orgName,
+		SupportEmail:   h.supportEmail,
+		HasLogo:        logo != "",
+		MaxUploadBytes: h.policy.maxUpload,
+		Acknowledgement: ackRequirement{
+			MinReadSeconds: int(h.policy.minReadTime.Seconds()),
+			RequireScroll:  h.policy.requireScroll,
+		},
+		Features: configFeatures{
+			PublicPortal:         portal,
+			DeadlineReminders:    h.deadlineReminders,
+			PublishNotifications: notify,
+			TrainingWebhook:      h.trainingWebhook,
+			DemoMode:             h.demoMode,
+		},
+	})
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+)
+
+const (
+	maxFieldValueLen = 1000
+	maxFieldOptions  = 100
+)
+
+var (
+	fieldKeyRe   = regexp.MustCompile(`^[a-z0-9_]{1,40}$`)
+	fieldKeyTrim = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// CustomFields lets a SuperAdmin define extra policy attributes such as
+// "Regulatory basis" or "Document classification".
+type CustomFields struct {
+	db *database.DB
+}
+
+func NewCustomFields(db *database.DB) *CustomFields {
+	return &CustomFields{db: db}
+}
+
+// List returns the custom fields in display order, so forms and filters
+// can be built from them.
+// GET /api/custom-fields
+func (h *CustomFields) List(c echo.Context) error {
+	fields, err := h.db.ListCustomFields(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	if fields == nil {
+		fields = []*database.CustomField{}
+	}
+	return c.JSON(http.StatusOK, fields)
+}
+
+type customFieldRequest struct {
+	Key      string    `json:"key"`
+	Label    *string   `json:"label"`
+	Type     string    `json:"type"`
+	Options  *[]string `json:"options"`
+	Position *int      `json:"position"`
+}
+
+// Create adds a custom field. The key, which names the field in API
+// responses and filters, is derived from the label when omitted and cannot
+// be changed later; nor can the type.
+// POST /api/admin/custom-fields  (SuperAdmin only)
+func (h *CustomFields) Create(c echo.Context) error {
+	var req customFieldRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	f := &database.CustomField{Type: req.Type, Key: req.Key, Options: []string{}}
+	if req.Label != nil {
+		f.Label = strings.TrimSpace(*req.Label)
+	}
+	if f.Label == "" {
+		return apierr.Invalid("label is required", "label")
+	}
+	if f.Key == "" {
+		f.Key = strings.Trim(fieldKeyTrim.ReplaceAllString(strings.ToLower(f.Label), "_"), "_")
+		if len(f.Key) > 40 {
+			f.Key = strings.TrimRight(f.Key[:40], "_")
+		}
+	}
+	if !fieldKeyRe.MatchString(f.Key) {
+		return apierr.Invalid("key must be 1-40 lowercase letters, digits, or underscores", "key")
+	}
+	switch f.Type {
+	case database.FieldText, database.FieldDate, database.FieldSelect:
+	default:
+		return apierr.Invalid("type must be text, date, or select", "type")
+	}
+	if req.Options != nil {
+		f.Options = *req.Options
+	}
+	if err := checkFieldOptions(f); err != nil {
+		return err
+	}
+
+	err := h.db.CreateCustomField(c.Request().Context(), f)
+	if errors.Is(err, database.ErrFieldKeyTaken) {
+		return apierr.New(http.StatusConflict, "FIELD_KEY_TAKEN", "a custom field with that key already exists")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusCreated, f)
+}
+
+// Update changes a field's label, options, or position. Removing an option
+// that policies still use is refused.
+// PUT /api/admin/custom-fields/:id  (SuperAdmin only)
+func (h *CustomFields) Update(c echo.Context) error {
+	ctx := c.Request().Context()
+	f, err := h.db.GetCustomField(ctx, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "FIELD_NOT_FOUND", "custom field not found")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	var req customFieldRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if (req.Key != "" && req.Key != f.Key) || (req.Type != "" && req.Type != f.Type) {
+		return apierr.Invalid("a custom field's key and type cannot be changed", "key", "type")
+	}
+	if req.Label != nil {
+		if f.Label = strings.TrimSpace(*req.Label); f.Label == "" {
+			return apierr.Invalid("label is required", "label")
+		}
+	}
+	if req.Position != nil {
+		f.Position = *req.Position
+	}
+	if req.Options != nil {
+		f.Options = *req.Options
+		if err := checkFieldOptions(f); err != nil {
+			return err
+		}
+	}
+	if req.Options != nil && f.Type == database.FieldSelect {
+		used, err := h.db.CustomFieldValuesInUse(ctx, f.ID)
+		if err != nil {
+			return apierr.Database()
+		}
+		var missing []string
+		for _, v := range used {
+			if !slices.Contains(f.Options, v) {
+				missing = append(missing, v)
+			}
+		}
+		if len(missing) > 0 {
+			return apierr.With(apierr.New(http.StatusConflict, "OPTION_IN_USE",
+				"policies still use options that would be removed"), "options", missing)
+		}
+	}
+	if err := h.db.UpdateCustomField(ctx, f); err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, f)
+}
+
+// Delete removes a custom field and its value on every policy.
+// DELETE /api/admin/custom-fields/:id  (SuperAdmin only)
+func (h *CustomFields) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	if _, err := h.db.GetCustomField(ctx, c.Param("id")); errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "FIELD_NOT_FOUND", "custom field not found")
+	} else if err != nil {
+		return apierr.Database()
+	}
+	if err := h.db.DeleteCustomField(ctx, c.Param("id")); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// checkFieldOptions requires a select field to list its options and other
+// types not to.
+func checkFieldOptions(f *database.CustomField) error {
+	if f.Type != database.FieldSelect {
+		if len(f.Options) > 0 {
+			return apierr.Invalid("only select fields have options", "options")
+		}
+		return nil
+	}
+	if len(f.Options) == 0 || len(f.Options) > maxFieldOptions {
+		return apierr.Invalid("a select field needs 1-100 options", "options")
+	}
+	for i, o := range f.Options {
+		if strings.TrimSpace(o) == "" || len(o) > maxFieldValueLen {
+			return apierr.Invalid("options must be non-empty and under 1000 characters", "options")
+		}
+		if slices.Contains(f.Options[:i], o) {
+			return apierr.Invalid("options must be unique", "options")
+		}
+	}
+	return nil
+}
+
+// checkFieldValue validates v as a value of f; empty clears the field.
+func checkFieldValue(f *database.CustomField, v string) error {
+	switch {
+	case v == "":
+		return nil
+	case len(v) > maxFieldValueLen:
+		return apierr.Invalid(f.Key+" must be under 1000 characters", f.Key)
+	case f.Type == database.FieldDate:
+		if _, err := time.Parse(time.DateOnly, v); err != nil {
+			return apierr.Invalid(f.Key+" must be a date (YYYY-MM-DD)", f.Key)
+		}
+	case f.Type == database.FieldSelect:
+		if !slices.Contains(f.Options, v) {
+			return apierr.With(apierr.Invalid(f.Key+" must be one of its options", f.Key), "options", f.Options)
+		}
+	}
+	return nil
+}
+
+// SetCustomFields sets a policy's custom field values by key. Keys left out
+// keep their value; an empty string clears one.
+// PUT /api/policies/:id/custom-fields  {"regulatory_basis": "GDPR Art. 32"}
+func (h *Policy) SetCustomFields(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	// Bound from the body alone: Bind would add the :id path parameter
+	// to the map.
+	var req map[string]string
+	if err := (&echo.DefaultBinder{}).BindBody(c, &req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	fields, err := h.db.ListCustomFields(ctx)
+	if err != nil {
+		return apierr.Database()
+	}
+	byKey := make(map[string]*database.CustomField, len(fields))
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+	values := make(map[string]string, len(req))
+	for key, v := range req {
+		f := byKey[key]
+		if f == nil {
+			return apierr.Invalid("unknown custom field: "+key, key)
+		}
+		v = strings.TrimSpace(v)
+		if err := checkFieldValue(f, v); err != nil {
+			return err
+		}
+		values[f.ID] = v
+	}
+	if err := h.db.SetPolicyFieldValues(ctx, policy.ID, values); err != nil {
+		return apierr.Database()
+	}
+	updated, err := h.db.GetPolicy(ctx, policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, updated.CustomFields)
+}
+
+// customFilter holds ?custom.<key>= list filters: an exact value, or for
+// date fields an inclusive from..to range with either end optional.
+type customFilter map[string][2]string
+
+func (h *Policy) parseCustomFilter(c echo.Context) (customFilter, error) {
+	var keys []string
+	for name := range c.QueryParams() {
+		if key, ok := strings.CutPrefix(name, "custom."); ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	fields, err := h.db.ListCustomFields(c.Request().Context())
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	filter := customFilter{}
+	for _, key := range keys {
+		i := slices.IndexFunc(fields, func(f *database.CustomField) bool { return f.Key == key })
+		if i < 0 {
+			return nil, apierr.Invalid("unknown custom field: "+key, "custom."+key)
+		}
+		v := c.QueryParam("custom." + key)
+		from, to, isRange := strings.Cut(v, "..")
+		if !isRange {
+			filter[key] = [2]string{v, v}
+			continue
+		}
+		if fields[i].Type != database.FieldDate {
+			return nil, apierr.Invalid("only date fields can be filtered by range", "custom."+key)
+		}
+		for _, d := range []string{from, to} {
+			if _, err := time.Parse(time.DateOnly, d); d != "" && err != nil {
+				return nil, apierr.Invalid("range ends must be dates (YYYY-MM-DD)", "custom."+key)
+			}
+		}
+		filter[key] = [2]string{from, to}
+	}
+	return filter, nil
+}
+
+// match reports whether p passes every filter. Dates compare as strings
+// since they are stored as YYYY-MM-DD.
+func (f customFilter) match(p *database.Policy) bool {
+	for key, r := range f {
+		v, ok := p.CustomFields[key]
+		if !ok {
+			return false
+		}
+		if r[0] == r[1] {
+			if v != r[0] {
+				return false
+			}
+			continue
+		}
+		if (r[0] != "" && v < r[0]) || (r[1] != "" && v > r[1]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestCustomFields_SetValuesAndFilter verifies values are checked against
+// the field's type, returned on the policy, usable as list filters, and
+// that an option in use cannot be removed.
+func TestCustomFields_SetValuesAndFilter(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	e := echo.New()
+	fields := NewCustomFields(db)
+	h := NewPolicy(db)
+	gdpr, _ := db.CreatePolicy(ctx, "Data Protection", "", nil, "organization", nil)
+	travel, _ := db.CreatePolicy(ctx, "Travel", "", nil, "organization", nil)
+
+	create := func(body string) (*httptest.ResponseRecorder, error) {
+		c, rec := testutil.NewContext(e, http.MethodPost, body, "", mw.RoleSuperAdmin, nil)
+		return rec, fields.Create(c)
+	}
+	rec, err := create(`{"label":"Document classification","type":"select","options":["Public","Internal","Confidential"]}`)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	var class struct{ ID, Key string }
+	json.Unmarshal(rec.Body.Bytes(), &class)
+	if class.Key != "document_classification" {
+		t.Errorf("derived key = %q", class.Key)
+	}
+	if _, err := create(`{"key":"review_by","label":"Review by","type":"date"}`); err != nil {
+		t.Fatalf("Create date: %v", err)
+	}
+	var he *echo.HTTPError
+	if _, err := create(`{"label":"Document Classification","type":"text"}`); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Errorf("duplicate key: err = %v; want 409", err)
+	}
+
+	set := func(id, body string) error {
+		c, _ := testutil.NewContext(e, http.MethodPut, body, id, mw.RoleSuperAdmin, nil)
+		return h.SetCustomFields(c)
+	}
+	for _, body := range []string{`{"document_classification":"Secret"}`, `{"review_by":"next year"}`, `{"owner":"x"}`} {
+		if err := set(gdpr.ID, body); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+			t.Errorf("%s: err = %v; want 400", body, err)
+		}
+	}
+	if err := set(gdpr.ID, `{"document_classification":"Confidential","review_by":"2027-03-01"}`); err != nil {
+		t.Fatalf("SetCustomFields: %v", err)
+	}
+	if err := set(travel.ID, `{"document_classification":"Internal","review_by":"2026-11-15"}`); err != nil {
+		t.Fatalf("SetCustomFields: %v", err)
+	}
+	got, _ := db.GetPolicy(ctx, gdpr.ID)
+	if got.CustomFields["document_classification"] != "Confidential" || got.CustomFields["review_by"] != "2027-03-01" {
+		t.Errorf("custom_fields = %v", got.CustomFields)
+	}
+
+	list := func(query string) []string {
+		c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+		c.Request().URL.RawQuery = query
+		if err := h.List(c); err != nil {
+			t.Fatalf("List ?%s: %v", query, err)
+		}
+		var out []struct{ Title string }
+		json.Unmarshal(rec.Body.Bytes(), &out)
+		titles := []string{}
+		for _, p := range out {
+			titles = append(titles, p.Title)
+		}
+		return titles
+	}
+	if got := list("custom.document_classification=Confidential"); len(got) != 1 || got[0] != "Data Protection" {
+		t.Errorf("classification filter = %v", got)
+	}
+	if got := list("custom.review_by=..2026-12-31"); len(got) != 1 || got[0] != "Travel" {
+		t.Errorf("date range filter = %v", got)
+	}
+
+	c, _ := testutil.NewContext(e, http.MethodPut, `{"options":["Public","Internal"]}`, class.ID, mw.RoleSuperAdmin, nil)
+	if err := fields.Update(c); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Errorf("removing used option: err = %v; want 409", err)
+	}
+}
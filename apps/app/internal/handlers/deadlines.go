@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/ics"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/reminders"
+)
+
+// Deadlines serves the current user's acknowledgement deadlines as a calendar.
+type Deadlines struct {
+	policy  *Policy
+	auth    *mw.Auth
+	baseURL string
+}
+
+func NewDeadlines(policy *Policy, auth *mw.Auth) *Deadlines {
+	return &Deadlines{policy: policy, auth: auth, baseURL: baseURLFromEnv()}
+}
+
+// ICS returns an iCalendar feed of the user's outstanding policy deadlines.
+// GET /api/me/deadlines.ics?token=...
+func (h *Deadlines) ICS(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get(mw.CtxUserID).(string)
+	role := c.Get(mw.CtxUserRole).(string)
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+
+	pending, err := h.policy.db.ListPendingPoliciesForUser(ctx, userID, role, deptID)
+	if err != nil {
+		return apierr.Database()
+	}
+	cal := ics.Calendar("PolicyFlow deadlines", reminders.DeadlineEvents(h.baseURL, pending))
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="policy-deadlines.ics"`)
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", cal)
+}
+
+// Subscription returns a subscribable feed URL carrying a long-lived
+// calendar-only token, for adding to Outlook or Google Calendar.
+// GET /api/me/deadlines/subscription
+func (h *Deadlines) Subscription(c echo.Context) error {
+	userID := c.Get(mw.CtxUserID).(string)
+	token, err := h.auth.IssueFeedToken(userID)
+	if err != nil {
+		return apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "token error")
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"url": h.baseURL + "/api/me/deadlines.ics?token=" + url.QueryEscape(token),
+	})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+)
+
+// DemoLogin signs the visitor in as the demo account for {"role":
+// "SuperAdmin" | "DeptAdmin" | "Staff"}, since demo users cannot receive
+// sign-in emails. Only registered when DEMO_MODE is on.
+// POST /api/demo/login  (public)
+func (h *Auth) DemoLogin(c echo.Context) error {
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	email, ok := database.DemoAccounts[body.Role]
+	if !ok {
+		return apierr.Invalid("role must be SuperAdmin, DeptAdmin, or Staff", "role")
+	}
+	user, err := h.db.GetUserByEmail(c.Request().Context(), email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusServiceUnavailable, "DEMO_RESETTING", "the demo is being reset; try again shortly")
+		}
+		return apierr.Database()
+	}
+	sessionToken, err := h.startSession(c, user)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"token": sessionToken})
+}
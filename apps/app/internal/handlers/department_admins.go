@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// adminDepartment is a department the caller may act for, as listed by
+// MyDepartments.
+type adminDepartment struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Home bool   `json:"home"` // the user's own department rather than a grant
+}
+
+// Grants lists the departments a DeptAdmin administers besides their own.
+// GET /api/admin/users/:id/departments  (SuperAdmin only)
+func (h *User) Grants(c echo.Context) error {
+	grants, err := h.db.ListDepartmentGrants(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return apierr.Database()
+	}
+	if grants == nil {
+		grants = []*database.DepartmentGrant{}
+	}
+	return c.JSON(http.StatusOK, grants)
+}
+
+// Grant lets a DeptAdmin also administer another department, which they
+// then select per request with X-Department-Id.
+// PUT /api/admin/users/:id/departments/:dept_id  (SuperAdmin only)
+func (h *User) Grant(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, err := h.db.GetUserByID(ctx, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	if user.Role != mw.RoleDeptAdmin {
+		return apierr.New(http.StatusConflict, "USER_NOT_DEPT_ADMIN", "only department admins can be granted departments")
+	}
+	dept, err := h.db.GetDepartment(ctx, c.Param("dept_id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "DEPARTMENT_NOT_FOUND", "department not found")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	if sameDept(user.DepartmentID, &dept.ID) {
+		return apierr.Invalid("that is the user's own department", "dept_id")
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	if err := h.db.GrantDepartmentAdmin(ctx, user.ID, dept.ID, &userID); err != nil {
+		return apierr.Database()
+	}
+	return h.Grants(c)
+}
+
+// Revoke removes a department grant.
+// DELETE /api/admin/users/:id/departments/:dept_id  (SuperAdmin only)
+func (h *User) Revoke(c echo.Context) error {
+	removed, err := h.db.RevokeDepartmentAdmin(c.Request().Context(), c.Param("id"), c.Param("dept_id"))
+	if err != nil {
+		return apierr.Database()
+	}
+	if !removed {
+		return apierr.New(http.StatusNotFound, "GRANT_NOT_FOUND", "user has no grant for that department")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MyDepartments lists the departments the caller can act for: their own
+// and, for a DeptAdmin, any they have been granted. The frontend offers a
+// switcher when there is more than one.
+// GET /api/me/departments
+func (h *User) MyDepartments(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, err := h.db.GetUserByID(ctx, c.Get(mw.CtxUserID).(string))
+	if err != nil {
+		return apierr.Database()
+	}
+	out := []adminDepartment{}
+	if user.DepartmentID != nil {
+		name := ""
+		if user.DepartmentName != nil {
+			name = *user.DepartmentName
+		}
+		out = append(out, adminDepartment{ID: *user.DepartmentID, Name: name, Home: true})
+	}
+	if user.Role == mw.RoleDeptAdmin {
+		grants, err := h.db.ListDepartmentGrants(ctx, user.ID)
+		if err != nil {
+			return apierr.Database()
+		}
+		for _, g := range grants {
+			out = append(out, adminDepartment{ID: g.DepartmentID, Name: g.DepartmentName})
+		}
+	}
+	return c.JSON(http.StatusOK, out)
+}
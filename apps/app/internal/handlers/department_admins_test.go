@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestDepartmentGrants_ActForGrantedDepartments verifies a DeptAdmin granted
+// a second department can act for it with X-Department-Id, but not for a
+// department they were not granted, and loses the grant when demoted.
+func TestDepartmentGrants_ActForGrantedDepartments(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	fac, _ := db.CreateDepartment(ctx, "Facilities", "")
+	fin, _ := db.CreateDepartment(ctx, "Finance", "")
+	root, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	head, _ := db.CreateUser(ctx, "head@example.com", "Head of Ops", mw.RoleDeptAdmin, nil, testutil.Ptr(ops.ID))
+	staff, _ := db.CreateUser(ctx, "staff@example.com", "Staff", mw.RoleStaff, nil, testutil.Ptr(ops.ID))
+
+	e := echo.New()
+	keys := testutil.NewKeyring(t, db)
+	h := NewUser(db, email.New(), keys)
+	grant := func(userID, deptID string) error {
+		c, _ := testutil.NewContext(e, http.MethodPut, "", userID, mw.RoleSuperAdmin, nil)
+		c.Set(mw.CtxUserID, root.ID)
+		c.SetParamNames("id", "dept_id")
+		c.SetParamValues(userID, deptID)
+		return h.Grant(c)
+	}
+	if err := grant(head.ID, fac.ID); err != nil {
+		t.Fatalf("Grant: %v", err)
+	}
+	var he *echo.HTTPError
+	if err := grant(staff.ID, fac.ID); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Errorf("grant to Staff: err = %v; want 409", err)
+	}
+
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleDeptAdmin, testutil.Ptr(ops.ID))
+	c.Set(mw.CtxUserID, head.ID)
+	if err := h.MyDepartments(c); err != nil {
+		t.Fatalf("MyDepartments: %v", err)
+	}
+	var mine []adminDepartment
+	json.Unmarshal(rec.Body.Bytes(), &mine)
+	if len(mine) != 2 || !mine[0].Home || mine[0].ID != ops.ID || mine[1].ID != fac.ID {
+		t.Errorf("my departments = %+v; want Operations (home), Facilities", mine)
+	}
+
+	token, _ := keys.Sign(jwt.MapClaims{
+		"sub": head.ID, "role": head.Role, "type": "session",
+		"iat": time.Now().Unix(), "auth_time": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	actFor := func(deptID string) (*string, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		req.Header.Set(mw.HeaderDepartment, deptID)
+		var got *string
+		err := mw.NewAuth(keys, db).Require(func(c echo.Context) error {
+			got, _ = c.Get(mw.CtxDeptID).(*string)
+			return nil
+		})(e.NewContext(req, httptest.NewRecorder()))
+		return got, err
+	}
+	if got, err := actFor(fac.ID); err != nil || got == nil || *got != fac.ID {
+		t.Errorf("acting for Facilities: dept %v, err %v", got, err)
+	}
+	if got, err := actFor(""); err != nil || got == nil || *got != ops.ID {
+		t.Errorf("no header: dept %v, err %v; want Operations", got, err)
+	}
+	if _, err := actFor(fin.ID); !errors.As(err, &he) || he.Code != http.StatusForbidden {
+		t.Errorf("acting for Finance: err = %v; want 403", err)
+	}
+
+	if err := db.UpdateUser(ctx, head.ID, head.Name, head.Email, mw.RoleStaff, head.DepartmentID); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if granted, _ := db.HasDepartmentGrant(ctx, head.ID, fac.ID); granted {
+		t.Error("grant kept after the user stopped being a DeptAdmin")
+	}
+}
@@ -3,11 +3,14 @@ package handlers
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 
+	"policyflow/internal/apierr"
 	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
 )
 
 // Departments handles department management endpoints.
@@ -22,9 +25,10 @@ func NewDepartments(db *database.DB) *Departments {
 // List returns all departments. Available to all authenticated users.
 // GET /api/departments
 func (h *Departments) List(c echo.Context) error {
-	depts, err := h.db.ListDepartments()
+	ctx := c.Request().Context()
+	depts, err := h.db.ListDepartments(ctx)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
 	}
 	if depts == nil {
 		depts = []*database.Department{}
@@ -35,31 +39,34 @@ func (h *Departments) List(c echo.Context) error {
 // Create creates a new department.
 // POST /api/departments  (SuperAdmin only)
 func (h *Departments) Create(c echo.Context) error {
+	ctx := c.Request().Context()
 	var body struct {
 		Name        string `json:"name"`
 		Description string `json:"description"`
 	}
 	if err := c.Bind(&body); err != nil || body.Name == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "name is required")
+		return apierr.Invalid("name is required", "name")
 	}
 
-	dept, err := h.db.CreateDepartment(body.Name, body.Description)
+	dept, err := h.db.CreateDepartment(ctx, body.Name, body.Description)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusConflict, "department already exists or database error")
+		return apierr.New(http.StatusConflict, "DEPARTMENT_EXISTS", "department already exists or database error")
 	}
+	mw.LogAudit(c, h.db, database.ActivityDepartmentCreated, "department", dept.ID, dept.Name)
 	return c.JSON(http.StatusCreated, dept)
 }
 
 // Update updates a department's name and description.
 // PUT /api/departments/:id  (SuperAdmin only)
 func (h *Departments) Update(c echo.Context) error {
+	ctx := c.Request().Context()
 	id := c.Param("id")
-	existing, err := h.db.GetDepartment(id)
+	existing, err := h.db.GetDepartment(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusNotFound, "department not found")
+			return apierr.New(http.StatusNotFound, "DEPARTMENT_NOT_FOUND", "department not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
 	}
 
 	var body struct {
@@ -67,7 +74,7 @@ func (h *Departments) Update(c echo.Context) error {
 		Description string `json:"description"`
 	}
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid body")
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
 	}
 	if body.Name == "" {
 		body.Name = existing.Name
@@ -76,34 +83,114 @@ func (h *Departments) Update(c echo.Context) error {
 		body.Description = existing.Description
 	}
 
-	dept, err := h.db.UpdateDepartment(id, body.Name, body.Description)
+	dept, err := h.db.UpdateDepartment(ctx, id, body.Name, body.Description)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
 	}
+	details := dept.Name
+	if dept.Name != existing.Name {
+		details = existing.Name + " → " + dept.Name
+	}
+	mw.LogAudit(c, h.db, database.ActivityDepartmentUpdated, "department", dept.ID, details)
 	return c.JSON(http.StatusOK, dept)
 }
 
-// Delete removes a department. Returns 409 if policies are still assigned to it.
-// DELETE /api/departments/:id  (SuperAdmin only)
+// Delete removes a department. Without reassign_to it returns 409 while
+// policies or users still belong to it; with reassign_to it first moves them,
+// along with department assignments and report schedules, to that
+// department and reports how many policies and users moved.
+// DELETE /api/departments/:id?reassign_to=  (SuperAdmin only)
 func (h *Departments) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
 	id := c.Param("id")
-	if _, err := h.db.GetDepartment(id); err != nil {
+	dept, err := h.db.GetDepartment(ctx, id)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusNotFound, "department not found")
+			return apierr.New(http.StatusNotFound, "DEPARTMENT_NOT_FOUND", "department not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
+	}
+
+	if to := c.QueryParam("reassign_to"); to != "" {
+		return h.deleteReassigning(c, dept, to)
 	}
 
-	hasPolicies, err := h.db.DepartmentHasPolicies(id)
+	hasPolicies, err := h.db.DepartmentHasPolicies(ctx, id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
 	}
 	if hasPolicies {
-		return echo.NewHTTPError(http.StatusConflict, "department has assigned policies; reassign them first")
+		return apierr.New(http.StatusConflict, "DEPARTMENT_IN_USE", "department has assigned policies; reassign them first")
+	}
+	hasUsers, err := h.db.DepartmentHasUsers(ctx, id)
+	if err != nil {
+		return apierr.Database()
+	}
+	if hasUsers {
+		return apierr.New(http.StatusConflict, "DEPARTMENT_IN_USE", "department has users; reassign them first")
 	}
 
-	if err := h.db.DeleteDepartment(id); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+	if err := h.db.DeleteDepartment(ctx, id); err != nil {
+		return apierr.Database()
 	}
+	mw.LogAudit(c, h.db, database.ActivityDepartmentDeleted, "department", id, dept.Name)
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *Departments) deleteReassigning(c echo.Context, dept *database.Department, to string) error {
+	ctx := c.Request().Context()
+	if to == dept.ID {
+		return apierr.Invalid("reassign_to must be another department", "reassign_to")
+	}
+	target, err := h.db.GetDepartment(ctx, to)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.Invalid("reassign_to department not found", "reassign_to")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	moved, err := h.db.DeleteDepartmentReassigning(ctx, dept.ID, target.ID, &userID)
+	if err != nil {
+		return apierr.Database()
+	}
+	mw.LogAudit(c, h.db, database.ActivityDepartmentDeleted, "department", dept.ID,
+		fmt.Sprintf("%s (%d policies and %d users moved to %s)", dept.Name, moved.Policies, moved.Users, target.Name))
+	return c.JSON(http.StatusOK, moved)
+}
+
+// LegacyDepartments reports the values of the deprecated policies.department
+// text field still in use and the department each would be mapped to.
+// GET /api/admin/departments/legacy  (SuperAdmin only)
+func (h *Departments) LegacyDepartments(c echo.Context) error {
+	report, err := h.db.LegacyDepartments(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// ConsolidateLegacy maps legacy department values onto departments,
+// creating any that are missing. Fuzzy matches are only applied with
+// accept_fuzzy or an override; the rest are reported as skipped.
+// POST /api/admin/departments/legacy/consolidate  (SuperAdmin only)
+func (h *Departments) ConsolidateLegacy(c echo.Context) error {
+	var opts database.ConsolidateOptions
+	if err := c.Bind(&opts); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	result, err := h.db.ConsolidateLegacyDepartments(c.Request().Context(), opts)
+	if errors.Is(err, database.ErrUnknownDepartment) {
+		return apierr.New(http.StatusBadRequest, "DEPARTMENT_NOT_FOUND", err.Error())
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	for _, l := range result {
+		if l.Action == database.LegacyCreated {
+			mw.LogAudit(c, h.db, database.ActivityDepartmentCreated, "department", *l.DepartmentID, *l.DepartmentName)
+		}
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestDeleteDepartment_Reassign verifies that a department still in use is
+// refused without reassign_to and otherwise deleted after its policies,
+// users, and assignments move to the target.
+func TestDeleteDepartment_Reassign(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	old, _ := db.CreateDepartment(ctx, "Old", "")
+	merged, _ := db.CreateDepartment(ctx, "Merged", "")
+	p, _ := db.CreatePolicy(ctx, "Handbook", "", testutil.Ptr(old.ID), "department", nil)
+	org, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	u, _ := db.CreateUser(ctx, "u@example.com", "U", mw.RoleStaff, nil, testutil.Ptr(old.ID))
+	db.CreatePolicyAssignment(ctx, org.ID, database.AssignDepartment, old.ID, nil)
+	db.CreatePolicyAssignment(ctx, org.ID, database.AssignDepartment, merged.ID, nil)
+
+	e := echo.New()
+	h := NewDepartments(db)
+	del := func(query string) (int, error) {
+		c, rec := testutil.NewContext(e, http.MethodDelete, "", old.ID, mw.RoleSuperAdmin, nil)
+		c.Set(mw.CtxUserID, admin.ID)
+		c.Request().URL.RawQuery = query
+		err := h.Delete(c)
+		return rec.Code, err
+	}
+
+	var he *echo.HTTPError
+	if _, err := del(""); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Fatalf("delete in use: err = %v; want 409", err)
+	}
+	if _, err := del("reassign_to=" + old.ID); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("reassign to itself: err = %v; want 400", err)
+	}
+	if code, err := del("reassign_to=" + merged.ID); err != nil || code != http.StatusOK {
+		t.Fatalf("delete with reassign_to: code %d, err %v", code, err)
+	}
+
+	if _, err := db.GetDepartment(ctx, old.ID); err == nil {
+		t.Error("department still exists")
+	}
+	if got, _ := db.GetPolicy(ctx, p.ID); got.DepartmentID == nil || *got.DepartmentID != merged.ID || got.Version != p.Version+1 {
+		t.Errorf("policy = %+v; want moved to Merged with its version bumped", got)
+	}
+	if got, _ := db.GetUserByID(ctx, u.ID); got.DepartmentID == nil || *got.DepartmentID != merged.ID {
+		t.Errorf("user department = %v; want Merged", got.DepartmentID)
+	}
+	if as, _ := db.ListPolicyAssignments(ctx, org.ID); len(as) != 1 || as[0].TargetID != merged.ID {
+		t.Errorf("assignments = %+v; want one, to Merged", as)
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+)
+
+// maxTemplateBytes bounds an edited subject or body.
+const maxTemplateBytes = 64 << 10
+
+// EmailTemplates lets admins reword the emails PolicyFlow sends.
+type EmailTemplates struct {
+	db *database.DB
+}
+
+func NewEmailTemplates(db *database.DB) *EmailTemplates {
+	return &EmailTemplates{db: db}
+}
+
+// emailTemplateView is a template as used for sending, alongside its
+// default and the variables it may use.
+type emailTemplateView struct {
+	email.Template
+	Variables  []string       `json:"variables"`
+	Customized bool           `json:"customized"`
+	Default    email.Template `json:"default"`
+	UpdatedBy  *string        `json:"updated_by,omitempty"`
+	UpdatedAt  *time.Time     `json:"updated_at,omitempty"`
+}
+
+func templateView(def email.Template, edit *database.EmailTemplate) emailTemplateView {
+	v := emailTemplateView{Template: def, Variables: email.Variables(def.Name), Default: def}
+	if edit != nil {
+		v.Subject, v.Body, v.Customized = edit.Subject, edit.Body, true
+		v.UpdatedBy, v.UpdatedAt = edit.UpdatedBy, &edit.UpdatedAt
+	}
+	return v
+}
+
+// List returns every email template, edited or not.
+// GET /api/admin/email-templates  (SuperAdmin only)
+func (h *EmailTemplates) List(c echo.Context) error {
+	edits, err := h.db.ListEmailTemplates(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	byName := make(map[string]*database.EmailTemplate, len(edits))
+	for _, e := range edits {
+		byName[e.Name] = e
+	}
+	defaults := email.Defaults()
+	out := make([]emailTemplateView, len(defaults))
+	for i, def := range defaults {
+		out[i] = templateView(def, byName[def.Name])
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+// Update replaces a template's subject and body. Both are checked by
+// rendering them with example values, so a typo in a placeholder is
+// rejected here rather than discovered in a user's inbox.
+// PUT /api/admin/email-templates/:name  (SuperAdmin only)
+func (h *EmailTemplates) Update(c echo.Context) error {
+	t, err := h.bindTemplate(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	userID := c.Get(mw.CtxUserID).(string)
+	if err := h.db.SetEmailTemplate(ctx, t.Name, t.Subject, t.Body, &userID); err != nil {
+		return apierr.Database()
+	}
+	def, _ := email.Default(t.Name)
+	return c.JSON(http.StatusOK, templateView(def, &database.EmailTemplate{
+		Name: t.Name, Subject: t.Subject, Body: t.Body, UpdatedBy: &userID, UpdatedAt: time.Now().UTC(),
+	}))
+}
+
+// Reset discards the edit of a template, restoring the default wording.
+// DELETE /api/admin/email-templates/:name  (SuperAdmin only)
+func (h *EmailTemplates) Reset(c echo.Context) error {
+	if _, ok := email.Default(c.Param("name")); !ok {
+		return apierr.New(http.StatusNotFound, "TEMPLATE_NOT_FOUND", "email template not found")
+	}
+	if err := h.db.DeleteEmailTemplate(c.Request().Context(), c.Param("name")); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Preview renders a template with example values. The body holds the
+// draft being edited; omitted parts fall back to the template as stored.
+// POST /api/admin/email-templates/:name/preview  (SuperAdmin only)
+func (h *EmailTemplates) Preview(c echo.Context) error {
+	t, err := h.bindTemplate(c)
+	if err != nil {
+		return err
+	}
+	subject, body, _ := email.Preview(t)
+	return c.JSON(http.StatusOK, map[string]string{"subject": subject, "body": body})
+}
+
+// bindTemplate reads {subject, body} for the template named in the path,
+// filling omitted parts from the stored template, and checks that it
+// renders.
+func (h *EmailTemplates) bindTemplate(c echo.Context) (email.Template, error) {
+	t, ok := email.Default(c.Param("name"))
+	if !ok {
+		return t, apierr.New(http.StatusNotFound, "TEMPLATE_NOT_FOUND", "email template not found")
+	}
+	subject, body, edited, err := h.db.EmailTemplateOverride(c.Request().Context(), t.Name)
+	if err != nil {
+		return t, apierr.Database()
+	}
+	if edited {
+		t.Subject, t.Body = subject, body
+	}
+	var req struct {
+		Subject *string `json:"subject"`
+		Body    *string `json:"body"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return t, apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if req.Subject != nil {
+		t.Subject = *req.Subject
+	}
+	if req.Body != nil {
+		t.Body = *req.Body
+	}
+	if strings.TrimSpace(t.Subject) == "" {
+		return t, apierr.Invalid("subject is required", "subject")
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		return t, apierr.Invalid("body is required", "body")
+	}
+	if len(t.Subject) > maxTemplateBytes || len(t.Body) > maxTemplateBytes {
+		return t, apierr.Invalid("subject and body must each be under 64 KB", "subject", "body")
+	}
+	if _, _, err := email.Preview(t); err != nil {
+		field, _, _ := strings.Cut(err.Error(), ":")
+		return t, apierr.With(apierr.Invalid(err.Error(), field), "variables", email.Variables(t.Name))
+	}
+	return t, nil
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestEmailTemplates_EditPreviewReset verifies an edit is checked against
+// the template's variables before it is stored, previews render with
+// example values, and resetting restores the default.
+func TestEmailTemplates_EditPreviewReset(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	e := echo.New()
+	h := NewEmailTemplates(db)
+	call := func(fn echo.HandlerFunc, method, body string) (*httptest.ResponseRecorder, error) {
+		c, rec := testutil.NewContext(e, method, body, "", mw.RoleSuperAdmin, nil)
+		c.Set(mw.CtxUserID, admin.ID)
+		c.SetParamNames("name")
+		c.SetParamValues(email.TemplateMagicLink)
+		return rec, fn(c)
+	}
+
+	var he *echo.HTTPError
+	if _, err := call(h.Update, http.MethodPut, `{"body":"Hi {{.FirstName}}"}`); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Fatalf("unknown variable: err = %v; want 400", err)
+	}
+
+	if _, err := call(h.Update, http.MethodPut, `{"subject":"Sign in to Acme Policies","body":"Hello {{.Name}}: {{.URL}}"}`); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	subject, body, ok, _ := db.EmailTemplateOverride(ctx, email.TemplateMagicLink)
+	if !ok || subject != "Sign in to Acme Policies" || body != "Hello {{.Name}}: {{.URL}}" {
+		t.Errorf("stored = %q %q %v", subject, body, ok)
+	}
+
+	rec, err := call(h.Preview, http.MethodPost, `{}`)
+	if err != nil {
+		t.Fatalf("Preview: %v", err)
+	}
+	var preview map[string]string
+	json.Unmarshal(rec.Body.Bytes(), &preview)
+	if preview["subject"] != "Sign in to Acme Policies" || !strings.HasPrefix(preview["body"], "Hello Jane Doe: https://") {
+		t.Errorf("preview = %v", preview)
+	}
+
+	if _, err := call(h.Reset, http.MethodDelete, ""); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	rec, _ = call(h.List, http.MethodGet, "")
+	var list []emailTemplateView
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	def, _ := email.Default(email.TemplateMagicLink)
+	for _, v := range list {
+		if v.Name == email.TemplateMagicLink && (v.Customized || v.Subject != def.Subject) {
+			t.Errorf("after reset = %+v", v)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+
+	"policyflow/internal/database"
+	"policyflow/internal/events"
+	mw "policyflow/internal/middleware"
+)
+
+// sseHeartbeat keeps idle connections open through proxies.
+const sseHeartbeat = 25 * time.Second
+
+// dashboardInterval is how often the dashboard socket sends its counters
+// when no acknowledgement prompts it sooner.
+const dashboardInterval = 5 * time.Second
+
+// Events streams real-time events to signed-in clients.
+type Events struct {
+	db  *database.DB
+	hub *events.Hub
+}
+
+func NewEvents(db *database.DB, hub *events.Hub) *Events {
+	return &Events{db: db, hub: hub}
+}
+
+// Stream is a server-sent events stream of the publish, acknowledgement, and
+// notification events the caller may see. EventSource cannot send headers,
+// so the session token may be passed as ?token=.
+// GET /api/events
+func (h *Events) Stream(c echo.Context) error {
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+	sub := h.hub.Subscribe(c.Get(mw.CtxUserID).(string), c.Get(mw.CtxUserRole).(string), deptID)
+	defer h.hub.Unsubscribe(sub)
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // disable nginx buffering
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	w.Flush()
+
+	ticker := time.NewTicker(sseHeartbeat)
+	defer ticker.Stop()
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case ev := <-sub.C:
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+		}
+		w.Flush()
+	}
+}
+
+// DashboardCounters are the live figures sent over the dashboard socket.
+type DashboardCounters struct {
+	AcksLastMinute int       `json:"acks_last_minute"`
+	ActiveSessions int       `json:"active_sessions"` // users with the app or a dashboard open
+	At             time.Time `json:"at"`
+}
+
+// Dashboard is a WebSocket for the big-screen dashboard shown during
+// compliance campaigns. It sends DashboardCounters as a JSON message when
+// it opens, every five seconds, and straight after each acknowledgement the
+// caller can see; a DeptAdmin's counters cover their department. Browsers
+// cannot set headers on a WebSocket, so the session token may be passed as
+// ?token=, which also makes checking the origin unnecessary. Messages from
+// the client are ignored.
+// GET /api/ws  (DeptAdmin+)
+func (h *Events) Dashboard(c echo.Context) error {
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	role := c.Get(mw.CtxUserRole).(string)
+	own, _ := c.Get(mw.CtxDeptID).(*string)
+	websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			sub := h.hub.Subscribe(userID, role, own)
+			defer h.hub.Unsubscribe(sub)
+
+			ctx, cancel := context.WithCancel(c.Request().Context())
+			defer cancel()
+			go func() {
+				defer cancel()
+				var discard []byte
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+			}()
+
+			send := func() bool {
+				counters, err := h.dashboardCounters(ctx, deptID)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Printf("dashboard counters: %v", err)
+					}
+					return false
+				}
+				ws.SetWriteDeadline(time.Now().Add(dashboardInterval))
+				return websocket.JSON.Send(ws, counters) == nil
+			}
+			ticker := time.NewTicker(dashboardInterval)
+			defer ticker.Stop()
+			if !send() {
+				return
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				case ev := <-sub.C:
+					if ev.Type != events.PolicyAcknowledged {
+						continue
+					}
+				}
+				if !send() {
+					return
+				}
+			}
+		},
+	}.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+func (h *Events) dashboardCounters(ctx context.Context, deptID *string) (*DashboardCounters, error) {
+	now := time.Now().UTC()
+	acks, err := h.db.CountAcknowledgementsSince(ctx, deptID, now.Add(-time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	return &DashboardCounters{AcksLastMinute: acks, ActiveSessions: h.hub.ConnectedUsers(deptID), At: now}, nil
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+
+	"policyflow/internal/events"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestDashboard_PushesCountersOnAcknowledgement verifies that the dashboard
+// socket sends the counters on connect and again as soon as someone in the
+// department acknowledges, without waiting for the next tick.
+func TestDashboard_PushesCountersOnAcknowledgement(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	admin, _ := db.CreateUser(ctx, "lead@example.com", "Lead", mw.RoleDeptAdmin, nil, &eng.ID)
+	dev, _ := db.CreateUser(ctx, "dev@example.com", "Dev", mw.RoleStaff, nil, &eng.ID)
+	clerk, _ := db.CreateUser(ctx, "clerk@example.com", "Clerk", mw.RoleStaff, nil, &hr.ID)
+	p, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, clerk.ID, *p.CurrentVersionID) // another department
+
+	hub := events.NewHub()
+	app := hub.Subscribe(dev.ID, dev.Role, &eng.ID) // dev has the app open
+	defer hub.Unsubscribe(app)
+	h := NewEvents(db, hub)
+	e := echo.New()
+	e.GET("/api/ws", func(c echo.Context) error {
+		c.Set(mw.CtxUserID, admin.ID)
+		c.Set(mw.CtxUserRole, mw.RoleDeptAdmin)
+		c.Set(mw.CtxDeptID, &eng.ID)
+		return h.Dashboard(c)
+	})
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", "", srv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(dashboardInterval / 2))
+	var got DashboardCounters
+	if err := websocket.JSON.Receive(ws, &got); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+	if got.AcksLastMinute != 0 || got.ActiveSessions != 2 {
+		t.Errorf("on connect = %+v; want 0 acks and 2 sessions (dev and the dashboard)", got)
+	}
+
+	db.CreateAcknowledgement(ctx, dev.ID, *p.CurrentVersionID)
+	hub.Publish(events.Event{Type: events.PolicyAcknowledged, Audience: events.Audience{DepartmentID: &eng.ID, AdminsOnly: true}})
+	if err := websocket.JSON.Receive(ws, &got); err != nil {
+		t.Fatalf("after acknowledgement: %v", err)
+	}
+	if got.AcksLastMinute != 1 {
+		t.Errorf("after acknowledgement = %+v; want 1 ack", got)
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	"policyflow/internal/events"
+	mw "policyflow/internal/middleware"
+)
+
+// maxExceptionDays caps how long a single exception can run.
+const maxExceptionDays = 365
+
+// Exceptions handles requests for documented exceptions to a policy and
+// their approval by the policy's owner.
+type Exceptions struct {
+	policy  *Policy
+	mailer  *email.Mailer
+	baseURL string
+}
+
+func NewExceptions(policy *Policy, mailer *email.Mailer) *Exceptions {
+	return &Exceptions{policy: policy, mailer: mailer, baseURL: baseURLFromEnv()}
+}
+
+// Request files an exception to a policy the caller can see, with a
+// justification and a duration in days. The policy owner is notified.
+// POST /api/policies/:id/exceptions
+func (h *Exceptions) Request(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.policy.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	var body struct {
+		Justification string `json:"justification"`
+		DurationDays  int    `json:"duration_days"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	body.Justification = strings.TrimSpace(body.Justification)
+	if body.Justification == "" {
+		return apierr.Invalid("justification is required", "justification")
+	}
+	if body.DurationDays < 1 || body.DurationDays > maxExceptionDays {
+		return apierr.Invalid(fmt.Sprintf("duration_days must be between 1 and %d", maxExceptionDays), "duration_days")
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	open, err := h.policy.db.HasOpenException(ctx, policy.ID, userID, time.Now())
+	if err != nil {
+		return apierr.Database()
+	}
+	if open {
+		return apierr.New(http.StatusConflict, "EXCEPTION_EXISTS", "you already have a pending or active exception for this policy")
+	}
+	exc, err := h.policy.db.CreatePolicyException(ctx, policy.ID, userID, body.Justification, body.DurationDays)
+	if err != nil {
+		return apierr.Database()
+	}
+
+	approvers, err := h.approvers(c, policy)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(approvers))
+	for i, a := range approvers {
+		ids[i] = a.ID
+		if err := h.mailer.SendExceptionRequest(a.Email, a.Name, exc.UserName, policy.Title, exc.Justification,
+			exc.DurationDays, h.baseURL+"/admin"); err != nil {
+			log.Printf("exception request email to %s: %v", a.Email, err)
+		}
+	}
+	h.policy.events.Publish(events.Event{
+		Type: events.Notification,
+		Data: map[string]any{
+			"kind":         "exception_requested",
+			"policy_id":    policy.ID,
+			"exception_id": exc.ID,
+			"message":      fmt.Sprintf("%s requested an exception to %q.", exc.UserName, policy.Title),
+		},
+		Audience: events.Audience{UserIDs: ids},
+	})
+	return c.JSON(http.StatusCreated, exc)
+}
+
+// approvers returns who should review exceptions to policy: its owner or,
+// if the owner is gone, every active SuperAdmin.
+func (h *Exceptions) approvers(c echo.Context, policy *database.Policy) ([]*database.User, error) {
+	ctx := c.Request().Context()
+	if policy.CreatedBy != nil {
+		owner, err := h.policy.db.GetUserByID(ctx, *policy.CreatedBy)
+		if err == nil && owner.DeactivatedAt == nil {
+			return []*database.User{owner}, nil
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, apierr.Database()
+		}
+	}
+	users, err := h.policy.db.ListActiveUsers(ctx)
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	var out []*database.User
+	for _, u := range users {
+		if u.Role == mw.RoleSuperAdmin {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+// Mine lists the caller's own exception requests.
+// GET /api/me/exceptions
+func (h *Exceptions) Mine(c echo.Context) error {
+	return h.list(c, database.ExceptionFilter{UserID: c.Get(mw.CtxUserID).(string)})
+}
+
+// List returns the exceptions the caller can decide on: all of them for
+// SuperAdmin, and for DeptAdmin those on policies they own or that belong to
+// their department. Filter with ?status=pending|approved|denied.
+// GET /api/admin/exceptions?status=
+func (h *Exceptions) List(c echo.Context) error {
+	f := database.ExceptionFilter{Status: c.QueryParam("status")}
+	switch f.Status {
+	case "", database.ExceptionPending, database.ExceptionApproved, database.ExceptionDenied:
+	default:
+		return apierr.Invalid("status must be pending, approved, or denied", "status")
+	}
+	if c.Get(mw.CtxUserRole) != mw.RoleSuperAdmin {
+		f.ApproverID = c.Get(mw.CtxUserID).(string)
+		f.ApproverDeptID, _ = c.Get(mw.CtxDeptID).(*string)
+	}
+	return h.list(c, f)
+}
+
+func (h *Exceptions) list(c echo.Context, f database.ExceptionFilter) error {
+	out, err := h.policy.db.ListPolicyExceptions(c.Request().Context(), f)
+	if err != nil {
+		return apierr.Database()
+	}
+	if out == nil {
+		out = []*database.PolicyException{}
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+// Decide approves or denies a pending exception. Only the policy owner,
+// SuperAdmin, or a DeptAdmin of the policy's department may decide, and
+// nobody may decide on their own request. An approved exception runs for
+// its requested duration from now.
+// PUT /api/admin/exceptions/:id
+func (h *Exceptions) Decide(c echo.Context) error {
+	ctx := c.Request().Context()
+	var body struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if body.Status != database.ExceptionApproved && body.Status != database.ExceptionDenied {
+		return apierr.Invalid("status must be approved or denied", "status")
+	}
+
+	exc, err := h.policy.db.GetPolicyException(ctx, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "EXCEPTION_NOT_FOUND", "exception not found")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	policy, err := h.policy.db.GetPolicy(ctx, exc.PolicyID)
+	if err != nil {
+		return apierr.Database()
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+	isOwner := policy.CreatedBy != nil && *policy.CreatedBy == userID
+	if c.Get(mw.CtxUserRole) != mw.RoleSuperAdmin && !isOwner && !sameDept(deptID, policy.DepartmentID) {
+		return apierr.New(http.StatusNotFound, "EXCEPTION_NOT_FOUND", "exception not found")
+	}
+	if exc.UserID == userID {
+		return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "cannot decide on your own exception")
+	}
+
+	now := time.Now().UTC()
+	var expiresAt *time.Time
+	if body.Status == database.ExceptionApproved {
+		t := now.AddDate(0, 0, exc.DurationDays)
+		expiresAt = &t
+	}
+	err = h.policy.db.DecidePolicyException(ctx, exc.ID, body.Status, userID, strings.TrimSpace(body.Note), now, expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusConflict, "EXCEPTION_DECIDED", "exception has already been decided")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	exc, err = h.policy.db.GetPolicyException(ctx, exc.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+
+	approved := exc.Status == database.ExceptionApproved
+	if err := h.mailer.SendExceptionDecision(exc.UserEmail, exc.UserName, exc.PolicyTitle, approved, exc.DecisionNote, exc.ExpiresAt); err != nil {
+		log.Printf("exception decision email to %s: %v", exc.UserEmail, err)
+	}
+	h.policy.events.Publish(events.Event{
+		Type: events.Notification,
+		Data: map[string]any{
+			"kind":         "exception_" + exc.Status,
+			"policy_id":    exc.PolicyID,
+			"exception_id": exc.ID,
+			"message":      fmt.Sprintf("Your exception to %q was %s.", exc.PolicyTitle, exc.Status),
+		},
+		Audience: events.Audience{UserIDs: []string{exc.UserID}},
+	})
+	return c.JSON(http.StatusOK, exc)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestExceptions_RequestApproveAndReport walks an exception from request to
+// approval by the policy owner and checks it shows in the compliance matrix.
+func TestExceptions_RequestApproveAndReport(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	owner, _ := db.CreateUser(ctx, "owner@example.com", "Owner", mw.RoleDeptAdmin, nil, testutil.Ptr(hr.ID))
+	other, _ := db.CreateUser(ctx, "other@example.com", "Other", mw.RoleDeptAdmin, nil, testutil.Ptr(eng.ID))
+	staff, _ := db.CreateUser(ctx, "staff@example.com", "Staff", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	p, _ := db.CreatePolicy(ctx, "Remote Work", "", testutil.Ptr(hr.ID), "organization", &owner.ID)
+	testutil.Publish(t, db, p)
+
+	e := echo.New()
+	h := NewExceptions(NewPolicy(db), email.New())
+	as := func(u *database.User, body, id string) (echo.Context, func() []byte) {
+		c, rec := testutil.NewContext(e, http.MethodPost, body, id, u.Role, u.DepartmentID)
+		c.Set(mw.CtxUserID, u.ID)
+		return c, func() []byte { return rec.Body.Bytes() }
+	}
+
+	c, body := as(staff, `{"justification":"Working abroad","duration_days":30}`, p.ID)
+	if err := h.Request(c); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	var exc database.PolicyException
+	json.Unmarshal(body(), &exc)
+	var he *echo.HTTPError
+	c, _ = as(staff, `{"justification":"Again","duration_days":30}`, p.ID)
+	if err := h.Request(c); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Errorf("second request: got %v; want 409", err)
+	}
+
+	c, _ = as(other, `{"status":"approved"}`, exc.ID)
+	if err := h.Decide(c); !errors.As(err, &he) || he.Code != http.StatusNotFound {
+		t.Errorf("decide by admin of another department: got %v; want 404", err)
+	}
+	c, body = as(owner, `{"status":"approved","note":"OK for the trip"}`, exc.ID)
+	if err := h.Decide(c); err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+	json.Unmarshal(body(), &exc)
+	if exc.Status != database.ExceptionApproved || exc.ExpiresAt == nil {
+		t.Fatalf("decided exception = %+v", exc)
+	}
+
+	m, err := db.BuildAckMatrix(ctx, testutil.Ptr(eng.ID), exc.CreatedAt)
+	if err != nil {
+		t.Fatalf("BuildAckMatrix: %v", err)
+	}
+	for i, u := range m.Users {
+		if u.ID == staff.ID && m.Cells[i][0].ExceptionUntil == nil {
+			t.Errorf("matrix cell for staff has no exception: %+v", m.Cells[i][0])
+		}
+	}
+	if d := m.ByDepartment(); len(d) != 1 || d[0].Exceptions != 1 {
+		t.Errorf("department compliance = %+v; want 1 active exception", d)
+	}
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// ExportUsers streams the user directory as CSV for HR reporting, including
+// each user's count of outstanding acknowledgements. DeptAdmin receives their
+// own department only.
+// GET /api/admin/users/export.csv
+func (h *User) ExportUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+	read := h.db.Reader()
+	role := c.Get(mw.CtxUserRole).(string)
+
+	var users []*database.User
+	var err error
+	if role == mw.RoleSuperAdmin {
+		users, err = read.ListUsers(ctx)
+	} else {
+		deptID, _ := c.Get(mw.CtxDeptID).(*string)
+		if deptID == nil {
+			return apierr.New(http.StatusForbidden, "DEPARTMENT_REQUIRED", "department admin must belong to a department")
+		}
+		users, err = read.ListUsersByDepartment(ctx, *deptID)
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+
+	out, err := streamCSV(c, "users.csv", []string{"id", "email", "name", "role", "department", "status", "created_at", "last_login_at", "outstanding_acknowledgements"})
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		pending, err := read.ListPendingPoliciesForUser(ctx, u.ID, u.Role, u.DepartmentID)
+		if err != nil {
+			return err
+		}
+		status := "active"
+		if u.DeactivatedAt != nil {
+			status = "deactivated"
+		}
+		if err := out.Write([]string{
+			u.ID, u.Email, u.Name, u.Role, deref(u.DepartmentName), status,
+			formatTime(&u.CreatedAt), formatTime(u.LastLoginAt), strconv.Itoa(len(pending)),
+		}); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// exportFlushRows is how many rows a streamed export writes between
+// flushes to the client.
+const exportFlushRows = 500
+
+// csvStream writes a CSV attachment row by row, flushing every
+// exportFlushRows rows, so an export never holds the whole file in memory.
+// Cells that a spreadsheet would evaluate as formulas are prefixed with a
+// quote.
+type csvStream struct {
+	res  *echo.Response
+	w    *csv.Writer
+	rows int
+}
+
+// streamCSV starts a CSV attachment named filename with its header row.
+// Errors must be reported before calling it: the response is committed.
+func streamCSV(c echo.Context, filename string, header []string) (*csvStream, error) {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	res.WriteHeader(http.StatusOK)
+	s := &csvStream{res: res, w: csv.NewWriter(res)}
+	return s, s.Write(header)
+}
+
+func (s *csvStream) Write(row []string) error {
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+			row[i] = "'" + cell
+		}
+	}
+	if err := s.w.Write(row); err != nil {
+		return err
+	}
+	if s.rows++; s.rows%exportFlushRows == 0 {
+		s.w.Flush()
+		if err := s.w.Error(); err != nil {
+			return err
+		}
+		flush(s.res)
+	}
+	return nil
+}
+
+// Close writes out any buffered rows.
+func (s *csvStream) Close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// writeCSV sends rows, the first being the header, as a CSV attachment
+// named filename.
+func writeCSV(c echo.Context, filename string, rows [][]string) error {
+	s, err := streamCSV(c, filename, rows[0])
+	if err != nil {
+		return err
+	}
+	for _, row := range rows[1:] {
+		if err := s.Write(row); err != nil {
+			return err
+		}
+	}
+	return s.Close()
+}
+
+// jsonStream writes a JSON array one element at a time, flushing every
+// exportFlushRows elements. The array may sit inside an object: head is
+// everything up to and including its "[", tail everything from its "]".
+type jsonStream struct {
+	res   *echo.Response
+	tail  string
+	items int
+}
+
+// streamJSON starts a JSON response with head. Errors must be reported
+// before calling it: the response is committed.
+func streamJSON(c echo.Context, head, tail string) (*jsonStream, error) {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	res.WriteHeader(http.StatusOK)
+	_, err := io.WriteString(res, head)
+	return &jsonStream{res: res, tail: tail}, err
+}
+
+func (s *jsonStream) Write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if s.items > 0 {
+		data = append([]byte{','}, data...)
+	}
+	if _, err := s.res.Write(data); err != nil {
+		return err
+	}
+	if s.items++; s.items%exportFlushRows == 0 {
+		flush(s.res)
+	}
+	return nil
+}
+
+// Close ends the array and whatever encloses it.
+func (s *jsonStream) Close() error {
+	_, err := io.WriteString(s.res, s.tail)
+	return err
+}
+
+// flush sends what has been written so far, if the connection allows it.
+func flush(res *echo.Response) {
+	_ = http.NewResponseController(res.Writer).Flush()
+}
+
+// writeXLSX sends a rendered workbook as an attachment named filename.
+func writeXLSX(c echo.Context, filename string, data []byte) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Blob(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", data)
+}
+
+// formatTime renders an optional timestamp as RFC3339, or "" for nil.
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// catalogEntry is one row of the policy inventory export.
+type catalogEntry struct {
+	ID             string     `json:"id"`
+	Title          string     `json:"title"`
+	Status         string     `json:"status"`
+	Department     string     `json:"department"`
+	Visibility     string     `json:"visibility_type"`
+	CurrentVersion string     `json:"current_version"`
+	EffectiveDate  *time.Time `json:"effective_date"` // effective_from of the current version, else when it went live
+	ExpiryDate     *time.Time `json:"expiry_date"`    // effective_to of the current version
+	Owner          string     `json:"owner"`
+	Required       int        `json:"required_count"`
+	Acknowledged   int        `json:"acknowledged_count"`
+	AckPercentage  float64    `json:"ack_percentage"`
+}
+
+// ExportCatalog lists every policy with its status, ownership, current
+// version, and acknowledgement coverage, as JSON or (?format=csv) CSV.
+// DeptAdmin receives their own department's policies only.
+// GET /api/admin/policies/export
+func (h *Policy) ExportCatalog(c echo.Context) error {
+	ctx := c.Request().Context()
+	read := h.db.Reader()
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return apierr.Invalid("format must be json or csv", "format")
+	}
+
+	policies, err := read.ListPolicies(ctx)
+	if err != nil {
+		return apierr.Database()
+	}
+	coverage, err := read.AckCoverageByPolicy(ctx)
+	if err != nil {
+		return apierr.Database()
+	}
+	var callerDeptID *string
+	if c.Get(mw.CtxUserRole) == mw.RoleDeptAdmin {
+		callerDeptID, _ = c.Get(mw.CtxDeptID).(*string)
+	}
+
+	write, done, err := catalogWriter(c, format)
+	if err != nil {
+		return err
+	}
+	for _, p := range policies {
+		if c.Get(mw.CtxUserRole) == mw.RoleDeptAdmin && !sameDept(callerDeptID, p.DepartmentID) {
+			continue
+		}
+		e := catalogEntry{
+			ID:         p.ID,
+			Title:      p.Title,
+			Status:     p.Status,
+			Department: deref(p.DepartmentName),
+			Visibility: p.VisibilityType,
+			Owner:      deref(p.CreatedByName),
+		}
+		if e.Department == "" {
+			e.Department = p.Department // legacy free-text department
+		}
+		if p.CurrentVersionID != nil {
+			v, err := read.GetPolicyVersion(ctx, *p.CurrentVersionID)
+			if err != nil {
+				return err
+			}
+			e.CurrentVersion = v.VersionString
+			e.EffectiveDate, e.ExpiryDate = v.PublishedAt, v.EffectiveTo
+			if v.EffectiveFrom != nil {
+				e.EffectiveDate = v.EffectiveFrom
+			}
+		}
+		if cov := coverage[p.ID]; cov != nil {
+			e.Required, e.Acknowledged, e.AckPercentage = cov.EligibleUserCount, cov.AckCount, cov.CompliancePct
+		}
+		if err := write(e); err != nil {
+			return err
+		}
+	}
+	return done()
+}
+
+// catalogWriter starts the catalog export in format and returns functions
+// to stream each entry and to finish.
+func catalogWriter(c echo.Context, format string) (write func(catalogEntry) error, done func() error, err error) {
+	if format != "csv" {
+		out, err := streamJSON(c, "[", "]")
+		return func(e catalogEntry) error { return out.Write(e) }, out.Close, err
+	}
+	out, err := streamCSV(c, "policies.csv", []string{"id", "title", "status", "department", "visibility_type", "current_version",
+		"effective_date", "expiry_date", "owner", "required_count", "acknowledged_count", "ack_percentage"})
+	return func(e catalogEntry) error {
+		return out.Write([]string{
+			e.ID, e.Title, e.Status, e.Department, e.Visibility, e.CurrentVersion,
+			formatTime(e.EffectiveDate), formatTime(e.ExpiryDate), e.Owner, strconv.Itoa(e.Required), strconv.Itoa(e.Acknowledged),
+			strconv.FormatFloat(e.AckPercentage, 'f', 1, 64),
+		})
+	}, out.Close, err
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestExportUsers_DeptAdminScoped verifies that a DeptAdmin's export only
+// contains their own department and neutralizes formula-like cells.
+func TestExportUsers_DeptAdminScoped(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	db.CreateUser(ctx, "eve@example.com", "=HYPERLINK()", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	db.CreateUser(ctx, "hal@example.com", "Hal", mw.RoleStaff, nil, testutil.Ptr(hr.ID))
+
+	e := echo.New()
+	h := NewUser(db, email.New(), testutil.NewKeyring(t, db))
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleDeptAdmin, testutil.Ptr(eng.ID))
+	if err := h.ExportUsers(c); err != nil {
+		t.Fatalf("ExportUsers: %v", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d; want header + 1", len(rows))
+	}
+	if rows[1][1] != "eve@example.com" || rows[1][2] != "'=HYPERLINK()" {
+		t.Errorf("row = %v; want eve with escaped name", rows[1])
+	}
+}
+
+// TestExportCatalog_AckPercentage verifies coverage counts only the active
+// users a policy is required for.
+func TestExportCatalog_AckPercentage(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	a, _ := db.CreateUser(ctx, "a@example.com", "A", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	db.CreateUser(ctx, "b@example.com", "B", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	db.CreateUser(ctx, "c@example.com", "C", mw.RoleStaff, nil, nil) // outside the department
+	gone, _ := db.CreateUser(ctx, "d@example.com", "D", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	db.DeactivateUser(ctx, gone.ID)
+
+	p, _ := db.CreatePolicy(ctx, "Eng Policy", "", testutil.Ptr(eng.ID), "department", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, a.ID, *p.CurrentVersionID)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	if err := h.ExportCatalog(c); err != nil {
+		t.Fatalf("ExportCatalog: %v", err)
+	}
+	var got []catalogEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got) != 1 || got[0].Required != 2 || got[0].Acknowledged != 1 || got[0].AckPercentage != 50 {
+		t.Errorf("catalog = %+v; want 1 of 2 acknowledged (50%%)", got)
+	}
+}
+
+// TestExportCatalog_ReadPool verifies an export served from the read pool
+// completes while a write transaction holds the single writer connection.
+func TestExportCatalog_ReadPool(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "pf.db")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	db := database.New(conn)
+	if err := db.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := db.CreatePolicy(ctx, "Handbook", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+
+	readDB, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=query_only(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readDB.Close()
+	db.SetReadPool(readDB)
+	if _, err := db.Reader().CreateDepartment(ctx, "Nope", ""); err == nil {
+		t.Error("write through the read pool succeeded")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE policies SET title = 'Renamed'`); err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	timeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	c.SetRequest(c.Request().WithContext(timeout))
+	if err := NewPolicy(db).ExportCatalog(c); err != nil {
+		t.Fatalf("ExportCatalog: %v", err)
+	}
+	var got []catalogEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got) != 1 || got[0].Title != "Handbook" {
+		t.Errorf("catalog = %+v; want the last committed title", got)
+	}
+}
+
+// TestGDPRExport_CollectsSubjectData verifies the bundle contains the user's
+// profile, acknowledgements, and the reports emailed to them, and nothing
+// about other users.
+func TestGDPRExport_CollectsSubjectData(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", mw.RoleStaff, nil, nil)
+	bob, _ := db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Privacy", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, ada.ID, *p.CurrentVersionID)
+	db.CreateAcknowledgement(ctx, bob.ID, *p.CurrentVersionID)
+	db.CreateReportSchedule(ctx, &database.ReportSchedule{Name: "Weekly", Frequency: "weekly", Format: "csv",
+		Recipients: []string{"Ada@example.com"}, CreatedBy: &bob.ID})
+
+	e := echo.New()
+	h := NewUser(db, email.New(), testutil.NewKeyring(t, db))
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	c.SetParamValues(ada.ID)
+	if err := h.GDPRExport(c); err != nil {
+		t.Fatalf("GDPRExport: %v", err)
+	}
+	var got struct {
+		Profile          map[string]any   `json:"profile"`
+		Acknowledgements []map[string]any `json:"acknowledgements"`
+		ReportSchedules  []map[string]any `json:"report_schedules"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Profile["email"] != "ada@example.com" {
+		t.Errorf("profile = %v", got.Profile)
+	}
+	if len(got.Acknowledgements) != 1 || got.Acknowledgements[0]["policy_title"] != "Privacy" {
+		t.Errorf("acknowledgements = %v; want one for Privacy", got.Acknowledgements)
+	}
+	if len(got.ReportSchedules) != 1 || got.ReportSchedules[0]["role"] != "recipient" {
+		t.Errorf("report schedules = %v; want one received", got.ReportSchedules)
+	}
+}
+
+// TestExportAcknowledgements_StreamsEveryRow verifies the CSV export of a
+// version's acknowledgements carries every row, past the first flush.
+func TestExportAcknowledgements_StreamsEveryRow(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	p, _ := db.CreatePolicy(ctx, "Handbook", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	n := exportFlushRows + 1
+	for i := range n {
+		u, _ := db.CreateUser(ctx, fmt.Sprintf("user%d@example.com", i), "User", mw.RoleStaff, nil, nil)
+		db.CreateAcknowledgement(ctx, u.ID, *p.CurrentVersionID)
+	}
+
+	e := echo.New()
+	c, rec := testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleSuperAdmin, nil)
+	c.Request().URL.RawQuery = "format=csv"
+	if err := NewPolicy(db).Acknowledgements(c); err != nil {
+		t.Fatalf("Acknowledgements: %v", err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(rows) != n+1 || rows[1][0] != "Handbook" || rows[1][1] != "v1.0.0" {
+		t.Errorf("rows = %d, first %v; want header + %d", len(rows), rows[1], n)
+	}
+}
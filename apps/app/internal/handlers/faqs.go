@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// Limits on a policy's FAQs.
+const (
+	maxPolicyFAQs     = 100
+	maxFAQQuestionLen = 500
+	maxFAQAnswerLen   = 20000
+)
+
+// FAQs lists the questions and answers clarifying a policy, in order.
+// GET /api/policies/:id/faqs
+func (h *Policy) FAQs(c echo.Context) error {
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	faqs, err := h.db.ListPolicyFAQs(c.Request().Context(), policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if faqs == nil {
+		faqs = []*database.PolicyFAQ{}
+	}
+	return c.JSON(http.StatusOK, faqs)
+}
+
+type faqRequest struct {
+	Question *string `json:"question"`
+	Answer   *string `json:"answer"`
+	Position *int    `json:"position"` // 0-based; PUT only
+}
+
+// validate applies req to f, trimming and checking the question and answer.
+func (req *faqRequest) validate(f *database.PolicyFAQ) error {
+	if req.Question != nil {
+		f.Question = strings.TrimSpace(*req.Question)
+	}
+	if req.Answer != nil {
+		f.Answer = strings.TrimSpace(*req.Answer)
+	}
+	if f.Question == "" || len(f.Question) > maxFAQQuestionLen {
+		return apierr.Invalid("question is required and must be under 500 characters", "question")
+	}
+	if f.Answer == "" || len(f.Answer) > maxFAQAnswerLen {
+		return apierr.Invalid("answer is required and must be under 20000 characters", "answer")
+	}
+	return nil
+}
+
+// CreateFAQ adds a question and answer after the policy's others. It does
+// not change the policy's version, so nobody is asked to acknowledge again.
+// POST /api/policies/:id/faqs  {"question", "answer"}
+func (h *Policy) CreateFAQ(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	var req faqRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	f := &database.PolicyFAQ{}
+	if err := req.validate(f); err != nil {
+		return err
+	}
+	existing, err := h.db.ListPolicyFAQs(ctx, policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if len(existing) >= maxPolicyFAQs {
+		return apierr.New(http.StatusConflict, "TOO_MANY_FAQS", "a policy can have at most 100 FAQs")
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	f, err = h.db.CreatePolicyFAQ(ctx, policy.ID, f.Question, f.Answer, &userID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusCreated, f)
+}
+
+// UpdateFAQ edits a question or answer, or moves it with position;
+// omitted fields keep their values.
+// PUT /api/policies/:id/faqs/:faqId  {"question", "answer", "position"}
+func (h *Policy) UpdateFAQ(c echo.Context) error {
+	f, err := h.loadFAQ(c)
+	if err != nil {
+		return err
+	}
+	var req faqRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if err := req.validate(f); err != nil {
+		return err
+	}
+	if req.Position != nil {
+		f.Position = *req.Position
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	f.UpdatedBy = &userID
+	ctx := c.Request().Context()
+	if err := h.db.UpdatePolicyFAQ(ctx, f); err != nil {
+		return apierr.Database()
+	}
+	if f, err = h.db.GetPolicyFAQ(ctx, f.PolicyID, f.ID); err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, f)
+}
+
+// DeleteFAQ removes a question and answer.
+// DELETE /api/policies/:id/faqs/:faqId
+func (h *Policy) DeleteFAQ(c echo.Context) error {
+	f, err := h.loadFAQ(c)
+	if err != nil {
+		return err
+	}
+	if err := h.db.DeletePolicyFAQ(c.Request().Context(), f.ID); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// loadFAQ loads the :faqId FAQ of the :id policy, which the caller must
+// manage.
+func (h *Policy) loadFAQ(c echo.Context) (*database.PolicyFAQ, error) {
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return nil, err
+	}
+	f, err := h.db.GetPolicyFAQ(c.Request().Context(), policy.ID, c.Param("faqId"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apierr.New(http.StatusNotFound, "FAQ_NOT_FOUND", "FAQ not found")
+	}
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	return f, nil
+}
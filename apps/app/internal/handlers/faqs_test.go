@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestFAQs_EditingDoesNotAskForReacknowledgement verifies that FAQs are
+// kept in order and can be moved, that adding one leaves the policy's
+// version and acknowledgements alone, and that only the policy's managers
+// may edit them.
+func TestFAQs_EditingDoesNotAskForReacknowledgement(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	lead, _ := db.CreateUser(ctx, "lead@example.com", "Lead", mw.RoleDeptAdmin, nil, &eng.ID)
+	dev, _ := db.CreateUser(ctx, "dev@example.com", "Dev", mw.RoleStaff, nil, &eng.ID)
+	p, _ := db.CreatePolicy(ctx, "On-call", "", &eng.ID, "department", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, dev.ID, *p.CurrentVersionID)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	create := func(body string) *database.PolicyFAQ {
+		t.Helper()
+		c, rec := testutil.NewContext(e, http.MethodPost, body, p.ID, mw.RoleDeptAdmin, &eng.ID)
+		c.Set(mw.CtxUserID, lead.ID)
+		if err := h.CreateFAQ(c); err != nil {
+			t.Fatalf("CreateFAQ: %v", err)
+		}
+		var f database.PolicyFAQ
+		json.Unmarshal(rec.Body.Bytes(), &f)
+		return &f
+	}
+	create(`{"question":"Who covers holidays?","answer":"The secondary on-call."}`)
+	pager := create(`{"question":"Do I need a pager?","answer":"No, the app is enough."}`)
+
+	c, _ := testutil.NewContext(e, http.MethodPut, `{"position":0}`, "", mw.RoleDeptAdmin, &eng.ID)
+	c.SetParamNames("id", "faqId")
+	c.SetParamValues(p.ID, pager.ID)
+	c.Set(mw.CtxUserID, lead.ID)
+	if err := h.UpdateFAQ(c); err != nil {
+		t.Fatalf("UpdateFAQ: %v", err)
+	}
+
+	c, rec := testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleStaff, &eng.ID)
+	if err := h.FAQs(c); err != nil {
+		t.Fatalf("FAQs: %v", err)
+	}
+	var faqs []database.PolicyFAQ
+	json.Unmarshal(rec.Body.Bytes(), &faqs)
+	if len(faqs) != 2 || faqs[0].Question != "Do I need a pager?" || faqs[1].Question != "Who covers holidays?" {
+		t.Errorf("faqs = %+v; want the pager question first", faqs)
+	}
+
+	after, _ := db.GetPolicy(ctx, p.ID)
+	pending, _ := db.ListPendingPoliciesForUser(ctx, dev.ID, dev.Role, dev.DepartmentID)
+	if *after.CurrentVersionID != *p.CurrentVersionID || len(pending) != 0 {
+		t.Errorf("version %s -> %s, %d pending; want the acknowledgement to stand", *p.CurrentVersionID, *after.CurrentVersionID, len(pending))
+	}
+
+	c, _ = testutil.NewContext(e, http.MethodPost, `{"question":"Q?","answer":"A."}`, p.ID, mw.RoleDeptAdmin, &ops.ID)
+	var he *echo.HTTPError
+	if err := h.CreateFAQ(c); !errors.As(err, &he) || he.Code != http.StatusForbidden {
+		t.Errorf("another department's admin: %v; want 403", err)
+	}
+}
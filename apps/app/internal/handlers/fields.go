@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+)
+
+// Fields that may be named in ?fields=. List responses add the caller's
+// acknowledged flag and, for admins, coverage on top of the policy's own.
+var (
+	policyFields = jsonFields(database.Policy{}, database.AckCoverage{}, struct {
+		Acknowledged bool `json:"acknowledged"`
+	}{})
+	versionFields = jsonFields(database.PolicyVersion{})
+)
+
+// fieldSelection is a parsed ?fields= and ?include=. A nil field set means
+// every field.
+type fieldSelection struct {
+	policy  map[string]bool
+	version map[string]bool
+	include map[string]bool
+}
+
+// parseFieldSelection reads ?fields=title,status,current_version.version_string
+// and ?include=a,b, rejecting names the endpoint does not know. Policy IDs are
+// always returned.
+func parseFieldSelection(c echo.Context, includes ...string) (*fieldSelection, error) {
+	sel := &fieldSelection{include: map[string]bool{}}
+	for _, name := range splitList(c.QueryParam("fields")) {
+		set, field := &sel.policy, name
+		known := policyFields
+		if v, ok := strings.CutPrefix(name, "current_version."); ok {
+			set, field, known = &sel.version, v, versionFields
+		}
+		if !known[field] {
+			return nil, apierr.Invalid("unknown field: "+name, "fields")
+		}
+		if *set == nil {
+			*set = map[string]bool{"id": true}
+		}
+		(*set)[field] = true
+	}
+	for _, name := range splitList(c.QueryParam("include")) {
+		if !slices.Contains(includes, name) {
+			return nil, apierr.Invalid("include must be one of: "+strings.Join(includes, ", "), "include")
+		}
+		sel.include[name] = true
+	}
+	return sel, nil
+}
+
+// empty reports whether the request asked for neither fields nor includes,
+// so the endpoint's usual response applies.
+func (s *fieldSelection) empty() bool {
+	return s.policy == nil && s.version == nil && len(s.include) == 0
+}
+
+// trim returns v as a JSON object holding only the fields in keep, or all of
+// them when keep is nil.
+func trim(v any, keep map[string]bool) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	if keep != nil {
+		for k := range m {
+			if !keep[k] {
+				delete(m, k)
+			}
+		}
+	}
+	return m, nil
+}
+
+// jsonFields returns the JSON names of the fields of the given structs,
+// including promoted ones.
+func jsonFields(vs ...any) map[string]bool {
+	out := map[string]bool{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if f.Anonymous && name == "" {
+				ft := f.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				walk(ft)
+				continue
+			}
+			if name != "" && name != "-" {
+				out[name] = true
+			}
+		}
+	}
+	for _, v := range vs {
+		walk(reflect.TypeOf(v))
+	}
+	return out
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
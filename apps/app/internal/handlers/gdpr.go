@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+)
+
+// GDPRExport returns everything stored about a user as a JSON bundle, for
+// answering a data-subject access request.
+// GET /api/admin/users/:id/gdpr-export  (SuperAdmin only)
+func (h *User) GDPRExport(c echo.Context) error {
+	data, err := h.db.Reader().SubjectData(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+		}
+		return apierr.Database()
+	}
+	data["generated_at"] = time.Now().UTC().Format(time.RFC3339)
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="gdpr-export-`+c.Param("id")+`.json"`)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+)
+
+// readyTimeout bounds how long a readiness check waits for the database,
+// and readyLockWait how long it waits for another writer to let go.
+const (
+	readyTimeout  = 2 * time.Second
+	readyLockWait = 500 * time.Millisecond
+)
+
+// Health serves the orchestrator probes. They are registered outside /api,
+// so maintenance mode and REQUEST_TIMEOUT do not apply to them.
+type Health struct {
+	db *database.DB
+}
+
+func NewHealth(db *database.DB) *Health {
+	return &Health{db: db}
+}
+
+type probeStatus struct {
+	Status            string   `json:"status"` // "ok" or "unavailable"
+	Reason            string   `json:"reason,omitempty"`
+	PendingMigrations []string `json:"pending_migrations,omitempty"`
+}
+
+// Live reports that the process is up and serving. It never touches the
+// database, so a busy database does not get the process restarted.
+// GET /healthz  (public)
+func (h *Health) Live(c echo.Context) error {
+	return c.JSON(http.StatusOK, probeStatus{Status: "ok"})
+}
+
+// Ready reports whether the server should receive traffic: 503 while
+// migrations are pending (a separate migration job has not finished) or the
+// database write lock cannot be taken within half a second.
+// GET /readyz  (public)
+func (h *Health) Ready(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readyTimeout)
+	defer cancel()
+	pending, err := h.db.PendingMigrations(ctx)
+	if err != nil {
+		return c.JSON(http.StatusServiceUnavailable, probeStatus{Status: "unavailable", Reason: "database unavailable"})
+	}
+	if len(pending) > 0 {
+		return c.JSON(http.StatusServiceUnavailable, probeStatus{Status: "unavailable", Reason: "migrations pending", PendingMigrations: pending})
+	}
+	if err := h.db.CheckWritable(ctx, readyLockWait); err != nil {
+		return c.JSON(http.StatusServiceUnavailable, probeStatus{Status: "unavailable", Reason: "database locked"})
+	}
+	return c.JSON(http.StatusOK, probeStatus{Status: "ok"})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+)
+
+// TestReady_WaitsForMigrationsAndWriteLock verifies that the readiness
+// probe fails while migrations are pending or another process holds the
+// write lock, and that the liveness probe passes regardless.
+func TestReady_WaitsForMigrationsAndWriteLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "policyflow.db")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	db := database.New(conn)
+	if err := db.Init(ctx); err != nil {
+		t.Fatalf("db.Init: %v", err)
+	}
+
+	e := echo.New()
+	h := NewHealth(db)
+	probe := func(handler echo.HandlerFunc) (int, probeStatus) {
+		rec := httptest.NewRecorder()
+		if err := handler(e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)); err != nil {
+			t.Fatalf("probe: %v", err)
+		}
+		var body probeStatus
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	if code, body := probe(h.Ready); code != http.StatusServiceUnavailable || len(body.PendingMigrations) == 0 {
+		t.Errorf("before migrating: %d %+v; want 503 with pending migrations", code, body)
+	}
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("db.Migrate: %v", err)
+	}
+	if code, body := probe(h.Ready); code != http.StatusOK {
+		t.Errorf("after migrating: %d %+v; want 200", code, body)
+	}
+
+	// A second process, such as a migration job, holds the write lock.
+	other, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open second connection: %v", err)
+	}
+	defer other.Close()
+	lock, err := other.Conn(ctx)
+	if err != nil {
+		t.Fatalf("conn: %v", err)
+	}
+	defer lock.Close()
+	if _, err := lock.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		t.Fatalf("BEGIN IMMEDIATE: %v", err)
+	}
+	if code, body := probe(h.Ready); code != http.StatusServiceUnavailable || body.Reason != "database locked" {
+		t.Errorf("while locked: %d %+v; want 503 database locked", code, body)
+	}
+	if code, _ := probe(h.Live); code != http.StatusOK {
+		t.Errorf("liveness while locked: %d; want 200", code)
+	}
+	lock.ExecContext(ctx, `ROLLBACK`)
+	if code, body := probe(h.Ready); code != http.StatusOK {
+		t.Errorf("after unlock: %d %+v; want 200", code, body)
+	}
+}
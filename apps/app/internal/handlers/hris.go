@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/hris"
+	mw "policyflow/internal/middleware"
+)
+
+// HRIS exposes the HR system sync run log, a manual trigger, and the inbound
+// provisioning webhook.
+type HRIS struct {
+	db           *database.DB
+	syncer       *hris.Syncer // nil when no provider is configured
+	webhookToken string       // empty disables the webhook
+}
+
+func NewHRIS(db *database.DB, syncer *hris.Syncer) *HRIS {
+	return &HRIS{db: db, syncer: syncer, webhookToken: os.Getenv("HR_WEBHOOK_TOKEN")}
+}
+
+// Runs returns the most recent sync runs.
+// GET /api/admin/hris/runs  (SuperAdmin only)
+func (h *HRIS) Runs(c echo.Context) error {
+	ctx := c.Request().Context()
+	runs, err := h.db.ListHRISSyncRuns(ctx, 50)
+	if err != nil {
+		return apierr.Database()
+	}
+	if runs == nil {
+		runs = []*database.HRISSyncRun{}
+	}
+	provider := ""
+	if h.syncer != nil {
+		provider = h.syncer.ProviderName()
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"provider": provider,
+		"runs":     runs,
+	})
+}
+
+// Sync runs the HRIS sync immediately and returns the run record. With
+// ?dry_run=true it returns what the sync would do, changing nothing and
+// storing no run.
+// POST /api/admin/hris/sync?dry_run=  (SuperAdmin only)
+func (h *HRIS) Sync(c echo.Context) error {
+	if h.syncer == nil {
+		return apierr.New(http.StatusBadRequest, "HRIS_NOT_CONFIGURED", "HRIS sync is not configured")
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	if c.QueryParam("dry_run") == "true" {
+		run, err := h.syncer.Preview(c.Request().Context(), &userID)
+		if err != nil {
+			return apierr.New(http.StatusInternalServerError, "SYNC_FAILED", "sync error")
+		}
+		return c.JSON(http.StatusOK, run)
+	}
+	run, err := h.syncer.Run(c.Request().Context(), &userID)
+	if err != nil {
+		if errors.Is(err, hris.ErrSyncRunning) {
+			return apierr.New(http.StatusConflict, "SYNC_IN_PROGRESS", "a sync is already running")
+		}
+		return apierr.New(http.StatusInternalServerError, "SYNC_FAILED", "sync error")
+	}
+	return c.JSON(http.StatusOK, run)
+}
+
+// Webhook applies a single hire, transfer, or terminate event pushed by the HR
+// system, authenticated with the shared HR_WEBHOOK_TOKEN bearer token. Each
+// event is recorded in the sync run log with provider "webhook". With
+// ?dry_run=true the outcome is reported but nothing is changed or logged.
+// POST /api/integrations/users/webhook?dry_run=
+func (h *HRIS) Webhook(c echo.Context) error {
+	ctx := c.Request().Context()
+	if h.webhookToken == "" {
+		return apierr.New(http.StatusNotFound, "HRIS_NOT_CONFIGURED", "webhook not configured")
+	}
+	token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.webhookToken)) != 1 {
+		return apierr.New(http.StatusUnauthorized, "INVALID_TOKEN", "invalid token")
+	}
+
+	var body struct {
+		Event         string   `json:"event"` // hire | transfer | terminate
+		EmployeeID    string   `json:"employee_id"`
+		Email         string   `json:"email"`
+		Name          string   `json:"name"`
+		Department    string   `json:"department"`
+		OU            string   `json:"ou"`
+		JobTitle      string   `json:"job_title"`
+		Groups        []string `json:"groups"`
+		EffectiveDate string   `json:"effective_date"` // YYYY-MM-DD; terminate defaults to today
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if body.Email == "" && body.EmployeeID == "" {
+		return apierr.Invalid("email or employee_id is required", "email", "employee_id")
+	}
+
+	now := time.Now().UTC()
+	e := hris.Employee{ExternalID: body.EmployeeID, Email: body.Email, Name: body.Name, Department: body.Department,
+		OU: body.OU, JobTitle: body.JobTitle, Groups: body.Groups}
+	switch body.Event {
+	case "hire", "transfer":
+	case "terminate":
+		e.TerminationDate = &now
+		if body.EffectiveDate != "" {
+			t, err := time.Parse("2006-01-02", body.EffectiveDate)
+			if err != nil {
+				return apierr.Invalid("effective_date must be YYYY-MM-DD", "effective_date")
+			}
+			if t.After(now) {
+				return apierr.New(http.StatusUnprocessableEntity, "INVALID_EFFECTIVE_DATE", "send terminate events on or after the effective date")
+			}
+			e.TerminationDate = &t
+		}
+	default:
+		return apierr.Invalid("event must be hire, transfer, or terminate", "event")
+	}
+
+	// Events identified only by employee ID must match an existing account.
+	if e.Email == "" {
+		u, err := h.db.GetUserByExternalID(ctx, e.ExternalID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "unknown employee_id")
+			}
+			return apierr.Database()
+		}
+		e.Email = u.Email
+	}
+
+	rules, err := hris.LoadRules(ctx, h.db)
+	if err != nil {
+		return apierr.Database()
+	}
+	if c.QueryParam("dry_run") == "true" {
+		outcome, note, err := hris.PreviewEmployee(ctx, h.db, e, rules, now)
+		if err != nil {
+			return apierr.Database()
+		}
+		return c.JSON(http.StatusOK, map[string]any{
+			"outcome": outcome,
+			"note":    note,
+			"run_id":  nil,
+			"dry_run": true,
+		})
+	}
+	run, err := h.db.CreateHRISSyncRun(ctx, "webhook", nil)
+	if err != nil {
+		return apierr.Database()
+	}
+	outcome, note, err := hris.ApplyEmployee(ctx, h.db, e, rules, now)
+	run.Status = "success"
+	run.Log = fmt.Sprintf("%s %s %s (%s)", body.Event, outcome, e.Email, e.ExternalID)
+	if note != "" {
+		run.Log += ": " + note
+	}
+	if err != nil {
+		run.Status = "failed"
+		run.Log = fmt.Sprintf("%s error %s (%s): %v", body.Event, e.Email, e.ExternalID, err)
+	}
+	switch outcome {
+	case hris.OutcomeCreated:
+		run.Created = 1
+	case hris.OutcomeUpdated:
+		run.Updated = 1
+	case hris.OutcomeDeactivated:
+		run.Deactivated = 1
+	case hris.OutcomeSkipped:
+		run.Skipped = 1
+	}
+	if ferr := h.db.FinishHRISSyncRun(ctx, run); ferr != nil {
+		return apierr.Database()
+	}
+	if err != nil {
+		return apierr.New(http.StatusInternalServerError, "PROVISIONING_FAILED", "provisioning error")
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"outcome": outcome,
+		"note":    note,
+		"run_id":  run.ID,
+		"dry_run": false,
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/testutil"
+)
+
+// TestWebhook_HireThenTerminate verifies token checking and that webhook
+// events create and then deactivate an account.
+func TestWebhook_HireThenTerminate(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	h := &HRIS{db: db, webhookToken: "s3cret"}
+	e := echo.New()
+
+	post := func(token, body string) error {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		return h.Webhook(e.NewContext(req, httptest.NewRecorder()))
+	}
+
+	var he *echo.HTTPError
+	if err := post("wrong", `{"event":"hire","email":"x@example.com"}`); !errors.As(err, &he) || he.Code != http.StatusUnauthorized {
+		t.Fatalf("bad token error = %v; want 401", err)
+	}
+	if err := post("s3cret", `{"event":"hire","employee_id":"E1","email":"new@example.com","name":"New"}`); err != nil {
+		t.Fatalf("hire: %v", err)
+	}
+	if err := post("s3cret", `{"event":"terminate","employee_id":"E1"}`); err != nil {
+		t.Fatalf("terminate: %v", err)
+	}
+	u, err := db.GetUserByExternalID(ctx, "E1")
+	if err != nil || u.DeactivatedAt == nil {
+		t.Errorf("after terminate: user=%+v err=%v; want deactivated", u, err)
+	}
+}
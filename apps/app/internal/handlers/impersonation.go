@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// impersonationTTL bounds how long a SuperAdmin can act as another user.
+const impersonationTTL = 30 * time.Minute
+
+// Impersonate issues a short-lived, read-only session for another user so a
+// SuperAdmin can see exactly what they see. The token carries the admin's ID
+// in its "imp" claim, and every request made with it is audited.
+// POST /api/admin/impersonate/:id  (SuperAdmin only)
+func (h *Auth) Impersonate(c echo.Context) error {
+	ctx := c.Request().Context()
+	adminID := c.Get(mw.CtxUserID).(string)
+	if _, nested := c.Get(mw.CtxImpersonatorID).(string); nested {
+		return apierr.New(http.StatusForbidden, "ALREADY_IMPERSONATING", "already impersonating")
+	}
+	target, err := h.db.GetUserByID(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+		}
+		return apierr.Database()
+	}
+	switch {
+	case target.ID == adminID:
+		return apierr.New(http.StatusBadRequest, "SELF_IMPERSONATION", "cannot impersonate yourself")
+	case target.Role == mw.RoleSuperAdmin:
+		return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "cannot impersonate another super admin")
+	case target.DeactivatedAt != nil:
+		return apierr.New(http.StatusConflict, "ACCOUNT_DEACTIVATED", "account deactivated")
+	}
+
+	expiresAt := time.Now().Add(impersonationTTL)
+	token, err := h.buildImpersonationToken(target, adminID, expiresAt)
+	if err != nil {
+		return apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "session error")
+	}
+	mw.LogAudit(c, h.db, "impersonation.start", "user", target.ID, "as "+target.Email)
+	return c.JSON(http.StatusOK, map[string]any{
+		"token":      token,
+		"expires_at": expiresAt.UTC().Truncate(time.Second),
+		"user":       target,
+	})
+}
+
+func (h *Auth) buildImpersonationToken(user *database.User, adminID string, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":   user.ID,
+		"email": user.Email,
+		"role":  user.Role,
+		"type":  "session",
+		"imp":   adminID,
+		"exp":   expiresAt.Unix(),
+		"iat":   time.Now().Unix(),
+	}
+	return h.keys.Sign(claims)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestImpersonate_ReadOnlyAndAudited verifies an impersonation token acts as
+// the target user, refuses writes, and leaves an audit trail naming the
+// SuperAdmin.
+func TestImpersonate_ReadOnlyAndAudited(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	staff, _ := db.CreateUser(ctx, "sam@example.com", "Sam", mw.RoleStaff, nil, nil)
+
+	e := echo.New()
+	keys := testutil.NewKeyring(t, db)
+	h := NewAuth(db, email.New(), keys)
+	c, rec := testutil.NewContext(e, http.MethodPost, "", staff.ID, mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, admin.ID)
+	if err := h.Impersonate(c); err != nil {
+		t.Fatalf("Impersonate: %v", err)
+	}
+	var body struct{ Token string }
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	auth := mw.NewAuth(keys, db)
+	call := func(method string) (echo.Context, error) {
+		req := httptest.NewRequest(method, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+body.Token)
+		c := e.NewContext(req, httptest.NewRecorder())
+		return c, auth.Require(auth.Audit(func(echo.Context) error { return nil }))(c)
+	}
+
+	c, err := call(http.MethodGet)
+	if err != nil || c.Get(mw.CtxUserID) != staff.ID || c.Get(mw.CtxImpersonatorID) != admin.ID {
+		t.Fatalf("GET: err=%v user=%v impersonator=%v", err, c.Get(mw.CtxUserID), c.Get(mw.CtxImpersonatorID))
+	}
+	var he *echo.HTTPError
+	if _, err := call(http.MethodPost); !errors.As(err, &he) || he.Code != http.StatusForbidden {
+		t.Errorf("POST: got %v; want 403", err)
+	}
+
+	entries, _ := db.ListAuditLog(ctx, database.AuditFilter{ActorID: admin.ID})
+	actions := map[string]bool{}
+	for _, e := range entries {
+		actions[e.Action] = true
+	}
+	if !actions["impersonation.start"] || !actions["impersonation.write_blocked"] {
+		t.Errorf("audit actions = %v; want start and write_blocked", actions)
+	}
+}
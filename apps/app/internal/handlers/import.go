@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/docx"
+	mw "policyflow/internal/middleware"
+)
+
+// maxImportBytes caps uploaded documents for import.
+const maxImportBytes = 20 << 20
+
+// ImportDOCX converts an uploaded Word document to markdown and creates a
+// Draft policy with it as the first version. The title defaults to the
+// document's Title paragraph, then to the file name. With ?dry_run=true
+// the document is converted and checked but nothing is created: the
+// response shows the policy and version as they would be, and an upload_id
+// stays usable for the real import.
+// POST /api/policies/import/docx?dry_run= (multipart: file or upload_id,
+// title, department_id, visibility_type, version_string)
+func (h *Policy) ImportDOCX(c echo.Context) error {
+	ctx := c.Request().Context()
+	dryRun := c.QueryParam("dry_run") == "true"
+	f, err := h.incomingFile(c, maxImportBytes)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if !strings.EqualFold(filepath.Ext(f.Filename), ".docx") {
+		return apierr.New(http.StatusBadRequest, "UNSUPPORTED_FILE_TYPE", "only .docx files can be imported")
+	}
+	if err := h.scanUpload(c, database.UploadDOCXImport, nil, f.Filename, f); err != nil {
+		return err
+	}
+	doc, err := docx.Convert(f, f.Size)
+	if errors.Is(err, docx.ErrInvalid) {
+		return apierr.New(http.StatusBadRequest, "UNSUPPORTED_FILE_TYPE", "file is not a valid Word document")
+	}
+	if err != nil {
+		return apierr.New(http.StatusInternalServerError, "CONVERSION_FAILED", "conversion failed")
+	}
+	if strings.TrimSpace(doc.Markdown) == "" {
+		return apierr.New(http.StatusBadRequest, "DOCUMENT_EMPTY", "document has no text")
+	}
+
+	title := strings.TrimSpace(c.FormValue("title"))
+	if title == "" {
+		title = doc.Title
+	}
+	if title == "" {
+		title = strings.TrimSuffix(f.Filename, filepath.Ext(f.Filename))
+	}
+	versionString := c.FormValue("version_string")
+	if versionString == "" {
+		versionString = "v1.0.0"
+	}
+	visibility := c.FormValue("visibility_type")
+	if visibility == "" {
+		visibility = "organization"
+	}
+	if visibility != "organization" && visibility != "department" {
+		return apierr.Invalid("visibility_type must be organization or department", "visibility_type")
+	}
+	var deptID *string
+	if id := c.FormValue("department_id"); id != "" {
+		deptID = &id
+	}
+
+	// DeptAdmin can only create dept-scoped policies for their own department.
+	if c.Get(mw.CtxUserRole) == mw.RoleDeptAdmin {
+		deptID, _ = c.Get(mw.CtxDeptID).(*string)
+		if deptID == nil {
+			return apierr.New(http.StatusForbidden, "DEPARTMENT_REQUIRED", "department admin must belong to a department")
+		}
+		visibility = "department"
+	}
+
+	if deptID != nil {
+		if _, err := h.db.GetDepartment(ctx, *deptID); errors.Is(err, sql.ErrNoRows) {
+			return apierr.Invalid("unknown department", "department_id")
+		} else if err != nil {
+			return apierr.Database()
+		}
+	}
+
+	warnings := doc.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	if dryRun {
+		return c.JSON(http.StatusOK, map[string]any{
+			"policy": &database.Policy{Title: title, Status: "Draft", DepartmentID: deptID, VisibilityType: visibility,
+				CreatedBy: &userID, CustomFields: map[string]string{}},
+			"version":  &database.PolicyVersion{Content: doc.Markdown, VersionString: versionString, Changelog: "Imported from " + f.Filename},
+			"warnings": warnings,
+			"dry_run":  true,
+		})
+	}
+	policy, err := h.db.CreatePolicy(ctx, title, "", deptID, visibility, &userID)
+	if err != nil {
+		return apierr.Database()
+	}
+	version, err := h.db.CreatePolicyVersion(ctx, policy.ID, doc.Markdown, versionString, "Imported from "+f.Filename, &userID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if err := h.db.SetPolicyCurrentVersion(ctx, policy.ID, version.ID); err != nil {
+		return apierr.Database()
+	}
+	policy.CurrentVersionID = &version.ID
+	mw.LogAudit(c, h.db, database.ActivityPolicyCreated, "policy", policy.ID, policy.Title)
+	f.Done()
+
+	return c.JSON(http.StatusCreated, map[string]any{
+		"policy":   policy,
+		"version":  version,
+		"warnings": warnings,
+		"dry_run":  false,
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/tokens"
+)
+
+// Keys manages the JWT signing keys.
+type Keys struct {
+	keys   *tokens.Keyring
+	window time.Duration
+}
+
+func NewKeys(keys *tokens.Keyring, window time.Duration) *Keys {
+	return &Keys{keys: keys, window: window}
+}
+
+// List returns the accepted signing keys (IDs and expiry only).
+// GET /api/admin/jwt/keys  (SuperAdmin only)
+func (h *Keys) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.keys.Keys())
+}
+
+// Rotate makes a freshly generated key current. Sessions signed by the old
+// key keep working until the rotation window ends.
+// POST /api/admin/jwt/rotate  (SuperAdmin only)
+func (h *Keys) Rotate(c echo.Context) error {
+	k, err := h.keys.Rotate(c.Request().Context(), h.window)
+	if errors.Is(err, tokens.ErrPrivateKeysConfigured) {
+		return apierr.New(http.StatusConflict, "SET_BY_ENVIRONMENT", "signing keys are set by JWT_PRIVATE_KEY_FILE; rotate them there")
+	}
+	if err != nil {
+		log.Printf("jwt rotate: %v", err)
+		return apierr.New(http.StatusInternalServerError, "ROTATION_FAILED", "rotation failed")
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"kid":                  k.KID,
+		"previous_valid_until": time.Now().Add(h.window).UTC().Truncate(time.Second),
+	})
+}
+
+// JWKS publishes the public signing keys so other services can verify
+// PolicyFlow tokens. It is empty unless JWT_PRIVATE_KEY_FILE is set.
+// GET /.well-known/jwks.json  (public)
+func (h *Keys) JWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, map[string]any{"keys": h.keys.JWKS()})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestConsolidateLegacy maps exact matches, creates missing departments once,
+// and leaves unconfirmed fuzzy matches for review.
+func TestConsolidateLegacy(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	hr, _ := db.CreateDepartment(ctx, "Human Resources", "")
+	db.CreateDepartment(ctx, "Finance", "")
+	conduct, _ := db.CreatePolicy(ctx, "Conduct", "human resources dept.", nil, "organization", nil)
+	expenses, _ := db.CreatePolicy(ctx, "Expenses", "Finanse", nil, "organization", nil)
+	contracts, _ := db.CreatePolicy(ctx, "Contracts", "Legal", nil, "organization", nil)
+	privacy, _ := db.CreatePolicy(ctx, "Privacy", "legal ", nil, "organization", nil)
+
+	e := echo.New()
+	h := NewDepartments(db)
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	if err := h.LegacyDepartments(c); err != nil {
+		t.Fatalf("LegacyDepartments: %v", err)
+	}
+	var report []database.LegacyDepartment
+	json.Unmarshal(rec.Body.Bytes(), &report)
+	matches := map[string]string{}
+	for _, l := range report {
+		matches[l.Value] = l.Match
+	}
+	if matches["human resources dept."] != database.LegacyMatchExact || matches["Finanse"] != database.LegacyMatchFuzzy || matches["Legal"] != database.LegacyMatchNone {
+		t.Fatalf("report matches = %v", matches)
+	}
+
+	c, rec = testutil.NewContext(e, http.MethodPost, `{}`, "", mw.RoleSuperAdmin, nil)
+	if err := h.ConsolidateLegacy(c); err != nil {
+		t.Fatalf("ConsolidateLegacy: %v", err)
+	}
+	got := func(id string) *database.Policy {
+		p, err := db.GetPolicy(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	if p := got(conduct.ID); p.DepartmentID == nil || *p.DepartmentID != hr.ID || p.Department != "" {
+		t.Errorf("Conduct department = %v %q; want HR, legacy text cleared", p.DepartmentID, p.Department)
+	}
+	if p := got(expenses.ID); p.DepartmentID != nil || p.Department != "Finanse" {
+		t.Errorf("fuzzy match applied without confirmation: %v", p.DepartmentID)
+	}
+	legal, err := db.GetDepartmentByName(ctx, "Legal")
+	if err != nil {
+		t.Fatalf("Legal not created: %v", err)
+	}
+	for _, id := range []string{contracts.ID, privacy.ID} {
+		if p := got(id); p.DepartmentID == nil || *p.DepartmentID != legal.ID {
+			t.Errorf("%s department = %v; want the one created Legal department", p.Title, p.DepartmentID)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// editLockTTL is how long an edit lock lasts without a heartbeat. Editors
+// renew it about every 30 seconds.
+const editLockTTL = 2 * time.Minute
+
+// Lock takes the soft edit lock on a policy's draft, or renews it when the
+// caller already holds it. While another user holds it the request fails
+// with 409 and the current holder.
+// PUT /api/policies/:id/lock
+func (h *Policy) Lock(c echo.Context) error {
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	lock, err := h.db.AcquirePolicyLock(c.Request().Context(), policy.ID, userID, time.Now(), editLockTTL)
+	if err != nil {
+		return apierr.Database()
+	}
+	if lock.UserID != userID {
+		return apierr.Respond(c, apierr.With(apierr.New(http.StatusConflict, "POLICY_LOCKED", lock.UserName+" is editing this draft"), "lock", lock))
+	}
+	return c.JSON(http.StatusOK, lock)
+}
+
+// Unlock releases the caller's edit lock. SuperAdmin can release anyone's.
+// DELETE /api/policies/:id/lock
+func (h *Policy) Unlock(c echo.Context) error {
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	holder := c.Get(mw.CtxUserID).(string)
+	if c.Get(mw.CtxUserRole) == mw.RoleSuperAdmin {
+		holder = ""
+	}
+	if err := h.db.ReleasePolicyLock(c.Request().Context(), policy.ID, holder); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// lockedByOther returns the active edit lock on policyID when someone other
+// than the caller holds it.
+func (h *Policy) lockedByOther(c echo.Context, policyID string) (*database.PolicyLock, error) {
+	lock, err := h.db.GetPolicyLock(c.Request().Context(), policyID, time.Now())
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	if lock == nil || lock.UserID == c.Get(mw.CtxUserID).(string) {
+		return nil, nil
+	}
+	return lock, nil
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestEditLock_SecondEditorBlocked verifies that a second DeptAdmin cannot
+// take the lock or save a version while the first holds it, and can once it
+// is released.
+func TestEditLock_SecondEditorBlocked(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	dept, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ann, _ := db.CreateUser(ctx, "ann@example.com", "Ann", mw.RoleDeptAdmin, nil, testutil.Ptr(dept.ID))
+	ben, _ := db.CreateUser(ctx, "ben@example.com", "Ben", mw.RoleDeptAdmin, nil, testutil.Ptr(dept.ID))
+	p, _ := db.CreatePolicy(ctx, "On-call", "", testutil.Ptr(dept.ID), "department", nil)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	as := func(u *database.User, method, body string) (echo.Context, func() int) {
+		c, rec := testutil.NewContext(e, method, body, p.ID, u.Role, u.DepartmentID)
+		c.Set(mw.CtxUserID, u.ID)
+		return c, func() int { return rec.Code }
+	}
+
+	c, _ := as(ann, http.MethodPut, "")
+	if err := h.Lock(c); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	c, code := as(ben, http.MethodPut, "")
+	if err := h.Lock(c); err != nil || code() != http.StatusConflict {
+		t.Errorf("second Lock: err=%v code=%d; want 409", err, code())
+	}
+	var he *echo.HTTPError
+	version := `{"content":"# Draft","version_string":"v1"}`
+	c, _ = as(ben, http.MethodPost, version)
+	if err := h.CreateVersion(c); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Errorf("CreateVersion while locked: got %v; want 409", err)
+	}
+
+	c, _ = as(ann, http.MethodDelete, "")
+	if err := h.Unlock(c); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	c, _ = as(ben, http.MethodPost, version)
+	if err := h.CreateVersion(c); err != nil {
+		t.Errorf("CreateVersion after unlock: %v", err)
+	}
+}
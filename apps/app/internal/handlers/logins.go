@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+const loginHistoryLimit = 50
+
+// MyLogins returns the current user's recent sign-ins and magic-link requests.
+// GET /api/me/logins
+func (h *Auth) MyLogins(c echo.Context) error {
+	return h.logins(c, c.Get(mw.CtxUserID).(string))
+}
+
+// UserLogins returns a user's recent sign-ins and magic-link requests.
+// GET /api/admin/users/:id/logins  (SuperAdmin only)
+func (h *Auth) UserLogins(c echo.Context) error {
+	if _, err := h.db.GetUserByID(c.Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+		}
+		return apierr.Database()
+	}
+	return h.logins(c, c.Param("id"))
+}
+
+func (h *Auth) logins(c echo.Context, userID string) error {
+	events, err := h.db.ListLoginEvents(c.Request().Context(), userID, loginHistoryLimit)
+	if err != nil {
+		return apierr.Database()
+	}
+	if events == nil {
+		events = []*database.LoginEvent{}
+	}
+	return c.JSON(http.StatusOK, events)
+}
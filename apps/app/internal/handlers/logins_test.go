@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+	"policyflow/internal/tokens"
+)
+
+// TestLogins_RecordedWithDeviceDetection verifies magic-link logins are
+// recorded with their user agent and that only an unseen browser counts as
+// a new device.
+func TestLogins_RecordedWithDeviceDetection(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	u, _ := db.CreateUser(ctx, "lin@example.com", "Lin", mw.RoleStaff, nil, nil)
+
+	e := echo.New()
+	h := NewAuth(db, email.New(), testutil.NewKeyring(t, db))
+	token, _ := h.buildMagicToken(u.Email, tokens.DefaultLifetimes)
+	req := httptest.NewRequest(http.MethodGet, "/?token="+token, nil)
+	req.Header.Set("User-Agent", "Firefox/130")
+	if err := h.MagicLogin(e.NewContext(req, httptest.NewRecorder())); err != nil {
+		t.Fatalf("MagicLogin: %v", err)
+	}
+
+	if isNew, _ := db.RecordLoginEvent(ctx, u.ID, database.LoginEventLogin, "", "Firefox/130"); isNew {
+		t.Error("same browser reported as a new device")
+	}
+	if isNew, _ := db.RecordLoginEvent(ctx, u.ID, database.LoginEventLogin, "", "Safari/18"); !isNew {
+		t.Error("unseen browser not reported as a new device")
+	}
+
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleStaff, nil)
+	c.Set(mw.CtxUserID, u.ID)
+	if err := h.MyLogins(c); err != nil {
+		t.Fatalf("MyLogins: %v", err)
+	}
+	var events []database.LoginEvent
+	json.Unmarshal(rec.Body.Bytes(), &events)
+	if len(events) != 3 || events[2].UserAgent != "Firefox/130" {
+		t.Errorf("events = %+v; want 3, oldest from Firefox", events)
+	}
+}
+
+// TestLoginCode_SingleUseWithAttemptLimit verifies an emailed code signs the
+// user in once, and that repeated wrong guesses void it.
+func TestLoginCode_SingleUseWithAttemptLimit(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	u, _ := db.CreateUser(ctx, "kim@example.com", "Kim", mw.RoleStaff, nil, nil)
+	e := echo.New()
+	h := NewAuth(db, email.New(), testutil.NewKeyring(t, db))
+	verify := func(code string) (string, error) {
+		c, rec := testutil.NewContext(e, http.MethodPost, `{"email":"kim@example.com","code":"`+code+`"}`, "", "", nil)
+		err := h.VerifyLoginCode(c)
+		var out map[string]string
+		json.Unmarshal(rec.Body.Bytes(), &out)
+		return out["token"], err
+	}
+
+	c, _ := testutil.NewContext(e, http.MethodPost, `{"email":"kim@example.com"}`, "", "", nil)
+	if err := h.RequestLoginCode(c); err != nil {
+		t.Fatalf("RequestLoginCode: %v", err)
+	}
+
+	db.SetLoginCode(ctx, u.ID, "123456", time.Now().Add(time.Minute))
+	if _, err := verify("654321"); err == nil {
+		t.Fatal("wrong code accepted")
+	}
+	if token, err := verify("123 456"); err != nil || token == "" {
+		t.Fatalf("right code: token %q, err %v", token, err)
+	}
+	if _, err := verify("123456"); err == nil {
+		t.Error("code accepted twice")
+	}
+
+	db.SetLoginCode(ctx, u.ID, "111111", time.Now().Add(time.Minute))
+	for range database.MaxLoginCodeAttempts {
+		verify("000000")
+	}
+	if _, err := verify("111111"); err == nil {
+		t.Error("code still accepted after too many wrong guesses")
+	}
+}
+
+// TestLoginCode_LimitsSurviveReissue verifies that requesting new codes
+// neither resets the count of wrong guesses nor sends codes without limit.
+func TestLoginCode_LimitsSurviveReissue(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	u, _ := db.CreateUser(ctx, "ida@example.com", "Ida", mw.RoleStaff, nil, nil)
+	e := echo.New()
+	h := NewAuth(db, email.New(), testutil.NewKeyring(t, db))
+
+	for range loginCodeSendLimit + 2 {
+		c, _ := testutil.NewContext(e, http.MethodPost, `{"email":"ida@example.com"}`, "", "", nil)
+		if err := h.RequestLoginCode(c); err != nil {
+			t.Fatalf("RequestLoginCode: %v", err)
+		}
+	}
+	if n, _ := db.CountLoginEventsSince(ctx, u.ID, database.LoginEventCodeIssued, time.Now().Add(-time.Minute)); n != loginCodeSendLimit {
+		t.Errorf("codes issued = %d; want %d", n, loginCodeSendLimit)
+	}
+
+	verify := func(code string) error {
+		c, _ := testutil.NewContext(e, http.MethodPost, `{"email":"ida@example.com","code":"`+code+`"}`, "", "", nil)
+		return h.VerifyLoginCode(c)
+	}
+	for i := range loginCodeFailLimit {
+		if i%2 == 0 {
+			db.SetLoginCode(ctx, u.ID, "222222", time.Now().Add(time.Minute))
+		}
+		verify("000000")
+	}
+	db.SetLoginCode(ctx, u.ID, "222222", time.Now().Add(time.Minute))
+	var he *echo.HTTPError
+	if err := verify("222222"); !errors.As(err, &he) || he.Code != http.StatusTooManyRequests {
+		t.Errorf("right code after %d wrong ones: err = %v; want 429", loginCodeFailLimit, err)
+	}
+}
+
+// TestMagicLogin_Redirect verifies a sign-in lands on the requested page
+// only when it is an allowlisted path on this site.
+func TestMagicLogin_Redirect(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	u, _ := db.CreateUser(ctx, "ray@example.com", "Ray", mw.RoleStaff, nil, nil)
+	e := echo.New()
+	h := NewAuth(db, email.New(), testutil.NewKeyring(t, db))
+
+	for redirect, want := range map[string]string{
+		"/policies?id=abc":         "&redirect=%2Fpolicies%3Fid%3Dabc",
+		"/admin":                   "&redirect=%2Fadmin",
+		"/policies/../admin?tab=1": "&redirect=%2Fadmin%3Ftab%3D1",
+		"https://evil.example/x":   "",
+		"//evil.example/policies":  "",
+		"/\\evil.example/policies": "",
+		"/settings/../../etc":      "",
+		"/policies-extra?id=abc":   "",
+		"javascript:alert(1)":      "",
+	} {
+		token, _ := h.buildMagicToken(u.Email, tokens.DefaultLifetimes)
+		req := httptest.NewRequest(http.MethodGet, "/?token="+token+"&redirect="+url.QueryEscape(redirect), nil)
+		rec := httptest.NewRecorder()
+		if err := h.MagicLogin(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("%s: %v", redirect, err)
+		}
+		_, got, _ := strings.Cut(rec.Header().Get("Location"), "&")
+		if got != strings.TrimPrefix(want, "&") {
+			t.Errorf("redirect %q: Location ends %q; want %q", redirect, got, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// Maintenance exposes and toggles read-only maintenance mode.
+type Maintenance struct {
+	db *database.DB
+	m  *mw.Maintenance
+}
+
+func NewMaintenance(db *database.DB, m *mw.Maintenance) *Maintenance {
+	return &Maintenance{db: db, m: m}
+}
+
+type maintenanceStatus struct {
+	Maintenance bool   `json:"maintenance"`
+	Message     string `json:"message"`
+	Forced      bool   `json:"forced"`
+}
+
+// Status tells the frontend whether to show the maintenance banner.
+// GET /api/status  (public)
+func (h *Maintenance) Status(c echo.Context) error {
+	enabled, msg, err := h.m.Status(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, maintenanceStatus{Maintenance: enabled, Message: msg, Forced: h.m.Forced()})
+}
+
+// Set turns maintenance mode on or off, optionally with a banner message.
+// PUT /api/admin/maintenance  (SuperAdmin only)
+func (h *Maintenance) Set(c echo.Context) error {
+	ctx := c.Request().Context()
+	var body struct {
+		Enabled bool   `json:"enabled"`
+		Message string `json:"message"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if !body.Enabled && h.m.Forced() {
+		return apierr.New(http.StatusConflict, "SET_BY_ENVIRONMENT", "maintenance mode is set by MAINTENANCE_MODE")
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	if err := h.db.SetSetting(ctx, database.SettingMaintenanceMode, strconv.FormatBool(body.Enabled), &userID); err != nil {
+		return apierr.Database()
+	}
+	if err := h.db.SetSetting(ctx, database.SettingMaintenanceMessage, strings.TrimSpace(body.Message), &userID); err != nil {
+		return apierr.Database()
+	}
+	return h.Status(c)
+}
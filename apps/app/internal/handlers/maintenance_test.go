@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestMaintenance_BlocksWrites verifies that maintenance mode refuses writes
+// with 503 while reads, POSTs that only read, and the toggle itself keep
+// working, and that a mode
+// forced by the environment cannot be switched off through the API.
+func TestMaintenance_BlocksWrites(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	e := echo.New()
+	m := mw.NewMaintenance(db, false)
+	h := NewMaintenance(db, m)
+
+	c, _ := testutil.NewContext(e, http.MethodPut, `{"enabled":true,"message":"Backup in progress"}`, "", mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, admin.ID)
+	if err := h.Set(c); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	guarded := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		if err := m.Guard(func(c echo.Context) error { return c.NoContent(http.StatusOK) })(c); err != nil {
+			t.Fatalf("Guard: %v", err)
+		}
+		return rec.Code
+	}
+	if code := guarded(http.MethodPost, "/api/policies"); code != http.StatusServiceUnavailable {
+		t.Errorf("POST during maintenance = %d; want 503", code)
+	}
+	if code := guarded(http.MethodGet, "/api/policies"); code != http.StatusOK {
+		t.Errorf("GET during maintenance = %d; want 200", code)
+	}
+	for _, path := range []string{"/api/policies/batch", "/api/admin/reports"} {
+		if code := guarded(http.MethodPost, path); code != http.StatusOK {
+			t.Errorf("read-only POST %s during maintenance = %d; want 200", path, code)
+		}
+	}
+	if code := guarded(http.MethodPut, "/api/admin/maintenance"); code != http.StatusOK {
+		t.Errorf("toggle during maintenance = %d; want 200", code)
+	}
+
+	forced := NewMaintenance(db, mw.NewMaintenance(db, true))
+	c, _ = testutil.NewContext(e, http.MethodPut, `{"enabled":false}`, "", mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, admin.ID)
+	var he *echo.HTTPError
+	if err := forced.Set(c); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Errorf("disable forced mode: err = %v; want 409", err)
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// pendingPolicy is a required policy awaiting the user's acknowledgement.
+type pendingPolicy struct {
+	*database.Policy
+	Overdue      bool       `json:"overdue"`
+	DaysOverdue  int        `json:"days_overdue"`
+	SnoozedUntil *time.Time `json:"snoozed_until"` // reminders paused until then; nil = not snoozed
+}
+
+// Pending returns published policies whose current version the current user
+// still has to acknowledge, with deadline and overdue information.
+// GET /api/me/pending
+func (h *Policy) Pending(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get(mw.CtxUserID).(string)
+	role := c.Get(mw.CtxUserRole).(string)
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+
+	policies, err := h.db.ListPendingPoliciesForUser(ctx, userID, role, deptID)
+	if err != nil {
+		return apierr.Database()
+	}
+
+	now := time.Now().UTC()
+	snoozed, err := h.db.ReminderSnoozes(ctx, userID, now)
+	if err != nil {
+		return apierr.Database()
+	}
+	result := make([]pendingPolicy, len(policies))
+	for i, p := range policies {
+		result[i] = newPendingPolicy(p, now)
+		if until, ok := snoozed[*p.CurrentVersionID]; ok {
+			result[i].SnoozedUntil = &until
+		}
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// maxSnoozeDays caps how long one snooze lasts; it can be renewed.
+const maxSnoozeDays = 30
+
+// Snooze pauses deadline reminders about the policy's current version for
+// {"days": n} (1-30). The policy stays pending and its deadline unchanged.
+// POST /api/policies/:id/snooze
+func (h *Policy) Snooze(c echo.Context) error {
+	var body struct {
+		Days int `json:"days"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if body.Days < 1 || body.Days > maxSnoozeDays {
+		return apierr.Invalid(fmt.Sprintf("days must be between 1 and %d", maxSnoozeDays), "days")
+	}
+	versionID, err := h.pendingVersion(c)
+	if err != nil {
+		return err
+	}
+	until := time.Now().UTC().AddDate(0, 0, body.Days).Truncate(time.Second)
+	if err := h.db.SnoozeReminders(c.Request().Context(), c.Get(mw.CtxUserID).(string), versionID, until); err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, map[string]time.Time{"snoozed_until": until})
+}
+
+// Unsnooze resumes deadline reminders about the policy.
+// DELETE /api/policies/:id/snooze
+func (h *Policy) Unsnooze(c echo.Context) error {
+	versionID, err := h.pendingVersion(c)
+	if err != nil {
+		return err
+	}
+	if err := h.db.UnsnoozeReminders(c.Request().Context(), c.Get(mw.CtxUserID).(string), versionID); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// pendingVersion returns the current version of the :id policy, which the
+// caller must be able to see and not yet have acknowledged.
+func (h *Policy) pendingVersion(c echo.Context) (string, error) {
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return "", err
+	}
+	if policy.Status != "Published" || policy.CurrentVersionID == nil {
+		return "", apierr.New(http.StatusBadRequest, "POLICY_NOT_PUBLISHED", "policy is not published")
+	}
+	acked, err := h.db.HasAcknowledged(c.Request().Context(), c.Get(mw.CtxUserID).(string), *policy.CurrentVersionID)
+	if err != nil {
+		return "", apierr.Database()
+	}
+	if acked {
+		return "", apierr.New(http.StatusConflict, "ALREADY_ACKNOWLEDGED", "already acknowledged")
+	}
+	return *policy.CurrentVersionID, nil
+}
+
+func newPendingPolicy(p *database.Policy, now time.Time) pendingPolicy {
+	pp := pendingPolicy{Policy: p}
+	if p.AckDeadline != nil && now.After(*p.AckDeadline) {
+		pp.Overdue = true
+		pp.DaysOverdue = int(now.Sub(*p.AckDeadline) / (24 * time.Hour))
+	}
+	return pp
+}
+
+// parseDeadline accepts a calendar date (end of that day, UTC) or an RFC3339
+// timestamp. An empty string yields nil.
+func parseDeadline(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		t = t.Add(24*time.Hour - time.Second)
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+	return &t, nil
+}
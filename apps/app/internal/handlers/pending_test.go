@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/reminders"
+	"policyflow/internal/testutil"
+)
+
+// TestSnooze_PausesRemindersAndShowsInPending verifies that a snoozed policy
+// stays pending with its snooze end, that the reminder job skips it, and
+// that unsnoozing brings the reminders back.
+func TestSnooze_PausesRemindersAndShowsInPending(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	sam, _ := db.CreateUser(ctx, "sam@example.com", "Sam", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Travel", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	due := time.Now().Add(24 * time.Hour)
+	db.SetPolicyAckDeadline(ctx, p.ID, &due)
+	p, _ = db.GetPolicy(ctx, p.ID)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	call := func(method, body string, handler func(echo.Context) error) error {
+		c, _ := testutil.NewContext(e, method, body, p.ID, mw.RoleStaff, nil)
+		c.Set(mw.CtxUserID, sam.ID)
+		return handler(c)
+	}
+	var he *echo.HTTPError
+	if err := call(http.MethodPost, `{"days":31}`, h.Snooze); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("31 days: err = %v; want 400", err)
+	}
+	if err := call(http.MethodPost, `{"days":3}`, h.Snooze); err != nil {
+		t.Fatalf("Snooze: %v", err)
+	}
+
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleStaff, nil)
+	c.Set(mw.CtxUserID, sam.ID)
+	if err := h.Pending(c); err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	var pending []struct {
+		ID           string     `json:"id"`
+		SnoozedUntil *time.Time `json:"snoozed_until"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &pending)
+	if len(pending) != 1 || pending[0].SnoozedUntil == nil || pending[0].SnoozedUntil.Before(time.Now().Add(71*time.Hour)) {
+		t.Fatalf("pending = %+v; want Travel snoozed for 3 days", pending)
+	}
+
+	runner := reminders.NewRunner(db, email.New(), "http://localhost", 72*time.Hour)
+	reminded := func() bool {
+		t.Helper()
+		if err := runner.Run(ctx); err != nil {
+			t.Fatalf("reminders: %v", err)
+		}
+		sent, _ := db.ReminderSentSince(ctx, sam.ID, *p.CurrentVersionID, time.Now().Add(-time.Minute))
+		return sent
+	}
+	if reminded() {
+		t.Error("snoozed policy was reminded")
+	}
+	if err := call(http.MethodDelete, "", h.Unsnooze); err != nil {
+		t.Fatalf("Unsnooze: %v", err)
+	}
+	if !reminded() {
+		t.Error("unsnoozed policy was not reminded")
+	}
+
+	db.CreateAcknowledgement(ctx, sam.ID, *p.CurrentVersionID)
+	if err := call(http.MethodPost, `{"days":3}`, h.Snooze); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Errorf("snooze after acknowledging: err = %v; want 409", err)
+	}
+}
+
+// TestPending_ListsUnacknowledgedWithOverdue verifies that only published
+// policies the user has not acknowledged are pending, and that a passed
+// deadline is reported as overdue in whole days.
+func TestPending_ListsUnacknowledgedWithOverdue(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	kai, _ := db.CreateUser(ctx, "kai@example.com", "Kai", mw.RoleStaff, nil, nil)
+	late, _ := db.CreatePolicy(ctx, "Expenses", "", nil, "organization", nil)
+	open, _ := db.CreatePolicy(ctx, "Security", "", nil, "organization", nil)
+	done, _ := db.CreatePolicy(ctx, "Conduct", "", nil, "organization", nil)
+	db.CreatePolicy(ctx, "Draft", "", nil, "organization", nil)
+	for _, p := range []*database.Policy{late, open, done} {
+		testutil.Publish(t, db, p)
+	}
+	passed := time.Now().Add(-50 * time.Hour)
+	db.SetPolicyAckDeadline(ctx, late.ID, &passed)
+	done, _ = db.GetPolicy(ctx, done.ID)
+	db.CreateAcknowledgement(ctx, kai.ID, *done.CurrentVersionID)
+
+	c, rec := testutil.NewContext(echo.New(), http.MethodGet, "", "", mw.RoleStaff, nil)
+	c.Set(mw.CtxUserID, kai.ID)
+	if err := NewPolicy(db).Pending(c); err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	var pending []struct {
+		Title       string `json:"title"`
+		Overdue     bool   `json:"overdue"`
+		DaysOverdue int    `json:"days_overdue"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &pending)
+	got := map[string]int{}
+	for _, p := range pending {
+		if p.Overdue {
+			got[p.Title] = p.DaysOverdue
+		} else {
+			got[p.Title] = -1
+		}
+	}
+	if len(got) != 2 || got["Expenses"] != 2 || got["Security"] != -1 {
+		t.Errorf("pending = %+v; want Expenses 2 days overdue and Security not overdue", pending)
+	}
+}
+
+func TestParseDeadline(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string // RFC3339, "" for nil
+		bad  bool
+	}{
+		{"", "", false},
+		{"2026-03-31", "2026-03-31T23:59:59Z", false},
+		{"2026-03-31T09:00:00+02:00", "2026-03-31T07:00:00Z", false},
+		{"31/03/2026", "", true},
+		{"2026-02-30", "", true},
+	}
+	for _, tc := range cases {
+		got, err := parseDeadline(tc.in)
+		if (err != nil) != tc.bad {
+			t.Errorf("parseDeadline(%q) err = %v; want error %v", tc.in, err, tc.bad)
+			continue
+		}
+		if tc.want == "" {
+			if got != nil {
+				t.Errorf("parseDeadline(%q) = %v; want nil", tc.in, got)
+			}
+			continue
+		}
+		if got == nil || got.Format(time.RFC3339) != tc.want {
+			t.Errorf("parseDeadline(%q) = %v; want %s", tc.in, got, tc.want)
+		}
+	}
+}
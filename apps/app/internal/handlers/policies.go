@@ -1,104 +1,405 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log"
+	"maps"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"policyflow/internal/apierr"
+	"policyflow/internal/changelog"
 	"policyflow/internal/database"
+	"policyflow/internal/events"
 	mw "policyflow/internal/middleware"
+	"policyflow/internal/scan"
+	"policyflow/internal/storage"
 )
 
 // Policy handles policy management and acknowledgement endpoints.
 type Policy struct {
 	db *database.DB
+
+	// Reading requirements enforced before an acknowledgement is accepted.
+	minReadTime   time.Duration
+	requireScroll bool
+
+	events    *events.Hub   // nil = no real-time events
+	store     storage.Store // originals of uploaded versions; nil = uploads disabled
+	scanner   scan.Scanner  // virus scanner for uploads; nil = not scanned
+	uploads   *Uploads      // chunked uploads; nil = files must come with the request
+	receipts  *Receipts     // acknowledgement receipt emails; nil = none
+	maxUpload int64         // largest attachment or PDF version accepted
 }
 
 func NewPolicy(db *database.DB) *Policy {
-	return &Policy{db: db}
+	h := &Policy{db: db, maxUpload: DefaultMaxUploadBytes}
+	if v := os.Getenv("MAX_UPLOAD_SIZE"); v != "" {
+		if n, err := mw.ParseSize(v); err == nil {
+			h.maxUpload = n
+		}
+	}
+	if v := os.Getenv("ACK_MIN_READ_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			h.minReadTime = time.Duration(n) * time.Second
+		}
+	}
+	h.requireScroll = os.Getenv("ACK_REQUIRE_SCROLL") == "true"
+	return h
+}
+
+// MaxUploadBytes is the largest attachment or PDF version accepted, from
+// MAX_UPLOAD_SIZE.
+func (h *Policy) MaxUploadBytes() int64 {
+	return h.maxUpload
+}
+
+// SetEvents makes the handler publish real-time events to hub.
+func (h *Policy) SetEvents(hub *events.Hub) {
+	h.events = hub
+}
+
+// SetStore sets the blob store that holds uploaded version originals.
+func (h *Policy) SetStore(store storage.Store) {
+	h.store = store
 }
 
 // List returns policies visible to the current user based on role and department.
-// GET /api/policies
+// ?ids=a,b,c fetches just those policies (up to 100), skipping any the
+// caller cannot see.
+// ?custom.<key>=value filters on a custom field; date fields also take
+// from..to, with either end optional.
+// ?as_role=&as_department= (SuperAdmin only) lists what a user with that
+// role and department would see instead, ignoring user assignments.
+// GET /api/policies?acknowledged=true|false|overdue&ids=&custom.<key>=&as_role=&as_department=
 func (h *Policy) List(c echo.Context) error {
+	ids, err := queryIDs(c, maxQueryIDs)
+	if err != nil {
+		return err
+	}
+	return h.list(c, ids)
+}
+
+// Batch is List with the IDs in the body, for lists too long for a URL.
+// POST /api/policies/batch  {"ids": [...]}
+func (h *Policy) Batch(c echo.Context) error {
+	ids, err := bodyIDs(c)
+	if err != nil {
+		return err
+	}
+	return h.list(c, ids)
+}
+
+func (h *Policy) list(c echo.Context, ids []string) error {
+	ctx := c.Request().Context()
+	userID := c.Get(mw.CtxUserID).(string)
 	role := c.Get(mw.CtxUserRole).(string)
 	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+	if asRole, asDept, ok, err := h.viewAs(c); err != nil {
+		return err
+	} else if ok {
+		if ids != nil {
+			return apierr.Invalid("ids cannot be combined with as_role", "ids")
+		}
+		userID, role, deptID = "", asRole, asDept
+	}
 
-	policies, err := h.db.ListPoliciesForUser(role, deptID)
+	// ?acknowledged=false|overdue narrows to the user's to-do list (required
+	// policies not yet acknowledged) in a single query; =true keeps only
+	// policies whose current version they have acknowledged.
+	var policies []*database.Policy
+	var err error
+	filter := c.QueryParam("acknowledged")
+	sel, err := parseFieldSelection(c, "current_version", "acknowledgement")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return err
 	}
-	if policies == nil {
-		policies = []*database.Policy{}
+	custom, err := h.parseCustomFilter(c)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ids != nil:
+		if filter != "" {
+			return apierr.Invalid("ids cannot be combined with acknowledged", "ids")
+		}
+		policies, err = h.visiblePolicies(c, ids)
+	case filter == "" || filter == "true":
+		policies, err = h.db.ListPoliciesForUser(ctx, userID, role, deptID)
+	case filter == "false":
+		policies, err = h.db.ListPendingPoliciesForUser(ctx, userID, role, deptID)
+	case filter == "overdue":
+		policies, err = h.db.ListOverduePoliciesForUser(ctx, userID, role, deptID, time.Now())
+	default:
+		return apierr.Invalid("acknowledged must be true, false, or overdue", "acknowledged")
+	}
+	if err != nil {
+		return apierr.Database()
 	}
 
 	// Attach acknowledgement status for the current user.
-	userID := c.Get(mw.CtxUserID).(string)
-	ackMap, _ := h.db.AckStatusForUser(userID)
+	ackMap := map[string]bool{}
+	if filter == "" || filter == "true" {
+		ackMap, _ = h.db.AckStatusForUser(ctx, userID)
+	}
 
-	type policyWithAck struct {
-		*database.Policy
-		Acknowledged bool `json:"acknowledged"`
+	// Admins also get each policy's acknowledgement coverage.
+	var coverage map[string]*database.AckCoverage
+	if role == mw.RoleSuperAdmin || role == mw.RoleDeptAdmin {
+		if coverage, err = h.db.Reader().AckCoverageByPolicy(ctx); err != nil {
+			return apierr.Database()
+		}
 	}
-	result := make([]policyWithAck, len(policies))
-	for i, p := range policies {
+
+	result := make([]policyWithAck, 0, len(policies))
+	for _, p := range policies {
 		acked := false
 		if p.CurrentVersionID != nil {
 			acked = ackMap[*p.CurrentVersionID]
 		}
-		result[i] = policyWithAck{Policy: p, Acknowledged: acked}
+		if filter == "true" && !acked {
+			continue
+		}
+		if custom != nil && !custom.match(p) {
+			continue
+		}
+		item := policyWithAck{Policy: p, Acknowledged: acked}
+		if coverage != nil {
+			item.AckCoverage = coverage[p.ID]
+			if item.AckCoverage == nil {
+				item.AckCoverage = &database.AckCoverage{}
+			}
+		}
+		result = append(result, item)
 	}
 
-	return c.JSON(http.StatusOK, result)
+	if sel.empty() {
+		return c.JSON(http.StatusOK, result)
+	}
+	shaped, err := h.shapeList(c, sel, result)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, shaped)
 }
 
-// Get returns a single policy with its current version content.
-// Enforces visibility: non-SuperAdmin users cannot access dept-scoped policies outside their dept.
-// GET /api/policies/:id
-func (h *Policy) Get(c echo.Context) error {
-	policy, err := h.db.GetPolicy(c.Param("id"))
-	if err != nil {
+// viewAs reads ?as_role= and ?as_department=, with which a SuperAdmin
+// previews the policies another role and department see. ok is false when
+// neither is given.
+func (h *Policy) viewAs(c echo.Context) (role string, deptID *string, ok bool, err error) {
+	role, dept := c.QueryParam("as_role"), c.QueryParam("as_department")
+	if role == "" && dept == "" {
+		return "", nil, false, nil
+	}
+	if c.Get(mw.CtxUserRole) != mw.RoleSuperAdmin {
+		return "", nil, false, apierr.New(http.StatusForbidden, apierr.CodeForbidden, "only super admins can preview as another role")
+	}
+	switch role {
+	case mw.RoleStaff, mw.RoleDeptAdmin, mw.RoleSuperAdmin:
+	case "":
+		role = mw.RoleStaff
+	default:
+		return "", nil, false, apierr.Invalid("as_role must be Staff, DeptAdmin, or SuperAdmin", "as_role")
+	}
+	if dept != "" {
+		d, err := h.db.GetDepartment(c.Request().Context(), dept)
 		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusNotFound, "policy not found")
+			return "", nil, false, apierr.Invalid("as_department is not a department", "as_department")
+		}
+		if err != nil {
+			return "", nil, false, apierr.Database()
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		deptID = &d.ID
 	}
+	return role, deptID, true, nil
+}
 
-	// Enforce visibility for non-SuperAdmin.
-	role := c.Get(mw.CtxUserRole).(string)
-	if role != mw.RoleSuperAdmin && policy.VisibilityType == "department" {
-		deptID, _ := c.Get(mw.CtxDeptID).(*string)
-		if deptID == nil || policy.DepartmentID == nil || *deptID != *policy.DepartmentID {
-			return echo.NewHTTPError(http.StatusNotFound, "policy not found")
+// policyWithAck is a policy as listed: with the caller's acknowledgement of
+// its current version and, for admins, its coverage.
+type policyWithAck struct {
+	*database.Policy
+	Acknowledged bool `json:"acknowledged"`
+	*database.AckCoverage
+}
+
+// shapeList applies ?fields= and ?include= to list items. List views leave
+// out version content unless current_version.content is asked for.
+func (h *Policy) shapeList(c echo.Context, sel *fieldSelection, items []policyWithAck) ([]map[string]any, error) {
+	ctx := c.Request().Context()
+
+	var versions map[string]*database.PolicyVersion
+	if sel.include["current_version"] {
+		ids := []string{}
+		for _, p := range items {
+			if p.CurrentVersionID != nil {
+				ids = append(ids, *p.CurrentVersionID)
+			}
+		}
+		var err error
+		if versions, err = h.db.GetPolicyVersionsByIDs(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+	keepVersion := sel.version
+	if keepVersion == nil {
+		keepVersion = maps.Clone(versionFields)
+		delete(keepVersion, "content")
+	}
+
+	acks := map[string]*database.Acknowledgement{}
+	if sel.include["acknowledgement"] {
+		list, err := h.db.ListUserAcknowledgements(ctx, c.Get(mw.CtxUserID).(string))
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range list {
+			acks[a.PolicyVersionID] = a
+		}
+	}
+
+	out := make([]map[string]any, len(items))
+	for i, p := range items {
+		m, err := trim(p, sel.policy)
+		if err != nil {
+			return nil, err
+		}
+		if sel.include["current_version"] {
+			m["current_version"] = nil
+			if p.CurrentVersionID != nil && versions[*p.CurrentVersionID] != nil {
+				if m["current_version"], err = trim(versions[*p.CurrentVersionID], keepVersion); err != nil {
+					return nil, err
+				}
+			}
 		}
+		if sel.include["acknowledgement"] {
+			m["acknowledgement"] = nil
+			if p.CurrentVersionID != nil && acks[*p.CurrentVersionID] != nil {
+				m["acknowledgement"] = acks[*p.CurrentVersionID]
+			}
+		}
+		out[i] = m
 	}
+	return out, nil
+}
+
+// Get returns a single policy with its current version content.
+// Enforces visibility: non-SuperAdmin users cannot access dept-scoped policies outside their dept.
+// ?fields= trims the policy and current_version; ?include= limits the
+// response to the policy, acknowledged, and the sections named.
+// GET /api/policies/:id?fields=&include=current_version,acknowledgement,related,lock
+func (h *Policy) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+	sel, err := parseFieldSelection(c, "current_version", "acknowledgement", "related", "lock")
+	if err != nil {
+		return err
+	}
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	wants := func(section string) bool { return len(sel.include) == 0 || sel.include[section] }
 
 	var currentVersion *database.PolicyVersion
 	if policy.CurrentVersionID != nil {
-		currentVersion, _ = h.db.GetPolicyVersion(*policy.CurrentVersionID)
+		currentVersion, _ = h.db.GetPolicyVersion(ctx, *policy.CurrentVersionID)
 	}
 
-	userID := c.Get(mw.CtxUserID).(string)
 	acknowledged := false
 	if currentVersion != nil {
-		acknowledged, _ = h.db.HasAcknowledged(userID, currentVersion.ID)
+		acknowledged, _ = h.db.HasAcknowledged(ctx, userID, currentVersion.ID)
 	}
 
-	return c.JSON(http.StatusOK, map[string]any{
-		"policy":          policy,
-		"current_version": currentVersion,
-		"acknowledged":    acknowledged,
-	})
+	// Admins also get the policy's acknowledgement coverage.
+	var body any = policy
+	if role := c.Get(mw.CtxUserRole); role == mw.RoleSuperAdmin || role == mw.RoleDeptAdmin {
+		coverage, err := h.db.GetAckCoverage(ctx, policy.ID)
+		if err != nil {
+			return apierr.Database()
+		}
+		body = struct {
+			*database.Policy
+			*database.AckCoverage
+		}{policy, coverage}
+	}
+
+	setPolicyETag(c, policy)
+	if sel.empty() {
+		related, err := h.visibleRelated(c, policy.ID)
+		if err != nil {
+			return err
+		}
+		lock, err := h.db.GetPolicyLock(ctx, policy.ID, time.Now())
+		if err != nil {
+			return apierr.Database()
+		}
+		return c.JSON(http.StatusOK, map[string]any{
+			"policy":          body,
+			"current_version": currentVersion,
+			"acknowledged":    acknowledged,
+			"related":         related,
+			"lock":            lock,
+		})
+	}
+
+	trimmed, err := trim(body, sel.policy)
+	if err != nil {
+		return err
+	}
+	resp := map[string]any{"policy": trimmed, "acknowledged": acknowledged}
+	if wants("current_version") {
+		resp["current_version"] = nil
+		if currentVersion != nil {
+			if resp["current_version"], err = trim(currentVersion, sel.version); err != nil {
+				return err
+			}
+		}
+	}
+	if sel.include["acknowledgement"] {
+		resp["acknowledgement"] = nil
+		if currentVersion != nil && acknowledged {
+			acks, err := h.db.ListUserAcknowledgements(ctx, userID)
+			if err != nil {
+				return apierr.Database()
+			}
+			for _, a := range acks {
+				if a.PolicyVersionID == currentVersion.ID {
+					resp["acknowledgement"] = a
+				}
+			}
+		}
+	}
+	if wants("related") {
+		if resp["related"], err = h.visibleRelated(c, policy.ID); err != nil {
+			return err
+		}
+	}
+	if wants("lock") {
+		if resp["lock"], err = h.db.GetPolicyLock(ctx, policy.ID, time.Now()); err != nil {
+			return apierr.Database()
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
 // Versions returns all versions for a policy.
 // GET /api/policies/:id/versions
 func (h *Policy) Versions(c echo.Context) error {
-	versions, err := h.db.ListPolicyVersions(c.Param("id"))
+	policy, err := h.visiblePolicy(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return err
+	}
+	versions, err := h.db.ListPolicyVersions(c.Request().Context(), policy.ID)
+	if err != nil {
+		return apierr.Database()
 	}
 	if versions == nil {
 		versions = []*database.PolicyVersion{}
@@ -109,33 +410,60 @@ func (h *Policy) Versions(c echo.Context) error {
 // Acknowledge records a user's acknowledgement of the current policy version.
 // POST /api/policies/:id/acknowledge
 func (h *Policy) Acknowledge(c echo.Context) error {
-	policy, err := h.db.GetPolicy(c.Param("id"))
+	ctx := c.Request().Context()
+	policy, err := h.visiblePolicy(c)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusNotFound, "policy not found")
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return err
 	}
 
 	if policy.Status != "Published" {
-		return echo.NewHTTPError(http.StatusBadRequest, "can only acknowledge published policies")
+		return apierr.New(http.StatusBadRequest, "POLICY_NOT_PUBLISHED", "can only acknowledge published policies")
 	}
 	if policy.CurrentVersionID == nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "policy has no current version")
+		return apierr.New(http.StatusBadRequest, "POLICY_NO_CURRENT_VERSION", "policy has no current version")
 	}
 
 	userID := c.Get(mw.CtxUserID).(string)
-	already, err := h.db.HasAcknowledged(userID, *policy.CurrentVersionID)
+	already, err := h.db.HasAcknowledged(ctx, userID, *policy.CurrentVersionID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
 	}
 	if already {
-		return echo.NewHTTPError(http.StatusConflict, "already acknowledged")
+		return apierr.New(http.StatusConflict, "ALREADY_ACKNOWLEDGED", "already acknowledged")
+	}
+	version, err := h.db.GetPolicyVersion(ctx, *policy.CurrentVersionID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if !version.InEffect(time.Now()) {
+		err := apierr.New(http.StatusBadRequest, "VERSION_NOT_EFFECTIVE", "this version is not in effect")
+		return apierr.With(apierr.With(err, "effective_from", version.EffectiveFrom), "effective_to", version.EffectiveTo)
+	}
+	if err := h.checkReadRequirements(ctx, userID, *policy.CurrentVersionID); err != nil {
+		return err
 	}
 
-	ack, err := h.db.CreateAcknowledgement(userID, *policy.CurrentVersionID)
+	ack, err := h.db.CreateAcknowledgement(ctx, userID, *policy.CurrentVersionID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
+	}
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+	h.events.Publish(events.Event{
+		Type: events.PolicyAcknowledged,
+		Data: map[string]any{
+			"policy_id":         policy.ID,
+			"title":             policy.Title,
+			"user_id":           userID,
+			"policy_version_id": ack.PolicyVersionID,
+		},
+		Audience: events.Audience{AdminsOnly: true, DepartmentID: deptID},
+	})
+	if h.receipts != nil {
+		go func() {
+			if err := h.receipts.Send(context.WithoutCancel(ctx), ack.ID); err != nil {
+				log.Printf("acknowledgement receipt %s: %v", ack.ID, err)
+			}
+		}()
 	}
 	return c.JSON(http.StatusCreated, ack)
 }
@@ -143,14 +471,20 @@ func (h *Policy) Acknowledge(c echo.Context) error {
 // Create creates a new policy.
 // POST /api/policies
 func (h *Policy) Create(c echo.Context) error {
+	ctx := c.Request().Context()
 	var body struct {
 		Title          string  `json:"title"`
 		Department     string  `json:"department"`
 		DepartmentID   *string `json:"department_id"`
 		VisibilityType string  `json:"visibility_type"`
+		AckDeadline    string  `json:"ack_deadline"`
 	}
 	if err := c.Bind(&body); err != nil || body.Title == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "title is required")
+		return apierr.Invalid("title is required", "title")
+	}
+	deadline, err := parseDeadline(body.AckDeadline)
+	if err != nil {
+		return apierr.Invalid("ack_deadline must be YYYY-MM-DD or RFC3339", "ack_deadline")
 	}
 
 	if body.VisibilityType == "" {
@@ -158,7 +492,7 @@ func (h *Policy) Create(c echo.Context) error {
 	}
 	validVis := map[string]bool{"organization": true, "department": true}
 	if !validVis[body.VisibilityType] {
-		return echo.NewHTTPError(http.StatusBadRequest, "visibility_type must be organization or department")
+		return apierr.Invalid("visibility_type must be organization or department", "visibility_type")
 	}
 
 	// DeptAdmin can only create dept-scoped policies for their own department.
@@ -166,49 +500,75 @@ func (h *Policy) Create(c echo.Context) error {
 	if role == mw.RoleDeptAdmin {
 		deptID, _ := c.Get(mw.CtxDeptID).(*string)
 		if deptID == nil {
-			return echo.NewHTTPError(http.StatusForbidden, "department admin must belong to a department")
+			return apierr.New(http.StatusForbidden, "DEPARTMENT_REQUIRED", "department admin must belong to a department")
 		}
 		body.VisibilityType = "department"
 		body.DepartmentID = deptID
 	}
+	if body.DepartmentID == nil && strings.TrimSpace(body.Department) != "" {
+		if body.DepartmentID, err = h.legacyDepartmentID(c, body.Department); err != nil {
+			return err
+		}
+	}
 
-	policy, err := h.db.CreatePolicy(body.Title, body.Department, body.DepartmentID, body.VisibilityType)
+	userID := c.Get(mw.CtxUserID).(string)
+	policy, err := h.db.CreatePolicy(ctx, body.Title, "", body.DepartmentID, body.VisibilityType, &userID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
+	}
+	if deadline != nil {
+		if err := h.db.SetPolicyAckDeadline(ctx, policy.ID, deadline); err != nil {
+			return apierr.Database()
+		}
+		policy.AckDeadline = deadline
 	}
+	mw.LogAudit(c, h.db, database.ActivityPolicyCreated, "policy", policy.ID, policy.Title)
 	return c.JSON(http.StatusCreated, policy)
 }
 
+// legacyDepartmentID maps a value of the deprecated department text field,
+// sent by older clients instead of department_id, to a department.
+func (h *Policy) legacyDepartmentID(c echo.Context, name string) (*string, error) {
+	d, err := h.db.FindDepartmentByLegacyName(c.Request().Context(), name)
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	if d == nil {
+		return nil, apierr.Invalid("department is deprecated and matches no department; use department_id", "department")
+	}
+	return &d.ID, nil
+}
+
 // Update updates policy metadata and status.
 // PUT /api/policies/:id
 func (h *Policy) Update(c echo.Context) error {
-	policy, err := h.db.GetPolicy(c.Param("id"))
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusNotFound, "policy not found")
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return err
 	}
-
-	// DeptAdmin can only update their own department's policies.
 	role := c.Get(mw.CtxUserRole).(string)
-	var callerDeptID *string
-	if role == mw.RoleDeptAdmin {
-		callerDeptID, _ = c.Get(mw.CtxDeptID).(*string)
-		if callerDeptID == nil || policy.DepartmentID == nil || *callerDeptID != *policy.DepartmentID {
-			return echo.NewHTTPError(http.StatusForbidden, "cannot edit policies outside your department")
-		}
-	}
+	callerDeptID, _ := c.Get(mw.CtxDeptID).(*string)
 
 	var body struct {
-		Title          string  `json:"title"`
-		Status         string  `json:"status"`
-		Department     string  `json:"department"`
-		DepartmentID   *string `json:"department_id"`
-		VisibilityType string  `json:"visibility_type"`
+		Title           string  `json:"title"`
+		Status          string  `json:"status"`
+		Department      string  `json:"department"`
+		DepartmentID    *string `json:"department_id"`
+		VisibilityType  string  `json:"visibility_type"`
+		AckDeadline     *string `json:"ack_deadline"` // nil = unchanged, "" = clear
+		ExpectedVersion *int    `json:"expected_version"`
+		Public          *bool   `json:"public"`
 	}
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid body")
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+
+	// Optimistic locking: the caller must say which version they edited,
+	// either as If-Match (the ETag from GET) or expected_version.
+	expected, err := expectedPolicyVersion(c, body.ExpectedVersion)
+	if err != nil {
+		return err
 	}
 
 	// Apply defaults from existing data.
@@ -218,12 +578,14 @@ func (h *Policy) Update(c echo.Context) error {
 	if body.Status == "" {
 		body.Status = policy.Status
 	}
-	if body.Department == "" {
-		body.Department = policy.Department
-	}
 	if body.VisibilityType == "" {
 		body.VisibilityType = policy.VisibilityType
 	}
+	if body.DepartmentID == nil && strings.TrimSpace(body.Department) != "" {
+		if body.DepartmentID, err = h.legacyDepartmentID(c, body.Department); err != nil {
+			return err
+		}
+	}
 	if body.DepartmentID == nil {
 		body.DepartmentID = policy.DepartmentID
 	}
@@ -232,91 +594,382 @@ func (h *Policy) Update(c echo.Context) error {
 	if role == mw.RoleDeptAdmin {
 		body.VisibilityType = "department"
 		body.DepartmentID = callerDeptID
+		if body.Public != nil && *body.Public {
+			return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "only super admins can publish policies to the public portal")
+		}
+	}
+	// The legacy text only survives on policies that still lack a department.
+	body.Department = ""
+	if body.DepartmentID == nil {
+		body.Department = policy.Department
 	}
 
-	validStatuses := map[string]bool{"Draft": true, "Review": true, "Published": true, "Archived": true}
-	if !validStatuses[body.Status] {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid status")
+	if err := h.checkStatusChange(c, policy, body.Status); err != nil {
+		return err
 	}
 
-	if err := h.db.UpdatePolicy(policy.ID, body.Title, body.Status, body.Department, body.DepartmentID, body.VisibilityType); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+	var deadline *time.Time
+	if body.AckDeadline != nil {
+		if deadline, err = parseDeadline(*body.AckDeadline); err != nil {
+			return apierr.Invalid("ack_deadline must be YYYY-MM-DD or RFC3339", "ack_deadline")
+		}
 	}
 
-	updated, _ := h.db.GetPolicy(policy.ID)
+	userID := c.Get(mw.CtxUserID).(string)
+	err = h.db.UpdatePolicyIfVersion(ctx, policy.ID, expected, &userID, body.Title, body.Status, body.Department, body.DepartmentID, body.VisibilityType)
+	if errors.Is(err, database.ErrVersionConflict) {
+		return apierr.New(http.StatusConflict, "POLICY_MODIFIED", "policy was modified by someone else; reload and try again")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	if body.AckDeadline != nil {
+		if err := h.db.SetPolicyAckDeadline(ctx, policy.ID, deadline); err != nil {
+			return apierr.Database()
+		}
+	}
+	if body.Public != nil {
+		if err := h.db.SetPolicyPublic(ctx, policy.ID, *body.Public); err != nil {
+			return apierr.Database()
+		}
+	}
+
+	updated, err := h.db.GetPolicy(ctx, policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	h.statusChanged(c, policy, updated)
+	setPolicyETag(c, updated)
 	return c.JSON(http.StatusOK, updated)
 }
 
-// CreateVersion adds a new version to a policy and sets it as current.
-// POST /api/policies/:id/versions
-func (h *Policy) CreateVersion(c echo.Context) error {
-	policy, err := h.db.GetPolicy(c.Param("id"))
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusNotFound, "policy not found")
+var policyStatuses = map[string]bool{"Draft": true, "Review": true, "Published": true, "Archived": true}
+
+// checkStatusChange validates moving policy to status: the status must
+// exist, and publishing waits for open change requests to be closed.
+func (h *Policy) checkStatusChange(c echo.Context, policy *database.Policy, status string) error {
+	if !policyStatuses[status] {
+		return apierr.Invalid("invalid status", "status")
+	}
+
+	if status == "Published" && policy.Status != "Published" {
+		open, err := h.db.CountOpenChangeRequests(c.Request().Context(), policy.ID)
+		if err != nil {
+			return apierr.Database()
+		}
+		if open > 0 {
+			return apierr.New(http.StatusConflict, "OPEN_CHANGE_REQUESTS",
+				fmt.Sprintf("%d open change request(s) must be resolved or dismissed before publishing", open))
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
 	}
+	return nil
+}
 
-	// DeptAdmin can only add versions to their own department's dept-scoped policies.
-	role := c.Get(mw.CtxUserRole).(string)
-	if role == mw.RoleDeptAdmin {
-		deptID, _ := c.Get(mw.CtxDeptID).(*string)
-		if policy.VisibilityType != "department" ||
-			deptID == nil || policy.DepartmentID == nil || *deptID != *policy.DepartmentID {
-			return echo.NewHTTPError(http.StatusForbidden, "cannot add versions to policies outside your department")
+// statusChanged records and announces a policy's move from before's status
+// to updated's.
+func (h *Policy) statusChanged(c echo.Context, before, updated *database.Policy) {
+	if updated.Status == before.Status {
+		return
+	}
+	switch updated.Status {
+	case "Published":
+		mw.LogAudit(c, h.db, database.ActivityPolicyPublished, "policy", updated.ID, updated.Title)
+		h.publishEvent(updated, "")
+		h.notifyPublished(c.Request().Context(), updated, "")
+	case "Archived":
+		mw.LogAudit(c, h.db, database.ActivityPolicyArchived, "policy", updated.ID, updated.Title)
+	}
+}
+
+// publishEvent announces that p, or a new version of it, is now published
+// to everyone who can see it.
+func (h *Policy) publishEvent(p *database.Policy, versionString string) {
+	audience := events.Audience{}
+	if p.VisibilityType == "department" {
+		audience.DepartmentID = p.DepartmentID
+	}
+	h.events.Publish(events.Event{
+		Type: events.PolicyPublished,
+		Data: map[string]any{
+			"policy_id":      p.ID,
+			"title":          p.Title,
+			"version_string": versionString,
+		},
+		Audience: audience,
+	})
+}
+
+// notifyPublished queues emails about p's newly published current version
+// to the users who can see it and have yet to acknowledge it, and tells
+// them in-app. Failures are logged; they must not fail the publish.
+func (h *Policy) notifyPublished(ctx context.Context, p *database.Policy, versionString string) {
+	if on, err := h.db.GetBoolSetting(ctx, database.SettingPublishNotifications, true); err != nil || !on {
+		return
+	}
+	userIDs, err := h.db.EnqueuePublishNotifications(ctx, p.ID)
+	if err != nil {
+		log.Printf("queue publish notifications for %s: %v", p.ID, err)
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+	message := fmt.Sprintf("%q has been published and needs your acknowledgement.", p.Title)
+	if versionString != "" {
+		message = fmt.Sprintf("Version %s of %q has been published and needs your acknowledgement.", versionString, p.Title)
+	}
+	h.events.Publish(events.Event{
+		Type: events.Notification,
+		Data: map[string]any{
+			"kind":      "published",
+			"policy_id": p.ID,
+			"title":     p.Title,
+			"message":   message,
+		},
+		Audience: events.Audience{UserIDs: userIDs},
+	})
+}
+
+// expectedPolicyVersion reads the version the client based its edit on from
+// If-Match (a strong or weak ETag, with or without quotes) or, failing that,
+// the expected_version body field.
+func expectedPolicyVersion(c echo.Context, fromBody *int) (int, error) {
+	if tag := c.Request().Header.Get("If-Match"); tag != "" {
+		tag = strings.Trim(strings.TrimPrefix(tag, "W/"), `"`)
+		v, err := strconv.Atoi(tag)
+		if err != nil || v < 1 {
+			return 0, apierr.Invalid("If-Match must be a policy version", "If-Match")
 		}
+		return v, nil
+	}
+	if fromBody == nil {
+		return 0, apierr.New(http.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "If-Match header or expected_version is required")
+	}
+	if *fromBody < 1 {
+		return 0, apierr.Invalid("expected_version must be positive", "expected_version")
+	}
+	return *fromBody, nil
+}
+
+func setPolicyETag(c echo.Context, p *database.Policy) {
+	c.Response().Header().Set("ETag", `"`+strconv.Itoa(p.Version)+`"`)
+}
+
+// CreateVersion adds a new version to a policy and sets it as current.
+// effective_from and effective_to (YYYY-MM-DD or RFC3339, both optional)
+// bound when it is in force and can be acknowledged.
+// POST /api/policies/:id/versions
+func (h *Policy) CreateVersion(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.versionablePolicy(c)
+	if err != nil {
+		return err
 	}
 
 	var body struct {
 		Content       string `json:"content"`
 		VersionString string `json:"version_string"`
 		Changelog     string `json:"changelog"`
+		EffectiveFrom string `json:"effective_from"`
+		EffectiveTo   string `json:"effective_to"`
 	}
 	if err := c.Bind(&body); err != nil || body.Content == "" || body.VersionString == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "content and version_string are required")
+		return apierr.Invalid("content and version_string are required", "content", "version_string")
+	}
+	window, err := parseEffectiveWindow(body.EffectiveFrom, body.EffectiveTo)
+	if err != nil {
+		return err
+	}
+
+	// Authors often skip the changelog; derive one from the section-level diff
+	// against the version being replaced.
+	if strings.TrimSpace(body.Changelog) == "" {
+		prev := ""
+		if policy.CurrentVersionID != nil {
+			cur, err := h.db.GetPolicyVersion(ctx, *policy.CurrentVersionID)
+			if err != nil {
+				return apierr.Database()
+			}
+			prev = cur.Content
+		}
+		body.Changelog = changelog.Summarize(prev, body.Content)
 	}
 
-	version, err := h.db.CreatePolicyVersion(policy.ID, body.Content, body.VersionString, body.Changelog)
+	version, err := h.addVersion(c, policy, body.Content, body.VersionString, body.Changelog, nil, window)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return err
 	}
+	return c.JSON(http.StatusCreated, version)
+}
 
-	if err := h.db.SetPolicyCurrentVersion(policy.ID, version.ID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+// versionablePolicy loads the :id policy for adding a version, refusing
+// callers outside its department and drafts locked by someone else.
+func (h *Policy) versionablePolicy(c echo.Context) (*database.Policy, error) {
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return nil, err
+	}
+	// DeptAdmin can only add versions to dept-scoped policies.
+	if c.Get(mw.CtxUserRole) == mw.RoleDeptAdmin && policy.VisibilityType != "department" {
+		return nil, apierr.New(http.StatusForbidden, "OUTSIDE_DEPARTMENT", "cannot add versions to policies outside your department")
 	}
 
-	return c.JSON(http.StatusCreated, version)
+	if lock, err := h.lockedByOther(c, policy.ID); err != nil {
+		return nil, err
+	} else if lock != nil {
+		return nil, apierr.With(apierr.New(http.StatusConflict, "POLICY_LOCKED", lock.UserName+" is editing this draft"), "lock", lock)
+	}
+	return policy, nil
 }
 
-// AdminStats returns aggregate statistics.
-// GET /api/admin/stats
-func (h *Policy) AdminStats(c echo.Context) error {
-	stats, err := h.db.GetStats()
+// effectiveWindow bounds when a version is in force; nil ends are open.
+type effectiveWindow struct {
+	from, to *time.Time
+}
+
+// parseEffectiveWindow reads effective_from (a date means the start of that
+// day, UTC) and effective_to (the end of that day), each optional.
+func parseEffectiveWindow(from, to string) (effectiveWindow, error) {
+	var w effectiveWindow
+	if from != "" {
+		t, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			t, err = time.Parse(time.RFC3339, from)
+		}
+		if err != nil {
+			return w, apierr.Invalid("effective_from must be YYYY-MM-DD or RFC3339", "effective_from")
+		}
+		t = t.UTC()
+		w.from = &t
+	}
+	var err error
+	if w.to, err = parseDeadline(to); err != nil {
+		return w, apierr.Invalid("effective_to must be YYYY-MM-DD or RFC3339", "effective_to")
+	}
+	if w.from != nil && w.to != nil && !w.to.After(*w.from) {
+		return w, apierr.Invalid("effective_to must be after effective_from", "effective_to")
+	}
+	return w, nil
+}
+
+// addVersion saves a new version, optionally backed by an uploaded original,
+// and makes it current.
+func (h *Policy) addVersion(c echo.Context, policy *database.Policy, content, versionString, changes string, src *database.VersionSource, window effectiveWindow) (*database.PolicyVersion, error) {
+	ctx := c.Request().Context()
+	userID := c.Get(mw.CtxUserID).(string)
+	version, err := h.db.CreatePolicyVersion(ctx, policy.ID, content, versionString, changes, &userID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return nil, apierr.Database()
+	}
+	if window.from != nil || window.to != nil {
+		if err := h.db.SetPolicyVersionEffective(ctx, version.ID, window.from, window.to); err != nil {
+			return nil, apierr.Database()
+		}
+		version.EffectiveFrom, version.EffectiveTo = window.from, window.to
+	}
+	if src != nil {
+		if err := h.db.SetPolicyVersionSource(ctx, version.ID, src); err != nil {
+			return nil, apierr.Database()
+		}
+		version.Source = src
 	}
 
-	policies, _ := h.db.ListPolicies()
-	type policyAckCount struct {
-		PolicyID string `json:"policy_id"`
-		Title    string `json:"title"`
-		AckCount int    `json:"ack_count"`
+	if err := h.db.SetPolicyCurrentVersion(ctx, policy.ID, version.ID); err != nil {
+		return nil, apierr.Database()
 	}
-	var ackCounts []policyAckCount
-	for _, p := range policies {
-		if p.CurrentVersionID != nil && p.Status == "Published" {
-			acks, _ := h.db.ListAcknowledgements(*p.CurrentVersionID)
-			ackCounts = append(ackCounts, policyAckCount{
-				PolicyID: p.ID,
-				Title:    p.Title,
-				AckCount: len(acks),
-			})
+
+	mw.LogAudit(c, h.db, database.ActivityVersionCreated, "policy", policy.ID, policy.Title+" "+version.VersionString)
+	if policy.Status == "Published" {
+		h.publishEvent(policy, version.VersionString) // a new version needs acknowledging
+		h.notifyPublished(ctx, policy, version.VersionString)
+	}
+
+	// Enforce the org-level retention limit; failure here must not fail the
+	// publish itself.
+	if keep, err := h.db.GetIntSetting(ctx, database.SettingVersionRetention, 0); err == nil && keep > 0 {
+		if _, err := h.db.PruneOldVersions(ctx, policy.ID, keep); err != nil {
+			log.Printf("prune versions of %s: %v", policy.ID, err)
 		}
 	}
+	return version, nil
+}
 
+// DeleteVersion removes a version that is not current and has never been
+// acknowledged, e.g. a test upload.
+// DELETE /api/policies/:id/versions/:versionId
+func (h *Policy) DeleteVersion(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	version, err := h.db.GetPolicyVersion(ctx, c.Param("versionId"))
+	if err != nil || version.PolicyID != policy.ID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "VERSION_NOT_FOUND", "version not found")
+		}
+		return apierr.Database()
+	}
+	if err := h.db.DeletePolicyVersion(ctx, version.ID); err != nil {
+		if errors.Is(err, database.ErrVersionInUse) {
+			return apierr.New(http.StatusConflict, "VERSION_IN_USE", "only non-current versions without acknowledgements can be deleted")
+		}
+		return apierr.Database()
+	}
+	if version.Source != nil && h.store != nil {
+		if err := h.store.Delete(ctx, version.Source.StorageKey); err != nil {
+			log.Printf("version file delete %s: %v", version.Source.StorageKey, err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AdminStats returns aggregate statistics. With from and/or to
+// (YYYY-MM-DD, inclusive) the response adds a period with the
+// acknowledgements, publishes, and new users in that range, and the
+// per-policy acknowledgement counts only include that range.
+// GET /api/admin/stats?from=&to=
+func (h *Policy) AdminStats(c echo.Context) error {
+	ctx := c.Request().Context()
+	r, err := parseStatsRange(c)
+	if err != nil {
+		return err
+	}
+	stats, err := h.db.GetStats(ctx, r)
+	if err != nil {
+		return apierr.Database()
+	}
+	ackCounts, err := h.db.PolicyAckCounts(ctx, r)
+	if err != nil {
+		return apierr.Database()
+	}
 	return c.JSON(http.StatusOK, map[string]any{
 		"stats":      stats,
 		"ack_counts": ackCounts,
 	})
 }
+
+// parseStatsRange reads ?from= and ?to= as whole UTC days, returning nil
+// when neither is given.
+func parseStatsRange(c echo.Context) (*database.StatsRange, error) {
+	r := &database.StatsRange{}
+	var err error
+	if v := c.QueryParam("from"); v != "" {
+		if r.From, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, apierr.Invalid("from must be YYYY-MM-DD", "from")
+		}
+	}
+	if v := c.QueryParam("to"); v != "" {
+		if r.To, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, apierr.Invalid("to must be YYYY-MM-DD", "to")
+		}
+		r.To = r.To.AddDate(0, 0, 1) // inclusive of the whole "to" day
+	}
+	if r.From.IsZero() && r.To.IsZero() {
+		return nil, nil
+	}
+	if !r.To.IsZero() && !r.To.After(r.From) {
+		return nil, apierr.Invalid("to must not be before from", "to")
+	}
+	return r, nil
+}
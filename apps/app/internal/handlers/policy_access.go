@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// Every handler that loads the :id policy goes through visiblePolicy to read
+// it or managedPolicy to change it, so that who may see or edit a policy is
+// decided in one place rather than per endpoint.
+
+// loadPolicy loads a policy, mapping a missing one to 404 POLICY_NOT_FOUND.
+func (h *Policy) loadPolicy(c echo.Context, id string) (*database.Policy, error) {
+	policy, err := h.db.GetPolicy(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apierr.New(http.StatusNotFound, "POLICY_NOT_FOUND", "policy not found")
+		}
+		return nil, apierr.Database()
+	}
+	return policy, nil
+}
+
+// visiblePolicy loads the :id policy and enforces visibility for non-SuperAdmin
+// users. Policies the caller cannot see are reported as not found.
+func (h *Policy) visiblePolicy(c echo.Context) (*database.Policy, error) {
+	policy, err := h.loadPolicy(c, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	visible, err := h.canView(c, policy)
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	if !visible {
+		mw.Deny(c)
+		return nil, apierr.New(http.StatusNotFound, "POLICY_NOT_FOUND", "policy not found")
+	}
+	return policy, nil
+}
+
+// canView reports whether the caller may see policy. Explicit assignment
+// grants access to department-scoped policies.
+func (h *Policy) canView(c echo.Context, policy *database.Policy) (bool, error) {
+	role := c.Get(mw.CtxUserRole).(string)
+	if role == mw.RoleSuperAdmin || policy.VisibilityType != "department" {
+		return true, nil
+	}
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+	if sameDept(deptID, policy.DepartmentID) {
+		return true, nil
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	return h.db.IsPolicyAssignedTo(c.Request().Context(), policy.ID, userID, role, deptID)
+}
+
+// managedPolicy loads the :id policy and checks the caller may manage it.
+func (h *Policy) managedPolicy(c echo.Context) (*database.Policy, error) {
+	policy, err := h.loadPolicy(c, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := canManage(c, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// canManage refuses a DeptAdmin changing a policy outside their department.
+func canManage(c echo.Context, policy *database.Policy) error {
+	if c.Get(mw.CtxUserRole) == mw.RoleDeptAdmin {
+		deptID, _ := c.Get(mw.CtxDeptID).(*string)
+		if !sameDept(deptID, policy.DepartmentID) {
+			return apierr.New(http.StatusForbidden, "OUTSIDE_DEPARTMENT", "cannot manage policies outside your department")
+		}
+	}
+	return nil
+}
+
+// sameDept reports whether both department IDs are set and equal.
+func sameDept(a, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
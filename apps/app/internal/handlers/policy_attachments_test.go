@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/scan"
+	"policyflow/internal/storage"
+	"policyflow/internal/testutil"
+)
+
+// TestAttachments_UploadDownloadDelete round-trips an attachment through
+// local blob storage.
+func TestAttachments_UploadDownloadDelete(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	p, _ := db.CreatePolicy(ctx, "Travel", "", nil, "organization", nil)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	e := echo.New()
+	h := NewAttachments(NewPolicy(db), store)
+
+	var buf bytes.Buffer
+	mp := multipart.NewWriter(&buf)
+	fw, _ := mp.CreateFormFile("file", "expense-form.pdf")
+	fw.Write([]byte("%PDF-1.4 form"))
+	mp.Close()
+	req := httptest.NewRequest(http.MethodPost, "/", &buf)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(p.ID)
+	c.Set(mw.CtxUserRole, mw.RoleSuperAdmin)
+	c.Set(mw.CtxUserID, testutil.UserID)
+	if err := h.Upload(c); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	var a database.PolicyAttachment
+	if err := json.Unmarshal(rec.Body.Bytes(), &a); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if a.Filename != "expense-form.pdf" || a.SizeBytes != 13 || a.SHA256 == "" {
+		t.Fatalf("attachment = %+v", a)
+	}
+
+	c, rec = testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleStaff, nil)
+	c.SetParamNames("id", "attachmentId")
+	c.SetParamValues(p.ID, a.ID)
+	if err := h.Download(c); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if rec.Body.String() != "%PDF-1.4 form" {
+		t.Errorf("downloaded %q", rec.Body.String())
+	}
+
+	c, _ = testutil.NewContext(e, http.MethodDelete, "", p.ID, mw.RoleSuperAdmin, nil)
+	c.SetParamNames("id", "attachmentId")
+	c.SetParamValues(p.ID, a.ID)
+	if err := h.Delete(c); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	a2, _ := db.GetPolicyAttachment(ctx, a.ID)
+	if _, err := store.Get(ctx, "attachments/"+p.ID+"/"+a.ID); err != storage.ErrNotFound || a2 != nil {
+		t.Errorf("after delete: store err=%v row=%v", err, a2)
+	}
+}
+
+// stubScanner flags files containing "EICAR".
+type stubScanner struct{}
+
+func (stubScanner) Name() string { return "stub" }
+
+func (stubScanner) Scan(ctx context.Context, r io.Reader) (scan.Result, error) {
+	data, err := io.ReadAll(r)
+	if bytes.Contains(data, []byte("EICAR")) {
+		return scan.Result{Infected: true, Signature: "Eicar-Test-Signature"}, err
+	}
+	return scan.Result{}, err
+}
+
+// TestAttachments_InfectedUploadQuarantined verifies that a flagged upload
+// is refused, kept in quarantine rather than attached, and recorded, and
+// that a clean one is stored as usual.
+func TestAttachments_InfectedUploadQuarantined(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Travel", "", nil, "organization", nil)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	e := echo.New()
+	policyH := NewPolicy(db)
+	policyH.SetStore(store)
+	policyH.SetScanner(stubScanner{})
+	h := NewAttachments(policyH, store)
+	upload := func(content string) error {
+		var buf bytes.Buffer
+		mp := multipart.NewWriter(&buf)
+		fw, _ := mp.CreateFormFile("file", "form.pdf")
+		fw.Write([]byte(content))
+		mp.Close()
+		req := httptest.NewRequest(http.MethodPost, "/", &buf)
+		req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues(p.ID)
+		c.Set(mw.CtxUserRole, mw.RoleSuperAdmin)
+		c.Set(mw.CtxUserID, admin.ID)
+		return h.Upload(c)
+	}
+
+	var he *echo.HTTPError
+	if err := upload("%PDF-1.4 EICAR"); !errors.As(err, &he) || he.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("infected upload: err = %v; want 422", err)
+	}
+	if err := upload("%PDF-1.4 form"); err != nil {
+		t.Fatalf("clean upload: %v", err)
+	}
+	if list, _ := db.ListPolicyAttachments(ctx, p.ID); len(list) != 1 {
+		t.Errorf("attachments = %d; want only the clean one", len(list))
+	}
+
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	c.QueryParams().Set("infected", "true")
+	scans := NewUploadScans(db, store)
+	if err := scans.List(c); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var flagged []database.UploadScan
+	json.Unmarshal(rec.Body.Bytes(), &flagged)
+	if len(flagged) != 1 || flagged[0].Signature != "Eicar-Test-Signature" || !flagged[0].Quarantined {
+		t.Fatalf("flagged = %+v", flagged)
+	}
+	rc, err := store.Get(ctx, "quarantine/"+flagged[0].ID)
+	if err != nil {
+		t.Fatalf("quarantined file: %v", err)
+	}
+	rc.Close()
+
+	c, _ = testutil.NewContext(e, http.MethodDelete, "", flagged[0].ID, mw.RoleSuperAdmin, nil)
+	if err := scans.DeleteFile(c); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := store.Get(ctx, "quarantine/"+flagged[0].ID); err != storage.ErrNotFound {
+		t.Errorf("after delete: store err = %v", err)
+	}
+}
@@ -1,73 +1,34 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"net/http/httptest"
-	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
-	_ "modernc.org/sqlite"
-
 	"policyflow/internal/database"
 	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
 )
 
-// makeTestDB opens an in-memory SQLite DB, runs Init + Migrate, and returns it.
-func makeTestDB(t *testing.T) *database.DB {
-	t.Helper()
-	conn, err := sql.Open("sqlite", ":memory:")
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	conn.SetMaxOpenConns(1)
-	t.Cleanup(func() { conn.Close() })
-
-	db := database.New(conn)
-	if err := db.Init(); err != nil {
-		t.Fatalf("db.Init: %v", err)
-	}
-	if err := db.Migrate(); err != nil {
-		t.Fatalf("db.Migrate: %v", err)
-	}
-	return db
-}
-
-// makeCtx builds an echo context with role/deptID set — bypassing JWT middleware.
-func makeCtx(e *echo.Echo, method, body string, policyID, role string, deptID *string) (echo.Context, *httptest.ResponseRecorder) {
-	req := httptest.NewRequest(method, "/", strings.NewReader(body))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
-	c.SetParamNames("id")
-	c.SetParamValues(policyID)
-	c.Set(mw.CtxUserRole, role)
-	c.Set(mw.CtxUserID, "test-user-id")
-	if deptID != nil {
-		c.Set(mw.CtxDeptID, deptID)
-	}
-	return c, rec
-}
-
-func strPtr(s string) *string { return &s }
-
 // ─── Policy.Update() tests ──────────────────────────────────────────────────
 
 // TestDeptAdmin_Update_CannotEscalateVisibility verifies that a DeptAdmin sending
 // {"visibility_type":"organization"} on their own dept-scoped policy gets a 200
 // response but the policy remains department-scoped.
 func TestDeptAdmin_Update_CannotEscalateVisibility(t *testing.T) {
-	db := makeTestDB(t)
-	dept, _ := db.CreateDepartment("Engineering", "")
-	policy, _ := db.CreatePolicy("Test Policy", "", strPtr(dept.ID), "department")
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	dept, _ := db.CreateDepartment(ctx, "Engineering", "")
+	policy, _ := db.CreatePolicy(ctx, "Test Policy", "", testutil.Ptr(dept.ID), "department", nil)
 
 	e := echo.New()
 	h := NewPolicy(db)
 
-	body := `{"visibility_type":"organization"}`
-	c, rec := makeCtx(e, http.MethodPut, body, policy.ID, mw.RoleDeptAdmin, strPtr(dept.ID))
+	body := `{"visibility_type":"organization","expected_version":1}`
+	c, rec := testutil.NewContext(e, http.MethodPut, body, policy.ID, mw.RoleDeptAdmin, testutil.Ptr(dept.ID))
 
 	if err := h.Update(c); err != nil {
 		t.Fatalf("unexpected handler error: %v", err)
@@ -88,16 +49,17 @@ func TestDeptAdmin_Update_CannotEscalateVisibility(t *testing.T) {
 // TestDeptAdmin_Update_CannotReassignDepartment verifies that a DeptAdmin sending
 // a different department_id cannot move a policy to another department.
 func TestDeptAdmin_Update_CannotReassignDepartment(t *testing.T) {
-	db := makeTestDB(t)
-	deptA, _ := db.CreateDepartment("Engineering", "")
-	deptB, _ := db.CreateDepartment("HR", "")
-	policy, _ := db.CreatePolicy("Test Policy", "", strPtr(deptA.ID), "department")
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	deptA, _ := db.CreateDepartment(ctx, "Engineering", "")
+	deptB, _ := db.CreateDepartment(ctx, "HR", "")
+	policy, _ := db.CreatePolicy(ctx, "Test Policy", "", testutil.Ptr(deptA.ID), "department", nil)
 
 	e := echo.New()
 	h := NewPolicy(db)
 
-	body := `{"department_id":"` + deptB.ID + `"}`
-	c, rec := makeCtx(e, http.MethodPut, body, policy.ID, mw.RoleDeptAdmin, strPtr(deptA.ID))
+	body := `{"department_id":"` + deptB.ID + `","expected_version":1}`
+	c, rec := testutil.NewContext(e, http.MethodPut, body, policy.ID, mw.RoleDeptAdmin, testutil.Ptr(deptA.ID))
 
 	if err := h.Update(c); err != nil {
 		t.Fatalf("unexpected handler error: %v", err)
@@ -118,15 +80,16 @@ func TestDeptAdmin_Update_CannotReassignDepartment(t *testing.T) {
 // TestSuperAdmin_Update_CanChangeVisibility verifies that a SuperAdmin CAN change
 // visibility_type and department_id freely.
 func TestSuperAdmin_Update_CanChangeVisibility(t *testing.T) {
-	db := makeTestDB(t)
-	deptA, _ := db.CreateDepartment("Engineering", "")
-	policy, _ := db.CreatePolicy("Test Policy", "", strPtr(deptA.ID), "department")
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	deptA, _ := db.CreateDepartment(ctx, "Engineering", "")
+	policy, _ := db.CreatePolicy(ctx, "Test Policy", "", testutil.Ptr(deptA.ID), "department", nil)
 
 	e := echo.New()
 	h := NewPolicy(db)
 
-	body := `{"visibility_type":"organization"}`
-	c, rec := makeCtx(e, http.MethodPut, body, policy.ID, mw.RoleSuperAdmin, nil)
+	body := `{"visibility_type":"organization","expected_version":1}`
+	c, rec := testutil.NewContext(e, http.MethodPut, body, policy.ID, mw.RoleSuperAdmin, nil)
 
 	if err := h.Update(c); err != nil {
 		t.Fatalf("unexpected handler error: %v", err)
@@ -149,15 +112,16 @@ func TestSuperAdmin_Update_CanChangeVisibility(t *testing.T) {
 // TestDeptAdmin_CreateVersion_BlockedOnOrgWidePolicy verifies that a DeptAdmin
 // gets a 403 when trying to add a version to an org-wide policy.
 func TestDeptAdmin_CreateVersion_BlockedOnOrgWidePolicy(t *testing.T) {
-	db := makeTestDB(t)
-	dept, _ := db.CreateDepartment("Engineering", "")
-	orgPolicy, _ := db.CreatePolicy("Org Policy", "", nil, "organization")
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	dept, _ := db.CreateDepartment(ctx, "Engineering", "")
+	orgPolicy, _ := db.CreatePolicy(ctx, "Org Policy", "", nil, "organization", nil)
 
 	e := echo.New()
 	h := NewPolicy(db)
 
 	body := `{"content":"# Content","version_string":"v1.0.0","changelog":"init"}`
-	c, _ := makeCtx(e, http.MethodPost, body, orgPolicy.ID, mw.RoleDeptAdmin, strPtr(dept.ID))
+	c, _ := testutil.NewContext(e, http.MethodPost, body, orgPolicy.ID, mw.RoleDeptAdmin, testutil.Ptr(dept.ID))
 
 	err := h.CreateVersion(c)
 	if err == nil {
@@ -172,16 +136,17 @@ func TestDeptAdmin_CreateVersion_BlockedOnOrgWidePolicy(t *testing.T) {
 // TestDeptAdmin_CreateVersion_BlockedOnOtherDeptPolicy verifies that a DeptAdmin
 // gets a 403 when trying to add a version to another department's policy.
 func TestDeptAdmin_CreateVersion_BlockedOnOtherDeptPolicy(t *testing.T) {
-	db := makeTestDB(t)
-	deptA, _ := db.CreateDepartment("Engineering", "")
-	deptB, _ := db.CreateDepartment("HR", "")
-	deptBPolicy, _ := db.CreatePolicy("HR Policy", "", strPtr(deptB.ID), "department")
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	deptA, _ := db.CreateDepartment(ctx, "Engineering", "")
+	deptB, _ := db.CreateDepartment(ctx, "HR", "")
+	deptBPolicy, _ := db.CreatePolicy(ctx, "HR Policy", "", testutil.Ptr(deptB.ID), "department", nil)
 
 	e := echo.New()
 	h := NewPolicy(db)
 
 	body := `{"content":"# Content","version_string":"v1.0.0","changelog":"init"}`
-	c, _ := makeCtx(e, http.MethodPost, body, deptBPolicy.ID, mw.RoleDeptAdmin, strPtr(deptA.ID))
+	c, _ := testutil.NewContext(e, http.MethodPost, body, deptBPolicy.ID, mw.RoleDeptAdmin, testutil.Ptr(deptA.ID))
 
 	err := h.CreateVersion(c)
 	if err == nil {
@@ -196,15 +161,16 @@ func TestDeptAdmin_CreateVersion_BlockedOnOtherDeptPolicy(t *testing.T) {
 // TestDeptAdmin_CreateVersion_AllowedOnOwnPolicy verifies that a DeptAdmin CAN
 // add a version to their own department's dept-scoped policy.
 func TestDeptAdmin_CreateVersion_AllowedOnOwnPolicy(t *testing.T) {
-	db := makeTestDB(t)
-	dept, _ := db.CreateDepartment("Engineering", "")
-	ownPolicy, _ := db.CreatePolicy("Own Policy", "", strPtr(dept.ID), "department")
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	dept, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ownPolicy, _ := db.CreatePolicy(ctx, "Own Policy", "", testutil.Ptr(dept.ID), "department", nil)
 
 	e := echo.New()
 	h := NewPolicy(db)
 
 	body := `{"content":"# Content","version_string":"v1.0.0","changelog":"init"}`
-	c, rec := makeCtx(e, http.MethodPost, body, ownPolicy.ID, mw.RoleDeptAdmin, strPtr(dept.ID))
+	c, rec := testutil.NewContext(e, http.MethodPost, body, ownPolicy.ID, mw.RoleDeptAdmin, testutil.Ptr(dept.ID))
 
 	if err := h.CreateVersion(c); err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -217,14 +183,15 @@ func TestDeptAdmin_CreateVersion_AllowedOnOwnPolicy(t *testing.T) {
 // TestSuperAdmin_CreateVersion_AllowedOnOrgWidePolicy verifies that a SuperAdmin
 // CAN add a version to an org-wide policy.
 func TestSuperAdmin_CreateVersion_AllowedOnOrgWidePolicy(t *testing.T) {
-	db := makeTestDB(t)
-	orgPolicy, _ := db.CreatePolicy("Org Policy", "", nil, "organization")
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	orgPolicy, _ := db.CreatePolicy(ctx, "Org Policy", "", nil, "organization", nil)
 
 	e := echo.New()
 	h := NewPolicy(db)
 
 	body := `{"content":"# Content","version_string":"v1.0.0","changelog":"init"}`
-	c, rec := makeCtx(e, http.MethodPost, body, orgPolicy.ID, mw.RoleSuperAdmin, nil)
+	c, rec := testutil.NewContext(e, http.MethodPost, body, orgPolicy.ID, mw.RoleSuperAdmin, nil)
 
 	if err := h.CreateVersion(c); err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -233,3 +200,28 @@ func TestSuperAdmin_CreateVersion_AllowedOnOrgWidePolicy(t *testing.T) {
 		t.Errorf("expected 201, got %d: %s", rec.Code, rec.Body.String())
 	}
 }
+
+// ─── Visibility across policy endpoints ─────────────────────────────────────
+
+// TestStaff_OtherDeptPolicy_HiddenFromEveryReadEndpoint verifies that a
+// department-scoped policy is reported as not found to Staff of another
+// department by Get, Versions and Acknowledge alike.
+func TestStaff_OtherDeptPolicy_HiddenFromEveryReadEndpoint(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	deptA, _ := db.CreateDepartment(ctx, "Engineering", "")
+	deptB, _ := db.CreateDepartment(ctx, "HR", "")
+	policy, _ := db.CreatePolicy(ctx, "Salary Bands", "", testutil.Ptr(deptB.ID), "department", nil)
+	v, _ := db.CreatePolicyVersion(ctx, policy.ID, "Confidential", "1.0", "", nil)
+	db.SetPolicyCurrentVersion(ctx, policy.ID, v.ID)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	for name, fn := range map[string]echo.HandlerFunc{"Get": h.Get, "Versions": h.Versions, "Acknowledge": h.Acknowledge} {
+		c, _ := testutil.NewContext(e, http.MethodGet, "", policy.ID, mw.RoleStaff, testutil.Ptr(deptA.ID))
+		var he *echo.HTTPError
+		if err := fn(c); !errors.As(err, &he) || he.Code != http.StatusNotFound {
+			t.Errorf("%s: err = %v; want 404", name, err)
+		}
+	}
+}
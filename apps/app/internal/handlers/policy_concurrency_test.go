@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestUpdate_OptimisticLocking verifies that a second edit based on the same
+// version is rejected with 409, and that a version is required at all.
+func TestUpdate_OptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	policy, _ := db.CreatePolicy(ctx, "Test Policy", "", nil, "organization", nil)
+
+	e := echo.New()
+	h := NewPolicy(db)
+
+	c, rec := testutil.NewContext(e, http.MethodPut, `{"title":"First"}`, policy.ID, mw.RoleSuperAdmin, nil)
+	c.Request().Header.Set("If-Match", `"1"`)
+	if err := h.Update(c); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("ETag = %s; want \"2\"", etag)
+	}
+
+	c, _ = testutil.NewContext(e, http.MethodPut, `{"title":"Second","expected_version":1}`, policy.ID, mw.RoleSuperAdmin, nil)
+	var he *echo.HTTPError
+	if err := h.Update(c); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Fatalf("stale update error = %v; want 409", err)
+	}
+
+	c, _ = testutil.NewContext(e, http.MethodPut, `{"title":"Third"}`, policy.ID, mw.RoleSuperAdmin, nil)
+	if err := h.Update(c); !errors.As(err, &he) || he.Code != http.StatusPreconditionRequired {
+		t.Fatalf("unversioned update error = %v; want 428", err)
+	}
+
+	got, _ := db.GetPolicy(ctx, policy.ID)
+	if got.Title != "First" || got.Version != 2 {
+		t.Errorf("policy = %q v%d; want \"First\" v2", got.Title, got.Version)
+	}
+}
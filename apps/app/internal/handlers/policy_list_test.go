@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestList_AcknowledgedFilter verifies ?acknowledged=false|true|overdue.
+func TestList_AcknowledgedFilter(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	u, _ := db.CreateUser(ctx, "staff@example.com", "Staff", mw.RoleStaff, nil, nil)
+
+	done, _ := db.CreatePolicy(ctx, "Done", "", nil, "organization", nil)
+	todo, _ := db.CreatePolicy(ctx, "Todo", "", nil, "organization", nil)
+	late, _ := db.CreatePolicy(ctx, "Late", "", nil, "organization", nil)
+	for _, p := range []*database.Policy{done, todo, late} {
+		testutil.Publish(t, db, p)
+	}
+	done, _ = db.GetPolicy(ctx, done.ID)
+	db.CreateAcknowledgement(ctx, u.ID, *done.CurrentVersionID)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	db.SetPolicyAckDeadline(ctx, late.ID, &yesterday)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	list := func(filter string) []string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/?acknowledged="+filter, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(mw.CtxUserID, u.ID)
+		c.Set(mw.CtxUserRole, mw.RoleStaff)
+		if err := h.List(c); err != nil {
+			t.Fatalf("List(%s): %v", filter, err)
+		}
+		var got []database.Policy
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		titles := make([]string, len(got))
+		for i, p := range got {
+			titles[i] = p.Title
+		}
+		return titles
+	}
+
+	if got := list("false"); len(got) != 2 || got[0] != "Late" || got[1] != "Todo" {
+		t.Errorf("acknowledged=false = %v; want [Late Todo]", got)
+	}
+	if got := list("overdue"); len(got) != 1 || got[0] != "Late" {
+		t.Errorf("acknowledged=overdue = %v; want [Late]", got)
+	}
+	if got := list("true"); len(got) != 1 || got[0] != "Done" {
+		t.Errorf("acknowledged=true = %v; want [Done]", got)
+	}
+}
+
+// TestList_AdminCoverage verifies admins get each policy's acknowledgement
+// coverage and staff do not.
+func TestList_AdminCoverage(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "admin@example.com", "Admin", mw.RoleSuperAdmin, nil, nil)
+	a, _ := db.CreateUser(ctx, "a@example.com", "A", mw.RoleStaff, nil, nil)
+	db.CreateUser(ctx, "b@example.com", "B", mw.RoleStaff, nil, nil)
+	db.CreateUser(ctx, "c@example.com", "C", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Handbook", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, a.ID, *p.CurrentVersionID)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	list := func(userID, role string) []map[string]any {
+		t.Helper()
+		c, rec := testutil.NewContext(e, http.MethodGet, "", "", role, nil)
+		c.Set(mw.CtxUserID, userID)
+		if err := h.List(c); err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var got []map[string]any
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		return got
+	}
+
+	got := list(admin.ID, mw.RoleSuperAdmin)
+	// Four active users, including the admin; one has acknowledged.
+	if len(got) != 1 || got[0]["ack_count"] != 1.0 || got[0]["eligible_user_count"] != 4.0 || got[0]["compliance_pct"] != 25.0 {
+		t.Errorf("admin list = %v; want 1 of 4 acknowledged (25%%)", got)
+	}
+	if got := list(a.ID, mw.RoleStaff); len(got) != 1 || got[0]["ack_count"] != nil {
+		t.Errorf("staff list = %v; want no coverage fields", got)
+	}
+}
+
+// TestList_FieldsAndInclude verifies ?fields= trims list items, that
+// ?include=current_version embeds the version without its content unless
+// asked for, and that unknown fields are rejected.
+func TestList_FieldsAndInclude(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	u, _ := db.CreateUser(ctx, "staff@example.com", "Staff", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Handbook", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, u.ID, *p.CurrentVersionID)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	list := func(query string) ([]map[string]any, error) {
+		t.Helper()
+		c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleStaff, nil)
+		c.Set(mw.CtxUserID, u.ID)
+		c.Request().URL.RawQuery = query
+		err := h.List(c)
+		var got []map[string]any
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		return got, err
+	}
+
+	got, err := list("fields=title,acknowledged&include=current_version,acknowledgement")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d policies; want 1", len(got))
+	}
+	item := got[0]
+	if len(item) != 5 || item["id"] != p.ID || item["title"] != "Handbook" || item["acknowledged"] != true {
+		t.Errorf("item = %v; want id, title, acknowledged, and the includes", item)
+	}
+	version, _ := item["current_version"].(map[string]any)
+	if version == nil || version["id"] != *p.CurrentVersionID || version["content"] != nil {
+		t.Errorf("current_version = %v; want the version without content", item["current_version"])
+	}
+	if ack, _ := item["acknowledgement"].(map[string]any); ack == nil || ack["user_id"] != u.ID {
+		t.Errorf("acknowledgement = %v; want the caller's", item["acknowledgement"])
+	}
+
+	got, _ = list("fields=current_version.content&include=current_version")
+	if version, _ := got[0]["current_version"].(map[string]any); version == nil || version["content"] == nil || len(version) != 2 {
+		t.Errorf("current_version = %v; want id and content only", got[0]["current_version"])
+	}
+
+	var he *echo.HTTPError
+	if _, err := list("fields=bogus"); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: err = %v; want 400", err)
+	}
+	if _, err := list("include=related"); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("unknown include: err = %v; want 400", err)
+	}
+}
+
+// TestList_ViewAs verifies a SuperAdmin can list what a role and department
+// see, and that nobody else can.
+func TestList_ViewAs(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	root, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	db.CreatePolicy(ctx, "Conduct", "", nil, "organization", nil)
+	db.CreatePolicy(ctx, "On-call", eng.Name, &eng.ID, "department", nil)
+	db.CreatePolicy(ctx, "Warehouse", ops.Name, &ops.ID, "department", nil)
+	leads, _ := db.CreatePolicy(ctx, "Hiring", "", nil, "department", nil)
+	db.CreatePolicyAssignment(ctx, leads.ID, database.AssignRole, mw.RoleDeptAdmin, nil)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	list := func(role, query string) ([]string, error) {
+		t.Helper()
+		c, rec := testutil.NewContext(e, http.MethodGet, "", "", role, nil)
+		c.Set(mw.CtxUserID, root.ID)
+		c.Request().URL.RawQuery = query
+		if err := h.List(c); err != nil {
+			return nil, err
+		}
+		var got []database.Policy
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		titles := map[string]bool{}
+		for _, p := range got {
+			titles[p.Title] = true
+		}
+		out := []string{}
+		for _, title := range []string{"Conduct", "On-call", "Warehouse", "Hiring"} {
+			if titles[title] {
+				out = append(out, title)
+			}
+		}
+		return out, nil
+	}
+
+	cases := map[string]string{
+		"as_role=Staff&as_department=" + eng.ID: "[Conduct On-call]",
+		"as_role=DeptAdmin":                     "[Conduct Hiring]",
+		"as_department=" + ops.ID:               "[Conduct Warehouse]",
+	}
+	for query, want := range cases {
+		got, err := list(mw.RoleSuperAdmin, query)
+		if err != nil {
+			t.Fatalf("List(%s): %v", query, err)
+		}
+		if s := fmt.Sprint(got); s != want {
+			t.Errorf("List(%s) = %s; want %s", query, s, want)
+		}
+	}
+
+	var he *echo.HTTPError
+	if _, err := list(mw.RoleSuperAdmin, "as_role=Intern"); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("unknown role: err = %v; want 400", err)
+	}
+	if _, err := list(mw.RoleDeptAdmin, "as_role=Staff"); !errors.As(err, &he) || he.Code != http.StatusForbidden {
+		t.Errorf("DeptAdmin preview: err = %v; want 403", err)
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestDeleteVersion_OnlyUnusedNonCurrent verifies that the current version and
+// acknowledged versions are protected while an unused draft can be removed.
+func TestDeleteVersion_OnlyUnusedNonCurrent(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	u, _ := db.CreateUser(ctx, "staff@example.com", "Staff", mw.RoleStaff, nil, nil)
+	policy, _ := db.CreatePolicy(ctx, "Test Policy", "", nil, "organization", nil)
+	acked, _ := db.CreatePolicyVersion(ctx, policy.ID, "a", "v1", "", nil)
+	unused, _ := db.CreatePolicyVersion(ctx, policy.ID, "b", "v2", "", nil)
+	current, _ := db.CreatePolicyVersion(ctx, policy.ID, "c", "v3", "", nil)
+	db.SetPolicyCurrentVersion(ctx, policy.ID, current.ID)
+	db.CreateAcknowledgement(ctx, u.ID, acked.ID)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	del := func(versionID string) error {
+		c, _ := testutil.NewContext(e, http.MethodDelete, "", policy.ID, mw.RoleSuperAdmin, nil)
+		c.SetParamNames("id", "versionId")
+		c.SetParamValues(policy.ID, versionID)
+		return h.DeleteVersion(c)
+	}
+
+	var he *echo.HTTPError
+	for _, v := range []*database.PolicyVersion{acked, current} {
+		if err := del(v.ID); !errors.As(err, &he) || he.Code != http.StatusConflict {
+			t.Errorf("delete %s error = %v; want 409", v.VersionString, err)
+		}
+	}
+	if err := del(unused.ID); err != nil {
+		t.Fatalf("delete unused: %v", err)
+	}
+	versions, _ := db.ListPolicyVersions(ctx, policy.ID)
+	if len(versions) != 2 {
+		t.Errorf("remaining versions = %d; want 2", len(versions))
+	}
+}
+
+// TestCreateVersion_EnforcesRetention verifies that publishing past the
+// retention limit prunes the oldest unpublished, unacknowledged version.
+func TestCreateVersion_EnforcesRetention(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	policy, _ := db.CreatePolicy(ctx, "Test Policy", "", nil, "organization", nil)
+	first, _ := db.CreatePolicyVersion(ctx, policy.ID, "a", "v1", "", nil)
+	db.SetPolicyCurrentVersion(ctx, policy.ID, first.ID)
+	db.SetSetting(ctx, database.SettingVersionRetention, "1", nil)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	c, _ := testutil.NewContext(e, http.MethodPost, `{"content":"b","version_string":"v2"}`, policy.ID, mw.RoleSuperAdmin, nil)
+	if err := h.CreateVersion(c); err != nil {
+		t.Fatalf("CreateVersion: %v", err)
+	}
+
+	versions, _ := db.ListPolicyVersions(ctx, policy.ID)
+	if len(versions) != 1 || versions[0].VersionString != "v2" {
+		t.Errorf("versions after retention = %d; want only v2", len(versions))
+	}
+}
+
+// TestAcknowledge_OnlyWithinEffectiveWindow verifies a version that takes
+// effect in the future is returned with its window but cannot be
+// acknowledged, and is left off the to-do list, until that date.
+func TestAcknowledge_OnlyWithinEffectiveWindow(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	staff, _ := db.CreateUser(ctx, "staff@example.com", "Staff", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Whistleblowing", "", nil, "organization", nil)
+	db.UpdatePolicy(ctx, p.ID, p.Title, "Published", p.Department, p.DepartmentID, p.VisibilityType)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	var he *echo.HTTPError
+	c, _ := testutil.NewContext(e, http.MethodPost, `{"content":"a","version_string":"v1","effective_from":"2030-01-01","effective_to":"2029-12-31"}`, p.ID, mw.RoleSuperAdmin, nil)
+	if err := h.CreateVersion(c); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("window ending before it starts: err = %v; want 400", err)
+	}
+	future := time.Now().AddDate(0, 1, 0).Format(time.DateOnly)
+	c, _ = testutil.NewContext(e, http.MethodPost, `{"content":"a","version_string":"v1","effective_from":"`+future+`"}`, p.ID, mw.RoleSuperAdmin, nil)
+	if err := h.CreateVersion(c); err != nil {
+		t.Fatalf("CreateVersion: %v", err)
+	}
+	versions, _ := db.ListPolicyVersions(ctx, p.ID)
+	if len(versions) != 1 || versions[0].EffectiveFrom == nil || versions[0].EffectiveFrom.Format(time.DateOnly) != future {
+		t.Fatalf("versions = %+v; want effective_from %s", versions, future)
+	}
+
+	c, _ = testutil.NewContext(e, http.MethodPost, "", p.ID, mw.RoleStaff, nil)
+	c.Set(mw.CtxUserID, staff.ID)
+	if err := h.Acknowledge(c); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("acknowledge before effective: err = %v; want 400", err)
+	}
+	if pending, _ := db.ListPendingPoliciesForUser(ctx, staff.ID, staff.Role, nil); len(pending) != 0 {
+		t.Errorf("pending = %d; want 0 before the version takes effect", len(pending))
+	}
+
+	past := time.Now().AddDate(0, -1, 0)
+	db.SetPolicyVersionEffective(ctx, versions[0].ID, &past, nil)
+	c, _ = testutil.NewContext(e, http.MethodPost, "", p.ID, mw.RoleStaff, nil)
+	c.Set(mw.CtxUserID, staff.ID)
+	if err := h.Acknowledge(c); err != nil {
+		t.Errorf("acknowledge once effective: %v", err)
+	}
+}
+
+// TestVersionStats_KeepComplianceOfSupersededVersions verifies a version's
+// compliance is frozen when a new version becomes current.
+func TestVersionStats_KeepComplianceOfSupersededVersions(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", mw.RoleStaff, nil, nil)
+	db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Remote Work", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, ada.ID, *p.CurrentVersionID)
+	v2, _ := db.CreatePolicyVersion(ctx, p.ID, "# Body v2", "v2.0.0", "rewrite", nil)
+	if err := db.SetPolicyCurrentVersion(ctx, p.ID, v2.ID); err != nil {
+		t.Fatalf("set current: %v", err)
+	}
+
+	c, rec := testutil.NewContext(echo.New(), http.MethodGet, "", p.ID, mw.RoleSuperAdmin, nil)
+	if err := NewPolicy(db).VersionStats(c); err != nil {
+		t.Fatalf("VersionStats: %v", err)
+	}
+	var stats []database.VersionAckStats
+	json.Unmarshal(rec.Body.Bytes(), &stats)
+	if len(stats) != 2 {
+		t.Fatalf("got %d versions; want 2", len(stats))
+	}
+	cur, old := stats[0], stats[1]
+	if !cur.Current || cur.AckCount != 0 || cur.EligibleUserCount == nil || *cur.EligibleUserCount != 2 || *cur.CompliancePct != 0 {
+		t.Errorf("current = %+v; want 0 of 2", cur)
+	}
+	if old.Current || old.SupersededAt == nil || old.AckCount != 1 || *old.EligibleUserCount != 2 || *old.CompliancePct != 50 {
+		t.Errorf("superseded = %+v; want 1 of 2 frozen", old)
+	}
+	if len(old.ByMonth) != 1 || old.ByMonth[0].Acknowledgements != 1 {
+		t.Errorf("by month = %+v", old.ByMonth)
+	}
+}
+
+// TestCreateVersion_QueuesPublishNotifications verifies a new version of a
+// published policy queues one email per user who can see it, and that an
+// email becomes unnecessary once the user acknowledges the version.
+func TestCreateVersion_QueuesPublishNotifications(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", mw.RoleStaff, nil, &eng.ID)
+	db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleStaff, nil, &eng.ID)
+	db.CreateUser(ctx, "carol@example.com", "Carol", mw.RoleStaff, nil, &ops.ID)
+	p, _ := db.CreatePolicy(ctx, "On-call", eng.Name, &eng.ID, "department", nil)
+	testutil.Publish(t, db, p)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	newVersion := func(v string) {
+		t.Helper()
+		c, _ := testutil.NewContext(e, http.MethodPost, `{"content":"b","version_string":"`+v+`","changelog":"Shorter shifts"}`, p.ID, mw.RoleSuperAdmin, nil)
+		if err := h.CreateVersion(c); err != nil {
+			t.Fatalf("CreateVersion %s: %v", v, err)
+		}
+	}
+	newVersion("v2")
+	queued, _ := db.ListUnsentPublishNotifications(ctx, 10)
+	if len(queued) != 2 || queued[0].Changelog != "Shorter shifts" {
+		t.Fatalf("queued = %d; want Ada and Bob with the changelog", len(queued))
+	}
+
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, ada.ID, *p.CurrentVersionID)
+	queued, _ = db.ListUnsentPublishNotifications(ctx, 10)
+	for _, n := range queued {
+		if n.Stale != (n.UserID == ada.ID) {
+			t.Errorf("%s stale = %v; want only Ada's email dropped", n.UserName, n.Stale)
+		}
+	}
+
+	db.SetSetting(ctx, database.SettingPublishNotifications, "false", nil)
+	newVersion("v3")
+	if queued, _ = db.ListUnsentPublishNotifications(ctx, 10); len(queued) != 2 {
+		t.Errorf("queued with notifications off = %d; want none added", len(queued))
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// Print returns a standalone HTML document of a policy version for printing
+// or archival. The footer carries the SHA-256 of the version content so a
+// paper copy can be matched to the exact text, plus blank signature lines.
+// GET /api/policies/:id/print?version_id=...
+func (h *Policy) Print(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+
+	versionID := policy.CurrentVersionID
+	if v := c.QueryParam("version_id"); v != "" {
+		versionID = &v
+	}
+	if versionID == nil {
+		return apierr.New(http.StatusNotFound, "POLICY_NO_CURRENT_VERSION", "policy has no content yet")
+	}
+	version, err := h.db.GetPolicyVersion(ctx, *versionID)
+	if err != nil || version.PolicyID != policy.ID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "VERSION_NOT_FOUND", "version not found")
+		}
+		return apierr.Database()
+	}
+
+	var printedBy *database.User
+	if u, err := h.db.GetUserByID(ctx, c.Get(mw.CtxUserID).(string)); err == nil {
+		printedBy = u
+	}
+	sum := sha256.Sum256([]byte(version.Content))
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return renderPage(c, "print_policy.html", map[string]any{
+		"Policy":      policy,
+		"Version":     version,
+		"Current":     policy.CurrentVersionID != nil && *policy.CurrentVersionID == version.ID,
+		"ContentHash": hex.EncodeToString(sum[:]),
+		"PrintedAt":   time.Now().UTC(),
+		"PrintedBy":   printedBy,
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestPrint_RendersVersionWithContentHash verifies the print view renders the
+// current version with its metadata and a hash of the exact content.
+func TestPrint_RendersVersionWithContentHash(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	p, _ := db.CreatePolicy(ctx, "On-call", "", testutil.Ptr(eng.ID), "department", nil)
+	testutil.Publish(t, db, p)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	c, rec := testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleStaff, testutil.Ptr(eng.ID))
+	if err := h.Print(c); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+	body := rec.Body.String()
+	sum := sha256.Sum256([]byte("# Body"))
+	for _, want := range []string{"<h1>On-call</h1>", "v1.0.0", "Engineering", "<h1>Body</h1>", hex.EncodeToString(sum[:])} {
+		if !strings.Contains(body, want) {
+			t.Errorf("print view missing %q", want)
+		}
+	}
+
+	// Staff outside the department cannot print it.
+	c, _ = testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleStaff, testutil.Ptr("other"))
+	if err := h.Print(c); err == nil {
+		t.Error("Print outside department: want error")
+	}
+}
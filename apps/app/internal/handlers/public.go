@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/render"
+)
+
+// Public serves the unauthenticated, server-rendered policy portal. It is
+// only available while the public_portal setting is enabled.
+type Public struct {
+	db *database.DB
+}
+
+func NewPublic(db *database.DB) *Public {
+	return &Public{db: db}
+}
+
+// Index lists the policies published to the portal.
+// GET /public/policies
+func (h *Public) Index(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.requireEnabled(c); err != nil {
+		return err
+	}
+	policies, err := h.db.ListPublicPolicies(ctx)
+	if err != nil {
+		return apierr.Database()
+	}
+	return renderPage(c, "public_index.html", policies)
+}
+
+// Policy renders the current version of one public policy.
+// GET /public/policies/:id
+func (h *Public) Policy(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.requireEnabled(c); err != nil {
+		return err
+	}
+	policies, err := h.db.ListPublicPolicies(ctx)
+	if err != nil {
+		return apierr.Database()
+	}
+	for _, p := range policies {
+		if p.ID != c.Param("id") {
+			continue
+		}
+		v, err := h.db.GetPolicyVersion(ctx, *p.CurrentVersionID)
+		if err != nil {
+			return apierr.Database()
+		}
+		return renderPage(c, "public_policy.html", map[string]any{"Policy": p, "Version": v})
+	}
+	return apierr.New(http.StatusNotFound, "POLICY_NOT_FOUND", "policy not found")
+}
+
+func (h *Public) requireEnabled(c echo.Context) error {
+	enabled, err := h.db.GetBoolSetting(c.Request().Context(), database.SettingPublicPortal, false)
+	if err != nil {
+		return apierr.Database()
+	}
+	if !enabled {
+		return apierr.New(http.StatusNotFound, "NOT_FOUND", "not found")
+	}
+	return nil
+}
+
+// renderPage executes a server-side template and sends it as HTML.
+func renderPage(c echo.Context, name string, data any) error {
+	var buf bytes.Buffer
+	if err := render.Page(&buf, name, data); err != nil {
+		return apierr.New(http.StatusInternalServerError, "RENDER_FAILED", "render error")
+	}
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
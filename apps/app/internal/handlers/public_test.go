@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/testutil"
+)
+
+// TestPublicPortal verifies the portal is hidden until enabled and only
+// renders policies that were explicitly made public.
+func TestPublicPortal(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	open, _ := db.CreatePolicy(ctx, "Supplier Code", "", nil, "organization", nil)
+	internal, _ := db.CreatePolicy(ctx, "Payroll Handbook", "", nil, "organization", nil)
+	testutil.Publish(t, db, open)
+	testutil.Publish(t, db, internal)
+	if err := db.SetPolicyPublic(ctx, open.ID, true); err != nil {
+		t.Fatalf("set public: %v", err)
+	}
+
+	e := echo.New()
+	h := NewPublic(db)
+	get := func(id string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if id == "" {
+			return rec, h.Index(c)
+		}
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return rec, h.Policy(c)
+	}
+	status := func(err error) int {
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			return he.Code
+		}
+		return http.StatusOK
+	}
+
+	if _, err := get(""); status(err) != http.StatusNotFound {
+		t.Fatalf("disabled portal: got %v; want 404", err)
+	}
+	if err := db.SetSetting(ctx, database.SettingPublicPortal, "true", nil); err != nil {
+		t.Fatalf("enable portal: %v", err)
+	}
+
+	rec, err := get("")
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "Supplier Code") || strings.Contains(body, "Payroll Handbook") {
+		t.Errorf("index body does not list only the public policy:\n%s", body)
+	}
+	if rec, err = get(open.ID); err != nil || !strings.Contains(rec.Body.String(), "<h1>Body</h1>") {
+		t.Errorf("public policy: err=%v body=%s", err, rec.Body.String())
+	}
+	if _, err := get(internal.ID); status(err) != http.StatusNotFound {
+		t.Errorf("non-public policy: got %v; want 404", err)
+	}
+}
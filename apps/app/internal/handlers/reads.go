@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// RecordRead records a client-reported reading event (open or scroll_complete)
+// against the policy's current version and returns the updated read status.
+// POST /api/policies/:id/read-events
+func (h *Policy) RecordRead(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	if policy.CurrentVersionID == nil {
+		return apierr.New(http.StatusBadRequest, "POLICY_NO_CURRENT_VERSION", "policy has no current version")
+	}
+
+	var body struct {
+		Event string `json:"event"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if body.Event != database.ReadEventOpen && body.Event != database.ReadEventScrollComplete {
+		return apierr.Invalid("event must be open or scroll_complete", "event")
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	versionID := *policy.CurrentVersionID
+	status, err := h.db.GetReadStatus(ctx, userID, versionID)
+	if err != nil {
+		return apierr.Database()
+	}
+	// A scroll signal only counts if the document was actually opened first.
+	if body.Event == database.ReadEventScrollComplete && status.FirstOpenedAt == nil {
+		return apierr.New(http.StatusBadRequest, "READ_REQUIREMENT_NOT_MET", "policy must be opened before scrolling is reported")
+	}
+
+	if err := h.db.RecordReadEvent(ctx, userID, versionID, body.Event); err != nil {
+		return apierr.Database()
+	}
+	status, err = h.db.GetReadStatus(ctx, userID, versionID)
+	if err != nil {
+		return apierr.Database()
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"read_status":      status,
+		"min_read_seconds": int(h.minReadTime / time.Second),
+		"require_scroll":   h.requireScroll,
+		"can_acknowledge":  h.checkReadRequirements(ctx, userID, versionID) == nil,
+	})
+}
+
+// checkReadRequirements returns an HTTP error if the user has not yet met the
+// configured reading requirements for a version. All timing uses server-side
+// event timestamps so clients cannot shortcut the wait.
+func (h *Policy) checkReadRequirements(ctx context.Context, userID, versionID string) error {
+	if h.minReadTime <= 0 && !h.requireScroll {
+		return nil
+	}
+	status, err := h.db.GetReadStatus(ctx, userID, versionID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if status.FirstOpenedAt == nil {
+		return apierr.New(http.StatusBadRequest, "READ_REQUIREMENT_NOT_MET", "policy must be opened before acknowledging")
+	}
+	if elapsed := time.Since(*status.FirstOpenedAt); elapsed < h.minReadTime {
+		remaining := (h.minReadTime - elapsed).Round(time.Second)
+		return apierr.New(http.StatusBadRequest, "READ_REQUIREMENT_NOT_MET",
+			fmt.Sprintf("minimum reading time not reached; try again in %s", remaining))
+	}
+	if h.requireScroll && status.ScrollCompletedAt == nil {
+		return apierr.New(http.StatusBadRequest, "READ_REQUIREMENT_NOT_MET", "policy must be read to the end before acknowledging")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestReadRequirements_OpenWaitAndScroll verifies that acknowledging needs
+// the policy opened, the minimum reading time passed since the first open,
+// and the end reached, and that a scroll is only counted after an open.
+func TestReadRequirements_OpenWaitAndScroll(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	u, _ := db.CreateUser(ctx, "rae@example.com", "Rae", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Travel", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	versionID := *p.CurrentVersionID
+
+	e := echo.New()
+	h := NewPolicy(db)
+	h.minReadTime = 50 * time.Millisecond
+	h.requireScroll = true
+	report := func(event string) (bool, error) {
+		c, rec := testutil.NewContext(e, http.MethodPost, `{"event":"`+event+`"}`, p.ID, mw.RoleStaff, nil)
+		c.Set(mw.CtxUserID, u.ID)
+		if err := h.RecordRead(c); err != nil {
+			return false, err
+		}
+		var out struct {
+			CanAcknowledge bool `json:"can_acknowledge"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &out)
+		return out.CanAcknowledge, nil
+	}
+	unmet := func(err error) bool {
+		var he *echo.HTTPError
+		return errors.As(err, &he) && he.Code == http.StatusBadRequest
+	}
+
+	if err := h.checkReadRequirements(ctx, u.ID, versionID); !unmet(err) {
+		t.Errorf("never opened: err = %v; want 400", err)
+	}
+	if _, err := report("scroll_complete"); !unmet(err) {
+		t.Errorf("scroll before open: err = %v; want 400", err)
+	}
+	if ok, err := report("open"); err != nil || ok {
+		t.Errorf("just opened: can_acknowledge %v, err %v; want false", ok, err)
+	}
+	if err := h.checkReadRequirements(ctx, u.ID, versionID); !unmet(err) {
+		t.Errorf("before the minimum reading time: err = %v; want 400", err)
+	}
+
+	time.Sleep(h.minReadTime)
+	if err := h.checkReadRequirements(ctx, u.ID, versionID); !unmet(err) {
+		t.Errorf("not scrolled to the end: err = %v; want 400", err)
+	}
+	if ok, err := report("scroll_complete"); err != nil || !ok {
+		t.Errorf("scrolled after the wait: can_acknowledge %v, err %v; want true", ok, err)
+	}
+
+	h.minReadTime, h.requireScroll = 0, false
+	if err := h.checkReadRequirements(ctx, "someone-else", versionID); err != nil {
+		t.Errorf("no requirements configured: err = %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+)
+
+// Receipts emails users a record of each acknowledgement, when the
+// organization turns ack_receipts on, and serves the certificates those
+// emails link to.
+type Receipts struct {
+	db      *database.DB
+	mailer  *email.Mailer
+	secret  []byte
+	baseURL string
+}
+
+func NewReceipts(db *database.DB, mailer *email.Mailer, secret string) *Receipts {
+	return &Receipts{db: db, mailer: mailer, secret: []byte(secret), baseURL: baseURLFromEnv()}
+}
+
+// SetReceipts makes the policy handler email a receipt after each
+// acknowledgement.
+func (h *Policy) SetReceipts(r *Receipts) {
+	h.receipts = r
+}
+
+// Send emails the receipt for acknowledgement ackID to the user who gave
+// it. It does nothing while receipts are turned off.
+func (h *Receipts) Send(ctx context.Context, ackID string) error {
+	on, err := h.db.GetBoolSetting(ctx, database.SettingAckReceipts, false)
+	if err != nil || !on {
+		return err
+	}
+	r, err := h.db.GetAckReceipt(ctx, ackID)
+	if err != nil {
+		return err
+	}
+	return h.mailer.SendAckReceipt(r.UserEmail, r.UserName, r.PolicyTitle, r.VersionString, r.Timestamp,
+		r.SignatureHash, h.CertificateURL(r.ID))
+}
+
+// CertificateURL is the signed link to the certificate of acknowledgement
+// ackID. It does not expire: the receipt is the user's record.
+func (h *Receipts) CertificateURL(ackID string) string {
+	return h.baseURL + "/certificates/" + ackID + "." + h.mac(ackID)
+}
+
+// Certificate renders a certificate of acknowledgement for anyone holding
+// its link. Forged links and deleted acknowledgements are indistinguishable.
+// GET /certificates/:token
+func (h *Receipts) Certificate(c echo.Context) error {
+	notFound := apierr.New(http.StatusNotFound, "CERTIFICATE_NOT_FOUND", "certificate not found")
+	id, sig, ok := strings.Cut(c.Param("token"), ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(h.mac(id))) {
+		return notFound
+	}
+	r, err := h.db.GetAckReceipt(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFound
+		}
+		return apierr.Database()
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("X-Robots-Tag", "noindex")
+	return renderPage(c, "ack_certificate.html", map[string]any{
+		"Receipt":  r,
+		"Imported": r.Source == database.AckSourceImported,
+	})
+}
+
+func (h *Receipts) mac(ackID string) string {
+	m := hmac.New(sha256.New, h.secret)
+	m.Write([]byte("ack-certificate:" + ackID))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestReceipts_SendAndCertificate checks receipts are only sent once turned
+// on, and that the certificate link in one renders while a tampered link
+// does not.
+func TestReceipts_SendAndCertificate(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ana, _ := db.CreateUser(ctx, "ana@example.com", "Ana", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Remote Work", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	versions, _ := db.ListPolicyVersions(ctx, p.ID)
+	ack, err := db.CreateAcknowledgement(ctx, ana.ID, versions[0].ID)
+	if err != nil {
+		t.Fatalf("CreateAcknowledgement: %v", err)
+	}
+	mailer := email.New()
+	outbox := mailer.Capture()
+	h := NewReceipts(db, mailer, testutil.JWTSecret)
+
+	if err := h.Send(ctx, ack.ID); err != nil {
+		t.Fatalf("Send (off): %v", err)
+	}
+	if n := len(outbox.Messages()); n != 0 {
+		t.Fatalf("receipts off: sent %d emails", n)
+	}
+	if err := db.SetSetting(ctx, database.SettingAckReceipts, "true", nil); err != nil {
+		t.Fatalf("SetSetting: %v", err)
+	}
+	if err := h.Send(ctx, ack.ID); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	msg, ok := outbox.Last("ana@example.com")
+	if !ok {
+		t.Fatal("no receipt sent")
+	}
+	url := h.CertificateURL(ack.ID)
+	for _, want := range []string{"Remote Work", "v1.0.0", ack.SignatureHash, url} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("receipt missing %q:\n%s", want, msg.Body)
+		}
+	}
+
+	view := func(token string) (string, error) {
+		c, rec := testutil.NewContext(echo.New(), http.MethodGet, "", "", "", nil)
+		c.SetParamNames("token")
+		c.SetParamValues(token)
+		err := h.Certificate(c)
+		return rec.Body.String(), err
+	}
+	token := url[strings.LastIndex(url, "/")+1:]
+	body, err := view(token)
+	if err != nil {
+		t.Fatalf("Certificate: %v", err)
+	}
+	for _, want := range []string{"Ana", "Remote Work", ack.SignatureHash} {
+		if !strings.Contains(body, want) {
+			t.Errorf("certificate missing %q", want)
+		}
+	}
+	if _, err := view(token + "x"); err == nil {
+		t.Error("tampered certificate link: want error")
+	}
+}
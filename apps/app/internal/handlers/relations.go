@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// visibleRelated lists the policies related to policyID that the caller can
+// see; links to policies hidden from them are left out.
+func (h *Policy) visibleRelated(c echo.Context, policyID string) ([]*database.RelatedPolicy, error) {
+	related, err := h.db.ListRelatedPolicies(c.Request().Context(), policyID)
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	out := make([]*database.RelatedPolicy, 0, len(related))
+	for _, r := range related {
+		visible, err := h.canView(c, r.Policy)
+		if err != nil {
+			return nil, apierr.Database()
+		}
+		if visible {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// CreateRelation links the policy to another one, either "supersedes" (this
+// policy replaces the other) or "relates_to". DeptAdmin may link their own
+// department's policies to any policy they can see.
+// POST /api/policies/:id/relations
+func (h *Policy) CreateRelation(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+
+	var body struct {
+		RelatedPolicyID string `json:"related_policy_id"`
+		Type            string `json:"type"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if body.Type != database.RelationSupersedes && body.Type != database.RelationRelatesTo {
+		return apierr.Invalid("type must be supersedes or relates_to", "type")
+	}
+	if body.RelatedPolicyID == policy.ID {
+		return apierr.New(http.StatusBadRequest, "SELF_RELATION", "a policy cannot be related to itself")
+	}
+	target, err := h.db.GetPolicy(ctx, body.RelatedPolicyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusBadRequest, "RELATED_POLICY_NOT_FOUND", "related policy not found")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	if visible, err := h.canView(c, target); err != nil {
+		return apierr.Database()
+	} else if !visible {
+		return apierr.New(http.StatusBadRequest, "RELATED_POLICY_NOT_FOUND", "related policy not found")
+	}
+
+	if body.Type == database.RelationSupersedes {
+		existing, err := h.db.ListRelatedPolicies(ctx, policy.ID)
+		if err != nil {
+			return apierr.Database()
+		}
+		for _, r := range existing {
+			if r.ID == target.ID && r.Relation == database.RelationSupersededBy {
+				return apierr.New(http.StatusConflict, "RELATION_CYCLE", "related policy already supersedes this one")
+			}
+		}
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	rel, err := h.db.CreatePolicyRelation(ctx, policy.ID, target.ID, body.Type, &userID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusCreated, rel)
+}
+
+// DeleteRelation removes a link between the policy and another one.
+// DELETE /api/policies/:id/relations/:relationId
+func (h *Policy) DeleteRelation(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	rel, err := h.db.GetPolicyRelation(ctx, c.Param("relationId"))
+	if err != nil || (rel.PolicyID != policy.ID && rel.RelatedPolicyID != policy.ID) {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "RELATION_NOT_FOUND", "relation not found")
+		}
+		return apierr.Database()
+	}
+	if err := h.db.DeletePolicyRelation(ctx, rel.ID); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
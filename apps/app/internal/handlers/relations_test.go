@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestRelations_BothDirectionsAndVisibility verifies that a supersedes link
+// shows up on both policies with the right direction, that links to policies
+// the reader cannot see are hidden, and that a reverse supersedes is refused.
+func TestRelations_BothDirectionsAndVisibility(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	conduct, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	old, _ := db.CreatePolicy(ctx, "Old Conduct Rules", "", nil, "organization", nil)
+	disciplinary, _ := db.CreatePolicy(ctx, "Disciplinary Policy", "", testutil.Ptr(hr.ID), "department", nil)
+	admin, _ := db.CreateUser(ctx, "admin@example.com", "Admin", mw.RoleSuperAdmin, nil, nil)
+
+	e := echo.New()
+	h := NewPolicy(db)
+	relate := func(from, to, typ string) error {
+		c, _ := testutil.NewContext(e, http.MethodPost, `{"related_policy_id":"`+to+`","type":"`+typ+`"}`, from, mw.RoleSuperAdmin, nil)
+		c.Set(mw.CtxUserID, admin.ID)
+		return h.CreateRelation(c)
+	}
+	if err := relate(conduct.ID, old.ID, database.RelationSupersedes); err != nil {
+		t.Fatalf("supersedes: %v", err)
+	}
+	if err := relate(conduct.ID, disciplinary.ID, database.RelationRelatesTo); err != nil {
+		t.Fatalf("relates_to: %v", err)
+	}
+	var he *echo.HTTPError
+	if err := relate(old.ID, conduct.ID, database.RelationSupersedes); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Errorf("reverse supersedes: got %v; want 409", err)
+	}
+
+	related := func(policyID, role string, deptID *string) map[string]string {
+		t.Helper()
+		c, rec := testutil.NewContext(e, http.MethodGet, "", policyID, role, deptID)
+		if err := h.Get(c); err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		var body struct {
+			Related []database.RelatedPolicy `json:"related"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		out := map[string]string{}
+		for _, r := range body.Related {
+			out[r.Title] = r.Relation
+		}
+		return out
+	}
+
+	got := related(conduct.ID, mw.RoleStaff, testutil.Ptr(hr.ID))
+	if len(got) != 2 || got["Old Conduct Rules"] != "supersedes" || got["Disciplinary Policy"] != "relates_to" {
+		t.Errorf("HR staff sees %v", got)
+	}
+	got = related(conduct.ID, mw.RoleStaff, testutil.Ptr(eng.ID))
+	if len(got) != 1 || got["Old Conduct Rules"] != "supersedes" {
+		t.Errorf("Engineering staff sees %v; want only the superseded policy", got)
+	}
+	got = related(old.ID, mw.RoleStaff, testutil.Ptr(eng.ID))
+	if got["Code of Conduct"] != "superseded_by" {
+		t.Errorf("old policy related = %v; want superseded_by Code of Conduct", got)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/reports"
+)
+
+// maxReportRecipients caps how many addresses one schedule can email.
+const maxReportRecipients = 50
+
+type reportScheduleRequest struct {
+	Name       string                    `json:"name"`
+	Definition database.ReportDefinition `json:"definition"`
+	Frequency  string                    `json:"frequency"`
+	Format     string                    `json:"format"`
+	Recipients []string                  `json:"recipients"`
+}
+
+// ListSchedules returns the scheduled reports the caller can manage.
+// GET /api/admin/reports/schedules
+func (h *Reports) ListSchedules(c echo.Context) error {
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+	schedules, err := h.db.ListReportSchedules(c.Request().Context(), deptID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if schedules == nil {
+		schedules = []*database.ReportSchedule{}
+	}
+	return c.JSON(http.StatusOK, schedules)
+}
+
+// CreateSchedule saves a report definition to be emailed weekly or monthly.
+// DeptAdmin schedules are limited to their own department. Recipients must
+// be active users or in a domain listed in REPORT_RECIPIENT_DOMAINS.
+// POST /api/admin/reports/schedules
+func (h *Reports) CreateSchedule(c echo.Context) error {
+	var req reportScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return apierr.Invalid("name is required", "name")
+	}
+	if req.Frequency != reports.Weekly && req.Frequency != reports.Monthly {
+		return apierr.Invalid("frequency must be weekly or monthly", "frequency")
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+	if req.Format != "csv" && req.Format != "pdf" {
+		return apierr.Invalid("format must be csv or pdf", "format")
+	}
+	if len(req.Recipients) == 0 || len(req.Recipients) > maxReportRecipients {
+		return apierr.Invalid("between 1 and 50 recipients are required", "recipients")
+	}
+	for i, r := range req.Recipients {
+		addr, err := mail.ParseAddress(r)
+		if err != nil {
+			return apierr.Invalid("invalid recipient: "+r, "recipients")
+		}
+		ok, err := h.runner.RecipientAllowed(c.Request().Context(), addr.Address)
+		if err != nil {
+			return apierr.Database()
+		}
+		if !ok {
+			return apierr.Invalid("recipient is not an active user or in an allowed domain: "+addr.Address, "recipients")
+		}
+		req.Recipients[i] = addr.Address
+	}
+	if err := database.ValidateReport(req.Definition); err != nil {
+		return apierr.New(http.StatusBadRequest, "INVALID_REPORT", err.Error())
+	}
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+
+	userID, _ := c.Get(mw.CtxUserID).(string)
+	s := &database.ReportSchedule{
+		Name:         req.Name,
+		Definition:   req.Definition,
+		Frequency:    req.Frequency,
+		Format:       req.Format,
+		Recipients:   req.Recipients,
+		DepartmentID: deptID,
+		CreatedBy:    &userID,
+		NextRunAt:    reports.NextRun(req.Frequency, time.Now()),
+	}
+	if err := h.db.CreateReportSchedule(c.Request().Context(), s); err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusCreated, s)
+}
+
+// DeleteSchedule removes a scheduled report.
+// DELETE /api/admin/reports/schedules/:id
+func (h *Reports) DeleteSchedule(c echo.Context) error {
+	s, err := h.loadSchedule(c)
+	if err != nil {
+		return err
+	}
+	if err := h.db.DeleteReportSchedule(c.Request().Context(), s.ID); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SendSchedule delivers a scheduled report immediately, without changing
+// when it next runs. Recipients no longer allowed are skipped.
+// POST /api/admin/reports/schedules/:id/send
+func (h *Reports) SendSchedule(c echo.Context) error {
+	s, err := h.loadSchedule(c)
+	if err != nil {
+		return err
+	}
+	sent, err := h.runner.Deliver(c.Request().Context(), s, time.Now())
+	if errors.Is(err, reports.ErrNoRecipients) {
+		return apierr.New(http.StatusConflict, "NO_RECIPIENTS", err.Error())
+	}
+	if err != nil {
+		return apierr.New(http.StatusBadGateway, "EMAIL_ERROR", "failed to send report")
+	}
+	return c.JSON(http.StatusOK, map[string]int{"sent": sent})
+}
+
+// loadSchedule fetches the :id schedule, hiding other departments'
+// schedules from a DeptAdmin.
+func (h *Reports) loadSchedule(c echo.Context) (*database.ReportSchedule, error) {
+	s, err := h.db.GetReportSchedule(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apierr.New(http.StatusNotFound, "SCHEDULE_NOT_FOUND", "schedule not found")
+	}
+	if err != nil {
+		return nil, apierr.Database()
+	}
+	deptID, err := reportScope(c)
+	if err != nil {
+		return nil, err
+	}
+	if deptID != nil && (s.DepartmentID == nil || *s.DepartmentID != *deptID) {
+		return nil, apierr.New(http.StatusNotFound, "SCHEDULE_NOT_FOUND", "schedule not found")
+	}
+	return s, nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/reports"
+)
+
+// Reports runs ad-hoc report definitions and manages scheduled deliveries.
+type Reports struct {
+	db     *database.DB
+	runner *reports.Runner
+}
+
+func NewReports(db *database.DB, runner *reports.Runner) *Reports {
+	return &Reports{db: db, runner: runner}
+}
+
+// Fields lists the entities a report can query and their fields.
+// GET /api/admin/reports/fields
+func (h *Reports) Fields(c echo.Context) error {
+	return c.JSON(http.StatusOK, database.ReportFields())
+}
+
+// Run executes a report definition and returns its rows as JSON or, with
+// ?format=csv, as a CSV download. DeptAdmin reports only cover their own
+// department.
+// POST /api/admin/reports?format=json|csv
+func (h *Reports) Run(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return apierr.Invalid("format must be json or csv", "format")
+	}
+	var def database.ReportDefinition
+	if err := c.Bind(&def); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+
+	// Rows are streamed as they are read, so the response is only started
+	// once the query runs: errors before then are still reported normally.
+	started := false
+	var write func(row []string) error
+	var done func() error
+	start := func(columns []string) error {
+		started = true
+		if format == "csv" {
+			out, err := streamCSV(c, def.Entity+"-report.csv", columns)
+			write, done = out.Write, out.Close
+			return err
+		}
+		head, err := json.Marshal(columns)
+		if err != nil {
+			return err
+		}
+		out, err := streamJSON(c, `{"columns":`+string(head)+`,"rows":[`, "]}")
+		write = func(row []string) error { return out.Write(row) }
+		done = out.Close
+		return err
+	}
+	err = h.db.Reader().StreamReport(c.Request().Context(), def, deptID, start, func(row []string) error { return write(row) })
+	var re *database.ReportError
+	switch {
+	case errors.As(err, &re):
+		return apierr.New(http.StatusBadRequest, "INVALID_REPORT", re.Error())
+	case err != nil && !started:
+		return apierr.Database()
+	case err != nil:
+		return err
+	}
+	return done()
+}
+
+// reportScope returns the department a DeptAdmin's reports are limited to,
+// or nil for SuperAdmin.
+func reportScope(c echo.Context) (*string, error) {
+	if c.Get(mw.CtxUserRole) != mw.RoleDeptAdmin {
+		return nil, nil
+	}
+	deptID, _ := c.Get(mw.CtxDeptID).(*string)
+	if deptID == nil {
+		return nil, apierr.New(http.StatusForbidden, "DEPARTMENT_REQUIRED", "department admin must belong to a department")
+	}
+	return deptID, nil
+}
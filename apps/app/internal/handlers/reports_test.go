@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestReports_GroupedAndScoped verifies a grouped acknowledgement report,
+// that DeptAdmin results are limited to their department, and that fields
+// outside the whitelist are rejected.
+func TestReports_GroupedAndScoped(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	p, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	for i, dept := range []string{eng.ID, eng.ID, hr.ID} {
+		u, _ := db.CreateUser(ctx, string(rune('a'+i))+"@example.com", "U", mw.RoleStaff, nil, testutil.Ptr(dept))
+		if _, err := db.CreateAcknowledgement(ctx, u.ID, *p.CurrentVersionID); err != nil {
+			t.Fatalf("ack: %v", err)
+		}
+	}
+
+	e := echo.New()
+	h := NewReports(db, nil)
+	run := func(body, role string, deptID *string) (*database.ReportResult, error) {
+		c, rec := testutil.NewContext(e, http.MethodPost, body, "", role, deptID)
+		if err := h.Run(c); err != nil {
+			return nil, err
+		}
+		var res database.ReportResult
+		json.Unmarshal(rec.Body.Bytes(), &res)
+		return &res, nil
+	}
+
+	grouped := `{"entity":"acknowledgements","group_by":["department"],"date_range":{"from":"2000-01-01"}}`
+	res, err := run(grouped, mw.RoleSuperAdmin, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got := map[string]string{}
+	for _, r := range res.Rows {
+		got[r[0]] = r[1]
+	}
+	if strings.Join(res.Columns, ",") != "department,count" || got["Engineering"] != "2" || got["HR"] != "1" {
+		t.Errorf("grouped report = %+v", res)
+	}
+
+	res, err = run(grouped, mw.RoleDeptAdmin, testutil.Ptr(hr.ID))
+	if err != nil || len(res.Rows) != 1 || res.Rows[0][0] != "HR" {
+		t.Errorf("dept admin report = %+v, %v; want HR only", res, err)
+	}
+
+	_, err = run(`{"entity":"users","filters":[{"field":"password","op":"eq","value":"x"}]}`, mw.RoleSuperAdmin, nil)
+	var he *echo.HTTPError
+	if !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: err = %v; want 400", err)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/scan"
+	"policyflow/internal/storage"
+)
+
+// SetScanner makes uploads go through a virus scanner before they are
+// stored; nil turns scanning off.
+func (h *Policy) SetScanner(s scan.Scanner) {
+	h.scanner = s
+}
+
+// scanUpload checks an uploaded file with the virus scanner, if one is
+// configured, and records the result. A flagged file is moved to quarantine
+// and refused with 422 FILE_INFECTED. If the scanner cannot be reached the
+// upload is refused rather than let through. r is rewound for the caller.
+func (h *Policy) scanUpload(c echo.Context, kind string, policyID *string, filename string, r io.ReadSeeker) error {
+	if h.scanner == nil {
+		return nil
+	}
+	ctx := c.Request().Context()
+	res, err := h.scanner.Scan(ctx, r)
+	if err != nil {
+		log.Printf("scan %s: %v", filename, err)
+		return apierr.New(http.StatusServiceUnavailable, "SCAN_UNAVAILABLE", "uploads cannot be checked for viruses right now; try again later")
+	}
+	hash := sha256.New()
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return apierr.New(http.StatusBadRequest, "INVALID_UPLOAD", "invalid upload")
+	}
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return apierr.New(http.StatusBadRequest, "INVALID_UPLOAD", "invalid upload")
+	}
+	uploader := c.Get(mw.CtxUserID).(string)
+	s := &database.UploadScan{
+		ID:         uuid.New().String(),
+		Kind:       kind,
+		PolicyID:   policyID,
+		Filename:   filename,
+		SizeBytes:  size,
+		SHA256:     hex.EncodeToString(hash.Sum(nil)),
+		Scanner:    h.scanner.Name(),
+		Infected:   res.Infected,
+		Signature:  res.Signature,
+		UploadedBy: &uploader,
+	}
+	if res.Infected && h.store != nil {
+		key := "quarantine/" + s.ID
+		if _, err := r.Seek(0, io.SeekStart); err == nil {
+			if err := h.store.Put(ctx, key, r, size, "application/octet-stream"); err != nil {
+				log.Printf("quarantine %s: %v", filename, err)
+			} else {
+				s.QuarantineKey = &key
+			}
+		}
+	}
+	if err := h.db.RecordUploadScan(ctx, s); err != nil {
+		return apierr.Database()
+	}
+	if res.Infected {
+		log.Printf("upload %s by %s flagged: %s", filename, uploader, res.Signature)
+		return apierr.With(apierr.New(http.StatusUnprocessableEntity, "FILE_INFECTED", "file was flagged by the virus scanner"),
+			"signature", res.Signature)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return apierr.New(http.StatusBadRequest, "INVALID_UPLOAD", "invalid upload")
+	}
+	return nil
+}
+
+// UploadScans lets admins review virus scan results and clear quarantine.
+type UploadScans struct {
+	db    *database.DB
+	store storage.Store
+}
+
+func NewUploadScans(db *database.DB, store storage.Store) *UploadScans {
+	return &UploadScans{db: db, store: store}
+}
+
+// List returns recent scan results, newest first. ?infected=true limits
+// them to flagged files.
+// GET /api/admin/upload-scans?infected=&limit=  (SuperAdmin only)
+func (h *UploadScans) List(c echo.Context) error {
+	limit := 100
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			return apierr.Invalid("limit must be between 1 and 1000", "limit")
+		}
+		limit = n
+	}
+	scans, err := h.db.ListUploadScans(c.Request().Context(), c.QueryParam("infected") == "true", limit)
+	if err != nil {
+		return apierr.Database()
+	}
+	if scans == nil {
+		scans = []*database.UploadScan{}
+	}
+	return c.JSON(http.StatusOK, scans)
+}
+
+// DeleteFile deletes a flagged file from quarantine. The scan result is
+// kept.
+// DELETE /api/admin/upload-scans/:id/file  (SuperAdmin only)
+func (h *UploadScans) DeleteFile(c echo.Context) error {
+	ctx := c.Request().Context()
+	s, err := h.db.GetUploadScan(ctx, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "SCAN_NOT_FOUND", "scan not found")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	if s.QuarantineKey == nil {
+		return apierr.New(http.StatusNotFound, "FILE_NOT_FOUND", "no quarantined file")
+	}
+	if err := h.store.Delete(ctx, *s.QuarantineKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("quarantine delete %s: %v", *s.QuarantineKey, err)
+		return apierr.New(http.StatusBadGateway, "STORAGE_ERROR", "storage error")
+	}
+	if err := h.db.ClearQuarantine(ctx, s.ID); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
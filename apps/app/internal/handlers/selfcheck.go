@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/selfcheck"
+)
+
+// SelfCheck runs the deployment self-check on demand.
+type SelfCheck struct {
+	checker *selfcheck.Checker
+}
+
+func NewSelfCheck(checker *selfcheck.Checker) *SelfCheck {
+	return &SelfCheck{checker: checker}
+}
+
+// Run checks the configuration, database, migrations, SMTP server, and
+// blob storage, and returns a result for each. It answers 200 whatever
+// the outcome; the report's status says whether anything failed.
+// GET /api/admin/selfcheck  (SuperAdmin)
+func (h *SelfCheck) Run(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.checker.Run(c.Request().Context()))
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/tokens"
+)
+
+// Settings handles organization-wide settings.
+type Settings struct {
+	db *database.DB
+}
+
+func NewSettings(db *database.DB) *Settings {
+	return &Settings{db: db}
+}
+
+type settingsBody struct {
+	VersionRetention     int  `json:"version_retention"` // 0 = keep every version
+	PublicPortal         bool `json:"public_portal"`
+	MagicLinkTTLMinutes  int  `json:"magic_link_ttl_minutes"`
+	SessionTTLHours      int  `json:"session_ttl_hours"`
+	IdleTimeoutMinutes   int  `json:"idle_timeout_minutes"` // 0 = no idle timeout
+	NewHireGraceDays     int  `json:"new_hire_grace_days"`  // 0 = new hires get the policy's deadline
+	PublishNotifications bool `json:"publish_notifications"`
+	AckReceipts          bool `json:"ack_receipts"`
+	// Preset ("standard" or "strict") fills in the token lifetimes not set
+	// explicitly in the same request. It is not stored.
+	Preset string `json:"preset,omitempty"`
+}
+
+var lifetimePresets = map[string]tokens.Lifetimes{
+	"standard": tokens.DefaultLifetimes,
+	"strict":   tokens.StrictLifetimes,
+}
+
+func (h *Settings) load(ctx context.Context) (settingsBody, error) {
+	var s settingsBody
+	var err error
+	if s.VersionRetention, err = h.db.GetIntSetting(ctx, database.SettingVersionRetention, 0); err != nil {
+		return s, err
+	}
+	if s.PublicPortal, err = h.db.GetBoolSetting(ctx, database.SettingPublicPortal, false); err != nil {
+		return s, err
+	}
+	if s.NewHireGraceDays, err = h.db.NewHireGraceDays(ctx); err != nil {
+		return s, err
+	}
+	if s.PublishNotifications, err = h.db.GetBoolSetting(ctx, database.SettingPublishNotifications, true); err != nil {
+		return s, err
+	}
+	if s.AckReceipts, err = h.db.GetBoolSetting(ctx, database.SettingAckReceipts, false); err != nil {
+		return s, err
+	}
+	l, err := tokens.LoadLifetimes(ctx, h.db)
+	s.MagicLinkTTLMinutes = int(l.MagicLink / time.Minute)
+	s.SessionTTLHours = int(l.Session / time.Hour)
+	s.IdleTimeoutMinutes = int(l.Idle / time.Minute)
+	return s, err
+}
+
+// Get returns the current organization settings.
+// GET /api/admin/settings  (SuperAdmin only)
+func (h *Settings) Get(c echo.Context) error {
+	s, err := h.load(c.Request().Context())
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, s)
+}
+
+// Update saves organization settings; omitted fields keep their values.
+// Lowering the version retention prunes existing history immediately.
+// PUT /api/admin/settings  (SuperAdmin only)
+func (h *Settings) Update(c echo.Context) error {
+	ctx := c.Request().Context()
+	current, err := h.load(ctx)
+	if err != nil {
+		return apierr.Database()
+	}
+	body := current
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	if body.VersionRetention < 0 {
+		return apierr.Invalid("version_retention must be 0 or more", "version_retention")
+	}
+	if body.NewHireGraceDays < 0 || body.NewHireGraceDays > 365 {
+		return apierr.Invalid("new_hire_grace_days must be between 0 and 365", "new_hire_grace_days")
+	}
+	if body.Preset != "" {
+		preset, ok := lifetimePresets[body.Preset]
+		if !ok {
+			return apierr.Invalid("preset must be standard or strict", "preset")
+		}
+		if body.MagicLinkTTLMinutes == current.MagicLinkTTLMinutes {
+			body.MagicLinkTTLMinutes = int(preset.MagicLink / time.Minute)
+		}
+		if body.SessionTTLHours == current.SessionTTLHours {
+			body.SessionTTLHours = int(preset.Session / time.Hour)
+		}
+		if body.IdleTimeoutMinutes == current.IdleTimeoutMinutes {
+			body.IdleTimeoutMinutes = int(preset.Idle / time.Minute)
+		}
+		body.Preset = ""
+	}
+	if body.MagicLinkTTLMinutes < 5 || body.MagicLinkTTLMinutes > 7*24*60 {
+		return apierr.Invalid("magic_link_ttl_minutes must be between 5 and 10080", "magic_link_ttl_minutes")
+	}
+	if body.SessionTTLHours < 1 || body.SessionTTLHours > 90*24 {
+		return apierr.Invalid("session_ttl_hours must be between 1 and 2160", "session_ttl_hours")
+	}
+	if body.IdleTimeoutMinutes < 0 || body.IdleTimeoutMinutes > body.SessionTTLHours*60 {
+		return apierr.Invalid("idle_timeout_minutes must be between 0 and the session length", "idle_timeout_minutes")
+	}
+
+	userID := c.Get(mw.CtxUserID).(string)
+	for key, value := range map[string]string{
+		database.SettingVersionRetention:     strconv.Itoa(body.VersionRetention),
+		database.SettingPublicPortal:         strconv.FormatBool(body.PublicPortal),
+		database.SettingMagicLinkTTL:         strconv.Itoa(body.MagicLinkTTLMinutes),
+		database.SettingSessionTTL:           strconv.Itoa(body.SessionTTLHours),
+		database.SettingIdleTimeout:          strconv.Itoa(body.IdleTimeoutMinutes),
+		database.SettingNewHireGraceDays:     strconv.Itoa(body.NewHireGraceDays),
+		database.SettingPublishNotifications: strconv.FormatBool(body.PublishNotifications),
+		database.SettingAckReceipts:          strconv.FormatBool(body.AckReceipts),
+	} {
+		if err := h.db.SetSetting(ctx, key, value, &userID); err != nil {
+			return apierr.Database()
+		}
+	}
+
+	if body.VersionRetention > 0 && body.VersionRetention != current.VersionRetention {
+		policies, err := h.db.ListPolicies(ctx)
+		if err != nil {
+			return apierr.Database()
+		}
+		pruned := 0
+		for _, p := range policies {
+			n, err := h.db.PruneOldVersions(ctx, p.ID, body.VersionRetention)
+			if err != nil {
+				return apierr.Database()
+			}
+			pruned += n
+		}
+		if pruned > 0 {
+			log.Printf("version retention %d: pruned %d versions", body.VersionRetention, pruned)
+		}
+	}
+	return c.JSON(http.StatusOK, body)
+}
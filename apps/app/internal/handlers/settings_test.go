@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestSettings_StrictLifetimes verifies the strict preset shortens sessions:
+// an active session is reissued with a sliding idle expiry, and one older
+// than the session length is refused even though its token has not expired.
+func TestSettings_StrictLifetimes(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	e := echo.New()
+
+	c, _ := testutil.NewContext(e, http.MethodPut, `{"preset":"strict"}`, "", mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, admin.ID)
+	if err := NewSettings(db).Update(c); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, _ := NewSettings(db).load(ctx)
+	if got.MagicLinkTTLMinutes != 15 || got.SessionTTLHours != 12 || got.IdleTimeoutMinutes != 30 {
+		t.Fatalf("strict settings = %+v", got)
+	}
+
+	keys := testutil.NewKeyring(t, db)
+	auth := mw.NewAuth(keys, db)
+	session := func(signedIn time.Time) string {
+		tok, _ := keys.Sign(jwt.MapClaims{
+			"sub": admin.ID, "role": admin.Role, "type": "session",
+			"iat": signedIn.Unix(), "auth_time": signedIn.Unix(),
+			"exp": time.Now().Add(7 * 24 * time.Hour).Unix(),
+		})
+		return tok
+	}
+	call := func(token string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		return rec, auth.Require(func(echo.Context) error { return nil })(e.NewContext(req, rec))
+	}
+
+	rec, err := call(session(time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Fatalf("active session refused: %v", err)
+	}
+	refreshed := &mw.Claims{}
+	if err := keys.Parse(rec.Header().Get(mw.HeaderSessionToken), refreshed); err != nil {
+		t.Fatalf("refreshed token: %v", err)
+	}
+	if left := time.Until(refreshed.ExpiresAt.Time); left > 31*time.Minute || left < 29*time.Minute {
+		t.Errorf("refreshed token expires in %v; want the 30m idle timeout", left)
+	}
+
+	_, err = call(session(time.Now().Add(-13 * time.Hour)))
+	var he *echo.HTTPError
+	if !errors.As(err, &he) || he.Code != http.StatusUnauthorized {
+		t.Errorf("13h-old session: err = %v; want 401", err)
+	}
+}
+
+// TestConfig_ReflectsSettingsAndEnvironment verifies the public runtime
+// config follows both the environment and the stored settings.
+func TestConfig_ReflectsSettingsAndEnvironment(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	t.Setenv("ORG_NAME", "Acme")
+	t.Setenv("ACK_REQUIRE_SCROLL", "true")
+	db.SetSetting(ctx, database.SettingPublicPortal, "true", nil)
+	h := NewConfig(db, NewPolicy(db))
+
+	e := echo.New()
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", "", nil)
+	if err := h.Get(c); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	var got runtimeConfig
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if got.OrgName != "Acme" || got.SupportEmail != "" || !got.Acknowledgement.RequireScroll ||
+		!got.Features.PublicPortal || !got.Features.PublishNotifications || got.HasLogo {
+		t.Errorf("config = %+v", got)
+	}
+}
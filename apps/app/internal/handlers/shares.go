@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+const (
+	defaultShareTTL = 72 * time.Hour
+	maxShareTTL     = 30 * 24 * time.Hour
+)
+
+// Shares issues and serves signed read-only links to a policy version for
+// external reviewers without an account.
+type Shares struct {
+	policy  *Policy
+	secret  []byte
+	baseURL string
+}
+
+func NewShares(policy *Policy, secret string) *Shares {
+	return &Shares{policy: policy, secret: []byte(secret), baseURL: baseURLFromEnv()}
+}
+
+// Create issues a time-limited share link for a version of the policy,
+// defaulting to the current version.
+// POST /api/policies/:id/share
+func (h *Shares) Create(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.policy.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+
+	var body struct {
+		VersionID      *string `json:"version_id"`
+		ExpiresInHours int     `json:"expires_in_hours"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	versionID := policy.CurrentVersionID
+	if body.VersionID != nil {
+		versionID = body.VersionID
+	}
+	if versionID == nil {
+		return apierr.New(http.StatusBadRequest, "POLICY_NO_CURRENT_VERSION", "policy has no version to share")
+	}
+	v, err := h.policy.db.GetPolicyVersion(ctx, *versionID)
+	if err != nil || v.PolicyID != policy.ID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusBadRequest, "VERSION_NOT_FOUND", "unknown version")
+		}
+		return apierr.Database()
+	}
+	ttl := defaultShareTTL
+	if body.ExpiresInHours != 0 {
+		ttl = time.Duration(body.ExpiresInHours) * time.Hour
+		if ttl < 0 || ttl > maxShareTTL {
+			return apierr.Invalid("expires_in_hours must be between 1 and 720", "expires_in_hours")
+		}
+	}
+
+	creatorID := c.Get(mw.CtxUserID).(string)
+	share, err := h.policy.db.CreatePolicyShare(ctx, policy.ID, v.ID, time.Now().Add(ttl).Truncate(time.Second), &creatorID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"share": share,
+		"url":   h.baseURL + "/share/" + h.sign(share),
+	})
+}
+
+// List returns the policy's share links with their view counts.
+// GET /api/policies/:id/shares
+func (h *Shares) List(c echo.Context) error {
+	policy, err := h.policy.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	shares, err := h.policy.db.ListPolicyShares(c.Request().Context(), policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if shares == nil {
+		shares = []*database.PolicyShare{}
+	}
+	return c.JSON(http.StatusOK, shares)
+}
+
+// Revoke disables a share link before it expires.
+// DELETE /api/policies/:id/shares/:shareId
+func (h *Shares) Revoke(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.policy.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	share, err := h.policy.db.GetPolicyShare(ctx, c.Param("shareId"))
+	if err != nil || share.PolicyID != policy.ID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "SHARE_NOT_FOUND", "share not found")
+		}
+		return apierr.Database()
+	}
+	if err := h.policy.db.RevokePolicyShare(ctx, share.ID); err != nil {
+		return apierr.Database()
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// View renders the shared version for an unauthenticated reader and logs the
+// view. Invalid, expired, and revoked links are indistinguishable.
+// GET /share/:token
+func (h *Shares) View(c echo.Context) error {
+	ctx := c.Request().Context()
+	notFound := apierr.New(http.StatusNotFound, "LINK_EXPIRED", "link is invalid or has expired")
+
+	share, err := h.verify(c, c.Param("token"))
+	if err != nil {
+		return err
+	}
+	if share == nil || !share.Active(time.Now()) {
+		return notFound
+	}
+	policy, err := h.policy.db.GetPolicy(ctx, share.PolicyID)
+	if err != nil {
+		return apierr.Database()
+	}
+	v, err := h.policy.db.GetPolicyVersion(ctx, share.PolicyVersionID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if err := h.policy.db.RecordShareView(ctx, share.ID, c.RealIP(), c.Request().UserAgent()); err != nil {
+		return apierr.Database()
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("X-Robots-Tag", "noindex")
+	return renderPage(c, "shared_policy.html", map[string]any{"Policy": policy, "Version": v, "Share": share})
+}
+
+// sign builds the link token "<share id>.<expiry unix>.<hmac>". The expiry is
+// part of the signature so a link cannot be extended by editing the URL.
+func (h *Shares) sign(s *database.PolicyShare) string {
+	payload := s.ID + "." + strconv.FormatInt(s.ExpiresAt.Unix(), 10)
+	return payload + "." + h.mac(payload)
+}
+
+// verify checks a token's signature and loads its share. It returns nil
+// without error when the token is malformed, forged, or unknown.
+func (h *Shares) verify(c echo.Context, token string) (*database.PolicyShare, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(h.mac(payload))) {
+		return nil, nil
+	}
+	share, err := h.policy.db.GetPolicyShare(c.Request().Context(), parts[0])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, apierr.Database()
+	}
+	if strconv.FormatInt(share.ExpiresAt.Unix(), 10) != parts[1] {
+		return nil, nil
+	}
+	return share, nil
+}
+
+func (h *Shares) mac(payload string) string {
+	m := hmac.New(sha256.New, h.secret)
+	m.Write([]byte("policy-share:" + payload))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestShares_LinkLifecycle issues a share link, reads it without an account,
+// and checks that tampering and revocation both stop it working.
+func TestShares_LinkLifecycle(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	p, _ := db.CreatePolicy(ctx, "Vendor Security", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+
+	e := echo.New()
+	h := NewShares(NewPolicy(db), "test-secret")
+
+	c, rec := testutil.NewContext(e, http.MethodPost, `{"expires_in_hours":24}`, p.ID, mw.RoleSuperAdmin, nil)
+	if err := h.Create(c); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	var created struct {
+		Share struct{ ID string } `json:"share"`
+		URL   string              `json:"url"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	token := created.URL[strings.LastIndex(created.URL, "/")+1:]
+
+	view := func(token string) (*httptest.ResponseRecorder, error) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		c.SetParamNames("token")
+		c.SetParamValues(token)
+		return rec, h.View(c)
+	}
+	isNotFound := func(err error) bool {
+		var he *echo.HTTPError
+		return errors.As(err, &he) && he.Code == http.StatusNotFound
+	}
+
+	if rec, err := view(token); err != nil || !strings.Contains(rec.Body.String(), "Vendor Security") {
+		t.Fatalf("View: err=%v body=%s", err, rec.Body.String())
+	}
+	parts := strings.Split(token, ".")
+	if _, err := view(parts[0] + ".9999999999." + parts[2]); !isNotFound(err) {
+		t.Errorf("extended expiry: got %v; want 404", err)
+	}
+
+	share, _ := db.GetPolicyShare(ctx, created.Share.ID)
+	if share.ViewCount != 1 {
+		t.Errorf("view_count = %d; want 1", share.ViewCount)
+	}
+
+	c, _ = testutil.NewContext(e, http.MethodDelete, "", p.ID, mw.RoleSuperAdmin, nil)
+	c.SetParamNames("id", "shareId")
+	c.SetParamValues(p.ID, share.ID)
+	if err := h.Revoke(c); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := view(token); !isNotFound(err) {
+		t.Errorf("revoked link: got %v; want 404", err)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+)
+
+// SIEM serves the security event stream to a SIEM collector.
+type SIEM struct {
+	db    *database.DB
+	token string // SIEM_TOKEN; empty disables the stream
+}
+
+func NewSIEM(db *database.DB) *SIEM {
+	return &SIEM{db: db, token: os.Getenv("SIEM_TOKEN")}
+}
+
+// siemPollInterval is how often Events checks for new events while waiting.
+const siemPollInterval = time.Second
+
+// Events returns the security events after ?cursor=, oldest first, one per
+// line as JSON or CEF, authenticated with the SIEM_TOKEN bearer token. The
+// X-Next-Cursor header, also on each event, is the cursor for the next
+// call; without one the stream starts at the beginning. With ?wait= the
+// request holds for up to that many seconds until there is something to
+// return, so a collector can follow the stream with back-to-back calls. The
+// wait ends early enough to answer within REQUEST_TIMEOUT.
+// GET /api/integrations/siem/events?cursor=&format=jsonl|cef&limit=&wait=
+func (h *SIEM) Events(c echo.Context) error {
+	if h.token == "" {
+		return apierr.New(http.StatusNotFound, "SIEM_NOT_CONFIGURED", "SIEM stream not configured")
+	}
+	token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		return apierr.New(http.StatusUnauthorized, "INVALID_TOKEN", "invalid token")
+	}
+
+	var cursor database.SecurityCursor
+	if v := c.QueryParam("cursor"); v != "" {
+		var err error
+		if cursor, err = database.ParseSecurityCursor(v); err != nil {
+			return apierr.Invalid("cursor is not one returned by this endpoint", "cursor")
+		}
+	}
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "jsonl"
+	}
+	if format != "jsonl" && format != "cef" {
+		return apierr.Invalid("format must be jsonl or cef", "format")
+	}
+	limit := 500
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 5000 {
+			return apierr.Invalid("limit must be between 1 and 5000", "limit")
+		}
+		limit = n
+	}
+	var wait time.Duration
+	if v := c.QueryParam("wait"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 60 {
+			return apierr.Invalid("wait must be between 0 and 60 seconds", "wait")
+		}
+		wait = time.Duration(n) * time.Second
+	}
+
+	ctx := c.Request().Context()
+	deadline := time.Now().Add(wait)
+	// Stop waiting a poll early of REQUEST_TIMEOUT, so the answer still
+	// goes out before the request context is cancelled.
+	if d, ok := ctx.Deadline(); ok && d.Add(-siemPollInterval).Before(deadline) {
+		deadline = d.Add(-siemPollInterval)
+	}
+	events, next, err := h.db.Reader().ListSecurityEvents(ctx, cursor, limit)
+poll:
+	for err == nil && len(events) == 0 && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			// Nothing new; the collector resumes from where it was.
+			next = cursor
+			break poll
+		case <-time.After(siemPollInterval):
+		}
+		events, next, err = h.db.Reader().ListSecurityEvents(ctx, cursor, limit)
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+
+	var b strings.Builder
+	for _, e := range events {
+		if format == "cef" {
+			b.WriteString(cefLine(e))
+		} else {
+			line, _ := json.Marshal(e)
+			b.Write(line)
+		}
+		b.WriteByte('\n')
+	}
+	c.Response().Header().Set("X-Next-Cursor", next.String())
+	c.Response().Header().Set("Cache-Control", "no-store")
+	contentType := "application/x-ndjson"
+	if format == "cef" {
+		contentType = echo.MIMETextPlainCharsetUTF8
+	}
+	return c.Blob(http.StatusOK, contentType, []byte(b.String()))
+}
+
+// cefNames and cefSeverities give the CEF name and severity (0-10) of each
+// event category.
+var (
+	cefNames = map[string]string{
+		database.SecurityAuthentication: "Authentication",
+		database.SecurityAccessDenied:   "Access denied",
+		database.SecurityChange:         "Change",
+	}
+	cefSeverities = map[string]int{
+		database.SecurityAuthentication: 3,
+		database.SecurityAccessDenied:   7,
+		database.SecurityChange:         5,
+	}
+)
+
+// cefLine formats an event in ArcSight Common Event Format. The event type
+// is the signature ID; the cursor goes in externalId.
+func cefLine(e *database.SecurityEvent) string {
+	header := strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	value := strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+	ext := []string{"rt=" + strconv.FormatInt(e.At.UnixMilli(), 10)}
+	add := func(key, v string) {
+		if v != "" {
+			ext = append(ext, key+"="+value.Replace(v))
+		}
+	}
+	add("act", e.Type)
+	if e.ActorID != nil {
+		add("suid", *e.ActorID)
+	}
+	if e.ActorEmail != nil {
+		add("suser", *e.ActorEmail)
+	}
+	add("src", e.IPAddress)
+	add("requestClientApplication", e.UserAgent)
+	if e.ImpersonatorID != nil {
+		add("cs1Label", "impersonatorId")
+		add("cs1", *e.ImpersonatorID)
+	}
+	if e.TargetID != "" {
+		add("cs2Label", "targetType")
+		add("cs2", e.TargetType)
+		add("cs3Label", "targetId")
+		add("cs3", e.TargetID)
+	}
+	add("msg", e.Details)
+	add("externalId", e.Cursor)
+	return fmt.Sprintf("CEF:0|PolicyFlow|PolicyFlow|1.0|%s|%s|%d|%s",
+		header.Replace(e.Type), cefNames[e.Category], cefSeverities[e.Category], strings.Join(ext, " "))
+}
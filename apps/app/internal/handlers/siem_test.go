@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestSIEM_EventsResumeFromCursor verifies that sign-ins, refused requests,
+// and changes are streamed in order as JSONL or CEF, and that a collector
+// passing back the cursor only gets what is new.
+func TestSIEM_EventsResumeFromCursor(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", mw.RoleSuperAdmin, nil, nil)
+	db.RecordLoginEvent(ctx, ada.ID, database.LoginEventLogin, "10.0.0.1", "Firefox")
+	db.RecordAudit(ctx, &database.AuditEntry{ActorID: &ada.ID, Action: database.ActionAccessDenied,
+		TargetType: "policy", TargetID: "p1", Details: "GET /api/policies/:id: status 403 FORBIDDEN", IPAddress: "10.0.0.1"})
+	db.RecordAudit(ctx, &database.AuditEntry{ActorID: &ada.ID, Action: "PUT /api/admin/settings",
+		Details: "status=200", IPAddress: "10.0.0.1"})
+
+	h := &SIEM{db: db, token: "siem-secret"}
+	fetch := func(query string) (lines []string, next string) {
+		t.Helper()
+		c, rec := testutil.NewContext(echo.New(), http.MethodGet, "", "", "", nil)
+		c.Request().Header.Set(echo.HeaderAuthorization, "Bearer siem-secret")
+		for _, kv := range strings.Split(query, "&") {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				c.QueryParams().Set(k, v)
+			}
+		}
+		if err := h.Events(c); err != nil {
+			t.Fatalf("Events(%s): %v", query, err)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != "" {
+			lines = strings.Split(body, "\n")
+		}
+		return lines, rec.Header().Get("X-Next-Cursor")
+	}
+
+	lines, cursor := fetch("")
+	if len(lines) != 3 {
+		t.Fatalf("got %d events; want 3:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	categories := map[string]bool{}
+	for _, l := range lines {
+		var e database.SecurityEvent
+		if err := json.Unmarshal([]byte(l), &e); err != nil {
+			t.Fatalf("line %q: %v", l, err)
+		}
+		categories[e.Category] = true
+	}
+	if len(categories) != 3 {
+		t.Errorf("categories = %v; want authentication, access_denied, and change", categories)
+	}
+
+	if lines, next := fetch("cursor=" + cursor); len(lines) != 0 || next != cursor {
+		t.Errorf("after cursor: %d events, next %q; want none and %q", len(lines), next, cursor)
+	}
+	db.RecordLoginEvent(ctx, ada.ID, database.LoginEventLinkIssued, "10.0.0.2", "Safari")
+	lines, _ = fetch("cursor=" + cursor + "&format=cef")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "CEF:0|PolicyFlow|PolicyFlow|1.0|auth.link_issued|Authentication|3|") ||
+		!strings.Contains(lines[0], "suser=ada@example.com") || !strings.Contains(lines[0], "src=10.0.0.2") {
+		t.Errorf("new events as CEF = %q", lines)
+	}
+	lines, _ = fetch("format=cef")
+	if !strings.Contains(strings.Join(lines, "\n"), `act=PUT /api/admin/settings suid=`+ada.ID+` suser=ada@example.com src=10.0.0.1 msg=status\=200`) {
+		t.Errorf("CEF did not escape '=': %q", lines)
+	}
+
+	// A wait longer than the request timeout still answers with the cursor.
+	_, next := fetch("")
+	c, rec := testutil.NewContext(echo.New(), http.MethodGet, "", "", "", nil)
+	tctx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
+	defer cancel()
+	c.SetRequest(c.Request().WithContext(tctx))
+	c.Request().Header.Set(echo.HeaderAuthorization, "Bearer siem-secret")
+	c.QueryParams().Set("cursor", next)
+	c.QueryParams().Set("wait", "30")
+	if err := h.Events(c); err != nil || rec.Code != http.StatusOK || rec.Header().Get("X-Next-Cursor") != next {
+		t.Errorf("wait past the timeout: err %v, status %d, cursor %q; want 200 with %q",
+			err, rec.Code, rec.Header().Get("X-Next-Cursor"), next)
+	}
+
+	c, _ = testutil.NewContext(echo.New(), http.MethodGet, "", "", "", nil)
+	c.Request().Header.Set(echo.HeaderAuthorization, "Bearer wrong")
+	if err := h.Events(c); err == nil {
+		t.Error("wrong token accepted")
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+// Training receives course completions pushed by the learning management
+// system.
+type Training struct {
+	db           *database.DB
+	webhookToken string // empty disables the webhook
+}
+
+func NewTraining(db *database.DB) *Training {
+	return &Training{db: db, webhookToken: os.Getenv("LMS_WEBHOOK_TOKEN")}
+}
+
+// Webhook records that a user completed a course, authenticated with the
+// shared LMS_WEBHOOK_TOKEN bearer token. Completions are stored whether or
+// not a policy links the course yet, so linking it later picks them up;
+// linked_policies tells the LMS how many policies the course counts for.
+// POST /api/integrations/training/webhook
+func (h *Training) Webhook(c echo.Context) error {
+	ctx := c.Request().Context()
+	if h.webhookToken == "" {
+		return apierr.New(http.StatusNotFound, "LMS_NOT_CONFIGURED", "webhook not configured")
+	}
+	token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.webhookToken)) != 1 {
+		return apierr.New(http.StatusUnauthorized, "INVALID_TOKEN", "invalid token")
+	}
+
+	var body struct {
+		CourseID    string `json:"course_id"`
+		Email       string `json:"email"`
+		EmployeeID  string `json:"employee_id"`
+		CompletedAt string `json:"completed_at"` // RFC3339; defaults to now
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	body.CourseID = strings.TrimSpace(body.CourseID)
+	if body.CourseID == "" {
+		return apierr.Invalid("course_id is required", "course_id")
+	}
+	if body.Email == "" && body.EmployeeID == "" {
+		return apierr.Invalid("email or employee_id is required", "email", "employee_id")
+	}
+	completedAt := time.Now().UTC()
+	if body.CompletedAt != "" {
+		t, err := time.Parse(time.RFC3339, body.CompletedAt)
+		if err != nil {
+			return apierr.Invalid("completed_at must be RFC3339", "completed_at")
+		}
+		if t.After(completedAt.Add(5 * time.Minute)) {
+			return apierr.Invalid("completed_at is in the future", "completed_at")
+		}
+		completedAt = t
+	}
+
+	var user *database.User
+	var err error
+	if body.EmployeeID != "" {
+		user, err = h.db.GetUserByExternalID(ctx, body.EmployeeID)
+	} else {
+		user, err = h.db.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(body.Email)))
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "unknown user")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+
+	if err := h.db.RecordTrainingCompletion(ctx, body.CourseID, user.ID, completedAt); err != nil {
+		return apierr.Database()
+	}
+	linked, err := h.db.CountCoursePolicies(ctx, body.CourseID)
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, map[string]any{"user_id": user.ID, "linked_policies": linked})
+}
+
+// policyTrainingView is a policy's course with the caller's completion.
+type policyTrainingView struct {
+	*database.PolicyTraining
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// GetTraining returns the course linked to a policy and when the caller
+// completed it.
+// GET /api/policies/:id/training
+func (h *Policy) GetTraining(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	t, err := h.db.GetPolicyTraining(ctx, policy.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(http.StatusNotFound, "TRAINING_NOT_FOUND", "policy has no linked training")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	completed, err := h.db.TrainingCompletedAt(ctx, t.CourseID, c.Get(mw.CtxUserID).(string))
+	if err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, policyTrainingView{PolicyTraining: t, CompletedAt: completed})
+}
+
+// SetTraining links a policy to the LMS course that must be completed
+// alongside acknowledging it.
+// PUT /api/policies/:id/training  {"course_id", "course_name", "course_url"}
+func (h *Policy) SetTraining(c echo.Context) error {
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	var req struct {
+		CourseID   string `json:"course_id"`
+		CourseName string `json:"course_name"`
+		CourseURL  string `json:"course_url"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	t := &database.PolicyTraining{
+		PolicyID:   policy.ID,
+		CourseID:   strings.TrimSpace(req.CourseID),
+		CourseName: strings.TrimSpace(req.CourseName),
+		CourseURL:  strings.TrimSpace(req.CourseURL),
+	}
+	if t.CourseID == "" || len(t.CourseID) > 200 {
+		return apierr.Invalid("course_id is required and must be under 200 characters", "course_id")
+	}
+	if len(t.CourseName) > 200 {
+		return apierr.Invalid("course_name must be under 200 characters", "course_name")
+	}
+	if t.CourseURL != "" {
+		if u, err := url.Parse(t.CourseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return apierr.Invalid("course_url must be an http(s) URL", "course_url")
+		}
+	}
+	userID := c.Get(mw.CtxUserID).(string)
+	t.UpdatedBy = &userID
+	if err := h.db.SetPolicyTraining(c.Request().Context(), t); err != nil {
+		return apierr.Database()
+	}
+	return c.JSON(http.StatusOK, t)
+}
+
+// DeleteTraining unlinks a policy's course.
+// DELETE /api/policies/:id/training
+func (h *Policy) DeleteTraining(c echo.Context) error {
+	policy, err := h.managedPolicy(c)
+	if err != nil {
+		return err
+	}
+	removed, err := h.db.DeletePolicyTraining(c.Request().Context(), policy.ID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if !removed {
+		return apierr.New(http.StatusNotFound, "TRAINING_NOT_FOUND", "policy has no linked training")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// TrainingGaps lists users who acknowledged a policy's current version but
+// have not completed its mandatory course. DeptAdmin sees their own
+// department.
+// GET /api/admin/reports/training-gaps?format=json|csv
+func (h *Reports) TrainingGaps(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return apierr.Invalid("format must be json or csv", "format")
+	}
+	deptID, err := reportScope(c)
+	if err != nil {
+		return err
+	}
+	gaps, err := h.db.Reader().ListTrainingGaps(c.Request().Context(), deptID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if format == "csv" {
+		out, err := streamCSV(c, "training-gaps.csv", []string{"user", "email", "department", "policy", "course_id", "course_name", "acknowledged_at"})
+		if err != nil {
+			return err
+		}
+		for _, g := range gaps {
+			dept := ""
+			if g.DepartmentName != nil {
+				dept = *g.DepartmentName
+			}
+			if err := out.Write([]string{g.UserName, g.UserEmail, dept, g.PolicyTitle, g.CourseID, g.CourseName,
+				g.AcknowledgedAt.Format(time.RFC3339)}); err != nil {
+				return err
+			}
+		}
+		return out.Close()
+	}
+	if gaps == nil {
+		gaps = []*database.TrainingGap{}
+	}
+	return c.JSON(http.StatusOK, gaps)
+}
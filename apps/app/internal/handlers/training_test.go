@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestTraining_CompletionsCloseGaps verifies users who acknowledged a policy
+// are reported until the LMS sends their course completion.
+func TestTraining_CompletionsCloseGaps(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	root, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", mw.RoleStaff, nil, nil)
+	bob, _ := db.CreateUser(ctx, "bob@example.com", "Bob", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Information Security", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	for _, u := range []*database.User{ada, bob} {
+		db.CreateAcknowledgement(ctx, u.ID, *p.CurrentVersionID)
+	}
+
+	e := echo.New()
+	c, _ := testutil.NewContext(e, http.MethodPut, `{"course_id":"SEC-101","course_url":"javascript:alert(1)"}`, p.ID, mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, root.ID)
+	var he *echo.HTTPError
+	if err := NewPolicy(db).SetTraining(c); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Fatalf("bad course_url: err = %v; want 400", err)
+	}
+	c, _ = testutil.NewContext(e, http.MethodPut, `{"course_id":"SEC-101","course_name":"Security Basics"}`, p.ID, mw.RoleSuperAdmin, nil)
+	c.Set(mw.CtxUserID, root.ID)
+	if err := NewPolicy(db).SetTraining(c); err != nil {
+		t.Fatalf("SetTraining: %v", err)
+	}
+
+	h := &Training{db: db, webhookToken: "s3cret"}
+	post := func(token, body string) error {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		return h.Webhook(e.NewContext(req, httptest.NewRecorder()))
+	}
+	if err := post("wrong", `{"course_id":"SEC-101","email":"ada@example.com"}`); !errors.As(err, &he) || he.Code != http.StatusUnauthorized {
+		t.Fatalf("bad token: err = %v; want 401", err)
+	}
+	if err := post("s3cret", `{"course_id":"SEC-101","email":"ada@example.com","completed_at":"2026-01-05T10:00:00Z"}`); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleSuperAdmin, nil)
+	if err := NewReports(db, nil).TrainingGaps(c); err != nil {
+		t.Fatalf("TrainingGaps: %v", err)
+	}
+	var gaps []database.TrainingGap
+	json.Unmarshal(rec.Body.Bytes(), &gaps)
+	if len(gaps) != 1 || gaps[0].UserID != bob.ID || gaps[0].CourseName != "Security Basics" {
+		t.Errorf("gaps = %+v; want only Bob", gaps)
+	}
+}
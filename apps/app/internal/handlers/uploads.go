@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+)
+
+const (
+	// UploadChunkBytes is the most a single chunk may carry.
+	UploadChunkBytes = 8 << 20
+	// uploadLifetime is how long a chunked upload may take to finish and
+	// be used.
+	uploadLifetime = 24 * time.Hour
+	// headerUploadOffset names the byte offset a chunk starts at.
+	headerUploadOffset = "Upload-Offset"
+)
+
+// Uploads receives large files in chunks, so that a dropped connection
+// costs one chunk rather than the whole file. The parts are appended to a
+// file on local disk; a finished upload is then passed by ID as the
+// upload_id form field to an endpoint that takes files.
+type Uploads struct {
+	db       *database.DB
+	dir      string
+	maxBytes int64
+	busy     sync.Map // upload ID → chunk being written
+}
+
+func NewUploads(db *database.DB, dir string, maxBytes int64) (*Uploads, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &Uploads{db: db, dir: dir, maxBytes: maxBytes}, nil
+}
+
+func (h *Uploads) path(id string) string {
+	return filepath.Join(h.dir, id)
+}
+
+// uploadStatus is the client's view of an upload, telling it where to resume.
+type uploadStatus struct {
+	*database.Upload
+	ChunkBytes int `json:"chunk_bytes"`
+}
+
+// Create starts a chunked upload of a file of the given size.
+// POST /api/uploads  {filename, size_bytes, content_type}
+func (h *Uploads) Create(c echo.Context) error {
+	var body struct {
+		Filename    string `json:"filename"`
+		SizeBytes   int64  `json:"size_bytes"`
+		ContentType string `json:"content_type"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
+	}
+	name := filepath.Base(strings.TrimSpace(body.Filename))
+	if name == "" || name == "." || name == "/" {
+		return apierr.Invalid("filename is required", "filename")
+	}
+	if body.SizeBytes < 1 {
+		return apierr.Invalid("size_bytes must be positive", "size_bytes")
+	}
+	if body.SizeBytes > h.maxBytes {
+		return apierr.With(apierr.New(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "file is too large"), "limit", h.maxBytes)
+	}
+	if _, _, err := mime.ParseMediaType(body.ContentType); err != nil {
+		body.ContentType = "application/octet-stream"
+	}
+
+	ctx := c.Request().Context()
+	h.cleanup(c)
+	u := &database.Upload{
+		UserID:      c.Get(mw.CtxUserID).(string),
+		Filename:    name,
+		ContentType: body.ContentType,
+		SizeBytes:   body.SizeBytes,
+	}
+	if err := h.db.CreateUpload(ctx, u, time.Now().Add(uploadLifetime)); err != nil {
+		return apierr.Database()
+	}
+	f, err := os.OpenFile(h.path(u.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Printf("upload %s: %v", u.ID, err)
+		_ = h.db.DeleteUpload(ctx, u.ID)
+		return apierr.New(http.StatusInternalServerError, "UPLOAD_FAILED", "upload could not be started")
+	}
+	f.Close()
+	return c.JSON(http.StatusCreated, uploadStatus{u, UploadChunkBytes})
+}
+
+// Status reports how much of an upload has arrived, for resuming it.
+// GET /api/uploads/:id
+func (h *Uploads) Status(c echo.Context) error {
+	u, err := h.own(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, uploadStatus{u, UploadChunkBytes})
+}
+
+// Append stores the next chunk, sent as the raw request body. The
+// Upload-Offset header must equal the bytes received so far; on a mismatch
+// the reply says where to resume.
+// PATCH /api/uploads/:id
+func (h *Uploads) Append(c echo.Context) error {
+	u, err := h.own(c)
+	if err != nil {
+		return err
+	}
+	offset, err := strconv.ParseInt(c.Request().Header.Get(headerUploadOffset), 10, 64)
+	if err != nil {
+		return apierr.Invalid("Upload-Offset header is required", headerUploadOffset)
+	}
+	if offset != u.Received {
+		return apierr.With(apierr.New(http.StatusConflict, "OFFSET_MISMATCH", "chunk does not start where the upload left off"), "received", u.Received)
+	}
+	if _, loaded := h.busy.LoadOrStore(u.ID, true); loaded {
+		return apierr.New(http.StatusConflict, "UPLOAD_BUSY", "another chunk of this upload is being received")
+	}
+	defer h.busy.Delete(u.ID)
+
+	f, err := os.OpenFile(h.path(u.ID), os.O_WRONLY, 0)
+	if err != nil {
+		log.Printf("upload %s: %v", u.ID, err)
+		return apierr.New(http.StatusGone, "UPLOAD_LOST", "upload data is gone; start again")
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return apierr.New(http.StatusInternalServerError, "UPLOAD_FAILED", "chunk could not be stored")
+	}
+	// A chunk may not run past the declared size.
+	remaining := u.SizeBytes - offset
+	n, err := io.Copy(f, io.LimitReader(c.Request().Body, min(remaining, UploadChunkBytes)+1))
+	if err != nil {
+		// The connection dropped mid-chunk; whatever arrived is discarded.
+		return apierr.New(http.StatusBadRequest, "INVALID_UPLOAD", "chunk was cut short")
+	}
+	if n > remaining || n > UploadChunkBytes {
+		return apierr.New(http.StatusRequestEntityTooLarge, "CHUNK_TOO_LARGE", "chunk runs past the end of the file or the chunk size")
+	}
+	if err := f.Truncate(offset + n); err != nil {
+		return apierr.New(http.StatusInternalServerError, "UPLOAD_FAILED", "chunk could not be stored")
+	}
+	if err := h.db.AdvanceUpload(c.Request().Context(), u.ID, offset, offset+n); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusConflict, "OFFSET_MISMATCH", "chunk does not start where the upload left off")
+		}
+		return apierr.Database()
+	}
+	u.Received = offset + n
+	return c.JSON(http.StatusOK, uploadStatus{u, UploadChunkBytes})
+}
+
+// Cancel abandons an upload and deletes what was received.
+// DELETE /api/uploads/:id
+func (h *Uploads) Cancel(c echo.Context) error {
+	u, err := h.own(c)
+	if err != nil {
+		return err
+	}
+	h.discard(c, u.ID)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// own loads the :id upload, which must belong to the caller.
+func (h *Uploads) own(c echo.Context) (*database.Upload, error) {
+	return h.load(c, c.Param("id"))
+}
+
+func (h *Uploads) load(c echo.Context, id string) (*database.Upload, error) {
+	u, err := h.db.GetUpload(c.Request().Context(), id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, apierr.Database()
+	}
+	if err != nil || u.UserID != c.Get(mw.CtxUserID).(string) {
+		return nil, apierr.New(http.StatusNotFound, "UPLOAD_NOT_FOUND", "upload not found or expired")
+	}
+	return u, nil
+}
+
+func (h *Uploads) discard(c echo.Context, id string) {
+	if err := h.db.DeleteUpload(c.Request().Context(), id); err != nil {
+		log.Printf("upload %s: %v", id, err)
+	}
+	if err := os.Remove(h.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("upload %s: %v", id, err)
+	}
+}
+
+// cleanup deletes expired uploads, and files left behind by uploads whose
+// records are gone, such as those of deleted users.
+func (h *Uploads) cleanup(c echo.Context) {
+	ids, err := h.db.DeleteExpiredUploads(c.Request().Context())
+	if err != nil {
+		log.Printf("expire uploads: %v", err)
+	}
+	for _, id := range ids {
+		os.Remove(h.path(id))
+	}
+	entries, _ := os.ReadDir(h.dir)
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > uploadLifetime+time.Hour {
+			os.Remove(filepath.Join(h.dir, e.Name()))
+		}
+	}
+}
+
+// take opens a finished upload for an endpoint that takes files. Done on
+// the result deletes it.
+func (h *Uploads) take(c echo.Context, id string) (*incomingFile, error) {
+	u, err := h.load(c, id)
+	if err != nil {
+		return nil, err
+	}
+	if !u.Complete() {
+		return nil, apierr.With(apierr.New(http.StatusConflict, "UPLOAD_INCOMPLETE", "upload is not finished"), "received", u.Received)
+	}
+	f, err := os.Open(h.path(u.ID))
+	if err != nil {
+		log.Printf("upload %s: %v", u.ID, err)
+		return nil, apierr.New(http.StatusGone, "UPLOAD_LOST", "upload data is gone; start again")
+	}
+	return &incomingFile{
+		File:        f,
+		Filename:    u.Filename,
+		ContentType: u.ContentType,
+		Size:        u.SizeBytes,
+		done:        func() { h.discard(c, u.ID) },
+	}, nil
+}
+
+// incomingFile is an uploaded file, sent either with the request as the
+// multipart "file" field or beforehand in chunks and named by the
+// "upload_id" field.
+type incomingFile struct {
+	multipart.File
+	Filename    string
+	ContentType string
+	Size        int64
+	done        func()
+}
+
+// Done deletes a chunked upload once the file has been used.
+func (f *incomingFile) Done() {
+	if f.done != nil {
+		f.done()
+	}
+}
+
+// SetUploads lets the handler's file endpoints take chunked uploads.
+func (h *Policy) SetUploads(u *Uploads) {
+	h.uploads = u
+}
+
+// incomingFile opens the request's file, refusing it with 413 if it is over
+// max bytes. The caller closes it.
+func (h *Policy) incomingFile(c echo.Context, max int64) (*incomingFile, error) {
+	var f *incomingFile
+	if id := c.FormValue("upload_id"); id != "" && h.uploads != nil {
+		var err error
+		if f, err = h.uploads.take(c, id); err != nil {
+			return nil, err
+		}
+	} else {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, apierr.Invalid("file is required", "file")
+		}
+		if fh.Size > max {
+			return nil, apierr.With(apierr.New(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "file is too large"), "limit", max)
+		}
+		mf, err := fh.Open()
+		if err != nil {
+			return nil, apierr.New(http.StatusBadRequest, "INVALID_UPLOAD", "invalid upload")
+		}
+		f = &incomingFile{File: mf, Filename: filepath.Base(fh.Filename), ContentType: fh.Header.Get(echo.HeaderContentType), Size: fh.Size}
+	}
+	if f.Size > max {
+		f.Close()
+		return nil, apierr.With(apierr.New(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "file is too large"), "limit", max)
+	}
+	return f, nil
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/storage"
+	"policyflow/internal/testutil"
+)
+
+// TestUploads_ResumeAndAttach sends a file in chunks, resumes after a
+// chunk with the wrong offset, and attaches the finished upload to a
+// policy by its ID.
+func TestUploads_ResumeAndAttach(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Travel", "", nil, "organization", nil)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	uploads, err := NewUploads(db, t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("NewUploads: %v", err)
+	}
+	policyH := NewPolicy(db)
+	policyH.SetUploads(uploads)
+	attachments := NewAttachments(policyH, store)
+	e := echo.New()
+	call := func(fn echo.HandlerFunc, method, body, id string, header map[string]string) (*httptest.ResponseRecorder, error) {
+		c, rec := testutil.NewContext(e, method, body, id, mw.RoleSuperAdmin, nil)
+		for k, v := range header {
+			c.Request().Header.Set(k, v)
+		}
+		c.Set(mw.CtxUserID, admin.ID)
+		return rec, fn(c)
+	}
+
+	var he *echo.HTTPError
+	if _, err := call(uploads.Create, http.MethodPost, `{"filename":"big.pdf","size_bytes":2000000}`, "", nil); !errors.As(err, &he) || he.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("over the limit: err = %v; want 413", err)
+	}
+	rec, err := call(uploads.Create, http.MethodPost, `{"filename":"../form.pdf","size_bytes":11,"content_type":"application/pdf"}`, "", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	var u database.Upload
+	json.Unmarshal(rec.Body.Bytes(), &u)
+	if u.Filename != "form.pdf" {
+		t.Errorf("filename = %q; want the base name", u.Filename)
+	}
+	chunk := func(offset, data string) error {
+		_, err := call(uploads.Append, http.MethodPatch, data, u.ID, map[string]string{headerUploadOffset: offset})
+		return err
+	}
+	if err := chunk("0", "%PDF-"); err != nil {
+		t.Fatalf("first chunk: %v", err)
+	}
+	if err := chunk("0", "%PDF-"); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Fatalf("repeated chunk: err = %v; want 409", err)
+	}
+	rec, _ = call(uploads.Status, http.MethodGet, "", u.ID, nil)
+	json.Unmarshal(rec.Body.Bytes(), &u)
+	if u.Received != 5 {
+		t.Fatalf("received = %d; want 5", u.Received)
+	}
+	if err := chunk("5", "1.4 form and more"); !errors.As(err, &he) || he.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("chunk past the end: err = %v; want 413", err)
+	}
+	if err := chunk("5", "1.4 fm"); err != nil {
+		t.Fatalf("last chunk: %v", err)
+	}
+
+	form := url.Values{"upload_id": {u.ID}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(p.ID)
+	c.Set(mw.CtxUserRole, mw.RoleSuperAdmin)
+	c.Set(mw.CtxUserID, admin.ID)
+	if err := attachments.Upload(c); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	var a database.PolicyAttachment
+	json.Unmarshal(rec.Body.Bytes(), &a)
+	rc, err := store.Get(ctx, "attachments/"+p.ID+"/"+a.ID)
+	if err != nil {
+		t.Fatalf("stored attachment: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "%PDF-1.4 fm" || a.Filename != "form.pdf" || a.ContentType != "application/pdf" {
+		t.Errorf("attachment = %+v with %q", a, data)
+	}
+	if _, err := db.GetUpload(ctx, u.ID); err == nil {
+		t.Error("upload still exists after it was attached")
+	}
+}
@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"policyflow/internal/apierr"
 	"policyflow/internal/database"
 	"policyflow/internal/email"
 	mw "policyflow/internal/middleware"
+	"policyflow/internal/tokens"
 )
 
 // User handles user management endpoints (admin-only).
@@ -20,30 +25,64 @@ type User struct {
 	auth   *Auth
 }
 
-func NewUser(db *database.DB, mailer *email.Mailer, jwtSecret string) *User {
+func NewUser(db *database.DB, mailer *email.Mailer, keys *tokens.Keyring) *User {
 	return &User{
 		db:     db,
 		mailer: mailer,
-		auth:   NewAuth(db, mailer, jwtSecret),
+		auth:   NewAuth(db, mailer, keys),
 	}
 }
 
 // List returns all users. SuperAdmin sees all; DeptAdmin sees own department only.
-// GET /api/users
+// ?ids=a,b,c fetches just those users (up to 100).
+// GET /api/users?ids=
 func (h *User) List(c echo.Context) error {
+	ids, err := queryIDs(c, maxQueryIDs)
+	if err != nil {
+		return err
+	}
+	if ids != nil {
+		return h.byIDs(c, ids)
+	}
+	ctx := c.Request().Context()
 	role := c.Get(mw.CtxUserRole).(string)
 	deptID := c.Get(mw.CtxDeptID) // *string or nil
 
 	var users []*database.User
-	var err error
-
 	if role == mw.RoleSuperAdmin || deptID == nil {
-		users, err = h.db.ListUsers()
+		users, err = h.db.ListUsers(ctx)
 	} else {
-		users, err = h.db.ListUsersByDepartment(*deptID.(*string))
+		users, err = h.db.ListUsersByDepartment(ctx, *deptID.(*string))
 	}
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
+	}
+	if users == nil {
+		users = []*database.User{}
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+// Inactive lists active users who have not signed in for ?days= (default
+// 90), including those who never have. DeptAdmin sees their own department.
+// GET /api/admin/users/inactive?days=90
+func (h *User) Inactive(c echo.Context) error {
+	days := 90
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 3650 {
+			return apierr.Invalid("days must be between 1 and 3650", "days")
+		}
+		days = n
+	}
+	var deptID *string
+	if c.Get(mw.CtxUserRole) != mw.RoleSuperAdmin {
+		deptID, _ = c.Get(mw.CtxDeptID).(*string)
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+	users, err := h.db.Reader().ListInactiveUsers(c.Request().Context(), cutoff, deptID)
+	if err != nil {
+		return apierr.Database()
 	}
 	if users == nil {
 		users = []*database.User{}
@@ -54,17 +93,19 @@ func (h *User) List(c echo.Context) error {
 // Create creates a new user and sends them a magic-link welcome email.
 // POST /api/users
 func (h *User) Create(c echo.Context) error {
+	ctx := c.Request().Context()
 	var body struct {
 		Email        string  `json:"email"`
 		Name         string  `json:"name"`
 		Role         string  `json:"role"`
 		DepartmentID *string `json:"department_id"`
+		ManagerID    *string `json:"manager_id"`
 	}
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid request body")
 	}
 	if body.Email == "" || body.Name == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "email and name are required")
+		return apierr.Invalid("email and name are required", "email", "name")
 	}
 	if body.Role == "" {
 		body.Role = mw.RoleStaff
@@ -75,7 +116,7 @@ func (h *User) Create(c echo.Context) error {
 		mw.RoleStaff:      true,
 	}
 	if !validRoles[body.Role] {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+		return apierr.Invalid("invalid role", "role")
 	}
 
 	// DeptAdmin can only create users in their own department.
@@ -83,41 +124,112 @@ func (h *User) Create(c echo.Context) error {
 	if callerRole == mw.RoleDeptAdmin {
 		deptID := c.Get(mw.CtxDeptID)
 		if deptID == nil {
-			return echo.NewHTTPError(http.StatusForbidden, "department admin must belong to a department")
+			return apierr.New(http.StatusForbidden, "DEPARTMENT_REQUIRED", "department admin must belong to a department")
 		}
 		body.DepartmentID = deptID.(*string)
 		// DeptAdmin cannot create SuperAdmin users.
 		if body.Role == mw.RoleSuperAdmin {
-			return echo.NewHTTPError(http.StatusForbidden, "cannot create super admin")
+			return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "cannot create super admin")
+		}
+	}
+
+	if body.ManagerID != nil && *body.ManagerID != "" {
+		if err := h.validateManager(ctx, "", *body.ManagerID); err != nil {
+			return err
 		}
 	}
 
 	creatorID := c.Get(mw.CtxUserID).(string)
-	user, err := h.db.CreateUser(body.Email, body.Name, body.Role, &creatorID, body.DepartmentID)
+	user, err := h.db.CreateUser(ctx, body.Email, body.Name, body.Role, &creatorID, body.DepartmentID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusConflict, "user already exists or database error")
+		return apierr.New(http.StatusConflict, "USER_EXISTS", "user already exists or database error")
 	}
+	if body.ManagerID != nil && *body.ManagerID != "" {
+		if err := h.db.SetUserManager(ctx, user.ID, body.ManagerID); err != nil {
+			return apierr.Database()
+		}
+		user.ManagerID = body.ManagerID
+	}
+	onboardUser(c, h.db, user)
+	// Details carry no personal data, so the entry survives anonymization.
+	mw.LogAudit(c, h.db, database.ActivityUserCreated, "user", user.ID, user.Role)
 
 	// Send welcome email with magic link.
-	magicToken, err := h.auth.BuildMagicTokenForUser(user.Email)
+	magicToken, validFor, err := h.auth.BuildMagicTokenForUser(ctx, user.Email)
 	if err == nil {
 		magicURL := fmt.Sprintf("%s/api/magic-login?token=%s", h.auth.BaseURL(), magicToken)
-		_ = h.mailer.SendNewUserWelcome(user.Email, user.Name, magicURL)
+		_ = h.mailer.SendNewUserWelcome(user.Email, user.Name, magicURL, validFor)
+		_, _ = h.db.RecordLoginEvent(ctx, user.ID, database.LoginEventLinkIssued, "", "")
 	}
 
 	return c.JSON(http.StatusCreated, user)
 }
 
+// Invites a user can be sent in inviteWindow. Every magic link counts,
+// including those the user asked for themselves.
+const (
+	inviteLimit  = 3
+	inviteWindow = time.Hour
+)
+
+// ResendInvite sends the welcome email again with a fresh magic link, for
+// when the first one expired or was lost. DeptAdmin can only resend to
+// their own department.
+// POST /api/users/:id/resend-invite
+func (h *User) ResendInvite(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, err := h.db.GetUserByID(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+		}
+		return apierr.Database()
+	}
+	if c.Get(mw.CtxUserRole) == mw.RoleDeptAdmin {
+		deptID, _ := c.Get(mw.CtxDeptID).(*string)
+		if !sameDept(deptID, user.DepartmentID) {
+			return apierr.New(http.StatusForbidden, "OUTSIDE_DEPARTMENT", "cannot manage users outside your department")
+		}
+	}
+	if user.DeactivatedAt != nil {
+		return apierr.New(http.StatusConflict, "USER_DEACTIVATED", "user is deactivated")
+	}
+	sent, err := h.db.CountLoginEventsSince(ctx, user.ID, database.LoginEventLinkIssued, time.Now().Add(-inviteWindow))
+	if err != nil {
+		return apierr.Database()
+	}
+	if sent >= inviteLimit {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(inviteWindow.Seconds())))
+		return apierr.New(http.StatusTooManyRequests, "INVITE_RATE_LIMITED",
+			fmt.Sprintf("%d sign-in links were sent in the last hour; try again later", sent))
+	}
+
+	magicToken, validFor, err := h.auth.BuildMagicTokenForUser(ctx, user.Email)
+	if err != nil {
+		return apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "token error")
+	}
+	magicURL := fmt.Sprintf("%s/api/magic-login?token=%s", h.auth.BaseURL(), magicToken)
+	if err := h.mailer.SendNewUserWelcome(user.Email, user.Name, magicURL, validFor); err != nil {
+		return apierr.New(http.StatusInternalServerError, "EMAIL_ERROR", "email error")
+	}
+	if _, err := h.db.RecordLoginEvent(ctx, user.ID, database.LoginEventLinkIssued, "", ""); err != nil {
+		return apierr.Database()
+	}
+	mw.LogAudit(c, h.db, database.ActionInviteResent, "user", user.ID, "")
+	return c.NoContent(http.StatusNoContent)
+}
+
 // Update updates an existing user's name, email, role, and department.
 // PUT /api/users/:id  (SuperAdmin only)
 func (h *User) Update(c echo.Context) error {
+	ctx := c.Request().Context()
 	targetID := c.Param("id")
-	target, err := h.db.GetUserByID(targetID)
+	target, err := h.db.GetUserByID(ctx, targetID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+			return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
 	}
 
 	var body struct {
@@ -125,9 +237,10 @@ func (h *User) Update(c echo.Context) error {
 		Email        string  `json:"email"`
 		Role         string  `json:"role"`
 		DepartmentID *string `json:"department_id"`
+		ManagerID    *string `json:"manager_id"` // nil = unchanged, "" = clear
 	}
 	if err := c.Bind(&body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid body")
+		return apierr.New(http.StatusBadRequest, apierr.CodeInvalidBody, "invalid body")
 	}
 
 	// Apply defaults from existing data.
@@ -147,59 +260,206 @@ func (h *User) Update(c echo.Context) error {
 		mw.RoleStaff:      true,
 	}
 	if !validRoles[body.Role] {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+		return apierr.Invalid("invalid role", "role")
 	}
 
 	// Prevent downgrading the last SuperAdmin.
 	if target.Role == mw.RoleSuperAdmin && body.Role != mw.RoleSuperAdmin {
-		count, err := h.db.CountSuperAdmins()
+		count, err := h.db.CountSuperAdmins(ctx)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+			return apierr.Database()
 		}
 		if count <= 1 {
-			return echo.NewHTTPError(http.StatusConflict, "cannot downgrade the last super admin")
+			return apierr.New(http.StatusConflict, "LAST_SUPER_ADMIN", "cannot downgrade the last super admin")
 		}
 	}
 
-	if err := h.db.UpdateUser(targetID, body.Name, body.Email, body.Role, body.DepartmentID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+	if body.ManagerID != nil && *body.ManagerID != "" {
+		if err := h.validateManager(ctx, targetID, *body.ManagerID); err != nil {
+			return err
+		}
 	}
 
-	updated, _ := h.db.GetUserByID(targetID)
+	if err := h.db.UpdateUser(ctx, targetID, body.Name, body.Email, body.Role, body.DepartmentID); err != nil {
+		return apierr.Database()
+	}
+	if body.ManagerID != nil {
+		var managerID *string
+		if *body.ManagerID != "" {
+			managerID = body.ManagerID
+		}
+		if err := h.db.SetUserManager(ctx, targetID, managerID); err != nil {
+			return apierr.Database()
+		}
+	}
+
+	updated, _ := h.db.GetUserByID(ctx, targetID)
+	if updated != nil && updated.DepartmentID != nil && !sameDept(target.DepartmentID, updated.DepartmentID) {
+		onboardUser(c, h.db, updated)
+	}
 	return c.JSON(http.StatusOK, updated)
 }
 
-// Delete removes a user.
-// DELETE /api/users/:id  (SuperAdmin only)
+// Delete removes a user by anonymizing them, which keeps their
+// acknowledgements valid. ?hard=true deletes the row outright, but only for
+// users who have never acknowledged a policy. ?transfer_to= hands the
+// user's policies, open change requests, and report schedules to another
+// active admin in the same operation and returns what moved.
+// DELETE /api/users/:id?hard=&transfer_to=  (SuperAdmin only)
 func (h *User) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
 	targetID := c.Param("id")
 	callerID := c.Get(mw.CtxUserID).(string)
 
 	if targetID == callerID {
-		return echo.NewHTTPError(http.StatusConflict, "cannot delete yourself")
+		return apierr.New(http.StatusConflict, "SELF_DELETION", "cannot delete yourself")
 	}
 
-	target, err := h.db.GetUserByID(targetID)
+	target, err := h.db.GetUserByID(ctx, targetID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+			return apierr.New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+		return apierr.Database()
 	}
 
 	// Prevent deleting the last SuperAdmin.
 	if target.Role == mw.RoleSuperAdmin {
-		count, err := h.db.CountSuperAdmins()
+		count, err := h.db.CountSuperAdmins(ctx)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+			return apierr.Database()
 		}
 		if count <= 1 {
-			return echo.NewHTTPError(http.StatusConflict, "cannot delete the last super admin")
+			return apierr.New(http.StatusConflict, "LAST_SUPER_ADMIN", "cannot delete the last super admin")
 		}
 	}
 
-	if err := h.db.DeleteUser(targetID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "database error")
+	transferTo := c.QueryParam("transfer_to")
+	if transferTo != "" {
+		if err := h.validateNewOwner(ctx, targetID, transferTo); err != nil {
+			return err
+		}
 	}
-	return c.NoContent(http.StatusNoContent)
+
+	if c.QueryParam("hard") != "true" {
+		if target.AnonymizedAt != nil {
+			return apierr.New(http.StatusConflict, "USER_ANONYMIZED", "user is already anonymized")
+		}
+		moved, err := h.db.AnonymizeUser(ctx, targetID, transferTo)
+		if err != nil {
+			return apierr.Database()
+		}
+		mw.LogAudit(c, h.db, database.ActivityUserRemoved, "user", targetID, "anonymized")
+		return removed(c, moved)
+	}
+
+	// Hard deletion would destroy compliance evidence.
+	acks, err := h.db.CountUserAcknowledgements(ctx, targetID)
+	if err != nil {
+		return apierr.Database()
+	}
+	if acks > 0 {
+		return apierr.New(http.StatusConflict, "USER_HAS_ACKNOWLEDGEMENTS", "user has acknowledgements; anonymize instead of deleting")
+	}
+	moved, err := h.db.DeleteUser(ctx, targetID, transferTo)
+	if err != nil {
+		return apierr.Database()
+	}
+	mw.LogAudit(c, h.db, database.ActivityUserRemoved, "user", targetID, "deleted")
+	return removed(c, moved)
+}
+
+// validateNewOwner checks that ownerID can take over userID's content: an
+// active admin other than the user being removed.
+func (h *User) validateNewOwner(ctx context.Context, userID, ownerID string) error {
+	if ownerID == userID {
+		return apierr.Invalid("transfer_to must be another user", "transfer_to")
+	}
+	owner, err := h.db.GetUserByID(ctx, ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apierr.Invalid("transfer_to user not found", "transfer_to")
+	}
+	if err != nil {
+		return apierr.Database()
+	}
+	if owner.DeactivatedAt != nil || (owner.Role != mw.RoleSuperAdmin && owner.Role != mw.RoleDeptAdmin) {
+		return apierr.Invalid("transfer_to must be an active admin", "transfer_to")
+	}
+	return nil
+}
+
+// removed answers a user deletion: 204, or the ownership transfer when one
+// was requested.
+func removed(c echo.Context, moved *database.OwnershipTransfer) error {
+	if moved == nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.JSON(http.StatusOK, moved)
+}
+
+// validateManager checks that managerID exists and that making it userID's
+// manager would not create a reporting cycle. userID is empty for new users.
+func (h *User) validateManager(ctx context.Context, userID, managerID string) error {
+	if managerID == userID {
+		return apierr.New(http.StatusBadRequest, "SELF_MANAGER", "a user cannot be their own manager")
+	}
+	seen := map[string]bool{}
+	for id := managerID; id != ""; {
+		if seen[id] {
+			break // pre-existing cycle; nothing more to learn
+		}
+		seen[id] = true
+		m, err := h.db.GetUserByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return apierr.New(http.StatusBadRequest, "MANAGER_NOT_FOUND", "manager not found")
+			}
+			return apierr.Database()
+		}
+		if m.ManagerID == nil {
+			break
+		}
+		if userID != "" && *m.ManagerID == userID {
+			return apierr.New(http.StatusBadRequest, "MANAGER_CYCLE", "manager assignment would create a reporting cycle")
+		}
+		id = *m.ManagerID
+	}
+	return nil
+}
+
+// ReportsCompliance lists the current user's direct reports with their
+// outstanding acknowledgements, so line managers can follow up with their team.
+// GET /api/me/reports/compliance
+func (h *User) ReportsCompliance(c echo.Context) error {
+	ctx := c.Request().Context()
+	managerID := c.Get(mw.CtxUserID).(string)
+	reports, err := h.db.ListDirectReports(ctx, managerID)
+	if err != nil {
+		return apierr.Database()
+	}
+
+	type reportCompliance struct {
+		User         *database.User  `json:"user"`
+		Outstanding  []pendingPolicy `json:"outstanding"`
+		PendingCount int             `json:"pending_count"`
+		OverdueCount int             `json:"overdue_count"`
+	}
+	now := time.Now().UTC()
+	result := make([]reportCompliance, 0, len(reports))
+	for _, u := range reports {
+		pending, err := h.db.ListPendingPoliciesForUser(ctx, u.ID, u.Role, u.DepartmentID)
+		if err != nil {
+			return apierr.Database()
+		}
+		rc := reportCompliance{User: u, Outstanding: make([]pendingPolicy, len(pending))}
+		for i, p := range pending {
+			rc.Outstanding[i] = newPendingPolicy(p, now)
+			if rc.Outstanding[i].Overdue {
+				rc.OverdueCount++
+			}
+		}
+		rc.PendingCount = len(pending)
+		result = append(result, rc)
+	}
+	return c.JSON(http.StatusOK, result)
 }
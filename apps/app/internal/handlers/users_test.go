@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/notify"
+	"policyflow/internal/testutil"
+)
+
+// TestDelete_AnonymizesAndKeepsAcknowledgements verifies that removing a
+// user scrubs their identity but keeps the acknowledgement evidence, and
+// that a hard delete is refused while evidence exists.
+func TestDelete_AnonymizesAndKeepsAcknowledgements(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada Lovelace", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Privacy", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	ack, _ := db.CreateAcknowledgement(ctx, ada.ID, *p.CurrentVersionID)
+
+	e := echo.New()
+	h := NewUser(db, email.New(), testutil.NewKeyring(t, db))
+	del := func(query string) error {
+		c, _ := testutil.NewContext(e, http.MethodDelete, "", ada.ID, mw.RoleSuperAdmin, nil)
+		c.Request().URL.RawQuery = query
+		return h.Delete(c)
+	}
+
+	var he *echo.HTTPError
+	if err := del("hard=true"); !errors.As(err, &he) || he.Code != http.StatusConflict {
+		t.Fatalf("hard delete with acknowledgements: got %v; want 409", err)
+	}
+	if err := del(""); err != nil {
+		t.Fatalf("anonymize: %v", err)
+	}
+
+	u, err := db.GetUserByID(ctx, ada.ID)
+	if err != nil {
+		t.Fatalf("user row removed: %v", err)
+	}
+	if u.AnonymizedAt == nil || u.DeactivatedAt == nil || strings.Contains(u.Name+u.Email, "Ada") || strings.Contains(u.Email, "example.com") {
+		t.Errorf("user not anonymized: %+v", u)
+	}
+	acks, _ := db.ListUserAcknowledgements(ctx, ada.ID)
+	if len(acks) != 1 || acks[0].SignatureHash != ack.SignatureHash {
+		t.Errorf("acknowledgements = %+v; want the original one", acks)
+	}
+}
+
+// TestDelete_TransfersOwnership verifies that transfer_to hands a removed
+// author's policies, drafts, and pending reviews to another admin in the
+// same request.
+func TestDelete_TransfersOwnership(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	author, _ := db.CreateUser(ctx, "author@example.com", "Author", mw.RoleDeptAdmin, nil, nil)
+	heir, _ := db.CreateUser(ctx, "heir@example.com", "Heir", mw.RoleSuperAdmin, nil, nil)
+	staff, _ := db.CreateUser(ctx, "staff@example.com", "Staff", mw.RoleStaff, nil, nil)
+	live, _ := db.CreatePolicy(ctx, "Live", "", nil, "organization", &author.ID)
+	testutil.Publish(t, db, live)
+	draft, _ := db.CreatePolicy(ctx, "Draft", "", nil, "organization", &author.ID)
+	db.CreatePolicyException(ctx, live.ID, staff.ID, "on leave", 30)
+	db.CreateChangeRequest(ctx, live.ID, nil, "Introduction", "typo", &author.ID)
+
+	e := echo.New()
+	h := NewUser(db, email.New(), testutil.NewKeyring(t, db))
+	del := func(query string) (*httptest.ResponseRecorder, error) {
+		c, rec := testutil.NewContext(e, http.MethodDelete, "", author.ID, mw.RoleSuperAdmin, nil)
+		c.Request().URL.RawQuery = query
+		return rec, h.Delete(c)
+	}
+
+	var he *echo.HTTPError
+	if _, err := del("transfer_to=" + staff.ID); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("transfer to staff: err = %v; want 400", err)
+	}
+	rec, err := del("transfer_to=" + heir.ID)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	var moved database.OwnershipTransfer
+	json.Unmarshal(rec.Body.Bytes(), &moved)
+	if moved != (database.OwnershipTransfer{Policies: 2, Drafts: 1, PendingReviews: 1, ChangeRequests: 1}) {
+		t.Errorf("transfer = %+v", moved)
+	}
+	for _, id := range []string{live.ID, draft.ID} {
+		if p, _ := db.GetPolicy(ctx, id); p.CreatedBy == nil || *p.CreatedBy != heir.ID {
+			t.Errorf("policy %s owner = %v; want %s", p.Title, p.CreatedBy, heir.ID)
+		}
+	}
+}
+
+// TestInactive_ListsStaleAndNeverLoggedIn verifies the inactive-user report
+// puts accounts that never signed in first, skips deactivated ones, and
+// gives new accounts a grace period.
+func TestInactive_ListsStaleAndNeverLoggedIn(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	seen, _ := db.CreateUser(ctx, "seen@example.com", "Seen", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	never, _ := db.CreateUser(ctx, "never@example.com", "Never", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	gone, _ := db.CreateUser(ctx, "gone@example.com", "Gone", mw.RoleStaff, nil, testutil.Ptr(eng.ID))
+	db.CreateUser(ctx, "other@example.com", "Other", mw.RoleStaff, nil, nil)
+	db.RecordLogin(ctx, seen.ID)
+	db.DeactivateUser(ctx, gone.ID)
+
+	// Everyone is idle as of an hour from now.
+	users, err := db.ListInactiveUsers(ctx, time.Now().Add(time.Hour), testutil.Ptr(eng.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].ID != never.ID || users[1].ID != seen.ID {
+		t.Errorf("inactive = %v; want never, then seen", users)
+	}
+
+	e := echo.New()
+	h := NewUser(db, email.New(), testutil.NewKeyring(t, db))
+	c, rec := testutil.NewContext(e, http.MethodGet, "", "", mw.RoleDeptAdmin, testutil.Ptr(eng.ID))
+	c.Request().URL.RawQuery = "days=1"
+	if err := h.Inactive(c); err != nil || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("days=1: body %s, err %v; want no new accounts", rec.Body.String(), err)
+	}
+	c, _ = testutil.NewContext(e, http.MethodGet, "", "", mw.RoleDeptAdmin, testutil.Ptr(eng.ID))
+	c.Request().URL.RawQuery = "days=0"
+	var he *echo.HTTPError
+	if err := h.Inactive(c); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("days=0: err = %v; want 400", err)
+	}
+}
+
+// TestRequire_SeesUserChangesDespiteCache verifies that moving or
+// deactivating a user takes effect on their next request, although the
+// auth middleware caches users between requests.
+func TestRequire_SeesUserChangesDespiteCache(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	fin, _ := db.CreateDepartment(ctx, "Finance", "")
+	sam, _ := db.CreateUser(ctx, "sam@example.com", "Sam", mw.RoleStaff, nil, testutil.Ptr(ops.ID))
+
+	e := echo.New()
+	keys := testutil.NewKeyring(t, db)
+	auth := mw.NewAuth(keys, db)
+	token, _ := keys.Sign(jwt.MapClaims{
+		"sub": sam.ID, "role": sam.Role, "type": "session",
+		"iat": time.Now().Unix(), "auth_time": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	call := func() (*string, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		var got *string
+		err := auth.Require(func(c echo.Context) error {
+			got, _ = c.Get(mw.CtxDeptID).(*string)
+			return nil
+		})(e.NewContext(req, httptest.NewRecorder()))
+		return got, err
+	}
+
+	if got, err := call(); err != nil || got == nil || *got != ops.ID {
+		t.Fatalf("first request: dept %v, err %v; want Operations", got, err)
+	}
+	db.UpdateUser(ctx, sam.ID, sam.Name, sam.Email, sam.Role, testutil.Ptr(fin.ID))
+	if got, err := call(); err != nil || got == nil || *got != fin.ID {
+		t.Errorf("after move: dept %v, err %v; want Finance", got, err)
+	}
+	db.DeactivateUser(ctx, sam.ID)
+	var he *echo.HTTPError
+	if _, err := call(); !errors.As(err, &he) || he.Code != http.StatusUnauthorized {
+		t.Errorf("after deactivation: err = %v; want 401", err)
+	}
+}
+
+// TestResendInvite_RateLimitedAndAudited verifies that an admin can resend
+// the welcome email a few times an hour, that DeptAdmins are kept to their
+// department, and that each resend is audited.
+func TestResendInvite_RateLimitedAndAudited(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	fin, _ := db.CreateDepartment(ctx, "Finance", "")
+	admin, _ := db.CreateUser(ctx, "root@example.com", "Root", mw.RoleSuperAdmin, nil, nil)
+	sam, _ := db.CreateUser(ctx, "sam@example.com", "Sam", mw.RoleStaff, nil, testutil.Ptr(ops.ID))
+
+	e := echo.New()
+	h := NewUser(db, email.New(), testutil.NewKeyring(t, db))
+	resend := func(role string, deptID *string) (*httptest.ResponseRecorder, error) {
+		c, rec := testutil.NewContext(e, http.MethodPost, "", sam.ID, role, deptID)
+		c.Set(mw.CtxUserID, admin.ID)
+		return rec, h.ResendInvite(c)
+	}
+
+	var he *echo.HTTPError
+	if _, err := resend(mw.RoleDeptAdmin, testutil.Ptr(fin.ID)); !errors.As(err, &he) || he.Code != http.StatusForbidden {
+		t.Errorf("DeptAdmin of another department: err = %v; want 403", err)
+	}
+	for i := range inviteLimit {
+		if rec, err := resend(mw.RoleDeptAdmin, testutil.Ptr(ops.ID)); err != nil || rec.Code != http.StatusNoContent {
+			t.Fatalf("resend %d: %d, %v", i+1, rec.Code, err)
+		}
+	}
+	rec, err := resend(mw.RoleSuperAdmin, nil)
+	if !errors.As(err, &he) || he.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("resend over the limit: err = %v, Retry-After %q; want 429 with Retry-After", err, rec.Header().Get("Retry-After"))
+	}
+
+	entries, _ := db.ListAuditLog(ctx, database.AuditFilter{Action: database.ActionInviteResent})
+	if len(entries) != inviteLimit || entries[0].TargetID != sam.ID {
+		t.Errorf("audit entries = %d; want %d naming Sam", len(entries), inviteLimit)
+	}
+}
+
+// TestUpdate_MovingDepartmentSendsOneWelcome verifies a user moved into a
+// department gets one email listing every policy awaiting them, and that a
+// second move before it is sent replaces the first.
+func TestUpdate_MovingDepartmentSendsOneWelcome(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	ops, _ := db.CreateDepartment(ctx, "Operations", "")
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", mw.RoleStaff, nil, nil)
+	for _, title := range []string{"On-call", "Code Review"} {
+		p, _ := db.CreatePolicy(ctx, title, "", &eng.ID, "department", nil)
+		testutil.Publish(t, db, p)
+	}
+	p, _ := db.CreatePolicy(ctx, "Forklifts", "", &ops.ID, "department", nil)
+	testutil.Publish(t, db, p)
+
+	mailer := email.New()
+	outbox := mailer.Capture()
+	h := NewUser(db, mailer, testutil.NewKeyring(t, db))
+	move := func(deptID string) {
+		t.Helper()
+		c, _ := testutil.NewContext(echo.New(), http.MethodPut, `{"department_id":"`+deptID+`"}`, ada.ID, mw.RoleSuperAdmin, nil)
+		if err := h.Update(c); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	move(ops.ID)
+	move(eng.ID)
+	runner := notify.NewRunner(db, mailer, "https://policyflow.test")
+	if err := runner.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	msgs := outbox.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("sent %d emails; want one welcome", len(msgs))
+	}
+	for _, want := range []string{"Engineering", "On-call", "Code Review"} {
+		if !strings.Contains(msgs[0].Subject+msgs[0].Body, want) {
+			t.Errorf("welcome missing %q:\n%s", want, msgs[0].Body)
+		}
+	}
+	if strings.Contains(msgs[0].Body, "Forklifts") {
+		t.Error("welcome lists a policy of the department the user left")
+	}
+
+	// Saving the user without moving them queues nothing more.
+	move(eng.ID)
+	runner.Run(ctx)
+	if n := len(outbox.Messages()); n != 1 {
+		t.Errorf("sent %d emails after an update that kept the department; want 1", n)
+	}
+}
+
+// TestValidateManager_RefusesCycles verifies that a manager must exist and
+// that no chain of managers may lead back to the user, while a user joining
+// an existing chain is accepted.
+func TestValidateManager_RefusesCycles(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	ceo, _ := db.CreateUser(ctx, "ceo@example.com", "CEO", mw.RoleStaff, nil, nil)
+	vp, _ := db.CreateUser(ctx, "vp@example.com", "VP", mw.RoleStaff, nil, nil)
+	lead, _ := db.CreateUser(ctx, "lead@example.com", "Lead", mw.RoleStaff, nil, nil)
+	db.SetUserManager(ctx, vp.ID, &ceo.ID)
+	db.SetUserManager(ctx, lead.ID, &vp.ID)
+	h := NewUser(db, email.New(), testutil.NewKeyring(t, db))
+
+	cases := []struct {
+		name          string
+		user, manager string
+		code          string // "" when accepted
+	}{
+		{"new user under a chain", "", lead.ID, ""},
+		{"existing user given the same manager", vp.ID, ceo.ID, ""},
+		{"self", lead.ID, lead.ID, "SELF_MANAGER"},
+		{"direct cycle", vp.ID, lead.ID, "MANAGER_CYCLE"},
+		{"indirect cycle", ceo.ID, lead.ID, "MANAGER_CYCLE"},
+		{"unknown manager", lead.ID, "nobody", "MANAGER_NOT_FOUND"},
+	}
+	for _, tc := range cases {
+		err := h.validateManager(ctx, tc.user, tc.manager)
+		if tc.code == "" {
+			if err != nil {
+				t.Errorf("%s: err = %v; want accepted", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || apierr.From(err).Code != tc.code {
+			t.Errorf("%s: err = %v; want %s", tc.name, err, tc.code)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/pdftext"
+	"policyflow/internal/storage"
+)
+
+// UploadPDFVersion adds a version whose canonical text is an uploaded PDF.
+// The file is kept in blob storage and served to readers as is; its
+// extracted text becomes the version's content, so it can be searched,
+// diffed, and read in the app.
+// POST /api/policies/:id/versions/pdf (multipart: file or upload_id,
+// version_string, changelog, effective_from, effective_to)
+func (h *Policy) UploadPDFVersion(c echo.Context) error {
+	ctx := c.Request().Context()
+	if h.store == nil {
+		return apierr.New(http.StatusServiceUnavailable, "STORAGE_NOT_CONFIGURED", "file storage is not configured")
+	}
+	policy, err := h.versionablePolicy(c)
+	if err != nil {
+		return err
+	}
+	versionString := c.FormValue("version_string")
+	if versionString == "" {
+		return apierr.Invalid("version_string is required", "version_string")
+	}
+	window, err := parseEffectiveWindow(c.FormValue("effective_from"), c.FormValue("effective_to"))
+	if err != nil {
+		return err
+	}
+	f, err := h.incomingFile(c, h.maxUpload)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	magic := make([]byte, 5)
+	if _, err := f.ReadAt(magic, 0); err != nil || string(magic) != "%PDF-" {
+		return apierr.New(http.StatusBadRequest, "UNSUPPORTED_FILE_TYPE", "file is not a PDF")
+	}
+	if err := h.scanUpload(c, database.UploadPDFVersion, &policy.ID, f.Filename, f); err != nil {
+		return err
+	}
+	text, err := pdftext.Extract(f, f.Size)
+	if err != nil {
+		return apierr.New(http.StatusBadRequest, "UNREADABLE_FILE", "PDF could not be read; it may be damaged or encrypted")
+	}
+
+	hash := sha256.New()
+	src := &database.VersionSource{
+		Filename:    f.Filename,
+		ContentType: "application/pdf",
+		SizeBytes:   f.Size,
+	}
+	src.StorageKey = "versions/" + policy.ID + "/" + uuid.New().String() + ".pdf"
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return apierr.New(http.StatusBadRequest, "INVALID_UPLOAD", "invalid upload")
+	}
+	if err := h.store.Put(ctx, src.StorageKey, io.TeeReader(f, hash), src.SizeBytes, src.ContentType); err != nil {
+		log.Printf("version file upload: %v", err)
+		return apierr.New(http.StatusBadGateway, "STORAGE_ERROR", "storage error")
+	}
+	src.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	changes := strings.TrimSpace(c.FormValue("changelog"))
+	if changes == "" {
+		changes = "Uploaded " + src.Filename
+	}
+	version, err := h.addVersion(c, policy, text, versionString, changes, src, window)
+	if err != nil {
+		_ = h.store.Delete(ctx, src.StorageKey)
+		return err
+	}
+	f.Done()
+
+	warnings := []string{}
+	if strings.TrimSpace(text) == "" {
+		warnings = append(warnings, "no text could be extracted; the PDF may be a scan without a text layer")
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"version":  version,
+		"warnings": warnings,
+	})
+}
+
+// VersionFile serves the original file of an uploaded version inline.
+// Fetching the current version's file counts as opening the policy for
+// the reading requirements checked on acknowledgement, except when an
+// impersonating SuperAdmin fetches it.
+// GET /api/policies/:id/versions/:versionId/file
+func (h *Policy) VersionFile(c echo.Context) error {
+	ctx := c.Request().Context()
+	policy, err := h.visiblePolicy(c)
+	if err != nil {
+		return err
+	}
+	version, err := h.db.GetPolicyVersion(ctx, c.Param("versionId"))
+	if err != nil || version.PolicyID != policy.ID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(http.StatusNotFound, "VERSION_NOT_FOUND", "version not found")
+		}
+		return apierr.Database()
+	}
+	if version.Source == nil || h.store == nil {
+		return apierr.New(http.StatusNotFound, "FILE_NOT_FOUND", "version has no original file")
+	}
+	rc, err := h.store.Get(ctx, version.Source.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return apierr.New(http.StatusNotFound, "FILE_NOT_FOUND", "version has no original file")
+		}
+		log.Printf("version file download: %v", err)
+		return apierr.New(http.StatusBadGateway, "STORAGE_ERROR", "storage error")
+	}
+	defer rc.Close()
+
+	_, impersonating := c.Get(mw.CtxImpersonatorID).(string)
+	if !impersonating && policy.CurrentVersionID != nil && *policy.CurrentVersionID == version.ID {
+		userID := c.Get(mw.CtxUserID).(string)
+		if err := h.db.RecordReadEvent(ctx, userID, version.ID, database.ReadEventOpen); err != nil {
+			log.Printf("record read of %s: %v", version.ID, err)
+		}
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("inline", map[string]string{"filename": version.Source.Filename}))
+	c.Response().Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	return c.Stream(http.StatusOK, version.Source.ContentType, rc)
+}
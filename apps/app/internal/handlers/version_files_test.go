@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/storage"
+	"policyflow/internal/testutil"
+)
+
+// TestUploadPDFVersion stores the original, indexes its text as content, and
+// serves the file back while counting it as an open for acknowledgement,
+// unless an impersonating SuperAdmin is the one fetching it.
+func TestUploadPDFVersion(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	admin, _ := db.CreateUser(ctx, "admin@example.com", "Admin", mw.RoleSuperAdmin, nil, nil)
+	reader, _ := db.CreateUser(ctx, "staff@example.com", "Staff", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Remote Work", "", nil, "organization", nil)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	e := echo.New()
+	h := NewPolicy(db)
+	h.SetStore(store)
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(20, 20, "Staff may work remotely two days a week.")
+	var file bytes.Buffer
+	if err := pdf.Output(&file); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	mp := multipart.NewWriter(&buf)
+	mp.WriteField("version_string", "v2.0.0")
+	fw, _ := mp.CreateFormFile("file", "remote-work.pdf")
+	fw.Write(file.Bytes())
+	mp.Close()
+	req := httptest.NewRequest(http.MethodPost, "/", &buf)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(p.ID)
+	c.Set(mw.CtxUserRole, mw.RoleSuperAdmin)
+	c.Set(mw.CtxUserID, admin.ID)
+	if err := h.UploadPDFVersion(c); err != nil {
+		t.Fatalf("UploadPDFVersion: %v", err)
+	}
+	var res struct {
+		Version database.PolicyVersion `json:"version"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	v, err := db.GetPolicyVersion(ctx, res.Version.ID)
+	if err != nil {
+		t.Fatalf("GetPolicyVersion: %v", err)
+	}
+	if !strings.Contains(v.Content, "work remotely two days") || v.Source == nil || v.Source.Filename != "remote-work.pdf" {
+		t.Fatalf("version = %+v, source %+v", v, v.Source)
+	}
+
+	testutil.Publish(t, db, p) // adds a plain version; make the PDF current again
+	if err := db.SetPolicyCurrentVersion(ctx, p.ID, v.ID); err != nil {
+		t.Fatal(err)
+	}
+	c, _ = testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleStaff, nil)
+	c.SetParamNames("id", "versionId")
+	c.SetParamValues(p.ID, v.ID)
+	c.Set(mw.CtxUserID, reader.ID)
+	c.Set(mw.CtxImpersonatorID, admin.ID)
+	if err := h.VersionFile(c); err != nil {
+		t.Fatalf("VersionFile impersonated: %v", err)
+	}
+	if status, _ := db.GetReadStatus(ctx, reader.ID, v.ID); status.FirstOpenedAt != nil {
+		t.Error("an impersonated fetch recorded an open for the user")
+	}
+
+	c, rec = testutil.NewContext(e, http.MethodGet, "", p.ID, mw.RoleStaff, nil)
+	c.SetParamNames("id", "versionId")
+	c.SetParamValues(p.ID, v.ID)
+	c.Set(mw.CtxUserID, reader.ID)
+	if err := h.VersionFile(c); err != nil {
+		t.Fatalf("VersionFile: %v", err)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), file.Bytes()) || rec.Header().Get(echo.HeaderContentType) != "application/pdf" {
+		t.Errorf("served %d bytes as %q", rec.Body.Len(), rec.Header().Get(echo.HeaderContentType))
+	}
+	status, _ := db.GetReadStatus(ctx, reader.ID, v.ID)
+	if status.FirstOpenedAt == nil {
+		t.Error("serving the current version's file did not record an open")
+	}
+}
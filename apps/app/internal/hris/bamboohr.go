@@ -0,0 +1,90 @@
+package hris
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// BambooHR pulls the roster through a custom report on the BambooHR API.
+// The division is read as the employee's OU.
+type BambooHR struct {
+	Subdomain string
+	APIKey    string
+	Client    *http.Client // optional; defaults to http.DefaultClient
+}
+
+func (p *BambooHR) Name() string { return "bamboohr" }
+
+func (p *BambooHR) FetchEmployees(ctx context.Context) ([]Employee, error) {
+	reqBody, _ := json.Marshal(map[string]any{
+		"title":  "PolicyFlow sync",
+		"fields": []string{"id", "firstName", "lastName", "workEmail", "department", "division", "jobTitle", "hireDate", "terminationDate"},
+	})
+	url := fmt.Sprintf("https://api.bamboohr.com/api/gateway.php/%s/v1/reports/custom?format=JSON&onlyCurrent=false", p.Subdomain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(p.APIKey, "x")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("bamboohr: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bamboohr: %s", resp.Status)
+	}
+
+	var report struct {
+		Employees []map[string]any `json:"employees"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("bamboohr: decode report: %w", err)
+	}
+
+	out := make([]Employee, 0, len(report.Employees))
+	for _, r := range report.Employees {
+		e := Employee{
+			ExternalID: str(r["id"]),
+			Email:      str(r["workEmail"]),
+			Name:       strings.TrimSpace(str(r["firstName"]) + " " + str(r["lastName"])),
+			Department: str(r["department"]),
+			OU:         str(r["division"]),
+			JobTitle:   str(r["jobTitle"]),
+		}
+		if e.StartDate, err = parseDate(str(r["hireDate"])); err != nil {
+			return nil, fmt.Errorf("bamboohr employee %s: %w", e.ExternalID, err)
+		}
+		if e.TerminationDate, err = parseDate(str(r["terminationDate"])); err != nil {
+			return nil, fmt.Errorf("bamboohr employee %s: %w", e.ExternalID, err)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// str renders a decoded JSON scalar as a string; BambooHR returns IDs as
+// either numbers or strings depending on the endpoint.
+func str(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return fmt.Sprintf("%.0f", t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
@@ -0,0 +1,113 @@
+package hris
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// CSVProvider reads a roster export with a header row containing
+// employee_id, email, name, department, start_date, and termination_date,
+// and optionally ou, job_title, and groups (separated by ";"), in any order
+// and case-insensitive. Source is a local path — typically a file
+// dropped by an SFTP transfer job — or an http(s) URL.
+type CSVProvider struct {
+	Source string
+}
+
+func (p *CSVProvider) Name() string { return "csv" }
+
+func (p *CSVProvider) FetchEmployees(ctx context.Context) ([]Employee, error) {
+	r, err := p.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return parseCSV(r)
+}
+
+func (p *CSVProvider) open(ctx context.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(p.Source, "http://") && !strings.HasPrefix(p.Source, "https://") {
+		return os.Open(p.Source)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetch roster: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func parseCSV(r io.Reader) ([]Employee, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"employee_id", "email", "name"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var out []Employee
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		e := Employee{
+			ExternalID: field(rec, "employee_id"),
+			Email:      field(rec, "email"),
+			Name:       field(rec, "name"),
+			Department: field(rec, "department"),
+			OU:         field(rec, "ou"),
+			JobTitle:   field(rec, "job_title"),
+			Groups:     splitGroups(field(rec, "groups")),
+		}
+		if e.StartDate, err = parseDate(field(rec, "start_date")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if e.TerminationDate, err = parseDate(field(rec, "termination_date")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// splitGroups splits a ";"-separated group list, dropping empty entries.
+func splitGroups(s string) []string {
+	var out []string
+	for g := range strings.SplitSeq(s, ";") {
+		if g = strings.TrimSpace(g); g != "" {
+			out = append(out, g)
+		}
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+// Package hris synchronises PolicyFlow accounts with an external HR
+// information system: new hires get accounts, transfers move department, and
+// leavers are deactivated on their termination date.
+package hris
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Employee is a normalised HRIS record. Department is matched to a
+// PolicyFlow department by name unless a claim rule assigns one; OU,
+// JobTitle, and Groups are only read by claim rules.
+type Employee struct {
+	ExternalID      string
+	Email           string
+	Name            string
+	Department      string
+	OU              string
+	JobTitle        string
+	Groups          []string
+	StartDate       *time.Time
+	TerminationDate *time.Time
+}
+
+// Provider fetches the current employee roster from an HRIS.
+type Provider interface {
+	Name() string
+	FetchEmployees(ctx context.Context) ([]Employee, error)
+}
+
+// FromEnv builds the provider selected by HRIS_PROVIDER. It returns nil, nil
+// when no provider is configured.
+func FromEnv() (Provider, error) {
+	switch p := os.Getenv("HRIS_PROVIDER"); p {
+	case "":
+		return nil, nil
+	case "csv":
+		src := os.Getenv("HRIS_CSV_SOURCE")
+		if src == "" {
+			return nil, fmt.Errorf("HRIS_CSV_SOURCE is required for the csv provider")
+		}
+		return &CSVProvider{Source: src}, nil
+	case "bamboohr":
+		sub, key := os.Getenv("BAMBOOHR_SUBDOMAIN"), os.Getenv("BAMBOOHR_API_KEY")
+		if sub == "" || key == "" {
+			return nil, fmt.Errorf("BAMBOOHR_SUBDOMAIN and BAMBOOHR_API_KEY are required for the bamboohr provider")
+		}
+		return &BambooHR{Subdomain: sub, APIKey: key}, nil
+	case "workday":
+		u := os.Getenv("WORKDAY_REPORT_URL")
+		if u == "" {
+			return nil, fmt.Errorf("WORKDAY_REPORT_URL is required for the workday provider")
+		}
+		return &Workday{
+			ReportURL: u,
+			Username:  os.Getenv("WORKDAY_USERNAME"),
+			Password:  os.Getenv("WORKDAY_PASSWORD"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown HRIS_PROVIDER %q (want csv, bamboohr, or workday)", p)
+	}
+}
+
+// parseDate parses the YYYY-MM-DD dates HR systems export. Empty or
+// zero-valued dates ("0000-00-00" in BambooHR) yield nil.
+func parseDate(s string) (*time.Time, error) {
+	if s == "" || s == "0000-00-00" {
+		return nil, nil
+	}
+	if len(s) > 10 {
+		s = s[:10] // tolerate trailing time or zone, e.g. Workday's "2024-01-31-08:00"
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", s)
+	}
+	return &t, nil
+}
@@ -0,0 +1,93 @@
+package hris
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"policyflow/internal/database"
+)
+
+// Rules are the organization's claim rules, in the order they are tried.
+type Rules []*database.ClaimRule
+
+// LoadRules reads the claim rules, once per sync run.
+func LoadRules(ctx context.Context, db *database.DB) (Rules, error) {
+	return db.ListClaimRules(ctx)
+}
+
+// Assignment is what the claim rules decide for one employee.
+type Assignment struct {
+	Role         string   `json:"role"`          // "" = no rule sets the role
+	DepartmentID *string  `json:"department_id"` // nil = no rule sets the department
+	Matched      []string `json:"matched"`       // IDs of every rule that matched, in order
+}
+
+// Resolve applies the rules to e: the first matching rule that sets a role
+// decides the role, and likewise for the department.
+func (rs Rules) Resolve(e Employee) Assignment {
+	a := Assignment{Matched: []string{}}
+	for _, r := range rs {
+		if !RuleMatches(r, e) {
+			continue
+		}
+		a.Matched = append(a.Matched, r.ID)
+		if a.Role == "" && r.Role != nil {
+			a.Role = *r.Role
+		}
+		if a.DepartmentID == nil && r.DepartmentID != nil {
+			a.DepartmentID = r.DepartmentID
+		}
+	}
+	return a
+}
+
+// RuleMatches reports whether e's attribute satisfies r. A rule with an
+// invalid pattern matches nothing.
+func RuleMatches(r *database.ClaimRule, e Employee) bool {
+	var values []string
+	switch r.Attribute {
+	case database.ClaimDepartment:
+		values = []string{e.Department}
+	case database.ClaimOU:
+		values = []string{e.OU}
+	case database.ClaimJobTitle:
+		values = []string{e.JobTitle}
+	case database.ClaimGroup:
+		values = e.Groups
+	}
+	var re *regexp.Regexp
+	if r.Operator == database.ClaimRegex {
+		var err error
+		if re, err = regexp.Compile(r.Value); err != nil {
+			return false
+		}
+	}
+	want := strings.ToLower(r.Value)
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		lv := strings.ToLower(v)
+		switch r.Operator {
+		case database.ClaimEquals:
+			if lv == want {
+				return true
+			}
+		case database.ClaimContains:
+			if strings.Contains(lv, want) {
+				return true
+			}
+		case database.ClaimPrefix:
+			if strings.HasPrefix(lv, want) {
+				return true
+			}
+		case database.ClaimRegex:
+			if re.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
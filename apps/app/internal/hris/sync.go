@@ -0,0 +1,304 @@
+package hris
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"policyflow/internal/database"
+)
+
+// ErrSyncRunning is returned when a sync is requested while one is in progress.
+var ErrSyncRunning = errors.New("hris sync already running")
+
+// Outcome describes what applying one employee record did to PolicyFlow.
+type Outcome string
+
+const (
+	OutcomeCreated     Outcome = "created"
+	OutcomeUpdated     Outcome = "updated"
+	OutcomeDeactivated Outcome = "deactivated"
+	OutcomeUnchanged   Outcome = "unchanged"
+	OutcomeSkipped     Outcome = "skipped"
+)
+
+// Syncer applies a provider's roster to the users table and logs each run.
+type Syncer struct {
+	db       *database.DB
+	provider Provider
+	mu       sync.Mutex
+}
+
+func NewSyncer(db *database.DB, provider Provider) *Syncer {
+	return &Syncer{db: db, provider: provider}
+}
+
+func (s *Syncer) ProviderName() string { return s.provider.Name() }
+
+// Run fetches the roster and applies every record. triggeredBy is the user ID
+// for manual runs and nil for scheduled ones. Individual record failures are
+// logged and the run is marked partial rather than aborted.
+func (s *Syncer) Run(ctx context.Context, triggeredBy *string) (*database.HRISSyncRun, error) {
+	if !s.mu.TryLock() {
+		return nil, ErrSyncRunning
+	}
+	defer s.mu.Unlock()
+
+	run, err := s.db.CreateHRISSyncRun(ctx, s.provider.Name(), triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sync(ctx, run); err != nil {
+		return nil, err
+	}
+	if err := s.db.FinishHRISSyncRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Preview works out what Run would do without changing anything. The run it
+// returns, with DryRun set, is not stored.
+func (s *Syncer) Preview(ctx context.Context, triggeredBy *string) (*database.HRISSyncRun, error) {
+	run := &database.HRISSyncRun{Provider: s.provider.Name(), TriggeredBy: triggeredBy, StartedAt: time.Now().UTC(), DryRun: true}
+	if err := s.sync(ctx, run); err != nil {
+		return nil, err
+	}
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	return run, nil
+}
+
+// sync fetches the roster and applies it, or with run.DryRun only checks
+// it, filling in run's counts, status, and log.
+func (s *Syncer) sync(ctx context.Context, run *database.HRISSyncRun) error {
+	var lines []string
+	employees, err := s.provider.FetchEmployees(ctx)
+	if err != nil {
+		run.Status = "failed"
+		run.Log = "fetch failed: " + err.Error()
+		return nil
+	}
+
+	rules, err := LoadRules(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	failures := 0
+	for _, e := range employees {
+		outcome, note, err := applyEmployee(ctx, s.db, e, rules, now, run.DryRun)
+		if err != nil {
+			failures++
+			lines = append(lines, fmt.Sprintf("error %s (%s): %v", e.Email, e.ExternalID, err))
+			continue
+		}
+		switch outcome {
+		case OutcomeCreated:
+			run.Created++
+		case OutcomeUpdated:
+			run.Updated++
+		case OutcomeDeactivated:
+			run.Deactivated++
+		case OutcomeSkipped:
+			run.Skipped++
+		}
+		if outcome != OutcomeUnchanged {
+			line := fmt.Sprintf("%s %s (%s)", outcome, e.Email, e.ExternalID)
+			if note != "" {
+				line += ": " + note
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	run.Status = "success"
+	if failures > 0 {
+		run.Status = "partial"
+	}
+	run.Log = strings.Join(lines, "\n")
+	if !run.DryRun {
+		log.Printf("HRIS sync (%s): created=%d updated=%d deactivated=%d skipped=%d errors=%d",
+			run.Provider, run.Created, run.Updated, run.Deactivated, run.Skipped, failures)
+	}
+	return nil
+}
+
+// Schedule runs the sync every interval until ctx is cancelled.
+func (s *Syncer) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Run(ctx, nil); err != nil && !errors.Is(err, ErrSyncRunning) {
+				log.Printf("HRIS sync error: %v", err)
+			}
+		}
+	}
+}
+
+// ApplyEmployee reconciles one HRIS record with PolicyFlow: it creates the
+// account for a new hire, moves department on transfer, and deactivates the
+// account once the termination date has passed. Claim rules that match the
+// record set its role and department; they never change a SuperAdmin's
+// role. The returned note explains skips and non-fatal issues such as an
+// unknown department.
+func ApplyEmployee(ctx context.Context, db *database.DB, e Employee, rules Rules, now time.Time) (Outcome, string, error) {
+	return applyEmployee(ctx, db, e, rules, now, false)
+}
+
+// PreviewEmployee reports what ApplyEmployee would do without changing
+// anything.
+func PreviewEmployee(ctx context.Context, db *database.DB, e Employee, rules Rules, now time.Time) (Outcome, string, error) {
+	return applyEmployee(ctx, db, e, rules, now, true)
+}
+
+func applyEmployee(ctx context.Context, db *database.DB, e Employee, rules Rules, now time.Time, dryRun bool) (Outcome, string, error) {
+	e.Email = strings.ToLower(strings.TrimSpace(e.Email))
+	if e.Email == "" {
+		return OutcomeSkipped, "no email address", nil
+	}
+	terminated := e.TerminationDate != nil && !e.TerminationDate.After(now)
+
+	user, err := findUser(ctx, db, e)
+	if err != nil {
+		return "", "", err
+	}
+
+	assigned := rules.Resolve(e)
+	deptID := assigned.DepartmentID
+	note := ""
+	if deptID == nil && e.Department != "" {
+		d, err := db.GetDepartmentByName(ctx, e.Department)
+		switch {
+		case err == nil:
+			deptID = &d.ID
+		case errors.Is(err, sql.ErrNoRows):
+			note = fmt.Sprintf("unknown department %q; department left unchanged", e.Department)
+		default:
+			return "", "", err
+		}
+	}
+
+	if user == nil {
+		if terminated {
+			return OutcomeSkipped, "terminated before an account was created", nil
+		}
+		if dryRun {
+			return OutcomeCreated, note, nil
+		}
+		name := e.Name
+		if name == "" {
+			name = e.Email
+		}
+		role := cmp.Or(assigned.Role, "Staff")
+		u, err := db.CreateUser(ctx, e.Email, name, role, nil, deptID)
+		if err != nil {
+			return "", "", err
+		}
+		if e.ExternalID != "" {
+			if err := db.SetUserExternalID(ctx, u.ID, e.ExternalID); err != nil {
+				return "", "", err
+			}
+		}
+		if err := onboard(ctx, db, u); err != nil {
+			return "", "", err
+		}
+		return OutcomeCreated, note, nil
+	}
+
+	if terminated {
+		if user.DeactivatedAt != nil {
+			return OutcomeUnchanged, "", nil
+		}
+		if dryRun {
+			return OutcomeDeactivated, note, nil
+		}
+		if err := db.DeactivateUser(ctx, user.ID); err != nil {
+			return "", "", err
+		}
+		return OutcomeDeactivated, note, nil
+	}
+
+	// Rehire: the HRIS no longer reports a past termination date.
+	rehired := user.DeactivatedAt != nil
+	newExternalID := e.ExternalID != "" && (user.ExternalID == nil || *user.ExternalID != e.ExternalID)
+	changed := rehired || newExternalID
+	name, email, role, dept := user.Name, user.Email, user.Role, user.DepartmentID
+	if e.Name != "" && e.Name != name {
+		name, changed = e.Name, true
+	}
+	if e.Email != strings.ToLower(email) {
+		email, changed = e.Email, true
+	}
+	if assigned.Role != "" && role != "SuperAdmin" && role != assigned.Role {
+		role, changed = assigned.Role, true
+	}
+	moved := deptID != nil && (dept == nil || *dept != *deptID)
+	if moved {
+		dept, changed = deptID, true
+	}
+	if !changed {
+		return OutcomeUnchanged, note, nil
+	}
+	if dryRun {
+		return OutcomeUpdated, note, nil
+	}
+	if rehired {
+		if err := db.ReactivateUser(ctx, user.ID); err != nil {
+			return "", "", err
+		}
+	}
+	if newExternalID {
+		if err := db.SetUserExternalID(ctx, user.ID, e.ExternalID); err != nil {
+			return "", "", err
+		}
+	}
+	if err := db.UpdateUser(ctx, user.ID, name, email, role, dept); err != nil {
+		return "", "", err
+	}
+	if moved {
+		user.Role, user.DepartmentID = role, dept
+		if err := onboard(ctx, db, user); err != nil {
+			return "", "", err
+		}
+	}
+	return OutcomeUpdated, note, nil
+}
+
+// onboard applies the bundles matching a user who is new or has moved
+// department and queues the email listing the policies awaiting them.
+func onboard(ctx context.Context, db *database.DB, u *database.User) error {
+	if _, err := db.AssignOnboardingBundles(ctx, u); err != nil {
+		return err
+	}
+	return db.QueueDepartmentWelcome(ctx, u)
+}
+
+// findUser matches by HRIS employee ID first, then by email. It returns nil
+// when the employee has no account yet.
+func findUser(ctx context.Context, db *database.DB, e Employee) (*database.User, error) {
+	if e.ExternalID != "" {
+		u, err := db.GetUserByExternalID(ctx, e.ExternalID)
+		if err == nil {
+			return u, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+	u, err := db.GetUserByEmail(ctx, e.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return u, err
+}
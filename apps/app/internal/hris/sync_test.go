@@ -0,0 +1,129 @@
+package hris
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"policyflow/internal/database"
+	"policyflow/internal/testutil"
+)
+
+func writeRoster(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write roster: %v", err)
+	}
+}
+
+// TestSyncer_Lifecycle runs a CSV sync through hire → transfer → termination.
+func TestSyncer_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+
+	path := filepath.Join(t.TempDir(), "roster.csv")
+	s := NewSyncer(db, &CSVProvider{Source: path})
+
+	writeRoster(t, path, "employee_id,email,name,department\nE1,ada@example.com,Ada,Engineering\n")
+	run, err := s.Run(ctx, nil)
+	if err != nil || run.Status != "success" || run.Created != 1 {
+		t.Fatalf("hire run = %+v, %v", run, err)
+	}
+	u, err := db.GetUserByExternalID(ctx, "E1")
+	if err != nil || u.DepartmentID == nil || *u.DepartmentID != eng.ID {
+		t.Fatalf("after hire: user=%+v err=%v", u, err)
+	}
+
+	writeRoster(t, path, "employee_id,email,name,department\nE1,ada@example.com,Ada,HR\n")
+	if run, err = s.Run(ctx, nil); err != nil || run.Updated != 1 {
+		t.Fatalf("transfer run = %+v, %v", run, err)
+	}
+	u, _ = db.GetUserByID(ctx, u.ID)
+	if u.DepartmentID == nil || *u.DepartmentID != hr.ID {
+		t.Errorf("after transfer: department_id = %v; want %s", u.DepartmentID, hr.ID)
+	}
+
+	writeRoster(t, path, "employee_id,email,name,department,termination_date\nE1,ada@example.com,Ada,HR,2000-01-01\n")
+	if run, err = s.Run(ctx, nil); err != nil || run.Deactivated != 1 {
+		t.Fatalf("termination run = %+v, %v", run, err)
+	}
+	u, _ = db.GetUserByID(ctx, u.ID)
+	if u.DeactivatedAt == nil {
+		t.Error("after termination: user still active")
+	}
+}
+
+// TestSyncer_ClaimRules checks claim rules set role and department over the
+// department name, the first matching rule wins, and SuperAdmins keep their
+// role.
+func TestSyncer_ClaimRules(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	sales, _ := db.CreateDepartment(ctx, "Sales", "")
+	db.CreateUser(ctx, "boss@example.com", "Boss", "SuperAdmin", nil, nil)
+	for _, r := range []*database.ClaimRule{
+		{Attribute: database.ClaimGroup, Operator: database.ClaimEquals, Value: "managers", Role: testutil.Ptr("DeptAdmin")},
+		{Attribute: database.ClaimJobTitle, Operator: database.ClaimContains, Value: "engineer", DepartmentID: &eng.ID},
+		{Attribute: database.ClaimOU, Operator: database.ClaimRegex, Value: `^EMEA/`, DepartmentID: &sales.ID},
+	} {
+		if err := db.CreateClaimRule(ctx, r); err != nil {
+			t.Fatalf("CreateClaimRule: %v", err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "roster.csv")
+	writeRoster(t, path, "employee_id,email,name,department,ou,job_title,groups\n"+
+		"E1,ada@example.com,Ada,Sales,EMEA/London,Senior Engineer,Staff; Managers\n"+
+		"E2,bo@example.com,Bo,,EMEA/Paris,Account Executive,\n"+
+		"E3,boss@example.com,Boss,,,,Staff;managers\n")
+	if run, err := NewSyncer(db, &CSVProvider{Source: path}).Run(ctx, nil); err != nil || run.Status != "success" {
+		t.Fatalf("run = %+v, %v", run, err)
+	}
+	for _, want := range []struct {
+		email, role string
+		dept        *string
+	}{
+		{"ada@example.com", "DeptAdmin", &eng.ID},
+		{"bo@example.com", "Staff", &sales.ID},
+		{"boss@example.com", "SuperAdmin", nil},
+	} {
+		u, err := db.GetUserByEmail(ctx, want.email)
+		if err != nil {
+			t.Fatalf("GetUserByEmail(%s): %v", want.email, err)
+		}
+		if u.Role != want.role || (want.dept == nil) != (u.DepartmentID == nil) ||
+			(want.dept != nil && *u.DepartmentID != *want.dept) {
+			t.Errorf("%s: role %s, department %v; want %s, %v", want.email, u.Role, u.DepartmentID, want.role, want.dept)
+		}
+	}
+}
+
+// TestSyncer_PreviewChangesNothing checks a dry run counts what a sync
+// would do without creating, moving, or logging anything.
+func TestSyncer_PreviewChangesNothing(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	eng, _ := db.CreateDepartment(ctx, "Engineering", "")
+	db.CreateDepartment(ctx, "HR", "")
+	ada, _ := db.CreateUser(ctx, "ada@example.com", "Ada", "Staff", nil, &eng.ID)
+
+	path := filepath.Join(t.TempDir(), "roster.csv")
+	writeRoster(t, path, "employee_id,email,name,department\nE1,ada@example.com,Ada,HR\nE2,bob@example.com,Bob,HR\n")
+	run, err := NewSyncer(db, &CSVProvider{Source: path}).Preview(ctx, nil)
+	if err != nil || !run.DryRun || run.Status != "success" || run.Created != 1 || run.Updated != 1 {
+		t.Fatalf("preview = %+v, %v; want 1 created and 1 updated", run, err)
+	}
+	if u, _ := db.GetUserByID(ctx, ada.ID); u.DepartmentID == nil || *u.DepartmentID != eng.ID || u.ExternalID != nil {
+		t.Errorf("Ada changed by the preview: %+v", u)
+	}
+	if _, err := db.GetUserByEmail(ctx, "bob@example.com"); err == nil {
+		t.Error("Bob created by the preview")
+	}
+	if runs, _ := db.ListHRISSyncRuns(ctx, 10); len(runs) != 0 {
+		t.Errorf("runs = %d; want the preview not stored", len(runs))
+	}
+}
@@ -0,0 +1,81 @@
+package hris
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Workday reads a Report-as-a-Service (RaaS) custom report in JSON format.
+// The report must expose the fields Employee_ID, Email, Name, Department,
+// Hire_Date, and Termination_Date; for claim rules it may also expose
+// Supervisory_Organization (the OU), Job_Title, and Groups (a list, or a
+// ";"-separated string).
+type Workday struct {
+	ReportURL string
+	Username  string
+	Password  string
+	Client    *http.Client // optional; defaults to http.DefaultClient
+}
+
+func (p *Workday) Name() string { return "workday" }
+
+func (p *Workday) FetchEmployees(ctx context.Context) ([]Employee, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.ReportURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.Username != "" {
+		req.SetBasicAuth(p.Username, p.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("workday: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("workday: %s", resp.Status)
+	}
+
+	var report struct {
+		Entries []map[string]any `json:"Report_Entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("workday: decode report: %w", err)
+	}
+
+	out := make([]Employee, 0, len(report.Entries))
+	for _, r := range report.Entries {
+		e := Employee{
+			ExternalID: str(r["Employee_ID"]),
+			Email:      str(r["Email"]),
+			Name:       str(r["Name"]),
+			Department: str(r["Department"]),
+			OU:         str(r["Supervisory_Organization"]),
+			JobTitle:   str(r["Job_Title"]),
+		}
+		switch g := r["Groups"].(type) {
+		case []any:
+			for _, v := range g {
+				e.Groups = append(e.Groups, str(v))
+			}
+		default:
+			e.Groups = splitGroups(str(g))
+		}
+		if e.StartDate, err = parseDate(str(r["Hire_Date"])); err != nil {
+			return nil, fmt.Errorf("workday employee %s: %w", e.ExternalID, err)
+		}
+		if e.TerminationDate, err = parseDate(str(r["Termination_Date"])); err != nil {
+			return nil, fmt.Errorf("workday employee %s: %w", e.ExternalID, err)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,71 @@
+// Package ics renders iCalendar (RFC 5545) documents for acknowledgement
+// deadlines, used both as email attachments and as a subscribable feed.
+package ics
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event is an all-day calendar entry on Date.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Date        time.Time
+}
+
+// Calendar renders events as a VCALENDAR with METHOD:PUBLISH so clients offer
+// "add to calendar" rather than an RSVP.
+func Calendar(name string, events []Event) []byte {
+	var b bytes.Buffer
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	line(&b, "BEGIN:VCALENDAR")
+	line(&b, "VERSION:2.0")
+	line(&b, "PRODID:-//PolicyFlow//Deadlines//EN")
+	line(&b, "CALSCALE:GREGORIAN")
+	line(&b, "METHOD:PUBLISH")
+	line(&b, "X-WR-CALNAME:"+escape(name))
+	for _, e := range events {
+		day := e.Date.UTC()
+		line(&b, "BEGIN:VEVENT")
+		line(&b, "UID:"+escape(e.UID))
+		line(&b, "DTSTAMP:"+stamp)
+		line(&b, "DTSTART;VALUE=DATE:"+day.Format("20060102"))
+		line(&b, "DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"))
+		line(&b, "SUMMARY:"+escape(e.Summary))
+		if e.Description != "" {
+			line(&b, "DESCRIPTION:"+escape(e.Description))
+		}
+		if e.URL != "" {
+			line(&b, "URL:"+e.URL)
+		}
+		line(&b, "TRANSP:TRANSPARENT")
+		line(&b, "END:VEVENT")
+	}
+	line(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+// line writes a content line terminated by CRLF, folding at 75 octets as
+// required by RFC 5545 §3.1 without splitting UTF-8 sequences.
+func line(b *bytes.Buffer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		fmt.Fprintf(b, "%s\r\n ", s[:cut])
+		s = s[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(s + "\r\n")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(s string) string { return escaper.Replace(s) }
@@ -0,0 +1,48 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// TestCalendar_FoldsAndEscapes verifies that long lines are folded at 75
+// octets without splitting a UTF-8 character, that text values are escaped,
+// and that an all-day event ends the day after it starts.
+func TestCalendar_FoldsAndEscapes(t *testing.T) {
+	summary := "Acknowledge: Café, Bar; and Lounge policy — " + strings.Repeat("é", 40)
+	cal := string(Calendar("PolicyFlow deadlines", []Event{{
+		UID:         "v1@policyflow",
+		Summary:     summary,
+		Description: "Line one\nLine two \\ done",
+		Date:        time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC),
+	}}))
+
+	if !strings.HasSuffix(cal, "END:VCALENDAR\r\n") {
+		t.Errorf("calendar does not end with a CRLF-terminated END:VCALENDAR")
+	}
+	for _, l := range strings.Split(strings.TrimSuffix(cal, "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("line of %d octets: %q", len(l), l)
+		}
+		if !utf8.ValidString(l) {
+			t.Errorf("line splits a UTF-8 character: %q", l)
+		}
+		if strings.Contains(l, "\n") {
+			t.Errorf("bare newline in line %q", l)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(cal, "\r\n ", "")
+	for _, want := range []string{
+		"SUMMARY:" + strings.NewReplacer(",", `\,`, ";", `\;`).Replace(summary) + "\r\n",
+		`DESCRIPTION:Line one\nLine two \\ done` + "\r\n",
+		"DTSTART;VALUE=DATE:20260331\r\n",
+		"DTEND;VALUE=DATE:20260401\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("unfolded calendar lacks %q:\n%s", want, unfolded)
+		}
+	}
+}
@@ -3,11 +3,14 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 
+	"policyflow/internal/apierr"
 	"policyflow/internal/database"
+	"policyflow/internal/tokens"
 )
 
 // Claims holds the JWT payload for session tokens.
@@ -16,8 +19,22 @@ type Claims struct {
 	Email string `json:"email"`
 	Role  string `json:"role"`
 	Type  string `json:"type"`
+	// ImpersonatorID is the SuperAdmin acting as Subject in an impersonation
+	// session; empty for normal sessions.
+	ImpersonatorID string `json:"imp,omitempty"`
+	// AuthTime is when the user signed in (Unix seconds). Refreshed session
+	// tokens keep it, so the session length is counted from sign-in.
+	AuthTime int64 `json:"auth_time,omitempty"`
 }
 
+// HeaderDepartment selects which of their departments a DeptAdmin with
+// department grants is acting for; without it they act for their own.
+const HeaderDepartment = "X-Department-Id"
+
+// HeaderSessionToken carries a reissued session token when an idle timeout
+// is configured; clients should replace their stored token with it.
+const HeaderSessionToken = "X-Session-Token"
+
 // Role constants.
 const (
 	RoleSuperAdmin = "SuperAdmin"
@@ -31,51 +48,135 @@ const (
 	CtxUserEmail = "user_email"
 	CtxUserRole  = "user_role"
 	CtxDeptID    = "user_dept_id" // *string, may be nil
+	// CtxImpersonatorID is the SuperAdmin's user ID in impersonation sessions.
+	CtxImpersonatorID = "impersonator_id"
 )
 
 // Auth provides JWT-based authentication middleware.
 type Auth struct {
-	secret []byte
-	db     *database.DB
+	keys *tokens.Keyring
+	db   *database.DB
 }
 
-func NewAuth(secret string, db *database.DB) *Auth {
-	return &Auth{secret: []byte(secret), db: db}
+func NewAuth(keys *tokens.Keyring, db *database.DB) *Auth {
+	return &Auth{keys: keys, db: db}
 }
 
 // Require validates the Bearer token, stores claims in the Echo context,
-// and fetches the user's department_id from the DB.
+// and fetches the user's department_id from the DB, or takes it from
+// X-Department-Id for a DeptAdmin granted that department.
 func (a *Auth) Require(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		token := extractToken(c.Request())
 		if token == "" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "missing token")
+			return apierr.New(http.StatusUnauthorized, "MISSING_TOKEN", "missing token")
 		}
 
 		claims, err := a.parseSession(token)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+			return apierr.New(http.StatusUnauthorized, "INVALID_TOKEN", "invalid token")
 		}
 
 		c.Set(CtxUserID, claims.Subject)
 		c.Set(CtxUserEmail, claims.Email)
 		c.Set(CtxUserRole, claims.Role)
 
-		// Fetch department_id from DB so handlers can enforce scoping.
-		user, err := a.db.GetUserByID(claims.Subject)
+		// Fetch department_id so handlers can enforce scoping. The user is
+		// cached briefly; changes to users drop the cache.
+		user, err := a.db.GetUserByIDCached(c.Request().Context(), claims.Subject)
 		if err == nil {
+			if user.DeactivatedAt != nil {
+				return apierr.New(http.StatusUnauthorized, "ACCOUNT_DEACTIVATED", "account deactivated")
+			}
 			c.Set(CtxDeptID, user.DepartmentID) // *string, may be nil
+			if err := a.actForDepartment(c, user, claims.Role); err != nil {
+				return err
+			}
+		}
+
+		if claims.ImpersonatorID == "" {
+			if err := a.applyLifetimes(c, claims); err != nil {
+				return err
+			}
+		} else {
+			// The impersonator must still be an active SuperAdmin.
+			admin, err := a.db.GetUserByIDCached(c.Request().Context(), claims.ImpersonatorID)
+			if err != nil || admin.DeactivatedAt != nil || admin.Role != RoleSuperAdmin {
+				return apierr.New(http.StatusUnauthorized, "INVALID_TOKEN", "invalid token")
+			}
+			c.Set(CtxImpersonatorID, claims.ImpersonatorID)
+			// Impersonation is for seeing what the user sees; writes would
+			// forge their actions, including acknowledgements.
+			if !readOnly(c) {
+				LogAudit(c, a.db, "impersonation.write_blocked", "", "", c.Request().Method+" "+c.Path())
+				return apierr.New(http.StatusForbidden, "IMPERSONATION_READ_ONLY", "impersonation sessions are read-only")
+			}
 		}
 
 		return next(c)
 	}
 }
 
+// actForDepartment scopes the request to the department in
+// X-Department-Id, which a DeptAdmin must have been granted. Other roles'
+// headers are ignored.
+func (a *Auth) actForDepartment(c echo.Context, user *database.User, role string) error {
+	dept := c.Request().Header.Get(HeaderDepartment)
+	if dept == "" || role != RoleDeptAdmin || (user.DepartmentID != nil && *user.DepartmentID == dept) {
+		return nil
+	}
+	granted, err := a.db.HasDepartmentGrant(c.Request().Context(), user.ID, dept)
+	if err != nil {
+		return apierr.Database()
+	}
+	if !granted {
+		return apierr.New(http.StatusForbidden, "DEPARTMENT_NOT_GRANTED", "you do not administer that department")
+	}
+	c.Set(CtxDeptID, &dept)
+	return nil
+}
+
+// applyLifetimes enforces the session length and idle timeout settings on a
+// session token. Tokens carry their idle expiry, so activity slides it
+// forward by reissuing the token in the X-Session-Token response header;
+// tokens issued under looser settings are shortened the same way.
+func (a *Auth) applyLifetimes(c echo.Context, claims *Claims) error {
+	lifetimes, err := tokens.LoadLifetimes(c.Request().Context(), a.db)
+	if err != nil {
+		return apierr.Database()
+	}
+	now := time.Now()
+	authTime := time.Unix(claims.AuthTime, 0)
+	if claims.AuthTime == 0 && claims.IssuedAt != nil {
+		authTime = claims.IssuedAt.Time // issued before auth_time existed
+	}
+	if !now.Before(authTime.Add(lifetimes.Session)) {
+		return apierr.New(http.StatusUnauthorized, "SESSION_EXPIRED", "session expired")
+	}
+	if lifetimes.Idle == 0 || claims.ExpiresAt == nil {
+		return nil
+	}
+	exp := lifetimes.SessionExpiry(authTime, now)
+	if d := exp.Sub(claims.ExpiresAt.Time); d > -time.Minute && d < time.Minute {
+		return nil // reissue at most about once a minute
+	}
+	refreshed := *claims
+	refreshed.AuthTime = authTime.Unix()
+	refreshed.IssuedAt = jwt.NewNumericDate(now)
+	refreshed.ExpiresAt = jwt.NewNumericDate(exp)
+	token, err := a.keys.Sign(refreshed)
+	if err != nil {
+		return apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "session error")
+	}
+	c.Response().Header().Set(HeaderSessionToken, token)
+	return nil
+}
+
 // RequireSuperAdmin enforces the SuperAdmin role. Must follow Require.
 func (a *Auth) RequireSuperAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		if c.Get(CtxUserRole) != RoleSuperAdmin {
-			return echo.NewHTTPError(http.StatusForbidden, "super admin only")
+			return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "super admin only")
 		}
 		return next(c)
 	}
@@ -86,7 +187,7 @@ func (a *Auth) RequireDeptAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		role := c.Get(CtxUserRole)
 		if role != RoleSuperAdmin && role != RoleDeptAdmin {
-			return echo.NewHTTPError(http.StatusForbidden, "admin only")
+			return apierr.New(http.StatusForbidden, apierr.CodeForbidden, "admin only")
 		}
 		return next(c)
 	}
@@ -97,21 +198,60 @@ func (a *Auth) RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return a.RequireDeptAdmin(next)
 }
 
+// RequireFeed authenticates calendar feed requests. It accepts a session token
+// or a long-lived calendar feed token (see IssueFeedToken), since calendar
+// clients cannot send Authorization headers or refresh sessions.
+func (a *Auth) RequireFeed(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := extractToken(c.Request())
+		if token == "" {
+			return apierr.New(http.StatusUnauthorized, "MISSING_TOKEN", "missing token")
+		}
+		claims, err := a.parseToken(token, "session", "calendar")
+		if err != nil {
+			return apierr.New(http.StatusUnauthorized, "INVALID_TOKEN", "invalid token")
+		}
+
+		// Feed tokens carry no role; always take identity from the DB.
+		user, err := a.db.GetUserByIDCached(c.Request().Context(), claims.Subject)
+		if err != nil || user.DeactivatedAt != nil {
+			return apierr.New(http.StatusUnauthorized, "INVALID_TOKEN", "invalid token")
+		}
+		c.Set(CtxUserID, user.ID)
+		c.Set(CtxUserEmail, user.Email)
+		c.Set(CtxUserRole, user.Role)
+		c.Set(CtxDeptID, user.DepartmentID)
+		return next(c)
+	}
+}
+
+// IssueFeedToken signs a read-only calendar feed token for a user, valid for a year.
+func (a *Auth) IssueFeedToken(userID string) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":  userID,
+		"type": "calendar",
+		"exp":  time.Now().Add(365 * 24 * time.Hour).Unix(),
+		"iat":  time.Now().Unix(),
+	}
+	return a.keys.Sign(claims)
+}
+
 func (a *Auth) parseSession(tokenStr string) (*Claims, error) {
+	return a.parseToken(tokenStr, "session")
+}
+
+// parseToken verifies the signature and expiry and checks the token type.
+func (a *Auth) parseToken(tokenStr string, types ...string) (*Claims, error) {
 	claims := &Claims{}
-	_, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, echo.ErrUnauthorized
-		}
-		return a.secret, nil
-	})
-	if err != nil {
+	if err := a.keys.Parse(tokenStr, claims); err != nil {
 		return nil, err
 	}
-	if claims.Type != "session" {
-		return nil, echo.ErrUnauthorized
+	for _, typ := range types {
+		if claims.Type == typ {
+			return claims, nil
+		}
 	}
-	return claims, nil
+	return nil, echo.ErrUnauthorized
 }
 
 func extractToken(r *http.Request) string {
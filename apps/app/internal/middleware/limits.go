@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+
+	"policyflow/internal/apierr"
+)
+
+// BodyLimits caps the size of request bodies, with larger limits for the
+// routes that take uploads.
+type BodyLimits struct {
+	def    int64
+	routes map[string]int64 // "METHOD /path" → limit
+}
+
+func NewBodyLimits(def int64) *BodyLimits {
+	return &BodyLimits{def: def, routes: map[string]int64{}}
+}
+
+// Set overrides the limit for one route, written as registered, e.g.
+// "/api/policies/:id/attachments".
+func (l *BodyLimits) Set(method, path string, n int64) {
+	l.routes[method+" "+path] = n
+}
+
+// Limit refuses bodies over the route's limit with 413 BODY_TOO_LARGE. A
+// declared Content-Length is checked up front; otherwise reading stops at
+// the limit, and whatever error the handler makes of that is replaced.
+func (l *BodyLimits) Limit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		n, ok := l.routes[req.Method+" "+c.Path()]
+		if !ok {
+			n = l.def
+		}
+		tooLarge := apierr.With(apierr.New(http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE", "request body is too large"), "limit", n)
+		if req.ContentLength > n {
+			return tooLarge
+		}
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(c.Response(), req.Body, n)}
+		req.Body = body
+		err := next(c)
+		if err != nil && body.exceeded {
+			return tooLarge
+		}
+		return err
+	}
+}
+
+// limitedBody notes when a request body ran past its limit.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// PerIP allows each client address n requests per window, refusing the rest
+// with 429 RATE_LIMITED. Counts are kept in memory, so each instance applies
+// the limit separately.
+func PerIP(n int, window time.Duration) echo.MiddlewareFunc {
+	return echomw.RateLimiterWithConfig(echomw.RateLimiterConfig{
+		Store: echomw.NewRateLimiterMemoryStoreWithConfig(echomw.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(float64(n) / window.Seconds()),
+			Burst:     n,
+			ExpiresIn: window,
+		}),
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			return c.RealIP(), nil
+		},
+		DenyHandler: func(c echo.Context, _ string, _ error) error {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(window.Seconds())/n))
+			return apierr.New(http.StatusTooManyRequests, "RATE_LIMITED", "too many requests; try again later")
+		},
+	})
+}
+
+// ParseSize reads a byte count written as a plain number or with a K, M, or
+// G suffix (powers of 1024), optionally followed by B: "512K", "100MB".
+func ParseSize(s string) (int64, error) {
+	v := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	mult := int64(1)
+	switch {
+	case strings.HasSuffix(v, "K"):
+		mult, v = 1<<10, strings.TrimSuffix(v, "K")
+	case strings.HasSuffix(v, "M"):
+		mult, v = 1<<20, strings.TrimSuffix(v, "M")
+	case strings.HasSuffix(v, "G"):
+		mult, v = 1<<30, strings.TrimSuffix(v, "G")
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
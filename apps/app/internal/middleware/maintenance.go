@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/database"
+)
+
+// DefaultMaintenanceMessage is shown when no message has been set.
+const DefaultMaintenanceMessage = "PolicyFlow is undergoing maintenance. You can read policies, but changes are paused."
+
+// maintenanceExempt lists the writes still accepted in maintenance mode: the
+// toggle itself, and signing in by link or code so an admin can get back in.
+var maintenanceExempt = map[string]bool{
+	"/api/admin/maintenance": true,
+	"/api/magic-link":        true,
+	"/api/login-code":        true,
+	"/api/login-code/verify": true,
+}
+
+// Maintenance puts the API into read-only mode, either from the
+// maintenance_mode setting or, if forced, from MAINTENANCE_MODE.
+type Maintenance struct {
+	db     *database.DB
+	forced bool
+}
+
+func NewMaintenance(db *database.DB, forced bool) *Maintenance {
+	return &Maintenance{db: db, forced: forced}
+}
+
+// Forced reports whether maintenance mode is fixed on by the environment.
+func (m *Maintenance) Forced() bool { return m.forced }
+
+// Status reports whether maintenance mode is on and the banner message.
+func (m *Maintenance) Status(ctx context.Context) (bool, string, error) {
+	enabled, err := m.db.GetBoolSetting(ctx, database.SettingMaintenanceMode, false)
+	if err != nil {
+		return m.forced, DefaultMaintenanceMessage, err
+	}
+	msg, err := m.db.GetSetting(ctx, database.SettingMaintenanceMessage, "")
+	if msg == "" {
+		msg = DefaultMaintenanceMessage
+	}
+	return enabled || m.forced, msg, err
+}
+
+// Guard refuses state-changing requests with 503 while maintenance mode is on.
+func (m *Maintenance) Guard(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if readOnly(c) || maintenanceExempt[c.Path()] {
+			return next(c)
+		}
+		enabled, msg, err := m.Status(c.Request().Context())
+		if err != nil {
+			// Fail open: a settings read error must not take the API down.
+			log.Printf("maintenance status: %v", err)
+		}
+		if enabled {
+			c.Response().Header().Set("Retry-After", "300")
+			return apierr.Respond(c, apierr.With(apierr.New(http.StatusServiceUnavailable, "MAINTENANCE", msg), "maintenance", true))
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,130 @@
+// Package notify emails users about newly published policy versions, and
+// about the policies awaiting them when they join a department, from the
+// queues filled when those happen.
+package notify
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	"policyflow/internal/reminders"
+)
+
+// batchSize caps how many queued emails one pass sends.
+const batchSize = 500
+
+// Runner drains the publish notification and department welcome queues.
+type Runner struct {
+	db      *database.DB
+	mailer  *email.Mailer
+	baseURL string
+}
+
+func NewRunner(db *database.DB, mailer *email.Mailer, baseURL string) *Runner {
+	return &Runner{db: db, mailer: mailer, baseURL: baseURL}
+}
+
+// Run sends the queued emails over one SMTP connection per queue. Emails
+// that are no longer needed, because the version was replaced or already
+// acknowledged or the user moved on, are dropped. Mail failures are logged and retried on the next pass.
+func (r *Runner) Run(ctx context.Context) error {
+	if err := r.sendPublished(ctx); err != nil {
+		return err
+	}
+	return r.sendWelcomes(ctx)
+}
+
+func (r *Runner) sendPublished(ctx context.Context) error {
+	queued, err := r.db.ListUnsentPublishNotifications(ctx, batchSize)
+	if err != nil || len(queued) == 0 {
+		return err
+	}
+	mail := r.mailer.Batch()
+	defer mail.Close()
+	sent := 0
+	for _, n := range queued {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !n.Stale {
+			if err := mail.SendPolicyPublished(n.UserEmail, n.UserName, n.PolicyTitle, n.VersionString, n.Changelog,
+				reminders.PolicyURL(r.baseURL, n.PolicyID)); err != nil {
+				log.Printf("notify: send to %s: %v", n.UserEmail, err)
+				continue
+			}
+			sent++
+		}
+		if err := r.db.MarkPublishNotificationSent(ctx, n.PolicyVersionID, n.UserID); err != nil {
+			return err
+		}
+	}
+	if sent > 0 {
+		log.Printf("notify: sent %d publish notification(s)", sent)
+	}
+	return nil
+}
+
+// sendWelcomes sends each user who joined a department one email listing
+// every policy awaiting their acknowledgement, read when it is sent. Users
+// with nothing pending get no email.
+func (r *Runner) sendWelcomes(ctx context.Context) error {
+	queued, err := r.db.ListUnsentDepartmentWelcomes(ctx, batchSize)
+	if err != nil || len(queued) == 0 {
+		return err
+	}
+	mail := r.mailer.Batch()
+	defer mail.Close()
+	now := time.Now()
+	sent := 0
+	for _, w := range queued {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !w.Stale {
+			pending, err := r.db.ListPendingPoliciesForUser(ctx, w.UserID, w.Role, &w.DepartmentID)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				items := make([]email.ReminderItem, len(pending))
+				for i, p := range pending {
+					items[i] = email.ReminderItem{Title: p.Title, URL: reminders.PolicyURL(r.baseURL, p.ID)}
+					if p.AckDeadline != nil {
+						items[i].Deadline, items[i].Overdue = *p.AckDeadline, now.After(*p.AckDeadline)
+					}
+				}
+				if err := mail.SendDepartmentWelcome(w.UserEmail, w.UserName, w.DepartmentName, items); err != nil {
+					log.Printf("notify: send welcome to %s: %v", w.UserEmail, err)
+					continue
+				}
+				sent++
+			}
+		}
+		if err := r.db.MarkDepartmentWelcomeSent(ctx, w.UserID, w.DepartmentID); err != nil {
+			return err
+		}
+	}
+	if sent > 0 {
+		log.Printf("notify: sent %d department welcome(s)", sent)
+	}
+	return nil
+}
+
+// Schedule runs a pass every interval until ctx is cancelled.
+func (r *Runner) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Run(ctx); err != nil {
+				log.Printf("notify: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package pdftext extracts the text of PDF documents.
+package pdftext
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// ErrInvalid is returned for files that cannot be parsed as PDF, including
+// encrypted ones.
+var ErrInvalid = errors.New("not a readable PDF")
+
+// MaxPages caps how many pages are read; text beyond it is not extracted.
+const MaxPages = 500
+
+// Extract returns the text of a PDF, one line per line of text on the page
+// and a blank line between pages. Scanned documents without a text layer
+// yield an empty string.
+func Extract(r io.ReaderAt, size int64) (text string, err error) {
+	// The parser panics on some malformed input rather than returning errors.
+	defer func() {
+		if p := recover(); p != nil {
+			text, err = "", fmt.Errorf("%w: %v", ErrInvalid, p)
+		}
+	}()
+	doc, err := pdf.NewReader(r, size)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	var pages []string
+	for i := 1; i <= min(doc.NumPage(), MaxPages); i++ {
+		if page := pageText(doc.Page(i).Content().Text); page != "" {
+			pages = append(pages, page)
+		}
+	}
+	return strings.Join(pages, "\n\n"), nil
+}
+
+// pageText joins a page's glyphs in content-stream order, which is reading
+// order for nearly every generator. A baseline shift starts a new line and
+// a horizontal gap wider than a fifth of the font size becomes a space.
+func pageText(glyphs []pdf.Text) string {
+	var lines []string
+	var b strings.Builder
+	flush := func() {
+		if line := strings.Join(strings.Fields(b.String()), " "); line != "" {
+			lines = append(lines, line)
+		}
+		b.Reset()
+	}
+	var prev *pdf.Text
+	for i := range glyphs {
+		g := &glyphs[i]
+		if prev != nil {
+			size := max(g.FontSize, 1)
+			switch {
+			case math.Abs(g.Y-prev.Y) > size/2:
+				flush()
+			case g.X-(prev.X+prev.W) > size/5:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(g.S)
+		prev = g
+	}
+	flush()
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,38 @@
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+func TestExtract(t *testing.T) {
+	f := gofpdf.New("P", "mm", "A4", "")
+	f.SetFont("Helvetica", "", 12)
+	f.AddPage()
+	f.Text(20, 20, "Remote Work Policy")
+	f.Text(20, 30, "Staff may work remotely two days a week.")
+	f.AddPage()
+	f.Text(20, 20, "Equipment remains company property.")
+	var buf bytes.Buffer
+	if err := f.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := Extract(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	for _, want := range []string{"Remote Work Policy\nStaff may work remotely two days a week.", "\n\nEquipment remains company property."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text = %q; want it to contain %q", text, want)
+		}
+	}
+
+	if _, err := Extract(strings.NewReader("%PDF-1.4 garbage"), 16); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Extract(garbage) err = %v; want ErrInvalid", err)
+	}
+}
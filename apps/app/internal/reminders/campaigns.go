@@ -0,0 +1,94 @@
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+)
+
+// CampaignRunner emails the audience of each active acknowledgement
+// campaign when it starts and on each of its reminder days, listing the
+// campaign policies they have still to acknowledge. Each stage reaches a
+// user at most once; users who finish get nothing more.
+type CampaignRunner struct {
+	db      *database.DB
+	mailer  *email.Mailer
+	baseURL string
+}
+
+func NewCampaignRunner(db *database.DB, mailer *email.Mailer, baseURL string) *CampaignRunner {
+	return &CampaignRunner{db: db, mailer: mailer, baseURL: baseURL}
+}
+
+// Run sends the emails due now over one SMTP connection. Mail failures are
+// logged and the user is tried again on the next run.
+func (r *CampaignRunner) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	campaigns, err := r.db.ListActiveCampaigns(ctx, now)
+	if err != nil {
+		return err
+	}
+	mail := r.mailer.Batch()
+	defer mail.Close()
+	for _, c := range campaigns {
+		stage := CampaignStage(c, now)
+		todos, err := r.db.ListCampaignTodos(ctx, c, stage)
+		if err != nil {
+			return err
+		}
+		sent := 0
+		for _, t := range todos {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			items := make([]email.ReminderItem, len(t.Policies))
+			for i, p := range t.Policies {
+				items[i] = email.ReminderItem{Title: p.Title, Deadline: c.Deadline, URL: PolicyURL(r.baseURL, p.ID)}
+			}
+			if err := mail.SendCampaignReminder(t.UserEmail, t.UserName, c.Name, c.Description, c.Deadline, items); err != nil {
+				log.Printf("campaigns: send %s to %s: %v", c.Name, t.UserEmail, err)
+				continue
+			}
+			if err := r.db.RecordCampaignReminder(ctx, c.ID, t.UserID, stage); err != nil {
+				return err
+			}
+			sent++
+		}
+		if sent > 0 {
+			log.Printf("campaigns: sent %d %q email(s) for %s", sent, stage, c.Name)
+		}
+	}
+	return nil
+}
+
+// Schedule runs the campaign pass every interval until ctx is cancelled.
+func (r *CampaignRunner) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Run(ctx); err != nil {
+				log.Printf("campaigns: %v", err)
+			}
+		}
+	}
+}
+
+// CampaignStage is the latest email stage of c due at now: "start" once it
+// has started, then "<n>d" from n reminder days before the deadline.
+func CampaignStage(c *database.Campaign, now time.Time) string {
+	stage := "start"
+	for _, d := range c.ReminderDays { // largest first
+		if !now.Before(c.Deadline.Add(-time.Duration(d) * 24 * time.Hour)) {
+			stage = fmt.Sprintf("%dd", d)
+		}
+	}
+	return stage
+}
@@ -0,0 +1,51 @@
+package reminders
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	mw "policyflow/internal/middleware"
+	"policyflow/internal/testutil"
+)
+
+// TestCampaignRunner_SendsEachStageOnce verifies that only users with
+// campaign policies outstanding are emailed, once per stage, and that the
+// stage is the latest reminder day reached.
+func TestCampaignRunner_SendsEachStageOnce(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	done, _ := db.CreateUser(ctx, "done@example.com", "Done", mw.RoleStaff, nil, nil)
+	db.CreateUser(ctx, "todo@example.com", "Todo", mw.RoleStaff, nil, nil)
+	p, _ := db.CreatePolicy(ctx, "Code of Conduct", "", nil, "organization", nil)
+	testutil.Publish(t, db, p)
+	p, _ = db.GetPolicy(ctx, p.ID)
+	db.CreateAcknowledgement(ctx, done.ID, *p.CurrentVersionID)
+
+	now := time.Now().UTC()
+	c := &database.Campaign{Name: "Refresh", StartsAt: now.Add(-10 * 24 * time.Hour), Deadline: now.Add(3 * 24 * time.Hour),
+		AudienceType: database.AudienceOrganization, ReminderDays: []int{1, 7}}
+	if err := db.SaveCampaign(ctx, c, []string{p.ID}); err != nil {
+		t.Fatal(err)
+	}
+	c, _ = db.GetCampaign(ctx, c.ID)
+	if got := CampaignStage(c, now); got != "7d" {
+		t.Errorf("stage = %q; want 7d", got)
+	}
+
+	mailer := email.New()
+	outbox := mailer.Capture()
+	r := NewCampaignRunner(db, mailer, "https://policies.example.com")
+	for range 2 {
+		if err := r.Run(ctx); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+	msgs := outbox.Messages()
+	if len(msgs) != 1 || msgs[0].To != "todo@example.com" || !strings.Contains(msgs[0].Body, "Code of Conduct") {
+		t.Fatalf("messages = %+v; want one reminder to todo@example.com", msgs)
+	}
+}
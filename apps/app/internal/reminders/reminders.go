@@ -0,0 +1,140 @@
+// Package reminders emails users about acknowledgement deadlines that are
+// approaching or have passed.
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	"policyflow/internal/ics"
+)
+
+// Runner sends at most one reminder per user and policy version per day.
+type Runner struct {
+	db       *database.DB
+	mailer   *email.Mailer
+	baseURL  string
+	leadTime time.Duration // remind this long before a deadline
+}
+
+func NewRunner(db *database.DB, mailer *email.Mailer, baseURL string, leadTime time.Duration) *Runner {
+	return &Runner{db: db, mailer: mailer, baseURL: baseURL, leadTime: leadTime}
+}
+
+// Run emails every active user whose pending policies are due within the
+// lead time or overdue, over one SMTP connection. Policies the user has
+// snoozed are left out. Mail failures are logged and do not stop the run.
+func (r *Runner) Run(ctx context.Context) error {
+	users, err := r.db.ListActiveUsers(ctx)
+	if err != nil {
+		return err
+	}
+	mail := r.mailer.Batch()
+	defer mail.Close()
+	now := time.Now().UTC()
+	dayAgo := now.Add(-24 * time.Hour)
+	sent := 0
+	for _, u := range users {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		pending, err := r.db.ListPendingPoliciesForUser(ctx, u.ID, u.Role, u.DepartmentID)
+		if err != nil {
+			return err
+		}
+		snoozed, err := r.db.ReminderSnoozes(ctx, u.ID, now)
+		if err != nil {
+			return err
+		}
+
+		var due []*database.Policy
+		for _, p := range pending {
+			if p.AckDeadline == nil || p.AckDeadline.Sub(now) > r.leadTime {
+				continue
+			}
+			if _, ok := snoozed[*p.CurrentVersionID]; ok {
+				continue
+			}
+			already, err := r.db.ReminderSentSince(ctx, u.ID, *p.CurrentVersionID, dayAgo)
+			if err != nil {
+				return err
+			}
+			if !already {
+				due = append(due, p)
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		items := make([]email.ReminderItem, len(due))
+		for i, p := range due {
+			items[i] = email.ReminderItem{
+				Title:    p.Title,
+				Deadline: *p.AckDeadline,
+				Overdue:  now.After(*p.AckDeadline),
+				URL:      PolicyURL(r.baseURL, p.ID),
+			}
+		}
+		cal := ics.Calendar("PolicyFlow deadlines", DeadlineEvents(r.baseURL, due))
+		if err := mail.SendDeadlineReminder(u.Email, u.Name, items, cal); err != nil {
+			log.Printf("reminders: send to %s: %v", u.Email, err)
+			continue
+		}
+		for _, p := range due {
+			if err := r.db.RecordReminder(ctx, u.ID, *p.CurrentVersionID); err != nil {
+				return err
+			}
+		}
+		sent++
+	}
+	if sent > 0 {
+		log.Printf("reminders: sent %d deadline reminder(s)", sent)
+	}
+	return nil
+}
+
+// Schedule runs the reminder pass every interval until ctx is cancelled.
+func (r *Runner) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Run(ctx); err != nil {
+				log.Printf("reminders: %v", err)
+			}
+		}
+	}
+}
+
+// DeadlineEvents converts policies with deadlines into calendar events.
+// Policies without a deadline are skipped.
+func DeadlineEvents(baseURL string, policies []*database.Policy) []ics.Event {
+	var events []ics.Event
+	for _, p := range policies {
+		if p.AckDeadline == nil || p.CurrentVersionID == nil {
+			continue
+		}
+		url := PolicyURL(baseURL, p.ID)
+		events = append(events, ics.Event{
+			UID:         fmt.Sprintf("%s-%s@policyflow", p.ID, *p.CurrentVersionID),
+			Summary:     "Acknowledge policy: " + p.Title,
+			Description: "Read and acknowledge this policy in PolicyFlow: " + url,
+			URL:         url,
+			Date:        *p.AckDeadline,
+		})
+	}
+	return events
+}
+
+// PolicyURL is the frontend deep link to a policy.
+func PolicyURL(baseURL, policyID string) string {
+	return fmt.Sprintf("%s/policies?id=%s", baseURL, policyID)
+}
@@ -0,0 +1,38 @@
+// Package render produces server-side HTML for pages served outside the
+// single-page app: the public policy portal, shared links, and print views.
+package render
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var (
+	md    = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	pages = template.Must(template.New("").Funcs(template.FuncMap{
+		"markdown": Markdown,
+	}).ParseFS(templateFS, "templates/*.html"))
+)
+
+// Markdown converts policy content to HTML. Raw HTML in the source is
+// dropped, so the result is safe to embed in a page.
+func Markdown(src string) template.HTML {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return template.HTML(template.HTMLEscapeString(src))
+	}
+	return template.HTML(buf.String())
+}
+
+// Page executes the named template (e.g. "public_index.html") into w.
+func Page(w io.Writer, name string, data any) error {
+	return pages.ExecuteTemplate(w, name, data)
+}
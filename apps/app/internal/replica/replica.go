@@ -0,0 +1,389 @@
+// Package replica streams the SQLite write-ahead log to blob storage, in the
+// style of Litestream, so a deployment on a single disk can be restored to
+// any point in time without switching databases.
+//
+// Replication is organised in generations. A generation starts with a
+// byte-for-byte snapshot of the database file taken right after a full
+// checkpoint, followed by segments: contiguous ranges of the WAL file, each
+// ending on a commit frame. The replicator disables SQLite's automatic
+// checkpoints and checkpoints itself once everything in the WAL has been
+// shipped, so every WAL "epoch" between two checkpoints is captured in full.
+// Restoring replays the snapshot and segments up to the requested time.
+//
+// Objects are stored under replica/:
+//
+//	replica/generations.json               every generation, oldest first
+//	replica/<gen>/manifest.json            the generation's segments
+//	replica/<gen>/snapshot.db.gz
+//	replica/<gen>/<epoch>-<offset>.wal.gz
+package replica
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"policyflow/internal/storage"
+)
+
+const (
+	walHeaderSize   = 32
+	frameHeaderSize = 24
+	prefix          = "replica/"
+	generationsKey  = prefix + "generations.json"
+)
+
+// ErrNoReplica is returned by Restore when storage holds no usable
+// generation for the requested time.
+var ErrNoReplica = errors.New("replica: no generation to restore from")
+
+// Generation is an entry in replica/generations.json.
+type Generation struct {
+	ID        string    `json:"id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Segment is a shipped range of one WAL epoch.
+type Segment struct {
+	Key       string    `json:"key"`
+	Epoch     int       `json:"epoch"`
+	Offset    int64     `json:"offset"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Manifest lists a generation's snapshot and segments in order.
+type Manifest struct {
+	Generation
+	Snapshot string    `json:"snapshot"`
+	Segments []Segment `json:"segments"`
+}
+
+// Replicator ships the WAL of the database at Path. DB must be the pool the
+// application writes through, limited to one open connection: holding that
+// connection while reading the WAL keeps writers out, so only committed
+// frames are read and nothing is checkpointed behind the replicator's back.
+type Replicator struct {
+	DB    *sql.DB
+	Path  string
+	Store storage.Store
+
+	// SnapshotInterval starts a new generation once the current one is this
+	// old, bounding how many segments a restore replays.
+	SnapshotInterval time.Duration
+	// Retention keeps older generations until their successor is this old,
+	// so any time within the window can still be restored.
+	Retention time.Duration
+	// CheckpointBytes is the WAL size at which the replicator checkpoints.
+	CheckpointBytes int64
+
+	now func() time.Time
+
+	gens         []Generation
+	manifest     *Manifest
+	epoch        int
+	salt         []byte
+	offset       int64
+	checkpointed bool
+}
+
+// New returns a replicator with default intervals.
+func New(db *sql.DB, path string, store storage.Store) *Replicator {
+	return &Replicator{
+		DB:               db,
+		Path:             path,
+		Store:            store,
+		SnapshotInterval: 24 * time.Hour,
+		Retention:        72 * time.Hour,
+		CheckpointBytes:  4 << 20,
+		now:              func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Start turns off automatic checkpoints and begins a new generation.
+func (r *Replicator) Start(ctx context.Context) error {
+	if _, err := r.DB.ExecContext(ctx, `PRAGMA wal_autocheckpoint = 0`); err != nil {
+		return err
+	}
+	gens, err := loadGenerations(ctx, r.Store)
+	if err != nil {
+		return err
+	}
+	r.gens = gens
+	return r.snapshot(ctx)
+}
+
+// Run syncs every interval until ctx is cancelled.
+func (r *Replicator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Sync(ctx); err != nil {
+				log.Printf("replica error: %v", err)
+			}
+		}
+	}
+}
+
+// Sync ships any WAL frames committed since the last call, checkpoints when
+// the WAL has grown past CheckpointBytes, and starts a new generation when
+// the current one is due or the WAL was reset unexpectedly.
+func (r *Replicator) Sync(ctx context.Context) error {
+	if r.manifest == nil || r.now().Sub(r.manifest.StartedAt) >= r.SnapshotInterval {
+		return r.snapshot(ctx)
+	}
+
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	wal, err := os.ReadFile(r.Path + "-wal")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(wal) < walHeaderSize {
+		return nil
+	}
+	salt := wal[16:24]
+	if !bytes.Equal(salt, r.salt) {
+		if !r.checkpointed {
+			// Someone else checkpointed and restarted the WAL; frames may
+			// have been lost, so start over from a fresh snapshot.
+			conn.Close()
+			log.Printf("replica: WAL was reset outside the replicator, starting a new generation")
+			return r.snapshot(ctx)
+		}
+		r.epoch++
+		r.salt = bytes.Clone(salt)
+		r.offset = 0
+		r.checkpointed = false
+	}
+
+	if end := lastCommit(wal, r.offset); end > r.offset {
+		seg := Segment{
+			Key:       fmt.Sprintf("%s%s/%06d-%016x.wal.gz", prefix, r.manifest.ID, r.epoch, r.offset),
+			Epoch:     r.epoch,
+			Offset:    r.offset,
+			Size:      end - r.offset,
+			CreatedAt: r.now(),
+		}
+		if err := putGzip(ctx, r.Store, seg.Key, bytes.NewReader(wal[r.offset:end])); err != nil {
+			return err
+		}
+		r.manifest.Segments = append(r.manifest.Segments, seg)
+		if err := putJSON(ctx, r.Store, manifestKey(r.manifest.ID), r.manifest); err != nil {
+			r.manifest.Segments = r.manifest.Segments[:len(r.manifest.Segments)-1]
+			return err
+		}
+		r.offset = end
+	}
+
+	if r.offset >= r.CheckpointBytes && r.offset == int64(len(wal)) {
+		done, err := checkpoint(ctx, conn)
+		if err != nil {
+			return err
+		}
+		r.checkpointed = done
+	}
+	return nil
+}
+
+// snapshot checkpoints the whole WAL into the database file, copies the file
+// while writers are held off, and uploads it as a new generation.
+func (r *Replicator) snapshot(ctx context.Context) error {
+	tmp, err := os.CreateTemp("", "policyflow-replica-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := r.copyCheckpointed(ctx, tmp); err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	started := r.now()
+	id := started.Format("20060102T150405Z") + "-" + randomHex(3)
+	m := &Manifest{
+		Generation: Generation{ID: id, StartedAt: started},
+		Snapshot:   prefix + id + "/snapshot.db.gz",
+		Segments:   []Segment{},
+	}
+	if err := putGzip(ctx, r.Store, m.Snapshot, tmp); err != nil {
+		return err
+	}
+	if err := putJSON(ctx, r.Store, manifestKey(id), m); err != nil {
+		return err
+	}
+	gens := append(r.prune(ctx, started), m.Generation)
+	if err := putJSON(ctx, r.Store, generationsKey, gens); err != nil {
+		return err
+	}
+	r.gens, r.manifest = gens, m
+	r.epoch, r.salt, r.offset, r.checkpointed = 0, nil, 0, true
+	log.Printf("replica: generation %s started", id)
+	return nil
+}
+
+func (r *Replicator) copyCheckpointed(ctx context.Context, w io.Writer) error {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	done, err := checkpoint(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if !done {
+		return errors.New("replica: checkpoint could not complete while readers were active")
+	}
+	f, err := os.Open(r.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// prune deletes generations whose successor started before the retention
+// window and returns the ones kept. The newest generation is always kept.
+func (r *Replicator) prune(ctx context.Context, now time.Time) []Generation {
+	cutoff := now.Add(-r.Retention)
+	kept := []Generation{}
+	for i, g := range r.gens {
+		next := now
+		if i+1 < len(r.gens) {
+			next = r.gens[i+1].StartedAt
+		}
+		if i == len(r.gens)-1 || !next.Before(cutoff) {
+			kept = append(kept, g)
+			continue
+		}
+		if err := deleteGeneration(ctx, r.Store, g.ID); err != nil {
+			log.Printf("replica: prune %s: %v", g.ID, err)
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
+
+func deleteGeneration(ctx context.Context, store storage.Store, id string) error {
+	var m Manifest
+	if err := getJSON(ctx, store, manifestKey(id), &m); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	keys := []string{m.Snapshot}
+	for _, s := range m.Segments {
+		keys = append(keys, s.Key)
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		if err := store.Delete(ctx, k); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	return store.Delete(ctx, manifestKey(id))
+}
+
+// checkpoint copies the WAL into the database file and truncates it. It
+// reports whether every frame reached the database file; readers on the
+// read pool can keep the WAL from being truncated even then, in which case
+// SQLite restarts it on a later write.
+func checkpoint(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var busy, logFrames, done int
+	if err := conn.QueryRowContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`).Scan(&busy, &logFrames, &done); err != nil {
+		return false, err
+	}
+	return busy == 0 || logFrames == done, nil
+}
+
+// lastCommit returns the end offset of the last commit frame in wal at or
+// after from, or from when there is none. Frames carrying another epoch's
+// salt are leftovers and end the scan.
+func lastCommit(wal []byte, from int64) int64 {
+	pageSize := int64(binary.BigEndian.Uint32(wal[8:12]))
+	frameSize := frameHeaderSize + pageSize
+	salt := wal[16:24]
+	end := from
+	off := max(from, walHeaderSize)
+	for ; off+frameSize <= int64(len(wal)); off += frameSize {
+		hdr := wal[off : off+frameHeaderSize]
+		if !bytes.Equal(hdr[8:16], salt) {
+			break
+		}
+		if binary.BigEndian.Uint32(hdr[4:8]) != 0 {
+			end = off + frameSize
+		}
+	}
+	return end
+}
+
+func manifestKey(id string) string { return prefix + id + "/manifest.json" }
+
+func loadGenerations(ctx context.Context, store storage.Store) ([]Generation, error) {
+	var gens []Generation
+	err := getJSON(ctx, store, generationsKey, &gens)
+	if errors.Is(err, storage.ErrNotFound) {
+		return []Generation{}, nil
+	}
+	return gens, err
+}
+
+func putGzip(ctx context.Context, store storage.Store, key string, r io.Reader) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, r); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return store.Put(ctx, key, &buf, int64(buf.Len()), "application/gzip")
+}
+
+func putJSON(ctx context.Context, store storage.Store, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, key, bytes.NewReader(b), int64(len(b)), "application/json")
+}
+
+func getJSON(ctx context.Context, store storage.Store, key string, v any) error {
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,109 @@
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"policyflow/internal/storage"
+)
+
+func TestReplicateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := storage.NewLocal(filepath.Join(dir, "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "live.db")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	if _, err := conn.Exec(`PRAGMA journal_mode = WAL; CREATE TABLE notes (n INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := New(conn, path, store)
+	r.now = func() time.Time { return clock }
+	r.CheckpointBytes = 1 // checkpoint after every sync, so segments span epochs
+	if err := r.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	insert := func(from, to int) {
+		t.Helper()
+		for i := from; i <= to; i++ {
+			if _, err := conn.Exec(`INSERT INTO notes (n) VALUES (?)`, i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		clock = clock.Add(time.Minute)
+		if err := r.Sync(ctx); err != nil {
+			t.Fatalf("Sync: %v", err)
+		}
+	}
+	insert(1, 10)
+	insert(11, 20)
+	pointInTime := clock
+	r.CheckpointBytes = 1 << 30
+	insert(21, 30)
+
+	count := func(p string) int {
+		t.Helper()
+		db, err := sql.Open("sqlite", p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM notes`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	latest := filepath.Join(dir, "latest.db")
+	m, err := Restore(ctx, store, latest, time.Time{})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if n := count(latest); n != 30 {
+		t.Errorf("latest restore has %d rows, want 30", n)
+	}
+	if len(m.Segments) != 3 || m.Segments[2].Epoch != 3 {
+		t.Errorf("segments = %+v, want one per epoch", m.Segments)
+	}
+
+	earlier := filepath.Join(dir, "earlier.db")
+	if _, err := Restore(ctx, store, earlier, pointInTime); err != nil {
+		t.Fatalf("Restore at %s: %v", pointInTime, err)
+	}
+	if n := count(earlier); n != 20 {
+		t.Errorf("point-in-time restore has %d rows, want 20", n)
+	}
+
+	// An outside checkpoint restarts the WAL; the replicator notices and
+	// begins a new generation rather than shipping an incomplete history.
+	if _, err := conn.Exec(`PRAGMA wal_checkpoint(TRUNCATE); INSERT INTO notes (n) VALUES (31)`); err != nil {
+		t.Fatal(err)
+	}
+	first := r.manifest.ID
+	clock = clock.Add(time.Minute)
+	if err := r.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if r.manifest.ID == first {
+		t.Error("expected a new generation after an unexpected WAL reset")
+	}
+	if _, err := Restore(ctx, store, filepath.Join(dir, "none.db"), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err != ErrNoReplica {
+		t.Errorf("restore before the first generation: err = %v, want ErrNoReplica", err)
+	}
+}
@@ -0,0 +1,78 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"policyflow/internal/database"
+)
+
+// RenderPDF lays a report out as a landscape A4 table, repeating the header
+// row on every page. Long cells are truncated to fit their column.
+func RenderPDF(title string, res *database.ReportResult) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // core fonts are cp1252
+	pdf.SetMargins(10, 12, 10)
+	pdf.SetAutoPageBreak(true, 12)
+
+	pageW, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	colW := (pageW - left - right) / float64(max(len(res.Columns), 1))
+	const rowH = 6.0
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(226, 232, 240)
+		for _, col := range res.Columns {
+			pdf.CellFormat(colW, rowH+1, tr(col), "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 8)
+	}
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() > 1 {
+			header()
+		}
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-10)
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.CellFormat(0, 4, tr(fmt.Sprintf("PolicyFlow · generated %s · page %d",
+			time.Now().UTC().Format("2006-01-02 15:04 MST"), pdf.PageNo())), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, tr(title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 6, fmt.Sprintf("%d rows", len(res.Rows)), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	header()
+	for _, row := range res.Rows {
+		for _, cell := range row {
+			pdf.CellFormat(colW, rowH, fit(pdf, tr(cell), colW-2), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit truncates s with an ellipsis so it is at most width wide.
+func fit(pdf *gofpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && pdf.GetStringWidth(string(r)+"...") > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "..."
+}
@@ -0,0 +1,259 @@
+// Package reports renders reports as files and delivers scheduled ones by
+// email.
+package reports
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+)
+
+// Schedule frequencies.
+const (
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// deliveryHour is the UTC hour scheduled reports go out.
+const deliveryHour = 6
+
+// NextRun returns the first delivery time after t: Monday 06:00 UTC for
+// weekly schedules and the 1st of the month 06:00 UTC for monthly ones.
+func NextRun(frequency string, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), deliveryHour, 0, 0, 0, time.UTC)
+	switch frequency {
+	case Monthly:
+		next := time.Date(t.Year(), t.Month(), 1, deliveryHour, 0, 0, 0, time.UTC)
+		if !next.After(t) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	default:
+		next := day.AddDate(0, 0, (int(time.Monday)-int(day.Weekday())+7)%7)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+}
+
+// ErrNoRecipients is returned when none of a schedule's recipients may be
+// sent reports any more.
+var ErrNoRecipients = errors.New("no recipient may receive this report")
+
+// Runner renders due schedules and emails them.
+type Runner struct {
+	db     *database.DB
+	mailer *email.Mailer
+	// domains are the email domains reports may be sent to besides the
+	// addresses of active users, from REPORT_RECIPIENT_DOMAINS.
+	domains []string
+}
+
+func NewRunner(db *database.DB, mailer *email.Mailer) *Runner {
+	var domains []string
+	for _, d := range strings.Split(os.Getenv("REPORT_RECIPIENT_DOMAINS"), ",") {
+		if d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@")); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return &Runner{db: db, mailer: mailer, domains: domains}
+}
+
+// RecipientAllowed reports whether reports may be emailed to addr: the
+// address of an active user, or one in an allowlisted domain.
+func (r *Runner) RecipientAllowed(ctx context.Context, addr string) (bool, error) {
+	if at := strings.LastIndexByte(addr, '@'); at >= 0 && slices.Contains(r.domains, strings.ToLower(addr[at+1:])) {
+		return true, nil
+	}
+	u, err := r.db.GetUserByEmail(ctx, addr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return u.DeactivatedAt == nil, nil
+}
+
+// ownerProblem says why s may no longer run, or returns "" while its
+// creator is an active admin of its department: a SuperAdmin, or for a
+// department's schedule a DeptAdmin of or granted that department.
+func (r *Runner) ownerProblem(ctx context.Context, s *database.ReportSchedule) (string, error) {
+	if s.CreatedBy == nil {
+		return "its creator no longer exists", nil
+	}
+	u, err := r.db.GetUserByID(ctx, *s.CreatedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "its creator no longer exists", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if u.DeactivatedAt != nil {
+		return "its creator has been deactivated", nil
+	}
+	switch {
+	case u.Role == "SuperAdmin":
+		return "", nil
+	case u.Role == "DeptAdmin" && s.DepartmentID != nil:
+		if u.DepartmentID != nil && *u.DepartmentID == *s.DepartmentID {
+			return "", nil
+		}
+		granted, err := r.db.HasDepartmentGrant(ctx, u.ID, *s.DepartmentID)
+		if err != nil || granted {
+			return "", err
+		}
+	}
+	return "its creator is no longer an admin of its department", nil
+}
+
+// Run delivers every schedule that is due at now. A failed delivery is
+// recorded on the schedule and retried at its next regular run. A schedule
+// whose creator may no longer send it is disabled instead.
+func (r *Runner) Run(ctx context.Context, now time.Time) error {
+	due, err := r.db.ListDueReportSchedules(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, s := range due {
+		problem, err := r.ownerProblem(ctx, s)
+		if err != nil {
+			return err
+		}
+		if problem != "" {
+			log.Printf("scheduled report %q disabled: %s", s.Name, problem)
+			if err := r.db.DisableReportSchedule(ctx, s.ID, "disabled: "+problem); err != nil {
+				return err
+			}
+			continue
+		}
+		lastError := ""
+		if _, err := r.Deliver(ctx, s, now); err != nil {
+			log.Printf("scheduled report %q: %v", s.Name, err)
+			lastError = err.Error()
+		}
+		if err := r.db.MarkReportScheduleRun(ctx, s.ID, now, NextRun(s.Frequency, now), lastError); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliver renders s and emails it to each recipient still allowed to
+// receive it, returning how many were sent and the recipients it could not
+// reach.
+func (r *Runner) Deliver(ctx context.Context, s *database.ReportSchedule, now time.Time) (int, error) {
+	var to []string
+	for _, addr := range s.Recipients {
+		ok, err := r.RecipientAllowed(ctx, addr)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			to = append(to, addr)
+		}
+	}
+	if len(to) == 0 {
+		return 0, ErrNoRecipients
+	}
+	res, err := r.db.Reader().RunReport(ctx, s.Definition, s.DepartmentID)
+	if err != nil {
+		return 0, err
+	}
+	title := fmt.Sprintf("%s — %s", s.Name, now.UTC().Format("2 Jan 2006"))
+	filename := fmt.Sprintf("%s-%s", slug(s.Name), now.UTC().Format("2006-01-02"))
+	var att email.Attachment
+	switch s.Format {
+	case "pdf":
+		data, err := RenderPDF(title, res)
+		if err != nil {
+			return 0, err
+		}
+		att = email.Attachment{Filename: filename + ".pdf", ContentType: "application/pdf", Data: data}
+	default:
+		data, err := RenderCSV(res)
+		if err != nil {
+			return 0, err
+		}
+		att = email.Attachment{Filename: filename + ".csv", ContentType: "text/csv; charset=utf-8", Data: data}
+	}
+
+	mail := r.mailer.Batch()
+	defer mail.Close()
+	for _, addr := range to {
+		_ = mail.SendScheduledReport(addr, title, len(res.Rows), att)
+	}
+	return len(to), mail.Err()
+}
+
+// Schedule checks for due reports every interval until ctx is cancelled.
+func (r *Runner) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ticker.C:
+			if err := r.Run(ctx, t); err != nil {
+				log.Printf("scheduled reports: %v", err)
+			}
+		}
+	}
+}
+
+// RenderCSV renders a report with a header row, neutralizing cells that a
+// spreadsheet would evaluate as formulas.
+func RenderCSV(res *database.ReportResult) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(res.Columns); err != nil {
+		return nil, err
+	}
+	for _, row := range res.Rows {
+		out := make([]string, len(row))
+		for i, cell := range row {
+			if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+				cell = "'" + cell
+			}
+			out[i] = cell
+		}
+		if err := w.Write(out); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	out := strings.TrimSuffix(b.String(), "-")
+	if out == "" {
+		return "report"
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	"policyflow/internal/testutil"
+)
+
+func TestNextRun(t *testing.T) {
+	wed := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	mon := time.Date(2026, 3, 9, 6, 0, 0, 0, time.UTC)
+	cases := []struct {
+		freq string
+		at   time.Time
+		want time.Time
+	}{
+		{Weekly, wed, mon},
+		{Weekly, mon, mon.AddDate(0, 0, 7)},
+		{Weekly, mon.Add(-time.Minute), mon},
+		{Monthly, wed, time.Date(2026, 4, 1, 6, 0, 0, 0, time.UTC)},
+		{Monthly, time.Date(2026, 12, 1, 5, 0, 0, 0, time.UTC), time.Date(2026, 12, 1, 6, 0, 0, 0, time.UTC)},
+		{Monthly, time.Date(2026, 12, 1, 6, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 6, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		if got := NextRun(tc.freq, tc.at); !got.Equal(tc.want) {
+			t.Errorf("NextRun(%s, %s) = %s; want %s", tc.freq, tc.at, got, tc.want)
+		}
+	}
+}
+
+// TestRunner_DeliversDueSchedules verifies that only due schedules are
+// delivered and that each is moved to its next run.
+func TestRunner_DeliversDueSchedules(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	now := time.Date(2026, 3, 9, 6, 5, 0, 0, time.UTC)
+	def := database.ReportDefinition{Entity: "policies"}
+	admin, _ := db.CreateUser(ctx, "a@example.com", "Admin", "SuperAdmin", nil, nil)
+
+	due := &database.ReportSchedule{Name: "Weekly PDF", Definition: def, Frequency: Weekly, Format: "pdf",
+		Recipients: []string{"a@example.com"}, CreatedBy: &admin.ID, NextRunAt: now.Add(-5 * time.Minute)}
+	later := &database.ReportSchedule{Name: "Monthly", Definition: def, Frequency: Monthly, Format: "csv",
+		Recipients: []string{"a@example.com"}, CreatedBy: &admin.ID, NextRunAt: now.Add(time.Hour)}
+	for _, s := range []*database.ReportSchedule{due, later} {
+		if err := db.CreateReportSchedule(ctx, s); err != nil {
+			t.Fatalf("create schedule: %v", err)
+		}
+	}
+
+	if err := NewRunner(db, email.New()).Run(ctx, now); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got, _ := db.GetReportSchedule(ctx, due.ID)
+	if got.LastRunAt == nil || got.LastError != "" || !got.NextRunAt.Equal(now.AddDate(0, 0, 7).Add(-5*time.Minute)) {
+		t.Errorf("due schedule after run = %+v", got)
+	}
+	got, _ = db.GetReportSchedule(ctx, later.ID)
+	if got.LastRunAt != nil {
+		t.Errorf("schedule not yet due was run")
+	}
+}
+
+// TestRunner_ChecksOwnerAndRecipients verifies that a schedule whose creator
+// is no longer an admin of its department is disabled rather than sent, and
+// that reports go only to active users and allowlisted domains.
+func TestRunner_ChecksOwnerAndRecipients(t *testing.T) {
+	t.Setenv("REPORT_RECIPIENT_DOMAINS", "partner.example")
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	now := time.Date(2026, 3, 9, 6, 5, 0, 0, time.UTC)
+	def := database.ReportDefinition{Entity: "policies"}
+	hr, _ := db.CreateDepartment(ctx, "HR", "")
+	ops, _ := db.CreateDepartment(ctx, "Ops", "")
+	lee, _ := db.CreateUser(ctx, "lee@example.com", "Lee", "DeptAdmin", nil, &hr.ID)
+	gone, _ := db.CreateUser(ctx, "gone@example.com", "Gone", "Staff", nil, nil)
+	db.DeactivateUser(ctx, gone.ID)
+
+	kept := &database.ReportSchedule{Name: "HR", Definition: def, Frequency: Weekly, Format: "csv", DepartmentID: &hr.ID,
+		Recipients: []string{"lee@example.com", "gone@example.com", "audit@partner.example", "x@elsewhere.example"},
+		CreatedBy:  &lee.ID, NextRunAt: now.Add(-time.Minute)}
+	orphaned := &database.ReportSchedule{Name: "Ops", Definition: def, Frequency: Weekly, Format: "csv", DepartmentID: &ops.ID,
+		Recipients: []string{"lee@example.com"}, CreatedBy: &lee.ID, NextRunAt: now.Add(-time.Minute)}
+	for _, s := range []*database.ReportSchedule{kept, orphaned} {
+		if err := db.CreateReportSchedule(ctx, s); err != nil {
+			t.Fatalf("create schedule: %v", err)
+		}
+	}
+
+	r := NewRunner(db, email.New())
+	if sent, err := r.Deliver(ctx, kept, now); err != nil || sent != 2 {
+		t.Errorf("Deliver = %d, %v; want 2 allowed recipients", sent, err)
+	}
+	if err := r.Run(ctx, now); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got, _ := db.GetReportSchedule(ctx, kept.ID); got.DisabledAt != nil || got.LastRunAt == nil {
+		t.Errorf("schedule of a current DeptAdmin = %+v; want sent", got)
+	}
+	got, _ := db.GetReportSchedule(ctx, orphaned.ID)
+	if got.DisabledAt == nil || got.LastRunAt != nil {
+		t.Errorf("schedule for another department = %+v; want disabled, not sent", got)
+	}
+	if due, _ := db.ListDueReportSchedules(ctx, now.AddDate(0, 1, 0)); len(due) != 1 || due[0].ID != kept.ID {
+		t.Errorf("due schedules = %d; want only the enabled one", len(due))
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	res := &database.ReportResult{Columns: []string{"policy", "count"}, Rows: [][]string{{"Code of Conduct — 2026", "3"}}}
+	data, err := RenderPDF("Acknowledgements", res)
+	if err != nil {
+		t.Fatalf("RenderPDF: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Errorf("output is not a PDF")
+	}
+}
@@ -0,0 +1,277 @@
+package reports
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"policyflow/internal/database"
+)
+
+// workbook wraps an excelize file with the header and status styles shared
+// by PolicyFlow's spreadsheets.
+type workbook struct {
+	f      *excelize.File
+	sheets map[string]bool
+	header int
+	status map[string]int // matrix cell status → fill style
+}
+
+func newWorkbook() (*workbook, error) {
+	w := &workbook{f: excelize.NewFile(), sheets: map[string]bool{}, status: map[string]int{}}
+	var err error
+	w.header, err = w.f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"334155"}},
+		Alignment: &excelize.Alignment{Vertical: "center", WrapText: true},
+		Border:    []excelize.Border{{Type: "bottom", Color: "1E293B", Style: 2}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for status, color := range map[string]string{
+		database.AckDone:    "DCFCE7",
+		database.AckPending: "FEF9C3",
+		database.AckOverdue: "FEE2E2",
+	} {
+		if w.status[status], err = w.f.NewStyle(&excelize.Style{
+			Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}},
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// sheet adds a worksheet, making name valid and unique. The first call
+// reuses the default sheet.
+func (w *workbook) sheet(name string) (string, error) {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "Sheet"
+	}
+	base := []rune(name)
+	for n := 2; w.sheets[strings.ToLower(name)] || len([]rune(name)) > 31; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		if len(base)+len(suffix) > 31 {
+			base = base[:31-len(suffix)]
+		}
+		name = string(base) + suffix
+	}
+	var err error
+	if len(w.sheets) == 0 {
+		err = w.f.SetSheetName("Sheet1", name)
+	} else {
+		_, err = w.f.NewSheet(name)
+	}
+	w.sheets[strings.ToLower(name)] = true
+	return name, err
+}
+
+// table writes a styled, filterable header row followed by rows, freezing
+// the header and the first freezeCols columns.
+func (w *workbook) table(sheet string, header []string, rows [][]any, freezeCols int) error {
+	if err := w.f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	last, _ := excelize.CoordinatesToCellName(len(header), 1)
+	if err := w.f.SetCellStyle(sheet, "A1", last, w.header); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := w.f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	end, _ := excelize.CoordinatesToCellName(len(header), len(rows)+1)
+	if err := w.f.AutoFilter(sheet, "A1:"+end, nil); err != nil {
+		return err
+	}
+	topLeft, _ := excelize.CoordinatesToCellName(freezeCols+1, 2)
+	if err := w.f.SetPanes(sheet, &excelize.Panes{
+		Freeze: true, XSplit: freezeCols, YSplit: 1, TopLeftCell: topLeft, ActivePane: "bottomRight",
+	}); err != nil {
+		return err
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	return w.f.SetColWidth(sheet, "A", lastCol, 18)
+}
+
+func (w *workbook) bytes() ([]byte, error) {
+	defer w.f.Close()
+	buf, err := w.f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MatrixXLSX renders the acknowledgement matrix with one sheet per
+// department. Each sheet only has columns for policies someone in that
+// department must acknowledge; cells show the acknowledgement date or
+// Pending/Overdue, colour-coded, and any active exception.
+func MatrixXLSX(m *database.AckMatrix) ([]byte, error) {
+	w, err := newWorkbook()
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groupByDepartment(m) {
+		var cols []int
+		for j := range m.Policies {
+			for _, i := range group.users {
+				if m.Cells[i][j].Status != database.AckNotRequired {
+					cols = append(cols, j)
+					break
+				}
+			}
+		}
+		header := []string{"Name", "Email", "Role"}
+		for _, j := range cols {
+			header = append(header, m.Policies[j].Title)
+		}
+		rows := make([][]any, len(group.users))
+		for r, i := range group.users {
+			u := m.Users[i]
+			rows[r] = []any{u.Name, u.Email, u.Role}
+			for _, j := range cols {
+				rows[r] = append(rows[r], MatrixCellText(m.Cells[i][j]))
+			}
+		}
+
+		sheet, err := w.sheet(group.name)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.table(sheet, header, rows, 1); err != nil {
+			return nil, err
+		}
+		for r, i := range group.users {
+			for c, j := range cols {
+				if style, ok := w.status[m.Cells[i][j].Status]; ok {
+					cell, _ := excelize.CoordinatesToCellName(c+4, r+2)
+					if err := w.f.SetCellStyle(sheet, cell, cell, style); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		w.f.SetColWidth(sheet, "A", "B", 28)
+		w.f.SetRowHeight(sheet, 1, 32)
+	}
+	return w.bytes()
+}
+
+// DepartmentComplianceXLSX renders a Summary sheet with one row per
+// department, followed by a sheet per department listing each user's
+// outstanding policies and active exceptions.
+func DepartmentComplianceXLSX(m *database.AckMatrix) ([]byte, error) {
+	w, err := newWorkbook()
+	if err != nil {
+		return nil, err
+	}
+	summary, err := w.sheet("Summary")
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]any
+	for _, d := range m.ByDepartment() {
+		rows = append(rows, []any{d.Department, d.Users, d.Required, d.Acknowledged, d.Pending, d.Overdue, d.Exceptions, d.AckPercentage / 100})
+	}
+	if err := w.table(summary, []string{"Department", "Users", "Required", "Acknowledged", "Pending", "Overdue", "Active exceptions", "Acknowledged %"}, rows, 1); err != nil {
+		return nil, err
+	}
+	pct, err := w.f.NewStyle(&excelize.Style{NumFmt: 10}) // 0.00%
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 {
+		end, _ := excelize.CoordinatesToCellName(8, len(rows)+1)
+		if err := w.f.SetCellStyle(summary, "H2", end, pct); err != nil {
+			return nil, err
+		}
+	}
+	w.f.SetColWidth(summary, "A", "A", 28)
+
+	for _, group := range groupByDepartment(m) {
+		rows := make([][]any, len(group.users))
+		for r, i := range group.users {
+			u := m.Users[i]
+			var required, done, pending, overdue int
+			var outstanding, excepted []string
+			for j, cell := range m.Cells[i] {
+				if cell.ExceptionUntil != nil {
+					excepted = append(excepted, m.Policies[j].Title+" (until "+cell.ExceptionUntil.UTC().Format(time.DateOnly)+")")
+				}
+				switch cell.Status {
+				case database.AckDone:
+					done++
+				case database.AckPending:
+					pending++
+					outstanding = append(outstanding, m.Policies[j].Title)
+				case database.AckOverdue:
+					overdue++
+					outstanding = append(outstanding, m.Policies[j].Title+" (overdue)")
+				default:
+					continue
+				}
+				required++
+			}
+			rows[r] = []any{u.Name, u.Email, required, done, pending, overdue, strings.Join(outstanding, "; "), strings.Join(excepted, "; ")}
+		}
+		sheet, err := w.sheet(group.name)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.table(sheet, []string{"Name", "Email", "Required", "Acknowledged", "Pending", "Overdue", "Outstanding", "Active exceptions"}, rows, 1); err != nil {
+			return nil, err
+		}
+		w.f.SetColWidth(sheet, "A", "B", 28)
+		w.f.SetColWidth(sheet, "G", "H", 50)
+	}
+	return w.bytes()
+}
+
+// MatrixCellText is how a matrix cell is written in exports, noting any
+// active exception.
+func MatrixCellText(cell database.MatrixCell) string {
+	var text string
+	switch cell.Status {
+	case database.AckDone:
+		text = cell.AcknowledgedAt.UTC().Format(time.DateOnly)
+	case database.AckPending:
+		text = "Pending"
+	case database.AckOverdue:
+		text = "Overdue"
+	}
+	if cell.ExceptionUntil != nil {
+		text += " (exception until " + cell.ExceptionUntil.UTC().Format(time.DateOnly) + ")"
+	}
+	return text
+}
+
+type departmentGroup struct {
+	name  string
+	users []int // indexes into AckMatrix.Users
+}
+
+// groupByDepartment splits the matrix's users by department, keeping their
+// order; BuildAckMatrix already sorts them by department.
+func groupByDepartment(m *database.AckMatrix) []departmentGroup {
+	var out []departmentGroup
+	for i, u := range m.Users {
+		name := database.UserDepartment(u)
+		if len(out) == 0 || out[len(out)-1].name != name {
+			out = append(out, departmentGroup{name: name})
+		}
+		out[len(out)-1].users = append(out[len(out)-1].users, i)
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// chunkSize is the size of the INSTREAM chunks sent to clamd.
+const chunkSize = 64 << 10
+
+// ClamAV scans files with a clamd daemon using its INSTREAM command. The
+// daemon's StreamMaxLength must be at least the largest upload allowed.
+type ClamAV struct {
+	Network string // "tcp" or "unix"
+	Addr    string
+	Timeout time.Duration
+}
+
+func (s *ClamAV) Name() string { return "clamav" }
+
+func (s *ClamAV) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	d := net.Dialer{Timeout: s.Timeout}
+	conn, err := d.DialContext(ctx, s.Network, s.Addr)
+	if err != nil {
+		return Result{}, fmt.Errorf("clamav: %w", err)
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(s.Timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return Result{}, fmt.Errorf("clamav: %w", err)
+	}
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, werr := conn.Write(buf[:4+n]); werr != nil {
+				// clamd hangs up when the stream exceeds its limit; its
+				// reply says so.
+				break
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return Result{}, fmt.Errorf("clamav: read upload: %w", err)
+		}
+	}
+	conn.Write([]byte{0, 0, 0, 0})
+
+	reply, err := io.ReadAll(io.LimitReader(conn, 4096))
+	if err != nil && len(reply) == 0 {
+		return Result{}, fmt.Errorf("clamav: %w", err)
+	}
+	return parseReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseReply interprets clamd's answer: "stream: OK",
+// "stream: <signature> FOUND", or "<message> ERROR".
+func parseReply(reply string) (Result, error) {
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	case reply == "":
+		return Result{}, errors.New("clamav: no reply")
+	default:
+		return Result{}, fmt.Errorf("clamav: %s", reply)
+	}
+}
@@ -0,0 +1,58 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP posts the file as the request body to an external scanning service,
+// which answers 200 with {"infected": bool, "signature": "..."}. Token, if
+// set, is sent as a bearer token.
+type HTTP struct {
+	URL     string
+	Token   string
+	Timeout time.Duration
+	Client  *http.Client // nil = http.DefaultClient
+}
+
+func (s *HTTP) Name() string { return "http" }
+
+func (s *HTTP) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, r)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("scanner: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("scanner: %s", resp.Status)
+	}
+	var out struct {
+		Infected  bool   `json:"infected"`
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil {
+		return Result{}, fmt.Errorf("scanner: decode reply: %w", err)
+	}
+	if out.Infected && out.Signature == "" {
+		out.Signature = "unknown"
+	}
+	return Result{Infected: out.Infected, Signature: out.Signature}, nil
+}
@@ -0,0 +1,61 @@
+// Package scan checks uploaded files for malware before they are stored.
+// Drivers either talk to a ClamAV daemon or post the file to an external
+// scanning API.
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Result is a scanner's verdict on one file.
+type Result struct {
+	Infected  bool
+	Signature string // name of the detected threat when Infected
+}
+
+// Scanner checks a file's contents for malware. An error means the file
+// could not be checked, not that it is unsafe.
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+// FromEnv builds the scanner selected by SCANNER_DRIVER. It returns nil
+// when scanning is off, which is the default.
+func FromEnv() (Scanner, error) {
+	timeout := 2 * time.Minute
+	if v := os.Getenv("SCANNER_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid SCANNER_TIMEOUT %q", v)
+		}
+		timeout = d
+	}
+	switch d := os.Getenv("SCANNER_DRIVER"); d {
+	case "", "none":
+		return nil, nil
+	case "clamav":
+		addr := os.Getenv("CLAMAV_ADDR")
+		if addr == "" {
+			addr = "tcp://127.0.0.1:3310"
+		}
+		network, address, ok := strings.Cut(addr, "://")
+		if !ok || (network != "tcp" && network != "unix") {
+			return nil, fmt.Errorf("CLAMAV_ADDR must be tcp://host:port or unix:///path/to/clamd.sock")
+		}
+		return &ClamAV{Network: network, Addr: address, Timeout: timeout}, nil
+	case "http":
+		url := os.Getenv("SCANNER_URL")
+		if url == "" {
+			return nil, fmt.Errorf("SCANNER_URL is required for the http scanner")
+		}
+		return &HTTP{URL: url, Token: os.Getenv("SCANNER_TOKEN"), Timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unknown SCANNER_DRIVER %q (want none, clamav, or http)", d)
+	}
+}
@@ -0,0 +1,107 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd answers INSTREAM requests, flagging streams containing the
+// EICAR test string.
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				cmd := make([]byte, len("zINSTREAM\x00"))
+				if _, err := io.ReadFull(conn, cmd); err != nil || string(cmd) != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+				var data bytes.Buffer
+				for {
+					var n uint32
+					if err := binary.Read(conn, binary.BigEndian, &n); err != nil {
+						return
+					}
+					if n == 0 {
+						break
+					}
+					io.CopyN(&data, conn, int64(n))
+				}
+				if strings.Contains(data.String(), eicar) {
+					conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+				} else {
+					conn.Write([]byte("stream: OK\x00"))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// TestClamAV_Instream verifies that files are streamed to clamd in chunks
+// and its verdict is reported.
+func TestClamAV_Instream(t *testing.T) {
+	s := &ClamAV{Network: "tcp", Addr: fakeClamd(t), Timeout: 5 * time.Second}
+	ctx := context.Background()
+
+	big := strings.Repeat("policy text ", 20000) // several chunks
+	res, err := s.Scan(ctx, strings.NewReader(big))
+	if err != nil || res.Infected {
+		t.Fatalf("clean file: %+v, %v", res, err)
+	}
+	res, err = s.Scan(ctx, strings.NewReader(big+eicar))
+	if err != nil || !res.Infected || res.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("infected file: %+v, %v", res, err)
+	}
+	if _, err := parseReply("INSTREAM size limit exceeded. ERROR"); err == nil {
+		t.Error("clamd error reply: want error")
+	}
+}
+
+// TestHTTP_Verdict verifies the external API contract: the file as the
+// body, a bearer token, and a JSON verdict.
+func TestHTTP_Verdict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte(eicar)) {
+			w.Write([]byte(`{"infected":true,"signature":"EICAR"}`))
+			return
+		}
+		w.Write([]byte(`{"infected":false}`))
+	}))
+	defer srv.Close()
+
+	s := &HTTP{URL: srv.URL, Token: "s3cret", Timeout: 5 * time.Second}
+	if res, err := s.Scan(context.Background(), strings.NewReader(eicar)); err != nil || !res.Infected || res.Signature != "EICAR" {
+		t.Errorf("infected file: %+v, %v", res, err)
+	}
+	s.Token = "wrong"
+	if _, err := s.Scan(context.Background(), strings.NewReader("hello")); err == nil {
+		t.Error("rejected token: want error")
+	}
+}
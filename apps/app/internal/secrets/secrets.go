@@ -0,0 +1,108 @@
+// Package secrets fills in the secret settings that are not given as plain
+// environment variables: from files, such as Docker or Kubernetes secret
+// mounts, or from a secrets manager. It runs before anything reads the
+// environment, so the rest of PolicyFlow keeps reading os.Getenv.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Names are the settings that may come from a file or a secrets manager.
+var Names = []string{
+	"JWT_SECRET",
+	"DB_PATH", "DATABASE_URL",
+	"SMTP_USER", "SMTP_PASSWORD",
+	"HR_WEBHOOK_TOKEN", "LMS_WEBHOOK_TOKEN", "SIEM_TOKEN", "SCANNER_TOKEN",
+	"BAMBOOHR_API_KEY", "WORKDAY_USERNAME", "WORKDAY_PASSWORD",
+	"S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY",
+	"GCS_ACCESS_TOKEN",
+}
+
+// Provider looks secrets up by setting name in a secrets manager.
+type Provider interface {
+	Name() string
+	// Lookup returns the values it holds for names; names it does not
+	// hold are left out.
+	Lookup(ctx context.Context, names []string) (map[string]string, error)
+}
+
+// FromEnv builds the provider selected by SECRETS_PROVIDER. It returns nil
+// when none is configured, which is the default.
+func FromEnv() (Provider, error) {
+	switch p := os.Getenv("SECRETS_PROVIDER"); p {
+	case "", "none":
+		return nil, nil
+	case "vault":
+		addr, path := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_SECRET_PATH")
+		token, err := fromFile("VAULT_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+		if addr == "" || path == "" || token == "" {
+			return nil, fmt.Errorf("VAULT_ADDR, VAULT_TOKEN, and VAULT_SECRET_PATH are required for the vault provider")
+		}
+		return &Vault{Addr: addr, Token: token, Path: path, Namespace: os.Getenv("VAULT_NAMESPACE")}, nil
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_PROVIDER %q (want none or vault)", p)
+	}
+}
+
+// Load sets each of Names missing from the environment: from the file
+// named by <NAME>_FILE, or else from the SECRETS_PROVIDER. A variable that
+// is already set always wins. It returns where each loaded setting came
+// from, for the startup log; values are never logged.
+func Load(ctx context.Context) (map[string]string, error) {
+	loaded := map[string]string{}
+	var missing []string
+	for _, name := range Names {
+		if os.Getenv(name) != "" {
+			continue
+		}
+		v, err := fromFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if v == "" {
+			missing = append(missing, name)
+			continue
+		}
+		os.Setenv(name, v)
+		loaded[name] = "file"
+	}
+
+	p, err := FromEnv()
+	if err != nil || p == nil || len(missing) == 0 {
+		return loaded, err
+	}
+	values, err := p.Lookup(ctx, missing)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+	for name, v := range values {
+		os.Setenv(name, v)
+		loaded[name] = p.Name()
+	}
+	return loaded, nil
+}
+
+// fromFile returns name from the environment or, when it is unset, the
+// contents of the file named by <name>_FILE without the trailing newline
+// that editors and `echo` add.
+func fromFile(name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
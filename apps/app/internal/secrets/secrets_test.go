@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoad_EnvThenFileThenVault checks the order settings are taken in: a
+// variable already set, then a <NAME>_FILE mount, then Vault, reading the
+// Vault token itself from a file.
+func TestLoad_EnvThenFileThenVault(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/policyflow" || r.Header.Get("X-Vault-Token") != "s.root" {
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"JWT_SECRET":"from-vault","SMTP_USER":"from-vault","SMTP_PASSWORD":"from-vault"},"metadata":{"version":3}}}`))
+	}))
+	defer vault.Close()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	for _, name := range Names {
+		t.Setenv(name, "")
+	}
+	t.Setenv("SMTP_USER", "from-env")
+	t.Setenv("SMTP_PASSWORD_FILE", write("smtp_password", "from-file\n"))
+	t.Setenv("SECRETS_PROVIDER", "vault")
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "")
+	t.Setenv("VAULT_TOKEN_FILE", write("vault_token", "s.root\n"))
+	t.Setenv("VAULT_SECRET_PATH", "secret/data/policyflow")
+
+	loaded, err := Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for name, want := range map[string]string{"SMTP_USER": "from-env", "SMTP_PASSWORD": "from-file", "JWT_SECRET": "from-vault", "SIEM_TOKEN": ""} {
+		if got := os.Getenv(name); got != want {
+			t.Errorf("%s = %q; want %q", name, got, want)
+		}
+	}
+	if len(loaded) != 2 || loaded["SMTP_PASSWORD"] != "file" || loaded["JWT_SECRET"] != "vault" {
+		t.Errorf("loaded = %v", loaded)
+	}
+
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("VAULT_TOKEN_FILE", write("vault_token", "s.revoked"))
+	if _, err := Load(context.Background()); err == nil {
+		t.Error("Load with a rejected Vault token succeeded")
+	}
+}
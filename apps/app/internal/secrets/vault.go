@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Vault reads settings from one HashiCorp Vault secret whose keys are the
+// setting names, such as JWT_SECRET. Both KV engine versions work: for
+// version 2, Path includes the data/ segment (secret/data/policyflow).
+type Vault struct {
+	Addr      string // e.g. https://vault.example.com:8200
+	Token     string
+	Path      string
+	Namespace string       // Vault Enterprise namespace; optional
+	Client    *http.Client // optional; defaults to one with a 10-second timeout
+}
+
+func (v *Vault) Name() string { return "vault" }
+
+func (v *Vault) Lookup(ctx context.Context, names []string) (map[string]string, error) {
+	url := strings.TrimRight(v.Addr, "/") + "/v1/" + strings.TrimLeft(v.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("read %s: status %d", v.Path, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("read %s: %w", v.Path, err)
+	}
+	data := body.Data
+	// KV version 2 nests the values under data.data, next to metadata.
+	if inner, ok := data["data"].(map[string]any); ok && data["metadata"] != nil {
+		data = inner
+	}
+	out := map[string]string{}
+	for _, name := range names {
+		if s, ok := data[name].(string); ok && s != "" {
+			out[name] = s
+		}
+	}
+	return out, nil
+}
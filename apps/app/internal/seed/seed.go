@@ -1,6 +1,7 @@
 package seed
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log"
@@ -12,7 +13,7 @@ import (
 // adminEmail and adminName configure the bootstrap admin account; they fall
 // back to "admin@policyflow.local" / "Policy Admin" when empty.
 // It is safe to call on every startup — it is idempotent.
-func Run(db *database.DB, adminEmail, adminName string) error {
+func Run(ctx context.Context, db *database.DB, adminEmail, adminName string) error {
 	if adminEmail == "" {
 		adminEmail = "admin@policyflow.local"
 	}
@@ -21,7 +22,7 @@ func Run(db *database.DB, adminEmail, adminName string) error {
 	}
 
 	// Check if admin user already exists.
-	_, err := db.GetUserByEmail(adminEmail)
+	_, err := db.GetUserByEmail(ctx, adminEmail)
 	if err == nil {
 		return nil // already seeded
 	}
@@ -32,34 +33,34 @@ func Run(db *database.DB, adminEmail, adminName string) error {
 	log.Println("Seeding database with initial data…")
 
 	// Create sample departments.
-	hr, err := db.CreateDepartment("Human Resources", "HR policies and employee relations")
+	hr, err := db.CreateDepartment(ctx, "Human Resources", "HR policies and employee relations")
 	if err != nil {
 		return err
 	}
 	log.Printf("  Created department: %s (id=%s)", hr.Name, hr.ID)
 
-	eng, err := db.CreateDepartment("Engineering", "Technical standards and engineering practices")
+	eng, err := db.CreateDepartment(ctx, "Engineering", "Technical standards and engineering practices")
 	if err != nil {
 		return err
 	}
 	log.Printf("  Created department: %s (id=%s)", eng.Name, eng.ID)
 
 	// Create admin user (SuperAdmin, no department).
-	admin, err := db.CreateUser(adminEmail, adminName, "SuperAdmin", nil, nil)
+	admin, err := db.CreateUser(ctx, adminEmail, adminName, "SuperAdmin", nil, nil)
 	if err != nil {
 		return err
 	}
 	log.Printf("  Created admin user: %s (id=%s)", admin.Email, admin.ID)
 
 	// Create a staff test user in HR.
-	staff, err := db.CreateUser("staff@policyflow.local", "Test Staff", "Staff", &admin.ID, &hr.ID)
+	staff, err := db.CreateUser(ctx, "staff@policyflow.local", "Test Staff", "Staff", &admin.ID, &hr.ID)
 	if err != nil {
 		return err
 	}
 	log.Printf("  Created staff user: %s (id=%s)", staff.Email, staff.ID)
 
 	// Create a sample org-wide policy.
-	policy, err := db.CreatePolicy("Employee Code of Conduct", "Human Resources", nil, "organization")
+	policy, err := db.CreatePolicy(ctx, "Employee Code of Conduct", "", &hr.ID, "organization", &admin.ID)
 	if err != nil {
 		return err
 	}
@@ -101,33 +102,33 @@ If you observe or experience a violation of this policy, report it immediately t
 
 By acknowledging this policy, you confirm that you have read, understood, and agree to comply with its terms.
 `
-	version, err := db.CreatePolicyVersion(policy.ID, content, "v1.0.0", "Initial release")
+	version, err := db.CreatePolicyVersion(ctx, policy.ID, content, "v1.0.0", "Initial release", &admin.ID)
 	if err != nil {
 		return err
 	}
-	if err := db.SetPolicyCurrentVersion(policy.ID, version.ID); err != nil {
+	if err := db.SetPolicyCurrentVersion(ctx, policy.ID, version.ID); err != nil {
 		return err
 	}
-	if err := db.UpdatePolicy(policy.ID, policy.Title, "Published", policy.Department, nil, "organization"); err != nil {
+	if err := db.UpdatePolicy(ctx, policy.ID, policy.Title, "Published", "", &hr.ID, "organization"); err != nil {
 		return err
 	}
 	log.Printf("  Created policy version %s (id=%s)", version.VersionString, version.ID)
 
 	// Create a sample department-scoped policy for Engineering.
-	engPolicy, err := db.CreatePolicy("Engineering Security Standards", "Engineering", &eng.ID, "department")
+	engPolicy, err := db.CreatePolicy(ctx, "Engineering Security Standards", "", &eng.ID, "department", &admin.ID)
 	if err != nil {
 		return err
 	}
-	engVersion, err := db.CreatePolicyVersion(engPolicy.ID,
+	engVersion, err := db.CreatePolicyVersion(ctx, engPolicy.ID,
 		"# Engineering Security Standards\n\nAll engineers must follow secure coding practices and review guidelines.",
-		"v1.0.0", "Initial release")
+		"v1.0.0", "Initial release", &admin.ID)
 	if err != nil {
 		return err
 	}
-	if err := db.SetPolicyCurrentVersion(engPolicy.ID, engVersion.ID); err != nil {
+	if err := db.SetPolicyCurrentVersion(ctx, engPolicy.ID, engVersion.ID); err != nil {
 		return err
 	}
-	if err := db.UpdatePolicy(engPolicy.ID, engPolicy.Title, "Published", engPolicy.Department, &eng.ID, "department"); err != nil {
+	if err := db.UpdatePolicy(ctx, engPolicy.ID, engPolicy.Title, "Published", engPolicy.Department, &eng.ID, "department"); err != nil {
 		return err
 	}
 	log.Printf("  Created department policy: %s (id=%s)", engPolicy.Title, engPolicy.ID)
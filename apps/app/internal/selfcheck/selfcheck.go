@@ -0,0 +1,200 @@
+// Package selfcheck tests a PolicyFlow deployment's configuration and the
+// services it depends on, so that a misconfiguration shows up in
+// `policyflow --check` or the admin self-check rather than as errors when
+// users first reach the broken feature.
+package selfcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	"policyflow/internal/storage"
+)
+
+// checkTimeout bounds each check, so one unreachable service does not hold
+// up the report.
+const checkTimeout = 10 * time.Second
+
+// Check outcomes, from best to worst.
+const (
+	StatusOK      = "ok"
+	StatusSkipped = "skipped"
+	StatusWarning = "warning"
+	StatusFailed  = "failed"
+)
+
+var severity = map[string]int{StatusOK: 0, StatusSkipped: 1, StatusWarning: 2, StatusFailed: 3}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of every check. Status is the worst of them.
+type Report struct {
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Failed reports whether any check failed.
+func (r Report) Failed() bool { return r.Status == StatusFailed }
+
+// Checker runs the checks. Any service left nil is reported as skipped.
+type Checker struct {
+	DB     *database.DB
+	Store  storage.Store
+	Mailer *email.Mailer
+	// ConfigErr is the error reading the configuration returned, if any.
+	ConfigErr error
+}
+
+// Run runs every check in turn and returns the report.
+func (ch *Checker) Run(ctx context.Context) Report {
+	r := Report{Status: StatusOK, CheckedAt: time.Now().UTC()}
+	for _, c := range []struct {
+		name string
+		run  func(ctx context.Context) (string, string)
+	}{
+		{"config", ch.config},
+		{"database", ch.database},
+		{"migrations", ch.migrations},
+		{"smtp", ch.smtp},
+		{"storage", ch.storage},
+	} {
+		start := time.Now()
+		status, detail := timed(ctx, c.run)
+		r.Checks = append(r.Checks, Result{Name: c.name, Status: status, Detail: detail, DurationMS: time.Since(start).Milliseconds()})
+		if severity[status] > severity[r.Status] {
+			r.Status = status
+		}
+	}
+	return r
+}
+
+// timed runs check with checkTimeout. Mail servers and object stores do
+// not all honour a context, so a check that overruns is reported as failed
+// and left to finish in the background.
+func timed(ctx context.Context, check func(ctx context.Context) (string, string)) (string, string) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	type outcome struct{ status, detail string }
+	done := make(chan outcome, 1)
+	go func() {
+		status, detail := check(ctx)
+		done <- outcome{status, detail}
+	}()
+	select {
+	case o := <-done:
+		return o.status, o.detail
+	case <-ctx.Done():
+		return StatusFailed, fmt.Sprintf("no answer within %s", checkTimeout)
+	}
+}
+
+// config checks the settings that have working defaults for development
+// but not for production.
+func (ch *Checker) config(context.Context) (string, string) {
+	if ch.ConfigErr != nil {
+		return StatusFailed, ch.ConfigErr.Error()
+	}
+	var warnings []string
+	if os.Getenv("JWT_SECRET") == "" {
+		warnings = append(warnings, "JWT_SECRET is not set; sessions are signed with the insecure default")
+	}
+	if base := os.Getenv("BASE_URL"); base == "" {
+		warnings = append(warnings, "BASE_URL is not set; links in emails point to http://localhost:8080")
+	} else if u, err := url.Parse(base); err != nil || u.Scheme == "" || u.Host == "" {
+		return StatusFailed, fmt.Sprintf("BASE_URL %q is not an absolute URL", base)
+	}
+	if len(warnings) > 0 {
+		return StatusWarning, strings.Join(warnings, "; ")
+	}
+	return StatusOK, ""
+}
+
+// database checks that the database answers and its write lock is free.
+func (ch *Checker) database(ctx context.Context) (string, string) {
+	if ch.DB == nil {
+		return StatusSkipped, "no database"
+	}
+	if err := ch.DB.CheckWritable(ctx, time.Second); err != nil {
+		return StatusFailed, err.Error()
+	}
+	return StatusOK, ""
+}
+
+// migrations checks that the schema is as new as this build.
+func (ch *Checker) migrations(ctx context.Context) (string, string) {
+	if ch.DB == nil {
+		return StatusSkipped, "no database"
+	}
+	pending, err := ch.DB.PendingMigrations(ctx)
+	switch {
+	case err != nil:
+		return StatusFailed, err.Error()
+	case len(pending) > 0:
+		return StatusFailed, fmt.Sprintf("%d pending, starting with %s; run `policyflow migrate`", len(pending), pending[0])
+	}
+	return StatusOK, ""
+}
+
+// smtp checks that the mail server accepts a connection and the
+// credentials, without sending anything.
+func (ch *Checker) smtp(context.Context) (string, string) {
+	if ch.Mailer == nil {
+		return StatusSkipped, "no mailer"
+	}
+	switch err := ch.Mailer.Check(); {
+	case errors.Is(err, email.ErrNotConfigured):
+		return StatusWarning, err.Error()
+	case err != nil:
+		return StatusFailed, err.Error()
+	}
+	return StatusOK, ""
+}
+
+// storage writes, reads back, and deletes a small object.
+func (ch *Checker) storage(ctx context.Context) (string, string) {
+	if ch.Store == nil {
+		return StatusSkipped, "no blob storage"
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	key := "selfcheck/" + hex.EncodeToString(b)
+	probe := []byte("policyflow self-check " + key)
+	if err := ch.Store.Put(ctx, key, bytes.NewReader(probe), int64(len(probe)), "text/plain"); err != nil {
+		return StatusFailed, fmt.Sprintf("%s: write: %v", ch.Store.Name(), err)
+	}
+	rc, err := ch.Store.Get(ctx, key)
+	if err == nil {
+		var got []byte
+		got, err = io.ReadAll(rc)
+		rc.Close()
+		if err == nil && !bytes.Equal(got, probe) {
+			err = errors.New("object read back differs from the one written")
+		}
+	}
+	if err != nil {
+		ch.Store.Delete(ctx, key)
+		return StatusFailed, fmt.Sprintf("%s: read: %v", ch.Store.Name(), err)
+	}
+	if err := ch.Store.Delete(ctx, key); err != nil {
+		return StatusFailed, fmt.Sprintf("%s: delete: %v", ch.Store.Name(), err)
+	}
+	return StatusOK, ch.Store.Name()
+}
@@ -0,0 +1,50 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"policyflow/internal/email"
+	"policyflow/internal/storage"
+	"policyflow/internal/testutil"
+)
+
+// TestRun_ReportsEachCheck runs the checks against a healthy database and
+// storage with SMTP unconfigured, then against storage that cannot be
+// written and a configuration that did not load.
+func TestRun_ReportsEachCheck(t *testing.T) {
+	t.Setenv("JWT_SECRET", "s3cret")
+	t.Setenv("BASE_URL", "https://policies.example.com")
+	t.Setenv("SMTP_HOST", "")
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := &Checker{DB: testutil.NewDB(t), Store: store, Mailer: email.New()}
+
+	r := ch.Run(context.Background())
+	want := map[string]string{"config": StatusOK, "database": StatusOK, "migrations": StatusOK, "smtp": StatusWarning, "storage": StatusOK}
+	for _, c := range r.Checks {
+		if c.Status != want[c.Name] {
+			t.Errorf("%s = %s (%s); want %s", c.Name, c.Status, c.Detail, want[c.Name])
+		}
+	}
+	if len(r.Checks) != len(want) || r.Status != StatusWarning || r.Failed() {
+		t.Errorf("report = %+v; want a warning overall", r)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(store.Dir, "selfcheck")); len(entries) != 0 {
+		t.Errorf("storage probe left %d objects behind", len(entries))
+	}
+
+	blocked := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(blocked, nil, 0o600)
+	ch.Store = &storage.Local{Dir: blocked}
+	ch.ConfigErr = errors.New("invalid REQUEST_TIMEOUT")
+	r = ch.Run(context.Background())
+	if !r.Failed() || r.Checks[0].Status != StatusFailed || r.Checks[4].Status != StatusFailed {
+		t.Errorf("report = %+v; want config and storage to fail", r)
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	"policyflow/internal/handlers"
+	"policyflow/internal/hris"
+	authmw "policyflow/internal/middleware"
+	"policyflow/internal/scan"
+	"policyflow/internal/storage"
+	"policyflow/internal/tokens"
+)
+
+// Config is everything a Server is built from. ConfigFromEnv fills it from
+// the environment variables in the deployment guide; programs embedding
+// PolicyFlow, and tests, can set the fields directly. Optional fields are
+// noted, and their zero values turn the feature off.
+type Config struct {
+	// DB, Keys, and Store are required. ConfigFromEnv leaves DB and Keys to
+	// the caller, who opens and migrates the database first.
+	DB     *database.DB
+	Keys   *tokens.Keyring
+	Store  storage.Store
+	Mailer *email.Mailer // nil = email.New()
+
+	Scanner scan.Scanner     // nil = uploads are not scanned
+	HRIS    hris.Provider    // nil = no HRIS sync
+	Health  *handlers.Health // nil = handlers.NewHealth(DB)
+
+	JWTSecret      string        // JWT_SECRET; also signs share links
+	BaseURL        string        // BASE_URL, for links in emails
+	RotationWindow time.Duration // JWT_ROTATION_WINDOW; 0 = tokens.DefaultRotationWindow
+	UploadDir      string        // UPLOAD_DIR; "" = a directory in os.TempDir()
+	MaxBodySize    int64         // MAX_BODY_SIZE; 0 = 4 MiB
+	RequestTimeout time.Duration // REQUEST_TIMEOUT
+	Maintenance    bool          // MAINTENANCE_MODE
+	DemoMode       bool          // DEMO_MODE; the caller resets the data before starting
+
+	// Background jobs, started by Run.
+	BackupInterval   time.Duration // BACKUP_INTERVAL
+	HRISSyncInterval time.Duration // HRIS_SYNC_INTERVAL; 0 with HRIS set = daily
+	ReminderLead     time.Duration // REMINDER_LEAD_DAYS when DEADLINE_REMINDERS is on
+
+	// Frontend serves every path the API does not; nil = 404.
+	Frontend http.Handler
+}
+
+// ConfigFromEnv reads the services and settings from the environment:
+// blob storage, the upload scanner, the HRIS provider, and the mailer
+// with its DKIM key.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		JWTSecret:   getEnv("JWT_SECRET", "dev-secret-change-me-in-production"),
+		BaseURL:     getEnv("BASE_URL", "http://localhost:8080"),
+		UploadDir:   os.Getenv("UPLOAD_DIR"),
+		Maintenance: os.Getenv("MAINTENANCE_MODE") == "true",
+		DemoMode:    os.Getenv("DEMO_MODE") == "true",
+	}
+	var err error
+	if cfg.Store, err = storage.FromEnv(); err != nil {
+		return cfg, fmt.Errorf("storage: %w", err)
+	}
+	if cfg.Scanner, err = scan.FromEnv(); err != nil {
+		return cfg, fmt.Errorf("scanner: %w", err)
+	}
+	if cfg.HRIS, err = hris.FromEnv(); err != nil {
+		return cfg, fmt.Errorf("hris: %w", err)
+	}
+	cfg.Mailer = email.New()
+	if path := os.Getenv("DKIM_PRIVATE_KEY_FILE"); path != "" {
+		pemData, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("read DKIM_PRIVATE_KEY_FILE: %w", err)
+		}
+		if err := cfg.Mailer.SetDKIM(os.Getenv("DKIM_DOMAIN"), getEnv("DKIM_SELECTOR", "policyflow"), pemData); err != nil {
+			return cfg, fmt.Errorf("DKIM_PRIVATE_KEY_FILE: %w", err)
+		}
+	}
+
+	for env, d := range map[string]*time.Duration{
+		"JWT_ROTATION_WINDOW": &cfg.RotationWindow,
+		"REQUEST_TIMEOUT":     &cfg.RequestTimeout,
+		"BACKUP_INTERVAL":     &cfg.BackupInterval,
+		"HRIS_SYNC_INTERVAL":  &cfg.HRISSyncInterval,
+	} {
+		if v := os.Getenv(env); v != "" {
+			if *d, err = time.ParseDuration(v); err != nil {
+				return cfg, fmt.Errorf("invalid %s: %w", env, err)
+			}
+		}
+	}
+	if v := os.Getenv("MAX_BODY_SIZE"); v != "" {
+		if cfg.MaxBodySize, err = authmw.ParseSize(v); err != nil {
+			return cfg, fmt.Errorf("MAX_BODY_SIZE: %w", err)
+		}
+	}
+	if os.Getenv("DEADLINE_REMINDERS") == "true" {
+		leadDays := 3
+		if v := os.Getenv("REMINDER_LEAD_DAYS"); v != "" {
+			if leadDays, err = strconv.Atoi(v); err != nil {
+				return cfg, fmt.Errorf("invalid REMINDER_LEAD_DAYS: %w", err)
+			}
+		}
+		cfg.ReminderLead = time.Duration(leadDays) * 24 * time.Hour
+	}
+	return cfg, nil
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,262 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"policyflow/internal/server"
+	"policyflow/internal/testutil/testserver"
+)
+
+// access is the least privileged caller a route admits.
+type access int
+
+const (
+	public access = iota
+	authenticated
+	deptAdmin
+	superAdmin
+)
+
+// routeAccess lists every API route. A route added to the server must be
+// added here too, which makes its access level a deliberate choice.
+var routeAccess = map[string]access{
+	// No session needed.
+	"POST /api/magic-link":                    public,
+	"GET /api/magic-login":                    public,
+	"POST /api/login-code":                    public,
+	"POST /api/login-code/verify":             public,
+	"GET /api/branding/logo":                  public,
+	"GET /api/status":                         public,
+	"GET /api/config":                         public,
+	"POST /api/demo/login":                    public,
+	"POST /api/integrations/users/webhook":    public,
+	"POST /api/integrations/training/webhook": public,
+	"GET /api/integrations/siem/events":       public,
+
+	// Any signed-in user.
+	"GET /api/me/deadlines.ics":                        authenticated,
+	"GET /api/events":                                  authenticated,
+	"GET /api/ws":                                      deptAdmin,
+	"GET /api/me":                                      authenticated,
+	"GET /api/me/logins":                               authenticated,
+	"GET /api/me/departments":                          authenticated,
+	"GET /api/me/required":                             authenticated,
+	"GET /api/me/pending":                              authenticated,
+	"GET /api/me/exceptions":                           authenticated,
+	"GET /api/me/reports/compliance":                   authenticated,
+	"GET /api/me/deadlines/subscription":               authenticated,
+	"GET /api/departments":                             authenticated,
+	"GET /api/custom-fields":                           authenticated,
+	"GET /api/collections":                             authenticated,
+	"GET /api/collections/:id":                         authenticated,
+	"GET /api/policies":                                authenticated,
+	"POST /api/policies/batch":                         authenticated,
+	"GET /api/policies/:id":                            authenticated,
+	"GET /api/policies/:id/versions":                   authenticated,
+	"GET /api/policies/:id/versions/:versionId/file":   authenticated,
+	"GET /api/policies/:id/print":                      authenticated,
+	"GET /api/policies/:id/training":                   authenticated,
+	"GET /api/policies/:id/faqs":                       authenticated,
+	"GET /api/policies/:id/attachments":                authenticated,
+	"GET /api/policies/:id/attachments/:attachmentId":  authenticated,
+	"POST /api/policies/:id/read-events":               authenticated,
+	"POST /api/policies/:id/acknowledge":               authenticated,
+	"POST /api/policies/:id/snooze":                    authenticated,
+	"DELETE /api/policies/:id/snooze":                  authenticated,
+	"POST /api/policies/:id/exceptions":                authenticated,
+	"GET /api/policies/:id/change-requests":            authenticated,
+	"POST /api/policies/:id/change-requests":           authenticated,
+	"PUT /api/policies/:id/change-requests/:requestId": authenticated,
+
+	// DeptAdmin and SuperAdmin.
+	"POST /api/policies":                                 deptAdmin,
+	"POST /api/policies/import/docx":                     deptAdmin,
+	"POST /api/uploads":                                  deptAdmin,
+	"GET /api/uploads/:id":                               deptAdmin,
+	"PATCH /api/uploads/:id":                             deptAdmin,
+	"DELETE /api/uploads/:id":                            deptAdmin,
+	"PUT /api/policies/:id":                              deptAdmin,
+	"PUT /api/policies/:id/custom-fields":                deptAdmin,
+	"PUT /api/policies/:id/training":                     deptAdmin,
+	"DELETE /api/policies/:id/training":                  deptAdmin,
+	"POST /api/policies/:id/faqs":                        deptAdmin,
+	"PUT /api/policies/:id/faqs/:faqId":                  deptAdmin,
+	"DELETE /api/policies/:id/faqs/:faqId":               deptAdmin,
+	"POST /api/admin/policies/bulk-status":               deptAdmin,
+	"POST /api/policies/:id/versions":                    deptAdmin,
+	"POST /api/policies/:id/versions/pdf":                deptAdmin,
+	"PUT /api/policies/:id/lock":                         deptAdmin,
+	"DELETE /api/policies/:id/lock":                      deptAdmin,
+	"DELETE /api/policies/:id/versions/:versionId":       deptAdmin,
+	"POST /api/policies/:id/share":                       deptAdmin,
+	"GET /api/policies/:id/shares":                       deptAdmin,
+	"DELETE /api/policies/:id/shares/:shareId":           deptAdmin,
+	"POST /api/policies/:id/attachments":                 deptAdmin,
+	"DELETE /api/policies/:id/attachments/:attachmentId": deptAdmin,
+	"GET /api/policies/:id/acknowledgements":             deptAdmin,
+	"GET /api/policies/:id/versions/stats":               deptAdmin,
+	"GET /api/policies/:id/assignments":                  deptAdmin,
+	"POST /api/policies/:id/assignments":                 deptAdmin,
+	"DELETE /api/policies/:id/assignments/:assignmentId": deptAdmin,
+	"POST /api/policies/:id/relations":                   deptAdmin,
+	"DELETE /api/policies/:id/relations/:relationId":     deptAdmin,
+	"GET /api/users":                                     deptAdmin,
+	"POST /api/users/batch":                              deptAdmin,
+	"POST /api/users":                                    deptAdmin,
+	"POST /api/users/:id/resend-invite":                  deptAdmin,
+	"GET /api/admin/users/export.csv":                    deptAdmin,
+	"GET /api/admin/users/inactive":                      deptAdmin,
+	"GET /api/admin/users/:id/acknowledgements":          deptAdmin,
+	"GET /api/admin/policies/export":                     deptAdmin,
+	"GET /api/admin/stats":                               deptAdmin,
+	"GET /api/admin/stats/timeseries":                    deptAdmin,
+	"GET /api/admin/reports/fields":                      deptAdmin,
+	"POST /api/admin/reports":                            deptAdmin,
+	"GET /api/admin/exceptions":                          deptAdmin,
+	"PUT /api/admin/exceptions/:id":                      deptAdmin,
+	"GET /api/admin/reports/ack-matrix":                  deptAdmin,
+	"GET /api/admin/reports/department-compliance":       deptAdmin,
+	"GET /api/admin/reports/training-gaps":               deptAdmin,
+	"GET /api/admin/reports/reack-gaps":                  deptAdmin,
+	"GET /api/admin/reports/authors":                     deptAdmin,
+	"GET /api/admin/campaigns":                           deptAdmin,
+	"GET /api/admin/campaigns/:id":                       deptAdmin,
+	"GET /api/admin/campaigns/:id/progress":              deptAdmin,
+	"GET /api/admin/reports/schedules":                   deptAdmin,
+	"POST /api/admin/reports/schedules":                  deptAdmin,
+	"DELETE /api/admin/reports/schedules/:id":            deptAdmin,
+	"POST /api/admin/reports/schedules/:id/send":         deptAdmin,
+
+	// SuperAdmin only.
+	"POST /api/departments":                            superAdmin,
+	"PUT /api/departments/:id":                         superAdmin,
+	"DELETE /api/departments/:id":                      superAdmin,
+	"GET /api/admin/departments/legacy":                superAdmin,
+	"POST /api/admin/departments/legacy/consolidate":   superAdmin,
+	"PUT /api/users/:id":                               superAdmin,
+	"DELETE /api/users/:id":                            superAdmin,
+	"GET /api/admin/users/:id/gdpr-export":             superAdmin,
+	"GET /api/admin/users/:id/logins":                  superAdmin,
+	"GET /api/admin/users/:id/departments":             superAdmin,
+	"PUT /api/admin/users/:id/departments/:dept_id":    superAdmin,
+	"DELETE /api/admin/users/:id/departments/:dept_id": superAdmin,
+	"GET /api/admin/hris/runs":                         superAdmin,
+	"POST /api/admin/hris/sync":                        superAdmin,
+	"GET /api/admin/selfcheck":                         superAdmin,
+	"GET /api/admin/settings":                          superAdmin,
+	"PUT /api/admin/settings":                          superAdmin,
+	"PUT /api/admin/branding/logo":                     superAdmin,
+	"POST /api/admin/campaigns":                        superAdmin,
+	"PUT /api/admin/campaigns/:id":                     superAdmin,
+	"DELETE /api/admin/campaigns/:id":                  superAdmin,
+	"GET /api/admin/bundles":                           superAdmin,
+	"POST /api/admin/bundles":                          superAdmin,
+	"PUT /api/admin/bundles/:id":                       superAdmin,
+	"DELETE /api/admin/bundles/:id":                    superAdmin,
+	"GET /api/admin/bundles/:id/assignments":           superAdmin,
+	"POST /api/admin/bundles/:id/assignments":          superAdmin,
+	"GET /api/admin/collections":                       superAdmin,
+	"POST /api/admin/collections":                      superAdmin,
+	"PUT /api/admin/collections/:id":                   superAdmin,
+	"DELETE /api/admin/collections/:id":                superAdmin,
+	"GET /api/admin/claim-rules":                       superAdmin,
+	"POST /api/admin/claim-rules":                      superAdmin,
+	"POST /api/admin/claim-rules/evaluate":             superAdmin,
+	"PUT /api/admin/claim-rules/:id":                   superAdmin,
+	"DELETE /api/admin/claim-rules/:id":                superAdmin,
+	"POST /api/admin/custom-fields":                    superAdmin,
+	"PUT /api/admin/custom-fields/:id":                 superAdmin,
+	"DELETE /api/admin/custom-fields/:id":              superAdmin,
+	"GET /api/admin/email-templates":                   superAdmin,
+	"PUT /api/admin/email-templates/:name":             superAdmin,
+	"DELETE /api/admin/email-templates/:name":          superAdmin,
+	"POST /api/admin/email-templates/:name/preview":    superAdmin,
+	"POST /api/admin/backups":                          superAdmin,
+	"POST /api/admin/acknowledgements/import":          superAdmin,
+	"POST /api/admin/impersonate/:id":                  superAdmin,
+	"GET /api/admin/audit":                             superAdmin,
+	"GET /api/admin/audit/denied":                      superAdmin,
+	"GET /api/admin/upload-scans":                      superAdmin,
+	"DELETE /api/admin/upload-scans/:id/file":          superAdmin,
+	"GET /api/admin/activity":                          superAdmin,
+	"GET /api/admin/jwt/keys":                          superAdmin,
+	"POST /api/admin/jwt/rotate":                       superAdmin,
+	"PUT /api/admin/maintenance":                       superAdmin,
+}
+
+var routeParamRe = regexp.MustCompile(`:[A-Za-z_]+`)
+
+// TestRoutes_RoleMatrix calls every API route as each role and checks the
+// middleware turns away exactly the callers below the route's access level.
+func TestRoutes_RoleMatrix(t *testing.T) {
+	ctx := context.Background()
+	s := testserver.New(t, func(cfg *server.Config) { cfg.DemoMode = true })
+	dept, err := s.DB.CreateDepartment(ctx, "Operations", "")
+	if err != nil {
+		t.Fatalf("CreateDepartment: %v", err)
+	}
+	type caller struct {
+		name  string
+		level access
+		token string
+	}
+	callers := []caller{{name: "anonymous", level: public}}
+	for _, u := range []struct {
+		email, role string
+		level       access
+		dept        *string
+	}{
+		{"staff@example.com", "Staff", authenticated, &dept.ID},
+		{"lead@example.com", "DeptAdmin", deptAdmin, &dept.ID},
+		{"hr@example.com", "SuperAdmin", superAdmin, nil},
+	} {
+		user, err := s.DB.CreateUser(ctx, u.email, u.role, u.role, nil, u.dept)
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		callers = append(callers, caller{u.role, u.level, s.Token(t, user)})
+	}
+
+	registered := map[string]bool{}
+	for _, r := range s.Echo.Routes() {
+		if strings.HasPrefix(r.Path, "/api/") && !strings.HasSuffix(r.Path, "*") {
+			registered[r.Method+" "+r.Path] = true
+		}
+	}
+	for route := range registered {
+		if _, ok := routeAccess[route]; !ok {
+			t.Errorf("%s is not in routeAccess", route)
+		}
+	}
+
+	for route, need := range routeAccess {
+		if !registered[route] {
+			t.Errorf("%s is in routeAccess but not registered", route)
+			continue
+		}
+		method, path, _ := strings.Cut(route, " ")
+		path = routeParamRe.ReplaceAllString(path, "00000000-0000-0000-0000-000000000000")
+		for _, caller := range callers {
+			if caller.level >= need && path == "/api/events" {
+				continue // a stream that stays open
+			}
+			body := ""
+			if method != http.MethodGet && method != http.MethodDelete {
+				body = "{}"
+			}
+			got := s.Do(t, method, path, caller.token, body).StatusCode
+			switch {
+			case caller.level >= need && (got == http.StatusUnauthorized || got == http.StatusForbidden):
+				t.Errorf("%s as %s = %d; want it admitted", route, caller.name, got)
+			case caller.level < need && caller.level == public && got != http.StatusUnauthorized:
+				t.Errorf("%s as %s = %d; want 401", route, caller.name, got)
+			case caller.level < need && caller.level > public && got != http.StatusForbidden:
+				t.Errorf("%s as %s = %d; want 403", route, caller.name, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,429 @@
+// Package server assembles a PolicyFlow instance from its services: the
+// handlers, middleware, and routes of the HTTP API, and the background
+// jobs. main opens the database and runs it; tests serve it with httptest
+// through the testutil/testserver package.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+
+	"policyflow/internal/apierr"
+	"policyflow/internal/backup"
+	"policyflow/internal/demo"
+	"policyflow/internal/email"
+	"policyflow/internal/events"
+	"policyflow/internal/handlers"
+	"policyflow/internal/hris"
+	authmw "policyflow/internal/middleware"
+	"policyflow/internal/notify"
+	"policyflow/internal/reminders"
+	"policyflow/internal/reports"
+	"policyflow/internal/selfcheck"
+	"policyflow/internal/tokens"
+)
+
+// shutdownGrace is how long Run waits for requests in flight to finish.
+const shutdownGrace = 10 * time.Second
+
+// Server is a PolicyFlow instance: the HTTP API and the background jobs
+// that send email, sync users, and take backups.
+type Server struct {
+	cfg  Config
+	echo *echo.Echo
+	jobs []job
+}
+
+// job is a background loop Run starts; it returns when ctx is done.
+type job struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// NewServer builds the handlers, middleware, and routes. Nothing runs
+// until Run; tests can serve Handler directly.
+func NewServer(cfg Config) (*Server, error) {
+	if cfg.DB == nil || cfg.Keys == nil || cfg.Store == nil {
+		return nil, errors.New("server: DB, Keys, and Store are required")
+	}
+	db, keys := cfg.DB, cfg.Keys
+	if cfg.Mailer == nil {
+		cfg.Mailer = email.New()
+	}
+	mailer := cfg.Mailer
+	mailer.SetOverrides(db)
+	rotationWindow := cfg.RotationWindow
+	if rotationWindow == 0 {
+		rotationWindow = tokens.DefaultRotationWindow
+	}
+	healthH := cfg.Health
+	if healthH == nil {
+		healthH = handlers.NewHealth(db)
+	}
+	uploadDir := cfg.UploadDir
+	if uploadDir == "" {
+		uploadDir = filepath.Join(os.TempDir(), "policyflow-uploads")
+	}
+	srv := &Server{cfg: cfg}
+	srv.addJob("signing key refresh", func(ctx context.Context) { keys.Refresh(ctx, time.Minute) })
+
+	var hrisSyncer *hris.Syncer
+	if cfg.HRIS != nil {
+		hrisSyncer = hris.NewSyncer(db, cfg.HRIS)
+		interval := cfg.HRISSyncInterval
+		if interval == 0 {
+			interval = 24 * time.Hour
+		}
+		srv.addJob(fmt.Sprintf("HRIS sync (%s, every %s)", cfg.HRIS.Name(), interval),
+			func(ctx context.Context) { hrisSyncer.Schedule(ctx, interval) })
+	}
+	reportRunner := reports.NewRunner(db, mailer)
+	srv.addJob("scheduled reports", func(ctx context.Context) { reportRunner.Schedule(ctx, 15*time.Minute) })
+	campaignRunner := reminders.NewCampaignRunner(db, mailer, cfg.BaseURL)
+	srv.addJob("campaign emails", func(ctx context.Context) { campaignRunner.Schedule(ctx, 15*time.Minute) })
+	// Emails queued when policy versions are published.
+	notifier := notify.NewRunner(db, mailer, cfg.BaseURL)
+	srv.addJob("publish notifications", func(ctx context.Context) { notifier.Schedule(ctx, time.Minute) })
+	if cfg.ReminderLead > 0 {
+		runner := reminders.NewRunner(db, mailer, cfg.BaseURL, cfg.ReminderLead)
+		srv.addJob(fmt.Sprintf("deadline reminders (%s ahead)", cfg.ReminderLead),
+			func(ctx context.Context) { runner.Schedule(ctx, time.Hour) })
+	}
+	if cfg.BackupInterval > 0 {
+		srv.addJob(fmt.Sprintf("database backups (every %s)", cfg.BackupInterval),
+			func(ctx context.Context) { backup.Schedule(ctx, db, cfg.Store, cfg.BackupInterval) })
+	}
+	if cfg.DemoMode {
+		srv.addJob("nightly demo reset", func(ctx context.Context) { demo.Schedule(ctx, db) })
+	}
+
+	authMW := authmw.NewAuth(keys, db)
+	authH := handlers.NewAuth(db, mailer, keys)
+	userH := handlers.NewUser(db, mailer, keys)
+	keysH := handlers.NewKeys(keys, rotationWindow)
+	policyH := handlers.NewPolicy(db)
+	deptH := handlers.NewDepartments(db)
+	settingsH := handlers.NewSettings(db)
+	emailTemplatesH := handlers.NewEmailTemplates(db)
+	customFieldsH := handlers.NewCustomFields(db)
+	claimRulesH := handlers.NewClaimRules(db)
+	bundlesH := handlers.NewBundles(db)
+	campaignsH := handlers.NewCampaigns(db)
+	collectionsH := handlers.NewCollections(db)
+	publicH := handlers.NewPublic(db)
+	hrisH := handlers.NewHRIS(db, hrisSyncer)
+	trainingH := handlers.NewTraining(db)
+	siemH := handlers.NewSIEM(db)
+	selfCheckH := handlers.NewSelfCheck(&selfcheck.Checker{DB: db, Store: cfg.Store, Mailer: mailer})
+	deadlinesH := handlers.NewDeadlines(policyH, authMW)
+	sharesH := handlers.NewShares(policyH, cfg.JWTSecret)
+	attachmentsH := handlers.NewAttachments(policyH, cfg.Store)
+	exceptionsH := handlers.NewExceptions(policyH, mailer)
+	receiptsH := handlers.NewReceipts(db, mailer, cfg.JWTSecret)
+	brandingH := handlers.NewBranding(db, cfg.Store)
+	backupsH := handlers.NewBackups(db, cfg.Store)
+	auditH := handlers.NewAudit(db)
+	reportsH := handlers.NewReports(db, reportRunner)
+	hub := events.NewHub()
+	policyH.SetEvents(hub)
+	policyH.SetStore(cfg.Store)
+	policyH.SetScanner(cfg.Scanner)
+	policyH.SetReceipts(receiptsH)
+	uploadsH, err := handlers.NewUploads(db, uploadDir, policyH.MaxUploadBytes())
+	if err != nil {
+		return nil, fmt.Errorf("upload dir: %w", err)
+	}
+	policyH.SetUploads(uploadsH)
+	uploadScansH := handlers.NewUploadScans(db, cfg.Store)
+	eventsH := handlers.NewEvents(db, hub)
+	maintenance := authmw.NewMaintenance(db, cfg.Maintenance)
+	maintenanceH := handlers.NewMaintenance(db, maintenance)
+	configH := handlers.NewConfig(db, policyH)
+
+	e := echo.New()
+	e.HideBanner, e.HidePort = true, true
+	srv.echo = e
+	e.HTTPErrorHandler = apierr.Handler
+	e.Use(echomw.RequestID())
+	e.Use(echomw.Logger())
+	e.Use(echomw.Recover())
+	e.Use(echomw.CORSWithConfig(echomw.CORSConfig{
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAuthorization, "If-Match", authmw.HeaderDepartment, "Upload-Offset"},
+		ExposeHeaders: []string{"ETag", authmw.HeaderSessionToken, echo.HeaderXRequestID},
+	}))
+
+	// ── API routes ─────────────────────────────────────────────────────────
+	api := e.Group("/api")
+	if cfg.RequestTimeout > 0 {
+		// Cancels the request context, aborting in-flight queries.
+		api.Use(echomw.ContextTimeout(cfg.RequestTimeout))
+	}
+	api.Use(bodyLimits(cfg.MaxBodySize, policyH.MaxUploadBytes()).Limit)
+	api.Use(maintenance.Guard)
+
+	// Public
+	api.POST("/magic-link", authH.RequestMagicLink)
+	api.GET("/magic-login", authH.MagicLogin)
+	// Codes are short enough to guess, so each address gets few tries.
+	loginCodeLimit := authmw.PerIP(20, 10*time.Minute)
+	api.POST("/login-code", authH.RequestLoginCode, loginCodeLimit)
+	api.POST("/login-code/verify", authH.VerifyLoginCode, loginCodeLimit)
+
+	// Calendar feed (session or calendar feed token)
+	api.GET("/me/deadlines.ics", deadlinesH.ICS, authMW.RequireFeed)
+
+	api.GET("/branding/logo", brandingH.Logo)
+	api.GET("/status", maintenanceH.Status)
+	api.GET("/config", configH.Get)
+	if cfg.DemoMode {
+		api.POST("/demo/login", authH.DemoLogin)
+	}
+
+	// HR provisioning webhook (HR_WEBHOOK_TOKEN bearer token)
+	api.POST("/integrations/users/webhook", hrisH.Webhook)
+	// LMS course completion webhook (LMS_WEBHOOK_TOKEN bearer token)
+	api.POST("/integrations/training/webhook", trainingH.Webhook)
+	// Security event stream for a SIEM (SIEM_TOKEN bearer token)
+	api.GET("/integrations/siem/events", siemH.Events)
+
+	// Authenticated (any role)
+	authAPI := api.Group("", authMW.Audit, authMW.Require)
+	authAPI.GET("/me", authH.Me)
+	authAPI.GET("/me/logins", authH.MyLogins)
+	authAPI.GET("/me/departments", userH.MyDepartments)
+	authAPI.GET("/me/required", policyH.Required)
+	authAPI.GET("/me/pending", policyH.Pending)
+	authAPI.GET("/me/exceptions", exceptionsH.Mine)
+	authAPI.GET("/me/reports/compliance", userH.ReportsCompliance)
+	authAPI.GET("/me/deadlines/subscription", deadlinesH.Subscription)
+	authAPI.GET("/departments", deptH.List)
+	authAPI.GET("/custom-fields", customFieldsH.List)
+	authAPI.GET("/collections", collectionsH.List)
+	authAPI.GET("/collections/:id", collectionsH.Get)
+	authAPI.GET("/policies", policyH.List)
+	authAPI.POST("/policies/batch", policyH.Batch)
+	authAPI.GET("/policies/:id", policyH.Get)
+	authAPI.GET("/policies/:id/versions", policyH.Versions)
+	authAPI.GET("/policies/:id/versions/:versionId/file", policyH.VersionFile)
+	authAPI.GET("/policies/:id/print", policyH.Print)
+	authAPI.GET("/policies/:id/training", policyH.GetTraining)
+	authAPI.GET("/policies/:id/faqs", policyH.FAQs)
+	authAPI.GET("/policies/:id/attachments", attachmentsH.List)
+	authAPI.GET("/policies/:id/attachments/:attachmentId", attachmentsH.Download)
+	authAPI.POST("/policies/:id/read-events", policyH.RecordRead)
+	authAPI.POST("/policies/:id/acknowledge", policyH.Acknowledge)
+	authAPI.POST("/policies/:id/snooze", policyH.Snooze)
+	authAPI.DELETE("/policies/:id/snooze", policyH.Unsnooze)
+	authAPI.POST("/policies/:id/exceptions", exceptionsH.Request)
+	authAPI.GET("/policies/:id/change-requests", policyH.ChangeRequests)
+	authAPI.POST("/policies/:id/change-requests", policyH.CreateChangeRequest)
+	authAPI.PUT("/policies/:id/change-requests/:requestId", policyH.CloseChangeRequest)
+
+	// DeptAdmin + SuperAdmin
+	deptAdminAPI := api.Group("", authMW.Audit, authMW.Require, authMW.RequireDeptAdmin)
+	deptAdminAPI.POST("/policies", policyH.Create)
+	deptAdminAPI.POST("/policies/import/docx", policyH.ImportDOCX)
+	deptAdminAPI.POST("/uploads", uploadsH.Create)
+	deptAdminAPI.GET("/uploads/:id", uploadsH.Status)
+	deptAdminAPI.PATCH("/uploads/:id", uploadsH.Append)
+	deptAdminAPI.DELETE("/uploads/:id", uploadsH.Cancel)
+	deptAdminAPI.PUT("/policies/:id", policyH.Update)
+	deptAdminAPI.PUT("/policies/:id/custom-fields", policyH.SetCustomFields)
+	deptAdminAPI.PUT("/policies/:id/training", policyH.SetTraining)
+	deptAdminAPI.DELETE("/policies/:id/training", policyH.DeleteTraining)
+	deptAdminAPI.POST("/policies/:id/faqs", policyH.CreateFAQ)
+	deptAdminAPI.PUT("/policies/:id/faqs/:faqId", policyH.UpdateFAQ)
+	deptAdminAPI.DELETE("/policies/:id/faqs/:faqId", policyH.DeleteFAQ)
+	deptAdminAPI.POST("/admin/policies/bulk-status", policyH.BulkStatus)
+	deptAdminAPI.POST("/policies/:id/versions", policyH.CreateVersion)
+	deptAdminAPI.POST("/policies/:id/versions/pdf", policyH.UploadPDFVersion)
+	deptAdminAPI.PUT("/policies/:id/lock", policyH.Lock)
+	deptAdminAPI.DELETE("/policies/:id/lock", policyH.Unlock)
+	deptAdminAPI.DELETE("/policies/:id/versions/:versionId", policyH.DeleteVersion)
+	deptAdminAPI.POST("/policies/:id/share", sharesH.Create)
+	deptAdminAPI.GET("/policies/:id/shares", sharesH.List)
+	deptAdminAPI.DELETE("/policies/:id/shares/:shareId", sharesH.Revoke)
+	deptAdminAPI.POST("/policies/:id/attachments", attachmentsH.Upload)
+	deptAdminAPI.DELETE("/policies/:id/attachments/:attachmentId", attachmentsH.Delete)
+	deptAdminAPI.GET("/policies/:id/acknowledgements", policyH.Acknowledgements)
+	deptAdminAPI.GET("/policies/:id/versions/stats", policyH.VersionStats)
+	deptAdminAPI.GET("/policies/:id/assignments", policyH.Assignments)
+	deptAdminAPI.POST("/policies/:id/assignments", policyH.CreateAssignments)
+	deptAdminAPI.DELETE("/policies/:id/assignments/:assignmentId", policyH.DeleteAssignment)
+	deptAdminAPI.POST("/policies/:id/relations", policyH.CreateRelation)
+	deptAdminAPI.DELETE("/policies/:id/relations/:relationId", policyH.DeleteRelation)
+	deptAdminAPI.GET("/users", userH.List)
+	deptAdminAPI.POST("/users/batch", userH.Batch)
+	deptAdminAPI.POST("/users", userH.Create)
+	deptAdminAPI.POST("/users/:id/resend-invite", userH.ResendInvite)
+	deptAdminAPI.GET("/admin/users/export.csv", userH.ExportUsers)
+	deptAdminAPI.GET("/admin/users/inactive", userH.Inactive)
+	deptAdminAPI.GET("/admin/users/:id/acknowledgements", userH.Acknowledgements)
+	deptAdminAPI.GET("/admin/policies/export", policyH.ExportCatalog)
+	deptAdminAPI.GET("/admin/stats", policyH.AdminStats)
+	deptAdminAPI.GET("/admin/stats/timeseries", policyH.Timeseries)
+	deptAdminAPI.GET("/admin/reports/fields", reportsH.Fields)
+	deptAdminAPI.POST("/admin/reports", reportsH.Run)
+	deptAdminAPI.GET("/admin/exceptions", exceptionsH.List)
+	deptAdminAPI.PUT("/admin/exceptions/:id", exceptionsH.Decide)
+	deptAdminAPI.GET("/admin/reports/ack-matrix", reportsH.AckMatrix)
+	deptAdminAPI.GET("/admin/reports/department-compliance", reportsH.DepartmentCompliance)
+	deptAdminAPI.GET("/admin/reports/training-gaps", reportsH.TrainingGaps)
+	deptAdminAPI.GET("/admin/reports/reack-gaps", reportsH.ReackGaps)
+	deptAdminAPI.GET("/admin/reports/authors", reportsH.AuthorStats)
+	deptAdminAPI.GET("/admin/campaigns", campaignsH.List)
+	deptAdminAPI.GET("/admin/campaigns/:id", campaignsH.Get)
+	deptAdminAPI.GET("/admin/campaigns/:id/progress", campaignsH.Progress)
+	deptAdminAPI.GET("/admin/reports/schedules", reportsH.ListSchedules)
+	deptAdminAPI.POST("/admin/reports/schedules", reportsH.CreateSchedule)
+	deptAdminAPI.DELETE("/admin/reports/schedules/:id", reportsH.DeleteSchedule)
+	deptAdminAPI.POST("/admin/reports/schedules/:id/send", reportsH.SendSchedule)
+
+	// SuperAdmin only
+	superAdminAPI := api.Group("", authMW.Audit, authMW.Require, authMW.RequireSuperAdmin)
+	superAdminAPI.POST("/departments", deptH.Create)
+	superAdminAPI.PUT("/departments/:id", deptH.Update)
+	superAdminAPI.DELETE("/departments/:id", deptH.Delete)
+	superAdminAPI.GET("/admin/departments/legacy", deptH.LegacyDepartments)
+	superAdminAPI.POST("/admin/departments/legacy/consolidate", deptH.ConsolidateLegacy)
+	superAdminAPI.PUT("/users/:id", userH.Update)
+	superAdminAPI.DELETE("/users/:id", userH.Delete)
+	superAdminAPI.GET("/admin/users/:id/gdpr-export", userH.GDPRExport)
+	superAdminAPI.GET("/admin/users/:id/logins", authH.UserLogins)
+	superAdminAPI.GET("/admin/users/:id/departments", userH.Grants)
+	superAdminAPI.PUT("/admin/users/:id/departments/:dept_id", userH.Grant)
+	superAdminAPI.DELETE("/admin/users/:id/departments/:dept_id", userH.Revoke)
+	superAdminAPI.GET("/admin/hris/runs", hrisH.Runs)
+	superAdminAPI.POST("/admin/hris/sync", hrisH.Sync)
+	superAdminAPI.GET("/admin/claim-rules", claimRulesH.List)
+	superAdminAPI.POST("/admin/claim-rules", claimRulesH.Create)
+	superAdminAPI.POST("/admin/claim-rules/evaluate", claimRulesH.Evaluate)
+	superAdminAPI.PUT("/admin/claim-rules/:id", claimRulesH.Update)
+	superAdminAPI.DELETE("/admin/claim-rules/:id", claimRulesH.Delete)
+	superAdminAPI.GET("/admin/selfcheck", selfCheckH.Run)
+	superAdminAPI.GET("/admin/settings", settingsH.Get)
+	superAdminAPI.PUT("/admin/settings", settingsH.Update)
+	superAdminAPI.PUT("/admin/branding/logo", brandingH.UploadLogo)
+	superAdminAPI.POST("/admin/campaigns", campaignsH.Create)
+	superAdminAPI.PUT("/admin/campaigns/:id", campaignsH.Update)
+	superAdminAPI.DELETE("/admin/campaigns/:id", campaignsH.Delete)
+	superAdminAPI.GET("/admin/bundles", bundlesH.List)
+	superAdminAPI.POST("/admin/bundles", bundlesH.Create)
+	superAdminAPI.PUT("/admin/bundles/:id", bundlesH.Update)
+	superAdminAPI.DELETE("/admin/bundles/:id", bundlesH.Delete)
+	superAdminAPI.GET("/admin/bundles/:id/assignments", bundlesH.Assignments)
+	superAdminAPI.POST("/admin/bundles/:id/assignments", bundlesH.Assign)
+	superAdminAPI.GET("/admin/collections", collectionsH.Manage)
+	superAdminAPI.POST("/admin/collections", collectionsH.Create)
+	superAdminAPI.PUT("/admin/collections/:id", collectionsH.Update)
+	superAdminAPI.DELETE("/admin/collections/:id", collectionsH.Delete)
+	superAdminAPI.POST("/admin/custom-fields", customFieldsH.Create)
+	superAdminAPI.PUT("/admin/custom-fields/:id", customFieldsH.Update)
+	superAdminAPI.DELETE("/admin/custom-fields/:id", customFieldsH.Delete)
+	superAdminAPI.GET("/admin/email-templates", emailTemplatesH.List)
+	superAdminAPI.PUT("/admin/email-templates/:name", emailTemplatesH.Update)
+	superAdminAPI.DELETE("/admin/email-templates/:name", emailTemplatesH.Reset)
+	superAdminAPI.POST("/admin/email-templates/:name/preview", emailTemplatesH.Preview)
+	superAdminAPI.POST("/admin/backups", backupsH.Create)
+	superAdminAPI.POST("/admin/acknowledgements/import", policyH.ImportAcknowledgements)
+	superAdminAPI.POST("/admin/impersonate/:id", authH.Impersonate)
+	superAdminAPI.GET("/admin/audit", auditH.List)
+	superAdminAPI.GET("/admin/audit/denied", auditH.Denied)
+	superAdminAPI.GET("/admin/upload-scans", uploadScansH.List)
+	superAdminAPI.DELETE("/admin/upload-scans/:id/file", uploadScansH.DeleteFile)
+	superAdminAPI.GET("/admin/activity", auditH.Activity)
+	superAdminAPI.GET("/admin/jwt/keys", keysH.List)
+	superAdminAPI.POST("/admin/jwt/rotate", keysH.Rotate)
+	superAdminAPI.PUT("/admin/maintenance", maintenanceH.Set)
+
+	// Long-lived stream: registered outside /api so REQUEST_TIMEOUT does not
+	// cut it off.
+	e.GET("/api/events", eventsH.Stream, authMW.Require)
+	e.GET("/api/ws", eventsH.Dashboard, authMW.Require, authMW.RequireDeptAdmin)
+
+	// Orchestrator probes; outside /api so maintenance mode and
+	// REQUEST_TIMEOUT do not apply.
+	e.GET("/healthz", healthH.Live)
+	e.GET("/readyz", healthH.Ready)
+
+	// ── Public portal, share links, and certificates (server-rendered, no auth)
+	e.GET("/public/policies", publicH.Index)
+	e.GET("/public/policies/:id", publicH.Policy)
+	e.GET("/share/:token", sharesH.View)
+	e.GET("/certificates/:token", receiptsH.Certificate)
+	e.GET("/.well-known/jwks.json", keysH.JWKS)
+
+	if cfg.Frontend != nil {
+		e.Any("/*", echo.WrapHandler(cfg.Frontend))
+	}
+	return srv, nil
+}
+
+func (s *Server) addJob(name string, run func(ctx context.Context)) {
+	s.jobs = append(s.jobs, job{name, run})
+}
+
+// Handler serves the API, the probes, the public pages, and the frontend.
+func (s *Server) Handler() http.Handler { return s.echo }
+
+// Echo returns the router, for adding routes or listing them in tests.
+func (s *Server) Echo() *echo.Echo { return s.echo }
+
+// Run starts the background jobs and serves HTTP on addr until ctx is
+// cancelled, then stops accepting requests and waits up to shutdownGrace
+// for those in flight.
+func (s *Server) Run(ctx context.Context, addr string) error {
+	for _, j := range s.jobs {
+		log.Printf("Starting %s", j.name)
+		go j.run(ctx)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("PolicyFlow listening on %s", addr)
+		errc <- s.echo.Start(addr)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownGrace)
+	defer cancel()
+	if err := s.echo.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// bodyLimits caps request bodies at def (4 MiB if 0), with room for the
+// file endpoints' uploads.
+func bodyLimits(def, maxUpload int64) *authmw.BodyLimits {
+	if def == 0 {
+		def = 4 << 20
+	}
+	l := authmw.NewBodyLimits(def)
+	for _, path := range []string{
+		"/api/policies/:id/attachments",
+		"/api/policies/:id/versions/pdf",
+		"/api/policies/import/docx",
+		"/api/admin/acknowledgements/import",
+	} {
+		l.Set(http.MethodPost, path, maxUpload+1<<20)
+	}
+	l.Set(http.MethodPatch, "/api/uploads/:id", handlers.UploadChunkBytes)
+	return l
+}
@@ -0,0 +1,165 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"policyflow/internal/database"
+	"policyflow/internal/server"
+	"policyflow/internal/storage"
+	"policyflow/internal/testutil"
+	"policyflow/internal/testutil/testserver"
+)
+
+var magicLinkRe = regexp.MustCompile(`/api/magic-login\?token=\S+`)
+
+// signIn requests a magic link for email, follows it, and returns the
+// session token the callback redirect carries.
+func signIn(t *testing.T, s *testserver.Server, email string) string {
+	t.Helper()
+	if resp := s.Do(t, http.MethodPost, "/api/magic-link", "", `{"email":"`+email+`"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /api/magic-link = %d", resp.StatusCode)
+	}
+	msg, ok := s.Mail.Last(email)
+	if !ok {
+		t.Fatalf("no magic link sent to %s", email)
+	}
+	link := magicLinkRe.FindString(msg.Body)
+	if link == "" {
+		t.Fatalf("no magic link in %q", msg.Body)
+	}
+	client := *s.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Get(s.URL + link)
+	if err != nil {
+		t.Fatalf("GET magic link: %v", err)
+	}
+	resp.Body.Close()
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.StatusCode != http.StatusTemporaryRedirect || loc.Query().Get("token") == "" {
+		t.Fatalf("magic login = %d to %q; want a redirect with a session token", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	return loc.Query().Get("token")
+}
+
+// call sends a request and decodes the JSON response into out, if given,
+// failing the test unless the status is want.
+func call(t *testing.T, s *testserver.Server, method, path, token, body string, want int, out any) {
+	t.Helper()
+	resp := s.Do(t, method, path, token, body)
+	if resp.StatusCode != want {
+		var e map[string]any
+		json.NewDecoder(resp.Body).Decode(&e)
+		t.Fatalf("%s %s = %d %v; want %d", method, path, resp.StatusCode, e, want)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+	}
+}
+
+// TestE2E_MagicLinkToAcknowledgement signs in by magic link and walks a
+// policy from creation to a staff member's acknowledgement.
+func TestE2E_MagicLinkToAcknowledgement(t *testing.T) {
+	ctx := context.Background()
+	s := testserver.New(t)
+	if _, err := s.DB.CreateUser(ctx, "hr@example.com", "HR", "SuperAdmin", nil, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	admin := signIn(t, s, "hr@example.com")
+
+	var me database.User
+	call(t, s, http.MethodGet, "/api/me", admin, "", http.StatusOK, &me)
+	if me.Email != "hr@example.com" {
+		t.Fatalf("GET /api/me = %s; want hr@example.com", me.Email)
+	}
+
+	var dept database.Department
+	call(t, s, http.MethodPost, "/api/departments", admin, `{"name":"Operations"}`, http.StatusCreated, &dept)
+	var staff database.User
+	call(t, s, http.MethodPost, "/api/users", admin,
+		`{"email":"sam@example.com","name":"Sam","role":"Staff","department_id":"`+dept.ID+`"}`, http.StatusCreated, &staff)
+	if _, ok := s.Mail.Last("sam@example.com"); !ok {
+		t.Error("no welcome email sent to the new user")
+	}
+
+	var policy database.Policy
+	call(t, s, http.MethodPost, "/api/policies", admin,
+		`{"title":"Travel","department_id":"`+dept.ID+`","visibility_type":"department"}`, http.StatusCreated, &policy)
+	call(t, s, http.MethodPost, "/api/policies/"+policy.ID+"/versions", admin,
+		`{"content":"# Travel\n\nBook through the portal.","version_string":"v1.0","changelog":"First version"}`, http.StatusCreated, nil)
+	var got struct {
+		Policy database.Policy `json:"policy"`
+	}
+	call(t, s, http.MethodGet, "/api/policies/"+policy.ID, admin, "", http.StatusOK, &got)
+	call(t, s, http.MethodPut, "/api/policies/"+policy.ID, admin,
+		`{"status":"Published","expected_version":`+strconv.Itoa(got.Policy.Version)+`}`, http.StatusOK, nil)
+
+	sam := signIn(t, s, "sam@example.com")
+	var pending []struct {
+		ID string `json:"id"`
+	}
+	call(t, s, http.MethodGet, "/api/me/pending", sam, "", http.StatusOK, &pending)
+	if len(pending) != 1 || pending[0].ID != policy.ID {
+		t.Fatalf("pending = %+v; want the Travel policy", pending)
+	}
+	call(t, s, http.MethodPost, "/api/policies/"+policy.ID+"/acknowledge", sam, "", http.StatusCreated, nil)
+	call(t, s, http.MethodPost, "/api/policies/"+policy.ID+"/acknowledge", sam, "", http.StatusConflict, nil)
+	call(t, s, http.MethodGet, "/api/me/pending", sam, "", http.StatusOK, &pending)
+	if len(pending) != 0 {
+		t.Errorf("pending after acknowledging = %+v; want none", pending)
+	}
+}
+
+// TestRun_ShutsDownWhenCancelled checks Run serves until its context is
+// cancelled and then returns cleanly.
+func TestRun_ShutsDownWhenCancelled(t *testing.T) {
+	db := testutil.NewDB(t)
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	srv, err := server.NewServer(server.Config{DB: db, Keys: testutil.NewKeyring(t, db), Store: store, UploadDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx, addr) }()
+	var resp *http.Response
+	for range 50 {
+		if resp, err = http.Get("http://" + addr + "/healthz"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /healthz: %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run = %v; want nil after cancel", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
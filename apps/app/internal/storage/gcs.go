@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// GCS stores objects in a Google Cloud Storage bucket through the JSON API.
+// It authenticates with GCS_ACCESS_TOKEN when set, otherwise with the
+// service account of the instance via the GCE/Cloud Run metadata server.
+// For HMAC-key access, use the s3 driver with S3_ENDPOINT set to
+// https://storage.googleapis.com instead.
+type GCS struct {
+	Bucket string
+	Client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (g *GCS) Name() string { return "gcs" }
+
+const (
+	gcsAPI          = "https://storage.googleapis.com"
+	gcsMetadataURL  = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	gcsTokenRefresh = time.Minute
+)
+
+func (g *GCS) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	k, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	u := gcsAPI + "/upload/storage/v1/b/" + url.PathEscape(g.Bucket) + "/o?uploadType=media&name=" + url.QueryEscape(k)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, r)
+	if err != nil {
+		return err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := g.do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (g *GCS) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := g.objectRequest(ctx, http.MethodGet, key, "?alt=media")
+	if err != nil {
+		return nil, err
+	}
+	res, err := g.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (g *GCS) Delete(ctx context.Context, key string) error {
+	req, err := g.objectRequest(ctx, http.MethodDelete, key, "")
+	if err != nil {
+		return err
+	}
+	res, err := g.do(req)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (g *GCS) objectRequest(ctx context.Context, method, key, query string) (*http.Request, error) {
+	k, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	u := gcsAPI + "/storage/v1/b/" + url.PathEscape(g.Bucket) + "/o/" + url.PathEscape(k) + query
+	return http.NewRequestWithContext(ctx, method, u, nil)
+}
+
+func (g *GCS) do(req *http.Request) (*http.Response, error) {
+	token, err := g.accessToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := g.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrNotFound
+	}
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		res.Body.Close()
+		return nil, fmt.Errorf("storage: gcs %s: %s: %s", req.Method, res.Status, msg)
+	}
+	return res, nil
+}
+
+// accessToken returns a cached OAuth token, refreshing it from the metadata
+// server shortly before it expires.
+func (g *GCS) accessToken(ctx context.Context) (string, error) {
+	if t := os.Getenv("GCS_ACCESS_TOKEN"); t != "" {
+		return t, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && time.Now().Before(g.expires) {
+		return g.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcsMetadataURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	res, err := g.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("storage: gcs metadata token: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("storage: gcs metadata token: %s", res.Status)
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("storage: gcs metadata token: %w", err)
+	}
+	g.token = body.AccessToken
+	g.expires = time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - gcsTokenRefresh)
+	return g.token, nil
+}
+
+func (g *GCS) client() *http.Client {
+	if g.Client != nil {
+		return g.Client
+	}
+	return http.DefaultClient
+}
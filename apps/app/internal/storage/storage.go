@@ -0,0 +1,80 @@
+// Package storage keeps binary data — attachments, uploaded logos, database
+// backups, and exports — outside the SQLite file and the container's
+// ephemeral disk. Drivers store opaque objects under slash-separated keys.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when no object exists under the key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Store is a blob store.
+type Store interface {
+	Name() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FromEnv builds the driver selected by STORAGE_DRIVER, defaulting to local
+// disk under STORAGE_LOCAL_DIR.
+func FromEnv() (Store, error) {
+	switch d := os.Getenv("STORAGE_DRIVER"); d {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "data"
+		}
+		return NewLocal(dir)
+	case "s3":
+		s := &S3{
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Region:          os.Getenv("S3_REGION"),
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			AccessKeyID:     firstEnv("S3_ACCESS_KEY_ID", "AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: firstEnv("S3_SECRET_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
+		}
+		if s.Bucket == "" || s.AccessKeyID == "" || s.SecretAccessKey == "" {
+			return nil, fmt.Errorf("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for the s3 driver")
+		}
+		if s.Region == "" {
+			s.Region = "us-east-1"
+		}
+		return s, nil
+	case "gcs":
+		bucket := os.Getenv("GCS_BUCKET")
+		if bucket == "" {
+			return nil, fmt.Errorf("GCS_BUCKET is required for the gcs driver")
+		}
+		return &GCS{Bucket: bucket}, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q (want local, s3, or gcs)", d)
+	}
+}
+
+// cleanKey rejects keys that are empty or would escape the store's root.
+func cleanKey(key string) (string, error) {
+	k := path.Clean("/" + key)[1:]
+	if k == "" || k != strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return k, nil
+}
+
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+// Package testserver runs the full API, middleware and routing included,
+// on an httptest server for integration tests. It is separate from
+// testutil because it imports the handlers, whose own tests use testutil.
+package testserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"policyflow/internal/database"
+	"policyflow/internal/email"
+	"policyflow/internal/server"
+	"policyflow/internal/storage"
+	"policyflow/internal/testutil"
+	"policyflow/internal/tokens"
+)
+
+// Server is a running API server over an in-memory database. Background
+// jobs are not started; tests call the runners they need.
+type Server struct {
+	*httptest.Server
+	Echo *echo.Echo
+	DB   *database.DB
+	Keys *tokens.Keyring
+	Mail *email.Outbox // every email the server has sent
+}
+
+// New starts a server that is closed when the test ends. Blobs and uploads
+// go to temporary directories and email to Mail; configure, if given, can
+// change anything else before the server is built.
+func New(tb testing.TB, configure ...func(*server.Config)) *Server {
+	tb.Helper()
+	db := testutil.NewDB(tb)
+	keys := testutil.NewKeyring(tb, db)
+	store, err := storage.NewLocal(tb.TempDir())
+	if err != nil {
+		tb.Fatalf("storage: %v", err)
+	}
+	mailer := email.New()
+	outbox := mailer.Capture()
+	cfg := server.Config{
+		DB:        db,
+		Keys:      keys,
+		Store:     store,
+		Mailer:    mailer,
+		JWTSecret: testutil.JWTSecret,
+		UploadDir: tb.TempDir(),
+	}
+	for _, f := range configure {
+		f(&cfg)
+	}
+	app, err := server.NewServer(cfg)
+	if err != nil {
+		tb.Fatalf("server.NewServer: %v", err)
+	}
+	e := app.Echo()
+	e.Logger.SetOutput(io.Discard)
+	srv := httptest.NewServer(app.Handler())
+	tb.Cleanup(srv.Close)
+	return &Server{Server: srv, Echo: e, DB: db, Keys: keys, Mail: outbox}
+}
+
+// Token returns a session token for u.
+func (s *Server) Token(tb testing.TB, u *database.User) string {
+	tb.Helper()
+	return testutil.SessionToken(tb, s.Keys, u)
+}
+
+// Do sends a request to path, with body as JSON when not empty and token
+// as the bearer token when not empty. The response body is closed when
+// the test ends.
+func (s *Server) Do(tb testing.TB, method, path, token, body string) *http.Response {
+	tb.Helper()
+	req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+	if err != nil {
+		tb.Fatalf("new request: %v", err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		tb.Fatalf("%s %s: %v", method, path, err)
+	}
+	tb.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
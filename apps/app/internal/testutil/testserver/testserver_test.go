@@ -0,0 +1,49 @@
+package testserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"policyflow/internal/server"
+	"policyflow/internal/testutil/testserver"
+)
+
+// TestServer_RoutesThroughMiddleware checks requests pass the real auth,
+// role, and maintenance middleware on their way to the handlers.
+func TestServer_RoutesThroughMiddleware(t *testing.T) {
+	ctx := context.Background()
+	s := testserver.New(t)
+	staff, err := s.DB.CreateUser(ctx, "sam@example.com", "Sam", "Staff", nil, nil)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	token := s.Token(t, staff)
+
+	if resp := s.Do(t, http.MethodGet, "/api/me", "", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /api/me without a token = %d; want 401", resp.StatusCode)
+	}
+	resp := s.Do(t, http.MethodGet, "/api/me", token, "")
+	var me struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&me); err != nil || resp.StatusCode != http.StatusOK || me.Email != staff.Email {
+		t.Errorf("GET /api/me = %d %+v, %v; want 200 for %s", resp.StatusCode, me, err, staff.Email)
+	}
+	if resp := s.Do(t, http.MethodPost, "/api/departments", token, `{"name":"Ops"}`); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Staff POST /api/departments = %d; want 403", resp.StatusCode)
+	}
+	if resp := s.Do(t, http.MethodGet, "/healthz", "", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz = %d; want 200", resp.StatusCode)
+	}
+
+	ro := testserver.New(t, func(cfg *server.Config) { cfg.Maintenance = true })
+	admin, err := ro.DB.CreateUser(ctx, "hr@example.com", "HR", "SuperAdmin", nil, nil)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if resp := ro.Do(t, http.MethodPost, "/api/departments", ro.Token(t, admin), `{"name":"Ops"}`); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("POST in maintenance mode = %d; want 503", resp.StatusCode)
+	}
+}
@@ -0,0 +1,129 @@
+package tokens
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SetPrivateKeys configures RSA or Ed25519 signing keys from PEM data
+// (PKCS#8 "PRIVATE KEY" or PKCS#1 "RSA PRIVATE KEY" blocks). The first key
+// signs new tokens; any further keys are still accepted and published in the
+// JWKS, which is how they are rotated: prepend the new key, and remove the
+// old one once its tokens have expired.
+func (k *Keyring) SetPrivateKeys(ctx context.Context, pemData []byte) error {
+	var keys []*key
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		var priv any
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("tokens: parse private key: %w", err)
+		}
+		kk, err := privateKey(priv)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, kk)
+	}
+	if len(keys) == 0 {
+		return errors.New("tokens: no private key found in PEM data")
+	}
+	k.mu.Lock()
+	k.private = keys
+	k.mu.Unlock()
+	return k.Reload(ctx)
+}
+
+func privateKey(priv any) (*key, error) {
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("tokens: unsupported private key")
+	}
+	var method jwt.SigningMethod
+	var prefix string
+	switch signer.(type) {
+	case *rsa.PrivateKey:
+		method, prefix = jwt.SigningMethodRS256, "rsa-"
+	case ed25519.PrivateKey:
+		method, prefix = jwt.SigningMethodEdDSA, "ed-"
+	case *ecdsa.PrivateKey:
+		return nil, errors.New("tokens: ECDSA keys are not supported; use RSA or Ed25519")
+	default:
+		return nil, errors.New("tokens: unsupported private key")
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	return &key{
+		kid:       prefix + hex.EncodeToString(sum[:4]),
+		method:    method,
+		signKey:   signer,
+		verifyKey: signer.Public(),
+	}, nil
+}
+
+// Asymmetric reports whether tokens are signed with a configured private key.
+func (k *Keyring) Asymmetric() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.private) > 0
+}
+
+// JWK is a public key in RFC 7517 form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS returns the public halves of the configured private keys. HMAC keys
+// are never published, so the set is empty unless SetPrivateKeys was used.
+func (k *Keyring) JWKS() []JWK {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	b64 := base64.RawURLEncoding.EncodeToString
+	out := make([]JWK, 0, len(k.private))
+	for _, kk := range k.private {
+		j := JWK{Kid: kk.kid, Use: "sig", Alg: kk.method.Alg()}
+		switch pub := kk.verifyKey.(type) {
+		case *rsa.PublicKey:
+			j.Kty, j.N, j.E = "RSA", b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			j.Kty, j.Crv, j.X = "OKP", "Ed25519", b64(pub)
+		}
+		out = append(out, j)
+	}
+	return out
+}
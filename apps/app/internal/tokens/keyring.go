@@ -0,0 +1,267 @@
+// Package tokens signs and verifies PolicyFlow's JWTs with a rotating set of
+// keys identified by the "kid" header, so the signing secret can be changed
+// without logging everyone out.
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"policyflow/internal/database"
+)
+
+// ErrUnknownKey is returned for tokens signed by a key that is not, or is no
+// longer, accepted.
+var ErrUnknownKey = errors.New("tokens: unknown or expired signing key")
+
+// ErrPrivateKeysConfigured is returned by Rotate when signing keys come from
+// a key file, which is rotated by editing the file instead.
+var ErrPrivateKeysConfigured = errors.New("tokens: signing keys are configured by key file")
+
+// DefaultRotationWindow is how long tokens signed by the previous key are
+// still accepted after a rotation. It matches the longest session lifetime.
+const DefaultRotationWindow = 7 * 24 * time.Hour
+
+// unknownKeyReloadInterval is the least time between reloads prompted by a
+// token with an unknown kid, which anyone can send.
+const unknownKeyReloadInterval = 5 * time.Second
+
+type key struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   any // []byte for HMAC, otherwise a crypto.Signer
+	verifyKey any // []byte for HMAC, otherwise the public key
+	expiresAt *time.Time
+}
+
+func hmacKey(kid string, secret []byte, expiresAt *time.Time) *key {
+	return &key{kid: kid, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret, expiresAt: expiresAt}
+}
+
+// Keyring holds the current signing key and every previous key still inside
+// its rotation window. Rotated keys are stored in the database so that all
+// instances share them; JWT_SECRET remains the key until the first rotation.
+// When private keys are configured with SetPrivateKeys, the first of them
+// signs instead and the HMAC keys are only used to verify older tokens.
+type Keyring struct {
+	db      *database.DB
+	envKey  *key
+	private []*key
+
+	mu     sync.RWMutex
+	keys   map[string]*key
+	signer *key
+
+	missMu   sync.Mutex
+	lastMiss time.Time // last reload for an unknown kid
+}
+
+// EnvKID derives the key ID of the JWT_SECRET key from the secret itself, so
+// changing the variable yields a distinct key.
+func EnvKID(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "env-" + hex.EncodeToString(sum[:4])
+}
+
+// NewKeyring loads the stored keys. envSecret is the JWT_SECRET value.
+func NewKeyring(ctx context.Context, db *database.DB, envSecret string) (*Keyring, error) {
+	k := &Keyring{db: db, envKey: hmacKey(EnvKID(envSecret), []byte(envSecret), nil)}
+	if err := k.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload re-reads the keys from the database, dropping expired ones.
+func (k *Keyring) Reload(ctx context.Context) error {
+	rows, err := k.db.ListJWTKeys(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	keys := map[string]*key{}
+	var signer *key
+	envSeen := false
+	for _, r := range rows {
+		if r.KID == k.envKey.kid {
+			envSeen = true
+		}
+		if r.ExpiresAt != nil && !now.Before(*r.ExpiresAt) {
+			continue
+		}
+		var secret []byte
+		if r.Secret == "" {
+			if r.KID != k.envKey.kid {
+				continue // an environment key that is no longer configured
+			}
+			secret = k.envKey.signKey.([]byte)
+		} else if secret, err = base64.StdEncoding.DecodeString(r.Secret); err != nil {
+			return fmt.Errorf("tokens: key %s: %w", r.KID, err)
+		}
+		kk := hmacKey(r.KID, secret, r.ExpiresAt)
+		keys[kk.kid] = kk
+		if signer == nil && kk.expiresAt == nil {
+			signer = kk // rows are newest first
+		}
+	}
+	if !envSeen {
+		// Never rotated: JWT_SECRET is current.
+		keys[k.envKey.kid] = k.envKey
+		if signer == nil {
+			signer = k.envKey
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, pk := range k.private {
+		keys[pk.kid] = pk
+	}
+	if len(k.private) > 0 {
+		signer = k.private[0]
+	}
+	if signer == nil {
+		return errors.New("tokens: no current signing key")
+	}
+	k.keys, k.signer = keys, signer
+	return nil
+}
+
+// Refresh reloads the keys every interval until ctx is cancelled, so a
+// rotation made by another instance or the CLI is picked up.
+func (k *Keyring) Refresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Reload(ctx); err != nil {
+				log.Printf("jwt key reload: %v", err)
+			}
+		}
+	}
+}
+
+// Sign issues a token signed by the current key.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	signer := k.signer
+	k.mu.RUnlock()
+	t := jwt.NewWithClaims(signer.method, claims)
+	t.Header["kid"] = signer.kid
+	return t.SignedString(signer.signKey)
+}
+
+// Parse verifies a token's signature and expiry into claims. Tokens without
+// a kid predate key rotation and are checked against JWT_SECRET. An unknown
+// kid prompts a reload, rate-limited so that bogus tokens cannot make every
+// request query the database.
+func (k *Keyring) Parse(tokenStr string, claims jwt.Claims) error {
+	reloaded := false
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			kid = k.envKey.kid
+		}
+		kk := k.lookup(kid)
+		if kk == nil && !reloaded {
+			// Possibly rotated by another instance since the last refresh.
+			reloaded = true
+			if k.reloadForUnknown() {
+				kk = k.lookup(kid)
+			}
+		}
+		if kk == nil {
+			return nil, ErrUnknownKey
+		}
+		if t.Method.Alg() != kk.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return kk.verifyKey, nil
+	})
+	return err
+}
+
+// reloadForUnknown reloads the keys after a lookup missed, at most once per
+// unknownKeyReloadInterval, reporting whether it did.
+func (k *Keyring) reloadForUnknown() bool {
+	k.missMu.Lock()
+	defer k.missMu.Unlock()
+	if time.Since(k.lastMiss) < unknownKeyReloadInterval {
+		return false
+	}
+	k.lastMiss = time.Now()
+	return k.Reload(context.Background()) == nil
+}
+
+func (k *Keyring) lookup(kid string) *key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	kk := k.keys[kid]
+	if kk != nil && kk.expiresAt != nil && !time.Now().Before(*kk.expiresAt) {
+		return nil
+	}
+	return kk
+}
+
+// Rotate generates a new signing key. Tokens signed by the keys it replaces
+// stay valid for window.
+func (k *Keyring) Rotate(ctx context.Context, window time.Duration) (*database.JWTKey, error) {
+	if k.Asymmetric() {
+		return nil, ErrPrivateKeysConfigured
+	}
+	secret := make([]byte, 32)
+	id := make([]byte, 4)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	nk := &database.JWTKey{KID: hex.EncodeToString(id), Secret: base64.StdEncoding.EncodeToString(secret)}
+	if err := k.db.RotateJWTKey(ctx, k.envKey.kid, nk, time.Now().Add(window)); err != nil {
+		return nil, err
+	}
+	if err := k.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return nk, nil
+}
+
+// KeyInfo describes an accepted key without its secret.
+type KeyInfo struct {
+	KID       string     `json:"kid"`
+	Alg       string     `json:"alg"`
+	Current   bool       `json:"current"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Keys lists the accepted keys.
+func (k *Keyring) Keys() []KeyInfo {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	out := make([]KeyInfo, 0, len(k.keys))
+	for _, kk := range k.keys {
+		out = append(out, KeyInfo{KID: kk.kid, Alg: kk.method.Alg(), Current: kk == k.signer, ExpiresAt: kk.expiresAt})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Current != out[j].Current {
+			return out[i].Current
+		}
+		return out[i].KID < out[j].KID
+	})
+	return out
+}
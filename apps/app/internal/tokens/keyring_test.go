@@ -0,0 +1,164 @@
+package tokens
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	_ "modernc.org/sqlite"
+
+	"policyflow/internal/database"
+)
+
+func makeTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+
+	db := database.New(conn)
+	ctx := context.Background()
+	if err := db.Init(ctx); err != nil {
+		t.Fatalf("db.Init: %v", err)
+	}
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("db.Migrate: %v", err)
+	}
+	return db
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": "u1", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+// TestKeyring_Rotate verifies that tokens signed before a rotation, including
+// legacy tokens without a kid, stay valid during the window and are rejected
+// once it has passed.
+func TestKeyring_Rotate(t *testing.T) {
+	ctx := context.Background()
+	db := makeTestDB(t)
+	k, err := NewKeyring(ctx, db, "secret")
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+
+	before, _ := k.Sign(claims())
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims()).SignedString([]byte("secret"))
+
+	nk, err := k.Rotate(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	after, _ := k.Sign(claims())
+	tok, _, _ := jwt.NewParser().ParseUnverified(after, jwt.MapClaims{})
+	if tok.Header["kid"] != nk.KID {
+		t.Errorf("new token kid = %v; want %s", tok.Header["kid"], nk.KID)
+	}
+	for name, s := range map[string]string{"before": before, "legacy": legacy, "after": after} {
+		if err := k.Parse(s, jwt.MapClaims{}); err != nil {
+			t.Errorf("%s token rejected during window: %v", name, err)
+		}
+	}
+
+	// A second instance sharing the database sees the new key as current.
+	other, _ := NewKeyring(ctx, db, "secret")
+	if err := other.Parse(after, jwt.MapClaims{}); err != nil {
+		t.Errorf("other instance rejected rotated token: %v", err)
+	}
+
+	if _, err := k.Rotate(ctx, 0); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if err := k.Parse(after, jwt.MapClaims{}); err == nil {
+		t.Error("token signed by a retired key accepted after the window")
+	}
+
+	// An instance that missed the rotation reloads on seeing the new kid,
+	// but not again for every unknown kid that follows.
+	stale, _ := NewKeyring(ctx, db, "secret")
+	bogus := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	bogus.Header["kid"] = "bogus"
+	forged, _ := bogus.SignedString([]byte("guess"))
+	if err := stale.Parse(forged, jwt.MapClaims{}); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown kid: err = %v; want ErrUnknownKey", err)
+	}
+	next, err := k.Rotate(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	rotated, _ := k.Sign(claims())
+	if err := stale.Parse(rotated, jwt.MapClaims{}); err == nil {
+		t.Errorf("reloaded for kid %s right after another unknown kid", next.KID)
+	}
+	stale.lastMiss = time.Now().Add(-unknownKeyReloadInterval)
+	if err := stale.Parse(rotated, jwt.MapClaims{}); err != nil {
+		t.Errorf("rotated token rejected once reloading was allowed: %v", err)
+	}
+}
+
+// TestKeyring_PrivateKeys verifies EdDSA signing from a PEM file, that the
+// JWKS lets a third party verify the token, and that HMAC tokens issued
+// before the switch still parse.
+func TestKeyring_PrivateKeys(t *testing.T) {
+	ctx := context.Background()
+	k, err := NewKeyring(ctx, makeTestDB(t), "secret")
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	hmacToken, _ := k.Sign(claims())
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	edDER, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	pemData := append(
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})...,
+	)
+	if err := k.SetPrivateKeys(ctx, pemData); err != nil {
+		t.Fatalf("SetPrivateKeys: %v", err)
+	}
+
+	signed, err := k.Sign(claims())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	jwks := k.JWKS()
+	if len(jwks) != 2 || jwks[0].Kty != "OKP" || jwks[1].Kty != "RSA" {
+		t.Fatalf("JWKS = %+v; want OKP then RSA", jwks)
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(jwks[0].X)
+	tok, err := jwt.Parse(signed, func(t *jwt.Token) (any, error) { return ed25519.PublicKey(x), nil })
+	if err != nil || tok.Method.Alg() != "EdDSA" || tok.Header["kid"] != jwks[0].Kid {
+		t.Errorf("verify with JWKS: alg=%v kid=%v err=%v", tok.Method.Alg(), tok.Header["kid"], err)
+	}
+
+	for name, s := range map[string]string{"eddsa": signed, "hmac": hmacToken} {
+		if err := k.Parse(s, jwt.MapClaims{}); err != nil {
+			t.Errorf("%s token rejected: %v", name, err)
+		}
+	}
+
+	// An HS256 token using the public key as secret must not pass as the Ed25519 key.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	forged.Header["kid"] = jwks[0].Kid
+	forgedStr, _ := forged.SignedString(x)
+	if err := k.Parse(forgedStr, jwt.MapClaims{}); err == nil {
+		t.Error("HS256 token accepted under an EdDSA kid")
+	}
+
+	if _, err := k.Rotate(ctx, time.Hour); !errors.Is(err, ErrPrivateKeysConfigured) {
+		t.Errorf("Rotate err = %v; want ErrPrivateKeysConfigured", err)
+	}
+}
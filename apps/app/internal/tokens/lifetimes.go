@@ -0,0 +1,60 @@
+package tokens
+
+import (
+	"context"
+	"time"
+
+	"policyflow/internal/database"
+)
+
+// Lifetimes controls how long sign-in links and sessions last. They are
+// organization settings, so changes apply without a restart.
+type Lifetimes struct {
+	MagicLink time.Duration
+	Session   time.Duration
+	Idle      time.Duration // 0 = no inactivity timeout
+}
+
+// DefaultLifetimes are used until SuperAdmin changes them.
+var DefaultLifetimes = Lifetimes{MagicLink: 24 * time.Hour, Session: 7 * 24 * time.Hour}
+
+// StrictLifetimes is the "strict" settings preset for high-security orgs.
+var StrictLifetimes = Lifetimes{MagicLink: 15 * time.Minute, Session: 12 * time.Hour, Idle: 30 * time.Minute}
+
+// LoadLifetimes reads the lifetime settings, falling back to the defaults.
+func LoadLifetimes(ctx context.Context, db *database.DB) (Lifetimes, error) {
+	l := DefaultLifetimes
+	link, err := db.GetIntSetting(ctx, database.SettingMagicLinkTTL, int(l.MagicLink/time.Minute))
+	if err != nil {
+		return l, err
+	}
+	session, err := db.GetIntSetting(ctx, database.SettingSessionTTL, int(l.Session/time.Hour))
+	if err != nil {
+		return l, err
+	}
+	idle, err := db.GetIntSetting(ctx, database.SettingIdleTimeout, 0)
+	if err != nil {
+		return l, err
+	}
+	if link > 0 {
+		l.MagicLink = time.Duration(link) * time.Minute
+	}
+	if session > 0 {
+		l.Session = time.Duration(session) * time.Hour
+	}
+	if idle > 0 {
+		l.Idle = time.Duration(idle) * time.Minute
+	}
+	return l, nil
+}
+
+// SessionExpiry is when a session signed in at authTime expires if its
+// token is issued now: the idle timeout from now, capped by the session
+// length from sign-in.
+func (l Lifetimes) SessionExpiry(authTime, now time.Time) time.Time {
+	exp := authTime.Add(l.Session)
+	if l.Idle > 0 && now.Add(l.Idle).Before(exp) {
+		exp = now.Add(l.Idle)
+	}
+	return exp
+}
@@ -0,0 +1,130 @@
+// Package webui serves the frontend's static export: pages by route, with
+// index.html for client-side routes, and a real 404 for missing assets.
+package webui
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// immutablePrefix holds the build's content-hashed assets, whose names
+// change whenever their content does.
+const immutablePrefix = "_next/static/"
+
+// fallbackPage is served while the frontend has not been built, unless a
+// maintenance page is configured.
+var fallbackPage = []byte(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>PolicyFlow</title></head>
+<body><h1>PolicyFlow is being updated</h1><p>Please try again in a few minutes.</p></body></html>
+`)
+
+// notFoundPage is served for missing assets when the build has no 404.html.
+var notFoundPage = []byte(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Not found</title></head>
+<body><h1>Not found</h1><p>The file you asked for does not exist.</p></body></html>
+`)
+
+// Handler serves files, the static export of the frontend. Paths without
+// an extension are pages: page.html, then page/index.html, then index.html
+// so the app can route them itself. A missing file with an extension is
+// answered 404 with the build's 404.html. Hashed assets are cached for a
+// year; everything else is revalidated, with an ETag of its content.
+//
+// When files holds no build, only the placeholder index.html, every
+// request gets maintenance (or a built-in page) with 503, so a binary
+// built without the frontend says so instead of serving a blank app.
+func Handler(files fs.FS, maintenance []byte) http.Handler {
+	if !Built(files) {
+		if maintenance == nil {
+			maintenance = fallbackPage
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writePage(w, r, http.StatusServiceUnavailable, maintenance)
+		})
+	}
+	notFound, err := fs.ReadFile(files, "404.html")
+	if err != nil {
+		notFound = notFoundPage
+	}
+	var etags sync.Map // path → ETag; the files never change
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		name, ok := resolve(files, strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/"))
+		if !ok {
+			writePage(w, r, http.StatusNotFound, notFound)
+			return
+		}
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		etag, ok := etags.Load(name)
+		if !ok {
+			sum := sha256.Sum256(data)
+			etag, _ = etags.LoadOrStore(name, `"`+hex.EncodeToString(sum[:16])+`"`)
+		}
+		h := w.Header()
+		h.Set("ETag", etag.(string))
+		if strings.HasPrefix(name, immutablePrefix) {
+			h.Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			h.Set("Cache-Control", "no-cache")
+		}
+		if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+			h.Set("Content-Type", ct)
+		}
+		// ServeContent rather than http.FileServer, which redirects
+		// /index.html to / and so loops on the app's fallback.
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+	})
+}
+
+// Built reports whether files holds a frontend build rather than the
+// placeholder checked into the repository.
+func Built(files fs.FS) bool {
+	_, err := fs.Stat(files, "_next")
+	return err == nil
+}
+
+// resolve finds the file to serve for a cleaned request path, reporting
+// false when there is none. Next.js exports with trailingSlash:false write
+// `page.html` files rather than `page/index.html` directories, so both are
+// tried.
+func resolve(files fs.FS, name string) (string, bool) {
+	if name == "" || name == "." {
+		return "index.html", true
+	}
+	if fi, err := fs.Stat(files, name); err == nil && !fi.IsDir() {
+		return name, true
+	}
+	if path.Ext(name) != "" {
+		return "", false
+	}
+	for _, page := range []string{name + ".html", name + "/index.html"} {
+		if _, err := fs.Stat(files, page); err == nil {
+			return page, true
+		}
+	}
+	return "index.html", true
+}
+
+func writePage(w http.ResponseWriter, r *http.Request, status int, page []byte) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(page)
+	}
+}
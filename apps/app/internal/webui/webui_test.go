@@ -0,0 +1,74 @@
+package webui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestHandler_PagesAssetsAndMissingFiles verifies that pages and
+// client-side routes get HTML, hashed assets are cached as immutable and
+// revalidate by ETag, and missing assets get the build's 404 page.
+func TestHandler_PagesAssetsAndMissingFiles(t *testing.T) {
+	h := Handler(fstest.MapFS{
+		"index.html":                  {Data: []byte("<p>app</p>")},
+		"404.html":                    {Data: []byte("<p>custom 404</p>")},
+		"dashboard.html":              {Data: []byte("<p>dashboard</p>")},
+		"_next/static/chunks/a1b2.js": {Data: []byte("console.log(1)")},
+	}, nil)
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for path, want := range map[string]string{
+		"/":               "<p>app</p>",
+		"/dashboard":      "<p>dashboard</p>",
+		"/policies/p1":    "<p>app</p>",
+		"/../../etc/pass": "<p>app</p>",
+	} {
+		if rec := get(path); rec.Code != http.StatusOK || rec.Body.String() != want ||
+			rec.Header().Get("Cache-Control") != "no-cache" {
+			t.Errorf("%s: %d %q (Cache-Control %q); want 200 %q", path, rec.Code, rec.Body, rec.Header().Get("Cache-Control"), want)
+		}
+	}
+
+	rec := get("/_next/static/chunks/a1b2.js")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") ||
+		!strings.HasPrefix(rec.Header().Get("Content-Type"), "text/javascript") {
+		t.Errorf("hashed asset: %d %v", rec.Code, rec.Header())
+	}
+	if again := get("/_next/static/chunks/a1b2.js", "If-None-Match", rec.Header().Get("ETag")); again.Code != http.StatusNotModified {
+		t.Errorf("revalidation: %d; want 304", again.Code)
+	}
+
+	for _, path := range []string{"/_next/static/chunks/gone.js", "/favicon.ico"} {
+		if rec := get(path); rec.Code != http.StatusNotFound || rec.Body.String() != "<p>custom 404</p>" {
+			t.Errorf("%s: %d %q; want 404 with the custom page", path, rec.Code, rec.Body)
+		}
+	}
+}
+
+// TestHandler_MaintenancePageWithoutBuild verifies that the placeholder
+// export is not served as the app.
+func TestHandler_MaintenancePageWithoutBuild(t *testing.T) {
+	files := fstest.MapFS{"index.html": {Data: []byte("Run make build-web")}}
+	for maintenance, want := range map[string]string{"": "being updated", "<p>back soon</p>": "back soon"} {
+		var page []byte
+		if maintenance != "" {
+			page = []byte(maintenance)
+		}
+		rec := httptest.NewRecorder()
+		Handler(files, page).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+		if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("maintenance %q: %d %q; want 503 with %q", maintenance, rec.Code, rec.Body, want)
+		}
+	}
+}
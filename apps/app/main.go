@@ -1,42 +1,104 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	"database/sql"
 	"embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"io/fs"
 	"log"
-	"mime"
+	"maps"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/labstack/echo/v4"
-	echomw "github.com/labstack/echo/v4/middleware"
 	_ "modernc.org/sqlite"
 
 	"policyflow/internal/database"
-	"policyflow/internal/email"
+	"policyflow/internal/demo"
 	"policyflow/internal/handlers"
-	authmw "policyflow/internal/middleware"
+	"policyflow/internal/replica"
+	"policyflow/internal/secrets"
 	"policyflow/internal/seed"
+	"policyflow/internal/selfcheck"
+	"policyflow/internal/server"
+	"policyflow/internal/storage"
+	"policyflow/internal/tokens"
+	"policyflow/internal/webui"
 )
 
 //go:embed all:web/out
 var webFiles embed.FS
 
 func main() {
+	// Secrets mounted as files or kept in Vault go into the environment
+	// before anything reads it.
+	loaded, err := secrets.Load(context.Background())
+	if err != nil {
+		log.Fatalf("secrets: %v", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(loaded)) {
+		log.Printf("%s loaded from %s", name, loaded[name])
+	}
+
 	dbPath := getEnv("DB_PATH", "policyflow.db")
-	jwtSecret := getEnv("JWT_SECRET", "dev-secret-change-me-in-production")
 	port := getEnv("PORT", "8080")
+	if len(os.Args) > 1 && os.Args[1] == "--check" {
+		os.Exit(runCheck(dbPath, os.Args[2:]))
+	}
 
 	if os.Getenv("JWT_SECRET") == "" {
 		log.Println("WARNING: JWT_SECRET not set — using insecure default (development only)")
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cfg, err := server.ConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	store := cfg.Store
+	log.Printf("Blob storage: %s", store.Name())
+	if cfg.Scanner != nil {
+		log.Printf("Upload virus scanning: %s", cfg.Scanner.Name())
+	}
+
 	// ── Database ───────────────────────────────────────────────────────────
+	var replicaInterval time.Duration
+	if v := os.Getenv("REPLICA_INTERVAL"); v != "" {
+		if replicaInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid REPLICA_INTERVAL: %v", err)
+		}
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore-replica" {
+		restoreReplica(ctx, store, dbPath, os.Args[2:])
+		return
+	}
+	if _, err := os.Stat(dbPath); replicaInterval > 0 && errors.Is(err, os.ErrNotExist) {
+		m, err := replica.Restore(ctx, store, dbPath, time.Time{})
+		switch {
+		case errors.Is(err, replica.ErrNoReplica):
+			log.Printf("No replica in %s storage; starting with a new database", store.Name())
+		case err != nil:
+			log.Fatalf("restore replica: %v", err)
+		default:
+			log.Printf("Restored database from replica generation %s (%d WAL segments)", m.ID, len(m.Segments))
+		}
+	}
+
 	sqlDB, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		log.Fatalf("open db: %v", err)
@@ -45,121 +107,366 @@ func main() {
 	sqlDB.SetMaxOpenConns(1) // SQLite is single-writer
 
 	db := database.New(sqlDB)
-	if err := db.Init(); err != nil {
+	if err := db.Init(ctx); err != nil {
 		log.Fatalf("init db: %v", err)
 	}
-	if err := db.Migrate(); err != nil {
-		log.Fatalf("migrate db: %v", err)
-	}
-
-	adminEmail := os.Getenv("ADMIN_EMAIL")
-	adminName := os.Getenv("ADMIN_NAME")
-	if err := seed.Run(db, adminEmail, adminName); err != nil {
-		log.Printf("seed warning: %v", err)
-	}
-
-	// ── Services ───────────────────────────────────────────────────────────
-	mailer := email.New()
-	authMW := authmw.NewAuth(jwtSecret, db)
-
-	authH := handlers.NewAuth(db, mailer, jwtSecret)
-	userH := handlers.NewUser(db, mailer, jwtSecret)
-	policyH := handlers.NewPolicy(db)
-	deptH := handlers.NewDepartments(db)
-
-	// ── Echo ───────────────────────────────────────────────────────────────
-	e := echo.New()
-	e.HideBanner = true
-	e.Use(echomw.Logger())
-	e.Use(echomw.Recover())
-	e.Use(echomw.CORSWithConfig(echomw.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAuthorization},
-	}))
-
-	// ── API routes ─────────────────────────────────────────────────────────
-	api := e.Group("/api")
-
-	// Public
-	api.POST("/magic-link", authH.RequestMagicLink)
-	api.GET("/magic-login", authH.MagicLogin)
-
-	// Authenticated (any role)
-	authAPI := api.Group("", authMW.Require)
-	authAPI.GET("/me", authH.Me)
-	authAPI.GET("/departments", deptH.List)
-	authAPI.GET("/policies", policyH.List)
-	authAPI.GET("/policies/:id", policyH.Get)
-	authAPI.GET("/policies/:id/versions", policyH.Versions)
-	authAPI.POST("/policies/:id/acknowledge", policyH.Acknowledge)
-
-	// DeptAdmin + SuperAdmin
-	deptAdminAPI := api.Group("", authMW.Require, authMW.RequireDeptAdmin)
-	deptAdminAPI.POST("/policies", policyH.Create)
-	deptAdminAPI.PUT("/policies/:id", policyH.Update)
-	deptAdminAPI.POST("/policies/:id/versions", policyH.CreateVersion)
-	deptAdminAPI.GET("/users", userH.List)
-	deptAdminAPI.POST("/users", userH.Create)
-	deptAdminAPI.GET("/admin/stats", policyH.AdminStats)
-
-	// SuperAdmin only
-	superAdminAPI := api.Group("", authMW.Require, authMW.RequireSuperAdmin)
-	superAdminAPI.POST("/departments", deptH.Create)
-	superAdminAPI.PUT("/departments/:id", deptH.Update)
-	superAdminAPI.DELETE("/departments/:id", deptH.Delete)
-	superAdminAPI.PUT("/users/:id", userH.Update)
-	superAdminAPI.DELETE("/users/:id", userH.Delete)
-
-	// ── Frontend ───────────────────────────────────────────────────────────
+	healthH := handlers.NewHealth(db)
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "migrate":
+		runMigrate(ctx, db, os.Args[2:])
+		return
+	case os.Getenv("STARTUP_MIGRATE") == "false":
+		// Migrations run as a separate job (`policyflow migrate`).
+		awaitMigrations(ctx, db, healthH, port)
+	default:
+		if err := db.Migrate(ctx); err != nil {
+			log.Fatalf("migrate db: %v", err)
+		}
+	}
+	readPoolSize := 4
+	if v := os.Getenv("DB_READ_POOL_SIZE"); v != "" {
+		if readPoolSize, err = strconv.Atoi(v); err != nil || readPoolSize < 0 {
+			log.Fatalf("invalid DB_READ_POOL_SIZE: %q", v)
+		}
+	}
+	if readPoolSize > 0 {
+		// Reports and exports read through their own read-only connections so
+		// they never queue behind, or hold up, the writer.
+		readDB, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro&_pragma=busy_timeout(5000)&_pragma=query_only(1)")
+		if err != nil {
+			log.Fatalf("open read pool: %v", err)
+		}
+		defer readDB.Close()
+		readDB.SetMaxOpenConns(readPoolSize)
+		db.SetReadPool(readDB)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "consolidate-departments" {
+		consolidateDepartments(ctx, db, os.Args[2:])
+		return
+	}
+
+	// ── Signing keys ───────────────────────────────────────────────────────
+	keys, err := tokens.NewKeyring(ctx, db, cfg.JWTSecret)
+	if err != nil {
+		log.Fatalf("jwt keys: %v", err)
+	}
+	if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+		pemData, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("read JWT_PRIVATE_KEY_FILE: %v", err)
+		}
+		if err := keys.SetPrivateKeys(ctx, pemData); err != nil {
+			log.Fatalf("JWT_PRIVATE_KEY_FILE: %v", err)
+		}
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rotate-jwt-key" {
+		rotationWindow := cmp.Or(cfg.RotationWindow, tokens.DefaultRotationWindow)
+		k, err := keys.Rotate(ctx, rotationWindow)
+		if err != nil {
+			log.Fatalf("rotate jwt key: %v", err)
+		}
+		fmt.Printf("Signing key %s is now current; previous keys are accepted until %s.\n",
+			k.KID, time.Now().Add(rotationWindow).UTC().Format(time.RFC3339))
+		return
+	}
+
+	if cfg.DemoMode {
+		// Generated data only, wiped and refilled now and every night.
+		if err := demo.Reset(ctx, db); err != nil {
+			log.Fatalf("DEMO_MODE: %v", err)
+		}
+	} else {
+		adminEmail := os.Getenv("ADMIN_EMAIL")
+		adminName := os.Getenv("ADMIN_NAME")
+		if err := seed.Run(ctx, db, adminEmail, adminName); err != nil {
+			log.Printf("seed warning: %v", err)
+		}
+	}
+
+	// ── Replication ────────────────────────────────────────────────────────
+	if replicaInterval > 0 {
+		rep := replica.New(sqlDB, dbPath, store)
+		for env, d := range map[string]*time.Duration{
+			"REPLICA_SNAPSHOT_INTERVAL": &rep.SnapshotInterval,
+			"REPLICA_RETENTION":         &rep.Retention,
+		} {
+			if v := os.Getenv(env); v != "" {
+				if *d, err = time.ParseDuration(v); err != nil {
+					log.Fatalf("invalid %s: %v", env, err)
+				}
+			}
+		}
+		if err := rep.Start(ctx); err != nil {
+			log.Fatalf("replica: %v", err)
+		}
+		go rep.Run(ctx, replicaInterval)
+		log.Printf("WAL replication enabled (every %s)", replicaInterval)
+	}
+
+	// ── Server ─────────────────────────────────────────────────────────────
+	cfg.DB, cfg.Keys, cfg.Health = db, keys, healthH
+	cfg.Frontend = frontend()
+	srv, err := server.NewServer(cfg)
+	if err != nil {
+		log.Fatalf("server: %v", err)
+	}
+	if err := srv.Run(ctx, ":"+port); err != nil {
+		log.Fatalf("server: %v", err)
+	}
+	log.Println("PolicyFlow stopped")
+}
+
+// frontend serves the Next.js app: proxied to WEB_DEV_PROXY in
+// development, otherwise the static export embedded in the binary.
+func frontend() http.Handler {
 	if devProxy := os.Getenv("WEB_DEV_PROXY"); devProxy != "" {
 		target, err := url.Parse(devProxy)
 		if err != nil {
 			log.Fatalf("invalid WEB_DEV_PROXY: %v", err)
 		}
-		proxy := httputil.NewSingleHostReverseProxy(target)
-		e.Any("/*", echo.WrapHandler(proxy))
 		log.Printf("Frontend proxied to %s", devProxy)
-	} else {
-		subFS, err := fs.Sub(webFiles, "web/out")
+		return httputil.NewSingleHostReverseProxy(target)
+	}
+	subFS, err := fs.Sub(webFiles, "web/out")
+	if err != nil {
+		log.Fatalf("embed sub FS: %v", err)
+	}
+	var maintenance []byte
+	if p := os.Getenv("WEB_MAINTENANCE_PAGE"); p != "" {
+		if maintenance, err = os.ReadFile(p); err != nil {
+			log.Fatalf("WEB_MAINTENANCE_PAGE: %v", err)
+		}
+	}
+	if !webui.Built(subFS) {
+		log.Println("WARNING: frontend not built (run make build-web); serving the maintenance page")
+	}
+	return webui.Handler(subFS, maintenance)
+}
+
+// runCheck is `policyflow --check [-json]`: it checks the configuration
+// and the services it names without starting the server, applying
+// migrations, or creating the database, prints the report, and returns the
+// exit status, 1 if any check failed.
+func runCheck(dbPath string, args []string) int {
+	flags := flag.NewFlagSet("--check", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	flags.Parse(args)
+
+	ctx := context.Background()
+	cfg, err := server.ConfigFromEnv()
+	checker := &selfcheck.Checker{Store: cfg.Store, Mailer: cfg.Mailer, ConfigErr: err}
+	if _, err := os.Stat(dbPath); err == nil {
+		sqlDB, err := sql.Open("sqlite", "file:"+dbPath+"?mode=rw&_pragma=busy_timeout(5000)")
 		if err != nil {
-			log.Fatalf("embed sub FS: %v", err)
+			log.Fatalf("open db: %v", err)
 		}
-		e.GET("/*", func(c echo.Context) error {
-			rawPath := strings.TrimPrefix(c.Request().URL.Path, "/")
-			if rawPath == "" {
-				rawPath = "index.html"
-			}
-			// Next.js static export with trailingSlash:false generates `page.html`
-			// files rather than `page/index.html` directories, so check for both.
-			if _, err := fs.Stat(subFS, rawPath); err != nil {
-				htmlPath := rawPath + ".html"
-				if !strings.Contains(rawPath, ".") {
-					if _, err2 := fs.Stat(subFS, htmlPath); err2 == nil {
-						rawPath = htmlPath
-					} else {
-						rawPath = "index.html"
-					}
-				} else {
-					rawPath = "index.html"
-				}
+		defer sqlDB.Close()
+		sqlDB.SetMaxOpenConns(1)
+		checker.DB = database.New(sqlDB)
+	}
+	report := checker.Run(ctx)
+	if checker.DB == nil {
+		// A new deployment: the database is created on first start.
+		for i, r := range report.Checks {
+			if r.Name == "database" || r.Name == "migrations" {
+				report.Checks[i].Detail = dbPath + " does not exist yet; it is created on first start"
 			}
-			// Serve directly from embed FS to avoid http.FileServer's redirect
-			// behaviour of /index.html → / (which causes an infinite 301 loop).
-			data, err := fs.ReadFile(subFS, rawPath)
-			if err != nil {
-				return echo.ErrNotFound
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+		for _, r := range report.Checks {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, r.Status, r.Detail)
+		}
+		w.Flush()
+	}
+	if report.Failed() {
+		return 1
+	}
+	return 0
+}
+
+// awaitMigrations blocks until a separate migration job has applied every
+// migration this build knows. Meanwhile a probe-only server answers on port,
+// live but not ready, so the orchestrator neither restarts the process nor
+// routes traffic to it.
+func awaitMigrations(ctx context.Context, db *database.DB, health *handlers.Health, port string) {
+	probes := echo.New()
+	probes.HideBanner, probes.HidePort = true, true
+	probes.GET("/healthz", health.Live)
+	probes.GET("/readyz", health.Ready)
+	go func() {
+		if err := probes.Start(":" + port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("probe server: %v", err)
+		}
+	}()
+	defer probes.Shutdown(ctx)
+
+	logged := false
+	for {
+		pending, err := db.PendingMigrations(ctx)
+		if err != nil {
+			log.Fatalf("check migrations: %v", err)
+		}
+		if len(pending) == 0 {
+			return
+		}
+		if !logged {
+			log.Printf("STARTUP_MIGRATE=false: waiting for %d pending migration(s), starting with %s; run `policyflow migrate`", len(pending), pending[0])
+			logged = true
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// runMigrate is `policyflow migrate [status|up|down|redo] [-steps N]
+// [-dry-run]`. up (the default) applies pending migrations, all of them
+// unless -steps is given; down undoes the last -steps (default 1) applied;
+// redo undoes and reapplies the last one. -dry-run prints the SQL instead.
+func runMigrate(ctx context.Context, db *database.DB, args []string) {
+	action := "up"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	flags := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	steps := flags.Int("steps", 0, "how many migrations to apply (up, default all) or undo (down, default 1)")
+	dryRun := flags.Bool("dry-run", false, "print the SQL that would run without running it")
+	flags.Parse(args)
+	if *steps < 0 {
+		log.Fatalf("migrate: -steps must not be negative")
+	}
+
+	var plan []database.MigrationStep
+	var err error
+	switch action {
+	case "status":
+		statuses, err := db.MigrationStatuses(ctx)
+		if err != nil {
+			log.Fatalf("migrate status: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "MIGRATION\tAPPLIED\tREVERSIBLE")
+		pending := 0
+		for _, st := range statuses {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format(time.RFC3339)
+			} else {
+				pending++
 			}
-			ct := mime.TypeByExtension(filepath.Ext(rawPath))
-			if ct == "" {
-				ct = http.DetectContentType(data)
+			fmt.Fprintf(w, "%s\t%s\t%t\n", st.Name, applied, st.Reversible)
+		}
+		w.Flush()
+		fmt.Printf("%d of %d applied, %d pending.\n", len(statuses)-pending, len(statuses), pending)
+		return
+	case "up":
+		plan, err = db.PlanUp(ctx, *steps)
+	case "down":
+		plan, err = db.PlanDown(ctx, max(*steps, 1))
+	case "redo":
+		if plan, err = db.PlanDown(ctx, 1); err == nil && len(plan) == 1 {
+			plan = append(plan, database.MigrationStep{Name: plan[0].Name, SQL: db.MigrationSQL(plan[0].Name)})
+		}
+	default:
+		log.Fatalf("migrate: unknown action %q; want status, up, down, or redo", action)
+	}
+	if err != nil {
+		log.Fatalf("migrate %s: %v", action, err)
+	}
+	if len(plan) == 0 {
+		fmt.Println("Nothing to do; the database is up to date.")
+		return
+	}
+	if *dryRun {
+		for _, step := range plan {
+			direction := "up"
+			if step.Down {
+				direction = "down"
 			}
-			return c.Blob(http.StatusOK, ct, data)
-		})
+			fmt.Printf("-- %s (%s)\n%s\n\n", step.Name, direction, step.SQL)
+		}
+		fmt.Println("Dry run; nothing was changed.")
+		return
 	}
+	if err := db.ApplyMigrations(ctx, plan); err != nil {
+		log.Fatalf("migrate %s: %v", action, err)
+	}
+}
 
-	log.Printf("PolicyFlow listening on :%s", port)
-	e.Logger.Fatal(e.Start(":" + port))
+// consolidateDepartments prints how legacy policies.department values map to
+// departments and, with -apply, moves the policies onto them.
+func consolidateDepartments(ctx context.Context, db *database.DB, args []string) {
+	flags := flag.NewFlagSet("consolidate-departments", flag.ExitOnError)
+	apply := flags.Bool("apply", false, "map the values, creating missing departments")
+	acceptFuzzy := flags.Bool("accept-fuzzy", false, "also apply fuzzy matches (with -apply)")
+	flags.Parse(args)
+
+	var report []*database.LegacyDepartment
+	var err error
+	if *apply {
+		report, err = db.ConsolidateLegacyDepartments(ctx, database.ConsolidateOptions{AcceptFuzzy: *acceptFuzzy})
+	} else {
+		report, err = db.LegacyDepartments(ctx)
+	}
+	if err != nil {
+		log.Fatalf("consolidate departments: %v", err)
+	}
+	if len(report) == 0 {
+		fmt.Println("No policies use the legacy department field.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VALUE\tPOLICIES\tMATCH\tDEPARTMENT\tSIMILARITY\tACTION")
+	for _, l := range report {
+		fmt.Fprintf(w, "%q\t%d\t%s\t%s\t%.2f\t%s\n", l.Value, l.Policies, l.Match, deref(l.DepartmentName), l.Similarity, l.Action)
+	}
+	w.Flush()
+	if !*apply {
+		fmt.Println("Dry run; rerun with -apply to map these values (unmatched ones become new departments).")
+	}
+}
+
+// restoreReplica rebuilds the database from the WAL replica, optionally as
+// it was at -timestamp, into -o (default DB_PATH, which must not exist).
+func restoreReplica(ctx context.Context, store storage.Store, dbPath string, args []string) {
+	flags := flag.NewFlagSet("restore-replica", flag.ExitOnError)
+	out := flags.String("o", dbPath, "file to restore into")
+	timestamp := flags.String("timestamp", "", "restore the state as of this RFC 3339 time (default latest)")
+	flags.Parse(args)
+
+	var at time.Time
+	if *timestamp != "" {
+		t, err := time.Parse(time.RFC3339, *timestamp)
+		if err != nil {
+			log.Fatalf("invalid -timestamp: %v", err)
+		}
+		at = t
+	}
+	if _, err := os.Stat(*out); err == nil {
+		log.Fatalf("%s already exists; move it aside or pass -o", *out)
+	}
+	m, err := replica.Restore(ctx, store, *out, at)
+	if err != nil {
+		log.Fatalf("restore replica: %v", err)
+	}
+	last := m.StartedAt
+	if n := len(m.Segments); n > 0 {
+		last = m.Segments[n-1].CreatedAt
+	}
+	fmt.Printf("Restored %s from generation %s as of %s.\n", *out, m.ID, last.Format(time.RFC3339))
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
 }
 
 func getEnv(key, fallback string) string {
@@ -17,10 +17,19 @@ import {
   Globe,
 } from "lucide-react";
 import { Nav } from "@/components/nav";
-import { isAuthenticated, getTokenPayload, isSuperAdmin } from "@/lib/auth";
+import {
+  isAuthenticated,
+  getTokenPayload,
+  isSuperAdmin,
+  loginURL,
+  getActingDepartment,
+  setActingDepartment,
+} from "@/lib/auth";
 import {
   getAdminStats,
+  subscribeEvents,
   getMe,
+  getMyDepartments,
   listUsers,
   listPolicies,
   listDepartments,
@@ -33,6 +42,7 @@ import {
   createPolicy,
   updatePolicy,
   createPolicyVersion,
+  ApiError,
   type AdminStats,
   type User,
   type Policy,
@@ -40,6 +50,7 @@ import {
   type Department,
   type VisibilityType,
   type UserRole,
+  type AdminDepartment,
 } from "@/lib/api";
 
 // CodeMirror must be loaded client-side only (no SSR).
@@ -541,7 +552,7 @@ function UpdateStatusModal({
     e.preventDefault();
     setLoading(true);
     try {
-      await updatePolicy(policy.id, { status });
+      await updatePolicy(policy.id, { status, expected_version: policy.version });
       onUpdated();
       onClose();
     } finally {
@@ -655,6 +666,8 @@ export default function AdminPage() {
   const [users, setUsers] = useState<User[]>([]);
   const [policies, setPolicies] = useState<Policy[]>([]);
   const [departments, setDepartments] = useState<Department[]>([]);
+  const [myDepts, setMyDepts] = useState<AdminDepartment[]>([]);
+  const [actingDept, setActingDept] = useState<string | null>(getActingDepartment());
   const [loading, setLoading] = useState(true);
   const [tab, setTab] = useState<TabType>("overview");
   const [modal, setModal] = useState<ModalState>({ type: "none" });
@@ -665,7 +678,7 @@ export default function AdminPage() {
 
   useEffect(() => {
     if (!isAuthenticated()) {
-      router.replace("/");
+      router.replace(loginURL());
       return;
     }
     const payload = getTokenPayload();
@@ -677,15 +690,17 @@ export default function AdminPage() {
   const loadData = useCallback(async () => {
     setLoading(true);
     try {
-      const [s, me, u, p, d] = await Promise.all([
+      const [s, me, u, p, d, mine] = await Promise.all([
         getAdminStats(),
         getMe(),
         listUsers(),
         listPolicies(),
         listDepartments(),
+        getMyDepartments(),
       ]);
       setStats(s);
       setCurrentUser(me);
+      setMyDepts(mine);
       setUsers(u);
       setPolicies(p);
       setDepartments(d);
@@ -698,10 +713,26 @@ export default function AdminPage() {
 
   useEffect(() => {
     loadData();
-  }, [loadData]);
+  }, [loadData, actingDept]);
+
+  // Admins granted several departments work in one at a time.
+  function switchDepartment(id: string) {
+    const home = myDepts.find((d) => d.home)?.id;
+    setActingDepartment(id === home ? null : id);
+    setActingDept(id === home ? null : id);
+  }
+
+  // Refresh the dashboard live while a compliance push is under way.
+  useEffect(() => {
+    return subscribeEvents((e) => {
+      if (e.type === "policy.published" || e.type === "policy.acknowledged") {
+        getAdminStats().then(setStats).catch(() => {});
+      }
+    });
+  }, []);
 
   async function handleDeleteUser(user: User) {
-    if (!confirm(`Delete user "${user.name}"? This cannot be undone.`)) return;
+    if (!confirm(`Remove user "${user.name}"? Their name and email will be anonymized; acknowledgement records are kept. This cannot be undone.`)) return;
     setDeleteError("");
     try {
       await deleteUser(user.id);
@@ -711,6 +742,27 @@ export default function AdminPage() {
     }
   }
 
+  // A department still in use can be deleted once its policies and users
+  // have somewhere to go.
+  async function reassignAndDeleteDept(dept: Department, reason: string) {
+    const others = departments.filter((d) => d.id !== dept.id);
+    const name = prompt(
+      `${reason}. Move its policies and users to which department?\n${others.map((d) => d.name).join(", ")}`
+    );
+    if (!name) return;
+    const target = others.find((d) => d.name === name.trim());
+    if (!target) {
+      setDeleteError(`No department named "${name}"`);
+      return;
+    }
+    try {
+      await deleteDepartment(dept.id, target.id);
+      await loadData();
+    } catch (err: unknown) {
+      setDeleteError(err instanceof Error ? err.message : "Error deleting department");
+    }
+  }
+
   async function handleDeleteDept(dept: Department) {
     if (!confirm(`Delete department "${dept.name}"?`)) return;
     setDeleteError("");
@@ -718,6 +770,10 @@ export default function AdminPage() {
       await deleteDepartment(dept.id);
       await loadData();
     } catch (err: unknown) {
+      if (err instanceof ApiError && err.code === "DEPARTMENT_IN_USE") {
+        await reassignAndDeleteDept(dept, err.message);
+        return;
+      }
       setDeleteError(err instanceof Error ? err.message : "Error: " + (err instanceof Error ? err.message : "unknown error"));
     }
   }
@@ -739,6 +795,20 @@ export default function AdminPage() {
             <h1 className="text-2xl font-bold text-slate-900 dark:text-white">Admin Dashboard</h1>
             <p className="text-slate-500 dark:text-slate-400 text-sm mt-1">Manage users and policies</p>
           </div>
+          {myDepts.length > 1 && (
+            <select
+              value={actingDept ?? myDepts.find((d) => d.home)?.id ?? ""}
+              onChange={(e) => switchDepartment(e.target.value)}
+              aria-label="Department"
+              className="ml-auto mr-3 px-3 py-1.5 text-sm border border-slate-300 dark:border-slate-600 rounded-lg bg-white dark:bg-slate-800 text-slate-700 dark:text-slate-200"
+            >
+              {myDepts.map((d) => (
+                <option key={d.id} value={d.id}>
+                  {d.name}
+                </option>
+              ))}
+            </select>
+          )}
           <button
             onClick={loadData}
             className="flex items-center gap-1.5 text-sm text-slate-500 hover:text-slate-900 dark:hover:text-white px-3 py-1.5 border border-slate-300 dark:border-slate-600 rounded-lg hover:bg-white dark:hover:bg-slate-700 transition-colors"
@@ -878,6 +948,7 @@ export default function AdminPage() {
                         <th className="text-left px-5 py-3 text-xs font-semibold text-slate-500 uppercase tracking-wider">Role</th>
                         <th className="text-left px-5 py-3 text-xs font-semibold text-slate-500 uppercase tracking-wider">Department</th>
                         <th className="text-left px-5 py-3 text-xs font-semibold text-slate-500 uppercase tracking-wider">Joined</th>
+                        <th className="text-left px-5 py-3 text-xs font-semibold text-slate-500 uppercase tracking-wider">Last login</th>
                         <th className="text-left px-5 py-3 text-xs font-semibold text-slate-500 uppercase tracking-wider">Actions</th>
                       </tr>
                     </thead>
@@ -893,6 +964,7 @@ export default function AdminPage() {
                           </td>
                           <td className="px-5 py-3 text-slate-500">{u.department_name ?? "—"}</td>
                           <td className="px-5 py-3 text-slate-500">{formatDate(u.created_at)}</td>
+                          <td className="px-5 py-3 text-slate-500">{u.last_login_at ? formatDate(u.last_login_at) : "Never"}</td>
                           <td className="px-5 py-3">
                             {superAdmin && (
                               <div className="flex gap-2">
@@ -3,7 +3,7 @@
 import { Suspense, useEffect } from "react";
 import { useRouter, useSearchParams } from "next/navigation";
 import { Loader2, Shield } from "lucide-react";
-import { setToken } from "@/lib/auth";
+import { setToken, safeRedirect } from "@/lib/auth";
 
 // Inner component uses useSearchParams — must be wrapped in Suspense for static export.
 function TokenHandler() {
@@ -14,7 +14,7 @@ function TokenHandler() {
     const token = searchParams.get("token");
     if (token) {
       setToken(token);
-      router.replace("/policies");
+      router.replace(safeRedirect(searchParams.get("redirect")) ?? "/policies");
     } else {
       router.replace("/");
     }
@@ -24,7 +24,7 @@ function TokenHandler() {
 }
 
 // This page handles the redirect from GET /api/magic-login?token=...
-// The Go server redirects to /auth-callback?token=<session-jwt>
+// The Go server redirects to /auth-callback?token=<session-jwt>[&redirect=<path>]
 export default function AuthCallbackPage() {
   return (
     <div className="min-h-screen flex items-center justify-center bg-slate-50 dark:bg-slate-900">
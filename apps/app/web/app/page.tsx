@@ -2,19 +2,26 @@
 
 import { useState, useEffect } from "react";
 import { useRouter } from "next/navigation";
-import { Shield, Mail, ArrowRight, Loader2 } from "lucide-react";
-import { isAuthenticated } from "@/lib/auth";
-import { requestMagicLink } from "@/lib/api";
+import { Shield, Mail, ArrowRight, Loader2, KeyRound } from "lucide-react";
+import { isAuthenticated, setToken, safeRedirect } from "@/lib/auth";
+import { requestMagicLink, requestLoginCode, verifyLoginCode } from "@/lib/api";
+
+// The page to return to after sign-in, when sent here from one.
+function redirectTarget() {
+  return safeRedirect(new URLSearchParams(window.location.search).get("redirect"));
+}
 
 export default function LoginPage() {
   const router = useRouter();
   const [email, setEmail] = useState("");
+  const [mode, setMode] = useState<"link" | "code">("link");
+  const [code, setCode] = useState("");
   const [status, setStatus] = useState<"idle" | "loading" | "sent" | "error">("idle");
   const [errorMsg, setErrorMsg] = useState("");
 
   useEffect(() => {
     if (isAuthenticated()) {
-      router.replace("/policies");
+      router.replace(redirectTarget() ?? "/policies");
     }
   }, [router]);
 
@@ -24,7 +31,7 @@ export default function LoginPage() {
     setStatus("loading");
     setErrorMsg("");
     try {
-      await requestMagicLink(email);
+      await (mode === "code" ? requestLoginCode(email) : requestMagicLink(email, redirectTarget()));
       setStatus("sent");
     } catch (err: unknown) {
       setStatus("error");
@@ -32,6 +39,19 @@ export default function LoginPage() {
     }
   }
 
+  async function handleVerify(e: React.FormEvent) {
+    e.preventDefault();
+    if (!code) return;
+    setErrorMsg("");
+    try {
+      const { token } = await verifyLoginCode(email, code);
+      setToken(token);
+      router.replace(redirectTarget() ?? "/policies");
+    } catch (err: unknown) {
+      setErrorMsg(err instanceof Error ? err.message : "Something went wrong");
+    }
+  }
+
   return (
     <div className="min-h-screen flex items-center justify-center bg-linear-to-br from-slate-50 to-blue-50 dark:from-slate-900 dark:to-slate-800 p-4">
       <div className="w-full max-w-md">
@@ -48,7 +68,49 @@ export default function LoginPage() {
 
         {/* Card */}
         <div className="bg-white dark:bg-slate-800 rounded-2xl shadow-xl border border-slate-200 dark:border-slate-700 p-8">
-          {status === "sent" ? (
+          {status === "sent" && mode === "code" ? (
+            <form onSubmit={handleVerify} className="space-y-4">
+              <div className="text-center">
+                <div className="inline-flex items-center justify-center w-14 h-14 rounded-full bg-green-100 dark:bg-green-900/30 mb-4">
+                  <KeyRound className="w-7 h-7 text-green-600 dark:text-green-400" />
+                </div>
+                <h2 className="text-xl font-semibold text-slate-900 dark:text-white mb-2">
+                  Enter your code
+                </h2>
+                <p className="text-slate-500 dark:text-slate-400 text-sm">
+                  We sent a six-digit code to <strong>{email}</strong>. It expires in 10 minutes.
+                </p>
+              </div>
+              <input
+                type="text"
+                inputMode="numeric"
+                autoComplete="one-time-code"
+                pattern="[0-9 ]*"
+                maxLength={7}
+                required
+                autoFocus
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                placeholder="123456"
+                className="w-full px-3 py-2.5 rounded-lg border border-slate-300 dark:border-slate-600 bg-white dark:bg-slate-700 text-slate-900 dark:text-white text-center text-2xl tracking-[0.5em] font-mono focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+              />
+              {errorMsg && <p className="text-sm text-red-600 dark:text-red-400">{errorMsg}</p>}
+              <button
+                type="submit"
+                className="w-full flex items-center justify-center gap-2 py-2.5 px-4 bg-blue-600 hover:bg-blue-700 text-white rounded-lg font-medium text-sm transition-colors"
+              >
+                Sign in
+                <ArrowRight className="h-4 w-4" />
+              </button>
+              <button
+                type="button"
+                onClick={() => { setStatus("idle"); setCode(""); setErrorMsg(""); }}
+                className="w-full text-sm text-blue-600 hover:underline dark:text-blue-400"
+              >
+                Send a new code
+              </button>
+            </form>
+          ) : status === "sent" ? (
             <div className="text-center py-4">
               <div className="inline-flex items-center justify-center w-14 h-14 rounded-full bg-green-100 dark:bg-green-900/30 mb-4">
                 <Mail className="w-7 h-7 text-green-600 dark:text-green-400" />
@@ -108,11 +170,18 @@ export default function LoginPage() {
                     <Loader2 className="h-4 w-4 animate-spin" />
                   ) : (
                     <>
-                      Send login link
+                      {mode === "code" ? "Send login code" : "Send login link"}
                       <ArrowRight className="h-4 w-4" />
                     </>
                   )}
                 </button>
+                <button
+                  type="button"
+                  onClick={() => setMode(mode === "code" ? "link" : "code")}
+                  className="w-full text-sm text-blue-600 hover:underline dark:text-blue-400"
+                >
+                  {mode === "code" ? "Email me a link instead" : "Links not working? Email me a code instead"}
+                </button>
               </form>
             </>
           )}
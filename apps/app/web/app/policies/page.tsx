@@ -18,7 +18,7 @@ import {
   Building2,
 } from "lucide-react";
 import { Nav } from "@/components/nav";
-import { isAuthenticated } from "@/lib/auth";
+import { isAuthenticated, loginURL } from "@/lib/auth";
 import {
   listPolicies,
   listDepartments,
@@ -27,6 +27,7 @@ import {
   acknowledgePolicy,
   type Policy,
   type PolicyDetail,
+  type RelatedPolicy,
   type PolicyVersion,
   type Department,
 } from "@/lib/api";
@@ -226,12 +227,20 @@ function PolicyList({ onSelect }: { onSelect: (id: string) => void }) {
 
 // ─── Policy Detail View ────────────────────────────────────────────────────
 
+const relationLabels: Record<RelatedPolicy["relation"], string> = {
+  supersedes: "Supersedes",
+  superseded_by: "Superseded by",
+  relates_to: "Related",
+};
+
 function PolicyDetailView({
   policyId,
   onBack,
+  onSelect,
 }: {
   policyId: string;
   onBack: () => void;
+  onSelect: (id: string) => void;
 }) {
   const [detail, setDetail] = useState<PolicyDetail | null>(null);
   const [versions, setVersions] = useState<PolicyVersion[]>([]);
@@ -347,6 +356,27 @@ function PolicyDetailView({
         </div>
       )}
 
+      {/* Related policies */}
+      {detail.related.length > 0 && (
+        <div className="bg-white dark:bg-slate-800 rounded-xl border border-slate-200 dark:border-slate-700 mb-4">
+          <h2 className="px-5 pt-4 pb-2 text-sm font-medium text-slate-700 dark:text-slate-200">
+            Related policies
+          </h2>
+          <div className="divide-y divide-slate-100 dark:divide-slate-700">
+            {detail.related.map((r) => (
+              <button
+                key={r.relation_id}
+                onClick={() => onSelect(r.id)}
+                className="w-full px-5 py-3 flex items-center justify-between text-left hover:bg-slate-50 dark:hover:bg-slate-750 transition-colors"
+              >
+                <span className="text-sm text-blue-600 dark:text-blue-400">{r.title}</span>
+                <span className="text-xs text-slate-400">{relationLabels[r.relation]}</span>
+              </button>
+            ))}
+          </div>
+        </div>
+      )}
+
       {/* Version history */}
       {versions.length > 0 && (
         <div className="bg-white dark:bg-slate-800 rounded-xl border border-slate-200 dark:border-slate-700 overflow-hidden">
@@ -393,7 +423,7 @@ function PoliciesInner() {
 
   useEffect(() => {
     if (!isAuthenticated()) {
-      router.replace("/");
+      router.replace(loginURL());
     }
   }, [router]);
 
@@ -408,7 +438,7 @@ function PoliciesInner() {
   return (
     <main className="max-w-4xl mx-auto px-4 sm:px-6 lg:px-8 py-8">
       {selectedId ? (
-        <PolicyDetailView policyId={selectedId} onBack={handleBack} />
+        <PolicyDetailView policyId={selectedId} onBack={handleBack} onSelect={handleSelect} />
       ) : (
         <>
           <div className="mb-6">
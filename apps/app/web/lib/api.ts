@@ -1,25 +1,55 @@
-import { getToken } from "./auth";
+import { getToken, setToken, getActingDepartment } from "./auth";
 
 const API_BASE = process.env.NEXT_PUBLIC_API_URL ?? "";
 
+// ApiError is a failed API call. code is stable and safe to branch on (for
+// example "POLICY_NOT_PUBLISHED"); message is for people. fieldErrors maps
+// request fields to what was wrong with them.
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly code: string,
+    message: string,
+    readonly fieldErrors: Record<string, string> = {},
+    readonly requestId?: string
+  ) {
+    super(message);
+    this.name = "ApiError";
+  }
+}
+
+async function apiError(res: Response) {
+  const body = await res.json().catch(() => ({}));
+  return new ApiError(
+    res.status,
+    body.code ?? `HTTP_${res.status}`,
+    body.message ?? res.statusText ?? `HTTP ${res.status}`,
+    body.field_errors ?? {},
+    body.request_id ?? res.headers.get("X-Request-Id") ?? undefined
+  );
+}
+
 async function request<T>(
   path: string,
   options: RequestInit = {}
 ): Promise<T> {
   const token = getToken();
+  const dept = getActingDepartment();
   const res = await fetch(`${API_BASE}${path}`, {
     ...options,
     headers: {
       "Content-Type": "application/json",
       ...(token ? { Authorization: `Bearer ${token}` } : {}),
+      ...(dept ? { "X-Department-Id": dept } : {}),
       ...options.headers,
     },
   });
 
-  if (!res.ok) {
-    const err = await res.json().catch(() => ({ message: res.statusText }));
-    throw new Error(err.message ?? `HTTP ${res.status}`);
-  }
+  // Sliding sessions: the server reissues the token while the user is active.
+  const refreshed = res.headers.get("X-Session-Token");
+  if (refreshed) setToken(refreshed);
+
+  if (!res.ok) throw await apiError(res);
 
   if (res.status === 204) return undefined as T;
   return res.json();
@@ -27,15 +57,165 @@ async function request<T>(
 
 // ─── Auth ──────────────────────────────────────────────────────────────────
 
-export function requestMagicLink(email: string) {
+/** `redirect` is the page to open after sign-in, e.g. "/policies?id=…". */
+export function requestMagicLink(email: string, redirect?: string | null) {
   return request<{ message: string }>("/api/magic-link", {
+    method: "POST",
+    body: JSON.stringify({ email, redirect: redirect ?? undefined }),
+  });
+}
+
+export function requestLoginCode(email: string) {
+  return request<{ message: string }>("/api/login-code", {
     method: "POST",
     body: JSON.stringify({ email }),
   });
 }
 
+/** Exchanges an emailed six-digit code for a session token. */
+export function verifyLoginCode(email: string, code: string) {
+  return request<{ token: string }>("/api/login-code/verify", {
+    method: "POST",
+    body: JSON.stringify({ email, code }),
+  });
+}
+
+/** Signs in as a demo account. Only available when features.demo_mode is on. */
+export function demoLogin(role: "SuperAdmin" | "DeptAdmin" | "Staff") {
+  return request<{ token: string }>("/api/demo/login", {
+    method: "POST",
+    body: JSON.stringify({ role }),
+  });
+}
+
 export function getMe() {
-  return request<User>("/api/me");
+  return request<User & { impersonated_by?: string }>("/api/me");
+}
+
+export function impersonateUser(id: string) {
+  return request<{ token: string; expires_at: string; user: User }>(
+    `/api/admin/impersonate/${id}`,
+    { method: "POST" }
+  );
+}
+
+export interface LoginEvent {
+  id: string;
+  user_id: string;
+  event: "link_issued" | "code_issued" | "code_failed" | "login";
+  ip_address: string;
+  user_agent: string;
+  created_at: string;
+}
+
+export interface AdminDepartment {
+  id: string;
+  name: string;
+  /** The user's own department rather than a grant. */
+  home: boolean;
+}
+
+/** Departments the caller can act for; more than one means they hold grants. */
+export function getMyDepartments() {
+  return request<AdminDepartment[]>("/api/me/departments");
+}
+
+export function getMyLogins() {
+  return request<LoginEvent[]>("/api/me/logins");
+}
+
+// ─── Runtime config ────────────────────────────────────────────────────────
+
+export interface RuntimeConfig {
+  org_name: string;
+  support_email: string; // "" = none
+  has_logo: boolean;
+  sso_enabled: boolean;
+  max_upload_bytes: number;
+  acknowledgement: { min_read_seconds: number; require_scroll: boolean };
+  features: {
+    public_portal: boolean;
+    deadline_reminders: boolean;
+    publish_notifications: boolean;
+    training_webhook: boolean;
+    demo_mode: boolean;
+  };
+}
+
+export function getConfig() {
+  return request<RuntimeConfig>("/api/config");
+}
+
+// ─── Maintenance ───────────────────────────────────────────────────────────
+
+export interface ServerStatus {
+  maintenance: boolean;
+  message: string;
+  forced: boolean;
+}
+
+export function getStatus() {
+  return request<ServerStatus>("/api/status");
+}
+
+export function setMaintenance(enabled: boolean, message = "") {
+  return request<ServerStatus>("/api/admin/maintenance", {
+    method: "PUT",
+    body: JSON.stringify({ enabled, message }),
+  });
+}
+
+export type SelfCheckStatus = "ok" | "skipped" | "warning" | "failed";
+
+export interface SelfCheckReport {
+  status: SelfCheckStatus;
+  checked_at: string;
+  checks: { name: string; status: SelfCheckStatus; detail?: string; duration_ms: number }[];
+}
+
+// runSelfCheck checks the configuration, database, migrations, SMTP server,
+// and blob storage (SuperAdmin).
+export function runSelfCheck() {
+  return request<SelfCheckReport>("/api/admin/selfcheck");
+}
+
+// ─── Real-time events ──────────────────────────────────────────────────────
+
+export interface ServerEvent {
+  id: number;
+  type: "policy.published" | "policy.acknowledged" | "notification";
+  data: Record<string, string>;
+  at: string;
+}
+
+// subscribeEvents opens the /api/events stream and returns a function that
+// closes it. EventSource reconnects on its own after network errors.
+export function subscribeEvents(onEvent: (e: ServerEvent) => void) {
+  const token = getToken();
+  const source = new EventSource(`${API_BASE}/api/events?token=${token ?? ""}`);
+  const handler = (msg: MessageEvent) => onEvent(JSON.parse(msg.data));
+  for (const type of ["policy.published", "policy.acknowledged", "notification"]) {
+    source.addEventListener(type, handler);
+  }
+  return () => source.close();
+}
+
+export interface DashboardCounters {
+  acks_last_minute: number;
+  active_sessions: number;
+  at: string;
+}
+
+// openDashboardSocket connects to the /api/ws dashboard channel and returns a
+// function that closes it. Unlike EventSource, it does not reconnect itself.
+export function openDashboardSocket(onCounters: (c: DashboardCounters) => void, onClose?: () => void) {
+  const base = API_BASE || window.location.origin;
+  const url = new URL(`/api/ws?token=${getToken() ?? ""}`, base);
+  url.protocol = url.protocol === "https:" ? "wss:" : "ws:";
+  const socket = new WebSocket(url);
+  socket.onmessage = (msg) => onCounters(JSON.parse(msg.data));
+  if (onClose) socket.onclose = onClose;
+  return () => socket.close();
 }
 
 // ─── Departments ───────────────────────────────────────────────────────────
@@ -69,8 +249,37 @@ export function updateDepartment(
   });
 }
 
-export function deleteDepartment(id: string) {
-  return request<void>(`/api/departments/${id}`, { method: "DELETE" });
+// deleteDepartment removes a department. With reassignTo, its policies,
+// users, assignments, and report schedules move there first; otherwise a
+// department still in use is refused with DEPARTMENT_IN_USE.
+export function deleteDepartment(id: string, reassignTo?: string) {
+  return request<{ policies: number; users: number } | void>(
+    `/api/departments/${id}${pageQuery({ reassign_to: reassignTo })}`,
+    { method: "DELETE" }
+  );
+}
+
+export interface LegacyDepartment {
+  value: string;
+  policies: number;
+  match: "exact" | "fuzzy" | "none";
+  department_id: string | null;
+  department_name: string | null;
+  similarity: number;
+  action?: "mapped" | "created" | "skipped";
+}
+
+export function getLegacyDepartments() {
+  return request<LegacyDepartment[]>("/api/admin/departments/legacy");
+}
+
+export function consolidateLegacyDepartments(
+  data: { accept_fuzzy?: boolean; overrides?: Record<string, string> } = {}
+) {
+  return request<LegacyDepartment[]>("/api/admin/departments/legacy/consolidate", {
+    method: "POST",
+    body: JSON.stringify(data),
+  });
 }
 
 // ─── Policies ─────────────────────────────────────────────────────────────
@@ -83,12 +292,27 @@ export interface Policy {
   title: string;
   current_version_id?: string;
   status: PolicyStatus;
+  /** @deprecated Legacy free-text department; use department_id. */
   department: string;
   department_id: string | null;
   department_name: string | null;
   visibility_type: VisibilityType;
+  public: boolean;
+  version: number;
+  created_by: string | null;
+  created_by_name: string | null;
+  updated_by: string | null;
+  updated_by_name: string | null;
   created_at: string;
+  updated_at: string;
+  /** Custom field values by field key; fields without a value are absent. */
+  custom_fields: Record<string, string>;
   acknowledged?: boolean;
+  // Admins only: active users the policy is required for, and how many have
+  // acknowledged the current version.
+  ack_count?: number;
+  eligible_user_count?: number;
+  compliance_pct?: number;
 }
 
 export interface PolicyVersion {
@@ -97,17 +321,107 @@ export interface PolicyVersion {
   content: string;
   version_string: string;
   changelog: string;
+  // The window in which the version is in force and can be acknowledged;
+  // null ends are open.
+  effective_from: string | null;
+  effective_to: string | null;
+  created_by: string | null;
+  created_by_name: string | null;
   created_at: string;
+  source?: VersionSource;
+}
+
+// VersionSource is the uploaded original (a PDF) a version was made from.
+export interface VersionSource {
+  filename: string;
+  content_type: string;
+  size_bytes: number;
+  sha256: string;
+}
+
+export type RelationType = "supersedes" | "relates_to";
+
+export interface RelatedPolicy extends Policy {
+  relation_id: string;
+  relation: RelationType | "superseded_by";
 }
 
 export interface PolicyDetail {
   policy: Policy;
   current_version: PolicyVersion | null;
   acknowledged: boolean;
+  related: RelatedPolicy[];
+  lock: PolicyLock | null;
+}
+
+export interface PolicyLock {
+  policy_id: string;
+  user_id: string;
+  user_name: string;
+  acquired_at: string;
+  expires_at: string;
+}
+
+// lockPolicy takes or renews the edit lock; call it about every 30 seconds
+// while editing. It rejects with "<name> is editing this draft" when taken.
+export function lockPolicy(id: string) {
+  return request<PolicyLock>(`/api/policies/${id}/lock`, { method: "PUT" });
+}
+
+export function unlockPolicy(id: string) {
+  return request<void>(`/api/policies/${id}/lock`, { method: "DELETE" });
+}
+
+export function listPolicies(acknowledged?: "true" | "false" | "overdue") {
+  const query = acknowledged ? `?acknowledged=${acknowledged}` : "";
+  return request<(Policy & { acknowledged: boolean })[]>(`/api/policies${query}`);
+}
+
+// previewPolicies lists the policies a role and department would see, for
+// checking visibility before publishing. SuperAdmin only.
+export function previewPolicies(asRole: UserRole, asDepartment?: string) {
+  const params = new URLSearchParams({ as_role: asRole });
+  if (asDepartment) params.set("as_department", asDepartment);
+  return request<(Policy & { acknowledged: boolean })[]>(`/api/policies?${params}`);
+}
+
+// PolicySummary is a list item trimmed by listPolicySummaries: the policy's
+// id plus whichever fields and sections were asked for.
+export type PolicySummary = Partial<Policy & { acknowledged: boolean }> & {
+  id: string;
+  current_version?: Partial<PolicyVersion> | null;
+  acknowledgement?: Acknowledgement | null;
+};
+
+// listPolicySummaries lists policies with only the named fields, e.g.
+// ["title", "status", "current_version.version_string"]. Included versions
+// leave out their content unless "current_version.content" is asked for.
+export function listPolicySummaries(params: {
+  fields?: string[];
+  include?: ("current_version" | "acknowledgement")[];
+  acknowledged?: "true" | "false" | "overdue";
+}) {
+  return request<PolicySummary[]>(
+    `/api/policies${pageQuery({
+      fields: params.fields?.join(","),
+      include: params.include?.join(","),
+      acknowledged: params.acknowledged,
+    })}`
+  );
 }
 
-export function listPolicies() {
-  return request<(Policy & { acknowledged: boolean })[]>("/api/policies");
+// getPoliciesByIds fetches several policies at once, leaving out any the
+// caller cannot see. Long lists go in the body rather than the URL.
+export function getPoliciesByIds(ids: string[]) {
+  if (ids.length <= 100) {
+    return request<(Policy & { acknowledged: boolean })[]>(
+      `/api/policies?ids=${ids.map(encodeURIComponent).join(",")}`
+    );
+  }
+  return request<(Policy & { acknowledged: boolean })[]>("/api/policies/batch", {
+    method: "POST",
+    body: JSON.stringify({ ids }),
+  });
 }
 
 export function getPolicy(id: string) {
@@ -118,12 +432,104 @@ export function getPolicyVersions(id: string) {
   return request<PolicyVersion[]>(`/api/policies/${id}/versions`);
 }
 
+// printPolicy fetches the print view as HTML, ready to open in a new window.
+export async function printPolicy(id: string, versionId?: string) {
+  const token = getToken();
+  const query = versionId ? `?version_id=${versionId}` : "";
+  const res = await fetch(`${API_BASE}/api/policies/${id}/print${query}`, {
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+  });
+  if (!res.ok) throw new Error(`HTTP ${res.status}`);
+  return res.text();
+}
+
 export function acknowledgePolicy(id: string) {
   return request<{ id: string }>(`/api/policies/${id}/acknowledge`, {
     method: "POST",
   });
 }
 
+// snoozeReminders pauses deadline reminders about a pending policy for
+// 1-30 days; unsnoozeReminders resumes them.
+export function snoozeReminders(id: string, days: number) {
+  return request<{ snoozed_until: string }>(`/api/policies/${id}/snooze`, {
+    method: "POST",
+    body: JSON.stringify({ days }),
+  });
+}
+
+export function unsnoozeReminders(id: string) {
+  return request<void>(`/api/policies/${id}/snooze`, { method: "DELETE" });
+}
+
+export interface Acknowledgement {
+  id: string;
+  user_id: string;
+  policy_version_id: string;
+  timestamp: string;
+  signature_hash: string;
+  source: "app" | "imported";
+}
+
+export interface AckDetail extends Acknowledgement {
+  user_name: string;
+  user_email: string;
+  department_id: string | null;
+  department_name: string | null;
+}
+
+export interface AckPage<T = Acknowledgement> {
+  items: T[];
+  next_cursor: string | null;
+}
+
+type AckPageParams = { cursor?: string; limit?: number };
+
+function pageQuery(params: Record<string, string | number | undefined>) {
+  const query = new URLSearchParams(
+    Object.entries(params).filter(([, v]) => v !== undefined).map(([k, v]) => [k, String(v)])
+  ).toString();
+  return query ? `?${query}` : "";
+}
+
+export interface VersionAckStats {
+  version_id: string;
+  version_string: string;
+  current: boolean;
+  published_at: string | null;
+  superseded_at: string | null;
+  ack_count: number;
+  /** Live for the current version, frozen when superseded; null if never recorded. */
+  eligible_user_count: number | null;
+  compliance_pct: number | null;
+  by_month: { month: string; acknowledgements: number }[];
+}
+
+export function getVersionAckStats(policyId: string) {
+  return request<VersionAckStats[]>(`/api/policies/${policyId}/versions/stats`);
+}
+
+// listPolicyAcknowledgements pages through who acknowledged a version (the
+// current version by default); pass next_cursor back as cursor.
+export function listPolicyAcknowledgements(id: string, params: AckPageParams & { version_id?: string } = {}) {
+  return request<AckPage<AckDetail>>(`/api/policies/${id}/acknowledgements${pageQuery(params)}`);
+}
+
+// downloadPolicyAcknowledgements fetches every acknowledgement of a version
+// (the current version by default) as one CSV file.
+export async function downloadPolicyAcknowledgements(id: string, versionId?: string) {
+  const token = getToken();
+  const res = await fetch(`${API_BASE}/api/policies/${id}/acknowledgements${pageQuery({ version_id: versionId, format: "csv" })}`, {
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+  });
+  if (!res.ok) throw new Error(`HTTP ${res.status}`);
+  return res.blob();
+}
+
+export function listUserAcknowledgements(userId: string, params: AckPageParams = {}) {
+  return request<AckPage>(`/api/admin/users/${userId}/acknowledgements${pageQuery(params)}`);
+}
+
 export function createPolicy(data: {
   title: string;
   department?: string;
@@ -144,6 +550,8 @@ export function updatePolicy(
     department?: string;
     department_id?: string | null;
     visibility_type?: VisibilityType;
+    public?: boolean;
+    expected_version: number;
   }
 ) {
   return request<Policy>(`/api/policies/${id}`, {
@@ -152,9 +560,32 @@ export function updatePolicy(
   });
 }
 
+export type BulkStatusResult = {
+  id: string;
+  ok: boolean;
+  policy?: Policy;
+  error?: { code: string; message: string };
+};
+
+// bulkUpdatePolicyStatus moves several policies to one status. Policies that
+// cannot be changed are reported in results rather than failing the call.
+// With dryRun nothing is changed and results show the would-be policies.
+export function bulkUpdatePolicyStatus(ids: string[], status: PolicyStatus, dryRun = false) {
+  return request<{ updated: number; failed: number; results: BulkStatusResult[]; dry_run: boolean }>(
+    `/api/admin/policies/bulk-status${dryRun ? "?dry_run=true" : ""}`,
+    { method: "POST", body: JSON.stringify({ ids, status }) }
+  );
+}
+
 export function createPolicyVersion(
   policyId: string,
-  data: { content: string; version_string: string; changelog: string }
+  data: {
+    content: string;
+    version_string: string;
+    changelog: string;
+    effective_from?: string;
+    effective_to?: string;
+  }
 ) {
   return request<PolicyVersion>(`/api/policies/${policyId}/versions`, {
     method: "POST",
@@ -162,71 +593,1034 @@ export function createPolicyVersion(
   });
 }
 
-// ─── Users ─────────────────────────────────────────────────────────────────
+export function relatePolicy(policyId: string, relatedPolicyId: string, type: RelationType) {
+  return request<{ id: string }>(`/api/policies/${policyId}/relations`, {
+    method: "POST",
+    body: JSON.stringify({ related_policy_id: relatedPolicyId, type }),
+  });
+}
 
-export type UserRole = "SuperAdmin" | "DeptAdmin" | "Staff";
+export function deletePolicyRelation(policyId: string, relationId: string) {
+  return request<void>(`/api/policies/${policyId}/relations/${relationId}`, {
+    method: "DELETE",
+  });
+}
 
-export interface User {
+export type ExceptionStatus = "pending" | "approved" | "denied";
+
+export interface PolicyException {
   id: string;
-  email: string;
-  name: string;
-  role: UserRole;
-  created_by?: string;
-  department_id: string | null;
-  department_name: string | null;
+  policy_id: string;
+  policy_title: string;
+  user_id: string;
+  user_name: string;
+  user_email: string;
+  justification: string;
+  duration_days: number;
+  status: ExceptionStatus;
+  decided_by: string | null;
+  decided_by_name: string | null;
+  decision_note: string;
+  decided_at: string | null;
+  expires_at: string | null;
   created_at: string;
 }
 
-export function listUsers() {
-  return request<User[]>("/api/users");
+export function requestPolicyException(
+  policyId: string,
+  data: { justification: string; duration_days: number }
+) {
+  return request<PolicyException>(`/api/policies/${policyId}/exceptions`, {
+    method: "POST",
+    body: JSON.stringify(data),
+  });
 }
 
-export function createUser(data: {
-  email: string;
-  name: string;
-  role: string;
-  department_id?: string | null;
-}) {
-  return request<User>("/api/users", {
+export function getMyExceptions() {
+  return request<PolicyException[]>("/api/me/exceptions");
+}
+
+export function listExceptions(status?: ExceptionStatus) {
+  return request<PolicyException[]>(`/api/admin/exceptions${status ? `?status=${status}` : ""}`);
+}
+
+export function decideException(id: string, status: "approved" | "denied", note = "") {
+  return request<PolicyException>(`/api/admin/exceptions/${id}`, {
+    method: "PUT",
+    body: JSON.stringify({ status, note }),
+  });
+}
+
+export interface ChangeRequest {
+  id: string;
+  policy_id: string;
+  policy_version_id: string | null;
+  section: string;
+  comment: string;
+  status: "open" | "resolved" | "dismissed";
+  created_by: string | null;
+  created_by_name: string | null;
+  resolved_by: string | null;
+  resolved_by_name: string | null;
+  resolution_note: string;
+  resolved_at: string | null;
+  created_at: string;
+}
+
+export function listChangeRequests(policyId: string, status?: ChangeRequest["status"]) {
+  return request<ChangeRequest[]>(
+    `/api/policies/${policyId}/change-requests${status ? `?status=${status}` : ""}`
+  );
+}
+
+export function createChangeRequest(policyId: string, data: { section: string; comment: string }) {
+  return request<ChangeRequest>(`/api/policies/${policyId}/change-requests`, {
     method: "POST",
     body: JSON.stringify(data),
   });
 }
 
-export function updateUser(
-  id: string,
-  data: {
-    name?: string;
-    email?: string;
-    role?: string;
-    department_id?: string | null;
-  }
+export function closeChangeRequest(
+  policyId: string,
+  requestId: string,
+  status: "resolved" | "dismissed",
+  note = ""
 ) {
-  return request<User>(`/api/users/${id}`, {
+  return request<ChangeRequest>(`/api/policies/${policyId}/change-requests/${requestId}`, {
     method: "PUT",
+    body: JSON.stringify({ status, note }),
+  });
+}
+
+export interface PolicyShare {
+  id: string;
+  policy_id: string;
+  policy_version_id: string;
+  version_string: string;
+  created_by: string | null;
+  created_by_name: string | null;
+  expires_at: string;
+  revoked_at: string | null;
+  created_at: string;
+  view_count: number;
+  last_viewed_at: string | null;
+}
+
+export function sharePolicy(
+  policyId: string,
+  data: { version_id?: string; expires_in_hours?: number } = {}
+) {
+  return request<{ share: PolicyShare; url: string }>(`/api/policies/${policyId}/share`, {
+    method: "POST",
     body: JSON.stringify(data),
   });
 }
 
-export function deleteUser(id: string) {
-  return request<void>(`/api/users/${id}`, { method: "DELETE" });
+export function listPolicyShares(policyId: string) {
+  return request<PolicyShare[]>(`/api/policies/${policyId}/shares`);
 }
 
-// ─── Admin ─────────────────────────────────────────────────────────────────
+export function revokePolicyShare(policyId: string, shareId: string) {
+  return request<void>(`/api/policies/${policyId}/shares/${shareId}`, {
+    method: "DELETE",
+  });
+}
 
-export interface AdminStats {
-  stats: {
-    total_users: number;
-    total_policies: number;
-    published_count: number;
-    draft_count: number;
-    review_count: number;
-    archived_count: number;
-    total_acknowledgements: number;
-  };
-  ack_counts: { policy_id: string; title: string; ack_count: number }[];
+export interface PolicyAttachment {
+  id: string;
+  policy_id: string;
+  filename: string;
+  content_type: string;
+  size_bytes: number;
+  sha256: string;
+  uploaded_by: string | null;
+  uploaded_by_name: string | null;
+  created_at: string;
+}
+
+export function listPolicyAttachments(policyId: string) {
+  return request<PolicyAttachment[]>(`/api/policies/${policyId}/attachments`);
+}
+
+interface UploadStatus {
+  id: string;
+  size_bytes: number;
+  received: number;
+  chunk_bytes: number;
+}
+
+/** Files above this are sent in resumable chunks rather than in one request. */
+const CHUNKED_UPLOAD_BYTES = 8 << 20;
+
+/**
+ * Sends a file to /api/uploads chunk by chunk, retrying a failed chunk from
+ * where the server says it left off. Returns the upload ID to pass as
+ * upload_id.
+ */
+async function uploadInChunks(file: File, onProgress?: (sent: number) => void) {
+  let status = await request<UploadStatus>("/api/uploads", {
+    method: "POST",
+    body: JSON.stringify({ filename: file.name, size_bytes: file.size, content_type: file.type }),
+  });
+  let failures = 0;
+  while (status.received < status.size_bytes) {
+    const chunk = file.slice(status.received, status.received + status.chunk_bytes);
+    try {
+      status = await request<UploadStatus>(`/api/uploads/${status.id}`, {
+        method: "PATCH",
+        headers: { "Content-Type": "application/offset+octet-stream", "Upload-Offset": String(status.received) },
+        body: chunk,
+      });
+      failures = 0;
+      onProgress?.(status.received);
+    } catch (err) {
+      if (err instanceof ApiError && err.status < 500 && err.code !== "OFFSET_MISMATCH") throw err;
+      if (++failures > 5) throw err;
+      await new Promise((r) => setTimeout(r, 1000 * failures));
+      status = await request<UploadStatus>(`/api/uploads/${status.id}`);
+    }
+  }
+  return status.id;
+}
+
+/** Adds file to form as "file", or for large files as a finished chunked upload. */
+async function appendFile(form: FormData, file: File, onProgress?: (sent: number) => void) {
+  if (file.size > CHUNKED_UPLOAD_BYTES) {
+    form.append("upload_id", await uploadInChunks(file, onProgress));
+  } else {
+    form.append("file", file);
+  }
+}
+
+export async function uploadPolicyAttachment(policyId: string, file: File, onProgress?: (sent: number) => void) {
+  const token = getToken();
+  const form = new FormData();
+  await appendFile(form, file, onProgress);
+  const res = await fetch(`${API_BASE}/api/policies/${policyId}/attachments`, {
+    method: "POST",
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+    body: form,
+  });
+  if (!res.ok) throw await apiError(res);
+  return res.json() as Promise<PolicyAttachment>;
+}
+
+export async function uploadPolicyPdfVersion(
+  policyId: string,
+  file: File,
+  versionString: string,
+  changelog = "",
+  effective: { from?: string; to?: string } = {}
+) {
+  const token = getToken();
+  const form = new FormData();
+  await appendFile(form, file);
+  form.append("version_string", versionString);
+  if (changelog) form.append("changelog", changelog);
+  if (effective.from) form.append("effective_from", effective.from);
+  if (effective.to) form.append("effective_to", effective.to);
+  const res = await fetch(`${API_BASE}/api/policies/${policyId}/versions/pdf`, {
+    method: "POST",
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+    body: form,
+  });
+  if (!res.ok) throw await apiError(res);
+  return res.json() as Promise<{ version: PolicyVersion; warnings: string[] }>;
+}
+
+// fetchPolicyVersionFile downloads a version's original PDF; fetching the
+// current version counts as opening the policy.
+export async function fetchPolicyVersionFile(policyId: string, versionId: string) {
+  const token = getToken();
+  const res = await fetch(`${API_BASE}/api/policies/${policyId}/versions/${versionId}/file`, {
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+  });
+  if (!res.ok) throw new Error(`HTTP ${res.status}`);
+  return res.blob();
 }
 
-export function getAdminStats() {
-  return request<AdminStats>("/api/admin/stats");
+export interface DocxImport {
+  policy: Policy;
+  version: PolicyVersion;
+  warnings: string[];
+  dry_run: boolean; // policy and version were not created
+}
+
+export async function importPolicyDocx(
+  file: File,
+  fields: { title?: string; department_id?: string; visibility_type?: string; version_string?: string } = {},
+  dryRun = false
+) {
+  const token = getToken();
+  const form = new FormData();
+  await appendFile(form, file);
+  for (const [k, v] of Object.entries(fields)) {
+    if (v) form.append(k, v);
+  }
+  const res = await fetch(`${API_BASE}/api/policies/import/docx${dryRun ? "?dry_run=true" : ""}`, {
+    method: "POST",
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+    body: form,
+  });
+  if (!res.ok) throw await apiError(res);
+  return res.json() as Promise<DocxImport>;
+}
+
+export interface AckImportResult {
+  rows: number;
+  imported: number;
+  skipped: number; // already acknowledged
+  dry_run: boolean;
+}
+
+/**
+ * Imports acknowledgements given on paper or in a spreadsheet (SuperAdmin).
+ * The CSV needs email, policy, version, and date columns. A file with any
+ * invalid row is rejected whole; the error lists the rows under `errors`.
+ */
+export async function importAcknowledgements(file: File, dryRun = false) {
+  const token = getToken();
+  const form = new FormData();
+  await appendFile(form, file);
+  const res = await fetch(`${API_BASE}/api/admin/acknowledgements/import${dryRun ? "?dry_run=true" : ""}`, {
+    method: "POST",
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+    body: form,
+  });
+  if (!res.ok) throw await apiError(res);
+  return res.json() as Promise<AckImportResult>;
+}
+
+export function deletePolicyAttachment(policyId: string, attachmentId: string) {
+  return request<void>(`/api/policies/${policyId}/attachments/${attachmentId}`, {
+    method: "DELETE",
+  });
+}
+
+// ─── Users ─────────────────────────────────────────────────────────────────
+
+export type UserRole = "SuperAdmin" | "DeptAdmin" | "Staff";
+
+export interface User {
+  id: string;
+  email: string;
+  name: string;
+  role: UserRole;
+  created_by?: string;
+  department_id: string | null;
+  department_name: string | null;
+  anonymized_at?: string;
+  last_login_at?: string | null;
+  created_at: string;
+}
+
+export function listUsers() {
+  return request<User[]>("/api/users");
+}
+
+export interface UploadScan {
+  id: string;
+  kind: "attachment" | "docx_import" | "pdf_version";
+  policy_id: string | null;
+  filename: string;
+  size_bytes: number;
+  sha256: string;
+  scanner: string;
+  infected: boolean;
+  signature: string;
+  quarantined: boolean;
+  uploaded_by: string | null;
+  uploaded_by_name: string | null;
+  created_at: string;
+}
+
+export function listUploadScans(infectedOnly = false) {
+  return request<UploadScan[]>(`/api/admin/upload-scans${infectedOnly ? "?infected=true" : ""}`);
+}
+
+/** Deletes a flagged upload from quarantine; its scan result is kept. */
+export function deleteQuarantinedFile(scanId: string) {
+  return request<void>(`/api/admin/upload-scans/${scanId}/file`, { method: "DELETE" });
+}
+
+export interface PolicyBundle {
+  id: string;
+  name: string;
+  description: string;
+  department_id: string | null; // null = any department
+  department_name: string | null;
+  role: UserRole | null; // null = any role
+  policies: { id: string; title: string; status: PolicyStatus }[];
+  assigned_count: number;
+  created_by: string | null;
+  created_at: string;
+}
+
+export interface BundleAssignment {
+  bundle_id: string;
+  user_id: string;
+  user_name: string;
+  user_email: string;
+  assigned_by: string | null; // null = on account creation
+  assigned_at: string;
+}
+
+export interface BundleInput {
+  name?: string;
+  description?: string;
+  department_id?: string; // "" = any department
+  role?: UserRole | "";
+  policy_ids?: string[];
+}
+
+export function listBundles() {
+  return request<PolicyBundle[]>("/api/admin/bundles");
+}
+
+export function createBundle(bundle: BundleInput) {
+  return request<PolicyBundle>("/api/admin/bundles", { method: "POST", body: JSON.stringify(bundle) });
+}
+
+export function updateBundle(id: string, bundle: BundleInput) {
+  return request<PolicyBundle>(`/api/admin/bundles/${id}`, { method: "PUT", body: JSON.stringify(bundle) });
+}
+
+export function deleteBundle(id: string) {
+  return request<void>(`/api/admin/bundles/${id}`, { method: "DELETE" });
+}
+
+export function listBundleAssignments(id: string) {
+  return request<BundleAssignment[]>(`/api/admin/bundles/${id}/assignments`);
+}
+
+/** Applies a bundle to existing users; those who already had it are skipped. */
+export function assignBundle(id: string, userIds: string[]) {
+  return request<{ assigned: string[]; skipped: string[] }>(`/api/admin/bundles/${id}/assignments`, {
+    method: "POST",
+    body: JSON.stringify({ user_ids: userIds }),
+  });
+}
+
+export type CampaignAudience = "organization" | "department" | "role";
+
+export interface Campaign {
+  id: string;
+  name: string;
+  description: string;
+  starts_at: string;
+  deadline: string;
+  audience_type: CampaignAudience;
+  audience_id: string | null; // department ID or role; null for the organization
+  audience_name: string;
+  reminder_days: number[]; // days before the deadline
+  status: "scheduled" | "active" | "ended";
+  policies: { id: string; title: string; status: PolicyStatus }[];
+  created_by: string | null;
+  created_at: string;
+}
+
+export interface CampaignInput {
+  name?: string;
+  description?: string;
+  starts_at?: string; // YYYY-MM-DD or RFC 3339
+  deadline?: string;
+  audience_type?: CampaignAudience;
+  audience_id?: string;
+  reminder_days?: number[];
+  policy_ids?: string[];
+}
+
+export interface CampaignProgress {
+  campaign_id: string;
+  status: Campaign["status"];
+  deadline: string;
+  users: number;
+  completed_users: number;
+  required: number;
+  acknowledged: number;
+  late: number;
+  percent: number;
+  by_policy: { policy_id: string; policy_title: string; required: number; acknowledged: number }[];
+  by_department: {
+    department_id: string | null;
+    department: string;
+    users: number;
+    completed_users: number;
+    required: number;
+    acknowledged: number;
+  }[];
+  generated_at: string;
+}
+
+export function listCampaigns() {
+  return request<Campaign[]>("/api/admin/campaigns");
+}
+
+export function getCampaign(id: string) {
+  return request<Campaign>(`/api/admin/campaigns/${id}`);
+}
+
+export function createCampaign(campaign: CampaignInput) {
+  return request<Campaign>("/api/admin/campaigns", { method: "POST", body: JSON.stringify(campaign) });
+}
+
+export function updateCampaign(id: string, campaign: CampaignInput) {
+  return request<Campaign>(`/api/admin/campaigns/${id}`, { method: "PUT", body: JSON.stringify(campaign) });
+}
+
+export function deleteCampaign(id: string) {
+  return request<void>(`/api/admin/campaigns/${id}`, { method: "DELETE" });
+}
+
+/** Live campaign progress; a DeptAdmin gets their own department only. */
+export function getCampaignProgress(id: string) {
+  return request<CampaignProgress>(`/api/admin/campaigns/${id}/progress`);
+}
+
+export interface CollectionProgress {
+  total: number;
+  acknowledged: number;
+  ack_percentage: number;
+}
+
+/** A collection as the current user reads it: only policies they can see. */
+export interface CollectionView {
+  id: string;
+  title: string;
+  description: string;
+  progress: CollectionProgress;
+  next_policy_id: string | null; // null = everything acknowledged
+  items?: { id: string; title: string; current_version_id: string; acknowledged: boolean }[];
+}
+
+export interface Collection {
+  id: string;
+  title: string;
+  description: string;
+  position: number;
+  policies: { id: string; title: string; status: PolicyStatus }[];
+  created_by: string | null;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CollectionInput {
+  title?: string;
+  description?: string;
+  position?: number;
+  policy_ids?: string[];
+}
+
+export function listCollections() {
+  return request<CollectionView[]>("/api/collections");
+}
+
+export function getCollection(id: string) {
+  return request<CollectionView>(`/api/collections/${id}`);
+}
+
+export function listAllCollections() {
+  return request<Collection[]>("/api/admin/collections");
+}
+
+export function createCollection(collection: CollectionInput) {
+  return request<Collection>("/api/admin/collections", { method: "POST", body: JSON.stringify(collection) });
+}
+
+export function updateCollection(id: string, collection: CollectionInput) {
+  return request<Collection>(`/api/admin/collections/${id}`, { method: "PUT", body: JSON.stringify(collection) });
+}
+
+export function deleteCollection(id: string) {
+  return request<void>(`/api/admin/collections/${id}`, { method: "DELETE" });
+}
+
+export type CustomFieldType = "text" | "date" | "select";
+
+export interface CustomField {
+  id: string;
+  key: string;
+  label: string;
+  type: CustomFieldType;
+  options: string[];
+  position: number;
+  created_at: string;
+}
+
+export function listCustomFields() {
+  return request<CustomField[]>("/api/custom-fields");
+}
+
+export function createCustomField(field: { key?: string; label: string; type: CustomFieldType; options?: string[] }) {
+  return request<CustomField>("/api/admin/custom-fields", { method: "POST", body: JSON.stringify(field) });
+}
+
+/** Key and type are fixed once a field is created. */
+export function updateCustomField(id: string, field: { label?: string; options?: string[]; position?: number }) {
+  return request<CustomField>(`/api/admin/custom-fields/${id}`, { method: "PUT", body: JSON.stringify(field) });
+}
+
+export function deleteCustomField(id: string) {
+  return request<void>(`/api/admin/custom-fields/${id}`, { method: "DELETE" });
+}
+
+/** Sets values by field key; an empty string clears a field. */
+export function setPolicyCustomFields(policyId: string, values: Record<string, string>) {
+  return request<Record<string, string>>(`/api/policies/${policyId}/custom-fields`, {
+    method: "PUT",
+    body: JSON.stringify(values),
+  });
+}
+
+export interface DepartmentGrant {
+  user_id: string;
+  department_id: string;
+  department_name: string;
+  granted_by: string | null;
+  granted_at: string;
+}
+
+export function listDepartmentGrants(userId: string) {
+  return request<DepartmentGrant[]>(`/api/admin/users/${userId}/departments`);
+}
+
+/** Lets a DeptAdmin also administer deptId. Returns their grants. */
+export function grantDepartment(userId: string, deptId: string) {
+  return request<DepartmentGrant[]>(`/api/admin/users/${userId}/departments/${deptId}`, { method: "PUT" });
+}
+
+export function revokeDepartment(userId: string, deptId: string) {
+  return request<void>(`/api/admin/users/${userId}/departments/${deptId}`, { method: "DELETE" });
+}
+
+/** Active users who have not signed in within `days` days, never-signed-in first. */
+export function listInactiveUsers(days = 90) {
+  return request<User[]>(`/api/admin/users/inactive?days=${days}`);
+}
+
+export function getUsersByIds(ids: string[]) {
+  if (ids.length <= 100) {
+    return request<User[]>(`/api/users?ids=${ids.map(encodeURIComponent).join(",")}`);
+  }
+  return request<User[]>("/api/users/batch", { method: "POST", body: JSON.stringify({ ids }) });
+}
+
+export function createUser(data: {
+  email: string;
+  name: string;
+  role: string;
+  department_id?: string | null;
+}) {
+  return request<User>("/api/users", {
+    method: "POST",
+    body: JSON.stringify(data),
+  });
+}
+
+export function updateUser(
+  id: string,
+  data: {
+    name?: string;
+    email?: string;
+    role?: string;
+    department_id?: string | null;
+  }
+) {
+  return request<User>(`/api/users/${id}`, {
+    method: "PUT",
+    body: JSON.stringify(data),
+  });
+}
+
+// resendInvite emails the user a fresh welcome link. Fails with 429
+// INVITE_RATE_LIMITED after three sign-in links in an hour.
+export function resendInvite(id: string) {
+  return request<void>(`/api/users/${id}/resend-invite`, { method: "POST" });
+}
+
+// deleteUser anonymizes the user, keeping their acknowledgements. Pass
+// hard=true to remove an account that has never acknowledged anything.
+export interface OwnershipTransfer {
+  policies: number;
+  drafts: number;
+  pending_reviews: number;
+  change_requests: number;
+  report_schedules: number;
+}
+
+// deleteUser anonymizes (or with hard, deletes) a user. transferTo hands
+// their policies, open change requests, and report schedules to another
+// admin in the same request.
+export function deleteUser(id: string, hard = false, transferTo?: string) {
+  return request<OwnershipTransfer | void>(
+    `/api/users/${id}${pageQuery({ hard: hard ? "true" : undefined, transfer_to: transferTo })}`,
+    { method: "DELETE" }
+  );
+}
+
+// ─── Admin ─────────────────────────────────────────────────────────────────
+
+export interface AdminStats {
+  stats: {
+    total_users: number;
+    total_policies: number;
+    published_count: number;
+    draft_count: number;
+    review_count: number;
+    archived_count: number;
+    total_acknowledgements: number;
+    /** Activity within from..to; only present when a range was given. */
+    period?: { acknowledgements: number; publishes: number; new_users: number };
+  };
+  ack_counts: { policy_id: string; title: string; ack_count: number }[] | null;
+}
+
+/** from and to are inclusive YYYY-MM-DD dates; either may be omitted. */
+export function getAdminStats(range: { from?: string; to?: string } = {}) {
+  const params = new URLSearchParams();
+  if (range.from) params.set("from", range.from);
+  if (range.to) params.set("to", range.to);
+  const qs = params.toString();
+  return request<AdminStats>(`/api/admin/stats${qs ? `?${qs}` : ""}`);
+}
+
+export interface JWTKey {
+  kid: string;
+  alg: "HS256" | "RS256" | "EdDSA";
+  current: boolean;
+  expires_at: string | null;
+}
+
+export function listJWTKeys() {
+  return request<JWTKey[]>("/api/admin/jwt/keys");
+}
+
+export function rotateJWTKey() {
+  return request<{ kid: string; previous_valid_until: string }>("/api/admin/jwt/rotate", {
+    method: "POST",
+  });
+}
+
+export interface ActivityItem {
+  id: string;
+  type: string;
+  actor_id: string | null;
+  actor_name: string | null;
+  target_type: "policy" | "user" | "department";
+  target_id: string;
+  target_name: string | null;
+  details: string;
+  created_at: string;
+}
+
+export function getActivity(params: { type?: string; before?: string; limit?: number } = {}) {
+  const query = new URLSearchParams(
+    Object.entries(params).filter(([, v]) => v !== undefined).map(([k, v]) => [k, String(v)])
+  ).toString();
+  return request<{ items: ActivityItem[]; next_before: string | null }>(
+    `/api/admin/activity${query ? `?${query}` : ""}`
+  );
+}
+
+export interface EmailTemplate {
+  name: string;
+  subject: string;
+  body: string;
+  /** Placeholders the template may use, as {{.Name}}. */
+  variables: string[];
+  customized: boolean;
+  default: { name: string; subject: string; body: string };
+  updated_by?: string | null;
+  updated_at?: string;
+}
+
+export function listEmailTemplates() {
+  return request<EmailTemplate[]>("/api/admin/email-templates");
+}
+
+export function updateEmailTemplate(name: string, data: { subject?: string; body?: string }) {
+  return request<EmailTemplate>(`/api/admin/email-templates/${name}`, {
+    method: "PUT",
+    body: JSON.stringify(data),
+  });
+}
+
+export function resetEmailTemplate(name: string) {
+  return request<void>(`/api/admin/email-templates/${name}`, { method: "DELETE" });
+}
+
+export function previewEmailTemplate(name: string, data: { subject?: string; body?: string } = {}) {
+  return request<{ subject: string; body: string }>(`/api/admin/email-templates/${name}/preview`, {
+    method: "POST",
+    body: JSON.stringify(data),
+  });
+}
+
+// ─── Reports ───────────────────────────────────────────────────────────────
+
+export interface ReportDefinition {
+  entity: "acknowledgements" | "users" | "policies";
+  columns?: string[];
+  filters?: { field: string; op: "eq" | "neq" | "contains" | "gte" | "lte" | "in"; value?: string; values?: string[] }[];
+  group_by?: string[];
+  date_range?: { from?: string; to?: string };
+  limit?: number;
+}
+
+export interface ReportResult {
+  columns: string[];
+  rows: string[][];
+}
+
+export function getReportFields() {
+  return request<Record<string, string[]>>("/api/admin/reports/fields");
+}
+
+export function runReport(def: ReportDefinition) {
+  return request<ReportResult>("/api/admin/reports", {
+    method: "POST",
+    body: JSON.stringify(def),
+  });
+}
+
+export interface AckMatrix {
+  policies: Policy[];
+  users: User[];
+  cells: {
+    status: "acknowledged" | "pending" | "overdue" | "";
+    acknowledged_at?: string;
+    exception_until?: string;
+  }[][];
+}
+
+export interface DepartmentCompliance {
+  department_id: string | null;
+  department: string;
+  users: number;
+  required: number;
+  acknowledged: number;
+  pending: number;
+  overdue: number;
+  active_exceptions: number;
+  ack_percentage: number;
+}
+
+export function getAckMatrix() {
+  return request<AckMatrix>("/api/admin/reports/ack-matrix");
+}
+
+export function getDepartmentCompliance() {
+  return request<DepartmentCompliance[]>("/api/admin/reports/department-compliance");
+}
+
+export interface ReackGap {
+  user_id: string;
+  user_name: string;
+  user_email: string;
+  department_id: string | null;
+  department: string;
+  policy_id: string;
+  policy_title: string;
+  current_version: string;
+  acknowledged_version: string;
+  acknowledged_at: string;
+}
+
+export interface ReackGapReport {
+  by_policy: { policy_id: string; policy_title: string; current_version: string; users: number }[];
+  by_department: { department_id: string | null; department: string; users: number; gaps: number }[];
+  gaps: ReackGap[];
+}
+
+/** Users who acknowledged an earlier version of a policy but not the current one. */
+export function getReackGaps() {
+  return request<ReackGapReport>("/api/admin/reports/reack-gaps");
+}
+
+export interface PolicyTraining {
+  policy_id: string;
+  course_id: string;
+  course_name: string;
+  course_url: string;
+  updated_by: string | null;
+  updated_at: string;
+  completed_at?: string | null; // the current user's completion
+}
+
+export interface TrainingGap {
+  user_id: string;
+  user_name: string;
+  user_email: string;
+  department_name: string | null;
+  policy_id: string;
+  policy_title: string;
+  course_id: string;
+  course_name: string;
+  acknowledged_at: string;
+}
+
+export function getPolicyTraining(policyId: string) {
+  return request<PolicyTraining>(`/api/policies/${policyId}/training`);
+}
+
+export function setPolicyTraining(policyId: string, course: { course_id: string; course_name?: string; course_url?: string }) {
+  return request<PolicyTraining>(`/api/policies/${policyId}/training`, { method: "PUT", body: JSON.stringify(course) });
+}
+
+export function deletePolicyTraining(policyId: string) {
+  return request<void>(`/api/policies/${policyId}/training`, { method: "DELETE" });
+}
+
+export function getTrainingGaps() {
+  return request<TrainingGap[]>("/api/admin/reports/training-gaps");
+}
+
+export interface PolicyFAQ {
+  id: string;
+  policy_id: string;
+  question: string;
+  answer: string; // Markdown
+  position: number;
+  created_by: string | null;
+  updated_by: string | null;
+  created_at: string;
+  updated_at: string;
+}
+
+export function listPolicyFaqs(policyId: string) {
+  return request<PolicyFAQ[]>(`/api/policies/${policyId}/faqs`);
+}
+
+export function createPolicyFaq(policyId: string, faq: { question: string; answer: string }) {
+  return request<PolicyFAQ>(`/api/policies/${policyId}/faqs`, { method: "POST", body: JSON.stringify(faq) });
+}
+
+/** Edits or moves an FAQ entry; editing FAQs never asks for re-acknowledgement. */
+export function updatePolicyFaq(policyId: string, faqId: string, faq: { question?: string; answer?: string; position?: number }) {
+  return request<PolicyFAQ>(`/api/policies/${policyId}/faqs/${faqId}`, { method: "PUT", body: JSON.stringify(faq) });
+}
+
+export function deletePolicyFaq(policyId: string, faqId: string) {
+  return request<void>(`/api/policies/${policyId}/faqs/${faqId}`, { method: "DELETE" });
+}
+
+export interface AuthorStats {
+  author_id: string | null;
+  author_name: string;
+  author_email: string;
+  policies: number;
+  drafts: number;
+  in_review: number;
+  published: number;
+  archived: number;
+  overdue_reviews: number;
+  avg_days_to_publish: number | null;
+}
+
+export function getAuthorStats(reviewMonths?: number) {
+  const q = reviewMonths ? `?review_months=${reviewMonths}` : "";
+  return request<{ review_months: number; authors: AuthorStats[] }>(`/api/admin/reports/authors${q}`);
+}
+
+// downloadComplianceReport fetches a compliance report as a CSV or Excel file.
+export async function downloadComplianceReport(
+  report: "ack-matrix" | "department-compliance",
+  format: "csv" | "xlsx"
+) {
+  const token = getToken();
+  const res = await fetch(`${API_BASE}/api/admin/reports/${report}?format=${format}`, {
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+  });
+  if (!res.ok) throw new Error(`HTTP ${res.status}`);
+  return res.blob();
+}
+
+export interface ReportSchedule {
+  id: string;
+  name: string;
+  definition: ReportDefinition;
+  frequency: "weekly" | "monthly";
+  format: "csv" | "pdf";
+  recipients: string[];
+  department_id: string | null;
+  created_by: string | null;
+  created_at: string;
+  next_run_at: string;
+  last_run_at: string | null;
+  last_error: string;
+  disabled_at: string | null;
+}
+
+export function listReportSchedules() {
+  return request<ReportSchedule[]>("/api/admin/reports/schedules");
+}
+
+export function createReportSchedule(data: {
+  name: string;
+  definition: ReportDefinition;
+  frequency: "weekly" | "monthly";
+  format?: "csv" | "pdf";
+  recipients: string[];
+}) {
+  return request<ReportSchedule>("/api/admin/reports/schedules", {
+    method: "POST",
+    body: JSON.stringify(data),
+  });
+}
+
+export function sendReportSchedule(id: string) {
+  return request<{ sent: number }>(`/api/admin/reports/schedules/${id}/send`, { method: "POST" });
+}
+
+export function deleteReportSchedule(id: string) {
+  return request<void>(`/api/admin/reports/schedules/${id}`, { method: "DELETE" });
+}
+
+export type ClaimAttribute = "department" | "ou" | "job_title" | "group";
+export type ClaimOperator = "equals" | "contains" | "prefix" | "regex";
+
+export interface ClaimRule {
+  id: string;
+  attribute: ClaimAttribute;
+  operator: ClaimOperator;
+  value: string;
+  role: "Staff" | "DeptAdmin" | null;
+  department_id: string | null;
+  department_name: string | null;
+  position: number;
+  created_at: string;
+}
+
+export interface ClaimRuleInput {
+  attribute: ClaimAttribute;
+  operator: ClaimOperator;
+  value: string;
+  role?: "Staff" | "DeptAdmin" | null;
+  department_id?: string | null;
+  position?: number;
+}
+
+export function listClaimRules() {
+  return request<ClaimRule[]>("/api/admin/claim-rules");
+}
+
+export function createClaimRule(rule: ClaimRuleInput) {
+  return request<ClaimRule>("/api/admin/claim-rules", { method: "POST", body: JSON.stringify(rule) });
+}
+
+/** Replaces the rule; position is kept when omitted. */
+export function updateClaimRule(id: string, rule: ClaimRuleInput) {
+  return request<ClaimRule>(`/api/admin/claim-rules/${id}`, { method: "PUT", body: JSON.stringify(rule) });
+}
+
+export function deleteClaimRule(id: string) {
+  return request<void>(`/api/admin/claim-rules/${id}`, { method: "DELETE" });
+}
+
+/** Shows what the rules would assign to an employee with these attributes. */
+export function evaluateClaimRules(attrs: { department?: string; ou?: string; job_title?: string; groups?: string[] }) {
+  return request<{ role: string; department_id: string | null; matched: string[] }>("/api/admin/claim-rules/evaluate", {
+    method: "POST",
+    body: JSON.stringify(attrs),
+  });
 }
@@ -1,4 +1,5 @@
 const TOKEN_KEY = "pf_token";
+const DEPT_KEY = "pf_acting_dept";
 
 export type Role = "SuperAdmin" | "DeptAdmin" | "Staff";
 
@@ -13,6 +14,21 @@ export function setToken(token: string): void {
 
 export function clearToken(): void {
   localStorage.removeItem(TOKEN_KEY);
+  localStorage.removeItem(DEPT_KEY);
+}
+
+/**
+ * The department a DeptAdmin with department grants is acting for, sent as
+ * X-Department-Id; null means their own department.
+ */
+export function getActingDepartment(): string | null {
+  if (typeof window === "undefined") return null;
+  return localStorage.getItem(DEPT_KEY);
+}
+
+export function setActingDepartment(id: string | null): void {
+  if (id) localStorage.setItem(DEPT_KEY, id);
+  else localStorage.removeItem(DEPT_KEY);
 }
 
 export function isAuthenticated(): boolean {
@@ -26,6 +42,23 @@ export function isAuthenticated(): boolean {
   }
 }
 
+const REDIRECT_PATHS = ["/policies", "/admin"];
+
+/**
+ * Returns `to` if it is a page on this site a sign-in may land on, else
+ * null. Mirrors the server's check on the magic-link `redirect` parameter.
+ */
+export function safeRedirect(to: string | null): string | null {
+  if (!to || !to.startsWith("/") || to.startsWith("//") || to.includes("\\")) return null;
+  const path = new URL(to, "http://x").pathname;
+  return REDIRECT_PATHS.some((p) => path === p || path.startsWith(p + "/")) ? to : null;
+}
+
+/** The login page URL that returns to the current page after sign-in. */
+export function loginURL(): string {
+  return `/?redirect=${encodeURIComponent(window.location.pathname + window.location.search)}`;
+}
+
 export interface TokenPayload {
   sub: string;
   email: string;